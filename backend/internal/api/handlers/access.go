@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/clipset/clipset-go/internal/db/sqlc"
+	"github.com/clipset/clipset-go/internal/domain"
+)
+
+// Video access rules shared by the video, playlist, and comment handlers
+
+// hasVideoAccess checks if user can access a video
+func hasVideoAccess(video sqlc.Video, userID uuid.UUID, isAdmin bool) bool {
+	if isAdmin {
+		return true
+	}
+	if video.ProcessingStatus == domain.ProcessingStatusCompleted {
+		return true
+	}
+	return video.UploadedBy == userID
+}
+
+// isVideoOwnerOrAdmin checks if user is the video owner or admin
+func isVideoOwnerOrAdmin(video sqlc.Video, userID uuid.UUID, isAdmin bool) bool {
+	return isAdmin || video.UploadedBy == userID
+}
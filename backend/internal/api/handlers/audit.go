@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/clipset/clipset-go/internal/api/middleware"
+	"github.com/clipset/clipset-go/internal/api/response"
+	"github.com/clipset/clipset-go/internal/config"
+	"github.com/clipset/clipset-go/internal/db"
+	"github.com/clipset/clipset-go/internal/db/sqlc"
+)
+
+// Audited actions
+const (
+	auditConfigUpdate       = "config.update"
+	auditConfigImport       = "config.import"
+	auditHLSMigrationStart  = "config.hls_migration_start"
+	auditHLSMigrationCancel = "config.hls_migration_cancel"
+	auditHLSMigrationPause  = "config.hls_migration_pause"
+	auditHLSMigrationResume = "config.hls_migration_resume"
+	auditUserActivate       = "user.activate"
+	auditUserDeactivate     = "user.deactivate"
+	auditUserResetLink      = "user.generate_reset_link"
+	auditQuotaResetAll      = "quota.reset_all"
+	auditVideoDelete        = "video.delete"
+	auditVideoRetryFailed   = "video.retry_failed"
+	auditVideoPurgeFailed   = "video.purge_failed"
+	auditPlaylistDelete     = "playlist.delete"
+	auditCommentDelete      = "comment.delete"
+	auditInvitationCreate   = "invitation.create"
+	auditStorageCleanup     = "storage.cleanup"
+	auditStorageTempCleanup = "storage.temp_cleanup"
+	auditWebhookCreate      = "webhook.create"
+	auditWebhookDelete      = "webhook.delete"
+)
+
+// Audit target types
+const (
+	auditTargetConfig     = "config"
+	auditTargetUser       = "user"
+	auditTargetVideo      = "video"
+	auditTargetPlaylist   = "playlist"
+	auditTargetComment    = "comment"
+	auditTargetInvitation = "invitation"
+	auditTargetStorage    = "storage"
+	auditTargetWebhook    = "webhook"
+)
+
+// Date-only layout accepted by the audit log date filters
+const auditDateLayout = "2006-01-02"
+
+// auditEntry describes one audited action. The actor is taken from the request context.
+type auditEntry struct {
+	Action     string
+	TargetType string
+	TargetID   uuid.UUID // uuid.Nil when the action has no single target
+	Details    map[string]interface{}
+}
+
+// recordAudit writes an audit log entry. Failures are logged and otherwise
+// ignored so auditing never fails the action being audited.
+func recordAudit(ctx context.Context, queries *sqlc.Queries, entry auditEntry) {
+	params := sqlc.CreateAuditLogEntryParams{
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		Details:    []byte("{}"),
+	}
+	if actorID, ok := middleware.GetUserID(ctx); ok {
+		params.ActorID = pgtype.UUID{Bytes: actorID, Valid: true}
+	}
+	if entry.TargetID != uuid.Nil {
+		params.TargetID = pgtype.UUID{Bytes: entry.TargetID, Valid: true}
+	}
+	if len(entry.Details) > 0 {
+		details, err := json.Marshal(entry.Details)
+		if err != nil {
+			log.Printf("Error encoding audit details for %s: %v", entry.Action, err)
+		} else {
+			params.Details = details
+		}
+	}
+
+	if err := queries.CreateAuditLogEntry(ctx, params); err != nil {
+		log.Printf("Error recording audit entry %s on %s %s: %v", entry.Action, entry.TargetType, entry.TargetID, err)
+	}
+}
+
+// AuditHandler serves the admin audit log
+type AuditHandler struct {
+	db     *db.DB
+	config *config.Config
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(database *db.DB, cfg *config.Config) *AuditHandler {
+	return &AuditHandler{
+		db:     database,
+		config: cfg,
+	}
+}
+
+// AuditLogEntryResponse represents an audit log entry in API responses
+type AuditLogEntryResponse struct {
+	ID            string          `json:"id"`
+	ActorID       *string         `json:"actor_id"`
+	ActorUsername *string         `json:"actor_username"`
+	Action        string          `json:"action"`
+	TargetType    string          `json:"target_type"`
+	TargetID      *string         `json:"target_id"`
+	Details       json.RawMessage `json:"details"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// AuditLogListResponse represents a page of audit log entries
+type AuditLogListResponse struct {
+	Entries []AuditLogEntryResponse `json:"entries"`
+	Total   int64                   `json:"total"`
+	HasMore bool                    `json:"has_more"`
+}
+
+// parseAuditTime parses an RFC 3339 timestamp or a YYYY-MM-DD date. With
+// endOfDay set, a bare date means the end of that day so ranges include it.
+func parseAuditTime(value string, endOfDay bool) (*time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, true
+	}
+	t, err := time.Parse(auditDateLayout, value)
+	if err != nil {
+		return nil, false
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, true
+}
+
+// List handles GET /api/admin/audit-log (admin only)
+// Optional filters: actor_id, action, from and to (RFC 3339 or YYYY-MM-DD)
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	// Parse pagination
+	skip := 0
+	limit := 50
+	if s := query.Get("skip"); s != "" {
+		if v, err := strconv.Atoi(s); err == nil && v >= 0 {
+			skip = v
+		}
+	}
+	if l := query.Get("limit"); l != "" {
+		if v, err := strconv.Atoi(l); err == nil && v > 0 && v <= 200 {
+			limit = v
+		}
+	}
+
+	// Parse filters
+	params := sqlc.ListAuditLogParams{
+		Action:     query.Get("action"),
+		PageLimit:  int32(limit),
+		PageOffset: int32(skip),
+	}
+	if s := query.Get("actor_id"); s != "" {
+		actorID, err := uuid.Parse(s)
+		if err != nil {
+			response.BadRequest(w, "Invalid actor ID format")
+			return
+		}
+		params.ActorID = pgtype.UUID{Bytes: actorID, Valid: true}
+	}
+	if s := query.Get("from"); s != "" {
+		since, ok := parseAuditTime(s, false)
+		if !ok {
+			response.BadRequest(w, "Invalid from date (use RFC 3339 or YYYY-MM-DD)")
+			return
+		}
+		params.Since = since
+	}
+	if s := query.Get("to"); s != "" {
+		until, ok := parseAuditTime(s, true)
+		if !ok {
+			response.BadRequest(w, "Invalid to date (use RFC 3339 or YYYY-MM-DD)")
+			return
+		}
+		params.Until = until
+	}
+
+	entries, err := h.db.Queries.ListAuditLog(ctx, params)
+	if err != nil {
+		log.Printf("Error listing audit log: %v", err)
+		response.InternalServerError(w, "Failed to list audit log")
+		return
+	}
+
+	total, err := h.db.Queries.CountAuditLog(ctx, sqlc.CountAuditLogParams{
+		ActorID: params.ActorID,
+		Action:  params.Action,
+		Since:   params.Since,
+		Until:   params.Until,
+	})
+	if err != nil {
+		log.Printf("Error counting audit log: %v", err)
+		response.InternalServerError(w, "Failed to list audit log")
+		return
+	}
+
+	result := make([]AuditLogEntryResponse, len(entries))
+	for i, e := range entries {
+		result[i] = AuditLogEntryResponse{
+			ID:            e.ID.String(),
+			ActorID:       pgUUIDToString(e.ActorID),
+			ActorUsername: e.ActorUsername,
+			Action:        e.Action,
+			TargetType:    e.TargetType,
+			TargetID:      pgUUIDToString(e.TargetID),
+			Details:       json.RawMessage(e.Details),
+			CreatedAt:     e.CreatedAt,
+		}
+	}
+
+	response.OK(w, AuditLogListResponse{
+		Entries: result,
+		Total:   total,
+		HasMore: int64(skip+len(entries)) < total,
+	})
+}
@@ -4,32 +4,41 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"log"
+	"net"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 
 	"github.com/clipset/clipset-go/internal/api/middleware"
 	"github.com/clipset/clipset-go/internal/api/response"
+	"github.com/clipset/clipset-go/internal/config"
 	"github.com/clipset/clipset-go/internal/db"
 	"github.com/clipset/clipset-go/internal/db/sqlc"
-	"github.com/clipset/clipset-go/internal/domain"
 	"github.com/clipset/clipset-go/internal/services/auth"
+	"github.com/clipset/clipset-go/internal/services/session"
 )
 
 // AuthHandler handles authentication endpoints
 type AuthHandler struct {
 	db         *db.DB
+	config     *config.Config
 	jwtService *auth.JWTService
+	sessions   *session.Store
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler(database *db.DB, jwtService *auth.JWTService) *AuthHandler {
+func NewAuthHandler(database *db.DB, cfg *config.Config, jwtService *auth.JWTService, sessions *session.Store) *AuthHandler {
 	return &AuthHandler{
 		db:         database,
+		config:     cfg,
 		jwtService: jwtService,
+		sessions:   sessions,
 	}
 }
 
@@ -47,11 +56,44 @@ type RegisterRequest struct {
 	InvitationToken string `json:"invitation_token"`
 }
 
+// TokenResponse carries issued tokens. In cookie mode the tokens are set as
+// httpOnly cookies instead and left out of the body.
 type TokenResponse struct {
-	AccessToken string `json:"access_token"`
-	TokenType   string `json:"token_type"`
+	AccessToken  string `json:"access_token,omitempty"`
+	TokenType    string `json:"token_type"` // "bearer", or "cookie" in cookie mode
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int64  `json:"expires_in"` // Access token lifetime in seconds
 }
 
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// UsernameChangeRequest represents the request to change the caller's username
+type UsernameChangeRequest struct {
+	Username string `json:"username"`
+}
+
+// UsernameChangeResponse carries tokens with the new username claim
+type UsernameChangeResponse struct {
+	User UserResponse `json:"user"`
+	TokenResponse
+}
+
+// Users may only change their username this often
+const usernameChangeCooldown = 30 * 24 * time.Hour
+
+// MediaTokenResponse carries a token for image URLs (?token=)
+type MediaTokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresIn int64     `json:"expires_in"` // Lifetime in seconds
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Media tokens end up in URLs, so they are kept short-lived. Clients should
+// reuse one until it nears expiry so image URLs stay cacheable.
+const mediaTokenExpiry = time.Hour
+
 type ForgotPasswordRequest struct {
 	Email string `json:"email"`
 }
@@ -65,6 +107,7 @@ type UserResponse struct {
 	ID                string     `json:"id"`
 	Email             string     `json:"email"`
 	Username          string     `json:"username"`
+	DisplayName       string     `json:"display_name"`
 	Role              string     `json:"role"`
 	CreatedAt         time.Time  `json:"created_at"`
 	IsActive          bool       `json:"is_active"`
@@ -75,6 +118,17 @@ type UserResponse struct {
 	PlaylistCount     int64      `json:"playlist_count"`
 }
 
+// SessionResponse describes one of the caller's active sessions
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	UserAgent  *string   `json:"user_agent"`
+	IPAddress  *string   `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // The session making this request
+}
+
 // Login handles POST /api/auth/login
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req LoginRequest
@@ -113,18 +167,15 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Generate token
-	token, err := h.jwtService.GenerateToken(user.ID, user.Username, user.Role)
+	// Start a session and generate its tokens
+	tokens, err := h.issueTokens(r, &user)
 	if err != nil {
 		log.Printf("Error generating token: %v", err)
 		response.InternalServerError(w, "Failed to generate token")
 		return
 	}
 
-	response.OK(w, TokenResponse{
-		AccessToken: token,
-		TokenType:   "bearer",
-	})
+	response.OK(w, h.deliverTokens(w, r, tokens))
 }
 
 // Register handles POST /api/auth/register
@@ -146,8 +197,8 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(req.Password) < 8 {
-		response.BadRequest(w, "Password must be at least 8 characters")
+	if err := auth.ValidatePassword(passwordPolicy(h.config), req.Password); err != nil {
+		response.BadRequest(w, err.Error())
 		return
 	}
 
@@ -202,37 +253,274 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Create user
-	user, err := h.db.Queries.CreateUser(ctx, sqlc.CreateUserParams{
-		Email:        strings.ToLower(req.Email),
-		Username:     strings.ToLower(req.Username),
-		PasswordHash: passwordHash,
-		Role:         domain.UserRoleUser,
+	// Take a use of the invitation and create the user together. The update
+	// locks the invitation row, so concurrent registrations can't both take
+	// its last use.
+	var user sqlc.User
+	err = h.db.InTx(ctx, func(q *sqlc.Queries) error {
+		if _, err := q.ConsumeInvitationUse(ctx, invitation.ID); err != nil {
+			return err
+		}
+
+		var err error
+		user, err = q.CreateUser(ctx, sqlc.CreateUserParams{
+			Email:        strings.ToLower(req.Email),
+			Username:     strings.ToLower(req.Username),
+			PasswordHash: passwordHash,
+			Role:         invitation.Role,
+		})
+		return err
 	})
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.BadRequest(w, "Invalid or expired invitation token")
+			return
+		}
 		log.Printf("Error creating user: %v", err)
 		response.InternalServerError(w, "Failed to create user")
 		return
 	}
 
-	// Mark invitation as used
-	if err := h.db.Queries.MarkInvitationUsed(ctx, invitation.ID); err != nil {
-		log.Printf("Error marking invitation used: %v", err)
-		// Don't fail the registration, just log the error
+	// Start a session and generate its tokens
+	tokens, err := h.issueTokens(r, &user)
+	if err != nil {
+		log.Printf("Error generating token: %v", err)
+		response.InternalServerError(w, "Failed to generate token")
+		return
+	}
+
+	// Also return the tokens in headers (or cookies) for immediate use
+	if h.config.AuthCookieMode {
+		h.deliverTokens(w, r, tokens)
+	} else {
+		w.Header().Set("X-Auth-Token", tokens.AccessToken)
+		w.Header().Set("X-Refresh-Token", tokens.RefreshToken)
+	}
+
+	response.Created(w, h.userToResponse(ctx, &user, true))
+}
+
+// passwordPolicy returns the configured rules for new passwords
+func passwordPolicy(cfg *config.Config) auth.PasswordPolicy {
+	return auth.PasswordPolicy{
+		MinLength:        cfg.PasswordMinLength,
+		RequireMixedCase: cfg.PasswordRequireMixedCase,
+		RequireDigit:     cfg.PasswordRequireDigit,
+		RequireSymbol:    cfg.PasswordRequireSymbol,
+		RejectCommon:     cfg.PasswordRejectCommon,
+	}
+}
+
+// issueTokens records a new session for the user and returns tokens bound to it
+func (h *AuthHandler) issueTokens(r *http.Request, user *sqlc.User) (TokenResponse, error) {
+	expiresAt := time.Now().Add(h.config.RefreshTokenExpiry)
+	sess, err := h.sessions.Create(r.Context(), user.ID, r.UserAgent(), clientIP(r), expiresAt)
+	if err != nil {
+		return TokenResponse{}, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	refreshToken, err := h.createRefreshToken(r.Context(), h.db.Queries, sess.ID, expiresAt)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	return h.tokenResponse(user, sess.ID, refreshToken)
+}
+
+// createRefreshToken stores a new refresh token for a session and returns it
+func (h *AuthHandler) createRefreshToken(ctx context.Context, q *sqlc.Queries, sessionID uuid.UUID, expiresAt time.Time) (string, error) {
+	token, err := auth.GenerateSecureToken(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	if err := q.CreateRefreshToken(ctx, sqlc.CreateRefreshTokenParams{
+		SessionID: sessionID,
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return "", fmt.Errorf("failed to store refresh token: %w", err)
+	}
+	return token, nil
+}
+
+// tokenResponse pairs a fresh access token for the session with a refresh token
+func (h *AuthHandler) tokenResponse(user *sqlc.User, sessionID uuid.UUID, refreshToken string) (TokenResponse, error) {
+	accessToken, err := h.jwtService.GenerateToken(user.ID, user.Username, user.Role, sessionID)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	return TokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "bearer",
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(h.jwtService.GetExpiration().Seconds()),
+	}, nil
+}
+
+// errRefreshTokenReused means a refresh token was presented after it had been used
+var errRefreshTokenReused = errors.New("refresh token reused")
+
+// Refresh handles POST /api/auth/refresh
+// Each refresh token is single-use. Presenting one again means it has leaked,
+// so the whole session it belongs to is revoked.
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Cookie-mode clients send no body; the token comes from the refresh cookie
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	if req.RefreshToken == "" && h.config.AuthCookieMode {
+		if cookie, err := r.Cookie(middleware.RefreshCookieName); err == nil {
+			if !middleware.ValidCSRF(r) {
+				response.Forbidden(w, "Invalid CSRF token")
+				return
+			}
+			req.RefreshToken = cookie.Value
+		}
+	}
+
+	if req.RefreshToken == "" {
+		response.BadRequest(w, "refresh_token is required")
+		return
+	}
+
+	rt, err := h.db.Queries.GetRefreshTokenByHash(ctx, auth.HashToken(req.RefreshToken))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.Unauthorized(w, "Invalid refresh token")
+			return
+		}
+		log.Printf("Error getting refresh token: %v", err)
+		response.InternalServerError(w, "Internal server error")
+		return
+	}
+
+	if rt.UsedAt != nil {
+		h.revokeRefreshFamily(ctx, rt.SessionID, rt.UserID)
+		response.Unauthorized(w, "Refresh token has already been used")
+		return
+	}
+
+	if rt.SessionRevokedAt != nil {
+		response.Unauthorized(w, "Session has been revoked")
+		return
+	}
+
+	if time.Now().After(rt.ExpiresAt) {
+		response.Unauthorized(w, "Refresh token has expired")
+		return
 	}
 
-	// Generate token
-	token, err := h.jwtService.GenerateToken(user.ID, user.Username, user.Role)
+	user, err := h.db.Queries.GetUserByID(ctx, rt.UserID)
+	if err != nil {
+		log.Printf("Error getting user: %v", err)
+		response.InternalServerError(w, "Internal server error")
+		return
+	}
+
+	if !user.IsActive {
+		response.Unauthorized(w, "Account is deactivated")
+		return
+	}
+
+	// Rotate: consume the presented token, issue its successor and slide the session expiry
+	expiresAt := time.Now().Add(h.config.RefreshTokenExpiry)
+	var refreshToken string
+	err = h.db.InTx(ctx, func(q *sqlc.Queries) error {
+		if _, err := q.ConsumeRefreshToken(ctx, rt.ID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				// Lost a race with another request presenting the same token
+				return errRefreshTokenReused
+			}
+			return err
+		}
+
+		if err := q.ExtendUserSession(ctx, sqlc.ExtendUserSessionParams{
+			ID:        rt.SessionID,
+			ExpiresAt: expiresAt,
+		}); err != nil {
+			return err
+		}
+
+		var err error
+		refreshToken, err = h.createRefreshToken(ctx, q, rt.SessionID, expiresAt)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, errRefreshTokenReused) {
+			h.revokeRefreshFamily(ctx, rt.SessionID, rt.UserID)
+			response.Unauthorized(w, "Refresh token has already been used")
+			return
+		}
+		log.Printf("Error rotating refresh token: %v", err)
+		response.InternalServerError(w, "Failed to refresh token")
+		return
+	}
+
+	tokens, err := h.tokenResponse(&user, rt.SessionID, refreshToken)
 	if err != nil {
 		log.Printf("Error generating token: %v", err)
 		response.InternalServerError(w, "Failed to generate token")
 		return
 	}
 
-	response.Created(w, h.userToResponse(ctx, &user, true))
+	response.OK(w, h.deliverTokens(w, r, tokens))
+}
+
+// revokeRefreshFamily revokes the session behind a reused refresh token
+func (h *AuthHandler) revokeRefreshFamily(ctx context.Context, sessionID, userID uuid.UUID) {
+	log.Printf("Refresh token reuse detected for session %s of user %s, revoking session", sessionID, userID)
+	if err := h.sessions.Revoke(ctx, sessionID, userID); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("Error revoking session %s: %v", sessionID, err)
+	}
+}
 
-	// Also return the token in a header for immediate use
-	w.Header().Set("X-Auth-Token", token)
+// clientIP returns the caller's address, preferring the one set by the reverse proxy
+func clientIP(r *http.Request) string {
+	if ip := r.Header.Get("X-Real-IP"); ip != "" {
+		return ip
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// MediaToken handles GET /api/auth/media-token
+// Issues a token that can only load thumbnails, category images and avatars,
+// for <img src> URLs that can't send an Authorization header.
+func (h *AuthHandler) MediaToken(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserClaims(r.Context())
+	if !ok {
+		response.BadRequest(w, "Media tokens require a login session")
+		return
+	}
+
+	sessionID, err := claims.SessionID()
+	if err != nil {
+		response.Unauthorized(w, "Invalid token")
+		return
+	}
+
+	token, err := h.jwtService.GenerateMediaToken(claims.UserID, claims.Username, claims.Role, sessionID, mediaTokenExpiry)
+	if err != nil {
+		log.Printf("Error generating media token: %v", err)
+		response.InternalServerError(w, "Failed to generate token")
+		return
+	}
+
+	response.OK(w, MediaTokenResponse{
+		Token:     token,
+		ExpiresIn: int64(mediaTokenExpiry.Seconds()),
+		ExpiresAt: time.Now().Add(mediaTokenExpiry),
+	})
 }
 
 // Me handles GET /api/auth/me
@@ -372,8 +660,8 @@ func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(req.Password) < 8 {
-		response.BadRequest(w, "Password must be at least 8 characters")
+	if err := auth.ValidatePassword(passwordPolicy(h.config), req.Password); err != nil {
+		response.BadRequest(w, err.Error())
 		return
 	}
 
@@ -416,27 +704,362 @@ func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 		// Don't fail the request
 	}
 
+	// Sign out everywhere, in case the old password was compromised
+	if err := h.sessions.RevokeAll(ctx, resetToken.UserID); err != nil {
+		log.Printf("Error revoking sessions after password reset: %v", err)
+		// Don't fail the request
+	}
+
 	response.OK(w, map[string]string{
 		"message": "Password has been reset successfully",
 	})
 }
 
+// ListSessions handles GET /api/auth/sessions
+func (h *AuthHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+
+	var currentID uuid.UUID
+	if claims, ok := middleware.GetUserClaims(ctx); ok {
+		currentID, _ = claims.SessionID()
+	}
+
+	sessions, err := h.db.Queries.ListActiveUserSessions(ctx, userID)
+	if err != nil {
+		log.Printf("Error listing sessions: %v", err)
+		response.InternalServerError(w, "Failed to list sessions")
+		return
+	}
+
+	result := make([]SessionResponse, len(sessions))
+	for i, s := range sessions {
+		result[i] = SessionResponse{
+			ID:         s.ID.String(),
+			UserAgent:  s.UserAgent,
+			IPAddress:  s.IpAddress,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			ExpiresAt:  s.ExpiresAt,
+			Current:    s.ID == currentID,
+		}
+	}
+
+	response.OK(w, result)
+}
+
+// RevokeSession handles DELETE /api/auth/sessions/{session_id}
+func (h *AuthHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+
+	sessionID, err := uuid.Parse(r.PathValue("session_id"))
+	if err != nil {
+		response.BadRequest(w, "Invalid session ID format")
+		return
+	}
+
+	if err := h.sessions.Revoke(ctx, sessionID, userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.NotFound(w, "Session not found")
+			return
+		}
+		log.Printf("Error revoking session: %v", err)
+		response.InternalServerError(w, "Failed to revoke session")
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// LogoutAll handles POST /api/auth/logout-all
+// Revokes every session of the caller, including the current one
+func (h *AuthHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+
+	if err := h.sessions.RevokeAll(ctx, userID); err != nil {
+		log.Printf("Error revoking sessions: %v", err)
+		response.InternalServerError(w, "Failed to log out")
+		return
+	}
+
+	h.clearAuthCookies(w)
+
+	response.OK(w, map[string]string{
+		"message": "Logged out of all sessions",
+	})
+}
+
+// Logout handles POST /api/auth/logout
+// Revokes the session the request was made with and clears auth cookies.
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	claims, ok := middleware.GetUserClaims(ctx)
+	if !ok {
+		response.BadRequest(w, "Personal access tokens can't be logged out; delete the token instead")
+		return
+	}
+
+	sessionID, err := claims.SessionID()
+	if err != nil {
+		response.Unauthorized(w, "Invalid token")
+		return
+	}
+
+	if err := h.sessions.Revoke(ctx, sessionID, claims.UserID); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("Error revoking session %s: %v", sessionID, err)
+		response.InternalServerError(w, "Failed to log out")
+		return
+	}
+
+	h.clearAuthCookies(w)
+
+	response.OK(w, map[string]string{
+		"message": "Logged out",
+	})
+}
+
+// CSRFToken handles GET /api/auth/csrf
+// Issues the double-submit token cookie-mode clients echo in the X-CSRF-Token
+// header on mutating requests. An existing token is reused so open tabs agree.
+func (h *AuthHandler) CSRFToken(w http.ResponseWriter, r *http.Request) {
+	token, err := h.ensureCSRFCookie(w, r)
+	if err != nil {
+		log.Printf("Error generating CSRF token: %v", err)
+		response.InternalServerError(w, "Failed to generate CSRF token")
+		return
+	}
+
+	response.OK(w, map[string]string{
+		"csrf_token": token,
+	})
+}
+
+// deliverTokens hands issued tokens to the client. In cookie mode they are set
+// as httpOnly cookies and the returned response omits them.
+func (h *AuthHandler) deliverTokens(w http.ResponseWriter, r *http.Request, tokens TokenResponse) TokenResponse {
+	if !h.config.AuthCookieMode {
+		return tokens
+	}
+
+	http.SetCookie(w, h.authCookie(middleware.AuthCookieName, "/", tokens.AccessToken, int(tokens.ExpiresIn)))
+	http.SetCookie(w, h.authCookie(middleware.RefreshCookieName, "/api/auth", tokens.RefreshToken, int(h.config.RefreshTokenExpiry.Seconds())))
+	if _, err := h.ensureCSRFCookie(w, r); err != nil {
+		// The client can still fetch one from /api/auth/csrf
+		log.Printf("Error generating CSRF token: %v", err)
+	}
+
+	return TokenResponse{
+		TokenType: "cookie",
+		ExpiresIn: tokens.ExpiresIn,
+	}
+}
+
+// ensureCSRFCookie returns the request's CSRF token, setting a new cookie if it has none
+func (h *AuthHandler) ensureCSRFCookie(w http.ResponseWriter, r *http.Request) (string, error) {
+	if cookie, err := r.Cookie(middleware.CSRFCookieName); err == nil && cookie.Value != "" {
+		return cookie.Value, nil
+	}
+
+	token, err := auth.GenerateSecureToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	cookie := h.authCookie(middleware.CSRFCookieName, "/", token, int(h.config.RefreshTokenExpiry.Seconds()))
+	cookie.HttpOnly = false // Scripts must read it to send it back in the header
+	http.SetCookie(w, cookie)
+	return token, nil
+}
+
+// clearAuthCookies removes the auth and refresh cookies in cookie mode
+func (h *AuthHandler) clearAuthCookies(w http.ResponseWriter) {
+	if !h.config.AuthCookieMode {
+		return
+	}
+	http.SetCookie(w, h.authCookie(middleware.AuthCookieName, "/", "", -1))
+	http.SetCookie(w, h.authCookie(middleware.RefreshCookieName, "/api/auth", "", -1))
+}
+
+// authCookie builds an httpOnly cookie with the configured security attributes
+func (h *AuthHandler) authCookie(name, path, value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   h.config.AuthCookieSecure,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// ConfirmEmailChange handles POST /api/auth/confirm-email-change
+func (h *AuthHandler) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		response.BadRequest(w, "Token is required")
+		return
+	}
+
+	ctx := r.Context()
+	tokenHash := auth.HashToken(token)
+
+	// Get and validate change request
+	changeRequest, err := h.db.Queries.GetValidEmailChangeByHash(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.BadRequest(w, "Invalid or expired token")
+			return
+		}
+		log.Printf("Error getting email change request: %v", err)
+		response.InternalServerError(w, "Internal server error")
+		return
+	}
+
+	// The address may have been taken since the change was requested
+	emailExists, err := h.db.Queries.UserExistsByEmail(ctx, changeRequest.NewEmail)
+	if err != nil {
+		log.Printf("Error checking email: %v", err)
+		response.InternalServerError(w, "Internal server error")
+		return
+	}
+	if emailExists {
+		response.Conflict(w, "Email already registered")
+		return
+	}
+
+	err = h.db.InTx(ctx, func(q *sqlc.Queries) error {
+		if err := q.UpdateUserEmail(ctx, sqlc.UpdateUserEmailParams{
+			ID:    changeRequest.UserID,
+			Email: changeRequest.NewEmail,
+		}); err != nil {
+			return err
+		}
+		return q.DeleteEmailChangeRequest(ctx, changeRequest.ID)
+	})
+	if err != nil {
+		log.Printf("Error applying email change: %v", err)
+		response.InternalServerError(w, "Failed to change email")
+		return
+	}
+
+	log.Printf("Changed email for user %s", changeRequest.UserID)
+
+	response.OK(w, map[string]string{
+		"message": "Email has been changed successfully",
+	})
+}
+
+// ChangeUsername handles PATCH /api/users/me/username
+// Tokens issued before the change keep working; they carry the old username
+// claim, but handlers resolve the user by ID.
+func (h *AuthHandler) ChangeUsername(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+
+	var req UsernameChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	newUsername := strings.ToLower(strings.TrimSpace(req.Username))
+	if len(newUsername) < 3 || len(newUsername) > 50 {
+		response.BadRequest(w, "Username must be between 3 and 50 characters")
+		return
+	}
+
+	user, err := h.db.Queries.GetUserByID(ctx, userID)
+	if err != nil {
+		log.Printf("Error getting user: %v", err)
+		response.InternalServerError(w, "Failed to get user")
+		return
+	}
+
+	if newUsername == strings.ToLower(user.Username) {
+		response.BadRequest(w, "New username must differ from the current one")
+		return
+	}
+
+	if user.UsernameChangedAt != nil && time.Since(*user.UsernameChangedAt) < usernameChangeCooldown {
+		response.Error(w, http.StatusTooManyRequests, "Username can only be changed once every 30 days")
+		return
+	}
+
+	usernameExists, err := h.db.Queries.UserExistsByUsername(ctx, newUsername)
+	if err != nil {
+		log.Printf("Error checking username: %v", err)
+		response.InternalServerError(w, "Internal server error")
+		return
+	}
+	if usernameExists {
+		response.Conflict(w, "Username already taken")
+		return
+	}
+
+	updatedUser, err := h.db.Queries.UpdateUsername(ctx, sqlc.UpdateUsernameParams{
+		ID:       userID,
+		Username: newUsername,
+	})
+	if err != nil {
+		log.Printf("Error changing username: %v", err)
+		response.InternalServerError(w, "Failed to change username")
+		return
+	}
+
+	log.Printf("User %s changed username from %s to %s", userID, user.Username, updatedUser.Username)
+
+	// The caller's current token still says the old username
+	tokens, err := h.issueTokens(r, &updatedUser)
+	if err != nil {
+		log.Printf("Error generating token: %v", err)
+		response.InternalServerError(w, "Failed to generate token")
+		return
+	}
+
+	response.OK(w, UsernameChangeResponse{
+		User:          h.userToResponse(ctx, &updatedUser, true),
+		TokenResponse: h.deliverTokens(w, r, tokens),
+	})
+}
+
 // Helper to convert user to response
 func (h *AuthHandler) userToResponse(ctx context.Context, user *sqlc.User, includeQuota bool) UserResponse {
 	resp := UserResponse{
-		ID:        user.ID.String(),
-		Email:     user.Email,
-		Username:  user.Username,
-		Role:      string(user.Role),
-		CreatedAt: user.CreatedAt,
-		IsActive:  user.IsActive,
+		ID:          user.ID.String(),
+		Email:       user.Email,
+		Username:    user.Username,
+		DisplayName: displayNameOrUsername(user.DisplayName, user.Username),
+		Role:        string(user.Role),
+		CreatedAt:   user.CreatedAt,
+		IsActive:    user.IsActive,
 	}
 
 	// Avatar URL
-	if user.AvatarFilename != nil {
-		avatarURL := "/api/users/" + user.ID.String() + "/avatar"
-		resp.AvatarURL = &avatarURL
-	}
+	resp.AvatarURL = buildAvatarURL(user.ID, user.AvatarFilename)
 
 	// Get counts
 	videoCount, _ := h.db.Queries.CountUserVideos(ctx, user.ID)
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"log"
 	"net/http"
-	"os"
 	"regexp"
 	"strings"
 	"time"
@@ -741,21 +740,9 @@ func (h *CategoriesHandler) serveImageInternal(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	// Get image file path
+	// Serve the file, cached for 1 year
 	imagePath := h.imageProcessor.GetCategoryImagePath(*category.ImageFilename)
-
-	// Check if file exists
-	if _, err := os.Stat(imagePath); os.IsNotExist(err) {
-		response.NotFound(w, "Image file not found")
-		return
-	}
-
-	// Set cache headers (1 year)
-	w.Header().Set("Cache-Control", "public, max-age=31536000")
-	w.Header().Set("Content-Type", "image/jpeg")
-
-	// Serve the file
-	http.ServeFile(w, r, imagePath)
+	serveImageFile(w, r, imagePath, "category image", "public, max-age=31536000")
 }
 
 // ServeImage handles GET /api/categories/{category_id}/image
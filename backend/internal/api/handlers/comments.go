@@ -1,12 +1,16 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,25 +22,80 @@ import (
 	"github.com/clipset/clipset-go/internal/config"
 	"github.com/clipset/clipset-go/internal/db"
 	"github.com/clipset/clipset-go/internal/db/sqlc"
+	"github.com/clipset/clipset-go/internal/services/markdown"
+	"github.com/clipset/clipset-go/internal/services/ratelimit"
 )
 
-// Comment edit window duration
-const commentEditWindowHours = 24
+// How long comment settings from the DB config are cached
+const commentSettingsTTL = 30 * time.Second
 
 // Comment edit threshold in seconds (edits within this window don't count as "edited")
 const commentEditThresholdSeconds = 60
 
+// commentSettings are the site-wide comment settings from the DB config
+type commentSettings struct {
+	Enabled       bool
+	MaxLength     int
+	EditWindow    time.Duration
+	RatePerMinute int
+	RatePerHour   int
+}
+
+// Cached comment settings, shared so config updates can invalidate them
+var commentSettingsCache struct {
+	mu       sync.Mutex
+	settings commentSettings
+	loadedAt time.Time
+}
+
+// loadCommentSettings returns the comment settings, reading the DB config at
+// most once per commentSettingsTTL
+func loadCommentSettings(ctx context.Context, queries *sqlc.Queries) (commentSettings, error) {
+	commentSettingsCache.mu.Lock()
+	defer commentSettingsCache.mu.Unlock()
+
+	if !commentSettingsCache.loadedAt.IsZero() && time.Since(commentSettingsCache.loadedAt) < commentSettingsTTL {
+		return commentSettingsCache.settings, nil
+	}
+
+	dbConfig, err := queries.GetConfig(ctx)
+	if err != nil {
+		return commentSettings{}, err
+	}
+
+	commentSettingsCache.settings = commentSettings{
+		Enabled:       dbConfig.CommentsEnabled,
+		MaxLength:     int(dbConfig.CommentMaxLength),
+		EditWindow:    time.Duration(dbConfig.CommentEditWindowHours) * time.Hour,
+		RatePerMinute: int(dbConfig.CommentRatePerMinute),
+		RatePerHour:   int(dbConfig.CommentRatePerHour),
+	}
+	commentSettingsCache.loadedAt = time.Now()
+	return commentSettingsCache.settings, nil
+}
+
+// invalidateCommentSettings makes the next loadCommentSettings read the DB config
+func invalidateCommentSettings() {
+	commentSettingsCache.mu.Lock()
+	defer commentSettingsCache.mu.Unlock()
+	commentSettingsCache.loadedAt = time.Time{}
+}
+
 // CommentsHandler handles comment management endpoints
 type CommentsHandler struct {
-	db     *db.DB
-	config *config.Config
+	db       *db.DB
+	config   *config.Config
+	renderer markdown.Renderer
+	limiter  ratelimit.Limiter
 }
 
 // NewCommentsHandler creates a new comments handler
 func NewCommentsHandler(database *db.DB, cfg *config.Config) *CommentsHandler {
 	return &CommentsHandler{
-		db:     database,
-		config: cfg,
+		db:       database,
+		config:   cfg,
+		renderer: markdown.NewCommentRenderer(),
+		limiter:  ratelimit.NewMemoryLimiter(),
 	}
 }
 
@@ -44,21 +103,23 @@ func NewCommentsHandler(database *db.DB, cfg *config.Config) *CommentsHandler {
 
 // CommentResponse represents a comment with computed fields
 type CommentResponse struct {
-	ID               string            `json:"id"`
-	VideoID          string            `json:"video_id"`
-	Content          string            `json:"content"`
-	TimestampSeconds *int32            `json:"timestamp_seconds"`
-	ParentID         *string           `json:"parent_id"`
-	UserID           string            `json:"user_id"`
-	AuthorUsername   string            `json:"author_username"`
-	AuthorAvatarURL  *string           `json:"author_avatar_url"`
-	CreatedAt        time.Time         `json:"created_at"`
-	UpdatedAt        time.Time         `json:"updated_at"`
-	IsEdited         bool              `json:"is_edited"`
-	CanEdit          bool              `json:"can_edit"`
-	CanDelete        bool              `json:"can_delete"`
-	ReplyCount       int64             `json:"reply_count"`
-	Replies          []CommentResponse `json:"replies"`
+	ID                string            `json:"id"`
+	VideoID           string            `json:"video_id"`
+	Content           string            `json:"content"`
+	ContentHTML       string            `json:"content_html"`
+	TimestampSeconds  *int32            `json:"timestamp_seconds"`
+	ParentID          *string           `json:"parent_id"`
+	UserID            string            `json:"user_id"`
+	AuthorUsername    string            `json:"author_username"`
+	AuthorDisplayName string            `json:"author_display_name"`
+	AuthorAvatarURL   *string           `json:"author_avatar_url"`
+	CreatedAt         time.Time         `json:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+	IsEdited          bool              `json:"is_edited"`
+	CanEdit           bool              `json:"can_edit"`
+	CanDelete         bool              `json:"can_delete"`
+	ReplyCount        int64             `json:"reply_count"`
+	Replies           []CommentResponse `json:"replies"`
 }
 
 // CommentListResponse represents a paginated list of comments
@@ -97,17 +158,22 @@ func isEdited(createdAt, updatedAt time.Time) bool {
 	return updatedAt.Sub(createdAt).Seconds() > commentEditThresholdSeconds
 }
 
-// canEditComment checks if user can edit the comment (author only, within 24h)
-func canEditComment(commentUserID, currentUserID uuid.UUID, createdAt time.Time) bool {
+// canEditComment checks if user can edit the comment (author only, within the edit window)
+func canEditComment(commentUserID, currentUserID uuid.UUID, createdAt time.Time, editWindow time.Duration) bool {
 	if commentUserID != currentUserID {
 		return false
 	}
-	return time.Since(createdAt) < time.Duration(commentEditWindowHours)*time.Hour
+	return time.Since(createdAt) < editWindow
+}
+
+// formatEditWindow formats an edit window in hours, e.g. "24h"
+func formatEditWindow(window time.Duration) string {
+	return fmt.Sprintf("%dh", int(window.Hours()))
 }
 
-// canDeleteComment checks if user can delete the comment (author, video owner, or admin)
-func canDeleteComment(commentUserID, videoOwnerID, currentUserID uuid.UUID, isAdmin bool) bool {
-	if isAdmin {
+// canDeleteComment checks if user can delete the comment (author, video owner, or moderator/admin)
+func canDeleteComment(commentUserID, videoOwnerID, currentUserID uuid.UUID, isModerator bool) bool {
+	if isModerator {
 		return true
 	}
 	if commentUserID == currentUserID {
@@ -129,82 +195,99 @@ func pgUUIDToString(u pgtype.UUID) *string {
 }
 
 // buildCommentResponseFromListRow builds a CommentResponse from a ListCommentsByVideoRow
-func buildCommentResponseFromListRow(
+func (h *CommentsHandler) buildCommentResponseFromListRow(
 	row sqlc.ListCommentsByVideoRow,
 	currentUserID, videoOwnerID uuid.UUID,
-	isAdmin bool,
+	isModerator bool,
+	editWindow time.Duration,
 	replies []CommentResponse,
 ) CommentResponse {
 	return CommentResponse{
-		ID:               row.ID.String(),
-		VideoID:          row.VideoID.String(),
-		Content:          row.Content,
-		TimestampSeconds: row.TimestampSeconds,
-		ParentID:         pgUUIDToString(row.ParentID),
-		UserID:           row.UserID.String(),
-		AuthorUsername:   row.AuthorUsername,
-		AuthorAvatarURL:  buildAvatarURL(row.AuthorAvatar),
-		CreatedAt:        row.CreatedAt,
-		UpdatedAt:        row.UpdatedAt,
-		IsEdited:         isEdited(row.CreatedAt, row.UpdatedAt),
-		CanEdit:          canEditComment(row.UserID, currentUserID, row.CreatedAt),
-		CanDelete:        canDeleteComment(row.UserID, videoOwnerID, currentUserID, isAdmin),
-		ReplyCount:       row.ReplyCount,
-		Replies:          replies,
+		ID:                row.ID.String(),
+		VideoID:           row.VideoID.String(),
+		Content:           row.Content,
+		ContentHTML:       h.renderer.Render(row.Content),
+		TimestampSeconds:  row.TimestampSeconds,
+		ParentID:          pgUUIDToString(row.ParentID),
+		UserID:            row.UserID.String(),
+		AuthorUsername:    row.AuthorUsername,
+		AuthorDisplayName: row.AuthorDisplayName,
+		AuthorAvatarURL:   buildAvatarURL(row.UserID, row.AuthorAvatar),
+		CreatedAt:         row.CreatedAt,
+		UpdatedAt:         row.UpdatedAt,
+		IsEdited:          isEdited(row.CreatedAt, row.UpdatedAt),
+		CanEdit:           canEditComment(row.UserID, currentUserID, row.CreatedAt, editWindow),
+		CanDelete:         canDeleteComment(row.UserID, videoOwnerID, currentUserID, isModerator),
+		ReplyCount:        row.ReplyCount,
+		Replies:           replies,
 	}
 }
 
 // buildCommentResponseFromReplyRow builds a CommentResponse from a ListRepliesByCommentRow
-func buildCommentResponseFromReplyRow(
+func (h *CommentsHandler) buildCommentResponseFromReplyRow(
 	row sqlc.ListRepliesByCommentRow,
 	currentUserID, videoOwnerID uuid.UUID,
-	isAdmin bool,
+	isModerator bool,
+	editWindow time.Duration,
 ) CommentResponse {
 	return CommentResponse{
-		ID:               row.ID.String(),
-		VideoID:          row.VideoID.String(),
-		Content:          row.Content,
-		TimestampSeconds: row.TimestampSeconds,
-		ParentID:         pgUUIDToString(row.ParentID),
-		UserID:           row.UserID.String(),
-		AuthorUsername:   row.AuthorUsername,
-		AuthorAvatarURL:  buildAvatarURL(row.AuthorAvatar),
-		CreatedAt:        row.CreatedAt,
-		UpdatedAt:        row.UpdatedAt,
-		IsEdited:         isEdited(row.CreatedAt, row.UpdatedAt),
-		CanEdit:          canEditComment(row.UserID, currentUserID, row.CreatedAt),
-		CanDelete:        canDeleteComment(row.UserID, videoOwnerID, currentUserID, isAdmin),
-		ReplyCount:       0,
-		Replies:          nil,
+		ID:                row.ID.String(),
+		VideoID:           row.VideoID.String(),
+		Content:           row.Content,
+		ContentHTML:       h.renderer.Render(row.Content),
+		TimestampSeconds:  row.TimestampSeconds,
+		ParentID:          pgUUIDToString(row.ParentID),
+		UserID:            row.UserID.String(),
+		AuthorUsername:    row.AuthorUsername,
+		AuthorDisplayName: row.AuthorDisplayName,
+		AuthorAvatarURL:   buildAvatarURL(row.UserID, row.AuthorAvatar),
+		CreatedAt:         row.CreatedAt,
+		UpdatedAt:         row.UpdatedAt,
+		IsEdited:          isEdited(row.CreatedAt, row.UpdatedAt),
+		CanEdit:           canEditComment(row.UserID, currentUserID, row.CreatedAt, editWindow),
+		CanDelete:         canDeleteComment(row.UserID, videoOwnerID, currentUserID, isModerator),
+		ReplyCount:        0,
+		Replies:           nil,
 	}
 }
 
 // buildCommentResponseFromWithAuthorRow builds a CommentResponse from GetCommentWithAuthorRow
-func buildCommentResponseFromWithAuthorRow(
+func (h *CommentsHandler) buildCommentResponseFromWithAuthorRow(
 	row sqlc.GetCommentWithAuthorRow,
 	currentUserID uuid.UUID,
-	isAdmin bool,
+	isModerator bool,
+	editWindow time.Duration,
 	replyCount int64,
 ) CommentResponse {
 	return CommentResponse{
-		ID:               row.ID.String(),
-		VideoID:          row.VideoID.String(),
-		Content:          row.Content,
-		TimestampSeconds: row.TimestampSeconds,
-		ParentID:         pgUUIDToString(row.ParentID),
-		UserID:           row.UserID.String(),
-		AuthorUsername:   row.AuthorUsername,
-		AuthorAvatarURL:  buildAvatarURL(row.AuthorAvatar),
-		CreatedAt:        row.CreatedAt,
-		UpdatedAt:        row.UpdatedAt,
-		IsEdited:         isEdited(row.CreatedAt, row.UpdatedAt),
-		CanEdit:          canEditComment(row.UserID, currentUserID, row.CreatedAt),
-		CanDelete:        canDeleteComment(row.UserID, row.VideoOwnerID, currentUserID, isAdmin),
-		ReplyCount:       replyCount,
-		Replies:          nil,
+		ID:                row.ID.String(),
+		VideoID:           row.VideoID.String(),
+		Content:           row.Content,
+		ContentHTML:       h.renderer.Render(row.Content),
+		TimestampSeconds:  row.TimestampSeconds,
+		ParentID:          pgUUIDToString(row.ParentID),
+		UserID:            row.UserID.String(),
+		AuthorUsername:    row.AuthorUsername,
+		AuthorDisplayName: row.AuthorDisplayName,
+		AuthorAvatarURL:   buildAvatarURL(row.UserID, row.AuthorAvatar),
+		CreatedAt:         row.CreatedAt,
+		UpdatedAt:         row.UpdatedAt,
+		IsEdited:          isEdited(row.CreatedAt, row.UpdatedAt),
+		CanEdit:           canEditComment(row.UserID, currentUserID, row.CreatedAt, editWindow),
+		CanDelete:         canDeleteComment(row.UserID, row.VideoOwnerID, currentUserID, isModerator),
+		ReplyCount:        replyCount,
+		Replies:           nil,
 	}
 }
 
+// checkCommentRateLimit applies the per-minute and per-hour limits from the comment settings
+func (h *CommentsHandler) checkCommentRateLimit(ctx context.Context, userID uuid.UUID, settings commentSettings) (bool, time.Duration, error) {
+	return h.limiter.Allow(ctx, "comments:"+userID.String(),
+		ratelimit.Limit{Max: settings.RatePerMinute, Period: time.Minute},
+		ratelimit.Limit{Max: settings.RatePerHour, Period: time.Hour},
+	)
+}
+
 // --- Handlers ---
 
 // ListByVideo handles GET /api/videos/{video_id}/comments
@@ -231,6 +314,7 @@ func (h *CommentsHandler) ListByVideo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	isAdmin := middleware.IsAdmin(ctx)
+	isModerator := middleware.IsModerator(ctx)
 
 	// Verify video exists and get owner
 	video, err := h.db.Queries.GetVideoByID(ctx, videoID)
@@ -243,6 +327,12 @@ func (h *CommentsHandler) ListByVideo(w http.ResponseWriter, r *http.Request) {
 		response.InternalServerError(w, "Failed to get video")
 		return
 	}
+
+	// Check access
+	if !hasVideoAccess(video, currentUserID, isAdmin) {
+		response.Forbidden(w, "You don't have permission to view this video")
+		return
+	}
 	videoOwnerID := video.UploadedBy
 
 	// Parse pagination parameters
@@ -293,25 +383,39 @@ func (h *CommentsHandler) ListByVideo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Fetch all replies for all top-level comments eagerly
+	settings, err := loadCommentSettings(ctx, h.db.Queries)
+	if err != nil {
+		log.Printf("Error loading comment settings: %v", err)
+		response.InternalServerError(w, "Failed to get comments")
+		return
+	}
+
+	// Fetch the first few replies for all top-level comments on this page in one query;
+	// the rest are loaded through GET /api/comments/{comment_id}/replies
 	// Build a map of parent_id -> replies
 	repliesMap := make(map[uuid.UUID][]CommentResponse)
 
-	for _, comment := range comments {
-		replies, err := h.db.Queries.ListRepliesByComment(ctx, pgtype.UUID{
-			Bytes: comment.ID,
-			Valid: true,
+	if len(comments) > 0 && h.config.CommentReplyPreviewCount > 0 {
+		parentIDs := make([]uuid.UUID, len(comments))
+		for i, comment := range comments {
+			parentIDs[i] = comment.ID
+		}
+
+		replies, err := h.db.Queries.ListRepliesByParents(ctx, sqlc.ListRepliesByParentsParams{
+			ParentIds: parentIDs,
+			PerParent: int32(h.config.CommentReplyPreviewCount),
 		})
 		if err != nil {
-			log.Printf("Error fetching replies for comment %s: %v", comment.ID, err)
-			continue
+			log.Printf("Error fetching replies: %v", err)
+			response.InternalServerError(w, "Failed to get comments")
+			return
 		}
 
-		replyResponses := make([]CommentResponse, len(replies))
-		for i, reply := range replies {
-			replyResponses[i] = buildCommentResponseFromReplyRow(reply, currentUserID, videoOwnerID, isAdmin)
+		for _, reply := range replies {
+			parentID := uuid.UUID(reply.ParentID.Bytes)
+			repliesMap[parentID] = append(repliesMap[parentID],
+				h.buildCommentResponseFromReplyRow(sqlc.ListRepliesByCommentRow(reply), currentUserID, videoOwnerID, isModerator, settings.EditWindow))
 		}
-		repliesMap[comment.ID] = replyResponses
 	}
 
 	// Build response
@@ -321,7 +425,7 @@ func (h *CommentsHandler) ListByVideo(w http.ResponseWriter, r *http.Request) {
 		if replies == nil {
 			replies = []CommentResponse{}
 		}
-		commentResponses[i] = buildCommentResponseFromListRow(comment, currentUserID, videoOwnerID, isAdmin, replies)
+		commentResponses[i] = h.buildCommentResponseFromListRow(comment, currentUserID, videoOwnerID, isModerator, settings.EditWindow, replies)
 	}
 
 	hasMore := total > int64(skip+limit)
@@ -333,6 +437,113 @@ func (h *CommentsHandler) ListByVideo(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// ListReplies handles GET /api/comments/{comment_id}/replies
+// Returns a page of replies (oldest first) for a top-level comment
+func (h *CommentsHandler) ListReplies(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Parse comment_id
+	commentIDStr := r.PathValue("comment_id")
+	if commentIDStr == "" {
+		response.BadRequest(w, "Comment ID is required")
+		return
+	}
+
+	commentID, err := uuid.Parse(commentIDStr)
+	if err != nil {
+		response.BadRequest(w, "Invalid comment ID format")
+		return
+	}
+
+	// Get current user
+	currentUserID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+	isAdmin := middleware.IsAdmin(ctx)
+	isModerator := middleware.IsModerator(ctx)
+
+	// Get parent comment (includes video owner for can_delete)
+	parent, err := h.db.Queries.GetCommentWithAuthor(ctx, commentID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.NotFound(w, "Comment not found")
+			return
+		}
+		log.Printf("Error getting comment: %v", err)
+		response.InternalServerError(w, "Failed to get comment")
+		return
+	}
+
+	// Replies follow the access rule of the video they belong to
+	video, err := h.db.Queries.GetVideoByID(ctx, parent.VideoID)
+	if err != nil {
+		log.Printf("Error getting video: %v", err)
+		response.InternalServerError(w, "Failed to get video")
+		return
+	}
+	if !hasVideoAccess(video, currentUserID, isAdmin) {
+		response.Forbidden(w, "You don't have permission to view this video")
+		return
+	}
+
+	// Parse pagination parameters
+	skip := 0
+	limit := 20
+
+	if skipStr := r.URL.Query().Get("skip"); skipStr != "" {
+		if s, err := strconv.Atoi(skipStr); err == nil && s >= 0 {
+			skip = s
+		}
+	}
+
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l >= 1 && l <= 100 {
+			limit = l
+		}
+	}
+
+	parentID := pgtype.UUID{Bytes: parent.ID, Valid: true}
+
+	replies, err := h.db.Queries.ListRepliesByComment(ctx, sqlc.ListRepliesByCommentParams{
+		ParentID: parentID,
+		Limit:    int32(limit),
+		Offset:   int32(skip),
+	})
+	if err != nil {
+		log.Printf("Error listing replies: %v", err)
+		response.InternalServerError(w, "Failed to get replies")
+		return
+	}
+
+	total, err := h.db.Queries.CountRepliesByComment(ctx, parentID)
+	if err != nil {
+		log.Printf("Error counting replies: %v", err)
+		response.InternalServerError(w, "Failed to count replies")
+		return
+	}
+
+	settings, err := loadCommentSettings(ctx, h.db.Queries)
+	if err != nil {
+		log.Printf("Error loading comment settings: %v", err)
+		response.InternalServerError(w, "Failed to get replies")
+		return
+	}
+
+	// Build response
+	replyResponses := make([]CommentResponse, len(replies))
+	for i, reply := range replies {
+		replyResponses[i] = h.buildCommentResponseFromReplyRow(reply, currentUserID, parent.VideoOwnerID, isModerator, settings.EditWindow)
+	}
+
+	response.OK(w, CommentListResponse{
+		Comments: replyResponses,
+		Total:    total,
+		HasMore:  total > int64(skip+limit),
+	})
+}
+
 // Create handles POST /api/videos/{video_id}/comments
 func (h *CommentsHandler) Create(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -356,8 +567,8 @@ func (h *CommentsHandler) Create(w http.ResponseWriter, r *http.Request) {
 		response.Unauthorized(w, "Not authenticated")
 		return
 	}
-	currentUsername, _ := middleware.GetUsername(ctx)
 	isAdmin := middleware.IsAdmin(ctx)
+	isModerator := middleware.IsModerator(ctx)
 
 	// Verify video exists and get owner
 	video, err := h.db.Queries.GetVideoByID(ctx, videoID)
@@ -370,8 +581,31 @@ func (h *CommentsHandler) Create(w http.ResponseWriter, r *http.Request) {
 		response.InternalServerError(w, "Failed to get video")
 		return
 	}
+
+	// Check access
+	if !hasVideoAccess(video, currentUserID, isAdmin) {
+		response.Forbidden(w, "You don't have permission to view this video")
+		return
+	}
 	videoOwnerID := video.UploadedBy
 
+	settings, err := loadCommentSettings(ctx, h.db.Queries)
+	if err != nil {
+		log.Printf("Error loading comment settings: %v", err)
+		response.InternalServerError(w, "Failed to create comment")
+		return
+	}
+
+	// Comments are turned off site-wide or by the owner; existing comments stay visible
+	if !settings.Enabled {
+		response.Forbidden(w, "Comments are disabled")
+		return
+	}
+	if !video.CommentsEnabled {
+		response.Forbidden(w, "Comments are disabled for this video")
+		return
+	}
+
 	// Parse request body
 	var req CommentCreateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -385,8 +619,8 @@ func (h *CommentsHandler) Create(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Content is required")
 		return
 	}
-	if len(content) > 2000 {
-		response.BadRequest(w, "Content must be 2000 characters or less")
+	if len(content) > settings.MaxLength {
+		response.BadRequest(w, fmt.Sprintf("Content must be %d characters or less", settings.MaxLength))
 		return
 	}
 
@@ -396,6 +630,12 @@ func (h *CommentsHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Duration is unknown while processing; markers filter out-of-range timestamps later
+	if req.TimestampSeconds != nil && video.DurationSeconds != nil && *req.TimestampSeconds > *video.DurationSeconds {
+		response.BadRequest(w, fmt.Sprintf("Timestamp must be between 0 and %d seconds", *video.DurationSeconds))
+		return
+	}
+
 	// Handle parent_id for replies
 	var parentID pgtype.UUID
 	if req.ParentID != nil && *req.ParentID != "" {
@@ -432,6 +672,21 @@ func (h *CommentsHandler) Create(w http.ResponseWriter, r *http.Request) {
 		parentID = pgtype.UUID{Bytes: parsedParentID, Valid: true}
 	}
 
+	// Rate limit non-admins (replies count the same as top-level comments)
+	if !isAdmin {
+		allowed, retryAfter, err := h.checkCommentRateLimit(ctx, currentUserID, settings)
+		if err != nil {
+			log.Printf("Error checking comment rate limit: %v", err)
+			response.InternalServerError(w, "Failed to create comment")
+			return
+		}
+		if !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			response.Error(w, http.StatusTooManyRequests, "You are commenting too quickly, please try again later")
+			return
+		}
+	}
+
 	// Create comment
 	comment, err := h.db.Queries.CreateComment(ctx, sqlc.CreateCommentParams{
 		VideoID:          videoID,
@@ -454,27 +709,29 @@ func (h *CommentsHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	var avatarURL *string
 	if user.AvatarFilename != nil {
-		avatarURL = buildAvatarURL(user.AvatarFilename)
+		avatarURL = buildAvatarURL(user.ID, user.AvatarFilename)
 	}
 
 	log.Printf("Created comment %s on video %s by user %s", comment.ID, videoID, currentUserID)
 
 	response.Created(w, CommentResponse{
-		ID:               comment.ID.String(),
-		VideoID:          comment.VideoID.String(),
-		Content:          comment.Content,
-		TimestampSeconds: comment.TimestampSeconds,
-		ParentID:         pgUUIDToString(comment.ParentID),
-		UserID:           comment.UserID.String(),
-		AuthorUsername:   currentUsername,
-		AuthorAvatarURL:  avatarURL,
-		CreatedAt:        comment.CreatedAt,
-		UpdatedAt:        comment.UpdatedAt,
-		IsEdited:         false,
-		CanEdit:          canEditComment(comment.UserID, currentUserID, comment.CreatedAt),
-		CanDelete:        canDeleteComment(comment.UserID, videoOwnerID, currentUserID, isAdmin),
-		ReplyCount:       0,
-		Replies:          nil,
+		ID:                comment.ID.String(),
+		VideoID:           comment.VideoID.String(),
+		Content:           comment.Content,
+		ContentHTML:       h.renderer.Render(comment.Content),
+		TimestampSeconds:  comment.TimestampSeconds,
+		ParentID:          pgUUIDToString(comment.ParentID),
+		UserID:            comment.UserID.String(),
+		AuthorUsername:    user.Username,
+		AuthorDisplayName: displayNameOrUsername(user.DisplayName, user.Username),
+		AuthorAvatarURL:   avatarURL,
+		CreatedAt:         comment.CreatedAt,
+		UpdatedAt:         comment.UpdatedAt,
+		IsEdited:          false,
+		CanEdit:           canEditComment(comment.UserID, currentUserID, comment.CreatedAt, settings.EditWindow),
+		CanDelete:         canDeleteComment(comment.UserID, videoOwnerID, currentUserID, isModerator),
+		ReplyCount:        0,
+		Replies:           nil,
 	})
 }
 
@@ -501,7 +758,7 @@ func (h *CommentsHandler) Update(w http.ResponseWriter, r *http.Request) {
 		response.Unauthorized(w, "Not authenticated")
 		return
 	}
-	isAdmin := middleware.IsAdmin(ctx)
+	isModerator := middleware.IsModerator(ctx)
 
 	// Get comment with author and video owner
 	comment, err := h.db.Queries.GetCommentWithAuthor(ctx, commentID)
@@ -521,9 +778,21 @@ func (h *CommentsHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check time window: within 24 hours
-	if time.Since(comment.CreatedAt) >= time.Duration(commentEditWindowHours)*time.Hour {
-		response.Forbidden(w, "Comment editing window (24h) has expired")
+	settings, err := loadCommentSettings(ctx, h.db.Queries)
+	if err != nil {
+		log.Printf("Error loading comment settings: %v", err)
+		response.InternalServerError(w, "Failed to update comment")
+		return
+	}
+
+	if !settings.Enabled {
+		response.Forbidden(w, "Comments are disabled")
+		return
+	}
+
+	// Check time window
+	if time.Since(comment.CreatedAt) >= settings.EditWindow {
+		response.Forbidden(w, fmt.Sprintf("Comment editing window (%s) has expired", formatEditWindow(settings.EditWindow)))
 		return
 	}
 
@@ -540,15 +809,26 @@ func (h *CommentsHandler) Update(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Content is required")
 		return
 	}
-	if len(content) > 2000 {
-		response.BadRequest(w, "Content must be 2000 characters or less")
+	if len(content) > settings.MaxLength {
+		response.BadRequest(w, fmt.Sprintf("Content must be %d characters or less", settings.MaxLength))
 		return
 	}
 
-	// Update comment
-	updatedComment, err := h.db.Queries.UpdateComment(ctx, sqlc.UpdateCommentParams{
-		ID:      commentID,
-		Content: content,
+	// Update comment, keeping the previous content as a revision
+	var updatedComment sqlc.Comment
+	err = h.db.InTx(ctx, func(q *sqlc.Queries) error {
+		if err := q.CreateCommentRevision(ctx, sqlc.CreateCommentRevisionParams{
+			CommentID: commentID,
+			Content:   comment.Content,
+		}); err != nil {
+			return err
+		}
+		var err error
+		updatedComment, err = q.UpdateComment(ctx, sqlc.UpdateCommentParams{
+			ID:      commentID,
+			Content: content,
+		})
+		return err
 	})
 	if err != nil {
 		log.Printf("Error updating comment: %v", err)
@@ -559,27 +839,29 @@ func (h *CommentsHandler) Update(w http.ResponseWriter, r *http.Request) {
 	// Get reply count if this is a top-level comment
 	var replyCount int64
 	if !comment.ParentID.Valid {
-		replyCount, _ = h.db.Queries.CountCommentsByVideo(ctx, comment.VideoID)
+		replyCount, _ = h.db.Queries.CountRepliesByComment(ctx, pgtype.UUID{Bytes: comment.ID, Valid: true})
 	}
 
 	log.Printf("Updated comment %s by user %s", commentID, currentUserID)
 
 	response.OK(w, CommentResponse{
-		ID:               updatedComment.ID.String(),
-		VideoID:          updatedComment.VideoID.String(),
-		Content:          updatedComment.Content,
-		TimestampSeconds: updatedComment.TimestampSeconds,
-		ParentID:         pgUUIDToString(updatedComment.ParentID),
-		UserID:           updatedComment.UserID.String(),
-		AuthorUsername:   comment.AuthorUsername,
-		AuthorAvatarURL:  buildAvatarURL(comment.AuthorAvatar),
-		CreatedAt:        updatedComment.CreatedAt,
-		UpdatedAt:        updatedComment.UpdatedAt,
-		IsEdited:         isEdited(updatedComment.CreatedAt, updatedComment.UpdatedAt),
-		CanEdit:          canEditComment(updatedComment.UserID, currentUserID, updatedComment.CreatedAt),
-		CanDelete:        canDeleteComment(updatedComment.UserID, comment.VideoOwnerID, currentUserID, isAdmin),
-		ReplyCount:       replyCount,
-		Replies:          nil,
+		ID:                updatedComment.ID.String(),
+		VideoID:           updatedComment.VideoID.String(),
+		Content:           updatedComment.Content,
+		ContentHTML:       h.renderer.Render(updatedComment.Content),
+		TimestampSeconds:  updatedComment.TimestampSeconds,
+		ParentID:          pgUUIDToString(updatedComment.ParentID),
+		UserID:            updatedComment.UserID.String(),
+		AuthorUsername:    comment.AuthorUsername,
+		AuthorDisplayName: comment.AuthorDisplayName,
+		AuthorAvatarURL:   buildAvatarURL(comment.UserID, comment.AuthorAvatar),
+		CreatedAt:         updatedComment.CreatedAt,
+		UpdatedAt:         updatedComment.UpdatedAt,
+		IsEdited:          isEdited(updatedComment.CreatedAt, updatedComment.UpdatedAt),
+		CanEdit:           canEditComment(updatedComment.UserID, currentUserID, updatedComment.CreatedAt, settings.EditWindow),
+		CanDelete:         canDeleteComment(updatedComment.UserID, comment.VideoOwnerID, currentUserID, isModerator),
+		ReplyCount:        replyCount,
+		Replies:           nil,
 	})
 }
 
@@ -606,7 +888,7 @@ func (h *CommentsHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		response.Unauthorized(w, "Not authenticated")
 		return
 	}
-	isAdmin := middleware.IsAdmin(ctx)
+	isModerator := middleware.IsModerator(ctx)
 
 	// Get comment with author and video owner
 	comment, err := h.db.Queries.GetCommentWithAuthor(ctx, commentID)
@@ -621,7 +903,7 @@ func (h *CommentsHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check permission: author, video owner, or admin
-	if !canDeleteComment(comment.UserID, comment.VideoOwnerID, currentUserID, isAdmin) {
+	if !canDeleteComment(comment.UserID, comment.VideoOwnerID, currentUserID, isModerator) {
 		response.Forbidden(w, "You don't have permission to delete this comment")
 		return
 	}
@@ -635,9 +917,83 @@ func (h *CommentsHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("Deleted comment %s by user %s", commentID, currentUserID)
 
+	if comment.UserID != currentUserID {
+		recordAudit(ctx, h.db.Queries, auditEntry{
+			Action:     auditCommentDelete,
+			TargetType: auditTargetComment,
+			TargetID:   commentID,
+			Details: map[string]interface{}{
+				"author_id": comment.UserID.String(),
+				"video_id":  comment.VideoID.String(),
+			},
+		})
+	}
+
 	response.NoContent(w)
 }
 
+// CommentRevisionResponse represents a previous version of a comment
+type CommentRevisionResponse struct {
+	Content  string    `json:"content"`
+	EditedAt time.Time `json:"edited_at"`
+}
+
+// History handles GET /api/comments/{comment_id}/history
+// Visible to the comment author, the video owner, and admins
+func (h *CommentsHandler) History(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Parse comment_id
+	commentID, err := uuid.Parse(r.PathValue("comment_id"))
+	if err != nil {
+		response.BadRequest(w, "Invalid comment ID format")
+		return
+	}
+
+	// Get current user
+	currentUserID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+	isModerator := middleware.IsModerator(ctx)
+
+	// Get comment with author and video owner
+	comment, err := h.db.Queries.GetCommentWithAuthor(ctx, commentID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.NotFound(w, "Comment not found")
+			return
+		}
+		log.Printf("Error getting comment: %v", err)
+		response.InternalServerError(w, "Failed to get comment")
+		return
+	}
+
+	// Same audience as deletion: author, video owner, or admin
+	if !canDeleteComment(comment.UserID, comment.VideoOwnerID, currentUserID, isModerator) {
+		response.Forbidden(w, "You don't have permission to view this comment's history")
+		return
+	}
+
+	revisions, err := h.db.Queries.ListCommentRevisions(ctx, commentID)
+	if err != nil {
+		log.Printf("Error listing comment revisions: %v", err)
+		response.InternalServerError(w, "Failed to get comment history")
+		return
+	}
+
+	revisionResponses := make([]CommentRevisionResponse, len(revisions))
+	for i, rev := range revisions {
+		revisionResponses[i] = CommentRevisionResponse{
+			Content:  rev.Content,
+			EditedAt: rev.EditedAt,
+		}
+	}
+
+	response.OK(w, revisionResponses)
+}
+
 // GetMarkers handles GET /api/videos/{video_id}/comment-markers
 func (h *CommentsHandler) GetMarkers(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -655,15 +1011,16 @@ func (h *CommentsHandler) GetMarkers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Verify user is authenticated
-	_, ok := middleware.GetUserID(ctx)
+	// Get current user
+	currentUserID, ok := middleware.GetUserID(ctx)
 	if !ok {
 		response.Unauthorized(w, "Not authenticated")
 		return
 	}
+	isAdmin := middleware.IsAdmin(ctx)
 
 	// Verify video exists
-	_, err = h.db.Queries.GetVideoByID(ctx, videoID)
+	video, err := h.db.Queries.GetVideoByID(ctx, videoID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			response.NotFound(w, "Video not found")
@@ -674,6 +1031,18 @@ func (h *CommentsHandler) GetMarkers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check access
+	if !hasVideoAccess(video, currentUserID, isAdmin) {
+		response.Forbidden(w, "You don't have permission to view this video")
+		return
+	}
+
+	// No timeline markers while comments are disabled
+	if !video.CommentsEnabled {
+		response.OK(w, []CommentMarker{})
+		return
+	}
+
 	// Get markers
 	markers, err := h.db.Queries.GetCommentMarkers(ctx, videoID)
 	if err != nil {
@@ -699,3 +1068,253 @@ func (h *CommentsHandler) GetMarkers(w http.ResponseWriter, r *http.Request) {
 
 	response.OK(w, markerResponses)
 }
+
+// --- Reports ---
+
+// Max length of an optional report reason
+const commentReportReasonMaxLength = 500
+
+// CommentReportRequest represents the report comment request
+type CommentReportRequest struct {
+	Reason *string `json:"reason"`
+}
+
+// CommentReportResolveRequest represents the resolve report request
+type CommentReportResolveRequest struct {
+	Action string `json:"action"` // "dismiss" or "delete_comment"
+}
+
+// CommentReportResponse represents an open report in the moderation listing
+type CommentReportResponse struct {
+	ID                    string    `json:"id"`
+	CommentID             string    `json:"comment_id"`
+	CommentContent        string    `json:"comment_content"`
+	CommentVideoID        string    `json:"comment_video_id"`
+	CommentAuthorID       string    `json:"comment_author_id"`
+	CommentAuthorUsername string    `json:"comment_author_username"`
+	ReporterID            string    `json:"reporter_id"`
+	ReporterUsername      string    `json:"reporter_username"`
+	Reason                *string   `json:"reason"`
+	ReportCount           int64     `json:"report_count"`
+	CreatedAt             time.Time `json:"created_at"`
+}
+
+// CommentReportListResponse represents a paginated list of open reports
+type CommentReportListResponse struct {
+	Reports []CommentReportResponse `json:"reports"`
+	Total   int64                   `json:"total"`
+	HasMore bool                    `json:"has_more"`
+}
+
+// Report handles POST /api/comments/{comment_id}/report
+// Reporting the same comment twice is a no-op
+func (h *CommentsHandler) Report(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Parse comment_id
+	commentID, err := uuid.Parse(r.PathValue("comment_id"))
+	if err != nil {
+		response.BadRequest(w, "Invalid comment ID format")
+		return
+	}
+
+	// Get current user
+	currentUserID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+
+	// Parse request body (optional)
+	var req CommentReportRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.BadRequest(w, "Invalid request body")
+			return
+		}
+	}
+
+	var reason *string
+	if req.Reason != nil {
+		trimmed := strings.TrimSpace(*req.Reason)
+		if len(trimmed) > commentReportReasonMaxLength {
+			response.BadRequest(w, "Reason must be 500 characters or less")
+			return
+		}
+		if trimmed != "" {
+			reason = &trimmed
+		}
+	}
+
+	comment, err := h.db.Queries.GetCommentByID(ctx, commentID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.NotFound(w, "Comment not found")
+			return
+		}
+		log.Printf("Error getting comment: %v", err)
+		response.InternalServerError(w, "Failed to get comment")
+		return
+	}
+
+	// Only comments the user can see can be reported
+	video, err := h.db.Queries.GetVideoByID(ctx, comment.VideoID)
+	if err != nil {
+		log.Printf("Error getting video: %v", err)
+		response.InternalServerError(w, "Failed to get video")
+		return
+	}
+	if !hasVideoAccess(video, currentUserID, middleware.IsAdmin(ctx)) {
+		response.Forbidden(w, "You don't have permission to view this video")
+		return
+	}
+
+	if comment.UserID == currentUserID {
+		response.BadRequest(w, "You cannot report your own comment")
+		return
+	}
+
+	if err := h.db.Queries.CreateCommentReport(ctx, sqlc.CreateCommentReportParams{
+		CommentID:  commentID,
+		ReporterID: currentUserID,
+		Reason:     reason,
+	}); err != nil {
+		log.Printf("Error creating comment report: %v", err)
+		response.InternalServerError(w, "Failed to report comment")
+		return
+	}
+
+	response.OK(w, map[string]string{"message": "Comment reported"})
+}
+
+// ListReports handles GET /api/admin/comment-reports
+func (h *CommentsHandler) ListReports(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Parse pagination
+	skip := 0
+	limit := 50
+	if s := r.URL.Query().Get("skip"); s != "" {
+		if v, err := strconv.Atoi(s); err == nil && v >= 0 {
+			skip = v
+		}
+	}
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if v, err := strconv.Atoi(l); err == nil && v > 0 && v <= 100 {
+			limit = v
+		}
+	}
+
+	reports, err := h.db.Queries.ListOpenCommentReports(ctx, sqlc.ListOpenCommentReportsParams{
+		Limit:  int32(limit),
+		Offset: int32(skip),
+	})
+	if err != nil {
+		log.Printf("Error listing comment reports: %v", err)
+		response.InternalServerError(w, "Failed to list comment reports")
+		return
+	}
+
+	total, err := h.db.Queries.CountOpenCommentReports(ctx)
+	if err != nil {
+		log.Printf("Error counting comment reports: %v", err)
+		response.InternalServerError(w, "Failed to list comment reports")
+		return
+	}
+
+	reportResponses := make([]CommentReportResponse, len(reports))
+	for i, rep := range reports {
+		reportResponses[i] = CommentReportResponse{
+			ID:                    rep.ID.String(),
+			CommentID:             rep.CommentID.String(),
+			CommentContent:        rep.CommentContent,
+			CommentVideoID:        rep.CommentVideoID.String(),
+			CommentAuthorID:       rep.CommentAuthorID.String(),
+			CommentAuthorUsername: rep.CommentAuthorUsername,
+			ReporterID:            rep.ReporterID.String(),
+			ReporterUsername:      rep.ReporterUsername,
+			Reason:                rep.Reason,
+			ReportCount:           rep.OpenReportCount,
+			CreatedAt:             rep.CreatedAt,
+		}
+	}
+
+	response.OK(w, CommentReportListResponse{
+		Reports: reportResponses,
+		Total:   total,
+		HasMore: int64(skip+len(reports)) < total,
+	})
+}
+
+// ResolveReport handles POST /api/admin/comment-reports/{report_id}/resolve
+// Resolves every open report on the reported comment, optionally deleting it
+func (h *CommentsHandler) ResolveReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	reportID, err := uuid.Parse(r.PathValue("report_id"))
+	if err != nil {
+		response.BadRequest(w, "Invalid report ID format")
+		return
+	}
+
+	currentUserID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+
+	var req CommentReportResolveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+	if req.Action != "dismiss" && req.Action != "delete_comment" {
+		response.BadRequest(w, "Action must be 'dismiss' or 'delete_comment'")
+		return
+	}
+
+	report, err := h.db.Queries.GetCommentReportByID(ctx, reportID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.NotFound(w, "Report not found")
+			return
+		}
+		log.Printf("Error getting comment report: %v", err)
+		response.InternalServerError(w, "Failed to get report")
+		return
+	}
+
+	err = h.db.InTx(ctx, func(q *sqlc.Queries) error {
+		if err := q.ResolveCommentReports(ctx, sqlc.ResolveCommentReportsParams{
+			CommentID:  report.CommentID,
+			ResolvedBy: pgtype.UUID{Bytes: currentUserID, Valid: true},
+		}); err != nil {
+			return err
+		}
+		if req.Action == "delete_comment" {
+			// Cascade removes the comment's replies and reports
+			return q.DeleteComment(ctx, report.CommentID)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error resolving comment report: %v", err)
+		response.InternalServerError(w, "Failed to resolve report")
+		return
+	}
+
+	log.Printf("Resolved reports on comment %s (%s) by user %s", report.CommentID, req.Action, currentUserID)
+
+	if req.Action == "delete_comment" {
+		recordAudit(ctx, h.db.Queries, auditEntry{
+			Action:     auditCommentDelete,
+			TargetType: auditTargetComment,
+			TargetID:   report.CommentID,
+			Details: map[string]interface{}{
+				"report_id": report.ID.String(),
+			},
+		})
+	}
+
+	response.NoContent(w)
+}
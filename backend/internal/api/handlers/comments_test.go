@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/clipset/clipset-go/internal/config"
+	"github.com/clipset/clipset-go/internal/db/sqlc"
+	"github.com/clipset/clipset-go/internal/domain"
+	"github.com/clipset/clipset-go/internal/services/markdown"
+	"github.com/clipset/clipset-go/internal/services/ratelimit"
+)
+
+// commentsFixture is a video with top-level comments, each with replies
+type commentsFixture struct {
+	video    sqlc.Video
+	comments []sqlc.ListCommentsByVideoRow
+	replies  []sqlc.ListRepliesByParentsRow
+}
+
+func newCommentsFixture(topLevel, repliesEach int) commentsFixture {
+	f := commentsFixture{video: sqlc.Video{
+		ID:               uuid.New(),
+		ShortID:          "abc123",
+		UploadedBy:       uuid.New(),
+		ProcessingStatus: domain.ProcessingStatusCompleted,
+		CommentsEnabled:  true,
+	}}
+	author := uuid.New()
+	now := time.Now()
+	for i := range topLevel {
+		comment := sqlc.ListCommentsByVideoRow{
+			ID:             uuid.New(),
+			VideoID:        f.video.ID,
+			UserID:         author,
+			Content:        fmt.Sprintf("comment %d", i),
+			CreatedAt:      now,
+			UpdatedAt:      now,
+			AuthorUsername: "author",
+			ReplyCount:     int64(repliesEach),
+		}
+		f.comments = append(f.comments, comment)
+		for j := range repliesEach {
+			f.replies = append(f.replies, sqlc.ListRepliesByParentsRow{
+				ID:             uuid.New(),
+				VideoID:        f.video.ID,
+				UserID:         author,
+				Content:        fmt.Sprintf("reply %d to comment %d", j, i),
+				ParentID:       pgtype.UUID{Bytes: comment.ID, Valid: true},
+				CreatedAt:      now,
+				UpdatedAt:      now,
+				AuthorUsername: "author",
+			})
+		}
+	}
+	return f
+}
+
+// fakeDB returns a fake database holding the fixture
+func (f commentsFixture) fakeDB() *fakeDB {
+	return newFakeDB().
+		returns("GetVideoByID", f.video).
+		returns("ListCommentsByVideo", rowsOf(f.comments)...).
+		returns("CountCommentsByVideo", int64(len(f.comments))).
+		returns("GetConfig", sqlc.Config{CommentsEnabled: true, CommentMaxLength: 2000, CommentEditWindowHours: 24}).
+		returns("ListRepliesByParents", rowsOf(f.replies)...)
+}
+
+// listComments calls ListByVideo as a viewer of the fixture's video
+func listComments(t *testing.T, h *CommentsHandler, f commentsFixture) CommentListResponse {
+	t.Helper()
+	invalidateCommentSettings()
+	req := httptest.NewRequest(http.MethodGet, "/api/videos/"+f.video.ID.String()+"/comments", nil)
+	req.SetPathValue("video_id", f.video.ID.String())
+	req = withUser(req, uuid.New(), domain.UserRoleUser)
+
+	rec := httptest.NewRecorder()
+	h.ListByVideo(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("ListByVideo returned %d: %s", rec.Code, rec.Body)
+	}
+	var resp CommentListResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	return resp
+}
+
+func newTestCommentsHandler(fake *fakeDB, renderer markdown.Renderer) *CommentsHandler {
+	return &CommentsHandler{
+		db:       fake.db(),
+		config:   &config.Config{CommentReplyPreviewCount: 3},
+		renderer: renderer,
+		limiter:  ratelimit.NewMemoryLimiter(),
+	}
+}
+
+func TestListByVideoQueryCountDoesNotGrowWithComments(t *testing.T) {
+	var baseline []string
+	for _, topLevel := range []int{1, 10, 50} {
+		t.Run(fmt.Sprintf("%d comments", topLevel), func(t *testing.T) {
+			f := newCommentsFixture(topLevel, 2)
+			fake := f.fakeDB()
+			listComments(t, newTestCommentsHandler(fake, markdown.NewCommentRenderer()), f)
+
+			if n := fake.count("ListRepliesByParents"); n != 1 {
+				t.Errorf("ListRepliesByParents ran %d times, want once", n)
+			}
+			if n := fake.count("ListRepliesByComment"); n != 0 {
+				t.Errorf("ListRepliesByComment ran %d times, want replies loaded per page", n)
+			}
+
+			ran := fake.ran()
+			if baseline == nil {
+				baseline = ran
+			} else if !slices.Equal(ran, baseline) {
+				t.Errorf("queries = %v, want the same as for 1 comment: %v", ran, baseline)
+			}
+		})
+	}
+}
+
+func TestListByVideoGroupsRepliesByParent(t *testing.T) {
+	f := newCommentsFixture(3, 2)
+	fake := f.fakeDB()
+	resp := listComments(t, newTestCommentsHandler(fake, markdown.NewCommentRenderer()), f)
+
+	parentIDs, _ := fake.lastArgs("ListRepliesByParents")[0].([]uuid.UUID)
+	for _, c := range f.comments {
+		if !slices.Contains(parentIDs, c.ID) {
+			t.Errorf("replies not loaded for comment %s", c.ID)
+		}
+	}
+
+	if len(resp.Comments) != len(f.comments) {
+		t.Fatalf("got %d comments, want %d", len(resp.Comments), len(f.comments))
+	}
+	for i, c := range resp.Comments {
+		if len(c.Replies) != 2 {
+			t.Errorf("comment %d has %d replies, want 2", i, len(c.Replies))
+		}
+		for j, reply := range c.Replies {
+			if want := fmt.Sprintf("reply %d to comment %d", j, i); reply.Content != want {
+				t.Errorf("comment %d reply %d = %q, want %q", i, j, reply.Content, want)
+			}
+		}
+	}
+}
+
+func TestListByVideoSkipsRepliesWithoutPreview(t *testing.T) {
+	f := newCommentsFixture(3, 2)
+	fake := f.fakeDB()
+	h := newTestCommentsHandler(fake, markdown.NewCommentRenderer())
+	h.config.CommentReplyPreviewCount = 0
+	resp := listComments(t, h, f)
+
+	if n := fake.count("ListRepliesByParents"); n != 0 {
+		t.Errorf("ListRepliesByParents ran %d times with previews off", n)
+	}
+	for i, c := range resp.Comments {
+		if c.Replies == nil || len(c.Replies) != 0 {
+			t.Errorf("comment %d replies = %v, want an empty list", i, c.Replies)
+		}
+	}
+}
+
+// fakeRenderer marks what it renders, so responses show which content went
+// through the renderer
+type fakeRenderer struct{}
+
+func (fakeRenderer) Render(source string) string {
+	return "<rendered>" + source + "</rendered>"
+}
+
+func TestCommentResponsesRenderContent(t *testing.T) {
+	f := newCommentsFixture(2, 2)
+	f.comments[0].Content = "**bold** <b>"
+	resp := listComments(t, newTestCommentsHandler(f.fakeDB(), fakeRenderer{}), f)
+
+	check := func(c CommentResponse) {
+		t.Helper()
+		if want := (fakeRenderer{}).Render(c.Content); c.ContentHTML != want {
+			t.Errorf("content_html = %q, want %q", c.ContentHTML, want)
+		}
+	}
+	if resp.Comments[0].Content != "**bold** <b>" {
+		t.Errorf("content = %q, want the source text", resp.Comments[0].Content)
+	}
+	for _, c := range resp.Comments {
+		check(c)
+		for _, reply := range c.Replies {
+			check(reply)
+		}
+	}
+}
+
+func TestReportChecksVideoAccess(t *testing.T) {
+	uploader := uuid.New()
+	tests := []struct {
+		name   string
+		status domain.ProcessingStatus
+		viewer uuid.UUID
+		role   domain.UserRole
+		want   int
+	}{
+		{"completed video", domain.ProcessingStatusCompleted, uuid.New(), domain.UserRoleUser, http.StatusOK},
+		{"someone else's processing video", domain.ProcessingStatusProcessing, uuid.New(), domain.UserRoleUser, http.StatusForbidden},
+		{"own processing video", domain.ProcessingStatusProcessing, uploader, domain.UserRoleUser, http.StatusOK},
+		{"admin on processing video", domain.ProcessingStatusProcessing, uuid.New(), domain.UserRoleAdmin, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			video := sqlc.Video{ID: uuid.New(), UploadedBy: uploader, ProcessingStatus: tt.status}
+			comment := sqlc.Comment{ID: uuid.New(), VideoID: video.ID, UserID: uuid.New()}
+			fake := newFakeDB().
+				returns("GetCommentByID", comment).
+				returns("GetVideoByID", video)
+			h := newTestCommentsHandler(fake, markdown.NewCommentRenderer())
+
+			req := httptest.NewRequest(http.MethodPost, "/api/comments/"+comment.ID.String()+"/report", nil)
+			req.SetPathValue("comment_id", comment.ID.String())
+			rec := httptest.NewRecorder()
+			h.Report(rec, withUser(req, tt.viewer, tt.role))
+
+			if rec.Code != tt.want {
+				t.Fatalf("Report returned %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+			if reported := fake.count("CreateCommentReport") == 1; reported != (tt.want == http.StatusOK) {
+				t.Errorf("comment reported = %v", reported)
+			}
+		})
+	}
+}
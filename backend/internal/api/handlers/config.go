@@ -2,17 +2,25 @@ package handlers
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os/exec"
+	"reflect"
 	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 
 	"github.com/clipset/clipset-go/internal/api/middleware"
@@ -20,8 +28,13 @@ import (
 	"github.com/clipset/clipset-go/internal/config"
 	"github.com/clipset/clipset-go/internal/db"
 	"github.com/clipset/clipset-go/internal/db/sqlc"
+	"github.com/clipset/clipset-go/internal/domain"
+	"github.com/clipset/clipset-go/internal/services/video"
 )
 
+// Version of the ConfigExportDocument format
+const configExportVersion = 1
+
 // Validation constants
 const (
 	minFileSizeBytes     = 1048576      // 1MB
@@ -34,12 +47,30 @@ const (
 	maxCQ                = 51
 	minCRF               = 0
 	maxCRF               = 51
+	maxCommentsPerMinute = 1000
+	maxCommentsPerHour   = 10000
+	minCommentMaxLength  = 1
+	maxCommentMaxLength  = 10000
+	minCommentEditHours  = 0 // 0 disables editing
+	maxCommentEditHours  = 720
+	minStreamRateLimit   = 65536 // 64KB/s; anything lower stalls playback
 	ffmpegEncoderTimeout = 10 * time.Second
 	nvidiaSmiTimeout     = 5 * time.Second
 )
 
+// Encoder benchmark sample length limits, in seconds
+const (
+	minBenchmarkSeconds     = 2
+	maxBenchmarkSeconds     = 60
+	defaultBenchmarkSeconds = 10
+)
+
 // Valid option sets
 var (
+	validGPUBackends       = map[string]bool{video.GPUBackendNVENC: true, video.GPUBackendVAAPI: true, video.GPUBackendQSV: true}
+	validAudioTrackModes   = map[string]bool{video.AudioTrackModeFirst: true, video.AudioTrackModeMix: true, video.AudioTrackModeAll: true}
+	validVideoCodecs       = map[string]bool{video.VideoCodecH264: true, video.VideoCodecHEVC: true, video.VideoCodecAV1: true}
+	validHLSSegmentFormats = map[string]bool{video.HLSSegmentFormatTS: true, video.HLSSegmentFormatFMP4: true}
 	validNvencPresets      = map[string]bool{"p1": true, "p2": true, "p3": true, "p4": true, "p5": true, "p6": true, "p7": true}
 	validNvencRateControls = map[string]bool{"vbr": true, "cbr": true, "constqp": true}
 	validCPUPresets        = map[string]bool{
@@ -51,7 +82,7 @@ var (
 	validOutputFormats = map[string]bool{"hls": true, "progressive": true}
 	bitrateRegex       = regexp.MustCompile(`^\d+[kKmMgG]?$`)
 	audioBitrateRegex  = regexp.MustCompile(`^\d+[kK]$`)
-	targetEncoders     = []string{"h264_nvenc", "hevc_nvenc", "av1_nvenc", "libx264", "libx265"}
+	targetEncoders     = []string{"h264_nvenc", "hevc_nvenc", "av1_nvenc", "h264_vaapi", "hevc_vaapi", "av1_vaapi", "h264_qsv", "hevc_qsv", "av1_qsv", "libx264", "libx265", "libsvtav1"}
 )
 
 // Transcoding presets
@@ -88,90 +119,246 @@ var transcodingPresets = map[string]map[string]interface{}{
 	},
 }
 
+// HLS migration states
+const (
+	MigrationIdle      = "idle"
+	MigrationRunning   = "running"
+	MigrationPaused    = "paused"    // Queued videos wait; the in-flight ones finish
+	MigrationCancelled = "cancelled" // Queued videos are skipped
+	MigrationCompleted = "completed"
+)
+
 // HLS Migration state (global in-memory state)
 type hlsMigrationState struct {
 	mu           sync.RWMutex
-	IsRunning    bool
+	ID           int64 // Identifies the current migration; jobs from other migrations are skipped
+	Status       string
 	Total        int
 	Completed    int
 	CurrentVideo string
 	Errors       []string
+	inFlight     map[string]context.CancelFunc // Cancels in-flight transcodes, by video ID
 }
 
-var migrationState = &hlsMigrationState{}
+var migrationState = &hlsMigrationState{Status: MigrationIdle}
+
+// MigrationEnqueueFunc is a function type for enqueueing HLS migration jobs
+type MigrationEnqueueFunc func(ctx context.Context, videoID string, migrationID int64) error
+
+// BenchmarkEnqueueFunc is a function type for enqueueing encoder benchmark jobs.
+// It returns video.ErrQueueBusy while video jobs are queued or running.
+type BenchmarkEnqueueFunc func(ctx context.Context, sampleSeconds int, videoID string) error
 
 // ConfigHandler handles admin configuration endpoints
 type ConfigHandler struct {
-	db     *db.DB
-	config *config.Config
+	db               *db.DB
+	config           *config.Config
+	enqueueMigrate   MigrationEnqueueFunc // Optional function to enqueue HLS migration jobs
+	enqueueBenchmark BenchmarkEnqueueFunc // Optional function to enqueue encoder benchmark jobs
 }
 
 // NewConfigHandler creates a new config handler
 func NewConfigHandler(database *db.DB, cfg *config.Config) *ConfigHandler {
 	return &ConfigHandler{
-		db:     database,
-		config: cfg,
+		db:               database,
+		config:           cfg,
+		enqueueMigrate:   nil, // Set via SetMigrationEnqueueFunc after worker is initialized
+		enqueueBenchmark: nil, // Set via SetBenchmarkEnqueueFunc after worker is initialized
 	}
 }
 
+// SetMigrationEnqueueFunc sets the function used to enqueue HLS migration jobs
+// This should be called after the worker is initialized in main.go
+func (h *ConfigHandler) SetMigrationEnqueueFunc(fn MigrationEnqueueFunc) {
+	h.enqueueMigrate = fn
+}
+
+// SetBenchmarkEnqueueFunc sets the function used to enqueue encoder benchmark jobs
+// This should be called after the worker is initialized in main.go
+func (h *ConfigHandler) SetBenchmarkEnqueueFunc(fn BenchmarkEnqueueFunc) {
+	h.enqueueBenchmark = fn
+}
+
 // --- Response Types ---
 
 // ConfigResponse represents the system configuration
 type ConfigResponse struct {
-	MaxFileSizeBytes       int64     `json:"max_file_size_bytes"`
-	WeeklyUploadLimitBytes int64     `json:"weekly_upload_limit_bytes"`
-	UseGPUTranscoding      bool      `json:"use_gpu_transcoding"`
-	GPUDeviceID            int32     `json:"gpu_device_id"`
-	NvencPreset            string    `json:"nvenc_preset"`
-	NvencCQ                int32     `json:"nvenc_cq"`
-	NvencRateControl       string    `json:"nvenc_rate_control"`
-	NvencMaxBitrate        string    `json:"nvenc_max_bitrate"`
-	NvencBufferSize        string    `json:"nvenc_buffer_size"`
-	CPUPreset              string    `json:"cpu_preset"`
-	CPUCRF                 int32     `json:"cpu_crf"`
-	MaxResolution          string    `json:"max_resolution"`
-	AudioBitrate           string    `json:"audio_bitrate"`
-	TranscodePresetMode    string    `json:"transcode_preset_mode"`
-	VideoOutputFormat      string    `json:"video_output_format"`
-	UpdatedAt              time.Time `json:"updated_at"`
-	UpdatedBy              *string   `json:"updated_by"`
+	MaxFileSizeBytes             int64             `json:"max_file_size_bytes"`
+	WeeklyUploadLimitBytes       int64             `json:"weekly_upload_limit_bytes"`
+	UseGPUTranscoding            bool              `json:"use_gpu_transcoding"`
+	GPUDeviceID                  int32             `json:"gpu_device_id"`
+	GPUBackend                   string            `json:"gpu_backend"`
+	NvencPreset                  string            `json:"nvenc_preset"`
+	NvencCQ                      int32             `json:"nvenc_cq"`
+	NvencRateControl             string            `json:"nvenc_rate_control"`
+	NvencMaxBitrate              string            `json:"nvenc_max_bitrate"`
+	NvencBufferSize              string            `json:"nvenc_buffer_size"`
+	CPUPreset                    string            `json:"cpu_preset"`
+	CPUCRF                       int32             `json:"cpu_crf"`
+	MaxResolution                string            `json:"max_resolution"`
+	AudioBitrate                 string            `json:"audio_bitrate"`
+	TranscodePresetMode          string            `json:"transcode_preset_mode"`
+	VideoOutputFormat            string            `json:"video_output_format"`
+	CommentRatePerMinute         int32             `json:"comment_rate_per_minute"`
+	CommentRatePerHour           int32             `json:"comment_rate_per_hour"`
+	CommentsEnabled              bool              `json:"comments_enabled"`
+	CommentMaxLength             int32             `json:"comment_max_length"`
+	CommentEditWindowHours       int32             `json:"comment_edit_window_hours"`
+	RenditionLadder              []video.Rendition `json:"rendition_ladder"` // Empty means a single rendition
+	WorkerConcurrency            int32             `json:"worker_concurrency"`
+	GPUConcurrency               int32             `json:"gpu_concurrency"`
+	NormalizeAudio               bool              `json:"normalize_audio"`
+	LoudnessTargetLUFS           int32             `json:"loudness_target_lufs"`
+	AudioTrackMode               string            `json:"audio_track_mode"`
+	VideoCodec                   string            `json:"video_codec"`
+	HLSSegmentFormat             string            `json:"hls_segment_format"`
+	GPUDecoding                  bool              `json:"gpu_decoding"`
+	StreamRateLimitPerConnection int64             `json:"stream_rate_limit_per_connection"` // Bytes per second, 0 for unlimited
+	StreamRateLimitPerUser       int64             `json:"stream_rate_limit_per_user"`       // Bytes per second, 0 for unlimited
+	UpdatedAt                    time.Time         `json:"updated_at"`
+	UpdatedBy                    *string           `json:"updated_by"`
 }
 
 // EncoderInfoResponse represents encoder detection results
 type EncoderInfoResponse struct {
 	GPUAvailable bool     `json:"gpu_available"`
 	GPUName      *string  `json:"gpu_name"`
+	GPUBackends  []string `json:"gpu_backends"` // Usable backends for gpu_backend
 	Encoders     []string `json:"encoders"`
 }
 
 // HLSMigrationStatusResponse represents HLS migration status
 type HLSMigrationStatusResponse struct {
+	Status       string   `json:"status"`
 	IsRunning    bool     `json:"is_running"`
 	Total        int      `json:"total"`
 	Completed    int      `json:"completed"`
+	Remaining    int      `json:"remaining"`
 	CurrentVideo *string  `json:"current_video"`
 	Errors       []string `json:"errors"`
 }
 
+// HLSMigrationStartResponse is returned when an HLS migration is started
+type HLSMigrationStartResponse struct {
+	Message string `json:"message"`
+	Total   int    `json:"total"`
+}
+
+// BenchmarkRequest represents a request to start an encoder benchmark
+type BenchmarkRequest struct {
+	SampleSeconds *int    `json:"sample_seconds"` // Default 10
+	VideoID       *string `json:"video_id"`       // Omit to benchmark a generated test clip
+}
+
+// BenchmarkResultResponse represents the timings of one preset
+type BenchmarkResultResponse struct {
+	Encoder         string  `json:"encoder"`
+	Preset          string  `json:"preset"`
+	Frames          int64   `json:"frames"`
+	EncodeFPS       float64 `json:"encode_fps"`
+	WallTimeSeconds float64 `json:"wall_time_seconds"`
+	OutputBytes     int64   `json:"output_bytes"`
+	Error           *string `json:"error"`
+}
+
+// BenchmarkReportResponse represents a finished encoder benchmark
+type BenchmarkReportResponse struct {
+	Source        string                    `json:"source"` // generated or video
+	VideoID       *string                   `json:"video_id"`
+	SampleSeconds int                       `json:"sample_seconds"`
+	Width         int                       `json:"width"`
+	Height        int                       `json:"height"`
+	Results       []BenchmarkResultResponse `json:"results"`
+	Error         *string                   `json:"error"`
+	StartedAt     time.Time                 `json:"started_at"`
+	FinishedAt    time.Time                 `json:"finished_at"`
+}
+
+// BenchmarkStatusResponse represents the benchmark state and latest report
+type BenchmarkStatusResponse struct {
+	Running bool                     `json:"running"`
+	Report  *BenchmarkReportResponse `json:"report"` // Null until a benchmark finishes
+}
+
+// ConfigFieldChange is a setting's value before and after an update
+type ConfigFieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// ConfigHistoryEntryResponse represents one configuration update
+type ConfigHistoryEntryResponse struct {
+	ID                string          `json:"id"`
+	ChangedBy         *string         `json:"changed_by"`
+	ChangedByUsername *string         `json:"changed_by_username"`
+	Changes           json.RawMessage `json:"changes"` // Setting name -> ConfigFieldChange
+	CreatedAt         time.Time       `json:"created_at"`
+}
+
+// ConfigHistoryListResponse represents a page of configuration history
+type ConfigHistoryListResponse struct {
+	Entries []ConfigHistoryEntryResponse `json:"entries"`
+	Total   int64                        `json:"total"`
+	HasMore bool                         `json:"has_more"`
+}
+
+// ConfigExportDocument is the portable form of the system configuration,
+// produced by Export and accepted by Import
+type ConfigExportDocument struct {
+	Version    int                 `json:"version"`
+	ExportedAt time.Time           `json:"exported_at"`
+	Config     ConfigUpdateRequest `json:"config"`
+}
+
+// ConfigValidateResponse is the result of validating a config update without applying it
+type ConfigValidateResponse struct {
+	Valid     bool                       `json:"valid"`
+	Effective *ConfigResponse            `json:"effective,omitempty"` // The config after the update, if valid
+	Errors    []response.ValidationError `json:"errors,omitempty"`
+}
+
+// ConfigImportResponse lists the settings an import changed
+type ConfigImportResponse struct {
+	ChangedFields []string       `json:"changed_fields"`
+	Config        ConfigResponse `json:"config"`
+}
+
 // --- Request Types ---
 
 // ConfigUpdateRequest represents the config update request (all fields optional)
 type ConfigUpdateRequest struct {
-	MaxFileSizeBytes       *int64  `json:"max_file_size_bytes"`
-	WeeklyUploadLimitBytes *int64  `json:"weekly_upload_limit_bytes"`
-	UseGPUTranscoding      *bool   `json:"use_gpu_transcoding"`
-	GPUDeviceID            *int32  `json:"gpu_device_id"`
-	NvencPreset            *string `json:"nvenc_preset"`
-	NvencCQ                *int32  `json:"nvenc_cq"`
-	NvencRateControl       *string `json:"nvenc_rate_control"`
-	NvencMaxBitrate        *string `json:"nvenc_max_bitrate"`
-	NvencBufferSize        *string `json:"nvenc_buffer_size"`
-	CPUPreset              *string `json:"cpu_preset"`
-	CPUCRF                 *int32  `json:"cpu_crf"`
-	MaxResolution          *string `json:"max_resolution"`
-	AudioBitrate           *string `json:"audio_bitrate"`
-	TranscodePresetMode    *string `json:"transcode_preset_mode"`
-	VideoOutputFormat      *string `json:"video_output_format"`
+	MaxFileSizeBytes             *int64             `json:"max_file_size_bytes"`
+	WeeklyUploadLimitBytes       *int64             `json:"weekly_upload_limit_bytes"`
+	UseGPUTranscoding            *bool              `json:"use_gpu_transcoding"`
+	GPUDeviceID                  *int32             `json:"gpu_device_id"`
+	GPUBackend                   *string            `json:"gpu_backend"`
+	NvencPreset                  *string            `json:"nvenc_preset"`
+	NvencCQ                      *int32             `json:"nvenc_cq"`
+	NvencRateControl             *string            `json:"nvenc_rate_control"`
+	NvencMaxBitrate              *string            `json:"nvenc_max_bitrate"`
+	NvencBufferSize              *string            `json:"nvenc_buffer_size"`
+	CPUPreset                    *string            `json:"cpu_preset"`
+	CPUCRF                       *int32             `json:"cpu_crf"`
+	MaxResolution                *string            `json:"max_resolution"`
+	AudioBitrate                 *string            `json:"audio_bitrate"`
+	TranscodePresetMode          *string            `json:"transcode_preset_mode"`
+	VideoOutputFormat            *string            `json:"video_output_format"`
+	CommentRatePerMinute         *int32             `json:"comment_rate_per_minute"`
+	CommentRatePerHour           *int32             `json:"comment_rate_per_hour"`
+	CommentsEnabled              *bool              `json:"comments_enabled"`
+	CommentMaxLength             *int32             `json:"comment_max_length"`
+	CommentEditWindowHours       *int32             `json:"comment_edit_window_hours"`
+	RenditionLadder              *[]video.Rendition `json:"rendition_ladder"`
+	WorkerConcurrency            *int32             `json:"worker_concurrency"`
+	GPUConcurrency               *int32             `json:"gpu_concurrency"`
+	NormalizeAudio               *bool              `json:"normalize_audio"`
+	LoudnessTargetLUFS           *int32             `json:"loudness_target_lufs"`
+	AudioTrackMode               *string            `json:"audio_track_mode"`
+	VideoCodec                   *string            `json:"video_codec"`
+	HLSSegmentFormat             *string            `json:"hls_segment_format"`
+	GPUDecoding                  *bool              `json:"gpu_decoding"`
+	StreamRateLimitPerConnection *int64             `json:"stream_rate_limit_per_connection"`
+	StreamRateLimitPerUser       *int64             `json:"stream_rate_limit_per_user"`
 }
 
 // --- Helper Functions ---
@@ -185,39 +372,168 @@ func buildConfigResponse(cfg sqlc.Config) ConfigResponse {
 	}
 
 	return ConfigResponse{
-		MaxFileSizeBytes:       cfg.MaxFileSizeBytes,
-		WeeklyUploadLimitBytes: cfg.WeeklyUploadLimitBytes,
-		UseGPUTranscoding:      cfg.UseGpuTranscoding,
-		GPUDeviceID:            cfg.GpuDeviceID,
-		NvencPreset:            cfg.NvencPreset,
-		NvencCQ:                cfg.NvencCq,
-		NvencRateControl:       cfg.NvencRateControl,
-		NvencMaxBitrate:        cfg.NvencMaxBitrate,
-		NvencBufferSize:        cfg.NvencBufferSize,
-		CPUPreset:              cfg.CpuPreset,
-		CPUCRF:                 cfg.CpuCrf,
-		MaxResolution:          cfg.MaxResolution,
-		AudioBitrate:           cfg.AudioBitrate,
-		TranscodePresetMode:    cfg.TranscodePresetMode,
-		VideoOutputFormat:      cfg.VideoOutputFormat,
-		UpdatedAt:              cfg.UpdatedAt,
-		UpdatedBy:              updatedBy,
-	}
-}
-
-// detectEncoders runs ffmpeg to detect available encoders
-func detectEncoders(ctx context.Context) ([]string, bool, error) {
+		MaxFileSizeBytes:             cfg.MaxFileSizeBytes,
+		WeeklyUploadLimitBytes:       cfg.WeeklyUploadLimitBytes,
+		UseGPUTranscoding:            cfg.UseGpuTranscoding,
+		GPUDeviceID:                  cfg.GpuDeviceID,
+		GPUBackend:                   cfg.GpuBackend,
+		NvencPreset:                  cfg.NvencPreset,
+		NvencCQ:                      cfg.NvencCq,
+		NvencRateControl:             cfg.NvencRateControl,
+		NvencMaxBitrate:              cfg.NvencMaxBitrate,
+		NvencBufferSize:              cfg.NvencBufferSize,
+		CPUPreset:                    cfg.CpuPreset,
+		CPUCRF:                       cfg.CpuCrf,
+		MaxResolution:                cfg.MaxResolution,
+		AudioBitrate:                 cfg.AudioBitrate,
+		TranscodePresetMode:          cfg.TranscodePresetMode,
+		VideoOutputFormat:            cfg.VideoOutputFormat,
+		CommentRatePerMinute:         cfg.CommentRatePerMinute,
+		CommentRatePerHour:           cfg.CommentRatePerHour,
+		CommentsEnabled:              cfg.CommentsEnabled,
+		CommentMaxLength:             cfg.CommentMaxLength,
+		CommentEditWindowHours:       cfg.CommentEditWindowHours,
+		RenditionLadder:              renditionLadder(cfg),
+		WorkerConcurrency:            cfg.WorkerConcurrency,
+		GPUConcurrency:               cfg.GpuConcurrency,
+		NormalizeAudio:               cfg.NormalizeAudio,
+		LoudnessTargetLUFS:           cfg.LoudnessTargetLufs,
+		AudioTrackMode:               cfg.AudioTrackMode,
+		VideoCodec:                   cfg.VideoCodec,
+		HLSSegmentFormat:             cfg.HlsSegmentFormat,
+		GPUDecoding:                  cfg.GpuDecoding,
+		StreamRateLimitPerConnection: cfg.StreamRateLimitPerConnection,
+		StreamRateLimitPerUser:       cfg.StreamRateLimitPerUser,
+		UpdatedAt:                    cfg.UpdatedAt,
+		UpdatedBy:                    updatedBy,
+	}
+}
+
+// renditionLadder decodes the config's rendition ladder. A ladder that
+// can't be decoded is logged and treated as empty.
+func renditionLadder(cfg sqlc.Config) []video.Rendition {
+	ladder, err := video.ParseRenditionLadder(cfg.RenditionLadder)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	return ladder
+}
+
+// buildConfigExport converts a database config into an export document
+func buildConfigExport(cfg sqlc.Config) ConfigExportDocument {
+	ladder := renditionLadder(cfg)
+	return ConfigExportDocument{
+		Version:    configExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Config: ConfigUpdateRequest{
+			MaxFileSizeBytes:             &cfg.MaxFileSizeBytes,
+			WeeklyUploadLimitBytes:       &cfg.WeeklyUploadLimitBytes,
+			UseGPUTranscoding:            &cfg.UseGpuTranscoding,
+			GPUDeviceID:                  &cfg.GpuDeviceID,
+			GPUBackend:                   &cfg.GpuBackend,
+			NvencPreset:                  &cfg.NvencPreset,
+			NvencCQ:                      &cfg.NvencCq,
+			NvencRateControl:             &cfg.NvencRateControl,
+			NvencMaxBitrate:              &cfg.NvencMaxBitrate,
+			NvencBufferSize:              &cfg.NvencBufferSize,
+			CPUPreset:                    &cfg.CpuPreset,
+			CPUCRF:                       &cfg.CpuCrf,
+			MaxResolution:                &cfg.MaxResolution,
+			AudioBitrate:                 &cfg.AudioBitrate,
+			TranscodePresetMode:          &cfg.TranscodePresetMode,
+			VideoOutputFormat:            &cfg.VideoOutputFormat,
+			CommentRatePerMinute:         &cfg.CommentRatePerMinute,
+			CommentRatePerHour:           &cfg.CommentRatePerHour,
+			CommentsEnabled:              &cfg.CommentsEnabled,
+			CommentMaxLength:             &cfg.CommentMaxLength,
+			CommentEditWindowHours:       &cfg.CommentEditWindowHours,
+			RenditionLadder:              &ladder,
+			WorkerConcurrency:            &cfg.WorkerConcurrency,
+			GPUConcurrency:               &cfg.GpuConcurrency,
+			NormalizeAudio:               &cfg.NormalizeAudio,
+			LoudnessTargetLUFS:           &cfg.LoudnessTargetLufs,
+			AudioTrackMode:               &cfg.AudioTrackMode,
+			VideoCodec:                   &cfg.VideoCodec,
+			HLSSegmentFormat:             &cfg.HlsSegmentFormat,
+			GPUDecoding:                  &cfg.GpuDecoding,
+			StreamRateLimitPerConnection: &cfg.StreamRateLimitPerConnection,
+			StreamRateLimitPerUser:       &cfg.StreamRateLimitPerUser,
+		},
+	}
+}
+
+// unknownFields returns the keys of a JSON object that aren't JSON field
+// names of the struct type, sorted, each prefixed with prefix
+func unknownFields(object map[string]json.RawMessage, structType reflect.Type, prefix string) []string {
+	known := make(map[string]bool, structType.NumField())
+	for i := 0; i < structType.NumField(); i++ {
+		name, _, _ := strings.Cut(structType.Field(i).Tag.Get("json"), ",")
+		known[name] = true
+	}
+
+	var unknown []string
+	for key := range object {
+		if !known[key] {
+			unknown = append(unknown, prefix+key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// configDiff returns the settings that differ between two configs, keyed by
+// their API names. Bookkeeping fields (updated_at, updated_by) are ignored.
+func configDiff(before, after sqlc.Config) (map[string]ConfigFieldChange, error) {
+	oldValues, err := configValues(before)
+	if err != nil {
+		return nil, err
+	}
+	newValues, err := configValues(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]ConfigFieldChange)
+	for field, newValue := range newValues {
+		if field == "updated_at" || field == "updated_by" {
+			continue
+		}
+		if oldValue := oldValues[field]; !reflect.DeepEqual(oldValue, newValue) {
+			changes[field] = ConfigFieldChange{Old: oldValue, New: newValue}
+		}
+	}
+	return changes, nil
+}
+
+// configValues flattens a config into its API field names and values
+func configValues(cfg sqlc.Config) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(buildConfigResponse(cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber() // Keep byte counts exact
+	values := make(map[string]interface{})
+	if err := decoder.Decode(&values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// detectEncoders runs ffmpeg to detect available encoders, and returns them
+// with the hardware backends they make usable
+func detectEncoders(ctx context.Context) ([]string, []string, error) {
 	ctx, cancel := context.WithTimeout(ctx, ffmpegEncoderTimeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, "ffmpeg", "-encoders", "-hide_banner")
 	output, err := cmd.Output()
 	if err != nil {
-		return nil, false, err
+		return nil, nil, err
 	}
 
 	var encoders []string
-	gpuAvailable := false
 
 	scanner := bufio.NewScanner(strings.NewReader(string(output)))
 	for scanner.Scan() {
@@ -225,15 +541,12 @@ func detectEncoders(ctx context.Context) ([]string, bool, error) {
 		for _, encoder := range targetEncoders {
 			if strings.Contains(line, encoder) {
 				encoders = append(encoders, encoder)
-				if strings.HasSuffix(encoder, "_nvenc") {
-					gpuAvailable = true
-				}
 				break
 			}
 		}
 	}
 
-	return encoders, gpuAvailable, nil
+	return encoders, video.UsableGPUBackends(encoders), nil
 }
 
 // detectGPUName runs nvidia-smi to get GPU name
@@ -263,6 +576,7 @@ func (r *ConfigUpdateRequest) hasAnyField() bool {
 		r.WeeklyUploadLimitBytes != nil ||
 		r.UseGPUTranscoding != nil ||
 		r.GPUDeviceID != nil ||
+		r.GPUBackend != nil ||
 		r.NvencPreset != nil ||
 		r.NvencCQ != nil ||
 		r.NvencRateControl != nil ||
@@ -273,111 +587,80 @@ func (r *ConfigUpdateRequest) hasAnyField() bool {
 		r.MaxResolution != nil ||
 		r.AudioBitrate != nil ||
 		r.TranscodePresetMode != nil ||
-		r.VideoOutputFormat != nil
-}
-
-// --- Handlers ---
-
-// Get handles GET /api/config/
-func (h *ConfigHandler) Get(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	// Verify user is authenticated (admin check done by middleware)
-	_, ok := middleware.GetUserID(ctx)
-	if !ok {
-		response.Unauthorized(w, "Not authenticated")
-		return
-	}
-
-	// Get config
-	cfg, err := h.db.Queries.GetConfig(ctx)
-	if err != nil {
-		log.Printf("Error getting config: %v", err)
-		response.InternalServerError(w, "Failed to fetch system configuration")
-		return
-	}
-
-	response.OK(w, buildConfigResponse(cfg))
+		r.VideoOutputFormat != nil ||
+		r.CommentRatePerMinute != nil ||
+		r.CommentRatePerHour != nil ||
+		r.CommentsEnabled != nil ||
+		r.CommentMaxLength != nil ||
+		r.CommentEditWindowHours != nil ||
+		r.RenditionLadder != nil ||
+		r.WorkerConcurrency != nil ||
+		r.GPUConcurrency != nil ||
+		r.NormalizeAudio != nil ||
+		r.LoudnessTargetLUFS != nil ||
+		r.AudioTrackMode != nil ||
+		r.VideoCodec != nil ||
+		r.HLSSegmentFormat != nil ||
+		r.GPUDecoding != nil ||
+		r.StreamRateLimitPerConnection != nil ||
+		r.StreamRateLimitPerUser != nil
 }
 
-// Update handles PATCH /api/config/
-func (h *ConfigHandler) Update(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	// Get current user (admin check done by middleware)
-	userID, ok := middleware.GetUserID(ctx)
-	if !ok {
-		response.Unauthorized(w, "Not authenticated")
-		return
-	}
-
-	// Parse request body
-	var req ConfigUpdateRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, "Invalid request body")
-		return
-	}
-
-	// Check if any fields provided
-	if !req.hasAnyField() {
-		response.BadRequest(w, "No fields provided for update")
-		return
-	}
-
-	// Get current config for defaults
-	currentConfig, err := h.db.Queries.GetConfig(ctx)
-	if err != nil {
-		log.Printf("Error getting current config: %v", err)
-		response.InternalServerError(w, "Failed to get current configuration")
-		return
+// validate checks the request against the allowed values and ranges,
+// normalizing bitrate casing in place. Returns one error per invalid field;
+// the messages are safe to show.
+func (req *ConfigUpdateRequest) validate() []response.ValidationError {
+	var errs []response.ValidationError
+	invalid := func(field, message string) {
+		errs = append(errs, response.ValidationError{Field: field, Message: message})
 	}
 
-	// Validate and normalize fields
 	// max_file_size_bytes
 	if req.MaxFileSizeBytes != nil {
 		if *req.MaxFileSizeBytes < minFileSizeBytes || *req.MaxFileSizeBytes > maxFileSizeBytes {
-			response.BadRequest(w, "max_file_size_bytes must be between 1MB and 10GB")
-			return
+			invalid("max_file_size_bytes", "max_file_size_bytes must be between 1MB and 10GB")
 		}
 	}
 
 	// weekly_upload_limit_bytes
 	if req.WeeklyUploadLimitBytes != nil {
 		if *req.WeeklyUploadLimitBytes < minWeeklyUploadBytes || *req.WeeklyUploadLimitBytes > maxWeeklyUploadBytes {
-			response.BadRequest(w, "weekly_upload_limit_bytes must be between 1MB and 100GB")
-			return
+			invalid("weekly_upload_limit_bytes", "weekly_upload_limit_bytes must be between 1MB and 100GB")
 		}
 	}
 
 	// gpu_device_id
 	if req.GPUDeviceID != nil {
 		if *req.GPUDeviceID < minGPUDeviceID || *req.GPUDeviceID > maxGPUDeviceID {
-			response.BadRequest(w, "gpu_device_id must be between 0 and 15")
-			return
+			invalid("gpu_device_id", "gpu_device_id must be between 0 and 15")
+		}
+	}
+
+	// gpu_backend
+	if req.GPUBackend != nil {
+		if !validGPUBackends[*req.GPUBackend] {
+			invalid("gpu_backend", "gpu_backend must be one of: nvenc, vaapi, qsv")
 		}
 	}
 
 	// nvenc_preset
 	if req.NvencPreset != nil {
 		if !validNvencPresets[*req.NvencPreset] {
-			response.BadRequest(w, "nvenc_preset must be one of: p1, p2, p3, p4, p5, p6, p7")
-			return
+			invalid("nvenc_preset", "nvenc_preset must be one of: p1, p2, p3, p4, p5, p6, p7")
 		}
 	}
 
 	// nvenc_cq
 	if req.NvencCQ != nil {
 		if *req.NvencCQ < minCQ || *req.NvencCQ > maxCQ {
-			response.BadRequest(w, "nvenc_cq must be between 0 and 51")
-			return
+			invalid("nvenc_cq", "nvenc_cq must be between 0 and 51")
 		}
 	}
 
 	// nvenc_rate_control
 	if req.NvencRateControl != nil {
 		if !validNvencRateControls[*req.NvencRateControl] {
-			response.BadRequest(w, "nvenc_rate_control must be one of: vbr, cbr, constqp")
-			return
+			invalid("nvenc_rate_control", "nvenc_rate_control must be one of: vbr, cbr, constqp")
 		}
 	}
 
@@ -385,8 +668,7 @@ func (h *ConfigHandler) Update(w http.ResponseWriter, r *http.Request) {
 	if req.NvencMaxBitrate != nil {
 		normalized := strings.ToUpper(*req.NvencMaxBitrate)
 		if !bitrateRegex.MatchString(normalized) {
-			response.BadRequest(w, "nvenc_max_bitrate must match pattern like 8M, 5000k")
-			return
+			invalid("nvenc_max_bitrate", "nvenc_max_bitrate must match pattern like 8M, 5000k")
 		}
 		req.NvencMaxBitrate = &normalized
 	}
@@ -395,8 +677,7 @@ func (h *ConfigHandler) Update(w http.ResponseWriter, r *http.Request) {
 	if req.NvencBufferSize != nil {
 		normalized := strings.ToUpper(*req.NvencBufferSize)
 		if !bitrateRegex.MatchString(normalized) {
-			response.BadRequest(w, "nvenc_buffer_size must match pattern like 16M, 10000k")
-			return
+			invalid("nvenc_buffer_size", "nvenc_buffer_size must match pattern like 16M, 10000k")
 		}
 		req.NvencBufferSize = &normalized
 	}
@@ -404,24 +685,21 @@ func (h *ConfigHandler) Update(w http.ResponseWriter, r *http.Request) {
 	// cpu_preset
 	if req.CPUPreset != nil {
 		if !validCPUPresets[*req.CPUPreset] {
-			response.BadRequest(w, "cpu_preset must be one of: ultrafast, superfast, veryfast, faster, fast, medium, slow, slower, veryslow")
-			return
+			invalid("cpu_preset", "cpu_preset must be one of: ultrafast, superfast, veryfast, faster, fast, medium, slow, slower, veryslow")
 		}
 	}
 
 	// cpu_crf
 	if req.CPUCRF != nil {
 		if *req.CPUCRF < minCRF || *req.CPUCRF > maxCRF {
-			response.BadRequest(w, "cpu_crf must be between 0 and 51")
-			return
+			invalid("cpu_crf", "cpu_crf must be between 0 and 51")
 		}
 	}
 
 	// max_resolution
 	if req.MaxResolution != nil {
 		if !validResolutions[*req.MaxResolution] {
-			response.BadRequest(w, "max_resolution must be one of: 720p, 1080p, 1440p, 4k")
-			return
+			invalid("max_resolution", "max_resolution must be one of: 720p, 1080p, 1440p, 4k")
 		}
 	}
 
@@ -429,8 +707,7 @@ func (h *ConfigHandler) Update(w http.ResponseWriter, r *http.Request) {
 	if req.AudioBitrate != nil {
 		normalized := strings.ToLower(*req.AudioBitrate)
 		if !audioBitrateRegex.MatchString(normalized) {
-			response.BadRequest(w, "audio_bitrate must match pattern like 192k, 256k")
-			return
+			invalid("audio_bitrate", "audio_bitrate must match pattern like 192k, 256k")
 		}
 		req.AudioBitrate = &normalized
 	}
@@ -438,20 +715,152 @@ func (h *ConfigHandler) Update(w http.ResponseWriter, r *http.Request) {
 	// transcode_preset_mode
 	if req.TranscodePresetMode != nil {
 		if !validPresetModes[*req.TranscodePresetMode] {
-			response.BadRequest(w, "transcode_preset_mode must be one of: quality, balanced, performance, custom")
-			return
+			invalid("transcode_preset_mode", "transcode_preset_mode must be one of: quality, balanced, performance, custom")
 		}
 	}
 
 	// video_output_format
 	if req.VideoOutputFormat != nil {
 		if !validOutputFormats[*req.VideoOutputFormat] {
-			response.BadRequest(w, "video_output_format must be one of: hls, progressive")
-			return
+			invalid("video_output_format", "video_output_format must be one of: hls, progressive")
+		}
+	}
+
+	// comment_rate_per_minute (0 disables the limit)
+	if req.CommentRatePerMinute != nil {
+		if *req.CommentRatePerMinute < 0 || *req.CommentRatePerMinute > maxCommentsPerMinute {
+			invalid("comment_rate_per_minute", "comment_rate_per_minute must be between 0 and 1000")
+		}
+	}
+
+	// comment_rate_per_hour (0 disables the limit)
+	if req.CommentRatePerHour != nil {
+		if *req.CommentRatePerHour < 0 || *req.CommentRatePerHour > maxCommentsPerHour {
+			invalid("comment_rate_per_hour", "comment_rate_per_hour must be between 0 and 10000")
+		}
+	}
+
+	// comment_max_length
+	if req.CommentMaxLength != nil {
+		if *req.CommentMaxLength < minCommentMaxLength || *req.CommentMaxLength > maxCommentMaxLength {
+			invalid("comment_max_length", "comment_max_length must be between 1 and 10000")
+		}
+	}
+
+	// comment_edit_window_hours (0 disables editing)
+	if req.CommentEditWindowHours != nil {
+		if *req.CommentEditWindowHours < minCommentEditHours || *req.CommentEditWindowHours > maxCommentEditHours {
+			invalid("comment_edit_window_hours", "comment_edit_window_hours must be between 0 and 720")
+		}
+	}
+
+	// rendition_ladder
+	if req.RenditionLadder != nil {
+		errs = append(errs, validateRenditionLadder(*req.RenditionLadder)...)
+	}
+
+	// worker_concurrency
+	if req.WorkerConcurrency != nil {
+		if *req.WorkerConcurrency < 1 || *req.WorkerConcurrency > config.MaxWorkerConcurrency {
+			invalid("worker_concurrency", fmt.Sprintf("worker_concurrency must be between 1 and %d", config.MaxWorkerConcurrency))
+		}
+	}
+
+	// gpu_concurrency
+	if req.GPUConcurrency != nil {
+		if *req.GPUConcurrency < 1 || *req.GPUConcurrency > config.MaxWorkerConcurrency {
+			invalid("gpu_concurrency", fmt.Sprintf("gpu_concurrency must be between 1 and %d", config.MaxWorkerConcurrency))
+		}
+	}
+
+	// loudness_target_lufs
+	if req.LoudnessTargetLUFS != nil {
+		if *req.LoudnessTargetLUFS < video.MinLoudnessTarget || *req.LoudnessTargetLUFS > video.MaxLoudnessTarget {
+			invalid("loudness_target_lufs", fmt.Sprintf("loudness_target_lufs must be between %d and %d", video.MinLoudnessTarget, video.MaxLoudnessTarget))
+		}
+	}
+
+	// audio_track_mode
+	if req.AudioTrackMode != nil {
+		if !validAudioTrackModes[*req.AudioTrackMode] {
+			invalid("audio_track_mode", "audio_track_mode must be one of: first, mix, all")
+		}
+	}
+
+	// video_codec
+	if req.VideoCodec != nil {
+		if !validVideoCodecs[*req.VideoCodec] {
+			invalid("video_codec", "video_codec must be one of: h264, hevc, av1")
+		}
+	}
+
+	// hls_segment_format
+	if req.HLSSegmentFormat != nil {
+		if !validHLSSegmentFormats[*req.HLSSegmentFormat] {
+			invalid("hls_segment_format", "hls_segment_format must be one of: ts, fmp4")
+		}
+	}
+
+	// stream_rate_limit_per_connection (0 disables the limit)
+	if req.StreamRateLimitPerConnection != nil {
+		if *req.StreamRateLimitPerConnection < 0 || (*req.StreamRateLimitPerConnection > 0 && *req.StreamRateLimitPerConnection < minStreamRateLimit) {
+			invalid("stream_rate_limit_per_connection", "stream_rate_limit_per_connection must be 0 (unlimited) or at least 64KB per second")
+		}
+	}
+
+	// stream_rate_limit_per_user (0 disables the limit)
+	if req.StreamRateLimitPerUser != nil {
+		if *req.StreamRateLimitPerUser < 0 || (*req.StreamRateLimitPerUser > 0 && *req.StreamRateLimitPerUser < minStreamRateLimit) {
+			invalid("stream_rate_limit_per_user", "stream_rate_limit_per_user must be 0 (unlimited) or at least 64KB per second")
 		}
 	}
 
-	// Apply preset values if transcode_preset_mode is changed to a non-custom value
+	return errs
+}
+
+// validateRenditionLadder checks each rendition, reporting errors against
+// fields like rendition_ladder[1].crf
+func validateRenditionLadder(ladder []video.Rendition) []response.ValidationError {
+	var errs []response.ValidationError
+	invalid := func(field, message string) {
+		errs = append(errs, response.ValidationError{Field: field, Message: field + " " + message})
+	}
+
+	if len(ladder) > video.MaxRenditions {
+		invalid("rendition_ladder", fmt.Sprintf("must have at most %d renditions", video.MaxRenditions))
+	}
+
+	seen := make(map[string]bool, len(ladder))
+	for i, rendition := range ladder {
+		prefix := fmt.Sprintf("rendition_ladder[%d].", i)
+
+		if _, ok := video.RenditionHeights[rendition.Resolution]; !ok {
+			invalid(prefix+"resolution", "must be one of: 360p, 480p, 720p, 1080p, 1440p, 4k")
+		} else if seen[rendition.Resolution] {
+			invalid(prefix+"resolution", "is already used by another rendition")
+		}
+		seen[rendition.Resolution] = true
+
+		if rendition.VideoBitrate != "" && !bitrateRegex.MatchString(rendition.VideoBitrate) {
+			invalid(prefix+"video_bitrate", "must match pattern like 8M, 5000k")
+		}
+		if rendition.CRF != nil && (*rendition.CRF < minCRF || *rendition.CRF > maxCRF) {
+			invalid(prefix+"crf", "must be between 0 and 51")
+		}
+		if rendition.CQ != nil && (*rendition.CQ < minCQ || *rendition.CQ > maxCQ) {
+			invalid(prefix+"cq", "must be between 0 and 51")
+		}
+		if rendition.AudioBitrate != "" && !audioBitrateRegex.MatchString(rendition.AudioBitrate) {
+			invalid(prefix+"audio_bitrate", "must match pattern like 192k, 256k")
+		}
+	}
+
+	return errs
+}
+
+// applyTranscodePreset fills the transcoding fields the request leaves unset
+// from the preset when transcode_preset_mode is changed to a non-custom value
+func (req *ConfigUpdateRequest) applyTranscodePreset() {
 	if req.TranscodePresetMode != nil && *req.TranscodePresetMode != "custom" {
 		preset, exists := transcodingPresets[*req.TranscodePresetMode]
 		if exists {
@@ -490,7 +899,85 @@ func (h *ConfigHandler) Update(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 	}
+}
+
+// prepare validates the request and expands its preset mode into the
+// individual settings. Update, Import and Validate all go through it, so a
+// request Validate accepts is one Update would store unchanged.
+func (req *ConfigUpdateRequest) prepare() []response.ValidationError {
+	if errs := req.validate(); len(errs) > 0 {
+		return errs
+	}
+	req.applyTranscodePreset()
+	return nil
+}
+
+// effectiveConfig returns the config as it would be after applying the
+// prepared request to current
+func (req *ConfigUpdateRequest) effectiveConfig(current sqlc.Config) sqlc.Config {
+	cfg := current
+	setIfPresent(&cfg.MaxFileSizeBytes, req.MaxFileSizeBytes)
+	setIfPresent(&cfg.WeeklyUploadLimitBytes, req.WeeklyUploadLimitBytes)
+	setIfPresent(&cfg.UseGpuTranscoding, req.UseGPUTranscoding)
+	setIfPresent(&cfg.GpuDeviceID, req.GPUDeviceID)
+	setIfPresent(&cfg.GpuBackend, req.GPUBackend)
+	setIfPresent(&cfg.NvencPreset, req.NvencPreset)
+	setIfPresent(&cfg.NvencCq, req.NvencCQ)
+	setIfPresent(&cfg.NvencRateControl, req.NvencRateControl)
+	setIfPresent(&cfg.NvencMaxBitrate, req.NvencMaxBitrate)
+	setIfPresent(&cfg.NvencBufferSize, req.NvencBufferSize)
+	setIfPresent(&cfg.CpuPreset, req.CPUPreset)
+	setIfPresent(&cfg.CpuCrf, req.CPUCRF)
+	setIfPresent(&cfg.MaxResolution, req.MaxResolution)
+	setIfPresent(&cfg.AudioBitrate, req.AudioBitrate)
+	setIfPresent(&cfg.TranscodePresetMode, req.TranscodePresetMode)
+	setIfPresent(&cfg.VideoOutputFormat, req.VideoOutputFormat)
+	setIfPresent(&cfg.CommentRatePerMinute, req.CommentRatePerMinute)
+	setIfPresent(&cfg.CommentRatePerHour, req.CommentRatePerHour)
+	setIfPresent(&cfg.CommentsEnabled, req.CommentsEnabled)
+	setIfPresent(&cfg.CommentMaxLength, req.CommentMaxLength)
+	setIfPresent(&cfg.CommentEditWindowHours, req.CommentEditWindowHours)
+	if ladder := req.renditionLadderJSON(); ladder != nil {
+		cfg.RenditionLadder = ladder
+	}
+	setIfPresent(&cfg.WorkerConcurrency, req.WorkerConcurrency)
+	setIfPresent(&cfg.GpuConcurrency, req.GPUConcurrency)
+	setIfPresent(&cfg.NormalizeAudio, req.NormalizeAudio)
+	setIfPresent(&cfg.LoudnessTargetLufs, req.LoudnessTargetLUFS)
+	setIfPresent(&cfg.AudioTrackMode, req.AudioTrackMode)
+	setIfPresent(&cfg.VideoCodec, req.VideoCodec)
+	setIfPresent(&cfg.HlsSegmentFormat, req.HLSSegmentFormat)
+	setIfPresent(&cfg.GpuDecoding, req.GPUDecoding)
+	setIfPresent(&cfg.StreamRateLimitPerConnection, req.StreamRateLimitPerConnection)
+	setIfPresent(&cfg.StreamRateLimitPerUser, req.StreamRateLimitPerUser)
+	return cfg
+}
+
+// renditionLadderJSON encodes the request's rendition ladder for storage, or
+// returns nil if the request leaves it unset
+func (req *ConfigUpdateRequest) renditionLadderJSON() []byte {
+	if req.RenditionLadder == nil {
+		return nil
+	}
+	ladder := *req.RenditionLadder
+	if ladder == nil {
+		ladder = []video.Rendition{}
+	}
+	// Renditions only hold strings and ints, so encoding can't fail
+	encoded, _ := json.Marshal(ladder)
+	return encoded
+}
+
+// setIfPresent sets *dst to *value if value isn't nil
+func setIfPresent[T any](dst *T, value *T) {
+	if value != nil {
+		*dst = *value
+	}
+}
 
+// updateParams builds UpdateConfigParams, keeping current values for fields
+// the request leaves unset
+func (req *ConfigUpdateRequest) updateParams(currentConfig sqlc.Config, userID uuid.UUID) sqlc.UpdateConfigParams {
 	// Build update params with defaults from current config
 	// For nullable int64/int32/bool, we need to use 0/false to keep existing if nil
 	// The SQL uses COALESCE, so we pass the value or use the default behavior
@@ -540,6 +1027,60 @@ func (h *ConfigHandler) Update(w http.ResponseWriter, r *http.Request) {
 		params.CpuCrf = currentConfig.CpuCrf
 	}
 
+	if req.CommentRatePerMinute != nil {
+		params.CommentRatePerMinute = *req.CommentRatePerMinute
+	} else {
+		params.CommentRatePerMinute = currentConfig.CommentRatePerMinute
+	}
+
+	if req.CommentRatePerHour != nil {
+		params.CommentRatePerHour = *req.CommentRatePerHour
+	} else {
+		params.CommentRatePerHour = currentConfig.CommentRatePerHour
+	}
+
+	if req.CommentsEnabled != nil {
+		params.CommentsEnabled = *req.CommentsEnabled
+	} else {
+		params.CommentsEnabled = currentConfig.CommentsEnabled
+	}
+
+	if req.CommentMaxLength != nil {
+		params.CommentMaxLength = *req.CommentMaxLength
+	} else {
+		params.CommentMaxLength = currentConfig.CommentMaxLength
+	}
+
+	if req.CommentEditWindowHours != nil {
+		params.CommentEditWindowHours = *req.CommentEditWindowHours
+	} else {
+		params.CommentEditWindowHours = currentConfig.CommentEditWindowHours
+	}
+
+	if req.WorkerConcurrency != nil {
+		params.WorkerConcurrency = *req.WorkerConcurrency
+	} else {
+		params.WorkerConcurrency = currentConfig.WorkerConcurrency
+	}
+
+	if req.GPUConcurrency != nil {
+		params.GpuConcurrency = *req.GPUConcurrency
+	} else {
+		params.GpuConcurrency = currentConfig.GpuConcurrency
+	}
+
+	if req.NormalizeAudio != nil {
+		params.NormalizeAudio = *req.NormalizeAudio
+	} else {
+		params.NormalizeAudio = currentConfig.NormalizeAudio
+	}
+
+	if req.LoudnessTargetLUFS != nil {
+		params.LoudnessTargetLufs = *req.LoudnessTargetLUFS
+	} else {
+		params.LoudnessTargetLufs = currentConfig.LoudnessTargetLufs
+	}
+
 	// String fields: pass empty string to keep existing (SQL uses NULLIF to convert empty to NULL)
 	params.Column3 = ""
 
@@ -597,47 +1138,405 @@ func (h *ConfigHandler) Update(w http.ResponseWriter, r *http.Request) {
 		params.Column16 = ""
 	}
 
-	// Update config
-	updatedConfig, err := h.db.Queries.UpdateConfig(ctx, params)
-	if err != nil {
-		log.Printf("Error updating config: %v", err)
-		response.InternalServerError(w, "Failed to update system configuration")
-		return
+	if req.GPUBackend != nil {
+		params.Column23 = *req.GPUBackend
+	} else {
+		params.Column23 = ""
 	}
 
-	log.Printf("Updated system configuration by user %s", userID)
+	if req.AudioTrackMode != nil {
+		params.Column29 = *req.AudioTrackMode
+	} else {
+		params.Column29 = ""
+	}
 
-	response.OK(w, buildConfigResponse(updatedConfig))
-}
+	if req.VideoCodec != nil {
+		params.Column30 = *req.VideoCodec
+	} else {
+		params.Column30 = ""
+	}
 
-// GetEncoders handles GET /api/config/encoders
-func (h *ConfigHandler) GetEncoders(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	if req.HLSSegmentFormat != nil {
+		params.Column31 = *req.HLSSegmentFormat
+	} else {
+		params.Column31 = ""
+	}
 
-	// Verify user is authenticated (admin check done by middleware)
-	_, ok := middleware.GetUserID(ctx)
-	if !ok {
+	if req.GPUDecoding != nil {
+		params.GpuDecoding = *req.GPUDecoding
+	} else {
+		params.GpuDecoding = currentConfig.GpuDecoding
+	}
+
+	if req.StreamRateLimitPerConnection != nil {
+		params.StreamRateLimitPerConnection = *req.StreamRateLimitPerConnection
+	} else {
+		params.StreamRateLimitPerConnection = currentConfig.StreamRateLimitPerConnection
+	}
+
+	if req.StreamRateLimitPerUser != nil {
+		params.StreamRateLimitPerUser = *req.StreamRateLimitPerUser
+	} else {
+		params.StreamRateLimitPerUser = currentConfig.StreamRateLimitPerUser
+	}
+
+	// nil keeps the existing ladder
+	params.RenditionLadder = req.renditionLadderJSON()
+
+	return params
+}
+
+// --- Handlers ---
+
+// Get handles GET /api/config/
+func (h *ConfigHandler) Get(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify user is authenticated (admin check done by middleware)
+	_, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+
+	// Get config
+	cfg, err := h.db.Queries.GetConfig(ctx)
+	if err != nil {
+		log.Printf("Error getting config: %v", err)
+		response.InternalServerError(w, "Failed to fetch system configuration")
+		return
+	}
+
+	response.OK(w, buildConfigResponse(cfg))
+}
+
+// Update handles PATCH /api/config/
+func (h *ConfigHandler) Update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get current user (admin check done by middleware)
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+
+	// Parse request body
+	var req ConfigUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Check if any fields provided
+	if !req.hasAnyField() {
+		response.BadRequest(w, "No fields provided for update")
+		return
+	}
+
+	updatedConfig, _, ok := h.applyConfigUpdate(w, r, userID, &req, auditConfigUpdate)
+	if !ok {
+		return
+	}
+
+	log.Printf("Updated system configuration by user %s", userID)
+
+	response.OK(w, buildConfigResponse(updatedConfig))
+}
+
+// Validate handles POST /api/config/validate
+// Runs a ConfigUpdateRequest through the same validation and preset expansion
+// as Update without storing it, and returns the config that would result.
+func (h *ConfigHandler) Validate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req ConfigUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	if errs := req.prepare(); len(errs) > 0 {
+		response.OK(w, ConfigValidateResponse{Valid: false, Errors: errs})
+		return
+	}
+
+	currentConfig, err := h.db.Queries.GetConfig(ctx)
+	if err != nil {
+		log.Printf("Error getting current config: %v", err)
+		response.InternalServerError(w, "Failed to get current configuration")
+		return
+	}
+
+	effectiveConfig := req.effectiveConfig(currentConfig)
+	if verr := req.checkCodecEncoder(ctx, effectiveConfig); verr != nil {
+		response.OK(w, ConfigValidateResponse{Valid: false, Errors: []response.ValidationError{*verr}})
+		return
+	}
+
+	effective := buildConfigResponse(effectiveConfig)
+	response.OK(w, ConfigValidateResponse{Valid: true, Effective: &effective})
+}
+
+// checkCodecEncoder checks that FFmpeg has the encoder the effective config
+// would transcode with, when the request changes the codec or the encoder
+// it's picked from. H.264 is always allowed, as it was before codecs could be
+// chosen.
+func (req *ConfigUpdateRequest) checkCodecEncoder(ctx context.Context, cfg sqlc.Config) *response.ValidationError {
+	if req.VideoCodec == nil && req.UseGPUTranscoding == nil && req.GPUBackend == nil {
+		return nil
+	}
+	if cfg.VideoCodec == video.VideoCodecH264 {
+		return nil
+	}
+
+	encoder := video.CodecEncoder(cfg.VideoCodec, cfg.UseGpuTranscoding, cfg.GpuBackend)
+	encoders, _, err := detectEncoders(ctx)
+	if err != nil {
+		log.Printf("Error detecting encoders: %v", err)
+		return &response.ValidationError{Field: "video_codec", Message: "Failed to detect available encoders for video_codec"}
+	}
+	if !slices.Contains(encoders, encoder) {
+		return &response.ValidationError{
+			Field:   "video_codec",
+			Message: fmt.Sprintf("video_codec %s needs the %s encoder, which FFmpeg doesn't have", cfg.VideoCodec, encoder),
+		}
+	}
+	return nil
+}
+
+// applyConfigUpdate validates the request with the Update rules and applies it
+// in one transaction, recording the changed fields in the config history and
+// audit log. Writes an error response and returns false on failure.
+func (h *ConfigHandler) applyConfigUpdate(w http.ResponseWriter, r *http.Request, userID uuid.UUID, req *ConfigUpdateRequest, action string) (sqlc.Config, map[string]ConfigFieldChange, bool) {
+	ctx := r.Context()
+
+	// Get current config for defaults
+	currentConfig, err := h.db.Queries.GetConfig(ctx)
+	if err != nil {
+		log.Printf("Error getting current config: %v", err)
+		response.InternalServerError(w, "Failed to get current configuration")
+		return sqlc.Config{}, nil, false
+	}
+
+	if errs := req.prepare(); len(errs) > 0 {
+		response.BadRequest(w, errs[0].Message)
+		return sqlc.Config{}, nil, false
+	}
+
+	if verr := req.checkCodecEncoder(ctx, req.effectiveConfig(currentConfig)); verr != nil {
+		response.BadRequest(w, verr.Message)
+		return sqlc.Config{}, nil, false
+	}
+
+	params := req.updateParams(currentConfig, userID)
+
+	// Update config and record what changed
+	var updatedConfig sqlc.Config
+	var changes map[string]ConfigFieldChange
+	err = h.db.InTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		updatedConfig, err = q.UpdateConfig(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		changes, err = configDiff(currentConfig, updatedConfig)
+		if err != nil || len(changes) == 0 {
+			return err
+		}
+		encoded, err := json.Marshal(changes)
+		if err != nil {
+			return err
+		}
+		return q.CreateConfigHistoryEntry(ctx, sqlc.CreateConfigHistoryEntryParams{
+			ChangedBy: pgtype.UUID{Bytes: userID, Valid: true},
+			Changes:   encoded,
+		})
+	})
+	if err != nil {
+		log.Printf("Error updating config: %v", err)
+		response.InternalServerError(w, "Failed to update system configuration")
+		return sqlc.Config{}, nil, false
+	}
+
+	// Comment handlers pick up new comment settings on their next request,
+	// and streams started after this pick up new bandwidth limits
+	invalidateCommentSettings()
+	invalidateStreamLimits()
+
+	recordAudit(ctx, h.db.Queries, auditEntry{
+		Action:     action,
+		TargetType: auditTargetConfig,
+		Details: map[string]interface{}{
+			"changes": changes,
+		},
+	})
+
+	return updatedConfig, changes, true
+}
+
+// Export handles GET /api/config/export
+func (h *ConfigHandler) Export(w http.ResponseWriter, r *http.Request) {
+	cfg, err := h.db.Queries.GetConfig(r.Context())
+	if err != nil {
+		log.Printf("Error getting config: %v", err)
+		response.InternalServerError(w, "Failed to fetch system configuration")
+		return
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="clipset-config.json"`)
+	response.OK(w, buildConfigExport(cfg))
+}
+
+// Import handles POST /api/config/import
+// Applies an exported configuration document with the same validation as
+// Update. Fields missing from the document keep their current values.
+func (h *ConfigHandler) Import(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get current user (admin check done by middleware)
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+
+	// Decode loosely first so unknown fields can be reported by name
+	var document map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&document); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	var fields map[string]json.RawMessage
+	if raw, ok := document["config"]; ok {
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			response.BadRequest(w, "config must be an object")
+			return
+		}
+	}
+
+	unknown := unknownFields(document, reflect.TypeOf(ConfigExportDocument{}), "")
+	unknown = append(unknown, unknownFields(fields, reflect.TypeOf(ConfigUpdateRequest{}), "config.")...)
+	if len(unknown) > 0 {
+		response.BadRequest(w, "Unknown fields: "+strings.Join(unknown, ", "))
+		return
+	}
+
+	var version int
+	if err := json.Unmarshal(document["version"], &version); err != nil || version != configExportVersion {
+		response.BadRequest(w, fmt.Sprintf("Unsupported configuration version (expected %d)", configExportVersion))
+		return
+	}
+
+	var req ConfigUpdateRequest
+	if err := json.Unmarshal(document["config"], &req); err != nil || !req.hasAnyField() {
+		response.BadRequest(w, "config must contain at least one valid setting")
+		return
+	}
+
+	updatedConfig, changes, ok := h.applyConfigUpdate(w, r, userID, &req, auditConfigImport)
+	if !ok {
+		return
+	}
+
+	changedFields := make([]string, 0, len(changes))
+	for field := range changes {
+		changedFields = append(changedFields, field)
+	}
+	sort.Strings(changedFields)
+
+	log.Printf("Imported system configuration by user %s (%d fields changed)", userID, len(changedFields))
+
+	response.OK(w, ConfigImportResponse{
+		ChangedFields: changedFields,
+		Config:        buildConfigResponse(updatedConfig),
+	})
+}
+
+// History handles GET /api/config/history
+func (h *ConfigHandler) History(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Parse pagination
+	skip := 0
+	limit := 50
+	if s := r.URL.Query().Get("skip"); s != "" {
+		if v, err := strconv.Atoi(s); err == nil && v >= 0 {
+			skip = v
+		}
+	}
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if v, err := strconv.Atoi(l); err == nil && v > 0 && v <= 200 {
+			limit = v
+		}
+	}
+
+	entries, err := h.db.Queries.ListConfigHistory(ctx, sqlc.ListConfigHistoryParams{
+		Limit:  int32(limit),
+		Offset: int32(skip),
+	})
+	if err != nil {
+		log.Printf("Error listing config history: %v", err)
+		response.InternalServerError(w, "Failed to get configuration history")
+		return
+	}
+
+	total, err := h.db.Queries.CountConfigHistory(ctx)
+	if err != nil {
+		log.Printf("Error counting config history: %v", err)
+		response.InternalServerError(w, "Failed to get configuration history")
+		return
+	}
+
+	result := make([]ConfigHistoryEntryResponse, len(entries))
+	for i, e := range entries {
+		result[i] = ConfigHistoryEntryResponse{
+			ID:                e.ID.String(),
+			ChangedBy:         pgUUIDToString(e.ChangedBy),
+			ChangedByUsername: e.ChangedByUsername,
+			Changes:           json.RawMessage(e.Changes),
+			CreatedAt:         e.CreatedAt,
+		}
+	}
+
+	response.OK(w, ConfigHistoryListResponse{
+		Entries: result,
+		Total:   total,
+		HasMore: int64(skip+len(entries)) < total,
+	})
+}
+
+// GetEncoders handles GET /api/config/encoders
+func (h *ConfigHandler) GetEncoders(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify user is authenticated (admin check done by middleware)
+	_, ok := middleware.GetUserID(ctx)
+	if !ok {
 		response.Unauthorized(w, "Not authenticated")
 		return
 	}
 
 	// Detect encoders
-	encoders, gpuAvailable, err := detectEncoders(ctx)
+	encoders, gpuBackends, err := detectEncoders(ctx)
 	if err != nil {
 		log.Printf("Error detecting encoders: %v", err)
 		response.InternalServerError(w, "Failed to detect available encoders")
 		return
 	}
 
-	// Get GPU name if GPU is available
+	// Get GPU name if an NVIDIA GPU is available (nvidia-smi only knows those)
 	var gpuName *string
-	if gpuAvailable {
+	if slices.Contains(gpuBackends, video.GPUBackendNVENC) {
 		gpuName = detectGPUName(ctx)
 	}
 
 	response.OK(w, EncoderInfoResponse{
-		GPUAvailable: gpuAvailable,
+		GPUAvailable: len(gpuBackends) > 0,
 		GPUName:      gpuName,
+		GPUBackends:  gpuBackends,
 		Encoders:     encoders,
 	})
 }
@@ -657,46 +1556,339 @@ func (h *ConfigHandler) GetHLSMigrationStatus(w http.ResponseWriter, r *http.Req
 	migrationState.mu.RLock()
 	defer migrationState.mu.RUnlock()
 
+	response.OK(w, migrationStatusResponse())
+}
+
+// StartHLSMigration handles POST /api/config/hls-migration/start
+// Queues every completed progressive video for conversion to HLS. Progress is
+// reported by GetHLSMigrationStatus.
+func (h *ConfigHandler) StartHLSMigration(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if h.enqueueMigrate == nil {
+		response.Error(w, http.StatusServiceUnavailable, "Background worker is not available")
+		return
+	}
+
+	videos, err := h.db.Queries.ListVideosWithoutHLS(ctx)
+	if err != nil {
+		log.Printf("Error listing videos without HLS: %v", err)
+		response.InternalServerError(w, "Failed to start HLS migration")
+		return
+	}
+
+	migrationID, ok := startMigration(len(videos))
+	if !ok {
+		response.Conflict(w, "An HLS migration is already in progress")
+		return
+	}
+
+	queued := 0
+	for _, v := range videos {
+		if err := h.enqueueMigrate(ctx, v.ID.String(), migrationID); err != nil {
+			log.Printf("Error enqueueing HLS migration for video %s: %v", v.ID, err)
+			FinishMigrationVideo(migrationID, v.ID.String(), fmt.Errorf("failed to enqueue: %w", err))
+			continue
+		}
+		queued++
+	}
+
+	recordAudit(ctx, h.db.Queries, auditEntry{
+		Action:     auditHLSMigrationStart,
+		TargetType: auditTargetConfig,
+		Details:    map[string]interface{}{"total": len(videos), "queued": queued},
+	})
+
+	response.OK(w, HLSMigrationStartResponse{
+		Message: fmt.Sprintf("Queued %d videos for HLS migration", queued),
+		Total:   len(videos),
+	})
+}
+
+// CancelHLSMigration handles POST /api/config/hls-migration/cancel
+// Queued videos are skipped and in-flight transcodes finish, unless ?force=true
+// in which case they are stopped and their partial output removed.
+func (h *ConfigHandler) CancelHLSMigration(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	force := r.URL.Query().Get("force") == "true"
+
+	migrationState.mu.Lock()
+	if !migrationInProgress() {
+		migrationState.mu.Unlock()
+		response.Conflict(w, "No HLS migration is in progress")
+		return
+	}
+
+	migrationState.Status = MigrationCancelled
+	if force {
+		for _, cancel := range migrationState.inFlight {
+			cancel()
+		}
+	}
+	status := migrationStatusResponse()
+	migrationState.mu.Unlock()
+
+	recordAudit(ctx, h.db.Queries, auditEntry{
+		Action:     auditHLSMigrationCancel,
+		TargetType: auditTargetConfig,
+		Details: map[string]interface{}{
+			"force":     force,
+			"completed": status.Completed,
+			"remaining": status.Remaining,
+		},
+	})
+
+	response.OK(w, status)
+}
+
+// PauseHLSMigration handles POST /api/config/hls-migration/pause
+// Queued videos wait until the migration is resumed; in-flight transcodes finish.
+func (h *ConfigHandler) PauseHLSMigration(w http.ResponseWriter, r *http.Request) {
+	h.setMigrationStatus(w, r, MigrationRunning, MigrationPaused, auditHLSMigrationPause)
+}
+
+// ResumeHLSMigration handles POST /api/config/hls-migration/resume
+func (h *ConfigHandler) ResumeHLSMigration(w http.ResponseWriter, r *http.Request) {
+	h.setMigrationStatus(w, r, MigrationPaused, MigrationRunning, auditHLSMigrationResume)
+}
+
+// setMigrationStatus moves the migration from one status to another, with
+// 409 Conflict if it isn't currently in the from status
+func (h *ConfigHandler) setMigrationStatus(w http.ResponseWriter, r *http.Request, from, to, action string) {
+	ctx := r.Context()
+
+	migrationState.mu.Lock()
+	if migrationState.Status != from {
+		current := migrationState.Status
+		migrationState.mu.Unlock()
+		response.Conflict(w, fmt.Sprintf("HLS migration is %s, not %s", current, from))
+		return
+	}
+	migrationState.Status = to
+	status := migrationStatusResponse()
+	migrationState.mu.Unlock()
+
+	recordAudit(ctx, h.db.Queries, auditEntry{
+		Action:     action,
+		TargetType: auditTargetConfig,
+	})
+
+	response.OK(w, status)
+}
+
+// StartBenchmark handles POST /api/config/benchmark
+// Encodes a short sample with each candidate preset in the worker. Refused
+// while video jobs are queued or running; results are reported by
+// GetBenchmarkStatus.
+func (h *ConfigHandler) StartBenchmark(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if h.enqueueBenchmark == nil {
+		response.Error(w, http.StatusServiceUnavailable, "Background worker is not available")
+		return
+	}
+
+	var req BenchmarkRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.BadRequest(w, "Invalid request body")
+			return
+		}
+	}
+
+	sampleSeconds := defaultBenchmarkSeconds
+	if req.SampleSeconds != nil {
+		sampleSeconds = *req.SampleSeconds
+	}
+	if sampleSeconds < minBenchmarkSeconds || sampleSeconds > maxBenchmarkSeconds {
+		response.BadRequest(w, fmt.Sprintf("sample_seconds must be between %d and %d", minBenchmarkSeconds, maxBenchmarkSeconds))
+		return
+	}
+
+	videoID := ""
+	if req.VideoID != nil && *req.VideoID != "" {
+		videoUUID, err := uuid.Parse(*req.VideoID)
+		if err != nil {
+			response.BadRequest(w, "Invalid video ID format")
+			return
+		}
+		videoRecord, err := h.db.Queries.GetVideoByID(ctx, videoUUID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				response.NotFound(w, "Video not found")
+				return
+			}
+			log.Printf("Error getting video: %v", err)
+			response.InternalServerError(w, "Failed to get video")
+			return
+		}
+		if videoRecord.ProcessingStatus != domain.ProcessingStatusCompleted {
+			response.BadRequest(w, "Video has not finished processing")
+			return
+		}
+		videoID = videoUUID.String()
+	}
+
+	if err := video.BeginBenchmark(); err != nil {
+		response.Conflict(w, "An encoder benchmark is already running")
+		return
+	}
+
+	if err := h.enqueueBenchmark(ctx, sampleSeconds, videoID); err != nil {
+		video.FinishBenchmark(nil)
+		if errors.Is(err, video.ErrQueueBusy) {
+			response.Conflict(w, "Wait for queued and running video jobs to finish before benchmarking")
+			return
+		}
+		log.Printf("Error enqueueing encoder benchmark: %v", err)
+		response.InternalServerError(w, "Failed to start encoder benchmark")
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, map[string]string{"message": "Encoder benchmark started"})
+}
+
+// GetBenchmarkStatus handles GET /api/config/benchmark-status
+func (h *ConfigHandler) GetBenchmarkStatus(w http.ResponseWriter, r *http.Request) {
+	report, ok, running := video.LatestBenchmark()
+	result := BenchmarkStatusResponse{Running: running}
+	if !ok {
+		response.OK(w, result)
+		return
+	}
+
+	results := make([]BenchmarkResultResponse, len(report.Results))
+	for i, res := range report.Results {
+		results[i] = BenchmarkResultResponse{
+			Encoder:         res.Encoder,
+			Preset:          res.Preset,
+			Frames:          res.Frames,
+			EncodeFPS:       res.EncodeFPS,
+			WallTimeSeconds: res.WallTime.Seconds(),
+			OutputBytes:     res.OutputBytes,
+		}
+		if res.Error != "" {
+			errMsg := res.Error
+			results[i].Error = &errMsg
+		}
+	}
+
+	result.Report = &BenchmarkReportResponse{
+		Source:        report.Source,
+		SampleSeconds: report.SampleSeconds,
+		Width:         report.Width,
+		Height:        report.Height,
+		Results:       results,
+		StartedAt:     report.StartedAt,
+		FinishedAt:    report.FinishedAt,
+	}
+	if report.VideoID != "" {
+		videoID := report.VideoID
+		result.Report.VideoID = &videoID
+	}
+	if report.Error != "" {
+		errMsg := report.Error
+		result.Report.Error = &errMsg
+	}
+
+	response.OK(w, result)
+}
+
+// --- Migration State Management (exported for use by worker package) ---
+
+// migrationInProgress reports whether a migration is running or paused.
+// Callers hold migrationState.mu.
+func migrationInProgress() bool {
+	return migrationState.Status == MigrationRunning || migrationState.Status == MigrationPaused
+}
+
+// migrationStatusResponse builds the status response. Callers hold migrationState.mu.
+func migrationStatusResponse() HLSMigrationStatusResponse {
 	var currentVideo *string
 	if migrationState.CurrentVideo != "" {
-		currentVideo = &migrationState.CurrentVideo
+		video := migrationState.CurrentVideo
+		currentVideo = &video
 	}
 
-	errors := migrationState.Errors
-	if errors == nil {
-		errors = []string{}
-	}
+	migrationErrors := make([]string, len(migrationState.Errors))
+	copy(migrationErrors, migrationState.Errors)
 
-	response.OK(w, HLSMigrationStatusResponse{
-		IsRunning:    migrationState.IsRunning,
+	return HLSMigrationStatusResponse{
+		Status:       migrationState.Status,
+		IsRunning:    migrationState.Status == MigrationRunning,
 		Total:        migrationState.Total,
 		Completed:    migrationState.Completed,
+		Remaining:    migrationState.Total - migrationState.Completed - len(migrationState.Errors),
 		CurrentVideo: currentVideo,
-		Errors:       errors,
-	})
+		Errors:       migrationErrors,
+	}
 }
 
-// --- Migration State Management (exported for use by worker package) ---
-
-// SetMigrationRunning sets the migration running state
-func SetMigrationRunning(running bool) {
+// startMigration resets the state for a new migration of total videos and
+// returns its ID. Returns false if a migration is already in progress.
+func startMigration(total int) (int64, bool) {
 	migrationState.mu.Lock()
 	defer migrationState.mu.Unlock()
-	migrationState.IsRunning = running
+	if migrationInProgress() {
+		return 0, false
+	}
+	// Time-based so IDs aren't reused across restarts, when the state is lost
+	migrationState.ID = time.Now().UnixNano()
+	migrationState.Status = MigrationRunning
+	if total == 0 {
+		migrationState.Status = MigrationCompleted
+	}
+	migrationState.Total = total
+	migrationState.Completed = 0
+	migrationState.CurrentVideo = ""
+	migrationState.Errors = nil
+	migrationState.inFlight = make(map[string]context.CancelFunc)
+	return migrationState.ID, true
 }
 
-// SetMigrationTotal sets the total videos to migrate
-func SetMigrationTotal(total int) {
+// BeginMigrationVideo is called by a migration job before it converts a
+// video and returns how the job should proceed: MigrationRunning to convert
+// it, MigrationPaused to try again later, anything else to skip it. cancel
+// stops the job's transcode if the migration is force-cancelled.
+func BeginMigrationVideo(migrationID int64, videoID string, cancel context.CancelFunc) string {
 	migrationState.mu.Lock()
 	defer migrationState.mu.Unlock()
-	migrationState.Total = total
+	if migrationID != migrationState.ID {
+		return MigrationCancelled
+	}
+	if migrationState.Status == MigrationRunning {
+		migrationState.inFlight[videoID] = cancel
+	}
+	return migrationState.Status
 }
 
-// IncrementMigrationCompleted increments the completed count
-func IncrementMigrationCompleted() {
+// FinishMigrationVideo records the outcome of a video started with
+// BeginMigrationVideo, and completes the migration once every video is done.
+// Failures caused by cancelling the migration aren't recorded as errors.
+func FinishMigrationVideo(migrationID int64, videoID string, err error) {
 	migrationState.mu.Lock()
 	defer migrationState.mu.Unlock()
-	migrationState.Completed++
+	if migrationID != migrationState.ID {
+		return
+	}
+	delete(migrationState.inFlight, videoID)
+	if migrationState.CurrentVideo != "" && len(migrationState.inFlight) == 0 {
+		migrationState.CurrentVideo = ""
+	}
+
+	switch {
+	case err == nil:
+		migrationState.Completed++
+	case migrationState.Status == MigrationCancelled:
+		return
+	default:
+		migrationState.Errors = append(migrationState.Errors, fmt.Sprintf("%s: %v", videoID, err))
+	}
+
+	if migrationInProgress() && migrationState.Completed+len(migrationState.Errors) >= migrationState.Total {
+		migrationState.Status = MigrationCompleted
+		migrationState.CurrentVideo = ""
+	}
 }
 
 // SetMigrationCurrentVideo sets the current video being processed
@@ -706,20 +1898,15 @@ func SetMigrationCurrentVideo(video string) {
 	migrationState.CurrentVideo = video
 }
 
-// AddMigrationError adds an error to the error list
-func AddMigrationError(err string) {
-	migrationState.mu.Lock()
-	defer migrationState.mu.Unlock()
-	migrationState.Errors = append(migrationState.Errors, err)
-}
-
 // ResetMigrationState resets the migration state
 func ResetMigrationState() {
 	migrationState.mu.Lock()
 	defer migrationState.mu.Unlock()
-	migrationState.IsRunning = false
+	migrationState.ID = 0
+	migrationState.Status = MigrationIdle
 	migrationState.Total = 0
 	migrationState.Completed = 0
 	migrationState.CurrentVideo = ""
 	migrationState.Errors = nil
+	migrationState.inFlight = nil
 }
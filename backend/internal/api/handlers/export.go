@@ -0,0 +1,381 @@
+package handlers
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/clipset/clipset-go/internal/api/middleware"
+	"github.com/clipset/clipset-go/internal/api/response"
+	"github.com/clipset/clipset-go/internal/db/sqlc"
+	"github.com/clipset/clipset-go/internal/services/ratelimit"
+	"github.com/clipset/clipset-go/internal/services/storage"
+)
+
+// Each user may generate one export per day
+var exportLimit = ratelimit.Limit{Max: 1, Period: 24 * time.Hour}
+
+// Export archive types. These include private fields (email, private
+// playlists) since the archive is only ever served to its owner.
+
+// ExportProfile is profile.json in the export archive
+type ExportProfile struct {
+	ID             string    `json:"id"`
+	Email          string    `json:"email"`
+	Username       string    `json:"username"`
+	DisplayName    *string   `json:"display_name"`
+	Role           string    `json:"role"`
+	CreatedAt      time.Time `json:"created_at"`
+	AvatarFilename *string   `json:"avatar_filename"`
+}
+
+// ExportVideo is an entry in videos.json
+type ExportVideo struct {
+	ID               string    `json:"id"`
+	ShortID          string    `json:"short_id"`
+	Title            string    `json:"title"`
+	Description      *string   `json:"description"`
+	CategoryID       *string   `json:"category_id"`
+	OriginalFilename string    `json:"original_filename"`
+	FileSizeBytes    int64     `json:"file_size_bytes"`
+	DurationSeconds  *int32    `json:"duration_seconds"`
+	ViewCount        int32     `json:"view_count"`
+	ProcessingStatus string    `json:"processing_status"`
+	CommentsEnabled  bool      `json:"comments_enabled"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// ExportPlaylistEntry is a video within an exported playlist, in position order
+type ExportPlaylistEntry struct {
+	Position     int32     `json:"position"`
+	VideoID      string    `json:"video_id"`
+	VideoShortID string    `json:"video_short_id"`
+	VideoTitle   string    `json:"video_title"`
+	AddedAt      time.Time `json:"added_at"`
+}
+
+// ExportPlaylist is an entry in playlists.json. Smart playlists have no
+// stored entries, only their rules.
+type ExportPlaylist struct {
+	ID              string                `json:"id"`
+	ShortID         string                `json:"short_id"`
+	Name            string                `json:"name"`
+	Description     *string               `json:"description"`
+	IsPublic        bool                  `json:"is_public"`
+	PlaylistType    string                `json:"playlist_type"`
+	SmartCategoryID *string               `json:"smart_category_id,omitempty"`
+	SmartSort       *string               `json:"smart_sort,omitempty"`
+	SmartLimit      *int32                `json:"smart_limit,omitempty"`
+	CreatedAt       time.Time             `json:"created_at"`
+	UpdatedAt       time.Time             `json:"updated_at"`
+	Videos          []ExportPlaylistEntry `json:"videos"`
+}
+
+// ExportComment is an entry in comments.json
+type ExportComment struct {
+	ID               string    `json:"id"`
+	VideoID          string    `json:"video_id"`
+	VideoShortID     string    `json:"video_short_id"`
+	VideoTitle       string    `json:"video_title"`
+	ParentID         *string   `json:"parent_id"`
+	Content          string    `json:"content"`
+	TimestampSeconds *int32    `json:"timestamp_seconds"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// ExportMediaFile locates one video's files on the server's disk
+type ExportMediaFile struct {
+	VideoShortID      string  `json:"video_short_id"`
+	OriginalFilename  string  `json:"original_filename"`
+	Directory         string  `json:"directory"`
+	Filename          string  `json:"filename"`
+	HLSDirectory      string  `json:"hls_directory"`
+	ThumbnailFilename *string `json:"thumbnail_filename"`
+	ThumbnailPath     *string `json:"thumbnail_path"`
+}
+
+// ExportMediaManifest is media_manifest.json. Media files aren't included in
+// the archive; this tells an admin with disk access where to collect them.
+type ExportMediaManifest struct {
+	ThumbnailDirectory string            `json:"thumbnail_directory"`
+	AvatarDirectory    string            `json:"avatar_directory"`
+	AvatarFilename     *string           `json:"avatar_filename"`
+	Videos             []ExportMediaFile `json:"videos"`
+}
+
+// Export handles GET /api/users/me/export
+// Streams a zip of the caller's profile, videos, playlists and comments
+func (h *UsersHandler) Export(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+
+	user, err := h.db.Queries.GetUserByID(ctx, userID)
+	if err != nil {
+		log.Printf("Error getting user: %v", err)
+		response.InternalServerError(w, "Failed to get user")
+		return
+	}
+
+	limitKey := "export:" + userID.String()
+	allowed, retryAfter, err := h.exportLimiter.Allow(ctx, limitKey, exportLimit)
+	if err != nil {
+		log.Printf("Error checking export rate limit: %v", err)
+		response.InternalServerError(w, "Failed to export data")
+		return
+	}
+	if !allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		response.Error(w, http.StatusTooManyRequests, "You can only export your data once per day")
+		return
+	}
+
+	// Exports failing before the archive starts don't count towards the limit
+	refund := func() {
+		if err := h.exportLimiter.Refund(ctx, limitKey, exportLimit); err != nil {
+			log.Printf("Error refunding export rate limit: %v", err)
+		}
+	}
+
+	// Load everything before writing any of the archive, so a query failure
+	// can still be reported as an error response
+	videos, err := h.db.Queries.ListVideosByUploader(ctx, userID)
+	if err != nil {
+		log.Printf("Error listing videos for export: %v", err)
+		refund()
+		response.InternalServerError(w, "Failed to export data")
+		return
+	}
+
+	playlists, err := h.buildExportPlaylists(ctx, userID)
+	if err != nil {
+		log.Printf("Error listing playlists for export: %v", err)
+		refund()
+		response.InternalServerError(w, "Failed to export data")
+		return
+	}
+
+	comments, err := h.db.Queries.ListCommentsByUser(ctx, userID)
+	if err != nil {
+		log.Printf("Error listing comments for export: %v", err)
+		refund()
+		response.InternalServerError(w, "Failed to export data")
+		return
+	}
+
+	filename := fmt.Sprintf("clipset-export-%s-%s.zip", user.Username, time.Now().UTC().Format("2006-01-02"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.WriteHeader(http.StatusOK)
+
+	// Entries are encoded straight into the response; nothing is buffered
+	// beyond the rows already loaded
+	zw := zip.NewWriter(w)
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", buildExportProfile(&user)},
+		{"videos.json", buildExportVideos(videos)},
+		{"playlists.json", playlists},
+		{"comments.json", buildExportComments(comments)},
+		{"media_manifest.json", h.buildExportMediaManifest(&user, videos)},
+	}
+	for _, f := range files {
+		if err := writeZipJSON(zw, f.name, f.data); err != nil {
+			// Headers are already sent; the truncated archive will fail to open
+			log.Printf("Error writing %s to export for user %s: %v", f.name, userID, err)
+			return
+		}
+	}
+	if err := zw.Close(); err != nil {
+		log.Printf("Error finishing export for user %s: %v", userID, err)
+		return
+	}
+
+	log.Printf("Exported data for user %s (%d videos, %d playlists, %d comments)", userID, len(videos), len(playlists), len(comments))
+}
+
+// writeZipJSON adds a JSON file to the archive
+func writeZipJSON(zw *zip.Writer, name string, data interface{}) error {
+	f, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(data)
+}
+
+// buildExportProfile converts the user row for profile.json
+func buildExportProfile(user *sqlc.User) ExportProfile {
+	return ExportProfile{
+		ID:             user.ID.String(),
+		Email:          user.Email,
+		Username:       user.Username,
+		DisplayName:    user.DisplayName,
+		Role:           string(user.Role),
+		CreatedAt:      user.CreatedAt,
+		AvatarFilename: user.AvatarFilename,
+	}
+}
+
+// buildExportVideos converts video rows for videos.json
+func buildExportVideos(videos []sqlc.Video) []ExportVideo {
+	result := make([]ExportVideo, len(videos))
+	for i, v := range videos {
+		var categoryID *string
+		if v.CategoryID.Valid {
+			s := uuid.UUID(v.CategoryID.Bytes).String()
+			categoryID = &s
+		}
+
+		result[i] = ExportVideo{
+			ID:               v.ID.String(),
+			ShortID:          v.ShortID,
+			Title:            v.Title,
+			Description:      v.Description,
+			CategoryID:       categoryID,
+			OriginalFilename: v.OriginalFilename,
+			FileSizeBytes:    v.FileSizeBytes,
+			DurationSeconds:  v.DurationSeconds,
+			ViewCount:        v.ViewCount,
+			ProcessingStatus: string(v.ProcessingStatus),
+			CommentsEnabled:  v.CommentsEnabled,
+			CreatedAt:        v.CreatedAt,
+		}
+	}
+	return result
+}
+
+// buildExportPlaylists loads the user's playlists (public and private) with their entries
+func (h *UsersHandler) buildExportPlaylists(ctx context.Context, userID uuid.UUID) ([]ExportPlaylist, error) {
+	playlists, err := h.db.Queries.ListPlaylistsByUser(ctx, sqlc.ListPlaylistsByUserParams{
+		CreatedBy:   userID,
+		CreatedBy_2: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ExportPlaylist, len(playlists))
+	for i, p := range playlists {
+		entries, err := h.db.Queries.GetPlaylistVideos(ctx, p.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get videos for playlist %s: %w", p.ID, err)
+		}
+
+		videos := make([]ExportPlaylistEntry, len(entries))
+		for j, e := range entries {
+			videos[j] = ExportPlaylistEntry{
+				Position:     e.Position,
+				VideoID:      e.VideoID.String(),
+				VideoShortID: e.VideoShortID,
+				VideoTitle:   e.VideoTitle,
+				AddedAt:      e.AddedAt,
+			}
+		}
+
+		var smartCategoryID *string
+		if p.SmartCategoryID.Valid {
+			s := uuid.UUID(p.SmartCategoryID.Bytes).String()
+			smartCategoryID = &s
+		}
+
+		result[i] = ExportPlaylist{
+			ID:              p.ID.String(),
+			ShortID:         p.ShortID,
+			Name:            p.Name,
+			Description:     p.Description,
+			IsPublic:        p.IsPublic,
+			PlaylistType:    string(p.PlaylistType),
+			SmartCategoryID: smartCategoryID,
+			SmartSort:       p.SmartSort,
+			SmartLimit:      p.SmartLimit,
+			CreatedAt:       p.CreatedAt,
+			UpdatedAt:       p.UpdatedAt,
+			Videos:          videos,
+		}
+	}
+	return result, nil
+}
+
+// buildExportComments converts comment rows for comments.json
+func buildExportComments(comments []sqlc.ListCommentsByUserRow) []ExportComment {
+	result := make([]ExportComment, len(comments))
+	for i, c := range comments {
+		var parentID *string
+		if c.ParentID.Valid {
+			s := uuid.UUID(c.ParentID.Bytes).String()
+			parentID = &s
+		}
+
+		result[i] = ExportComment{
+			ID:               c.ID.String(),
+			VideoID:          c.VideoID.String(),
+			VideoShortID:     c.VideoShortID,
+			VideoTitle:       c.VideoTitle,
+			ParentID:         parentID,
+			Content:          c.Content,
+			TimestampSeconds: c.TimestampSeconds,
+			CreatedAt:        c.CreatedAt,
+			UpdatedAt:        c.UpdatedAt,
+		}
+	}
+	return result
+}
+
+// buildExportMediaManifest lists where each of the user's media files lives on disk
+func (h *UsersHandler) buildExportMediaManifest(user *sqlc.User, videos []sqlc.Video) ExportMediaManifest {
+	files := make([]ExportMediaFile, len(videos))
+	for i, v := range videos {
+		dir := h.config.VideoStoragePath
+		if v.StoragePath != nil && *v.StoragePath != "" {
+			dir = *v.StoragePath
+		}
+
+		// Files are in shard directories under the storage path, or directly
+		// in it if stored before sharding
+		var thumbnailPath *string
+		if v.ThumbnailFilename != nil && *v.ThumbnailFilename != "" {
+			p := storage.ResolvePath(h.config.ThumbnailStoragePath, *v.ThumbnailFilename)
+			thumbnailPath = &p
+		}
+
+		files[i] = ExportMediaFile{
+			VideoShortID:      v.ShortID,
+			OriginalFilename:  v.OriginalFilename,
+			Directory:         filepath.Dir(storage.ResolvePath(dir, v.Filename)),
+			Filename:          v.Filename,
+			HLSDirectory:      storage.ResolvePath(dir, storage.GetHLSDirectoryName(v.Filename)),
+			ThumbnailFilename: v.ThumbnailFilename,
+			ThumbnailPath:     thumbnailPath,
+		}
+	}
+
+	return ExportMediaManifest{
+		ThumbnailDirectory: h.config.ThumbnailStoragePath,
+		AvatarDirectory:    h.config.AvatarStoragePath,
+		AvatarFilename:     user.AvatarFilename,
+		Videos:             files,
+	}
+}
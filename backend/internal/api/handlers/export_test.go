@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/clipset/clipset-go/internal/config"
+	"github.com/clipset/clipset-go/internal/db/sqlc"
+	"github.com/clipset/clipset-go/internal/domain"
+	"github.com/clipset/clipset-go/internal/services/ratelimit"
+)
+
+func TestExportCountsOnlyStartedExports(t *testing.T) {
+	user := sqlc.User{ID: uuid.New(), Username: "viewer", Role: domain.UserRoleUser}
+	h := &UsersHandler{config: &config.Config{}, exportLimiter: ratelimit.NewMemoryLimiter()}
+
+	export := func(failing string) int {
+		t.Helper()
+		fake := newFakeDB().returns("GetUserByID", user)
+		if failing != "" {
+			fake.fails(failing, errors.New("connection reset"))
+		}
+		h.db = fake.db()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/users/me/export", nil)
+		rec := httptest.NewRecorder()
+		h.Export(rec, withUser(req, user.ID, user.Role))
+		return rec.Code
+	}
+
+	for _, failing := range []string{"ListVideosByUploader", "ListPlaylistsByUser", "ListCommentsByUser"} {
+		if got := export(failing); got != http.StatusInternalServerError {
+			t.Fatalf("export with %s failing returned %d, want 500", failing, got)
+		}
+	}
+	if got := export(""); got != http.StatusOK {
+		t.Fatalf("export after failed ones returned %d, want 200", got)
+	}
+	if got := export(""); got != http.StatusTooManyRequests {
+		t.Fatalf("second export returned %d, want 429", got)
+	}
+}
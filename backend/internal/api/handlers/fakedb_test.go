@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/clipset/clipset-go/internal/api/middleware"
+	"github.com/clipset/clipset-go/internal/db"
+	"github.com/clipset/clipset-go/internal/db/sqlc"
+	"github.com/clipset/clipset-go/internal/domain"
+)
+
+// fakeDB is a sqlc.DBTX answering each query by its sqlc name with canned
+// rows, recording the queries it runs. Rows are sqlc row structs, scanned
+// field by field, or single values for one-column queries. Queries without
+// rows return none, so :one queries get pgx.ErrNoRows.
+type fakeDB struct {
+	mu      sync.Mutex
+	rows    map[string][]any
+	errs    map[string]error
+	queries []string
+	args    map[string][]any
+}
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{
+		rows: make(map[string][]any),
+		errs: make(map[string]error),
+		args: make(map[string][]any),
+	}
+}
+
+// returns sets the rows returned by the named query
+func (f *fakeDB) returns(name string, rows ...any) *fakeDB {
+	f.rows[name] = rows
+	return f
+}
+
+// fails makes the named query fail with err
+func (f *fakeDB) fails(name string, err error) *fakeDB {
+	f.errs[name] = err
+	return f
+}
+
+// rowsOf converts typed rows for returns
+func rowsOf[T any](rows []T) []any {
+	out := make([]any, len(rows))
+	for i, row := range rows {
+		out[i] = row
+	}
+	return out
+}
+
+// db returns a database whose queries run against the fake. It has no pool,
+// so handlers reaching InTx panic.
+func (f *fakeDB) db() *db.DB {
+	return &db.DB{Queries: sqlc.New(f)}
+}
+
+// count returns how many times the named query ran
+func (f *fakeDB) count(name string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, q := range f.queries {
+		if q == name {
+			n++
+		}
+	}
+	return n
+}
+
+// ran returns the names of the queries run, in order
+func (f *fakeDB) ran() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.queries...)
+}
+
+// lastArgs returns the arguments of the named query's last run
+func (f *fakeDB) lastArgs(name string) []any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.args[name]
+}
+
+// run records a query and returns its rows or error
+func (f *fakeDB) run(sql string, args []any) ([]any, error) {
+	name := queryName(sql)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.queries = append(f.queries, name)
+	f.args[name] = args
+	return f.rows[name], f.errs[name]
+}
+
+func (f *fakeDB) Exec(_ context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	if _, err := f.run(sql, args); err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+func (f *fakeDB) Query(_ context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	rows, err := f.run(sql, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{rows: rows, next: -1}, nil
+}
+
+func (f *fakeDB) QueryRow(_ context.Context, sql string, args ...interface{}) pgx.Row {
+	rows, err := f.run(sql, args)
+	if err != nil {
+		return fakeRow{err: err}
+	}
+	if len(rows) == 0 {
+		return fakeRow{err: pgx.ErrNoRows}
+	}
+	return fakeRow{value: rows[0]}
+}
+
+// queryName returns the sqlc name from a query's "-- name: X :kind" header
+func queryName(sql string) string {
+	header, _, _ := strings.Cut(sql, "\n")
+	fields := strings.Fields(header)
+	if len(fields) < 3 || fields[1] != "name:" {
+		return sql
+	}
+	return fields[2]
+}
+
+// scanInto copies a canned row into the destinations of a Scan call
+func scanInto(value any, dest []any) error {
+	v := reflect.ValueOf(value)
+	if len(dest) == 1 {
+		if target := reflect.ValueOf(dest[0]).Elem(); v.Type().AssignableTo(target.Type()) {
+			target.Set(v)
+			return nil
+		}
+	}
+	if v.Kind() != reflect.Struct || v.NumField() != len(dest) {
+		return fmt.Errorf("fakeDB: can't scan %T into %d columns", value, len(dest))
+	}
+	for i, d := range dest {
+		target := reflect.ValueOf(d).Elem()
+		if !v.Field(i).Type().AssignableTo(target.Type()) {
+			return fmt.Errorf("fakeDB: can't scan %T field %d into %s", value, i, target.Type())
+		}
+		target.Set(v.Field(i))
+	}
+	return nil
+}
+
+type fakeRow struct {
+	value any
+	err   error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	return scanInto(r.value, dest)
+}
+
+type fakeRows struct {
+	rows []any
+	next int
+}
+
+func (r *fakeRows) Close()                                       {}
+func (r *fakeRows) Err() error                                   { return nil }
+func (r *fakeRows) CommandTag() pgconn.CommandTag                { return pgconn.NewCommandTag("SELECT") }
+func (r *fakeRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (r *fakeRows) Values() ([]any, error)                       { return nil, nil }
+func (r *fakeRows) RawValues() [][]byte                          { return nil }
+func (r *fakeRows) Conn() *pgx.Conn                              { return nil }
+
+func (r *fakeRows) Next() bool {
+	r.next++
+	return r.next < len(r.rows)
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	return scanInto(r.rows[r.next], dest)
+}
+
+// withUser authenticates a request as the given user, like the Auth middleware
+func withUser(r *http.Request, userID uuid.UUID, role domain.UserRole) *http.Request {
+	ctx := context.WithValue(r.Context(), middleware.UserIDKey, userID)
+	ctx = context.WithValue(ctx, middleware.UserRoleKey, role)
+	return r.WithContext(ctx)
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/clipset/clipset-go/internal/api/response"
+	"github.com/clipset/clipset-go/internal/services/image"
+)
+
+// Image serving shared by the thumbnail, avatar, and category image handlers
+
+// serveImageFile serves an image with the Content-Type sniffed from its
+// contents, falling back to its extension, and an ETag from its modification
+// time and size. ServeContent answers If-None-Match and If-Modified-Since
+// revalidations with 304. Missing and empty files are a 404, sent without
+// cacheControl so the error isn't cached. name describes the image in
+// errors, e.g. "thumbnail".
+func serveImageFile(w http.ResponseWriter, r *http.Request, path, name, cacheControl string) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			response.NotFound(w, strings.ToUpper(name[:1])+name[1:]+" file not found")
+			return
+		}
+		log.Printf("Error opening %s: %v", name, err)
+		response.InternalServerError(w, "Failed to read "+name)
+		return
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		log.Printf("Error getting %s stat: %v", name, err)
+		response.InternalServerError(w, "Failed to read "+name)
+		return
+	}
+	if stat.Size() == 0 {
+		// Left behind by a failed write; not worth sending to the browser
+		log.Printf("Warning: %s file is empty: %s", name, path)
+		response.NotFound(w, strings.ToUpper(name[:1])+name[1:]+" file not found")
+		return
+	}
+
+	contentType, err := sniffImageType(file, path)
+	if err != nil {
+		log.Printf("Error reading %s: %v", name, err)
+		response.InternalServerError(w, "Failed to read "+name)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", cacheControl)
+	w.Header().Set("ETag", fmt.Sprintf(`"%x-%x"`, stat.ModTime().UnixNano(), stat.Size()))
+
+	http.ServeContent(w, r, path, stat.ModTime(), file)
+}
+
+// sniffImageType detects an image's MIME type from its first bytes, so a PNG
+// stored under a .jpg name is still labelled correctly. It falls back to the
+// extension, and leaves the file at its start.
+func sniffImageType(file *os.File, path string) (string, error) {
+	header := make([]byte, 512)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	if contentType := http.DetectContentType(header[:n]); strings.HasPrefix(contentType, "image/") {
+		return contentType, nil
+	}
+	return image.ContentType(path), nil
+}
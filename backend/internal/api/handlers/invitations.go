@@ -21,12 +21,14 @@ import (
 	"github.com/clipset/clipset-go/internal/config"
 	"github.com/clipset/clipset-go/internal/db"
 	"github.com/clipset/clipset-go/internal/db/sqlc"
+	"github.com/clipset/clipset-go/internal/domain"
 )
 
 // Invitation settings
 const (
-	invitationTokenBytes     = 32 // Number of random bytes for token
-	invitationExpirationDays = 7  // Days until invitation expires
+	invitationTokenBytes     = 32   // Number of random bytes for token
+	invitationExpirationDays = 7    // Days until invitation expires
+	invitationMaxUses        = 1000 // Upper bound for generic invitation uses
 )
 
 // InvitationsHandler handles invitation management endpoints
@@ -48,26 +50,22 @@ func NewInvitationsHandler(database *db.DB, cfg *config.Config) *InvitationsHand
 // InvitationResponse represents an invitation
 type InvitationResponse struct {
 	ID        string     `json:"id"`
-	Email     string     `json:"email"`
+	Email     *string    `json:"email"` // nil for generic invitations
 	Token     string     `json:"token"`
 	CreatedBy string     `json:"created_by"`
 	CreatedAt time.Time  `json:"created_at"`
 	ExpiresAt time.Time  `json:"expires_at"`
 	Used      bool       `json:"used"`
 	UsedAt    *time.Time `json:"used_at"`
+	Role      string     `json:"role"`
+	MaxUses   int32      `json:"max_uses"`
+	UseCount  int32      `json:"use_count"`
 }
 
 // InvitationWithLinkResponse includes the invitation link
 type InvitationWithLinkResponse struct {
-	ID             string     `json:"id"`
-	Email          string     `json:"email"`
-	Token          string     `json:"token"`
-	CreatedBy      string     `json:"created_by"`
-	CreatedAt      time.Time  `json:"created_at"`
-	ExpiresAt      time.Time  `json:"expires_at"`
-	Used           bool       `json:"used"`
-	UsedAt         *time.Time `json:"used_at"`
-	InvitationLink string     `json:"invitation_link"`
+	InvitationResponse
+	InvitationLink string `json:"invitation_link"`
 }
 
 // InvitationValidationResponse represents the validation result
@@ -79,9 +77,12 @@ type InvitationValidationResponse struct {
 
 // --- Request Types ---
 
-// InvitationCreateRequest represents the create invitation request
+// InvitationCreateRequest represents the create invitation request.
+// Leaving out the email creates a generic invitation usable max_uses times.
 type InvitationCreateRequest struct {
-	Email string `json:"email"`
+	Email   string `json:"email"`
+	Role    string `json:"role"`
+	MaxUses *int32 `json:"max_uses"`
 }
 
 // --- Helper Functions ---
@@ -109,6 +110,11 @@ func (h *InvitationsHandler) buildInvitationLink(token string) string {
 	return fmt.Sprintf("%s/register/%s", baseURL, token)
 }
 
+// invitationExpiry returns the expiry for an invitation issued now
+func invitationExpiry() time.Time {
+	return time.Now().UTC().Add(time.Duration(invitationExpirationDays) * 24 * time.Hour)
+}
+
 // buildInvitationResponse converts a database invitation to a response
 func buildInvitationResponse(inv sqlc.Invitation) InvitationResponse {
 	var usedAt *time.Time
@@ -125,6 +131,9 @@ func buildInvitationResponse(inv sqlc.Invitation) InvitationResponse {
 		ExpiresAt: inv.ExpiresAt,
 		Used:      inv.Used,
 		UsedAt:    usedAt,
+		Role:      string(inv.Role),
+		MaxUses:   inv.MaxUses,
+		UseCount:  inv.UseCount,
 	}
 }
 
@@ -144,6 +153,9 @@ func buildInvitationResponseFromListRow(row sqlc.ListInvitationsRow) InvitationR
 		ExpiresAt: row.ExpiresAt,
 		Used:      row.Used,
 		UsedAt:    usedAt,
+		Role:      string(row.Role),
+		MaxUses:   row.MaxUses,
+		UseCount:  row.UseCount,
 	}
 }
 
@@ -167,17 +179,35 @@ func (h *InvitationsHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate email
+	// Validate email; none means a generic invitation
 	email := strings.TrimSpace(strings.ToLower(req.Email))
-	if email == "" {
-		response.BadRequest(w, "Email is required")
-		return
-	}
-	if !isValidEmail(email) {
+	if email != "" && !isValidEmail(email) {
 		response.BadRequest(w, "Invalid email format")
 		return
 	}
 
+	role := domain.UserRoleUser
+	if req.Role != "" {
+		role = domain.UserRole(req.Role)
+		if !role.IsValid() {
+			response.BadRequest(w, "Invalid role")
+			return
+		}
+	}
+
+	maxUses := int32(1)
+	if req.MaxUses != nil {
+		if email != "" && *req.MaxUses != 1 {
+			response.BadRequest(w, "max_uses is only allowed for generic invitations")
+			return
+		}
+		if *req.MaxUses < 1 || *req.MaxUses > invitationMaxUses {
+			response.BadRequest(w, "max_uses must be between 1 and 1000")
+			return
+		}
+		maxUses = *req.MaxUses
+	}
+
 	// Generate token
 	token, err := generateURLSafeToken(invitationTokenBytes)
 	if err != nil {
@@ -186,38 +216,46 @@ func (h *InvitationsHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Calculate expiration (7 days from now)
-	expiresAt := time.Now().UTC().Add(time.Duration(invitationExpirationDays) * 24 * time.Hour)
-
-	// Create invitation
-	invitation, err := h.db.Queries.CreateInvitation(ctx, sqlc.CreateInvitationParams{
-		Lower:     email, // The SQL uses LOWER($1), but we already lowercased it
+	params := sqlc.CreateInvitationParams{
 		Token:     token,
 		CreatedBy: userID,
-		ExpiresAt: expiresAt,
-	})
+		ExpiresAt: invitationExpiry(),
+		Role:      role,
+		MaxUses:   maxUses,
+	}
+	if email != "" {
+		params.Email = &email
+	}
+
+	// Create invitation
+	invitation, err := h.db.Queries.CreateInvitation(ctx, params)
 	if err != nil {
 		log.Printf("Error creating invitation: %v", err)
 		response.InternalServerError(w, "Failed to create invitation")
 		return
 	}
 
-	log.Printf("Created invitation %s for email %s by user %s", invitation.ID, email, userID)
-
-	// Build response with invitation link
-	invResponse := InvitationWithLinkResponse{
-		ID:             invitation.ID.String(),
-		Email:          invitation.Email,
-		Token:          invitation.Token,
-		CreatedBy:      invitation.CreatedBy.String(),
-		CreatedAt:      invitation.CreatedAt,
-		ExpiresAt:      invitation.ExpiresAt,
-		Used:           invitation.Used,
-		UsedAt:         nil,
-		InvitationLink: h.buildInvitationLink(invitation.Token),
+	if email != "" {
+		log.Printf("Created %s invitation %s for email %s by user %s", role, invitation.ID, email, userID)
+	} else {
+		log.Printf("Created generic %s invitation %s (%d uses) by user %s", role, invitation.ID, maxUses, userID)
 	}
 
-	response.Created(w, invResponse)
+	recordAudit(ctx, h.db.Queries, auditEntry{
+		Action:     auditInvitationCreate,
+		TargetType: auditTargetInvitation,
+		TargetID:   invitation.ID,
+		Details: map[string]interface{}{
+			"email":    invitation.Email,
+			"role":     invitation.Role,
+			"max_uses": invitation.MaxUses,
+		},
+	})
+
+	response.Created(w, InvitationWithLinkResponse{
+		InvitationResponse: buildInvitationResponse(invitation),
+		InvitationLink:     h.buildInvitationLink(invitation.Token),
+	})
 }
 
 // List handles GET /api/invitations/
@@ -304,7 +342,7 @@ func (h *InvitationsHandler) Validate(w http.ResponseWriter, r *http.Request) {
 	if invitation.Used {
 		response.OK(w, InvitationValidationResponse{
 			Valid:   false,
-			Email:   &invitation.Email,
+			Email:   invitation.Email,
 			Message: "Invitation has already been used",
 		})
 		return
@@ -314,7 +352,7 @@ func (h *InvitationsHandler) Validate(w http.ResponseWriter, r *http.Request) {
 	if time.Now().UTC().After(invitation.ExpiresAt) {
 		response.OK(w, InvitationValidationResponse{
 			Valid:   false,
-			Email:   &invitation.Email,
+			Email:   invitation.Email,
 			Message: "Invitation has expired",
 		})
 		return
@@ -323,7 +361,7 @@ func (h *InvitationsHandler) Validate(w http.ResponseWriter, r *http.Request) {
 	// Valid invitation
 	response.OK(w, InvitationValidationResponse{
 		Valid:   true,
-		Email:   &invitation.Email,
+		Email:   invitation.Email,
 		Message: "Invitation is valid",
 	})
 }
@@ -353,7 +391,7 @@ func (h *InvitationsHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Verify invitation exists
-	_, err = h.db.Queries.GetInvitationByID(ctx, invitationID)
+	invitation, err := h.db.Queries.GetInvitationByID(ctx, invitationID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			response.NotFound(w, "Invitation not found")
@@ -364,6 +402,12 @@ func (h *InvitationsHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Used-up invitations are kept as a record of who was invited
+	if invitation.Used {
+		response.Conflict(w, "Invitation has already been used")
+		return
+	}
+
 	// Delete invitation
 	if err := h.db.Queries.DeleteInvitation(ctx, invitationID); err != nil {
 		log.Printf("Error deleting invitation: %v", err)
@@ -375,3 +419,63 @@ func (h *InvitationsHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	response.OK(w, map[string]string{"message": "Invitation revoked successfully"})
 }
+
+// Resend handles POST /api/invitations/{invitation_id}/resend
+// It issues a new token, invalidating the old link, and restarts the expiry.
+func (h *InvitationsHandler) Resend(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get current user (admin check is done by middleware)
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+
+	invitationID, err := uuid.Parse(r.PathValue("invitation_id"))
+	if err != nil {
+		response.BadRequest(w, "Invalid invitation ID format")
+		return
+	}
+
+	invitation, err := h.db.Queries.GetInvitationByID(ctx, invitationID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.NotFound(w, "Invitation not found")
+			return
+		}
+		log.Printf("Error getting invitation: %v", err)
+		response.InternalServerError(w, "Failed to get invitation")
+		return
+	}
+
+	if invitation.Used {
+		response.Conflict(w, "Invitation has already been used")
+		return
+	}
+
+	token, err := generateURLSafeToken(invitationTokenBytes)
+	if err != nil {
+		log.Printf("Error generating invitation token: %v", err)
+		response.InternalServerError(w, "Failed to resend invitation")
+		return
+	}
+
+	invitation, err = h.db.Queries.RegenerateInvitationToken(ctx, sqlc.RegenerateInvitationTokenParams{
+		ID:        invitationID,
+		Token:     token,
+		ExpiresAt: invitationExpiry(),
+	})
+	if err != nil {
+		log.Printf("Error regenerating invitation token: %v", err)
+		response.InternalServerError(w, "Failed to resend invitation")
+		return
+	}
+
+	log.Printf("Regenerated invitation %s by user %s", invitationID, userID)
+
+	response.OK(w, InvitationWithLinkResponse{
+		InvitationResponse: buildInvitationResponse(invitation),
+		InvitationLink:     h.buildInvitationLink(invitation.Token),
+	})
+}
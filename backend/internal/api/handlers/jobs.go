@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/clipset/clipset-go/internal/api/response"
+	"github.com/clipset/clipset-go/internal/config"
+	"github.com/clipset/clipset-go/internal/db"
+	"github.com/clipset/clipset-go/internal/services/video"
+)
+
+// Job states that can be filtered on, as named by the job queue
+var jobStates = []string{"available", "scheduled", "retryable", "running", "pending", "completed", "cancelled", "discarded"}
+
+// defaultJobStates are the queued and running states listed without a filter
+var defaultJobStates = []string{"available", "scheduled", "retryable", "running"}
+
+// QueuedJob is a transcode job in the background worker's queue
+type QueuedJob struct {
+	ID          int64
+	VideoID     string
+	State       string
+	Attempt     int
+	MaxAttempts int
+	EnqueuedAt  time.Time
+	StartedAt   *time.Time // Start of the latest attempt; nil until first run
+}
+
+// JobQueueSnapshot is a page of transcode jobs plus the number of transcode jobs in each state
+type JobQueueSnapshot struct {
+	Jobs   []QueuedJob
+	Counts map[string]int64
+}
+
+// JobListFunc is a function type for listing transcode jobs, oldest first
+type JobListFunc func(ctx context.Context, states []string, limit int) (JobQueueSnapshot, error)
+
+// JobsHandler serves the admin job queue endpoints
+type JobsHandler struct {
+	db       *db.DB
+	config   *config.Config
+	listJobs JobListFunc // Optional function to inspect the worker's queue
+}
+
+// NewJobsHandler creates a new jobs handler
+func NewJobsHandler(database *db.DB, cfg *config.Config) *JobsHandler {
+	return &JobsHandler{
+		db:       database,
+		config:   cfg,
+		listJobs: nil, // Set via SetJobListFunc after worker is initialized
+	}
+}
+
+// SetJobListFunc sets the function used to list transcode jobs
+// This should be called after the worker is initialized in main.go
+func (h *JobsHandler) SetJobListFunc(fn JobListFunc) {
+	h.listJobs = fn
+}
+
+// JobProgressResponse represents the progress of a running transcode job
+type JobProgressResponse struct {
+	Phase     string    `json:"phase"`
+	Percent   float64   `json:"percent"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// QueuedJobResponse represents a transcode job in API responses
+type QueuedJobResponse struct {
+	ID           int64                `json:"id"`
+	State        string               `json:"state"`
+	VideoID      string               `json:"video_id"`
+	VideoShortID *string              `json:"video_short_id"` // Null if the video was deleted
+	VideoTitle   *string              `json:"video_title"`
+	EnqueuedAt   time.Time            `json:"enqueued_at"`
+	StartedAt    *time.Time           `json:"started_at"`
+	Attempt      int                  `json:"attempt"`
+	MaxAttempts  int                  `json:"max_attempts"`
+	Progress     *JobProgressResponse `json:"progress"` // Null unless the job is processing its video
+}
+
+// JobQueueResponse represents the transcode job queue
+type JobQueueResponse struct {
+	Jobs   []QueuedJobResponse `json:"jobs"`
+	Counts map[string]int64    `json:"counts"` // Transcode jobs in each state
+}
+
+// List handles GET /api/admin/jobs (admin only)
+// Lists transcode jobs, oldest first. ?state= takes a comma-separated list of
+// states and defaults to the queued and running ones.
+func (h *JobsHandler) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	if h.listJobs == nil {
+		response.Error(w, http.StatusServiceUnavailable, "Background worker is not available")
+		return
+	}
+
+	limit := 50
+	if l := query.Get("limit"); l != "" {
+		if v, err := strconv.Atoi(l); err == nil && v > 0 && v <= 200 {
+			limit = v
+		}
+	}
+
+	states := defaultJobStates
+	if s := query.Get("state"); s != "" {
+		states = strings.Split(s, ",")
+		for _, state := range states {
+			if !slices.Contains(jobStates, state) {
+				response.BadRequest(w, "Invalid state: must be one of "+strings.Join(jobStates, ", "))
+				return
+			}
+		}
+	}
+
+	snapshot, err := h.listJobs(ctx, states, limit)
+	if err != nil {
+		log.Printf("Error listing jobs: %v", err)
+		response.InternalServerError(w, "Failed to list jobs")
+		return
+	}
+
+	// Look up the jobs' videos in one query
+	videoIDs := make([]uuid.UUID, 0, len(snapshot.Jobs))
+	for _, job := range snapshot.Jobs {
+		if id, err := uuid.Parse(job.VideoID); err == nil {
+			videoIDs = append(videoIDs, id)
+		}
+	}
+	videos, err := h.db.Queries.ListVideoSummariesByIDs(ctx, videoIDs)
+	if err != nil {
+		log.Printf("Error getting job videos: %v", err)
+		response.InternalServerError(w, "Failed to list jobs")
+		return
+	}
+	byID := make(map[string]int, len(videos))
+	for i, v := range videos {
+		byID[v.ID.String()] = i
+	}
+
+	result := JobQueueResponse{
+		Jobs:   make([]QueuedJobResponse, 0, len(snapshot.Jobs)),
+		Counts: snapshot.Counts,
+	}
+	for _, job := range snapshot.Jobs {
+		item := QueuedJobResponse{
+			ID:          job.ID,
+			State:       job.State,
+			VideoID:     job.VideoID,
+			EnqueuedAt:  job.EnqueuedAt,
+			StartedAt:   job.StartedAt,
+			Attempt:     job.Attempt,
+			MaxAttempts: job.MaxAttempts,
+		}
+		if i, ok := byID[job.VideoID]; ok {
+			item.VideoShortID = &videos[i].ShortID
+			item.VideoTitle = &videos[i].Title
+		}
+		if job.State == "running" {
+			if progress, ok := video.GetProcessingProgress(job.VideoID); ok {
+				item.Progress = &JobProgressResponse{
+					Phase:     progress.Phase,
+					Percent:   progress.Percent,
+					UpdatedAt: progress.UpdatedAt,
+				}
+			}
+		}
+		result.Jobs = append(result.Jobs, item)
+	}
+
+	response.OK(w, result)
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,6 +21,7 @@ import (
 	"github.com/clipset/clipset-go/internal/config"
 	"github.com/clipset/clipset-go/internal/db"
 	"github.com/clipset/clipset-go/internal/db/sqlc"
+	"github.com/clipset/clipset-go/internal/domain"
 )
 
 // Playlist short ID settings (same as videos)
@@ -27,6 +29,14 @@ const playlistShortIDAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijk
 const playlistShortIDLength = 10
 const maxPlaylistShortIDRetries = 5
 
+// Playlist listing defaults
+const defaultPlaylistListLimit = 100
+const maxPlaylistListLimit = 100
+
+// Smart playlist rule defaults
+const defaultSmartPlaylistLimit = 25
+const maxSmartPlaylistLimit = 100
+
 // PlaylistsHandler handles playlist management endpoints
 type PlaylistsHandler struct {
 	db     *db.DB
@@ -45,17 +55,30 @@ func NewPlaylistsHandler(database *db.DB, cfg *config.Config) *PlaylistsHandler
 
 // PlaylistResponse represents a playlist with metadata
 type PlaylistResponse struct {
-	ID                  string    `json:"id"`
-	ShortID             string    `json:"short_id"`
-	Name                string    `json:"name"`
-	Description         *string   `json:"description"`
-	CreatedBy           string    `json:"created_by"`
-	CreatorUsername     string    `json:"creator_username"`
-	VideoCount          int64     `json:"video_count"`
-	IsPublic            bool      `json:"is_public"`
-	CreatedAt           time.Time `json:"created_at"`
-	UpdatedAt           time.Time `json:"updated_at"`
-	FirstVideoThumbnail *string   `json:"first_video_thumbnail"`
+	ID                  string              `json:"id"`
+	ShortID             string              `json:"short_id"`
+	Name                string              `json:"name"`
+	Description         *string             `json:"description"`
+	CreatedBy           string              `json:"created_by"`
+	CreatorUsername     string              `json:"creator_username"`
+	CreatorDisplayName  string              `json:"creator_display_name"`
+	VideoCount          int64               `json:"video_count"`
+	IsPublic            bool                `json:"is_public"`
+	CreatedAt           time.Time           `json:"created_at"`
+	UpdatedAt           time.Time           `json:"updated_at"`
+	FirstVideoThumbnail *string             `json:"first_video_thumbnail"`
+	PlaylistType        domain.PlaylistType `json:"playlist_type"`
+	SmartRule           *SmartPlaylistRule  `json:"smart_rule,omitempty"`
+	FollowerCount       int64               `json:"follower_count"`
+	FollowedByMe        bool                `json:"followed_by_me"`
+	ContainsVideo       *bool               `json:"contains_video,omitempty"`
+}
+
+// SmartPlaylistRule describes how a smart playlist's contents are resolved
+type SmartPlaylistRule struct {
+	CategoryID uuid.UUID `json:"category_id"`
+	Sort       string    `json:"sort"`
+	Limit      int32     `json:"limit"`
 }
 
 // PlaylistListResponse represents a list of playlists
@@ -66,16 +89,17 @@ type PlaylistListResponse struct {
 
 // PlaylistVideoDetailResponse represents video info in playlist context
 type PlaylistVideoDetailResponse struct {
-	ID                string    `json:"id"`
-	ShortID           string    `json:"short_id"`
-	Title             string    `json:"title"`
-	Description       *string   `json:"description"`
-	ThumbnailFilename *string   `json:"thumbnail_filename"`
-	DurationSeconds   *int32    `json:"duration_seconds"`
-	ViewCount         int32     `json:"view_count"`
-	ProcessingStatus  string    `json:"processing_status"`
-	CreatedAt         time.Time `json:"created_at"`
-	UploaderUsername  string    `json:"uploader_username"`
+	ID                  string    `json:"id"`
+	ShortID             string    `json:"short_id"`
+	Title               string    `json:"title"`
+	Description         *string   `json:"description"`
+	ThumbnailFilename   *string   `json:"thumbnail_filename"`
+	DurationSeconds     *int32    `json:"duration_seconds"`
+	ViewCount           int32     `json:"view_count"`
+	ProcessingStatus    string    `json:"processing_status"`
+	CreatedAt           time.Time `json:"created_at"`
+	UploaderUsername    string    `json:"uploader_username"`
+	UploaderDisplayName string    `json:"uploader_display_name"`
 }
 
 // PlaylistVideoResponse represents a video entry in a playlist
@@ -97,26 +121,71 @@ type PlaylistWithVideosResponse struct {
 	Description         *string                 `json:"description"`
 	CreatedBy           string                  `json:"created_by"`
 	CreatorUsername     string                  `json:"creator_username"`
+	CreatorDisplayName  string                  `json:"creator_display_name"`
 	VideoCount          int64                   `json:"video_count"`
 	IsPublic            bool                    `json:"is_public"`
 	CreatedAt           time.Time               `json:"created_at"`
 	UpdatedAt           time.Time               `json:"updated_at"`
 	FirstVideoThumbnail *string                 `json:"first_video_thumbnail"`
+	PlaylistType        domain.PlaylistType     `json:"playlist_type"`
+	SmartRule           *SmartPlaylistRule      `json:"smart_rule,omitempty"`
+	FollowerCount       int64                   `json:"follower_count"`
+	FollowedByMe        bool                    `json:"followed_by_me"`
+	HiddenCount         int64                   `json:"hidden_count"`
 	Videos              []PlaylistVideoResponse `json:"videos"`
 }
 
+// PlaylistFollowResponse represents the follow state of a playlist for the current user
+type PlaylistFollowResponse struct {
+	FollowedByMe  bool  `json:"followed_by_me"`
+	FollowerCount int64 `json:"follower_count"`
+}
+
+// PlaylistNavigationEntry is a slim playlist entry used for prev/next navigation
+type PlaylistNavigationEntry struct {
+	VideoID           string  `json:"video_id"`
+	ShortID           string  `json:"short_id"`
+	Title             string  `json:"title"`
+	ThumbnailFilename *string `json:"thumbnail_filename"`
+	DurationSeconds   *int32  `json:"duration_seconds"`
+	Position          int32   `json:"position"`
+}
+
+// PlaylistNavigationResponse represents the neighbors of a video within a playlist
+type PlaylistNavigationResponse struct {
+	Previous *PlaylistNavigationEntry `json:"previous"`
+	Next     *PlaylistNavigationEntry `json:"next"`
+}
+
 // --- Request Types ---
 
 // PlaylistCreateRequest represents the create playlist request
 type PlaylistCreateRequest struct {
 	Name        string  `json:"name"`
 	Description *string `json:"description"`
+	IsPublic    *bool   `json:"is_public"`
 }
 
 // PlaylistUpdateRequest represents the update playlist request
 type PlaylistUpdateRequest struct {
 	Name        *string `json:"name"`
 	Description *string `json:"description"`
+	IsPublic    *bool   `json:"is_public"`
+}
+
+// SmartPlaylistCreateRequest represents the create smart playlist request (admin only)
+type SmartPlaylistCreateRequest struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+	IsPublic    *bool   `json:"is_public"`
+	CategoryID  string  `json:"category_id"`
+	Sort        string  `json:"sort"`
+	Limit       *int32  `json:"limit"`
+}
+
+// PlaylistVisibilityRequest represents the visibility toggle request
+type PlaylistVisibilityRequest struct {
+	IsPublic *bool `json:"is_public"`
 }
 
 // PlaylistVideoAddRequest represents adding a single video
@@ -166,6 +235,45 @@ func (h *PlaylistsHandler) generateUniquePlaylistShortID(ctx context.Context) (s
 	return "", fmt.Errorf("failed to generate unique short ID after %d attempts", maxPlaylistShortIDRetries)
 }
 
+// playlistListOptions holds the sort and pagination options for playlist listings
+type playlistListOptions struct {
+	sort  string
+	skip  int
+	limit int
+}
+
+// parsePlaylistListOptions reads sort, skip and limit from the query string,
+// sorting by defaultSort when no sort is given
+func parsePlaylistListOptions(r *http.Request, defaultSort string) (playlistListOptions, error) {
+	opts := playlistListOptions{
+		sort:  defaultSort,
+		limit: defaultPlaylistListLimit,
+	}
+
+	if skipStr := r.URL.Query().Get("skip"); skipStr != "" {
+		if s, err := strconv.Atoi(skipStr); err == nil && s >= 0 {
+			opts.skip = s
+		}
+	}
+
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l >= 1 && l <= maxPlaylistListLimit {
+			opts.limit = l
+		}
+	}
+
+	if sortParam := r.URL.Query().Get("sort"); sortParam != "" {
+		switch sortParam {
+		case "newest", "oldest", "name", "most_videos", "recently_updated":
+			opts.sort = sortParam
+		default:
+			return opts, errors.New("Invalid sort parameter. Must be 'newest', 'oldest', 'name', 'most_videos', or 'recently_updated'")
+		}
+	}
+
+	return opts, nil
+}
+
 // isPlaylistOwner checks if user is the playlist owner
 func isPlaylistOwner(playlist sqlc.Playlist, userID uuid.UUID) bool {
 	return playlist.CreatedBy == userID
@@ -185,11 +293,15 @@ func buildPlaylistResponseFromUserRow(row sqlc.ListUserPlaylistsWithThumbnailRow
 		Description:         row.Description,
 		CreatedBy:           row.CreatedBy.String(),
 		CreatorUsername:     row.CreatorUsername,
+		CreatorDisplayName:  row.CreatorDisplayName,
 		VideoCount:          row.VideoCount,
 		IsPublic:            row.IsPublic,
 		CreatedAt:           row.CreatedAt,
 		UpdatedAt:           row.UpdatedAt,
 		FirstVideoThumbnail: row.FirstVideoThumbnail,
+		PlaylistType:        row.PlaylistType,
+		SmartRule:           buildSmartPlaylistRule(row.PlaylistType, row.SmartCategoryID, row.SmartSort, row.SmartLimit),
+		FollowerCount:       row.FollowerCount,
 	}
 }
 
@@ -202,11 +314,262 @@ func buildPlaylistResponseFromUsernameRow(row sqlc.GetPlaylistsByUsernameRow) Pl
 		Description:         row.Description,
 		CreatedBy:           row.CreatedBy.String(),
 		CreatorUsername:     row.CreatorUsername,
+		CreatorDisplayName:  row.CreatorDisplayName,
+		VideoCount:          row.VideoCount,
+		IsPublic:            row.IsPublic,
+		CreatedAt:           row.CreatedAt,
+		UpdatedAt:           row.UpdatedAt,
+		FirstVideoThumbnail: row.FirstVideoThumbnail,
+		PlaylistType:        row.PlaylistType,
+		SmartRule:           buildSmartPlaylistRule(row.PlaylistType, row.SmartCategoryID, row.SmartSort, row.SmartLimit),
+		FollowerCount:       row.FollowerCount,
+		FollowedByMe:        row.FollowedByMe,
+	}
+}
+
+// buildPlaylistResponseFromFollowedRow converts a ListFollowedPlaylistsRow to PlaylistResponse
+func buildPlaylistResponseFromFollowedRow(row sqlc.ListFollowedPlaylistsRow) PlaylistResponse {
+	return PlaylistResponse{
+		ID:                  row.ID.String(),
+		ShortID:             row.ShortID,
+		Name:                row.Name,
+		Description:         row.Description,
+		CreatedBy:           row.CreatedBy.String(),
+		CreatorUsername:     row.CreatorUsername,
+		CreatorDisplayName:  row.CreatorDisplayName,
 		VideoCount:          row.VideoCount,
 		IsPublic:            row.IsPublic,
 		CreatedAt:           row.CreatedAt,
 		UpdatedAt:           row.UpdatedAt,
 		FirstVideoThumbnail: row.FirstVideoThumbnail,
+		PlaylistType:        row.PlaylistType,
+		SmartRule:           buildSmartPlaylistRule(row.PlaylistType, row.SmartCategoryID, row.SmartSort, row.SmartLimit),
+		FollowerCount:       row.FollowerCount,
+		FollowedByMe:        true,
+	}
+}
+
+// buildPlaylistResponse builds a PlaylistResponse for a playlist row, loading count, thumbnail and creator
+func (h *PlaylistsHandler) buildPlaylistResponse(ctx context.Context, playlist sqlc.Playlist) PlaylistResponse {
+	// Get visible video count and first thumbnail
+	userID, _ := middleware.GetUserID(ctx)
+	rule := buildSmartPlaylistRule(playlist.PlaylistType, playlist.SmartCategoryID, playlist.SmartSort, playlist.SmartLimit)
+	videos, _ := h.loadPlaylistVideos(ctx, playlist.ID, rule)
+	videos, _ = filterPlaylistVideos(videos, userID, middleware.IsAdmin(ctx))
+	var firstThumbnail *string
+	if len(videos) > 0 {
+		firstThumbnail = videos[0].VideoThumbnail
+	}
+
+	// Get creator username
+	creator, _ := h.db.Queries.GetUserByID(ctx, playlist.CreatedBy)
+
+	// Get follow state
+	followerCount, _ := h.db.Queries.CountPlaylistFollowers(ctx, playlist.ID)
+	followedByMe, _ := h.db.Queries.IsFollowingPlaylist(ctx, sqlc.IsFollowingPlaylistParams{
+		PlaylistID: playlist.ID,
+		UserID:     userID,
+	})
+
+	return PlaylistResponse{
+		ID:                  playlist.ID.String(),
+		ShortID:             playlist.ShortID,
+		Name:                playlist.Name,
+		Description:         playlist.Description,
+		CreatedBy:           playlist.CreatedBy.String(),
+		CreatorUsername:     creator.Username,
+		CreatorDisplayName:  displayNameOrUsername(creator.DisplayName, creator.Username),
+		VideoCount:          int64(len(videos)),
+		IsPublic:            playlist.IsPublic,
+		CreatedAt:           playlist.CreatedAt,
+		UpdatedAt:           playlist.UpdatedAt,
+		FirstVideoThumbnail: firstThumbnail,
+		PlaylistType:        playlist.PlaylistType,
+		SmartRule:           rule,
+		FollowerCount:       followerCount,
+		FollowedByMe:        followedByMe,
+	}
+}
+
+// resolveSmartPlaylistSummary fills in the video count and first thumbnail of a
+// listed smart playlist. Listing queries only count playlist_videos, which smart
+// playlists don't use, so their rule is resolved here as in buildPlaylistResponse.
+func (h *PlaylistsHandler) resolveSmartPlaylistSummary(ctx context.Context, playlist *PlaylistResponse, playlistID uuid.UUID) {
+	if playlist.SmartRule == nil {
+		return
+	}
+
+	videos, err := h.loadPlaylistVideos(ctx, playlistID, playlist.SmartRule)
+	if err != nil {
+		log.Printf("Warning: failed to resolve smart playlist %s: %v", playlistID, err)
+		return
+	}
+	userID, _ := middleware.GetUserID(ctx)
+	videos, _ = filterPlaylistVideos(videos, userID, middleware.IsAdmin(ctx))
+
+	playlist.VideoCount = int64(len(videos))
+	playlist.FirstVideoThumbnail = nil
+	if len(videos) > 0 {
+		playlist.FirstVideoThumbnail = videos[0].VideoThumbnail
+	}
+}
+
+// buildSmartPlaylistRule returns the stored rule of a smart playlist, or nil for manual playlists
+func buildSmartPlaylistRule(playlistType domain.PlaylistType, categoryID pgtype.UUID, sort *string, limit *int32) *SmartPlaylistRule {
+	if playlistType != domain.PlaylistTypeSmart || !categoryID.Valid {
+		return nil
+	}
+
+	rule := &SmartPlaylistRule{
+		CategoryID: categoryID.Bytes,
+		Sort:       "newest",
+		Limit:      defaultSmartPlaylistLimit,
+	}
+	if sort != nil {
+		rule.Sort = *sort
+	}
+	if limit != nil {
+		rule.Limit = *limit
+	}
+	return rule
+}
+
+// loadPlaylistVideos returns a playlist's entries in order. Manual playlists read
+// playlist_videos; smart playlists run their rule at read time.
+func (h *PlaylistsHandler) loadPlaylistVideos(ctx context.Context, playlistID uuid.UUID, rule *SmartPlaylistRule) ([]sqlc.GetPlaylistVideosRow, error) {
+	if rule == nil {
+		return h.db.Queries.GetPlaylistVideos(ctx, playlistID)
+	}
+
+	rows, err := h.db.Queries.ListSmartPlaylistVideos(ctx, sqlc.ListSmartPlaylistVideosParams{
+		CategoryID: pgtype.UUID{Bytes: rule.CategoryID, Valid: true},
+		Sort:       rule.Sort,
+		MaxVideos:  rule.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	videos := make([]sqlc.GetPlaylistVideosRow, len(rows))
+	for i, row := range rows {
+		videos[i] = sqlc.GetPlaylistVideosRow{
+			ID:                       row.VideoID,
+			PlaylistID:               playlistID,
+			VideoID:                  row.VideoID,
+			Position:                 int32(i),
+			AddedAt:                  row.VideoCreatedAt,
+			VideoShortID:             row.VideoShortID,
+			VideoTitle:               row.VideoTitle,
+			VideoDescription:         row.VideoDescription,
+			VideoThumbnail:           row.VideoThumbnail,
+			VideoDuration:            row.VideoDuration,
+			VideoViewCount:           row.VideoViewCount,
+			VideoStatus:              row.VideoStatus,
+			VideoCreatedAt:           row.VideoCreatedAt,
+			VideoUploadedBy:          row.VideoUploadedBy,
+			VideoUploaderUsername:    row.VideoUploaderUsername,
+			VideoUploaderDisplayName: row.VideoUploaderDisplayName,
+		}
+	}
+	return videos, nil
+}
+
+// filterPlaylistVideos splits playlist entries into those the viewer can access
+// (same rules as hasVideoAccess) and the number of entries hidden from them
+func filterPlaylistVideos(videos []sqlc.GetPlaylistVideosRow, userID uuid.UUID, isAdmin bool) ([]sqlc.GetPlaylistVideosRow, int64) {
+	visible := make([]sqlc.GetPlaylistVideosRow, 0, len(videos))
+	for _, v := range videos {
+		if isAdmin || v.VideoStatus == domain.ProcessingStatusCompleted || v.VideoUploadedBy == userID {
+			visible = append(visible, v)
+		}
+	}
+	return visible, int64(len(videos) - len(visible))
+}
+
+// reorderError is a rejected playlist reorder request
+type reorderError struct {
+	status  int
+	message string
+	missing []string // Visible videos left out of the request
+}
+
+// planPlaylistReorder validates a reorder request covering the visible playlist entries and
+// returns the new position of every entry. Entries hidden from the owner keep their relative
+// order after the visible ones.
+func planPlaylistReorder(entries, visible []sqlc.GetPlaylistVideosRow, items []VideoPositionItem) ([]uuid.UUID, []int32, *reorderError) {
+	inPlaylist := make(map[uuid.UUID]bool, len(visible))
+	for _, e := range visible {
+		inPlaylist[e.VideoID] = true
+	}
+
+	count := int32(len(visible))
+	videoIDs := make([]uuid.UUID, len(items))
+	positions := make([]int32, len(items))
+	seenVideos := make(map[uuid.UUID]bool, len(items))
+	seenPositions := make(map[int32]bool, len(items))
+	for i, vp := range items {
+		if vp.VideoID == "" {
+			return nil, nil, &reorderError{status: http.StatusBadRequest, message: fmt.Sprintf("video_positions[%d]: video_id is required", i)}
+		}
+		videoID, err := uuid.Parse(vp.VideoID)
+		if err != nil {
+			return nil, nil, &reorderError{status: http.StatusBadRequest, message: fmt.Sprintf("Invalid video ID format: %s", vp.VideoID)}
+		}
+		if !inPlaylist[videoID] {
+			return nil, nil, &reorderError{status: http.StatusNotFound, message: fmt.Sprintf("Video %s not found in playlist", vp.VideoID)}
+		}
+		if seenVideos[videoID] {
+			return nil, nil, &reorderError{status: http.StatusBadRequest, message: fmt.Sprintf("Duplicate video %s in reorder request", vp.VideoID)}
+		}
+		seenVideos[videoID] = true
+
+		if vp.Position < 0 || vp.Position >= count {
+			return nil, nil, &reorderError{status: http.StatusBadRequest, message: fmt.Sprintf("video_positions[%d]: position must be between 0 and %d", i, count-1)}
+		}
+		if seenPositions[vp.Position] {
+			return nil, nil, &reorderError{status: http.StatusBadRequest, message: fmt.Sprintf("Duplicate position %d in reorder request", vp.Position)}
+		}
+		seenPositions[vp.Position] = true
+
+		videoIDs[i] = videoID
+		positions[i] = vp.Position
+	}
+
+	// Every visible video must be included
+	var missing []string
+	for _, e := range visible {
+		if !seenVideos[e.VideoID] {
+			missing = append(missing, e.VideoID.String())
+		}
+	}
+	if len(missing) > 0 {
+		return nil, nil, &reorderError{
+			status:  http.StatusBadRequest,
+			message: fmt.Sprintf("video_positions must include all %d videos in the playlist", count),
+			missing: missing,
+		}
+	}
+
+	next := count
+	for _, e := range entries {
+		if !inPlaylist[e.VideoID] {
+			videoIDs = append(videoIDs, e.VideoID)
+			positions = append(positions, next)
+			next++
+		}
+	}
+	return videoIDs, positions, nil
+}
+
+// buildPlaylistNavigationEntry converts a GetPlaylistVideosRow to a PlaylistNavigationEntry
+func buildPlaylistNavigationEntry(row sqlc.GetPlaylistVideosRow) *PlaylistNavigationEntry {
+	return &PlaylistNavigationEntry{
+		VideoID:           row.VideoID.String(),
+		ShortID:           row.VideoShortID,
+		Title:             row.VideoTitle,
+		ThumbnailFilename: row.VideoThumbnail,
+		DurationSeconds:   row.VideoDuration,
+		Position:          row.Position,
 	}
 }
 
@@ -226,16 +589,17 @@ func buildPlaylistVideoResponse(row sqlc.GetPlaylistVideosRow) PlaylistVideoResp
 		AddedAt:    row.AddedAt,
 		AddedBy:    addedBy,
 		Video: PlaylistVideoDetailResponse{
-			ID:                row.VideoID.String(),
-			ShortID:           row.VideoShortID,
-			Title:             row.VideoTitle,
-			Description:       row.VideoDescription,
-			ThumbnailFilename: row.VideoThumbnail,
-			DurationSeconds:   row.VideoDuration,
-			ViewCount:         row.VideoViewCount,
-			ProcessingStatus:  string(row.VideoStatus),
-			CreatedAt:         row.VideoCreatedAt,
-			UploaderUsername:  row.VideoUploaderUsername,
+			ID:                  row.VideoID.String(),
+			ShortID:             row.VideoShortID,
+			Title:               row.VideoTitle,
+			Description:         row.VideoDescription,
+			ThumbnailFilename:   row.VideoThumbnail,
+			DurationSeconds:     row.VideoDuration,
+			ViewCount:           row.VideoViewCount,
+			ProcessingStatus:    string(row.VideoStatus),
+			CreatedAt:           row.VideoCreatedAt,
+			UploaderUsername:    row.VideoUploaderUsername,
+			UploaderDisplayName: row.VideoUploaderDisplayName,
 		},
 	}
 }
@@ -253,22 +617,47 @@ func (h *PlaylistsHandler) ListByUsername(w http.ResponseWriter, r *http.Request
 	}
 
 	// Verify user is authenticated
-	_, ok := middleware.GetUserID(ctx)
+	userID, ok := middleware.GetUserID(ctx)
 	if !ok {
 		response.Unauthorized(w, "Not authenticated")
 		return
 	}
 
-	// Get playlists by username (case-insensitive)
-	playlists, err := h.db.Queries.GetPlaylistsByUsername(ctx, strings.ToLower(username))
+	opts, err := parsePlaylistListOptions(r, "recently_updated")
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	// Get playlists by username (case-insensitive), hiding other users' private playlists
+	isAdmin := middleware.IsAdmin(ctx)
+	playlists, err := h.db.Queries.GetPlaylistsByUsername(ctx, sqlc.GetPlaylistsByUsernameParams{
+		Username:   strings.ToLower(username),
+		ViewerID:   userID,
+		IsAdmin:    isAdmin,
+		Sort:       opts.sort,
+		PageLimit:  int32(opts.limit),
+		PageOffset: int32(opts.skip),
+	})
 	if err != nil {
 		log.Printf("Error getting playlists by username: %v", err)
 		response.InternalServerError(w, "Failed to get playlists")
 		return
 	}
 
+	total, err := h.db.Queries.CountPlaylistsByUsername(ctx, sqlc.CountPlaylistsByUsernameParams{
+		Username: strings.ToLower(username),
+		ViewerID: userID,
+		IsAdmin:  isAdmin,
+	})
+	if err != nil {
+		log.Printf("Error counting playlists by username: %v", err)
+		response.InternalServerError(w, "Failed to get playlists")
+		return
+	}
+
 	// If no playlists found, check if user exists
-	if len(playlists) == 0 {
+	if total == 0 {
 		_, err := h.db.Queries.GetUserByUsername(ctx, strings.ToLower(username))
 		if err != nil {
 			if errors.Is(err, pgx.ErrNoRows) {
@@ -285,11 +674,12 @@ func (h *PlaylistsHandler) ListByUsername(w http.ResponseWriter, r *http.Request
 	result := make([]PlaylistResponse, len(playlists))
 	for i, p := range playlists {
 		result[i] = buildPlaylistResponseFromUsernameRow(p)
+		h.resolveSmartPlaylistSummary(ctx, &result[i], p.ID)
 	}
 
 	response.OK(w, PlaylistListResponse{
 		Playlists: result,
-		Total:     int64(len(result)),
+		Total:     total,
 	})
 }
 
@@ -304,8 +694,6 @@ func (h *PlaylistsHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	username, _ := middleware.GetUsername(ctx)
-
 	// Parse request
 	var req PlaylistCreateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -337,6 +725,12 @@ func (h *PlaylistsHandler) Create(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Default to public
+	isPublic := true
+	if req.IsPublic != nil {
+		isPublic = *req.IsPublic
+	}
+
 	// Generate short ID
 	shortID, err := h.generateUniquePlaylistShortID(ctx)
 	if err != nil {
@@ -351,7 +745,7 @@ func (h *PlaylistsHandler) Create(w http.ResponseWriter, r *http.Request) {
 		Name:        name,
 		Description: description,
 		CreatedBy:   userID,
-		IsPublic:    true, // Default to public
+		IsPublic:    isPublic,
 	})
 	if err != nil {
 		log.Printf("Error creating playlist: %v", err)
@@ -361,21 +755,148 @@ func (h *PlaylistsHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("Created playlist %s by user %s", playlist.ID, userID)
 
+	// Get creator display name
+	creator, _ := h.db.Queries.GetUserByID(ctx, userID)
+
 	response.Created(w, PlaylistResponse{
 		ID:                  playlist.ID.String(),
 		ShortID:             playlist.ShortID,
 		Name:                playlist.Name,
 		Description:         playlist.Description,
 		CreatedBy:           playlist.CreatedBy.String(),
-		CreatorUsername:     username,
+		CreatorUsername:     creator.Username,
+		CreatorDisplayName:  displayNameOrUsername(creator.DisplayName, creator.Username),
 		VideoCount:          0,
 		IsPublic:            playlist.IsPublic,
 		CreatedAt:           playlist.CreatedAt,
 		UpdatedAt:           playlist.UpdatedAt,
 		FirstVideoThumbnail: nil,
+		PlaylistType:        playlist.PlaylistType,
 	})
 }
 
+// CreateSmart handles POST /api/playlists/smart (admin only)
+// Creates a playlist whose contents are resolved from a category rule at read time
+func (h *PlaylistsHandler) CreateSmart(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get current user
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+
+	// Parse request
+	var req SmartPlaylistCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate name
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		response.BadRequest(w, "Name is required")
+		return
+	}
+	if len(name) > 200 {
+		response.BadRequest(w, "Name must be 200 characters or less")
+		return
+	}
+
+	// Validate description
+	var description *string
+	if req.Description != nil {
+		desc := strings.TrimSpace(*req.Description)
+		if len(desc) > 1000 {
+			response.BadRequest(w, "Description must be 1000 characters or less")
+			return
+		}
+		if desc != "" {
+			description = &desc
+		}
+	}
+
+	// Validate rule
+	if req.CategoryID == "" {
+		response.BadRequest(w, "Category ID is required")
+		return
+	}
+	categoryID, err := uuid.Parse(req.CategoryID)
+	if err != nil {
+		response.BadRequest(w, "Invalid category ID format")
+		return
+	}
+
+	sort := "newest"
+	if req.Sort != "" {
+		switch req.Sort {
+		case "newest", "oldest", "most_viewed":
+			sort = req.Sort
+		default:
+			response.BadRequest(w, "Invalid sort. Must be 'newest', 'oldest', or 'most_viewed'")
+			return
+		}
+	}
+
+	limit := int32(defaultSmartPlaylistLimit)
+	if req.Limit != nil {
+		if *req.Limit < 1 || *req.Limit > maxSmartPlaylistLimit {
+			response.BadRequest(w, fmt.Sprintf("Limit must be between 1 and %d", maxSmartPlaylistLimit))
+			return
+		}
+		limit = *req.Limit
+	}
+
+	// Check category exists
+	category, err := h.db.Queries.GetCategoryByID(ctx, categoryID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.NotFound(w, "Category not found")
+			return
+		}
+		log.Printf("Error checking category: %v", err)
+		response.InternalServerError(w, "Failed to create playlist")
+		return
+	}
+
+	// Default to public
+	isPublic := true
+	if req.IsPublic != nil {
+		isPublic = *req.IsPublic
+	}
+
+	// Generate short ID
+	shortID, err := h.generateUniquePlaylistShortID(ctx)
+	if err != nil {
+		log.Printf("Error generating playlist short ID: %v", err)
+		response.InternalServerError(w, "Failed to create playlist")
+		return
+	}
+
+	// Create playlist
+	playlist, err := h.db.Queries.CreateSmartPlaylist(ctx, sqlc.CreateSmartPlaylistParams{
+		ShortID:         shortID,
+		Name:            name,
+		Description:     description,
+		CreatedBy:       userID,
+		IsPublic:        isPublic,
+		SmartCategoryID: pgtype.UUID{Bytes: category.ID, Valid: true},
+		SmartSort:       &sort,
+		SmartLimit:      &limit,
+	})
+	if err != nil {
+		log.Printf("Error creating smart playlist: %v", err)
+		response.InternalServerError(w, "Failed to create playlist")
+		return
+	}
+
+	log.Printf("Created smart playlist %s (category %s) by user %s", playlist.ID, category.Slug, userID)
+
+	response.Created(w, h.buildPlaylistResponse(ctx, playlist))
+}
+
 // GetByShortID handles GET /api/playlists/{short_id}
 func (h *PlaylistsHandler) GetByShortID(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -387,7 +908,7 @@ func (h *PlaylistsHandler) GetByShortID(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Verify user is authenticated
-	_, ok := middleware.GetUserID(ctx)
+	userID, ok := middleware.GetUserID(ctx)
 	if !ok {
 		response.Unauthorized(w, "Not authenticated")
 		return
@@ -405,13 +926,22 @@ func (h *PlaylistsHandler) GetByShortID(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Get playlist videos
-	videos, err := h.db.Queries.GetPlaylistVideos(ctx, playlist.ID)
+	// Private playlists are only visible to the owner and admins
+	isAdmin := middleware.IsAdmin(ctx)
+	if !playlist.IsPublic && playlist.CreatedBy != userID && !isAdmin {
+		response.NotFound(w, "Playlist not found")
+		return
+	}
+
+	// Get playlist videos, hiding those the viewer can't access
+	rule := buildSmartPlaylistRule(playlist.PlaylistType, playlist.SmartCategoryID, playlist.SmartSort, playlist.SmartLimit)
+	videos, err := h.loadPlaylistVideos(ctx, playlist.ID, rule)
 	if err != nil {
 		log.Printf("Error getting playlist videos: %v", err)
 		response.InternalServerError(w, "Failed to get playlist videos")
 		return
 	}
+	videos, hiddenCount := filterPlaylistVideos(videos, userID, isAdmin)
 
 	// Build video responses
 	videoResponses := make([]PlaylistVideoResponse, len(videos))
@@ -419,26 +949,127 @@ func (h *PlaylistsHandler) GetByShortID(w http.ResponseWriter, r *http.Request)
 		videoResponses[i] = buildPlaylistVideoResponse(v)
 	}
 
-	// Get first video thumbnail (position-based)
-	var firstThumbnail *string
-	if len(videos) > 0 {
-		firstThumbnail = videos[0].VideoThumbnail
+	// Get first video thumbnail (position-based)
+	var firstThumbnail *string
+	if len(videos) > 0 {
+		firstThumbnail = videos[0].VideoThumbnail
+	}
+
+	// Get follow state
+	followerCount, err := h.db.Queries.CountPlaylistFollowers(ctx, playlist.ID)
+	if err != nil {
+		log.Printf("Error counting playlist followers: %v", err)
+		response.InternalServerError(w, "Failed to get playlist")
+		return
+	}
+	followedByMe, err := h.db.Queries.IsFollowingPlaylist(ctx, sqlc.IsFollowingPlaylistParams{
+		PlaylistID: playlist.ID,
+		UserID:     userID,
+	})
+	if err != nil {
+		log.Printf("Error checking playlist follow: %v", err)
+		response.InternalServerError(w, "Failed to get playlist")
+		return
+	}
+
+	response.OK(w, PlaylistWithVideosResponse{
+		ID:                  playlist.ID.String(),
+		ShortID:             playlist.ShortID,
+		Name:                playlist.Name,
+		Description:         playlist.Description,
+		CreatedBy:           playlist.CreatedBy.String(),
+		CreatorUsername:     playlist.CreatorUsername,
+		CreatorDisplayName:  playlist.CreatorDisplayName,
+		VideoCount:          int64(len(videos)),
+		IsPublic:            playlist.IsPublic,
+		CreatedAt:           playlist.CreatedAt,
+		UpdatedAt:           playlist.UpdatedAt,
+		FirstVideoThumbnail: firstThumbnail,
+		PlaylistType:        playlist.PlaylistType,
+		SmartRule:           rule,
+		FollowerCount:       followerCount,
+		FollowedByMe:        followedByMe,
+		HiddenCount:         hiddenCount,
+		Videos:              videoResponses,
+	})
+}
+
+// Navigate handles GET /api/playlists/{short_id}/navigate?video_id=
+// Returns the previous and next accessible entries around a video (UUID or short ID)
+func (h *PlaylistsHandler) Navigate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	shortID := r.PathValue("short_id")
+	if shortID == "" {
+		response.BadRequest(w, "Playlist ID is required")
+		return
+	}
+
+	videoParam := r.URL.Query().Get("video_id")
+	if videoParam == "" {
+		response.BadRequest(w, "video_id is required")
+		return
+	}
+
+	// Verify user is authenticated
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+
+	// Get playlist
+	playlist, err := h.db.Queries.GetPlaylistByShortID(ctx, shortID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.NotFound(w, "Playlist not found")
+			return
+		}
+		log.Printf("Error getting playlist: %v", err)
+		response.InternalServerError(w, "Failed to get playlist")
+		return
+	}
+
+	// Private playlists are only visible to the owner and admins
+	isAdmin := middleware.IsAdmin(ctx)
+	if !playlist.IsPublic && playlist.CreatedBy != userID && !isAdmin {
+		response.NotFound(w, "Playlist not found")
+		return
+	}
+
+	// Get playlist videos, skipping those the viewer can't access
+	rule := buildSmartPlaylistRule(playlist.PlaylistType, playlist.SmartCategoryID, playlist.SmartSort, playlist.SmartLimit)
+	videos, err := h.loadPlaylistVideos(ctx, playlist.ID, rule)
+	if err != nil {
+		log.Printf("Error getting playlist videos: %v", err)
+		response.InternalServerError(w, "Failed to get playlist videos")
+		return
+	}
+	videos, _ = filterPlaylistVideos(videos, userID, isAdmin)
+
+	// Locate the current video by UUID or short ID
+	videoID, parseErr := uuid.Parse(videoParam)
+	current := -1
+	for i, v := range videos {
+		if (parseErr == nil && v.VideoID == videoID) || v.VideoShortID == videoParam {
+			current = i
+			break
+		}
+	}
+	if current == -1 {
+		response.NotFound(w, "Video not found in playlist")
+		return
+	}
+
+	var result PlaylistNavigationResponse
+	if current > 0 {
+		result.Previous = buildPlaylistNavigationEntry(videos[current-1])
+	}
+	if current < len(videos)-1 {
+		result.Next = buildPlaylistNavigationEntry(videos[current+1])
 	}
 
-	response.OK(w, PlaylistWithVideosResponse{
-		ID:                  playlist.ID.String(),
-		ShortID:             playlist.ShortID,
-		Name:                playlist.Name,
-		Description:         playlist.Description,
-		CreatedBy:           playlist.CreatedBy.String(),
-		CreatorUsername:     playlist.CreatorUsername,
-		VideoCount:          int64(len(videos)),
-		IsPublic:            playlist.IsPublic,
-		CreatedAt:           playlist.CreatedAt,
-		UpdatedAt:           playlist.UpdatedAt,
-		FirstVideoThumbnail: firstThumbnail,
-		Videos:              videoResponses,
-	})
+	response.OK(w, result)
 }
 
 // Update handles PATCH /api/playlists/{short_id}
@@ -510,12 +1141,18 @@ func (h *PlaylistsHandler) Update(w http.ResponseWriter, r *http.Request) {
 		description = playlist.Description
 	}
 
+	// Keep existing visibility unless provided
+	isPublic := playlist.IsPublic
+	if req.IsPublic != nil {
+		isPublic = *req.IsPublic
+	}
+
 	// Update playlist
 	updatedPlaylist, err := h.db.Queries.UpdatePlaylist(ctx, sqlc.UpdatePlaylistParams{
 		ID:          playlist.ID,
 		Column2:     name, // Empty string keeps existing
 		Description: description,
-		IsPublic:    playlist.IsPublic, // Keep existing
+		IsPublic:    isPublic,
 	})
 	if err != nil {
 		log.Printf("Error updating playlist: %v", err)
@@ -523,30 +1160,71 @@ func (h *PlaylistsHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get video count and first thumbnail
-	videoCount, _ := h.db.Queries.CountPlaylistVideos(ctx, playlist.ID)
-	videos, _ := h.db.Queries.GetPlaylistVideos(ctx, playlist.ID)
-	var firstThumbnail *string
-	if len(videos) > 0 {
-		firstThumbnail = videos[0].VideoThumbnail
+	response.OK(w, h.buildPlaylistResponse(ctx, updatedPlaylist))
+}
+
+// SetVisibility handles PATCH /api/playlists/{short_id}/visibility
+func (h *PlaylistsHandler) SetVisibility(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	shortID := r.PathValue("short_id")
+	if shortID == "" {
+		response.BadRequest(w, "Playlist ID is required")
+		return
 	}
 
-	// Get creator username
-	creator, _ := h.db.Queries.GetUserByID(ctx, playlist.CreatedBy)
+	// Get current user
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
 
-	response.OK(w, PlaylistResponse{
-		ID:                  updatedPlaylist.ID.String(),
-		ShortID:             updatedPlaylist.ShortID,
-		Name:                updatedPlaylist.Name,
-		Description:         updatedPlaylist.Description,
-		CreatedBy:           updatedPlaylist.CreatedBy.String(),
-		CreatorUsername:     creator.Username,
-		VideoCount:          videoCount,
-		IsPublic:            updatedPlaylist.IsPublic,
-		CreatedAt:           updatedPlaylist.CreatedAt,
-		UpdatedAt:           updatedPlaylist.UpdatedAt,
-		FirstVideoThumbnail: firstThumbnail,
+	// Parse request
+	var req PlaylistVisibilityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+	if req.IsPublic == nil {
+		response.BadRequest(w, "is_public is required")
+		return
+	}
+
+	// Get playlist
+	playlist, err := h.db.Queries.GetPlaylistByShortID(ctx, shortID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.NotFound(w, "Playlist not found")
+			return
+		}
+		log.Printf("Error getting playlist: %v", err)
+		response.InternalServerError(w, "Failed to get playlist")
+		return
+	}
+
+	// Check ownership (only owner can update, not admin)
+	if !isPlaylistOwner(playlist, userID) {
+		response.Forbidden(w, "You don't have permission to update this playlist")
+		return
+	}
+
+	// Update visibility only
+	updatedPlaylist, err := h.db.Queries.UpdatePlaylist(ctx, sqlc.UpdatePlaylistParams{
+		ID:          playlist.ID,
+		Column2:     "", // Empty string keeps existing
+		Description: playlist.Description,
+		IsPublic:    *req.IsPublic,
 	})
+	if err != nil {
+		log.Printf("Error updating playlist visibility: %v", err)
+		response.InternalServerError(w, "Failed to update playlist")
+		return
+	}
+
+	log.Printf("Set playlist %s visibility to public=%v by user %s", playlist.ID, *req.IsPublic, userID)
+
+	response.OK(w, h.buildPlaylistResponse(ctx, updatedPlaylist))
 }
 
 // Delete handles DELETE /api/playlists/{short_id}
@@ -594,6 +1272,19 @@ func (h *PlaylistsHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("Deleted playlist %s by user %s", playlist.ID, userID)
 
+	if playlist.CreatedBy != userID {
+		recordAudit(ctx, h.db.Queries, auditEntry{
+			Action:     auditPlaylistDelete,
+			TargetType: auditTargetPlaylist,
+			TargetID:   playlist.ID,
+			Details: map[string]interface{}{
+				"short_id": playlist.ShortID,
+				"name":     playlist.Name,
+				"owner_id": playlist.CreatedBy.String(),
+			},
+		})
+	}
+
 	response.NoContent(w)
 }
 
@@ -632,6 +1323,12 @@ func (h *PlaylistsHandler) AddVideosBatch(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// Smart playlist contents come from their rule
+	if playlist.PlaylistType == domain.PlaylistTypeSmart {
+		response.Conflict(w, "Smart playlist contents can't be modified directly")
+		return
+	}
+
 	// Parse request
 	var req PlaylistVideoBatchAddRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -719,16 +1416,17 @@ func (h *PlaylistsHandler) AddVideosBatch(w http.ResponseWriter, r *http.Request
 			AddedAt:    pv.AddedAt,
 			AddedBy:    &addedByStr,
 			Video: PlaylistVideoDetailResponse{
-				ID:                videoWithUploader.ID.String(),
-				ShortID:           videoWithUploader.ShortID,
-				Title:             videoWithUploader.Title,
-				Description:       videoWithUploader.Description,
-				ThumbnailFilename: videoWithUploader.ThumbnailFilename,
-				DurationSeconds:   videoWithUploader.DurationSeconds,
-				ViewCount:         videoWithUploader.ViewCount,
-				ProcessingStatus:  string(videoWithUploader.ProcessingStatus),
-				CreatedAt:         videoWithUploader.CreatedAt,
-				UploaderUsername:  videoWithUploader.UploaderUsername,
+				ID:                  videoWithUploader.ID.String(),
+				ShortID:             videoWithUploader.ShortID,
+				Title:               videoWithUploader.Title,
+				Description:         videoWithUploader.Description,
+				ThumbnailFilename:   videoWithUploader.ThumbnailFilename,
+				DurationSeconds:     videoWithUploader.DurationSeconds,
+				ViewCount:           videoWithUploader.ViewCount,
+				ProcessingStatus:    string(videoWithUploader.ProcessingStatus),
+				CreatedAt:           videoWithUploader.CreatedAt,
+				UploaderUsername:    videoWithUploader.UploaderUsername,
+				UploaderDisplayName: videoWithUploader.UploaderDisplayName,
 			},
 		})
 
@@ -773,6 +1471,12 @@ func (h *PlaylistsHandler) AddVideo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Smart playlist contents come from their rule
+	if playlist.PlaylistType == domain.PlaylistTypeSmart {
+		response.Conflict(w, "Smart playlist contents can't be modified directly")
+		return
+	}
+
 	// Parse request
 	var req PlaylistVideoAddRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -791,6 +1495,11 @@ func (h *PlaylistsHandler) AddVideo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Position != nil && *req.Position < 0 {
+		response.BadRequest(w, "Position must be >= 0")
+		return
+	}
+
 	// Check video exists
 	video, err := h.db.Queries.GetVideoByID(ctx, videoID)
 	if err != nil {
@@ -819,27 +1528,32 @@ func (h *PlaylistsHandler) AddVideo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Determine position
-	var position int32
-	if req.Position != nil {
-		position = *req.Position
-	} else {
-		// Append to end
-		maxPos, err := h.db.Queries.GetMaxPlaylistPosition(ctx, playlist.ID)
+	// Add video at the requested position (or append), shifting later entries up
+	var pv sqlc.PlaylistVideo
+	err = h.db.InTx(ctx, func(q *sqlc.Queries) error {
+		maxPos, err := q.GetMaxPlaylistPosition(ctx, playlist.ID)
 		if err != nil {
-			log.Printf("Error getting max position: %v", err)
-			response.InternalServerError(w, "Failed to add video")
-			return
+			return fmt.Errorf("failed to get max position: %w", err)
 		}
-		position = maxPos + 1
-	}
 
-	// Add video
-	pv, err := h.db.Queries.AddVideoToPlaylist(ctx, sqlc.AddVideoToPlaylistParams{
-		PlaylistID: playlist.ID,
-		VideoID:    videoID,
-		Position:   position,
-		AddedBy:    pgtype.UUID{Bytes: userID, Valid: true},
+		position := maxPos + 1
+		if req.Position != nil && *req.Position <= maxPos {
+			position = *req.Position
+			if err := q.IncrementPlaylistPositions(ctx, sqlc.IncrementPlaylistPositionsParams{
+				PlaylistID: playlist.ID,
+				Position:   position,
+			}); err != nil {
+				return fmt.Errorf("failed to shift positions: %w", err)
+			}
+		}
+
+		pv, err = q.AddVideoToPlaylist(ctx, sqlc.AddVideoToPlaylistParams{
+			PlaylistID: playlist.ID,
+			VideoID:    videoID,
+			Position:   position,
+			AddedBy:    pgtype.UUID{Bytes: userID, Valid: true},
+		})
+		return err
 	})
 	if err != nil {
 		log.Printf("Error adding video to playlist: %v", err)
@@ -864,16 +1578,17 @@ func (h *PlaylistsHandler) AddVideo(w http.ResponseWriter, r *http.Request) {
 		AddedAt:    pv.AddedAt,
 		AddedBy:    &addedByStr,
 		Video: PlaylistVideoDetailResponse{
-			ID:                videoWithUploader.ID.String(),
-			ShortID:           videoWithUploader.ShortID,
-			Title:             videoWithUploader.Title,
-			Description:       videoWithUploader.Description,
-			ThumbnailFilename: videoWithUploader.ThumbnailFilename,
-			DurationSeconds:   videoWithUploader.DurationSeconds,
-			ViewCount:         videoWithUploader.ViewCount,
-			ProcessingStatus:  string(videoWithUploader.ProcessingStatus),
-			CreatedAt:         videoWithUploader.CreatedAt,
-			UploaderUsername:  videoWithUploader.UploaderUsername,
+			ID:                  videoWithUploader.ID.String(),
+			ShortID:             videoWithUploader.ShortID,
+			Title:               videoWithUploader.Title,
+			Description:         videoWithUploader.Description,
+			ThumbnailFilename:   videoWithUploader.ThumbnailFilename,
+			DurationSeconds:     videoWithUploader.DurationSeconds,
+			ViewCount:           videoWithUploader.ViewCount,
+			ProcessingStatus:    string(videoWithUploader.ProcessingStatus),
+			CreatedAt:           videoWithUploader.CreatedAt,
+			UploaderUsername:    videoWithUploader.UploaderUsername,
+			UploaderDisplayName: videoWithUploader.UploaderDisplayName,
 		},
 	})
 }
@@ -925,6 +1640,12 @@ func (h *PlaylistsHandler) RemoveVideo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Smart playlist contents come from their rule
+	if playlist.PlaylistType == domain.PlaylistTypeSmart {
+		response.Conflict(w, "Smart playlist contents can't be modified directly")
+		return
+	}
+
 	// Get the video entry to find its position
 	entry, err := h.db.Queries.GetPlaylistVideoEntry(ctx, sqlc.GetPlaylistVideoEntryParams{
 		PlaylistID: playlist.ID,
@@ -963,6 +1684,12 @@ func (h *PlaylistsHandler) RemoveVideo(w http.ResponseWriter, r *http.Request) {
 }
 
 // Reorder handles PATCH /api/playlists/{short_id}/reorder
+//
+// The request must list every video in the playlist that the owner can see, each
+// exactly once, with positions forming a permutation of 0..n-1. Requests that leave
+// videos out are rejected with the missing IDs instead of being partially applied.
+// Entries hidden from the owner (e.g. others' unfinished videos) keep their relative
+// order after the visible ones.
 func (h *PlaylistsHandler) Reorder(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -997,6 +1724,12 @@ func (h *PlaylistsHandler) Reorder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Smart playlist contents come from their rule
+	if playlist.PlaylistType == domain.PlaylistTypeSmart {
+		response.Conflict(w, "Smart playlist contents can't be modified directly")
+		return
+	}
+
 	// Parse request
 	var req PlaylistReorderRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -1009,72 +1742,220 @@ func (h *PlaylistsHandler) Reorder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate: check for missing fields and negative positions
-	seenPositions := make(map[int32]bool)
-	for i, vp := range req.VideoPositions {
-		if vp.VideoID == "" {
-			response.BadRequest(w, fmt.Sprintf("video_positions[%d]: video_id is required", i))
+	// Get current playlist entries; the request covers the entries visible to the owner
+	entries, err := h.db.Queries.GetPlaylistVideos(ctx, playlist.ID)
+	if err != nil {
+		log.Printf("Error getting playlist videos: %v", err)
+		response.InternalServerError(w, "Failed to reorder playlist")
+		return
+	}
+	visible, _ := filterPlaylistVideos(entries, userID, middleware.IsAdmin(ctx))
+
+	// Validate the whole request before touching the database
+	videoIDs, positions, reorderErr := planPlaylistReorder(entries, visible, req.VideoPositions)
+	if reorderErr != nil {
+		if len(reorderErr.missing) > 0 {
+			response.ErrorWithDetails(w, reorderErr.status, reorderErr.message,
+				map[string]interface{}{"missing_video_ids": reorderErr.missing})
+			return
+		}
+		response.Error(w, reorderErr.status, reorderErr.message)
+		return
+	}
+
+	// Apply all positions in a single statement
+	err = h.db.InTx(ctx, func(q *sqlc.Queries) error {
+		return q.ReorderPlaylistVideos(ctx, sqlc.ReorderPlaylistVideosParams{
+			VideoIds:   videoIDs,
+			Positions:  positions,
+			PlaylistID: playlist.ID,
+		})
+	})
+	if err != nil {
+		log.Printf("Error reordering playlist videos: %v", err)
+		response.InternalServerError(w, "Failed to reorder playlist")
+		return
+	}
+
+	response.OK(w, map[string]string{"message": "Playlist reordered successfully"})
+}
+
+// GetUserPlaylists handles GET /api/playlists/videos/{video_id}/playlists
+// Returns the current user's playlists (for "Add to Playlist" dialog)
+// with contains_video set for each playlist when a video ID is given
+func (h *PlaylistsHandler) GetUserPlaylists(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get current user
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+
+	// The frontend uses video_id to show checkboxes for which playlists contain the video;
+	// the GET /api/playlists/ alias has no video_id
+	var videoID uuid.UUID
+	videoIDStr := r.PathValue("video_id")
+	if videoIDStr != "" {
+		var err error
+		videoID, err = uuid.Parse(videoIDStr)
+		if err != nil {
+			response.BadRequest(w, "Invalid video ID format")
 			return
 		}
-		if vp.Position < 0 {
-			response.BadRequest(w, fmt.Sprintf("video_positions[%d]: position must be >= 0", i))
+
+		video, err := h.db.Queries.GetVideoByID(ctx, videoID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				response.NotFound(w, "Video not found")
+				return
+			}
+			log.Printf("Error getting video: %v", err)
+			response.InternalServerError(w, "Failed to get playlists")
 			return
 		}
-		// Check for duplicate positions
-		if seenPositions[vp.Position] {
-			response.BadRequest(w, fmt.Sprintf("Duplicate position %d in reorder request", vp.Position))
+		if !hasVideoAccess(video, userID, middleware.IsAdmin(ctx)) {
+			response.NotFound(w, "Video not found")
 			return
 		}
-		seenPositions[vp.Position] = true
 	}
 
-	// Validate all videos exist in playlist and update positions
-	for _, vp := range req.VideoPositions {
-		videoID, err := uuid.Parse(vp.VideoID)
-		if err != nil {
-			response.BadRequest(w, fmt.Sprintf("Invalid video ID format: %s", vp.VideoID))
+	opts, err := parsePlaylistListOptions(r, "recently_updated")
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	// Get user's playlists
+	playlists, err := h.db.Queries.ListUserPlaylistsWithThumbnail(ctx, sqlc.ListUserPlaylistsWithThumbnailParams{
+		VideoID:    videoID,
+		CreatedBy:  userID,
+		IsAdmin:    middleware.IsAdmin(ctx),
+		Sort:       opts.sort,
+		PageLimit:  int32(opts.limit),
+		PageOffset: int32(opts.skip),
+	})
+	if err != nil {
+		log.Printf("Error getting user playlists: %v", err)
+		response.InternalServerError(w, "Failed to get playlists")
+		return
+	}
+
+	total, err := h.db.Queries.CountUserPlaylists(ctx, userID)
+	if err != nil {
+		log.Printf("Error counting user playlists: %v", err)
+		response.InternalServerError(w, "Failed to get playlists")
+		return
+	}
+
+	// Build response
+	result := make([]PlaylistResponse, len(playlists))
+	for i, p := range playlists {
+		result[i] = buildPlaylistResponseFromUserRow(p)
+		h.resolveSmartPlaylistSummary(ctx, &result[i], p.ID)
+		if videoIDStr != "" {
+			containsVideo := p.ContainsVideo
+			result[i].ContainsVideo = &containsVideo
+		}
+	}
+
+	response.OK(w, PlaylistListResponse{
+		Playlists: result,
+		Total:     total,
+	})
+}
+
+// Follow handles POST /api/playlists/{short_id}/follow
+func (h *PlaylistsHandler) Follow(w http.ResponseWriter, r *http.Request) {
+	h.setFollow(w, r, true)
+}
+
+// Unfollow handles DELETE /api/playlists/{short_id}/follow
+func (h *PlaylistsHandler) Unfollow(w http.ResponseWriter, r *http.Request) {
+	h.setFollow(w, r, false)
+}
+
+// setFollow follows or unfollows a playlist for the current user and returns the new follow state
+func (h *PlaylistsHandler) setFollow(w http.ResponseWriter, r *http.Request, follow bool) {
+	ctx := r.Context()
+
+	shortID := r.PathValue("short_id")
+	if shortID == "" {
+		response.BadRequest(w, "Playlist ID is required")
+		return
+	}
+
+	// Get current user
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+
+	// Get playlist
+	playlist, err := h.db.Queries.GetPlaylistByShortID(ctx, shortID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.NotFound(w, "Playlist not found")
 			return
 		}
+		log.Printf("Error getting playlist: %v", err)
+		response.InternalServerError(w, "Failed to get playlist")
+		return
+	}
 
-		// Check video is in playlist
-		exists, err := h.db.Queries.VideoInPlaylist(ctx, sqlc.VideoInPlaylistParams{
-			PlaylistID: playlist.ID,
-			VideoID:    videoID,
-		})
-		if err != nil {
-			log.Printf("Error checking video in playlist: %v", err)
-			response.InternalServerError(w, "Failed to reorder playlist")
+	params := sqlc.FollowPlaylistParams{
+		PlaylistID: playlist.ID,
+		UserID:     userID,
+	}
+
+	if follow {
+		// Private playlists are only visible to the owner and admins
+		if !playlist.IsPublic && !isPlaylistOwnerOrAdmin(playlist, userID, middleware.IsAdmin(ctx)) {
+			response.NotFound(w, "Playlist not found")
 			return
 		}
-		if !exists {
-			response.NotFound(w, fmt.Sprintf("Video %s not found in playlist", vp.VideoID))
+		if !playlist.IsPublic {
+			response.BadRequest(w, "Only public playlists can be followed")
+			return
+		}
+		if isPlaylistOwner(playlist, userID) {
+			response.BadRequest(w, "You can't follow your own playlist")
 			return
 		}
 
-		// Update position
-		if err := h.db.Queries.UpdatePlaylistVideoPosition(ctx, sqlc.UpdatePlaylistVideoPositionParams{
-			PlaylistID: playlist.ID,
-			VideoID:    videoID,
-			Position:   vp.Position,
-		}); err != nil {
-			log.Printf("Error updating video position: %v", err)
-			response.InternalServerError(w, "Failed to reorder playlist")
+		if err := h.db.Queries.FollowPlaylist(ctx, params); err != nil {
+			log.Printf("Error following playlist: %v", err)
+			response.InternalServerError(w, "Failed to follow playlist")
+			return
+		}
+	} else {
+		if err := h.db.Queries.UnfollowPlaylist(ctx, sqlc.UnfollowPlaylistParams(params)); err != nil {
+			log.Printf("Error unfollowing playlist: %v", err)
+			response.InternalServerError(w, "Failed to unfollow playlist")
 			return
 		}
 	}
 
-	response.OK(w, map[string]string{"message": "Playlist reordered successfully"})
+	followerCount, err := h.db.Queries.CountPlaylistFollowers(ctx, playlist.ID)
+	if err != nil {
+		log.Printf("Error counting playlist followers: %v", err)
+		response.InternalServerError(w, "Failed to get follower count")
+		return
+	}
+
+	response.OK(w, PlaylistFollowResponse{
+		FollowedByMe:  follow,
+		FollowerCount: followerCount,
+	})
 }
 
-// GetUserPlaylists handles GET /api/playlists/videos/{video_id}/playlists
-// Returns the current user's playlists (for "Add to Playlist" dialog)
-func (h *PlaylistsHandler) GetUserPlaylists(w http.ResponseWriter, r *http.Request) {
+// ListFollowed handles GET /api/users/me/followed-playlists
+// Supports sort, skip and limit; playlists are ordered by most recently followed by default
+func (h *PlaylistsHandler) ListFollowed(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	// The video_id is in the URL but we return the current user's playlists
-	// The frontend uses this to show checkboxes for which playlists contain the video
-	_ = r.PathValue("video_id") // Acknowledge but don't use
-
 	// Get current user
 	userID, ok := middleware.GetUserID(ctx)
 	if !ok {
@@ -1082,10 +1963,28 @@ func (h *PlaylistsHandler) GetUserPlaylists(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Get user's playlists
-	playlists, err := h.db.Queries.ListUserPlaylistsWithThumbnail(ctx, userID)
+	opts, err := parsePlaylistListOptions(r, "recently_followed")
 	if err != nil {
-		log.Printf("Error getting user playlists: %v", err)
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	playlists, err := h.db.Queries.ListFollowedPlaylists(ctx, sqlc.ListFollowedPlaylistsParams{
+		UserID:     userID,
+		IsAdmin:    middleware.IsAdmin(ctx),
+		Sort:       opts.sort,
+		PageLimit:  int32(opts.limit),
+		PageOffset: int32(opts.skip),
+	})
+	if err != nil {
+		log.Printf("Error getting followed playlists: %v", err)
+		response.InternalServerError(w, "Failed to get playlists")
+		return
+	}
+
+	total, err := h.db.Queries.CountFollowedPlaylists(ctx, userID)
+	if err != nil {
+		log.Printf("Error counting followed playlists: %v", err)
 		response.InternalServerError(w, "Failed to get playlists")
 		return
 	}
@@ -1093,11 +1992,12 @@ func (h *PlaylistsHandler) GetUserPlaylists(w http.ResponseWriter, r *http.Reque
 	// Build response
 	result := make([]PlaylistResponse, len(playlists))
 	for i, p := range playlists {
-		result[i] = buildPlaylistResponseFromUserRow(p)
+		result[i] = buildPlaylistResponseFromFollowedRow(p)
+		h.resolveSmartPlaylistSummary(ctx, &result[i], p.ID)
 	}
 
 	response.OK(w, PlaylistListResponse{
 		Playlists: result,
-		Total:     int64(len(result)),
+		Total:     total,
 	})
 }
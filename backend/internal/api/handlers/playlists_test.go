@@ -0,0 +1,505 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/clipset/clipset-go/internal/config"
+	"github.com/clipset/clipset-go/internal/db/dbtest"
+	"github.com/clipset/clipset-go/internal/db/sqlc"
+	"github.com/clipset/clipset-go/internal/domain"
+)
+
+// playlistEntries returns entries for the videos at positions 0..n-1
+func playlistEntries(videoIDs ...uuid.UUID) []sqlc.GetPlaylistVideosRow {
+	entries := make([]sqlc.GetPlaylistVideosRow, len(videoIDs))
+	for i, id := range videoIDs {
+		entries[i] = sqlc.GetPlaylistVideosRow{
+			ID:          uuid.New(),
+			VideoID:     id,
+			Position:    int32(i),
+			VideoStatus: domain.ProcessingStatusCompleted,
+		}
+	}
+	return entries
+}
+
+func TestPlanPlaylistReorder(t *testing.T) {
+	a, b, c, hidden := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	entries := playlistEntries(a, hidden, b, c)
+	// Another user's video that is still processing is hidden from the owner
+	entries[1].VideoStatus = domain.ProcessingStatusProcessing
+	entries[1].VideoUploadedBy = uuid.New()
+	visible, _ := filterPlaylistVideos(entries, uuid.New(), false)
+
+	tests := []struct {
+		name          string
+		items         []VideoPositionItem
+		wantIDs       []uuid.UUID
+		wantPositions []int32
+		wantStatus    int
+		wantMissing   []string
+	}{
+		{
+			name:          "every visible video, hidden ones after",
+			items:         []VideoPositionItem{{c.String(), 0}, {a.String(), 1}, {b.String(), 2}},
+			wantIDs:       []uuid.UUID{c, a, b, hidden},
+			wantPositions: []int32{0, 1, 2, 3},
+		},
+		{
+			name:        "missing videos are listed",
+			items:       []VideoPositionItem{{b.String(), 0}},
+			wantStatus:  http.StatusBadRequest,
+			wantMissing: []string{a.String(), c.String()},
+		},
+		{
+			name:       "hidden video",
+			items:      []VideoPositionItem{{a.String(), 0}, {b.String(), 1}, {c.String(), 2}, {hidden.String(), 3}},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "video not in playlist",
+			items:      []VideoPositionItem{{uuid.NewString(), 0}},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "duplicate video",
+			items:      []VideoPositionItem{{a.String(), 0}, {a.String(), 1}, {b.String(), 2}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "duplicate position",
+			items:      []VideoPositionItem{{a.String(), 0}, {b.String(), 0}, {c.String(), 2}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "position past the visible videos",
+			items:      []VideoPositionItem{{a.String(), 0}, {b.String(), 1}, {c.String(), 3}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid video ID",
+			items:      []VideoPositionItem{{"not-a-uuid", 0}},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids, positions, err := planPlaylistReorder(entries, visible, tt.items)
+			if tt.wantStatus != 0 {
+				if err == nil {
+					t.Fatalf("planPlaylistReorder() = %v %v, want a %d", ids, positions, tt.wantStatus)
+				}
+				if err.status != tt.wantStatus {
+					t.Errorf("status = %d (%s), want %d", err.status, err.message, tt.wantStatus)
+				}
+				if !slices.Equal(err.missing, tt.wantMissing) {
+					t.Errorf("missing = %v, want %v", err.missing, tt.wantMissing)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("planPlaylistReorder() rejected the request: %d %s", err.status, err.message)
+			}
+			if !slices.Equal(ids, tt.wantIDs) || !slices.Equal(positions, tt.wantPositions) {
+				t.Errorf("planPlaylistReorder() = %v %v, want %v %v", ids, positions, tt.wantIDs, tt.wantPositions)
+			}
+		})
+	}
+}
+
+func TestReorderReportsMissingVideos(t *testing.T) {
+	owner := uuid.New()
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	playlist := sqlc.Playlist{ID: uuid.New(), ShortID: "pl1", CreatedBy: owner, PlaylistType: domain.PlaylistTypeManual}
+	fake := newFakeDB().
+		returns("GetPlaylistByShortID", playlist).
+		returns("GetPlaylistVideos", rowsOf(playlistEntries(a, b, c))...)
+	h := &PlaylistsHandler{db: fake.db(), config: &config.Config{}}
+
+	body := `{"video_positions": [{"video_id": "` + b.String() + `", "position": 0}]}`
+	req := httptest.NewRequest(http.MethodPut, "/api/playlists/pl1/reorder", strings.NewReader(body))
+	req.SetPathValue("short_id", "pl1")
+	rec := httptest.NewRecorder()
+	h.Reorder(rec, withUser(req, owner, domain.UserRoleUser))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Reorder returned %d: %s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Detail          string   `json:"detail"`
+		MissingVideoIDs []string `json:"missing_video_ids"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if want := []string{a.String(), c.String()}; !slices.Equal(resp.MissingVideoIDs, want) {
+		t.Errorf("missing_video_ids = %v, want %v", resp.MissingVideoIDs, want)
+	}
+	if n := fake.count("ReorderPlaylistVideos"); n != 0 {
+		t.Errorf("ReorderPlaylistVideos ran %d times for a rejected request", n)
+	}
+}
+
+func TestGetByShortIDPrivacy(t *testing.T) {
+	owner := uuid.New()
+	tests := []struct {
+		name     string
+		isPublic bool
+		viewer   uuid.UUID
+		role     domain.UserRole
+		want     int
+	}{
+		{"owner sees private playlist", false, owner, domain.UserRoleUser, http.StatusOK},
+		{"admin sees private playlist", false, uuid.New(), domain.UserRoleAdmin, http.StatusOK},
+		{"stranger can't see private playlist", false, uuid.New(), domain.UserRoleUser, http.StatusNotFound},
+		{"moderator can't see private playlist", false, uuid.New(), domain.UserRoleModerator, http.StatusNotFound},
+		{"stranger sees public playlist", true, uuid.New(), domain.UserRoleUser, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeDB().
+				returns("GetPlaylistWithVideos", sqlc.GetPlaylistWithVideosRow{
+					ID:           uuid.New(),
+					ShortID:      "pl1",
+					CreatedBy:    owner,
+					IsPublic:     tt.isPublic,
+					PlaylistType: domain.PlaylistTypeManual,
+				}).
+				returns("GetPlaylistVideos", rowsOf(playlistEntries(uuid.New()))...).
+				returns("CountPlaylistFollowers", int64(0)).
+				returns("IsFollowingPlaylist", false)
+			h := &PlaylistsHandler{db: fake.db(), config: &config.Config{}}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/playlists/pl1", nil)
+			req.SetPathValue("short_id", "pl1")
+			rec := httptest.NewRecorder()
+			h.GetByShortID(rec, withUser(req, tt.viewer, tt.role))
+
+			if rec.Code != tt.want {
+				t.Fatalf("GetByShortID returned %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+			if tt.want == http.StatusNotFound && fake.count("GetPlaylistVideos") != 0 {
+				t.Error("loaded the videos of a playlist the viewer can't see")
+			}
+		})
+	}
+}
+
+func TestListByUsernamePrivacy(t *testing.T) {
+	database := dbtest.Connect(t)
+	ctx := context.Background()
+
+	createUser := func(username string, role domain.UserRole) sqlc.User {
+		t.Helper()
+		user, err := database.Queries.CreateUser(ctx, sqlc.CreateUserParams{
+			Email:        username + "@example.com",
+			Username:     username,
+			PasswordHash: "unused",
+			Role:         role,
+		})
+		if err != nil {
+			t.Fatalf("failed to create user %s: %v", username, err)
+		}
+		return user
+	}
+	owner := createUser("owner", domain.UserRoleUser)
+	admin := createUser("admin", domain.UserRoleAdmin)
+	stranger := createUser("stranger", domain.UserRoleUser)
+
+	for _, p := range []sqlc.CreatePlaylistParams{
+		{ShortID: "public1", Name: "Public", CreatedBy: owner.ID, IsPublic: true},
+		{ShortID: "private1", Name: "Private", CreatedBy: owner.ID, IsPublic: false},
+	} {
+		if _, err := database.Queries.CreatePlaylist(ctx, p); err != nil {
+			t.Fatalf("failed to create playlist %s: %v", p.ShortID, err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		viewer sqlc.User
+		want   []string
+	}{
+		{"owner", owner, []string{"private1", "public1"}},
+		{"admin", admin, []string{"private1", "public1"}},
+		{"stranger", stranger, []string{"public1"}},
+	}
+
+	h := &PlaylistsHandler{db: database, config: &config.Config{}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/playlists/by-user/Owner", nil)
+			req.SetPathValue("username", "Owner")
+			rec := httptest.NewRecorder()
+			h.ListByUsername(rec, withUser(req, tt.viewer.ID, tt.viewer.Role))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("ListByUsername returned %d: %s", rec.Code, rec.Body)
+			}
+			var resp PlaylistListResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid response: %v", err)
+			}
+			var got []string
+			for _, p := range resp.Playlists {
+				got = append(got, p.ShortID)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) || resp.Total != int64(len(tt.want)) {
+				t.Errorf("playlists = %v (total %d), want %v", got, resp.Total, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlaylistListingsCountOnlyAccessibleVideos(t *testing.T) {
+	database := dbtest.Connect(t)
+	ctx := context.Background()
+
+	createUser := func(username string, role domain.UserRole) sqlc.User {
+		t.Helper()
+		user, err := database.Queries.CreateUser(ctx, sqlc.CreateUserParams{
+			Email:        username + "@example.com",
+			Username:     username,
+			PasswordHash: "unused",
+			Role:         role,
+		})
+		if err != nil {
+			t.Fatalf("failed to create user %s: %v", username, err)
+		}
+		return user
+	}
+	owner := createUser("owner", domain.UserRoleUser)
+	uploader := createUser("uploader", domain.UserRoleUser)
+	admin := createUser("admin", domain.UserRoleAdmin)
+	follower := createUser("follower", domain.UserRoleUser)
+
+	playlist, err := database.Queries.CreatePlaylist(ctx, sqlc.CreatePlaylistParams{
+		ShortID: "pl1", Name: "Mixed", CreatedBy: owner.ID, IsPublic: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create playlist: %v", err)
+	}
+	// The first video is another user's, still processing
+	for i, status := range []domain.ProcessingStatus{domain.ProcessingStatusProcessing, domain.ProcessingStatusCompleted} {
+		video, err := database.Queries.CreateVideo(ctx, sqlc.CreateVideoParams{
+			ShortID:          fmt.Sprintf("video%d", i),
+			Title:            string(status),
+			Filename:         uuid.NewString() + ".mp4",
+			OriginalFilename: "upload.mp4",
+			FileSizeBytes:    1024,
+			UploadedBy:       uploader.ID,
+		})
+		if err != nil {
+			t.Fatalf("failed to create video: %v", err)
+		}
+		if _, err := database.Pool.Exec(ctx, `UPDATE videos SET processing_status = $2, thumbnail_filename = $3 WHERE id = $1`,
+			video.ID, status, string(status)+".jpg"); err != nil {
+			t.Fatalf("failed to update video: %v", err)
+		}
+		if _, err := database.Queries.AddVideoToPlaylist(ctx, sqlc.AddVideoToPlaylistParams{
+			PlaylistID: playlist.ID, VideoID: video.ID, Position: int32(i),
+		}); err != nil {
+			t.Fatalf("failed to add video: %v", err)
+		}
+	}
+	if err := database.Queries.FollowPlaylist(ctx, sqlc.FollowPlaylistParams{PlaylistID: playlist.ID, UserID: follower.ID}); err != nil {
+		t.Fatalf("failed to follow playlist: %v", err)
+	}
+
+	h := &PlaylistsHandler{db: database, config: &config.Config{}}
+	listings := map[string]func(viewer sqlc.User) *httptest.ResponseRecorder{
+		"by username": func(viewer sqlc.User) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/api/playlists/by-user/owner", nil)
+			req.SetPathValue("username", "owner")
+			rec := httptest.NewRecorder()
+			h.ListByUsername(rec, withUser(req, viewer.ID, viewer.Role))
+			return rec
+		},
+		"own": func(viewer sqlc.User) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/api/playlists/", nil)
+			rec := httptest.NewRecorder()
+			h.GetUserPlaylists(rec, withUser(req, viewer.ID, viewer.Role))
+			return rec
+		},
+		"followed": func(viewer sqlc.User) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/api/users/me/followed-playlists", nil)
+			rec := httptest.NewRecorder()
+			h.ListFollowed(rec, withUser(req, viewer.ID, viewer.Role))
+			return rec
+		},
+	}
+
+	tests := []struct {
+		listing   string
+		viewer    sqlc.User
+		wantCount int64
+		wantThumb string
+	}{
+		{"own", owner, 1, "completed.jpg"},
+		{"by username", owner, 1, "completed.jpg"},
+		{"by username", uploader, 2, "processing.jpg"},
+		{"by username", admin, 2, "processing.jpg"},
+		{"followed", follower, 1, "completed.jpg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.listing+" as "+tt.viewer.Username, func(t *testing.T) {
+			rec := listings[tt.listing](tt.viewer)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("listing returned %d: %s", rec.Code, rec.Body)
+			}
+			var resp PlaylistListResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid response: %v", err)
+			}
+			if len(resp.Playlists) != 1 {
+				t.Fatalf("got %d playlists, want 1", len(resp.Playlists))
+			}
+			got := resp.Playlists[0]
+			if got.VideoCount != tt.wantCount || got.FirstVideoThumbnail == nil || *got.FirstVideoThumbnail != tt.wantThumb {
+				t.Errorf("video_count = %d, first_video_thumbnail = %v, want %d, %s",
+					got.VideoCount, got.FirstVideoThumbnail, tt.wantCount, tt.wantThumb)
+			}
+		})
+	}
+}
+
+func TestPlaylistListingsResolveSmartPlaylists(t *testing.T) {
+	viewer := uuid.New()
+	smartID, manualID := uuid.New(), uuid.New()
+	category := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	limit := int32(2)
+	manualThumb, newestThumb, olderThumb := "manual.jpg", "newest.jpg", "older.jpg"
+	smartVideos := []sqlc.ListSmartPlaylistVideosRow{
+		{VideoID: uuid.New(), VideoThumbnail: &newestThumb, VideoStatus: domain.ProcessingStatusCompleted},
+		{VideoID: uuid.New(), VideoThumbnail: &olderThumb, VideoStatus: domain.ProcessingStatusCompleted},
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		rows  []any
+		list  func(h *PlaylistsHandler, w http.ResponseWriter, r *http.Request)
+	}{
+		{
+			name:  "by username",
+			query: "GetPlaylistsByUsername",
+			rows: []any{
+				sqlc.GetPlaylistsByUsernameRow{ID: smartID, PlaylistType: domain.PlaylistTypeSmart, SmartCategoryID: category, SmartLimit: &limit},
+				sqlc.GetPlaylistsByUsernameRow{ID: manualID, PlaylistType: domain.PlaylistTypeManual, VideoCount: 3, FirstVideoThumbnail: &manualThumb},
+			},
+			list: func(h *PlaylistsHandler, w http.ResponseWriter, r *http.Request) {
+				r.SetPathValue("username", "owner")
+				h.ListByUsername(w, r)
+			},
+		},
+		{
+			name:  "own",
+			query: "ListUserPlaylistsWithThumbnail",
+			rows: []any{
+				sqlc.ListUserPlaylistsWithThumbnailRow{ID: smartID, PlaylistType: domain.PlaylistTypeSmart, SmartCategoryID: category, SmartLimit: &limit},
+				sqlc.ListUserPlaylistsWithThumbnailRow{ID: manualID, PlaylistType: domain.PlaylistTypeManual, VideoCount: 3, FirstVideoThumbnail: &manualThumb},
+			},
+			list: (*PlaylistsHandler).GetUserPlaylists,
+		},
+		{
+			name:  "followed",
+			query: "ListFollowedPlaylists",
+			rows: []any{
+				sqlc.ListFollowedPlaylistsRow{ID: smartID, PlaylistType: domain.PlaylistTypeSmart, SmartCategoryID: category, SmartLimit: &limit},
+				sqlc.ListFollowedPlaylistsRow{ID: manualID, PlaylistType: domain.PlaylistTypeManual, VideoCount: 3, FirstVideoThumbnail: &manualThumb},
+			},
+			list: (*PlaylistsHandler).ListFollowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeDB().
+				returns(tt.query, tt.rows...).
+				returns("ListSmartPlaylistVideos", rowsOf(smartVideos)...).
+				returns("CountPlaylistsByUsername", int64(2)).
+				returns("CountUserPlaylists", int64(2)).
+				returns("CountFollowedPlaylists", int64(2))
+			h := &PlaylistsHandler{db: fake.db(), config: &config.Config{}}
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			tt.list(h, rec, withUser(req, viewer, domain.UserRoleUser))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("listing returned %d: %s", rec.Code, rec.Body)
+			}
+			var resp PlaylistListResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid response: %v", err)
+			}
+			if len(resp.Playlists) != 2 {
+				t.Fatalf("got %d playlists, want 2", len(resp.Playlists))
+			}
+			smart, manual := resp.Playlists[0], resp.Playlists[1]
+			if smart.VideoCount != 2 || smart.FirstVideoThumbnail == nil || *smart.FirstVideoThumbnail != "newest.jpg" {
+				t.Errorf("smart playlist video_count = %d, first_video_thumbnail = %v, want 2, newest.jpg",
+					smart.VideoCount, smart.FirstVideoThumbnail)
+			}
+			if manual.VideoCount != 3 || manual.FirstVideoThumbnail == nil || *manual.FirstVideoThumbnail != manualThumb {
+				t.Errorf("manual playlist changed to video_count = %d, first_video_thumbnail = %v",
+					manual.VideoCount, manual.FirstVideoThumbnail)
+			}
+			if n := fake.count("ListSmartPlaylistVideos"); n != 1 {
+				t.Errorf("ListSmartPlaylistVideos ran %d times, want once for the smart playlist", n)
+			}
+			if args := fake.lastArgs("ListSmartPlaylistVideos"); len(args) == 0 || args[len(args)-1] != limit {
+				t.Errorf("smart rule resolved with %v, want its limit %d", args, limit)
+			}
+		})
+	}
+}
+
+func TestListFollowedSort(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantStatus int
+		wantSort   string
+	}{
+		{"", http.StatusOK, "recently_followed"},
+		{"?sort=name", http.StatusOK, "name"},
+		{"?sort=most_videos", http.StatusOK, "most_videos"},
+		{"?sort=followers", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			fake := newFakeDB().returns("CountFollowedPlaylists", int64(0))
+			h := &PlaylistsHandler{db: fake.db(), config: &config.Config{}}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/users/me/followed-playlists"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			h.ListFollowed(rec, withUser(req, uuid.New(), domain.UserRoleUser))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("ListFollowed returned %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			// Sort follows the user ID and admin flag
+			if args := fake.lastArgs("ListFollowedPlaylists"); len(args) < 3 || args[2] != tt.wantSort {
+				t.Errorf("ListFollowedPlaylists args = %v, want sort %q", args, tt.wantSort)
+			}
+		})
+	}
+}
@@ -0,0 +1,348 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/clipset/clipset-go/internal/api/response"
+	"github.com/clipset/clipset-go/internal/config"
+	"github.com/clipset/clipset-go/internal/db"
+	"github.com/clipset/clipset-go/internal/services/storage"
+)
+
+// ScanEnqueueFunc is a function type for enqueueing storage scan jobs
+type ScanEnqueueFunc func(ctx context.Context) error
+
+// TempCleanupFunc is a function type for removing stale temp files
+type TempCleanupFunc func(ctx context.Context, dryRun bool) (storage.CleanupResult, error)
+
+// StorageHandler serves admin storage endpoints
+type StorageHandler struct {
+	db          *db.DB
+	config      *config.Config
+	enqueueScan ScanEnqueueFunc // Optional function to enqueue storage scan jobs
+	cleanTemp   TempCleanupFunc // Optional function to remove stale temp files
+}
+
+// NewStorageHandler creates a new storage handler
+func NewStorageHandler(database *db.DB, cfg *config.Config) *StorageHandler {
+	return &StorageHandler{
+		db:          database,
+		config:      cfg,
+		enqueueScan: nil, // Set via SetScanEnqueueFunc after worker is initialized
+		cleanTemp:   nil, // Set via SetTempCleanupFunc after worker is initialized
+	}
+}
+
+// SetScanEnqueueFunc sets the function used to enqueue storage scan jobs
+// This should be called after the worker is initialized in main.go
+func (h *StorageHandler) SetScanEnqueueFunc(fn ScanEnqueueFunc) {
+	h.enqueueScan = fn
+}
+
+// SetTempCleanupFunc sets the function used to remove stale temp files
+// This should be called after the worker is initialized in main.go
+func (h *StorageHandler) SetTempCleanupFunc(fn TempCleanupFunc) {
+	h.cleanTemp = fn
+}
+
+// StorageDirectoryStats represents the usage of one storage directory
+type StorageDirectoryStats struct {
+	Name          string  `json:"name"`
+	Path          string  `json:"path"`
+	UsedBytes     int64   `json:"used_bytes"`
+	FileCount     int64   `json:"file_count"`
+	FreeBytes     uint64  `json:"free_bytes"`
+	CapacityBytes uint64  `json:"capacity_bytes"`
+	Error         *string `json:"error"`
+}
+
+// StorageVolumeStats represents the free space uploads and transcodes write to,
+// read when requested
+type StorageVolumeStats struct {
+	Name          string  `json:"name"`
+	Path          string  `json:"path"`
+	FreeBytes     uint64  `json:"free_bytes"`
+	CapacityBytes uint64  `json:"capacity_bytes"`
+	Low           bool    `json:"low"` // Below min_free_bytes, so uploads or transcodes are refused
+	Error         *string `json:"error"`
+}
+
+// StorageStatsResponse represents storage usage across all directories
+type StorageStatsResponse struct {
+	Volumes            []StorageVolumeStats    `json:"volumes"`
+	MinFreeBytes       int64                   `json:"min_free_bytes"` // Free space uploads and transcodes must leave
+	Directories        []StorageDirectoryStats `json:"directories"`
+	TotalUsedBytes     int64                   `json:"total_used_bytes"`
+	TotalFileCount     int64                   `json:"total_file_count"`
+	DatabaseVideoBytes int64                   `json:"database_video_bytes"` // Sum of videos.file_size_bytes
+	RefreshedAt        *time.Time              `json:"refreshed_at"`         // Null until the worker first measures
+}
+
+// OrphanedFileResponse represents a file or directory no video references
+type OrphanedFileResponse struct {
+	Path  string `json:"path"`
+	Kind  string `json:"kind"`
+	Bytes int64  `json:"bytes"`
+	IsDir bool   `json:"is_dir"`
+}
+
+// MissingFileResponse represents a file a video references that doesn't exist
+type MissingFileResponse struct {
+	VideoID string `json:"video_id"`
+	Path    string `json:"path"`
+	Kind    string `json:"kind"`
+}
+
+// StorageScanReportResponse represents the latest orphaned file scan
+type StorageScanReportResponse struct {
+	Running       bool                   `json:"running"` // A newer scan is in progress
+	Orphans       []OrphanedFileResponse `json:"orphans"`
+	Missing       []MissingFileResponse  `json:"missing"`
+	OrphanedBytes int64                  `json:"orphaned_bytes"`
+	Errors        []string               `json:"errors"`
+	StartedAt     time.Time              `json:"started_at"`
+	FinishedAt    time.Time              `json:"finished_at"`
+}
+
+// StorageCleanupResponse represents the result of deleting orphaned files
+type StorageCleanupResponse struct {
+	DryRun       bool                   `json:"dry_run"`
+	Removed      []OrphanedFileResponse `json:"removed"`
+	RemovedBytes int64                  `json:"removed_bytes"`
+	Errors       []string               `json:"errors"`
+}
+
+// StorageTempCleanupResponse represents the result of removing stale temp files
+type StorageTempCleanupResponse struct {
+	DryRun       bool                   `json:"dry_run"`
+	MaxAge       string                 `json:"max_age"` // Temp files older than this are removed
+	Removed      []OrphanedFileResponse `json:"removed"`
+	RemovedBytes int64                  `json:"removed_bytes"`
+	Errors       []string               `json:"errors"`
+}
+
+// orphanResponses converts orphaned files to their API representation
+func orphanResponses(orphans []storage.OrphanedFile) []OrphanedFileResponse {
+	result := make([]OrphanedFileResponse, len(orphans))
+	for i, o := range orphans {
+		result[i] = OrphanedFileResponse{
+			Path:  o.Path,
+			Kind:  o.Kind,
+			Bytes: o.Bytes,
+			IsDir: o.IsDir,
+		}
+	}
+	return result
+}
+
+// volumeStats reads the free space of the directories uploads and transcodes
+// write to
+func (h *StorageHandler) volumeStats() []StorageVolumeStats {
+	dirs := []struct{ name, path string }{
+		{"videos", h.config.VideoStoragePath},
+		{"temp", h.config.TempStoragePath},
+		{"chunks", h.config.ChunksStoragePath},
+	}
+
+	volumes := make([]StorageVolumeStats, len(dirs))
+	for i, dir := range dirs {
+		volumes[i] = StorageVolumeStats{Name: dir.name, Path: dir.path}
+		free, capacity, err := storage.FreeSpace(dir.path)
+		if err != nil {
+			errMsg := err.Error()
+			volumes[i].Error = &errMsg
+			continue
+		}
+		volumes[i].FreeBytes = free
+		volumes[i].CapacityBytes = capacity
+		volumes[i].Low = int64(free) < h.config.MinFreeDiskBytes
+	}
+	return volumes
+}
+
+// Stats handles GET /api/admin/storage-stats (admin only)
+// Directory usage comes from the worker's last measurement, not a fresh walk;
+// volume free space is read on each request.
+func (h *StorageHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	databaseBytes, err := h.db.Queries.GetTotalStorageBytes(ctx)
+	if err != nil {
+		log.Printf("Error getting total storage bytes: %v", err)
+		response.InternalServerError(w, "Failed to get storage stats")
+		return
+	}
+
+	result := StorageStatsResponse{
+		Volumes:            h.volumeStats(),
+		MinFreeBytes:       h.config.MinFreeDiskBytes,
+		Directories:        []StorageDirectoryStats{},
+		DatabaseVideoBytes: databaseBytes,
+	}
+
+	if report, ok := storage.LatestUsage(); ok {
+		for _, dir := range report.Directories {
+			stats := StorageDirectoryStats{
+				Name:          dir.Name,
+				Path:          dir.Path,
+				UsedBytes:     dir.Bytes,
+				FileCount:     dir.Files,
+				FreeBytes:     dir.FreeBytes,
+				CapacityBytes: dir.CapacityBytes,
+			}
+			if dir.Error != "" {
+				errMsg := dir.Error
+				stats.Error = &errMsg
+			}
+			result.Directories = append(result.Directories, stats)
+		}
+		result.TotalUsedBytes = report.TotalBytes
+		result.TotalFileCount = report.TotalFiles
+		result.RefreshedAt = &report.RefreshedAt
+	}
+
+	response.OK(w, result)
+}
+
+// Scan handles POST /api/admin/storage/scan (admin only)
+// Starts an orphaned file scan in the worker; fetch the result with ScanReport.
+func (h *StorageHandler) Scan(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if h.enqueueScan == nil {
+		response.Error(w, http.StatusServiceUnavailable, "Background worker is not available")
+		return
+	}
+
+	if err := storage.BeginScan(); err != nil {
+		response.Conflict(w, "A storage scan is already running")
+		return
+	}
+
+	if err := h.enqueueScan(ctx); err != nil {
+		storage.FinishScan(nil)
+		log.Printf("Error enqueueing storage scan: %v", err)
+		response.InternalServerError(w, "Failed to start storage scan")
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, map[string]string{"message": "Storage scan started"})
+}
+
+// ScanReport handles GET /api/admin/storage/scan-report (admin only)
+func (h *StorageHandler) ScanReport(w http.ResponseWriter, r *http.Request) {
+	report, ok, running := storage.LatestScan()
+	if !ok {
+		if running {
+			response.NotFound(w, "The first storage scan is still running")
+			return
+		}
+		response.NotFound(w, "No storage scan has been run")
+		return
+	}
+
+	missing := make([]MissingFileResponse, len(report.Missing))
+	for i, m := range report.Missing {
+		missing[i] = MissingFileResponse{
+			VideoID: m.VideoID,
+			Path:    m.Path,
+			Kind:    m.Kind,
+		}
+	}
+
+	response.OK(w, StorageScanReportResponse{
+		Running:       running,
+		Orphans:       orphanResponses(report.Orphans),
+		Missing:       missing,
+		OrphanedBytes: report.OrphanedBytes,
+		Errors:        report.Errors,
+		StartedAt:     report.StartedAt,
+		FinishedAt:    report.FinishedAt,
+	})
+}
+
+// Cleanup handles POST /api/admin/storage/cleanup (admin only)
+// Deletes the orphans listed in the latest scan report. With ?dry_run=true
+// nothing is deleted and the response lists what would be.
+func (h *StorageHandler) Cleanup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
+	result, err := storage.CleanupOrphans(dryRun)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrScanRunning):
+			response.Conflict(w, "Wait for the running storage scan to finish")
+		case errors.Is(err, storage.ErrNoScanReport):
+			response.NotFound(w, "No storage scan has been run")
+		default:
+			log.Printf("Error cleaning up orphaned files: %v", err)
+			response.InternalServerError(w, "Failed to clean up orphaned files")
+		}
+		return
+	}
+
+	if !dryRun {
+		recordAudit(ctx, h.db.Queries, auditEntry{
+			Action:     auditStorageCleanup,
+			TargetType: auditTargetStorage,
+			Details: map[string]interface{}{
+				"removed":       len(result.Removed),
+				"removed_bytes": result.RemovedBytes,
+				"errors":        len(result.Errors),
+			},
+		})
+	}
+
+	response.OK(w, StorageCleanupResponse{
+		DryRun:       dryRun,
+		Removed:      orphanResponses(result.Removed),
+		RemovedBytes: result.RemovedBytes,
+		Errors:       result.Errors,
+	})
+}
+
+// TempCleanup handles POST /api/admin/storage/temp-cleanup (admin only)
+// Removes temp files older than TEMP_FILE_MAX_AGE that no video or upload
+// session is using, as the periodic cleanup does. With ?dry_run=true nothing
+// is deleted and the response lists what would be.
+func (h *StorageHandler) TempCleanup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
+	if h.cleanTemp == nil {
+		response.Error(w, http.StatusServiceUnavailable, "Background worker is not available")
+		return
+	}
+
+	result, err := h.cleanTemp(ctx, dryRun)
+	if err != nil {
+		log.Printf("Error cleaning up temp files: %v", err)
+		response.InternalServerError(w, "Failed to clean up temp files")
+		return
+	}
+
+	if !dryRun {
+		recordAudit(ctx, h.db.Queries, auditEntry{
+			Action:     auditStorageTempCleanup,
+			TargetType: auditTargetStorage,
+			Details: map[string]interface{}{
+				"removed":       len(result.Removed),
+				"removed_bytes": result.RemovedBytes,
+				"errors":        len(result.Errors),
+			},
+		})
+	}
+
+	response.OK(w, StorageTempCleanupResponse{
+		DryRun:       dryRun,
+		MaxAge:       h.config.TempFileMaxAge.String(),
+		Removed:      orphanResponses(result.Removed),
+		RemovedBytes: result.RemovedBytes,
+		Errors:       result.Errors,
+	})
+}
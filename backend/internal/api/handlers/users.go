@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"encoding/json"
 	"errors"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -16,8 +19,12 @@ import (
 	"github.com/clipset/clipset-go/internal/config"
 	"github.com/clipset/clipset-go/internal/db"
 	"github.com/clipset/clipset-go/internal/db/sqlc"
+	"github.com/clipset/clipset-go/internal/domain"
+	"github.com/clipset/clipset-go/internal/services/apitoken"
 	"github.com/clipset/clipset-go/internal/services/auth"
 	"github.com/clipset/clipset-go/internal/services/image"
+	"github.com/clipset/clipset-go/internal/services/ratelimit"
+	"github.com/clipset/clipset-go/internal/services/session"
 )
 
 // UsersHandler handles user management endpoints
@@ -25,14 +32,18 @@ type UsersHandler struct {
 	db             *db.DB
 	config         *config.Config
 	imageProcessor *image.Processor
+	exportLimiter  ratelimit.Limiter
+	sessions       *session.Store
 }
 
 // NewUsersHandler creates a new users handler
-func NewUsersHandler(database *db.DB, cfg *config.Config, imgProcessor *image.Processor) *UsersHandler {
+func NewUsersHandler(database *db.DB, cfg *config.Config, imgProcessor *image.Processor, sessions *session.Store) *UsersHandler {
 	return &UsersHandler{
 		db:             database,
 		config:         cfg,
 		imageProcessor: imgProcessor,
+		exportLimiter:  ratelimit.NewMemoryLimiter(),
+		sessions:       sessions,
 	}
 }
 
@@ -43,12 +54,25 @@ type UserListResponse struct {
 	ID            string    `json:"id"`
 	Email         string    `json:"email"`
 	Username      string    `json:"username"`
+	DisplayName   string    `json:"display_name"`
 	Role          string    `json:"role"`
 	CreatedAt     time.Time `json:"created_at"`
 	IsActive      bool      `json:"is_active"`
 	AvatarURL     *string   `json:"avatar_url"`
 	VideoCount    int64     `json:"video_count"`
 	PlaylistCount int64     `json:"playlist_count"`
+	// Total size of uploaded videos (admin listing)
+	TotalStorageBytes int64 `json:"total_storage_bytes"`
+	// Unconfirmed email change outstanding (admin listing)
+	PendingEmailChange bool `json:"pending_email_change"`
+	// Per-user weekly upload limit, nil when the global limit applies
+	WeeklyUploadLimitOverrideBytes *int64 `json:"weekly_upload_limit_override_bytes"`
+}
+
+// UserListPageResponse - paginated admin user list
+type UserListPageResponse struct {
+	Users []UserListResponse `json:"users"`
+	Total int64              `json:"total"`
 }
 
 // UserWithQuotaResponse - includes quota info (own profile only)
@@ -56,6 +80,7 @@ type UserWithQuotaResponse struct {
 	ID                string    `json:"id"`
 	Email             string    `json:"email"`
 	Username          string    `json:"username"`
+	DisplayName       string    `json:"display_name"`
 	Role              string    `json:"role"`
 	CreatedAt         time.Time `json:"created_at"`
 	IsActive          bool      `json:"is_active"`
@@ -70,6 +95,7 @@ type UserWithQuotaResponse struct {
 type UserProfileResponse struct {
 	ID            string    `json:"id"`
 	Username      string    `json:"username"`
+	DisplayName   string    `json:"display_name"`
 	CreatedAt     time.Time `json:"created_at"`
 	AvatarURL     *string   `json:"avatar_url"`
 	VideoCount    int64     `json:"video_count"`
@@ -80,28 +106,122 @@ type UserProfileResponse struct {
 type UserDirectoryResponse struct {
 	ID            string  `json:"id"`
 	Username      string  `json:"username"`
+	DisplayName   string  `json:"display_name"`
 	AvatarURL     *string `json:"avatar_url"`
 	VideoCount    int64   `json:"video_count"`
 	PlaylistCount int64   `json:"playlist_count"`
 }
 
+// UserProfileUpdateRequest represents the own-profile update request
+type UserProfileUpdateRequest struct {
+	DisplayName *string `json:"display_name"` // Empty string clears it
+}
+
 // PasswordResetLinkResponse - admin-generated password reset link
 type PasswordResetLinkResponse struct {
 	ResetLink string `json:"reset_link"`
 	ExpiresAt string `json:"expires_at"`
 }
 
+// UserEmailChangeRequest represents the request to change the caller's email
+type UserEmailChangeRequest struct {
+	NewEmail        string `json:"new_email"`
+	CurrentPassword string `json:"current_password"`
+}
+
+// UserQuotaOverrideRequest sets or, when null, clears a user's weekly upload limit
+type UserQuotaOverrideRequest struct {
+	WeeklyUploadLimitOverrideBytes *int64 `json:"weekly_upload_limit_override_bytes"`
+}
+
+// UserQuotaOverrideResponse - a user's weekly upload limit override
+type UserQuotaOverrideResponse struct {
+	UserID                         string `json:"user_id"`
+	WeeklyUploadLimitOverrideBytes *int64 `json:"weekly_upload_limit_override_bytes"`
+}
+
+// UserStorageStatusResponse - storage used by a user's videos in one processing status
+type UserStorageStatusResponse struct {
+	Status     string `json:"status"`
+	VideoCount int64  `json:"video_count"`
+	TotalBytes int64  `json:"total_bytes"`
+}
+
+// UserStorageResponse - total storage used by a user's videos
+type UserStorageResponse struct {
+	UserID            string                      `json:"user_id"`
+	TotalStorageBytes int64                       `json:"total_storage_bytes"`
+	VideoCount        int64                       `json:"video_count"`
+	ShareOfTotal      float64                     `json:"share_of_total"` // Fraction of all video storage, 0-1
+	ByStatus          []UserStorageStatusResponse `json:"by_status"`
+}
+
+// UserBulkStatusRequest activates or deactivates several users at once
+type UserBulkStatusRequest struct {
+	UserIDs  []string `json:"user_ids"`
+	IsActive *bool    `json:"is_active"`
+}
+
+// UserBulkStatusResult - outcome for one user of a bulk status change
+type UserBulkStatusResult struct {
+	UserID string `json:"user_id"`
+	Status string `json:"status"` // updated, not_found or skipped_self
+}
+
+// UserBulkStatusResponse - per-user outcomes of a bulk status change
+type UserBulkStatusResponse struct {
+	Results []UserBulkStatusResult `json:"results"`
+}
+
+// AccessTokenCreateRequest represents a request for a new personal access token
+type AccessTokenCreateRequest struct {
+	Name  string `json:"name"`
+	Scope string `json:"scope"` // "full" (default) or "upload"
+}
+
+// AccessTokenResponse - a personal access token, without its secret
+type AccessTokenResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Scope      string     `json:"scope"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+// AccessTokenCreatedResponse - includes the token itself, which is only shown once
+type AccessTokenCreatedResponse struct {
+	AccessTokenResponse
+	Token string `json:"token"`
+}
+
+// Per-user overrides may exceed the global maximum, but not by an unreasonable amount
+const maxUploadLimitOverrideBytes = 1099511627776 // 1TB
+
+// Upper bound on user IDs in a single bulk status change
+const maxBulkStatusUsers = 500
+
 // Helper to build avatar URL
-func buildAvatarURL(filename *string) *string {
+// The filename is included as a version so a new upload busts cached copies
+func buildAvatarURL(userID uuid.UUID, filename *string) *string {
 	if filename == nil || *filename == "" {
 		return nil
 	}
-	url := "/media/avatars/" + *filename
-	return &url
+	avatarURL := "/api/users/" + userID.String() + "/avatar?v=" + url.QueryEscape(*filename)
+	return &avatarURL
+}
+
+// displayNameOrUsername returns the display name, falling back to the username when unset
+func displayNameOrUsername(displayName *string, username string) string {
+	if displayName == nil || *displayName == "" {
+		return username
+	}
+	return *displayName
 }
 
 // List handles GET /api/users/ (admin only, paginated)
 func (h *UsersHandler) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
 	// Parse query parameters
 	skip := 0
 	limit := 10
@@ -118,10 +238,50 @@ func (h *UsersHandler) List(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	search := strings.TrimSpace(r.URL.Query().Get("search"))
+
+	role := r.URL.Query().Get("role")
+	if role != "" && !domain.UserRole(role).IsValid() {
+		response.BadRequest(w, "Invalid role")
+		return
+	}
+
+	// Normalized to "true"/"false" to match is_active::text in the query
+	isActive := r.URL.Query().Get("is_active")
+	if isActive != "" {
+		val, err := strconv.ParseBool(isActive)
+		if err != nil {
+			response.BadRequest(w, "Invalid is_active value")
+			return
+		}
+		isActive = strconv.FormatBool(val)
+	}
+
+	sortBy := r.URL.Query().Get("sort")
+	if sortBy == "" {
+		sortBy = "created_at"
+	}
+
+	// Validate sort option
+	validSorts := map[string]bool{
+		"created_at":  true,
+		"username":    true,
+		"video_count": true,
+		"storage":     true,
+	}
+	if !validSorts[sortBy] {
+		response.BadRequest(w, "Invalid sort. Must be one of: created_at, username, video_count, storage")
+		return
+	}
+
 	// Get users with counts
-	users, err := h.db.Queries.ListUsersWithCounts(r.Context(), sqlc.ListUsersWithCountsParams{
-		Limit:  int32(limit),
-		Offset: int32(skip),
+	users, err := h.db.Queries.ListUsersWithCounts(ctx, sqlc.ListUsersWithCountsParams{
+		Column1: search,
+		Column2: role,
+		Column3: isActive,
+		Column4: sortBy,
+		Limit:   int32(limit),
+		Offset:  int32(skip),
 	})
 	if err != nil {
 		log.Printf("Error listing users: %v", err)
@@ -129,23 +289,41 @@ func (h *UsersHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	total, err := h.db.Queries.CountUsersWithFilters(ctx, sqlc.CountUsersWithFiltersParams{
+		Column1: search,
+		Column2: role,
+		Column3: isActive,
+	})
+	if err != nil {
+		log.Printf("Error counting users: %v", err)
+		response.InternalServerError(w, "Failed to count users")
+		return
+	}
+
 	// Build response
 	result := make([]UserListResponse, len(users))
 	for i, u := range users {
 		result[i] = UserListResponse{
-			ID:            u.ID.String(),
-			Email:         u.Email,
-			Username:      u.Username,
-			Role:          string(u.Role),
-			CreatedAt:     u.CreatedAt,
-			IsActive:      u.IsActive,
-			AvatarURL:     buildAvatarURL(u.AvatarFilename),
-			VideoCount:    u.VideoCount,
-			PlaylistCount: u.PlaylistCount,
+			ID:                             u.ID.String(),
+			Email:                          u.Email,
+			Username:                       u.Username,
+			DisplayName:                    displayNameOrUsername(u.DisplayName, u.Username),
+			Role:                           string(u.Role),
+			CreatedAt:                      u.CreatedAt,
+			IsActive:                       u.IsActive,
+			AvatarURL:                      buildAvatarURL(u.ID, u.AvatarFilename),
+			VideoCount:                     u.VideoCount,
+			PlaylistCount:                  u.PlaylistCount,
+			TotalStorageBytes:              u.StorageBytes,
+			PendingEmailChange:             u.PendingEmailChange,
+			WeeklyUploadLimitOverrideBytes: u.WeeklyUploadLimitOverrideBytes,
 		}
 	}
 
-	response.OK(w, result)
+	response.OK(w, UserListPageResponse{
+		Users: result,
+		Total: total,
+	})
 }
 
 // Directory handles GET /api/users/directory (public user directory)
@@ -185,7 +363,8 @@ func (h *UsersHandler) Directory(w http.ResponseWriter, r *http.Request) {
 		result[i] = UserDirectoryResponse{
 			ID:            u.ID.String(),
 			Username:      u.Username,
-			AvatarURL:     buildAvatarURL(u.AvatarFilename),
+			DisplayName:   displayNameOrUsername(u.DisplayName, u.Username),
+			AvatarURL:     buildAvatarURL(u.ID, u.AvatarFilename),
 			VideoCount:    u.VideoCount,
 			PlaylistCount: u.PlaylistCount,
 		}
@@ -223,10 +402,11 @@ func (h *UsersHandler) GetByUsername(w http.ResponseWriter, r *http.Request) {
 			ID:                user.ID.String(),
 			Email:             user.Email,
 			Username:          user.Username,
+			DisplayName:       displayNameOrUsername(user.DisplayName, user.Username),
 			Role:              string(user.Role),
 			CreatedAt:         user.CreatedAt,
 			IsActive:          user.IsActive,
-			AvatarURL:         buildAvatarURL(user.AvatarFilename),
+			AvatarURL:         buildAvatarURL(user.ID, user.AvatarFilename),
 			VideoCount:        user.VideoCount,
 			PlaylistCount:     user.PlaylistCount,
 			WeeklyUploadBytes: user.WeeklyUploadBytes,
@@ -239,8 +419,9 @@ func (h *UsersHandler) GetByUsername(w http.ResponseWriter, r *http.Request) {
 	response.OK(w, UserProfileResponse{
 		ID:            user.ID.String(),
 		Username:      user.Username,
+		DisplayName:   displayNameOrUsername(user.DisplayName, user.Username),
 		CreatedAt:     user.CreatedAt,
-		AvatarURL:     buildAvatarURL(user.AvatarFilename),
+		AvatarURL:     buildAvatarURL(user.ID, user.AvatarFilename),
 		VideoCount:    user.VideoCount,
 		PlaylistCount: user.PlaylistCount,
 	})
@@ -281,10 +462,11 @@ func (h *UsersHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 			ID:                user.ID.String(),
 			Email:             user.Email,
 			Username:          user.Username,
+			DisplayName:       displayNameOrUsername(user.DisplayName, user.Username),
 			Role:              string(user.Role),
 			CreatedAt:         user.CreatedAt,
 			IsActive:          user.IsActive,
-			AvatarURL:         buildAvatarURL(user.AvatarFilename),
+			AvatarURL:         buildAvatarURL(user.ID, user.AvatarFilename),
 			VideoCount:        user.VideoCount,
 			PlaylistCount:     user.PlaylistCount,
 			WeeklyUploadBytes: user.WeeklyUploadBytes,
@@ -297,13 +479,150 @@ func (h *UsersHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	response.OK(w, UserProfileResponse{
 		ID:            user.ID.String(),
 		Username:      user.Username,
+		DisplayName:   displayNameOrUsername(user.DisplayName, user.Username),
 		CreatedAt:     user.CreatedAt,
-		AvatarURL:     buildAvatarURL(user.AvatarFilename),
+		AvatarURL:     buildAvatarURL(user.ID, user.AvatarFilename),
 		VideoCount:    user.VideoCount,
 		PlaylistCount: user.PlaylistCount,
 	})
 }
 
+// GetStorage handles GET /api/users/{user_id}/storage (self or admin)
+func (h *UsersHandler) GetStorage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := uuid.Parse(r.PathValue("user_id"))
+	if err != nil {
+		response.BadRequest(w, "Invalid user ID format")
+		return
+	}
+
+	currentUserID, _ := middleware.GetUserID(ctx)
+	if userID != currentUserID && !middleware.IsAdmin(ctx) {
+		response.Forbidden(w, "You can only view your own storage usage")
+		return
+	}
+
+	if _, err := h.db.Queries.GetUserByID(ctx, userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.NotFound(w, "User not found")
+			return
+		}
+		log.Printf("Error getting user: %v", err)
+		response.InternalServerError(w, "Failed to get user")
+		return
+	}
+
+	rows, err := h.db.Queries.GetUserStorageByStatus(ctx, userID)
+	if err != nil {
+		log.Printf("Error getting storage for user %s: %v", userID, err)
+		response.InternalServerError(w, "Failed to get storage usage")
+		return
+	}
+
+	overallBytes, err := h.db.Queries.GetTotalStorageBytes(ctx)
+	if err != nil {
+		log.Printf("Error getting total storage: %v", err)
+		response.InternalServerError(w, "Failed to get storage usage")
+		return
+	}
+
+	// Report every status, including ones the user has no videos in
+	byStatus := map[domain.ProcessingStatus]sqlc.GetUserStorageByStatusRow{}
+	for _, row := range rows {
+		byStatus[row.ProcessingStatus] = row
+	}
+
+	resp := UserStorageResponse{
+		UserID:   userID.String(),
+		ByStatus: make([]UserStorageStatusResponse, 0, 4),
+	}
+	for _, status := range []domain.ProcessingStatus{
+		domain.ProcessingStatusPending,
+		domain.ProcessingStatusProcessing,
+		domain.ProcessingStatusCompleted,
+		domain.ProcessingStatusFailed,
+	} {
+		row := byStatus[status]
+		resp.TotalStorageBytes += row.TotalBytes
+		resp.VideoCount += row.VideoCount
+		resp.ByStatus = append(resp.ByStatus, UserStorageStatusResponse{
+			Status:     string(status),
+			VideoCount: row.VideoCount,
+			TotalBytes: row.TotalBytes,
+		})
+	}
+	if overallBytes > 0 {
+		resp.ShareOfTotal = float64(resp.TotalStorageBytes) / float64(overallBytes)
+	}
+
+	response.OK(w, resp)
+}
+
+// UpdateMe handles PATCH /api/users/me
+func (h *UsersHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+
+	var req UserProfileUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	if req.DisplayName == nil {
+		response.BadRequest(w, "No fields provided for update")
+		return
+	}
+
+	// Validate display name
+	var displayName *string
+	trimmed := strings.TrimSpace(*req.DisplayName)
+	if utf8.RuneCountInString(trimmed) > 50 {
+		response.BadRequest(w, "Display name must be 50 characters or less")
+		return
+	}
+	if trimmed != "" {
+		displayName = &trimmed
+	}
+
+	if err := h.db.Queries.UpdateUserDisplayName(ctx, sqlc.UpdateUserDisplayNameParams{
+		ID:          userID,
+		DisplayName: displayName,
+	}); err != nil {
+		log.Printf("Error updating display name: %v", err)
+		response.InternalServerError(w, "Failed to update profile")
+		return
+	}
+
+	user, err := h.db.Queries.GetUserWithCounts(ctx, userID)
+	if err != nil {
+		log.Printf("Error getting user: %v", err)
+		response.InternalServerError(w, "Failed to get user")
+		return
+	}
+
+	response.OK(w, UserWithQuotaResponse{
+		ID:                user.ID.String(),
+		Email:             user.Email,
+		Username:          user.Username,
+		DisplayName:       displayNameOrUsername(user.DisplayName, user.Username),
+		Role:              string(user.Role),
+		CreatedAt:         user.CreatedAt,
+		IsActive:          user.IsActive,
+		AvatarURL:         buildAvatarURL(user.ID, user.AvatarFilename),
+		VideoCount:        user.VideoCount,
+		PlaylistCount:     user.PlaylistCount,
+		WeeklyUploadBytes: user.WeeklyUploadBytes,
+		LastUploadReset:   user.LastUploadReset,
+	})
+}
+
 // UploadAvatar handles POST /api/users/me/avatar
 func (h *UsersHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserID(r.Context())
@@ -395,7 +714,7 @@ func (h *UsersHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
 		Role:              string(updatedUser.Role),
 		CreatedAt:         updatedUser.CreatedAt,
 		IsActive:          updatedUser.IsActive,
-		AvatarURL:         buildAvatarURL(updatedUser.AvatarFilename),
+		AvatarURL:         buildAvatarURL(updatedUser.ID, updatedUser.AvatarFilename),
 		VideoCount:        videoCount,
 		PlaylistCount:     playlistCount,
 		WeeklyUploadBytes: updatedUser.WeeklyUploadBytes,
@@ -457,6 +776,42 @@ func (h *UsersHandler) DeleteAvatar(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// ServeAvatar handles GET /api/users/{user_id}/avatar (public)
+func (h *UsersHandler) ServeAvatar(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := uuid.Parse(r.PathValue("user_id"))
+	if err != nil {
+		response.BadRequest(w, "Invalid user ID format")
+		return
+	}
+
+	user, err := h.db.Queries.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.NotFound(w, "User not found")
+			return
+		}
+		log.Printf("Error getting user: %v", err)
+		response.InternalServerError(w, "Failed to get user")
+		return
+	}
+
+	if user.AvatarFilename == nil || *user.AvatarFilename == "" {
+		response.NotFound(w, "User has no avatar")
+		return
+	}
+
+	// Versioned URLs point at a file that never changes; unversioned or stale
+	// ones must pick up a new avatar reasonably quickly
+	cacheControl := "public, max-age=300"
+	if r.URL.Query().Get("v") == *user.AvatarFilename {
+		cacheControl = "public, max-age=31536000, immutable"
+	}
+
+	serveImageFile(w, r, h.imageProcessor.GetAvatarPath(*user.AvatarFilename), "avatar", cacheControl)
+}
+
 // Deactivate handles DELETE /api/users/{user_id} (soft delete, admin only)
 func (h *UsersHandler) Deactivate(w http.ResponseWriter, r *http.Request) {
 	userIDStr := r.PathValue("user_id")
@@ -499,6 +854,19 @@ func (h *UsersHandler) Deactivate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Existing tokens would otherwise stay valid until they expire
+	if err := h.sessions.RevokeAll(r.Context(), userID); err != nil {
+		log.Printf("Error revoking sessions for deactivated user %s: %v", userID, err)
+		response.InternalServerError(w, "Failed to deactivate user")
+		return
+	}
+
+	recordAudit(r.Context(), h.db.Queries, auditEntry{
+		Action:     auditUserDeactivate,
+		TargetType: auditTargetUser,
+		TargetID:   userID,
+	})
+
 	response.OK(w, map[string]string{
 		"message": "User deactivated successfully",
 	})
@@ -537,11 +905,128 @@ func (h *UsersHandler) Activate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r.Context(), h.db.Queries, auditEntry{
+		Action:     auditUserActivate,
+		TargetType: auditTargetUser,
+		TargetID:   userID,
+	})
+
 	response.OK(w, map[string]string{
 		"message": "User activated successfully",
 	})
 }
 
+// BulkSetStatus handles POST /api/users/bulk-status (admin only)
+func (h *UsersHandler) BulkSetStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	currentUserID, _ := middleware.GetUserID(ctx)
+
+	var req UserBulkStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	if req.IsActive == nil {
+		response.BadRequest(w, "is_active is required")
+		return
+	}
+	if len(req.UserIDs) == 0 {
+		response.BadRequest(w, "user_ids is required")
+		return
+	}
+	if len(req.UserIDs) > maxBulkStatusUsers {
+		response.BadRequest(w, "Too many user IDs (max 500)")
+		return
+	}
+
+	// Parse everything up front so a typo doesn't leave a half-applied batch
+	userIDs := make([]uuid.UUID, 0, len(req.UserIDs))
+	seen := make(map[uuid.UUID]bool, len(req.UserIDs))
+	for _, idStr := range req.UserIDs {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			response.BadRequest(w, "Invalid user ID format: "+idStr)
+			return
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		userIDs = append(userIDs, id)
+	}
+
+	results := make([]UserBulkStatusResult, 0, len(userIDs))
+	var updated, deactivated []uuid.UUID
+	err := h.db.InTx(ctx, func(q *sqlc.Queries) error {
+		for _, id := range userIDs {
+			result := UserBulkStatusResult{UserID: id.String()}
+
+			if id == currentUserID {
+				result.Status = "skipped_self"
+				results = append(results, result)
+				continue
+			}
+
+			if _, err := q.GetUserByID(ctx, id); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					result.Status = "not_found"
+					results = append(results, result)
+					continue
+				}
+				return err
+			}
+
+			if *req.IsActive {
+				if err := q.ActivateUser(ctx, id); err != nil {
+					return err
+				}
+			} else {
+				if err := q.DeactivateUser(ctx, id); err != nil {
+					return err
+				}
+				if err := q.RevokeUserSessions(ctx, id); err != nil {
+					return err
+				}
+				deactivated = append(deactivated, id)
+			}
+
+			result.Status = "updated"
+			results = append(results, result)
+			updated = append(updated, id)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error updating user status in bulk: %v", err)
+		response.InternalServerError(w, "Failed to update users")
+		return
+	}
+
+	// Sessions were revoked in the transaction; drop any cached validations
+	for _, id := range deactivated {
+		h.sessions.Forget(id)
+	}
+
+	action := auditUserDeactivate
+	if *req.IsActive {
+		action = auditUserActivate
+	}
+	for _, id := range updated {
+		recordAudit(ctx, h.db.Queries, auditEntry{
+			Action:     action,
+			TargetType: auditTargetUser,
+			TargetID:   id,
+			Details: map[string]interface{}{
+				"bulk": true,
+			},
+		})
+	}
+
+	response.OK(w, UserBulkStatusResponse{Results: results})
+}
+
 // GenerateResetLink handles POST /api/users/{user_id}/generate-reset-link (admin only)
 func (h *UsersHandler) GenerateResetLink(w http.ResponseWriter, r *http.Request) {
 	userIDStr := r.PathValue("user_id")
@@ -603,8 +1088,314 @@ func (h *UsersHandler) GenerateResetLink(w http.ResponseWriter, r *http.Request)
 	// Build reset link
 	resetLink := h.config.FrontendBaseURL + "/reset-password?token=" + token
 
+	recordAudit(ctx, h.db.Queries, auditEntry{
+		Action:     auditUserResetLink,
+		TargetType: auditTargetUser,
+		TargetID:   userID,
+		Details: map[string]interface{}{
+			"expires_at": expiresAt.UTC().Format(time.RFC3339),
+		},
+	})
+
 	response.OK(w, PasswordResetLinkResponse{
 		ResetLink: resetLink,
 		ExpiresAt: expiresAt.UTC().Format(time.RFC3339),
 	})
 }
+
+// SetQuotaOverride handles PATCH /api/users/{user_id}/quota (admin only)
+func (h *UsersHandler) SetQuotaOverride(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.PathValue("user_id")
+	if userIDStr == "" {
+		response.BadRequest(w, "User ID is required")
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		response.BadRequest(w, "Invalid user ID format")
+		return
+	}
+
+	ctx := r.Context()
+
+	var req UserQuotaOverrideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	if req.WeeklyUploadLimitOverrideBytes != nil {
+		if *req.WeeklyUploadLimitOverrideBytes < minWeeklyUploadBytes || *req.WeeklyUploadLimitOverrideBytes > maxUploadLimitOverrideBytes {
+			response.BadRequest(w, "weekly_upload_limit_override_bytes must be between 1MB and 1TB")
+			return
+		}
+	}
+
+	user, err := h.db.Queries.UpdateUploadLimitOverride(ctx, sqlc.UpdateUploadLimitOverrideParams{
+		ID:                             userID,
+		WeeklyUploadLimitOverrideBytes: req.WeeklyUploadLimitOverrideBytes,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.NotFound(w, "User not found")
+			return
+		}
+		log.Printf("Error updating upload limit override: %v", err)
+		response.InternalServerError(w, "Failed to update quota")
+		return
+	}
+
+	if user.WeeklyUploadLimitOverrideBytes != nil {
+		log.Printf("Set weekly upload limit override for user %s to %d bytes", userID, *user.WeeklyUploadLimitOverrideBytes)
+	} else {
+		log.Printf("Cleared weekly upload limit override for user %s", userID)
+	}
+
+	response.OK(w, UserQuotaOverrideResponse{
+		UserID:                         user.ID.String(),
+		WeeklyUploadLimitOverrideBytes: user.WeeklyUploadLimitOverrideBytes,
+	})
+}
+
+// GetQuotaHistory handles GET /api/users/{user_id}/quota/history (admin only)
+func (h *UsersHandler) GetQuotaHistory(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("user_id"))
+	if err != nil {
+		response.BadRequest(w, "Invalid user ID format")
+		return
+	}
+
+	if _, err := h.db.Queries.GetUserByID(r.Context(), userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.NotFound(w, "User not found")
+			return
+		}
+		log.Printf("Error getting user: %v", err)
+		response.InternalServerError(w, "Failed to get user")
+		return
+	}
+
+	serveQuotaHistory(w, r, h.db.Queries, userID)
+}
+
+// ListTokens handles GET /api/users/me/tokens
+func (h *UsersHandler) ListTokens(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+
+	tokens, err := h.db.Queries.ListPersonalAccessTokensByUser(ctx, userID)
+	if err != nil {
+		log.Printf("Error listing access tokens: %v", err)
+		response.InternalServerError(w, "Failed to list access tokens")
+		return
+	}
+
+	result := make([]AccessTokenResponse, len(tokens))
+	for i, t := range tokens {
+		result[i] = accessTokenToResponse(&t)
+	}
+
+	response.OK(w, result)
+}
+
+// CreateToken handles POST /api/users/me/tokens
+func (h *UsersHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+
+	var req AccessTokenCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		response.BadRequest(w, "Token name is required")
+		return
+	}
+	if utf8.RuneCountInString(name) > 100 {
+		response.BadRequest(w, "Token name must be at most 100 characters")
+		return
+	}
+
+	scope := req.Scope
+	if scope == "" {
+		scope = apitoken.ScopeFull
+	}
+	if !apitoken.IsValidScope(scope) {
+		response.BadRequest(w, "Invalid scope. Must be one of: full, upload")
+		return
+	}
+
+	token, tokenHash, err := apitoken.Generate()
+	if err != nil {
+		log.Printf("Error generating access token: %v", err)
+		response.InternalServerError(w, "Failed to create access token")
+		return
+	}
+
+	created, err := h.db.Queries.CreatePersonalAccessToken(ctx, sqlc.CreatePersonalAccessTokenParams{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: tokenHash,
+		Scope:     scope,
+	})
+	if err != nil {
+		log.Printf("Error creating access token: %v", err)
+		response.InternalServerError(w, "Failed to create access token")
+		return
+	}
+
+	log.Printf("Created %s access token %s for user %s", scope, created.ID, userID)
+
+	response.Created(w, AccessTokenCreatedResponse{
+		AccessTokenResponse: accessTokenToResponse(&created),
+		Token:               token,
+	})
+}
+
+// DeleteToken handles DELETE /api/users/me/tokens/{token_id}
+func (h *UsersHandler) DeleteToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+
+	tokenID, err := uuid.Parse(r.PathValue("token_id"))
+	if err != nil {
+		response.BadRequest(w, "Invalid token ID format")
+		return
+	}
+
+	if _, err := h.db.Queries.DeletePersonalAccessToken(ctx, sqlc.DeletePersonalAccessTokenParams{
+		ID:     tokenID,
+		UserID: userID,
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.NotFound(w, "Access token not found")
+			return
+		}
+		log.Printf("Error deleting access token: %v", err)
+		response.InternalServerError(w, "Failed to delete access token")
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// accessTokenToResponse converts a token row, omitting its hash
+func accessTokenToResponse(t *sqlc.PersonalAccessToken) AccessTokenResponse {
+	return AccessTokenResponse{
+		ID:         t.ID.String(),
+		Name:       t.Name,
+		Scope:      t.Scope,
+		CreatedAt:  t.CreatedAt,
+		LastUsedAt: t.LastUsedAt,
+	}
+}
+
+// RequestEmailChange handles POST /api/users/me/email
+// The new address only takes effect once the emailed link is confirmed
+func (h *UsersHandler) RequestEmailChange(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+
+	var req UserEmailChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	newEmail := strings.ToLower(strings.TrimSpace(req.NewEmail))
+	if newEmail == "" || req.CurrentPassword == "" {
+		response.BadRequest(w, "New email and current password are required")
+		return
+	}
+	if len(newEmail) > 255 || !strings.Contains(newEmail, "@") {
+		response.BadRequest(w, "Invalid email address")
+		return
+	}
+
+	user, err := h.db.Queries.GetUserByID(ctx, userID)
+	if err != nil {
+		log.Printf("Error getting user: %v", err)
+		response.InternalServerError(w, "Failed to get user")
+		return
+	}
+
+	if !auth.CheckPassword(req.CurrentPassword, user.PasswordHash) {
+		response.BadRequest(w, "Current password is incorrect")
+		return
+	}
+
+	if newEmail == strings.ToLower(user.Email) {
+		response.BadRequest(w, "New email is the same as the current email")
+		return
+	}
+
+	emailExists, err := h.db.Queries.UserExistsByEmail(ctx, newEmail)
+	if err != nil {
+		log.Printf("Error checking email: %v", err)
+		response.InternalServerError(w, "Failed to check email")
+		return
+	}
+	if emailExists {
+		response.Conflict(w, "Email already registered")
+		return
+	}
+
+	// Generate confirmation token
+	token, err := auth.GenerateSecureToken(32)
+	if err != nil {
+		log.Printf("Error generating token: %v", err)
+		response.InternalServerError(w, "Failed to generate token")
+		return
+	}
+
+	// Replace any earlier pending change (expires in 24 hours)
+	err = h.db.InTx(ctx, func(q *sqlc.Queries) error {
+		if err := q.DeleteEmailChangeRequestsByUser(ctx, userID); err != nil {
+			return err
+		}
+		_, err := q.CreateEmailChangeRequest(ctx, sqlc.CreateEmailChangeRequestParams{
+			UserID:    userID,
+			NewEmail:  newEmail,
+			TokenHash: auth.HashToken(token),
+			ExpiresAt: time.Now().Add(24 * time.Hour),
+		})
+		return err
+	})
+	if err != nil {
+		log.Printf("Error creating email change request: %v", err)
+		response.InternalServerError(w, "Failed to request email change")
+		return
+	}
+
+	// In production, send email to the new address here
+	// For now, log the confirmation link
+	log.Printf("Email change confirmation link for %s: %s/confirm-email-change?token=%s", newEmail, h.config.FrontendBaseURL, token)
+
+	response.OK(w, map[string]string{
+		"message": "A confirmation link has been sent to the new email address",
+	})
+}
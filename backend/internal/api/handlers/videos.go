@@ -2,17 +2,24 @@ package handlers
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html"
 	"io"
 	"log"
+	"mime"
 	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -27,8 +34,11 @@ import (
 	"github.com/clipset/clipset-go/internal/db/sqlc"
 	"github.com/clipset/clipset-go/internal/domain"
 	"github.com/clipset/clipset-go/internal/services/auth"
+	"github.com/clipset/clipset-go/internal/services/ratelimit"
 	"github.com/clipset/clipset-go/internal/services/storage"
 	"github.com/clipset/clipset-go/internal/services/upload"
+	"github.com/clipset/clipset-go/internal/services/video"
+	"github.com/clipset/clipset-go/internal/services/webhook"
 )
 
 // Short ID character set (alphanumeric)
@@ -36,16 +46,73 @@ const shortIDAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrs
 const shortIDLength = 8
 const maxShortIDRetries = 5
 
+// Maximum number of files accepted in a single batch upload
+const maxBatchUploadFiles = 20
+
+// How long stream bandwidth limits from the DB config are cached
+const streamLimitsTTL = 30 * time.Second
+
+// streamLimits are the bandwidth limits applied to a stream, in bytes per
+// second; 0 means unlimited. Key identifies whose per-user limit applies,
+// and is empty when the user isn't known.
+type streamLimits struct {
+	PerConnection int64
+	PerUser       int64
+	Key           string
+}
+
+// Cached stream limits, shared so config updates can invalidate them
+var streamLimitsCache struct {
+	mu       sync.Mutex
+	limits   streamLimits
+	loadedAt time.Time
+}
+
+// loadStreamLimits returns the stream bandwidth limits, reading the DB config
+// at most once per streamLimitsTTL
+func loadStreamLimits(ctx context.Context, queries *sqlc.Queries) (streamLimits, error) {
+	streamLimitsCache.mu.Lock()
+	defer streamLimitsCache.mu.Unlock()
+
+	if !streamLimitsCache.loadedAt.IsZero() && time.Since(streamLimitsCache.loadedAt) < streamLimitsTTL {
+		return streamLimitsCache.limits, nil
+	}
+
+	dbConfig, err := queries.GetConfig(ctx)
+	if err != nil {
+		return streamLimits{}, err
+	}
+
+	streamLimitsCache.limits = streamLimits{
+		PerConnection: dbConfig.StreamRateLimitPerConnection,
+		PerUser:       dbConfig.StreamRateLimitPerUser,
+	}
+	streamLimitsCache.loadedAt = time.Now()
+	return streamLimitsCache.limits, nil
+}
+
+// invalidateStreamLimits makes the next loadStreamLimits read the DB config
+func invalidateStreamLimits() {
+	streamLimitsCache.mu.Lock()
+	defer streamLimitsCache.mu.Unlock()
+	streamLimitsCache.loadedAt = time.Time{}
+}
+
 // EnqueueFunc is a function type for enqueueing transcode jobs
 type EnqueueFunc func(ctx context.Context, videoID string) error
 
+// WebhookEventFunc is a function type for queueing webhook deliveries of a video event
+type WebhookEventFunc func(ctx context.Context, event string, videoID uuid.UUID) error
+
 // VideosHandler handles video management endpoints
 type VideosHandler struct {
 	db           *db.DB
 	config       *config.Config
 	storage      *storage.Storage
 	chunkManager *upload.ChunkedUploadManager
-	enqueueJob   EnqueueFunc // Optional function to enqueue transcode jobs
+	bandwidth    *ratelimit.Bandwidth
+	enqueueJob   EnqueueFunc      // Optional function to enqueue transcode jobs
+	notifyEvent  WebhookEventFunc // Optional function to queue webhook deliveries
 }
 
 // NewVideosHandler creates a new videos handler
@@ -55,7 +122,9 @@ func NewVideosHandler(database *db.DB, cfg *config.Config, stor *storage.Storage
 		config:       cfg,
 		storage:      stor,
 		chunkManager: chunkMgr,
+		bandwidth:    ratelimit.NewBandwidth(),
 		enqueueJob:   nil, // Set via SetEnqueueFunc after worker is initialized
+		notifyEvent:  nil, // Set via SetWebhookEventFunc after worker is initialized
 	}
 }
 
@@ -65,6 +134,12 @@ func (h *VideosHandler) SetEnqueueFunc(fn EnqueueFunc) {
 	h.enqueueJob = fn
 }
 
+// SetWebhookEventFunc sets the function used to queue webhook deliveries
+// This should be called after the worker is initialized in main.go
+func (h *VideosHandler) SetWebhookEventFunc(fn WebhookEventFunc) {
+	h.notifyEvent = fn
+}
+
 // Response types matching Python schemas for frontend compatibility
 
 // VideoResponse represents a single video with all details
@@ -85,10 +160,16 @@ type VideoResponse struct {
 	ProcessingStatus  string    `json:"processing_status"`
 	ErrorMessage      *string   `json:"error_message"`
 	CreatedAt         time.Time `json:"created_at"`
+	CommentsEnabled   bool      `json:"comments_enabled"`
 	// Joined data
-	UploaderUsername string  `json:"uploader_username"`
-	CategoryName     *string `json:"category_name"`
-	CategorySlug     *string `json:"category_slug"`
+	UploaderUsername    string  `json:"uploader_username"`
+	UploaderDisplayName string  `json:"uploader_display_name"`
+	CategoryName        *string `json:"category_name"`
+	CategorySlug        *string `json:"category_slug"`
+	CommentCount        int64   `json:"comment_count"` // Includes replies
+	// Admin only: SHA-256 of the upload, and of the stored output
+	SourceSha256 *string `json:"source_sha256,omitempty"`
+	OutputSha256 *string `json:"output_sha256,omitempty"`
 }
 
 // VideoListResponse represents paginated video list
@@ -99,9 +180,10 @@ type VideoListResponse struct {
 
 // VideoUpdateRequest represents the update video request
 type VideoUpdateRequest struct {
-	Title       *string `json:"title"`
-	Description *string `json:"description"`
-	CategoryID  *string `json:"category_id"`
+	Title           *string `json:"title"`
+	Description     *string `json:"description"`
+	CategoryID      *string `json:"category_id"`
+	CommentsEnabled *bool   `json:"comments_enabled"`
 }
 
 // QuotaInfoResponse represents user quota information
@@ -112,6 +194,8 @@ type QuotaInfoResponse struct {
 	PercentageUsed   float64 `json:"percentage_used"`
 	CanUpload        bool    `json:"can_upload"`
 	MaxFileSizeBytes int64   `json:"max_file_size_bytes"`
+	// LimitBytes comes from a per-user override rather than the global config
+	LimitOverridden bool `json:"limit_overridden"`
 }
 
 // QuotaResetResponse represents the quota reset response
@@ -120,13 +204,83 @@ type QuotaResetResponse struct {
 	Message    string `json:"message"`
 }
 
+// FailedVideoResponse represents a video whose processing failed
+type FailedVideoResponse struct {
+	ID               string     `json:"id"`
+	ShortID          string     `json:"short_id"`
+	Title            string     `json:"title"`
+	UploadedBy       string     `json:"uploaded_by"`
+	UploaderUsername string     `json:"uploader_username"`
+	ErrorMessage     *string    `json:"error_message"`
+	Attempts         int32      `json:"attempts"`
+	SourceExists     bool       `json:"source_exists"` // Whether the upload is still there to retry from
+	CreatedAt        time.Time  `json:"created_at"`
+	LastFailedAt     *time.Time `json:"last_failed_at"` // Null for failures from before attempts were counted
+}
+
+// FailedVideosRetryResponse represents the result of retrying failed videos
+type FailedVideosRetryResponse struct {
+	Retried int `json:"retried"`
+	Skipped int `json:"skipped"` // Videos whose upload no longer exists
+}
+
+// FailedVideosPurgeResponse represents the failed videos deleted (or, for a dry run, that would be)
+type FailedVideosPurgeResponse struct {
+	DryRun bool                  `json:"dry_run"`
+	Purged []FailedVideoResponse `json:"purged"`
+}
+
+// QuotaEventResponse represents one change to a user's weekly upload usage
+type QuotaEventResponse struct {
+	ID             string    `json:"id"`
+	EventType      string    `json:"event_type"` // upload, weekly_reset or admin_reset
+	BytesDelta     int64     `json:"bytes_delta"`
+	UsedBytesAfter int64     `json:"used_bytes_after"`
+	VideoID        *string   `json:"video_id"`
+	VideoShortID   *string   `json:"video_short_id"`
+	VideoTitle     *string   `json:"video_title"`
+	ActorUsername  *string   `json:"actor_username"` // Admin who triggered a reset
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// QuotaHistoryResponse represents a page of a user's quota events
+type QuotaHistoryResponse struct {
+	Events []QuotaEventResponse `json:"events"`
+	Total  int64                `json:"total"`
+}
+
+// Quota event types recorded in quota_events
+const (
+	quotaEventUpload     = "upload"
+	quotaEventAdminReset = "admin_reset"
+)
+
 // StreamInfoResponse represents streaming availability information
 type StreamInfoResponse struct {
-	Format           string  `json:"format"`                      // "hls", "progressive", "unknown"
-	ManifestURL      *string `json:"manifest_url,omitempty"`      // URL to HLS manifest
-	StreamURL        *string `json:"stream_url,omitempty"`        // URL to progressive stream
-	Ready            bool    `json:"ready"`                       // Whether the video is ready to stream
-	ProcessingStatus *string `json:"processing_status,omitempty"` // Status if not ready
+	Format           string   `json:"format"`                      // "hls", "progressive", "unknown"
+	ManifestURL      *string  `json:"manifest_url,omitempty"`      // URL to HLS manifest
+	DASHManifestURL  *string  `json:"dash_manifest_url,omitempty"` // URL to DASH manifest, for fMP4 HLS
+	StreamURL        *string  `json:"stream_url,omitempty"`        // URL to progressive stream
+	Ready            bool     `json:"ready"`                       // Whether the video is ready to stream
+	ProcessingStatus *string  `json:"processing_status,omitempty"` // Status if not ready
+	AudioTracks      *int32   `json:"audio_tracks,omitempty"`      // Audio tracks in the output, if known
+	VideoCodec       *string  `json:"video_codec,omitempty"`       // Output video codec (h264, hevc or av1), if known
+	Qualities        []string `json:"qualities,omitempty"`         // Lower qualities the progressive stream can be requested at
+}
+
+// StreamURLResponse represents a signed, time-limited progressive stream URL
+type StreamURLResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ProcessingProgressResponse represents how far a video's processing has got.
+// Phase, percent and updated_at are null unless the worker is processing it.
+type ProcessingProgressResponse struct {
+	ProcessingStatus string     `json:"processing_status"`
+	Phase            *string    `json:"phase"`   // probing, transcoding, thumbnail or finalizing
+	Percent          *float64   `json:"percent"` // Transcode progress, 0-100
+	UpdatedAt        *time.Time `json:"updated_at"`
 }
 
 // ViewCountResponse represents the view count after increment
@@ -152,6 +306,54 @@ type ChunkUploadCompleteRequest struct {
 	Description *string `json:"description"`
 	CategoryID  *string `json:"category_id"`
 	Filename    string  `json:"filename"`
+	Checksum    *string `json:"checksum"` // Optional SHA-256 (hex) of the merged file
+}
+
+// UploadPrecheckRequest represents a pre-upload validation request
+type UploadPrecheckRequest struct {
+	SHA256    string `json:"sha256"`
+	SizeBytes int64  `json:"size_bytes"`
+	Filename  string `json:"filename"`
+}
+
+// UploadPrecheckResponse reports whether an upload would be accepted
+type UploadPrecheckResponse struct {
+	CanUpload         bool     `json:"can_upload"`
+	Duplicate         bool     `json:"duplicate"`
+	DuplicateShortID  *string  `json:"duplicate_short_id"`
+	ExtensionAccepted bool     `json:"extension_accepted"`
+	SizeAccepted      bool     `json:"size_accepted"`
+	QuotaAvailable    bool     `json:"quota_available"`
+	Reasons           []string `json:"reasons"`
+}
+
+// BatchUploadResult is the outcome of a single file in a batch upload
+type BatchUploadResult struct {
+	Index    int            `json:"index"`
+	Filename string         `json:"filename"`
+	Video    *VideoResponse `json:"video"`
+	Error    *string        `json:"error"`
+}
+
+// BatchUploadResponse represents the batch upload response
+type BatchUploadResponse struct {
+	Results   []BatchUploadResult `json:"results"`
+	Succeeded int                 `json:"succeeded"`
+	Failed    int                 `json:"failed"`
+}
+
+// UploadSessionResponse represents an in-progress chunked upload session (admin debugging)
+type UploadSessionResponse struct {
+	UploadID       string    `json:"upload_id"`
+	UserID         *string   `json:"user_id"`
+	Filename       *string   `json:"filename"`
+	ExpectedSize   int64     `json:"expected_size"`
+	ChunkCount     int       `json:"chunk_count"`
+	SizeBytes      int64     `json:"size_bytes"`
+	CreatedAt      time.Time `json:"created_at"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+	AgeSeconds     int64     `json:"age_seconds"`
+	Expired        bool      `json:"expired"`
 }
 
 // Helper functions
@@ -190,9 +392,17 @@ func (h *VideosHandler) getDBConfig(ctx context.Context) (maxFileSize int64, wee
 	return dbConfig.MaxFileSizeBytes, dbConfig.WeeklyUploadLimitBytes
 }
 
+// effectiveWeeklyLimit returns the user's override if set, otherwise the global limit
+func effectiveWeeklyLimit(globalLimit int64, override *int64) (limit int64, overridden bool) {
+	if override != nil {
+		return *override, true
+	}
+	return globalLimit, false
+}
+
 // checkUserQuota checks if user can upload a file of given size
 func (h *VideosHandler) checkUserQuota(ctx context.Context, userID uuid.UUID, fileSize int64) (bool, string) {
-	_, weeklyLimit := h.getDBConfig(ctx)
+	_, globalLimit := h.getDBConfig(ctx)
 
 	quota, err := h.db.Queries.GetUserQuota(ctx, userID)
 	if err != nil {
@@ -200,6 +410,8 @@ func (h *VideosHandler) checkUserQuota(ctx context.Context, userID uuid.UUID, fi
 		return true, "" // Allow upload if quota check fails
 	}
 
+	weeklyLimit, _ := effectiveWeeklyLimit(globalLimit, quota.WeeklyUploadLimitOverrideBytes)
+
 	wouldUse := quota.WeeklyUploadBytes + fileSize
 	if wouldUse > weeklyLimit {
 		usedGB := float64(quota.WeeklyUploadBytes) / (1024 * 1024 * 1024)
@@ -211,17 +423,65 @@ func (h *VideosHandler) checkUserQuota(ctx context.Context, userID uuid.UUID, fi
 	return true, ""
 }
 
+// createVideoRecord inserts the video row and charges the uploader's quota in one transaction,
+// recording the charge in the quota history.
+// New rows are marked needs_enqueue until their transcode job has been enqueued.
+func (h *VideosHandler) createVideoRecord(ctx context.Context, params sqlc.CreateVideoParams) (sqlc.Video, error) {
+	var video sqlc.Video
+	err := h.db.InTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		video, err = q.CreateVideo(ctx, params)
+		if err != nil {
+			return fmt.Errorf("failed to create video: %w", err)
+		}
+
+		if err := q.UpdateUploadQuota(ctx, sqlc.UpdateUploadQuotaParams{
+			ID:                params.UploadedBy,
+			WeeklyUploadBytes: params.FileSizeBytes,
+		}); err != nil {
+			return fmt.Errorf("failed to update user quota: %w", err)
+		}
+
+		if err := q.CreateQuotaEvent(ctx, sqlc.CreateQuotaEventParams{
+			UserID:     params.UploadedBy,
+			EventType:  quotaEventUpload,
+			BytesDelta: params.FileSizeBytes,
+			VideoID:    pgtype.UUID{Bytes: video.ID, Valid: true},
+		}); err != nil {
+			return fmt.Errorf("failed to record quota event: %w", err)
+		}
+		return nil
+	})
+	return video, err
+}
+
+// notifyUploaded tells webhooks a video was uploaded. Failures are logged
+// and otherwise ignored, so webhooks never fail an upload.
+func (h *VideosHandler) notifyUploaded(ctx context.Context, videoID uuid.UUID) {
+	if h.notifyEvent == nil {
+		return
+	}
+	if err := h.notifyEvent(ctx, webhook.EventVideoUploaded, videoID); err != nil {
+		log.Printf("Warning: failed to queue upload webhooks for video %s: %v", videoID, err)
+	}
+}
+
 // triggerProcessing enqueues a video for background transcoding
 func (h *VideosHandler) triggerProcessing(ctx context.Context, videoID uuid.UUID) {
 	if h.enqueueJob == nil {
-		log.Printf("Warning: video %s uploaded but no enqueue function set - processing skipped", videoID)
+		log.Printf("Warning: video %s uploaded but no enqueue function set - left for the enqueue outbox", videoID)
 		return
 	}
 
 	if err := h.enqueueJob(ctx, videoID.String()); err != nil {
-		log.Printf("Error enqueueing transcode job for video %s: %v", videoID, err)
-		// Don't fail the upload - the video is saved, just not processed yet
-		// An admin can manually trigger reprocessing later
+		// Don't fail the upload - the video stays marked needs_enqueue and
+		// the worker's outbox job will enqueue it on its next poll
+		log.Printf("Error enqueueing transcode job for video %s (will retry from outbox): %v", videoID, err)
+		return
+	}
+
+	if err := h.db.Queries.ClearVideoNeedsEnqueue(ctx, videoID); err != nil {
+		log.Printf("Warning: failed to clear needs_enqueue for video %s: %v", videoID, err)
 	}
 }
 
@@ -235,25 +495,28 @@ func buildVideoResponse(v sqlc.ListVideosWithAccessRow) VideoResponse {
 	}
 
 	return VideoResponse{
-		ID:                v.ID.String(),
-		ShortID:           v.ShortID,
-		Title:             v.Title,
-		Description:       v.Description,
-		Filename:          v.Filename,
-		ThumbnailFilename: v.ThumbnailFilename,
-		OriginalFilename:  v.OriginalFilename,
-		StoragePath:       v.StoragePath,
-		FileSizeBytes:     v.FileSizeBytes,
-		DurationSeconds:   v.DurationSeconds,
-		UploadedBy:        v.UploadedBy.String(),
-		CategoryID:        categoryID,
-		ViewCount:         v.ViewCount,
-		ProcessingStatus:  string(v.ProcessingStatus),
-		ErrorMessage:      v.ErrorMessage,
-		CreatedAt:         v.CreatedAt,
-		UploaderUsername:  v.UploaderUsername,
-		CategoryName:      v.CategoryName,
-		CategorySlug:      v.CategorySlug,
+		ID:                  v.ID.String(),
+		ShortID:             v.ShortID,
+		Title:               v.Title,
+		Description:         v.Description,
+		Filename:            v.Filename,
+		ThumbnailFilename:   v.ThumbnailFilename,
+		OriginalFilename:    v.OriginalFilename,
+		StoragePath:         v.StoragePath,
+		FileSizeBytes:       v.FileSizeBytes,
+		DurationSeconds:     v.DurationSeconds,
+		UploadedBy:          v.UploadedBy.String(),
+		CategoryID:          categoryID,
+		ViewCount:           v.ViewCount,
+		ProcessingStatus:    string(v.ProcessingStatus),
+		ErrorMessage:        v.ErrorMessage,
+		CreatedAt:           v.CreatedAt,
+		CommentsEnabled:     v.CommentsEnabled,
+		UploaderUsername:    v.UploaderUsername,
+		UploaderDisplayName: v.UploaderDisplayName,
+		CategoryName:        v.CategoryName,
+		CategorySlug:        v.CategorySlug,
+		CommentCount:        v.CommentCount,
 	}
 }
 
@@ -267,25 +530,28 @@ func buildVideoResponseFromShortIDRow(v sqlc.GetVideoByShortIDWithUploaderRow) V
 	}
 
 	return VideoResponse{
-		ID:                v.ID.String(),
-		ShortID:           v.ShortID,
-		Title:             v.Title,
-		Description:       v.Description,
-		Filename:          v.Filename,
-		ThumbnailFilename: v.ThumbnailFilename,
-		OriginalFilename:  v.OriginalFilename,
-		StoragePath:       v.StoragePath,
-		FileSizeBytes:     v.FileSizeBytes,
-		DurationSeconds:   v.DurationSeconds,
-		UploadedBy:        v.UploadedBy.String(),
-		CategoryID:        categoryID,
-		ViewCount:         v.ViewCount,
-		ProcessingStatus:  string(v.ProcessingStatus),
-		ErrorMessage:      v.ErrorMessage,
-		CreatedAt:         v.CreatedAt,
-		UploaderUsername:  v.UploaderUsername,
-		CategoryName:      v.CategoryName,
-		CategorySlug:      v.CategorySlug,
+		ID:                  v.ID.String(),
+		ShortID:             v.ShortID,
+		Title:               v.Title,
+		Description:         v.Description,
+		Filename:            v.Filename,
+		ThumbnailFilename:   v.ThumbnailFilename,
+		OriginalFilename:    v.OriginalFilename,
+		StoragePath:         v.StoragePath,
+		FileSizeBytes:       v.FileSizeBytes,
+		DurationSeconds:     v.DurationSeconds,
+		UploadedBy:          v.UploadedBy.String(),
+		CategoryID:          categoryID,
+		ViewCount:           v.ViewCount,
+		ProcessingStatus:    string(v.ProcessingStatus),
+		ErrorMessage:        v.ErrorMessage,
+		CreatedAt:           v.CreatedAt,
+		CommentsEnabled:     v.CommentsEnabled,
+		UploaderUsername:    v.UploaderUsername,
+		UploaderDisplayName: v.UploaderDisplayName,
+		CategoryName:        v.CategoryName,
+		CategorySlug:        v.CategorySlug,
+		CommentCount:        v.CommentCount,
 	}
 }
 
@@ -299,42 +565,177 @@ func buildVideoResponseFromIDRow(v sqlc.GetVideoByIDWithUploaderRow) VideoRespon
 	}
 
 	return VideoResponse{
-		ID:                v.ID.String(),
-		ShortID:           v.ShortID,
-		Title:             v.Title,
-		Description:       v.Description,
-		Filename:          v.Filename,
-		ThumbnailFilename: v.ThumbnailFilename,
-		OriginalFilename:  v.OriginalFilename,
-		StoragePath:       v.StoragePath,
-		FileSizeBytes:     v.FileSizeBytes,
-		DurationSeconds:   v.DurationSeconds,
-		UploadedBy:        v.UploadedBy.String(),
-		CategoryID:        categoryID,
-		ViewCount:         v.ViewCount,
-		ProcessingStatus:  string(v.ProcessingStatus),
-		ErrorMessage:      v.ErrorMessage,
-		CreatedAt:         v.CreatedAt,
-		UploaderUsername:  v.UploaderUsername,
-		CategoryName:      v.CategoryName,
-		CategorySlug:      v.CategorySlug,
-	}
-}
-
-// hasVideoAccess checks if user can access a video
-func hasVideoAccess(video sqlc.Video, userID uuid.UUID, isAdmin bool) bool {
-	if isAdmin {
+		ID:                  v.ID.String(),
+		ShortID:             v.ShortID,
+		Title:               v.Title,
+		Description:         v.Description,
+		Filename:            v.Filename,
+		ThumbnailFilename:   v.ThumbnailFilename,
+		OriginalFilename:    v.OriginalFilename,
+		StoragePath:         v.StoragePath,
+		FileSizeBytes:       v.FileSizeBytes,
+		DurationSeconds:     v.DurationSeconds,
+		UploadedBy:          v.UploadedBy.String(),
+		CategoryID:          categoryID,
+		ViewCount:           v.ViewCount,
+		ProcessingStatus:    string(v.ProcessingStatus),
+		ErrorMessage:        v.ErrorMessage,
+		CreatedAt:           v.CreatedAt,
+		CommentsEnabled:     v.CommentsEnabled,
+		UploaderUsername:    v.UploaderUsername,
+		UploaderDisplayName: v.UploaderDisplayName,
+		CategoryName:        v.CategoryName,
+		CategorySlug:        v.CategorySlug,
+		CommentCount:        v.CommentCount,
+	}
+}
+
+// checkUploadSession verifies a chunked upload session is usable by the user, writing
+// 400 for malformed IDs, 404 for unknown sessions, 403 for other users' sessions
+// and 410 for expired ones. Returns false if a response was written.
+func (h *VideosHandler) checkUploadSession(w http.ResponseWriter, uploadID string, userID uuid.UUID) bool {
+	if err := h.chunkManager.CheckSession(uploadID, userID.String()); err != nil {
+		writeUploadSessionError(w, err)
+		return false
+	}
+	return true
+}
+
+// writeUploadSessionError writes the response for a failed upload session check
+func writeUploadSessionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, upload.ErrSessionExpired):
+		response.Error(w, http.StatusGone, "Upload session has expired")
+	case errors.Is(err, upload.ErrSessionNotFound):
+		response.NotFound(w, "Upload session not found")
+	case errors.Is(err, upload.ErrInvalidSessionID):
+		response.BadRequest(w, "Invalid upload ID format")
+	case errors.Is(err, upload.ErrSessionNotOwned):
+		response.Forbidden(w, "You can only use your own uploads")
+	default:
+		log.Printf("Error checking upload session: %v", err)
+		response.InternalServerError(w, "Failed to check upload session")
+	}
+}
+
+// uploadError is a client-facing error raised while storing an uploaded video
+type uploadError struct {
+	status  int
+	message string
+}
+
+func (e *uploadError) Error() string {
+	return e.message
+}
+
+// checkFreeSpace writes a 507 and returns false if storing size more bytes
+// under path would leave less free disk space than configured. A check that
+// can't read the free space lets the upload through.
+func (h *VideosHandler) checkFreeSpace(w http.ResponseWriter, path string, size int64) bool {
+	err := storage.CheckFreeSpace(path, size, h.config.MinFreeDiskBytes)
+	if err == nil {
 		return true
 	}
-	if video.ProcessingStatus == domain.ProcessingStatusCompleted {
+	if !errors.Is(err, storage.ErrInsufficientStorage) {
+		log.Printf("Warning: failed to check free disk space: %v", err)
 		return true
 	}
-	return video.UploadedBy == userID
+
+	log.Printf("Refusing upload: %v", err)
+	response.Error(w, http.StatusInsufficientStorage, "The server is running out of disk space. Please try again later.")
+	return false
+}
+
+// resolveUploadCategory validates an optional category ID from an upload form
+func (h *VideosHandler) resolveUploadCategory(ctx context.Context, categoryIDStr string) (pgtype.UUID, *uploadError) {
+	if categoryIDStr == "" {
+		return pgtype.UUID{}, nil
+	}
+
+	catID, err := uuid.Parse(categoryIDStr)
+	if err != nil {
+		return pgtype.UUID{}, &uploadError{http.StatusBadRequest, "Invalid category ID format"}
+	}
+
+	if _, err := h.db.Queries.GetCategoryByID(ctx, catID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return pgtype.UUID{}, &uploadError{http.StatusNotFound, "Category not found"}
+		}
+		log.Printf("Error checking category: %v", err)
+		return pgtype.UUID{}, &uploadError{http.StatusInternalServerError, "Failed to validate category"}
+	}
+
+	return pgtype.UUID{Bytes: catID, Valid: true}, nil
 }
 
-// isVideoOwnerOrAdmin checks if user is the video owner or admin
-func isVideoOwnerOrAdmin(video sqlc.Video, userID uuid.UUID, isAdmin bool) bool {
-	return isAdmin || video.UploadedBy == userID
+// storeUploadedVideo saves an uploaded file to temp storage, validates it, creates the
+// video record, charges the user's quota and triggers processing.
+// Extension, size and quota checks are the caller's responsibility.
+func (h *VideosHandler) storeUploadedVideo(ctx context.Context, userID uuid.UUID, file io.Reader, originalFilename, title string, description *string, categoryID pgtype.UUID) (sqlc.Video, *uploadError) {
+	// Sniff content before writing anything to disk
+	file, err := storage.SniffVideoReader(file)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrEmptyFile):
+			return sqlc.Video{}, &uploadError{http.StatusBadRequest, "File is empty"}
+		case errors.Is(err, storage.ErrNotVideo):
+			return sqlc.Video{}, &uploadError{http.StatusBadRequest, "File does not appear to be a valid video"}
+		}
+		log.Printf("Error reading uploaded file: %v", err)
+		return sqlc.Video{}, &uploadError{http.StatusInternalServerError, "Failed to read uploaded file"}
+	}
+
+	// Generate unique filename
+	uniqueFilename := storage.GenerateUniqueFilename(originalFilename)
+	tempPath := h.storage.TempPath(uniqueFilename)
+
+	// Save file to temp storage, hashing it for duplicate detection
+	bytesWritten, contentHash, err := h.storage.SaveUploadedFile(file, tempPath)
+	if err != nil {
+		log.Printf("Error saving uploaded file: %v", err)
+		return sqlc.Video{}, &uploadError{http.StatusInternalServerError, "Failed to save uploaded file"}
+	}
+
+	// Validate it's actually a video file
+	if err := storage.ValidateVideoFile(tempPath); err != nil {
+		h.storage.DeleteFile(tempPath)
+		return sqlc.Video{}, &uploadError{http.StatusBadRequest, "File does not appear to be a valid video"}
+	}
+
+	// Generate short ID
+	shortID, err := h.generateUniqueShortID(ctx)
+	if err != nil {
+		h.storage.DeleteFile(tempPath)
+		log.Printf("Error generating short ID: %v", err)
+		return sqlc.Video{}, &uploadError{http.StatusInternalServerError, "Failed to generate video ID"}
+	}
+
+	// Create video record and charge quota
+	video, err := h.createVideoRecord(ctx, sqlc.CreateVideoParams{
+		ShortID:          shortID,
+		Title:            title,
+		Description:      description,
+		Filename:         uniqueFilename,
+		OriginalFilename: originalFilename,
+		FileSizeBytes:    bytesWritten,
+		UploadedBy:       userID,
+		CategoryID:       categoryID,
+		StoragePath:      nil,
+		ContentSha256:    &contentHash,
+	})
+	if err != nil {
+		h.storage.DeleteFile(tempPath)
+		log.Printf("Error creating video record: %v", err)
+		return sqlc.Video{}, &uploadError{http.StatusInternalServerError, "Failed to create video record"}
+	}
+
+	// Trigger background processing
+	h.triggerProcessing(ctx, video.ID)
+	h.notifyUploaded(ctx, video.ID)
+
+	log.Printf("Video uploaded successfully: %s by user %s", video.ID, userID)
+
+	return video, nil
 }
 
 // Handlers
@@ -350,6 +751,11 @@ func (h *VideosHandler) Upload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Refuse before reading the body if the temp volume can't hold it
+	if !h.checkFreeSpace(w, h.config.TempStoragePath, r.ContentLength) {
+		return
+	}
+
 	// Parse multipart form (max 2GB)
 	maxSize := h.config.MaxFileSizeBytes
 	if maxSize == 0 {
@@ -389,6 +795,12 @@ func (h *VideosHandler) Upload(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
+	// Reject empty files early
+	if header.Size == 0 {
+		response.BadRequest(w, "File is empty")
+		return
+	}
+
 	// Validate file extension
 	ext := filepath.Ext(header.Filename)
 	if !h.config.IsAcceptedVideoFormat(ext) {
@@ -413,53 +825,9 @@ func (h *VideosHandler) Upload(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate category if provided
-	var categoryID pgtype.UUID
-	if categoryIDStr != "" {
-		catID, err := uuid.Parse(categoryIDStr)
-		if err != nil {
-			response.BadRequest(w, "Invalid category ID format")
-			return
-		}
-
-		_, err = h.db.Queries.GetCategoryByID(ctx, catID)
-		if err != nil {
-			if errors.Is(err, pgx.ErrNoRows) {
-				response.NotFound(w, "Category not found")
-				return
-			}
-			log.Printf("Error checking category: %v", err)
-			response.InternalServerError(w, "Failed to validate category")
-			return
-		}
-
-		categoryID = pgtype.UUID{Bytes: catID, Valid: true}
-	}
-
-	// Generate unique filename
-	uniqueFilename := storage.GenerateUniqueFilename(header.Filename)
-	tempPath := h.storage.TempPath(uniqueFilename)
-
-	// Save file to temp storage
-	bytesWritten, err := h.storage.SaveUploadedFile(file, tempPath)
-	if err != nil {
-		log.Printf("Error saving uploaded file: %v", err)
-		response.InternalServerError(w, "Failed to save uploaded file")
-		return
-	}
-
-	// Validate it's actually a video file
-	if err := storage.ValidateVideoFile(tempPath); err != nil {
-		h.storage.DeleteFile(tempPath)
-		response.BadRequest(w, "File does not appear to be a valid video")
-		return
-	}
-
-	// Generate short ID
-	shortID, err := h.generateUniqueShortID(ctx)
-	if err != nil {
-		h.storage.DeleteFile(tempPath)
-		log.Printf("Error generating short ID: %v", err)
-		response.InternalServerError(w, "Failed to generate video ID")
+	categoryID, uerr := h.resolveUploadCategory(ctx, categoryIDStr)
+	if uerr != nil {
+		response.Error(w, uerr.status, uerr.message)
 		return
 	}
 
@@ -470,38 +838,13 @@ func (h *VideosHandler) Upload(w http.ResponseWriter, r *http.Request) {
 		desc = &trimmedDesc
 	}
 
-	// Create video record
-	video, err := h.db.Queries.CreateVideo(ctx, sqlc.CreateVideoParams{
-		ShortID:          shortID,
-		Title:            title,
-		Description:      desc,
-		Filename:         uniqueFilename,
-		OriginalFilename: header.Filename,
-		FileSizeBytes:    bytesWritten,
-		UploadedBy:       userID,
-		CategoryID:       categoryID,
-		StoragePath:      nil,
-	})
-	if err != nil {
-		h.storage.DeleteFile(tempPath)
-		log.Printf("Error creating video record: %v", err)
-		response.InternalServerError(w, "Failed to create video record")
+	// Save file and create video record
+	video, uerr := h.storeUploadedVideo(ctx, userID, file, header.Filename, title, desc, categoryID)
+	if uerr != nil {
+		response.Error(w, uerr.status, uerr.message)
 		return
 	}
 
-	// Increment user quota
-	if err := h.db.Queries.UpdateUploadQuota(ctx, sqlc.UpdateUploadQuotaParams{
-		ID:                userID,
-		WeeklyUploadBytes: bytesWritten,
-	}); err != nil {
-		log.Printf("Warning: failed to update user quota: %v", err)
-	}
-
-	// Trigger background processing
-	h.triggerProcessing(ctx, video.ID)
-
-	log.Printf("Video uploaded successfully: %s by user %s", video.ID, userID)
-
 	// Get full video response with uploader info
 	videoWithUploader, err := h.db.Queries.GetVideoByIDWithUploader(ctx, video.ID)
 	if err != nil {
@@ -518,8 +861,13 @@ func (h *VideosHandler) Upload(w http.ResponseWriter, r *http.Request) {
 	response.Created(w, buildVideoResponseFromIDRow(videoWithUploader))
 }
 
-// InitChunkedUpload handles POST /api/videos/upload/init
-func (h *VideosHandler) InitChunkedUpload(w http.ResponseWriter, r *http.Request) {
+// UploadBatch handles POST /api/videos/upload/batch
+//
+// Accepts multiple "file" parts. Titles are given either as a "titles" JSON array
+// or as repeated "title" fields, in the same order as the files; "descriptions"
+// (JSON array) and "category_id" are optional. Quota is checked for the whole
+// batch up front; all other validation is reported per file.
+func (h *VideosHandler) UploadBatch(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	// Get current user
@@ -529,64 +877,314 @@ func (h *VideosHandler) InitChunkedUpload(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Parse request
-	var req ChunkUploadInitRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, "Invalid request body")
+	// Parse multipart form (files beyond the in-memory limit spill to disk)
+	maxSize := h.config.MaxFileSizeBytes
+	if maxSize == 0 {
+		maxSize = 2 << 30 // 2GB default
+	}
+	if err := r.ParseMultipartForm(maxSize); err != nil {
+		response.BadRequest(w, "Failed to parse form data or file too large")
 		return
 	}
 
-	// Validate filename
-	if req.Filename == "" {
-		response.BadRequest(w, "Filename is required")
+	files := r.MultipartForm.File["file"]
+	if len(files) == 0 {
+		response.BadRequest(w, "No files provided")
 		return
 	}
-
-	ext := filepath.Ext(req.Filename)
-	if !h.config.IsAcceptedVideoFormat(ext) {
-		response.BadRequest(w, fmt.Sprintf("Invalid file type. Accepted formats: %s", h.config.AcceptedFormatsString()))
+	if len(files) > maxBatchUploadFiles {
+		response.BadRequest(w, fmt.Sprintf("Too many files. Maximum per batch: %d", maxBatchUploadFiles))
 		return
 	}
 
-	// Validate expected size
-	if req.ExpectedSize <= 0 {
-		response.BadRequest(w, "Expected size must be positive")
+	// Collect titles
+	var titles []string
+	if titlesJSON := r.FormValue("titles"); titlesJSON != "" {
+		if err := json.Unmarshal([]byte(titlesJSON), &titles); err != nil {
+			response.BadRequest(w, "Invalid titles format")
+			return
+		}
+	} else {
+		titles = r.MultipartForm.Value["title"]
+	}
+	if len(titles) != len(files) {
+		response.BadRequest(w, "Number of titles must match number of files")
 		return
 	}
 
-	// Get DB config
-	maxFileSize, _ := h.getDBConfig(ctx)
-
-	// Pre-check file size
-	if req.ExpectedSize > maxFileSize {
-		response.BadRequest(w, fmt.Sprintf("File too large. Maximum size: %.2f GB", float64(maxFileSize)/(1024*1024*1024)))
-		return
+	// Collect descriptions (optional)
+	var descriptions []string
+	if descriptionsJSON := r.FormValue("descriptions"); descriptionsJSON != "" {
+		if err := json.Unmarshal([]byte(descriptionsJSON), &descriptions); err != nil {
+			response.BadRequest(w, "Invalid descriptions format")
+			return
+		}
+		if len(descriptions) != len(files) {
+			response.BadRequest(w, "Number of descriptions must match number of files")
+			return
+		}
 	}
 
-	// Pre-check user quota
-	canUpload, reason := h.checkUserQuota(ctx, userID, req.ExpectedSize)
+	// Check cumulative quota for the whole batch
+	var totalSize int64
+	for _, header := range files {
+		totalSize += header.Size
+	}
+	canUpload, reason := h.checkUserQuota(ctx, userID, totalSize)
 	if !canUpload {
 		response.Forbidden(w, reason)
 		return
 	}
 
-	// Initialize upload session
-	uploadID, err := h.chunkManager.InitSession()
-	if err != nil {
-		log.Printf("Error initializing upload session: %v", err)
-		response.InternalServerError(w, "Failed to initialize upload")
+	// Validate category if provided (shared by all files)
+	categoryID, uerr := h.resolveUploadCategory(ctx, r.FormValue("category_id"))
+	if uerr != nil {
+		response.Error(w, uerr.status, uerr.message)
 		return
 	}
 
-	log.Printf("Initialized chunked upload session %s for user %s", uploadID, userID)
+	// Get DB config
+	maxFileSize, _ := h.getDBConfig(ctx)
 
-	response.OK(w, ChunkUploadInitResponse{UploadID: uploadID})
-}
+	results := make([]BatchUploadResult, len(files))
+	succeeded := 0
+	for i, header := range files {
+		results[i] = BatchUploadResult{
+			Index:    i,
+			Filename: header.Filename,
+		}
+
+		fail := func(msg string) {
+			results[i].Error = &msg
+		}
+
+		// Validate title
+		title := strings.TrimSpace(titles[i])
+		if title == "" {
+			fail("Title is required")
+			continue
+		}
+		if len(title) > 200 {
+			fail("Title must be 200 characters or less")
+			continue
+		}
+
+		// Validate description
+		var desc *string
+		if descriptions != nil && strings.TrimSpace(descriptions[i]) != "" {
+			if len(descriptions[i]) > 2000 {
+				fail("Description must be 2000 characters or less")
+				continue
+			}
+			trimmedDesc := strings.TrimSpace(descriptions[i])
+			desc = &trimmedDesc
+		}
+
+		// Reject empty files early
+		if header.Size == 0 {
+			fail("File is empty")
+			continue
+		}
+
+		// Validate file extension
+		if !h.config.IsAcceptedVideoFormat(filepath.Ext(header.Filename)) {
+			fail(fmt.Sprintf("Invalid file type. Accepted formats: %s", h.config.AcceptedFormatsString()))
+			continue
+		}
+
+		// Check file size
+		if header.Size > maxFileSize {
+			fail(fmt.Sprintf("File too large. Maximum size: %.2f GB", float64(maxFileSize)/(1024*1024*1024)))
+			continue
+		}
+
+		file, err := header.Open()
+		if err != nil {
+			log.Printf("Error opening uploaded file %s: %v", header.Filename, err)
+			fail("Failed to read uploaded file")
+			continue
+		}
+
+		video, uerr := h.storeUploadedVideo(ctx, userID, file, header.Filename, title, desc, categoryID)
+		file.Close()
+		if uerr != nil {
+			fail(uerr.message)
+			continue
+		}
+
+		succeeded++
+
+		videoWithUploader, err := h.db.Queries.GetVideoByIDWithUploader(ctx, video.ID)
+		if err != nil {
+			log.Printf("Warning: failed to get video with uploader: %v", err)
+			continue
+		}
+		videoResp := buildVideoResponseFromIDRow(videoWithUploader)
+		results[i].Video = &videoResp
+	}
+
+	log.Printf("Batch upload by user %s: %d/%d files succeeded", userID, succeeded, len(files))
+
+	response.OK(w, BatchUploadResponse{
+		Results:   results,
+		Succeeded: succeeded,
+		Failed:    len(files) - succeeded,
+	})
+}
+
+// Precheck handles POST /api/videos/precheck
+// Runs upload validation (duplicate, extension, size, quota) without receiving the file.
+func (h *VideosHandler) Precheck(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get current user
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+
+	// Parse request
+	var req UploadPrecheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	hash := strings.ToLower(strings.TrimSpace(req.SHA256))
+	if _, err := hex.DecodeString(hash); err != nil || len(hash) != 64 {
+		response.BadRequest(w, "sha256 must be a 64-character hex digest")
+		return
+	}
+	if req.Filename == "" {
+		response.BadRequest(w, "Filename is required")
+		return
+	}
+	if req.SizeBytes <= 0 {
+		response.BadRequest(w, "Size must be positive")
+		return
+	}
+
+	result := UploadPrecheckResponse{
+		Reasons: []string{},
+	}
+
+	// Check for an existing upload of the same file by this user
+	existing, err := h.db.Queries.GetVideoByUploaderAndHash(ctx, sqlc.GetVideoByUploaderAndHashParams{
+		UploadedBy:    userID,
+		ContentSha256: &hash,
+	})
+	if err == nil {
+		result.Duplicate = true
+		result.DuplicateShortID = &existing.ShortID
+		result.Reasons = append(result.Reasons, "You have already uploaded this file")
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("Error checking for duplicate video: %v", err)
+		response.InternalServerError(w, "Failed to check for duplicates")
+		return
+	}
+
+	// Check extension
+	result.ExtensionAccepted = h.config.IsAcceptedVideoFormat(filepath.Ext(req.Filename))
+	if !result.ExtensionAccepted {
+		result.Reasons = append(result.Reasons, fmt.Sprintf("Invalid file type. Accepted formats: %s", h.config.AcceptedFormatsString()))
+	}
+
+	// Check size
+	maxFileSize, _ := h.getDBConfig(ctx)
+	result.SizeAccepted = req.SizeBytes <= maxFileSize
+	if !result.SizeAccepted {
+		result.Reasons = append(result.Reasons, fmt.Sprintf("File too large. Maximum size: %.2f GB", float64(maxFileSize)/(1024*1024*1024)))
+	}
+
+	// Check quota
+	canUpload, reason := h.checkUserQuota(ctx, userID, req.SizeBytes)
+	result.QuotaAvailable = canUpload
+	if !canUpload {
+		result.Reasons = append(result.Reasons, reason)
+	}
+
+	result.CanUpload = !result.Duplicate && result.ExtensionAccepted && result.SizeAccepted && result.QuotaAvailable
+
+	response.OK(w, result)
+}
+
+// InitChunkedUpload handles POST /api/videos/upload/init
+func (h *VideosHandler) InitChunkedUpload(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get current user
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+
+	// Parse request
+	var req ChunkUploadInitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate filename
+	if req.Filename == "" {
+		response.BadRequest(w, "Filename is required")
+		return
+	}
+
+	ext := filepath.Ext(req.Filename)
+	if !h.config.IsAcceptedVideoFormat(ext) {
+		response.BadRequest(w, fmt.Sprintf("Invalid file type. Accepted formats: %s", h.config.AcceptedFormatsString()))
+		return
+	}
+
+	// Validate expected size
+	if req.ExpectedSize <= 0 {
+		response.BadRequest(w, "Expected size must be positive")
+		return
+	}
+
+	// Get DB config
+	maxFileSize, _ := h.getDBConfig(ctx)
+
+	// Pre-check file size
+	if req.ExpectedSize > maxFileSize {
+		response.BadRequest(w, fmt.Sprintf("File too large. Maximum size: %.2f GB", float64(maxFileSize)/(1024*1024*1024)))
+		return
+	}
+
+	// Pre-check user quota
+	canUpload, reason := h.checkUserQuota(ctx, userID, req.ExpectedSize)
+	if !canUpload {
+		response.Forbidden(w, reason)
+		return
+	}
+
+	// The chunks, then the assembled file, each need room for the whole video
+	if !h.checkFreeSpace(w, h.config.ChunksStoragePath, req.ExpectedSize) ||
+		!h.checkFreeSpace(w, h.config.TempStoragePath, req.ExpectedSize) {
+		return
+	}
+
+	// Initialize upload session
+	uploadID, err := h.chunkManager.InitSession(userID.String(), req.Filename, req.ExpectedSize)
+	if err != nil {
+		log.Printf("Error initializing upload session: %v", err)
+		response.InternalServerError(w, "Failed to initialize upload")
+		return
+	}
+
+	log.Printf("Initialized chunked upload session %s for user %s", uploadID, userID)
+
+	response.OK(w, ChunkUploadInitResponse{UploadID: uploadID})
+}
 
 // UploadChunk handles POST /api/videos/upload/chunk
 func (h *VideosHandler) UploadChunk(w http.ResponseWriter, r *http.Request) {
 	// Get current user
-	_, ok := middleware.GetUserID(r.Context())
+	userID, ok := middleware.GetUserID(r.Context())
 	if !ok {
 		response.Unauthorized(w, "Not authenticated")
 		return
@@ -612,23 +1210,54 @@ func (h *VideosHandler) UploadChunk(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check session exists
-	if !h.chunkManager.SessionExists(uploadID) {
-		response.NotFound(w, "Upload session not found")
+	// Check session exists, is the user's and hasn't expired
+	if !h.checkUploadSession(w, uploadID, userID) {
 		return
 	}
 
 	// Get file from form
-	file, _, err := r.FormFile("file")
+	file, header, err := r.FormFile("file")
 	if err != nil {
 		response.BadRequest(w, "No file provided")
 		return
 	}
 	defer file.Close()
 
+	if header.Size == 0 {
+		response.BadRequest(w, "Chunk is empty")
+		return
+	}
+
+	// The first chunk carries the container header, so sniff it before saving
+	var chunkReader io.Reader = file
+	if chunkIndex == 0 {
+		chunkReader, err = storage.SniffVideoReader(file)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotVideo) || errors.Is(err, storage.ErrEmptyFile) {
+				response.BadRequest(w, "File does not appear to be a valid video")
+				return
+			}
+			log.Printf("Error reading chunk: %v", err)
+			response.InternalServerError(w, "Failed to save chunk")
+			return
+		}
+	}
+
+	// Optional checksum (header takes precedence over form field)
+	checksum := r.Header.Get("X-Chunk-SHA256")
+	if checksum == "" {
+		checksum = r.FormValue("checksum")
+	}
+
 	// Save chunk
-	_, err = h.chunkManager.SaveChunkFromReader(uploadID, chunkIndex, file)
+	_, err = h.chunkManager.SaveChunkFromReader(uploadID, chunkIndex, chunkReader, checksum)
 	if err != nil {
+		if errors.Is(err, upload.ErrChecksumMismatch) {
+			response.ErrorWithDetails(w, http.StatusUnprocessableEntity, "Chunk checksum mismatch", map[string]interface{}{
+				"chunk_index": chunkIndex,
+			})
+			return
+		}
 		log.Printf("Error saving chunk: %v", err)
 		response.InternalServerError(w, "Failed to save chunk")
 		return
@@ -637,6 +1266,43 @@ func (h *VideosHandler) UploadChunk(w http.ResponseWriter, r *http.Request) {
 	response.NoContent(w)
 }
 
+// AbortChunkedUpload handles DELETE /api/videos/upload/{upload_id}
+func (h *VideosHandler) AbortChunkedUpload(w http.ResponseWriter, r *http.Request) {
+	// Get current user
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+
+	uploadID := r.PathValue("upload_id")
+	if uploadID == "" {
+		response.BadRequest(w, "Upload ID is required")
+		return
+	}
+
+	// Aborting a session that no longer exists is a no-op, and expired
+	// sessions can still be aborted
+	switch err := h.chunkManager.CheckSession(uploadID, userID.String()); {
+	case errors.Is(err, upload.ErrSessionNotFound):
+		response.NoContent(w)
+		return
+	case err != nil && !errors.Is(err, upload.ErrSessionExpired):
+		writeUploadSessionError(w, err)
+		return
+	}
+
+	if err := h.chunkManager.CleanupSession(uploadID); err != nil {
+		log.Printf("Error aborting upload session: %v", err)
+		response.InternalServerError(w, "Failed to abort upload")
+		return
+	}
+
+	log.Printf("Aborted chunked upload session %s for user %s", uploadID, userID)
+
+	response.NoContent(w)
+}
+
 // CompleteChunkedUpload handles POST /api/videos/upload/complete
 func (h *VideosHandler) CompleteChunkedUpload(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -673,9 +1339,8 @@ func (h *VideosHandler) CompleteChunkedUpload(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// Check session exists
-	if !h.chunkManager.SessionExists(req.UploadID) {
-		response.NotFound(w, "Upload session not found")
+	// Check session exists, is the user's and hasn't expired
+	if !h.checkUploadSession(w, req.UploadID, userID) {
 		return
 	}
 
@@ -683,8 +1348,8 @@ func (h *VideosHandler) CompleteChunkedUpload(w http.ResponseWriter, r *http.Req
 	uniqueFilename := storage.GenerateUniqueFilename(req.Filename)
 	tempPath := h.storage.TempPath(uniqueFilename)
 
-	// Merge chunks
-	totalSize, err := h.chunkManager.MergeChunks(req.UploadID, tempPath)
+	// Merge chunks, hashing the file for duplicate detection
+	totalSize, contentHash, err := h.chunkManager.MergeChunks(req.UploadID, tempPath)
 	if err != nil {
 		log.Printf("Error merging chunks: %v", err)
 		h.chunkManager.CleanupSession(req.UploadID)
@@ -692,6 +1357,23 @@ func (h *VideosHandler) CompleteChunkedUpload(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// Reject empty uploads
+	if totalSize == 0 {
+		h.storage.DeleteFile(tempPath)
+		h.chunkManager.CleanupSession(req.UploadID)
+		response.BadRequest(w, "File is empty")
+		return
+	}
+
+	// Verify whole-file checksum if provided
+	if req.Checksum != nil && *req.Checksum != "" {
+		if !strings.EqualFold(contentHash, strings.TrimSpace(*req.Checksum)) {
+			h.storage.DeleteFile(tempPath)
+			response.UnprocessableEntity(w, "File checksum mismatch")
+			return
+		}
+	}
+
 	// Get DB config
 	maxFileSize, _ := h.getDBConfig(ctx)
 
@@ -757,8 +1439,8 @@ func (h *VideosHandler) CompleteChunkedUpload(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// Create video record
-	video, err := h.db.Queries.CreateVideo(ctx, sqlc.CreateVideoParams{
+	// Create video record and charge quota
+	video, err := h.createVideoRecord(ctx, sqlc.CreateVideoParams{
 		ShortID:          shortID,
 		Title:            strings.TrimSpace(req.Title),
 		Description:      req.Description,
@@ -768,6 +1450,7 @@ func (h *VideosHandler) CompleteChunkedUpload(w http.ResponseWriter, r *http.Req
 		UploadedBy:       userID,
 		CategoryID:       categoryID,
 		StoragePath:      nil,
+		ContentSha256:    &contentHash,
 	})
 	if err != nil {
 		h.storage.DeleteFile(tempPath)
@@ -777,19 +1460,12 @@ func (h *VideosHandler) CompleteChunkedUpload(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// Increment user quota
-	if err := h.db.Queries.UpdateUploadQuota(ctx, sqlc.UpdateUploadQuotaParams{
-		ID:                userID,
-		WeeklyUploadBytes: totalSize,
-	}); err != nil {
-		log.Printf("Warning: failed to update user quota: %v", err)
-	}
-
 	// Cleanup session
 	h.chunkManager.CleanupSession(req.UploadID)
 
 	// Trigger background processing
 	h.triggerProcessing(ctx, video.ID)
+	h.notifyUploaded(ctx, video.ID)
 
 	log.Printf("Chunked upload completed: %s by user %s", video.ID, userID)
 
@@ -976,7 +1652,12 @@ func (h *VideosHandler) GetByShortID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response.OK(w, buildVideoResponseFromShortIDRow(videoWithUploader))
+	resp := buildVideoResponseFromShortIDRow(videoWithUploader)
+	if isAdmin {
+		resp.SourceSha256 = videoWithUploader.ContentSha256
+		resp.OutputSha256 = videoWithUploader.OutputSha256
+	}
+	response.OK(w, resp)
 }
 
 // Update handles PATCH /api/videos/{short_id}
@@ -1080,12 +1761,18 @@ func (h *VideosHandler) Update(w http.ResponseWriter, r *http.Request) {
 		categoryID = video.CategoryID
 	}
 
+	commentsEnabled := video.CommentsEnabled
+	if req.CommentsEnabled != nil {
+		commentsEnabled = *req.CommentsEnabled
+	}
+
 	// Update video
 	updatedVideo, err := h.db.Queries.UpdateVideo(ctx, sqlc.UpdateVideoParams{
-		ID:          video.ID,
-		Column2:     title, // Empty string means keep existing
-		Description: description,
-		CategoryID:  categoryID,
+		ID:              video.ID,
+		Column2:         title, // Empty string means keep existing
+		Description:     description,
+		CategoryID:      categoryID,
+		CommentsEnabled: commentsEnabled,
 	})
 	if err != nil {
 		log.Printf("Error updating video: %v", err)
@@ -1125,7 +1812,8 @@ func (h *VideosHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	isAdmin := middleware.IsAdmin(ctx)
+	// Moderators can take down any video
+	isModerator := middleware.IsModerator(ctx)
 
 	// Get video
 	video, err := h.db.Queries.GetVideoByShortID(ctx, shortID)
@@ -1140,16 +1828,23 @@ func (h *VideosHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check permission
-	if !isVideoOwnerOrAdmin(video, userID, isAdmin) {
+	if !isVideoOwnerOrAdmin(video, userID, isModerator) {
 		response.Forbidden(w, "You don't have permission to delete this video")
 		return
 	}
 
 	// Delete video files
-	if err := h.storage.DeleteVideoFiles(video.Filename, video.ThumbnailFilename, nil); err != nil {
+	if err := h.storage.DeleteVideoFiles(video.Filename, video.ThumbnailFilename, video.StoragePath); err != nil {
 		log.Printf("Warning: failed to delete video files: %v", err)
 	}
 
+	// Failed videos keep their upload so they can be retried
+	if video.ProcessingStatus == domain.ProcessingStatusFailed {
+		if err := h.storage.DeleteFile(h.storage.TempPath(video.Filename)); err != nil {
+			log.Printf("Warning: failed to delete upload: %v", err)
+		}
+	}
+
 	// Delete video record
 	if err := h.db.Queries.DeleteVideo(ctx, video.ID); err != nil {
 		log.Printf("Error deleting video: %v", err)
@@ -1159,6 +1854,19 @@ func (h *VideosHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("Deleted video %s by user %s", video.ID, userID)
 
+	if video.UploadedBy != userID {
+		recordAudit(ctx, h.db.Queries, auditEntry{
+			Action:     auditVideoDelete,
+			TargetType: auditTargetVideo,
+			TargetID:   video.ID,
+			Details: map[string]interface{}{
+				"short_id":    video.ShortID,
+				"title":       video.Title,
+				"uploader_id": video.UploadedBy.String(),
+			},
+		})
+	}
+
 	response.NoContent(w)
 }
 
@@ -1174,7 +1882,7 @@ func (h *VideosHandler) GetMyQuota(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get DB config
-	maxFileSize, weeklyLimit := h.getDBConfig(ctx)
+	maxFileSize, globalLimit := h.getDBConfig(ctx)
 
 	// Get user quota
 	quota, err := h.db.Queries.GetUserQuota(ctx, userID)
@@ -1184,6 +1892,8 @@ func (h *VideosHandler) GetMyQuota(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	weeklyLimit, overridden := effectiveWeeklyLimit(globalLimit, quota.WeeklyUploadLimitOverrideBytes)
+
 	used := quota.WeeklyUploadBytes
 	remaining := weeklyLimit - used
 	if remaining < 0 {
@@ -1202,6 +1912,81 @@ func (h *VideosHandler) GetMyQuota(w http.ResponseWriter, r *http.Request) {
 		PercentageUsed:   percentage,
 		CanUpload:        used < weeklyLimit,
 		MaxFileSizeBytes: maxFileSize,
+		LimitOverridden:  overridden,
+	})
+}
+
+// GetMyQuotaHistory handles GET /api/videos/quota/me/history
+func (h *VideosHandler) GetMyQuotaHistory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+
+	serveQuotaHistory(w, r, h.db.Queries, userID)
+}
+
+// serveQuotaHistory writes a page of a user's quota events, newest first
+func serveQuotaHistory(w http.ResponseWriter, r *http.Request, queries *sqlc.Queries, userID uuid.UUID) {
+	ctx := r.Context()
+
+	skip := 0
+	limit := 50
+
+	if s := r.URL.Query().Get("skip"); s != "" {
+		if val, err := strconv.Atoi(s); err == nil && val >= 0 {
+			skip = val
+		}
+	}
+
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if val, err := strconv.Atoi(l); err == nil && val >= 1 && val <= 200 {
+			limit = val
+		}
+	}
+
+	events, err := queries.ListQuotaEventsByUser(ctx, sqlc.ListQuotaEventsByUserParams{
+		UserID: userID,
+		Limit:  int32(limit),
+		Offset: int32(skip),
+	})
+	if err != nil {
+		log.Printf("Error listing quota events for user %s: %v", userID, err)
+		response.InternalServerError(w, "Failed to get quota history")
+		return
+	}
+
+	total, err := queries.CountQuotaEventsByUser(ctx, userID)
+	if err != nil {
+		log.Printf("Error counting quota events for user %s: %v", userID, err)
+		response.InternalServerError(w, "Failed to get quota history")
+		return
+	}
+
+	result := make([]QuotaEventResponse, len(events))
+	for i, e := range events {
+		var videoID *string
+		if e.VideoID.Valid {
+			id := uuid.UUID(e.VideoID.Bytes).String()
+			videoID = &id
+		}
+		result[i] = QuotaEventResponse{
+			ID:             e.ID.String(),
+			EventType:      e.EventType,
+			BytesDelta:     e.BytesDelta,
+			UsedBytesAfter: e.UsedBytesAfter,
+			VideoID:        videoID,
+			VideoShortID:   e.VideoShortID,
+			VideoTitle:     e.VideoTitle,
+			ActorUsername:  e.ActorUsername,
+			CreatedAt:      e.CreatedAt,
+		}
+	}
+
+	response.OK(w, QuotaHistoryResponse{
+		Events: result,
+		Total:  total,
 	})
 }
 
@@ -1219,8 +2004,19 @@ func (h *VideosHandler) ResetAllQuotas(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Reset all quotas
-	if err := h.db.Queries.ResetAllUploadQuotas(ctx); err != nil {
+	adminID, _ := middleware.GetUserID(ctx)
+
+	// Reset all quotas, recording what each user had used
+	err = h.db.InTx(ctx, func(q *sqlc.Queries) error {
+		if err := q.CreateQuotaResetEvents(ctx, sqlc.CreateQuotaResetEventsParams{
+			EventType: quotaEventAdminReset,
+			ActorID:   pgtype.UUID{Bytes: adminID, Valid: true},
+		}); err != nil {
+			return err
+		}
+		return q.ResetAllUploadQuotas(ctx)
+	})
+	if err != nil {
 		log.Printf("Error resetting quotas: %v", err)
 		response.InternalServerError(w, "Failed to reset quotas")
 		return
@@ -1228,26 +2024,377 @@ func (h *VideosHandler) ResetAllQuotas(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("Reset quotas for %d users", count)
 
+	recordAudit(ctx, h.db.Queries, auditEntry{
+		Action:     auditQuotaResetAll,
+		TargetType: auditTargetUser,
+		Details: map[string]interface{}{
+			"reset_count": count,
+		},
+	})
+
 	response.OK(w, QuotaResetResponse{
 		ResetCount: int(count),
 		Message:    fmt.Sprintf("Successfully reset quotas for %d users", count),
 	})
 }
 
-// --- Streaming Handlers (Phase 7) ---
-
-// Thumbnail handles GET /api/videos/{short_id}/thumbnail
-func (h *VideosHandler) Thumbnail(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+// ListUploadSessions handles GET /api/videos/admin/upload-sessions
+func (h *VideosHandler) ListUploadSessions(w http.ResponseWriter, r *http.Request) {
+	// This endpoint requires admin (enforced by router middleware)
 
-	shortID := r.PathValue("short_id")
-	if shortID == "" {
-		response.BadRequest(w, "Video ID is required")
+	sessions, err := h.chunkManager.ListSessions()
+	if err != nil {
+		log.Printf("Error listing upload sessions: %v", err)
+		response.InternalServerError(w, "Failed to list upload sessions")
 		return
 	}
 
-	// Get current user for access control
-	userID, ok := middleware.GetUserID(ctx)
+	result := make([]UploadSessionResponse, len(sessions))
+	for i, s := range sessions {
+		var userID, filename *string
+		if s.Meta.UserID != "" {
+			userID = &s.Meta.UserID
+		}
+		if s.Meta.Filename != "" {
+			filename = &s.Meta.Filename
+		}
+
+		result[i] = UploadSessionResponse{
+			UploadID:       s.UploadID,
+			UserID:         userID,
+			Filename:       filename,
+			ExpectedSize:   s.Meta.ExpectedSize,
+			ChunkCount:     s.ChunkCount,
+			SizeBytes:      s.SizeBytes,
+			CreatedAt:      s.Meta.CreatedAt,
+			LastActivityAt: s.Meta.LastActivityAt,
+			AgeSeconds:     int64(s.Age.Seconds()),
+			Expired:        s.Expired,
+		}
+	}
+
+	response.OK(w, result)
+}
+
+// listFailedVideos returns the failed videos and whether each one's upload still exists
+func (h *VideosHandler) listFailedVideos(ctx context.Context) ([]sqlc.ListFailedVideosRow, []FailedVideoResponse, error) {
+	videos, err := h.db.Queries.ListFailedVideos(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := make([]FailedVideoResponse, len(videos))
+	for i, v := range videos {
+		result[i] = FailedVideoResponse{
+			ID:               v.ID.String(),
+			ShortID:          v.ShortID,
+			Title:            v.Title,
+			UploadedBy:       v.UploadedBy.String(),
+			UploaderUsername: v.UploaderUsername,
+			ErrorMessage:     v.ErrorMessage,
+			Attempts:         v.Attempts,
+			SourceExists:     storage.FileExists(h.storage.TempPath(v.Filename)),
+			CreatedAt:        v.CreatedAt,
+			LastFailedAt:     v.LastFailedAt,
+		}
+	}
+	return videos, result, nil
+}
+
+// ListFailed handles GET /api/admin/videos/failed (admin only)
+// Lists failed videos, most recently failed first, with the full error message.
+func (h *VideosHandler) ListFailed(w http.ResponseWriter, r *http.Request) {
+	_, result, err := h.listFailedVideos(r.Context())
+	if err != nil {
+		log.Printf("Error listing failed videos: %v", err)
+		response.InternalServerError(w, "Failed to list failed videos")
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// TranscodeLog handles GET /api/videos/{short_id}/transcode-log (admin only)
+// Returns the video's stored processing logs as plain text, newest job first:
+// each FFmpeg command the job ran, with its stderr and exit status.
+func (h *VideosHandler) TranscodeLog(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	shortID := r.PathValue("short_id")
+	if shortID == "" {
+		response.BadRequest(w, "Video ID is required")
+		return
+	}
+
+	video, err := h.db.Queries.GetVideoByShortID(ctx, shortID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.NotFound(w, "Video not found")
+			return
+		}
+		log.Printf("Error getting video: %v", err)
+		response.InternalServerError(w, "Failed to get video")
+		return
+	}
+
+	logs, err := h.db.Queries.ListTranscodeLogsByVideo(ctx, video.ID)
+	if err != nil {
+		log.Printf("Error listing transcode logs: %v", err)
+		response.InternalServerError(w, "Failed to get transcode logs")
+		return
+	}
+	if len(logs) == 0 {
+		response.NotFound(w, "No transcode logs for this video")
+		return
+	}
+
+	var b strings.Builder
+	for _, l := range logs {
+		status := "succeeded"
+		if !l.Success {
+			status = "failed"
+		}
+		fmt.Fprintf(&b, "=== %s job at %s, %s ===\n", l.JobKind, l.CreatedAt.UTC().Format(time.RFC3339), status)
+		b.WriteString(l.Output)
+		b.WriteString("\n")
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(b.String()))
+}
+
+// Checksum check results
+const (
+	ChecksumMatch       = "match"
+	ChecksumMismatch    = "mismatch"
+	ChecksumMissing     = "missing"     // The file is gone
+	ChecksumUnavailable = "unavailable" // Nothing to compare, e.g. the upload is removed after processing
+	ChecksumRecorded    = "recorded"    // No checksum was stored; the computed one now is
+)
+
+// ChecksumCheckResponse compares a stored checksum with a recomputed one
+type ChecksumCheckResponse struct {
+	Status   string  `json:"status"`
+	Stored   *string `json:"stored"`
+	Computed *string `json:"computed"`
+	Error    *string `json:"error,omitempty"`
+}
+
+// VideoVerifyResponse is the result of re-hashing a video's files
+type VideoVerifyResponse struct {
+	VideoID string                `json:"video_id"`
+	OK      bool                  `json:"ok"` // Nothing mismatched or missing
+	Source  ChecksumCheckResponse `json:"source"`
+	Output  ChecksumCheckResponse `json:"output"`
+}
+
+// compareChecksum recomputes a checksum with compute and compares it with stored
+func compareChecksum(stored *string, compute func() (string, error)) ChecksumCheckResponse {
+	check := ChecksumCheckResponse{Stored: stored}
+	sum, err := compute()
+	if err != nil {
+		errMsg := err.Error()
+		check.Status = ChecksumMissing
+		check.Error = &errMsg
+		return check
+	}
+
+	check.Computed = &sum
+	switch {
+	case stored == nil:
+		check.Status = ChecksumRecorded
+	case *stored == sum:
+		check.Status = ChecksumMatch
+	default:
+		check.Status = ChecksumMismatch
+	}
+	return check
+}
+
+// Verify handles POST /api/videos/{short_id}/verify (admin only)
+// Re-hashes the video's upload (while it's still in temp storage) and its
+// processed output, and compares them with the stored checksums. An output
+// without a stored checksum has the computed one recorded. Large videos take
+// as long to verify as to read from disk.
+func (h *VideosHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	shortID := r.PathValue("short_id")
+	if shortID == "" {
+		response.BadRequest(w, "Video ID is required")
+		return
+	}
+
+	video, err := h.db.Queries.GetVideoByShortID(ctx, shortID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.NotFound(w, "Video not found")
+			return
+		}
+		log.Printf("Error getting video: %v", err)
+		response.InternalServerError(w, "Failed to get video")
+		return
+	}
+
+	result := VideoVerifyResponse{
+		VideoID: video.ID.String(),
+		Source:  ChecksumCheckResponse{Status: ChecksumUnavailable, Stored: video.ContentSha256},
+		Output:  ChecksumCheckResponse{Status: ChecksumUnavailable, Stored: video.OutputSha256},
+	}
+
+	if video.ProcessingStatus != domain.ProcessingStatusCompleted {
+		tempPath := h.storage.TempPath(video.Filename)
+		if storage.FileExists(tempPath) && video.ContentSha256 != nil {
+			result.Source = compareChecksum(video.ContentSha256, func() (string, error) {
+				return storage.ComputeSHA256(tempPath)
+			})
+		}
+	} else {
+		base := h.storage.Config().VideoPath
+		if video.StoragePath != nil && *video.StoragePath != "" {
+			base = *video.StoragePath
+		}
+		result.Output = compareChecksum(video.OutputSha256, func() (string, error) {
+			return storage.ComputeOutputSHA256(base, video.Filename)
+		})
+
+		if result.Output.Status == ChecksumRecorded {
+			if err := h.db.Queries.UpdateVideoOutputSha256(ctx, sqlc.UpdateVideoOutputSha256Params{
+				ID:           video.ID,
+				OutputSha256: result.Output.Computed,
+			}); err != nil {
+				log.Printf("Error storing output checksum: %v", err)
+				response.InternalServerError(w, "Failed to store output checksum")
+				return
+			}
+		}
+	}
+
+	result.OK = result.Source.Status != ChecksumMismatch && result.Source.Status != ChecksumMissing &&
+		result.Output.Status != ChecksumMismatch && result.Output.Status != ChecksumMissing
+	if !result.OK {
+		log.Printf("Checksum verification failed for video %s: source %s, output %s", video.ID, result.Source.Status, result.Output.Status)
+	}
+
+	response.OK(w, result)
+}
+
+// RetryAllFailed handles POST /api/admin/videos/failed/retry-all (admin only)
+// Re-enqueues every failed video whose upload still exists.
+func (h *VideosHandler) RetryAllFailed(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	videos, failed, err := h.listFailedVideos(ctx)
+	if err != nil {
+		log.Printf("Error listing failed videos: %v", err)
+		response.InternalServerError(w, "Failed to retry failed videos")
+		return
+	}
+
+	result := FailedVideosRetryResponse{}
+	for i, v := range videos {
+		if !failed[i].SourceExists {
+			result.Skipped++
+			continue
+		}
+
+		requeued, err := h.db.Queries.RequeueFailedVideo(ctx, v.ID)
+		if err != nil {
+			log.Printf("Error requeueing video %s: %v", v.ID, err)
+			response.InternalServerError(w, "Failed to retry failed videos")
+			return
+		}
+		if requeued == 0 {
+			// Retried or deleted since it was listed
+			continue
+		}
+
+		h.triggerProcessing(ctx, v.ID)
+		result.Retried++
+	}
+
+	log.Printf("Retried %d failed videos (%d skipped without upload)", result.Retried, result.Skipped)
+
+	recordAudit(ctx, h.db.Queries, auditEntry{
+		Action:     auditVideoRetryFailed,
+		TargetType: auditTargetVideo,
+		Details: map[string]interface{}{
+			"retried": result.Retried,
+			"skipped": result.Skipped,
+		},
+	})
+
+	response.OK(w, result)
+}
+
+// PurgeFailed handles POST /api/admin/videos/failed/purge (admin only)
+// Deletes the failed videos whose upload no longer exists, files included,
+// since they can't be retried. With ?dry_run=true nothing is deleted and the
+// response lists what would be.
+func (h *VideosHandler) PurgeFailed(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
+	videos, failed, err := h.listFailedVideos(ctx)
+	if err != nil {
+		log.Printf("Error listing failed videos: %v", err)
+		response.InternalServerError(w, "Failed to purge failed videos")
+		return
+	}
+
+	result := FailedVideosPurgeResponse{
+		DryRun: dryRun,
+		Purged: []FailedVideoResponse{},
+	}
+	for i, v := range videos {
+		if failed[i].SourceExists {
+			continue
+		}
+
+		if !dryRun {
+			if err := h.storage.DeleteVideoFiles(v.Filename, v.ThumbnailFilename, v.StoragePath); err != nil {
+				log.Printf("Warning: failed to delete video files: %v", err)
+			}
+			if err := h.db.Queries.DeleteVideo(ctx, v.ID); err != nil {
+				log.Printf("Error deleting video %s: %v", v.ID, err)
+				response.InternalServerError(w, "Failed to purge failed videos")
+				return
+			}
+		}
+		result.Purged = append(result.Purged, failed[i])
+	}
+
+	if !dryRun {
+		log.Printf("Purged %d failed videos", len(result.Purged))
+
+		recordAudit(ctx, h.db.Queries, auditEntry{
+			Action:     auditVideoPurgeFailed,
+			TargetType: auditTargetVideo,
+			Details: map[string]interface{}{
+				"purged": len(result.Purged),
+			},
+		})
+	}
+
+	response.OK(w, result)
+}
+
+// --- Streaming Handlers (Phase 7) ---
+
+// Thumbnail handles GET /api/videos/{short_id}/thumbnail
+func (h *VideosHandler) Thumbnail(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	shortID := r.PathValue("short_id")
+	if shortID == "" {
+		response.BadRequest(w, "Video ID is required")
+		return
+	}
+
+	// Get current user for access control
+	userID, ok := middleware.GetUserID(ctx)
 	if !ok {
 		response.Unauthorized(w, "Not authenticated")
 		return
@@ -1278,36 +2425,41 @@ func (h *VideosHandler) Thumbnail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Serve the WebP variant to browsers that accept it; videos processed
+	// before WebP variants only have the JPEG
 	thumbnailPath := h.storage.ThumbnailPath(*video.ThumbnailFilename)
-	if !storage.FileExists(thumbnailPath) {
-		response.NotFound(w, "Thumbnail file not found")
-		return
+	if acceptsWebP(r) {
+		if webpPath := h.storage.WebPThumbnailPath(*video.ThumbnailFilename); storage.FileExists(webpPath) {
+			thumbnailPath = webpPath
+		}
 	}
 
-	// Open thumbnail file
-	file, err := os.Open(thumbnailPath)
-	if err != nil {
-		log.Printf("Error opening thumbnail: %v", err)
-		response.InternalServerError(w, "Failed to read thumbnail")
-		return
-	}
-	defer file.Close()
+	// Access is checked per user, so only the browser may cache it. The ETag
+	// lets it revalidate cheaply once the cached copy goes stale.
+	w.Header().Set("Vary", "Accept")
+	serveImageFile(w, r, thumbnailPath, "thumbnail", "private, max-age=86400") // 24 hours
+}
 
-	// Get file info for content-length
-	stat, err := file.Stat()
-	if err != nil {
-		log.Printf("Error getting thumbnail stat: %v", err)
-		response.InternalServerError(w, "Failed to read thumbnail")
-		return
+// acceptsWebP reports whether the request's Accept header lists image/webp
+// without refusing it with q=0
+func acceptsWebP(r *http.Request) bool {
+	for _, value := range r.Header.Values("Accept") {
+		for _, part := range strings.Split(value, ",") {
+			mediaType, params, _ := strings.Cut(part, ";")
+			if !strings.EqualFold(strings.TrimSpace(mediaType), "image/webp") {
+				continue
+			}
+			for _, param := range strings.Split(params, ";") {
+				name, q, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if strings.EqualFold(name, "q") {
+					weight, err := strconv.ParseFloat(q, 64)
+					return err == nil && weight > 0
+				}
+			}
+			return true
+		}
 	}
-
-	// Set headers
-	w.Header().Set("Content-Type", "image/jpeg")
-	w.Header().Set("Content-Length", strconv.FormatInt(stat.Size(), 10))
-	w.Header().Set("Cache-Control", "public, max-age=86400") // 24 hours
-
-	// Stream the file
-	io.Copy(w, file)
+	return false
 }
 
 // IncrementView handles POST /api/videos/{short_id}/view
@@ -1398,23 +2550,34 @@ func (h *VideosHandler) StreamInfo(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check for HLS availability first (preferred format)
-	if h.storage.IsHLSAvailable(video.Filename, nil) {
+	if h.storage.IsHLSAvailable(video.Filename, video.StoragePath) {
 		manifestURL := fmt.Sprintf("/api/videos/%s/hls/master.m3u8", shortID)
-		response.OK(w, StreamInfoResponse{
+		info := StreamInfoResponse{
 			Format:      "hls",
 			ManifestURL: &manifestURL,
 			Ready:       true,
-		})
+			AudioTracks: video.AudioTrackCount,
+			VideoCodec:  video.VideoCodec,
+		}
+		// Videos with MPEG-TS segments have no DASH manifest
+		if h.storage.IsDASHAvailable(video.Filename, video.StoragePath) {
+			dashURL := fmt.Sprintf("/api/videos/%s/dash/%s", shortID, storage.DASHManifestName)
+			info.DASHManifestURL = &dashURL
+		}
+		response.OK(w, info)
 		return
 	}
 
 	// Check for progressive MP4
-	if h.storage.IsProgressiveAvailable(video.Filename, nil) {
+	if h.storage.IsProgressiveAvailable(video.Filename, video.StoragePath) {
 		streamURL := fmt.Sprintf("/api/videos/%s/stream", shortID)
 		response.OK(w, StreamInfoResponse{
-			Format:    "progressive",
-			StreamURL: &streamURL,
-			Ready:     true,
+			Format:      "progressive",
+			StreamURL:   &streamURL,
+			Ready:       true,
+			AudioTracks: video.AudioTrackCount,
+			VideoCodec:  video.VideoCodec,
+			Qualities:   h.progressiveQualities(video),
 		})
 		return
 	}
@@ -1429,6 +2592,7 @@ func (h *VideosHandler) StreamInfo(w http.ResponseWriter, r *http.Request) {
 }
 
 // Stream handles GET /api/videos/{short_id}/stream (progressive streaming with Range support)
+// An optional quality query parameter, e.g. ?quality=720p, picks a lower quality file
 func (h *VideosHandler) Stream(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -1438,6 +2602,170 @@ func (h *VideosHandler) Stream(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	quality, ok := parseStreamQuality(w, r)
+	if !ok {
+		return
+	}
+
+	// Get current user for access control
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+	isAdmin := middleware.IsAdmin(ctx)
+
+	// Get video
+	video, err := h.db.Queries.GetVideoByShortID(ctx, shortID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.NotFound(w, "Video not found")
+			return
+		}
+		log.Printf("Error getting video: %v", err)
+		response.InternalServerError(w, "Failed to get video")
+		return
+	}
+
+	// Check access
+	if !hasVideoAccess(video, userID, isAdmin) {
+		response.Forbidden(w, "You don't have permission to view this video")
+		return
+	}
+
+	// Admins aren't throttled
+	var limits streamLimits
+	if !isAdmin {
+		limits = h.streamLimits(ctx, userID.String())
+	}
+
+	h.streamVideo(w, r, video, quality, limits)
+}
+
+// AllowSignedStream serves stream requests carrying a signed URL (see
+// StreamURL) without authentication and passes the rest on to next
+func (h *VideosHandler) AllowSignedStream(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		signature := query.Get("signature")
+		if signature == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		shortID := r.PathValue("short_id")
+		userID, userErr := uuid.Parse(query.Get("user"))
+		expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+		if userErr != nil || err != nil || !auth.ValidateStreamSignature(shortID, userID, signature, expires, h.config.HLSSigningSecrets()) {
+			response.Forbidden(w, "Invalid or expired stream URL")
+			return
+		}
+
+		quality, ok := parseStreamQuality(w, r)
+		if !ok {
+			return
+		}
+
+		video, err := h.db.Queries.GetVideoByShortID(r.Context(), shortID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				response.NotFound(w, "Video not found")
+				return
+			}
+			log.Printf("Error getting video: %v", err)
+			response.InternalServerError(w, "Failed to get video")
+			return
+		}
+
+		// The URL streams as the user it was issued to, while they're still active
+		user, err := h.db.Queries.GetUserByID(r.Context(), userID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				response.Forbidden(w, "Invalid or expired stream URL")
+				return
+			}
+			log.Printf("Error getting user: %v", err)
+			response.InternalServerError(w, "Failed to get video")
+			return
+		}
+		if !user.IsActive {
+			response.Forbidden(w, "Invalid or expired stream URL")
+			return
+		}
+		isAdmin := user.Role == domain.UserRoleAdmin
+		if !hasVideoAccess(video, user.ID, isAdmin) {
+			response.Forbidden(w, "You don't have permission to view this video")
+			return
+		}
+
+		// Admins aren't throttled
+		var limits streamLimits
+		if !isAdmin {
+			limits = h.streamLimits(r.Context(), user.ID.String())
+		}
+
+		h.streamVideo(w, r, video, quality, limits)
+	})
+}
+
+// streamQualitySource names a video's main progressive file, served when no
+// quality is asked for or the video has no file at the quality asked for
+const streamQualitySource = "source"
+
+// parseStreamQuality reads the optional quality query parameter of a stream
+// request, responding with 400 and the valid options if it's unknown
+func parseStreamQuality(w http.ResponseWriter, r *http.Request) (string, bool) {
+	quality := r.URL.Query().Get("quality")
+	if quality == "" || quality == streamQualitySource || slices.Contains(video.RenditionResolutions, quality) {
+		return quality, true
+	}
+	options := append([]string{streamQualitySource}, video.RenditionResolutions...)
+	response.BadRequest(w, "Invalid quality. Valid options: "+strings.Join(options, ", "))
+	return "", false
+}
+
+// progressiveQualities lists the lower qualities a progressive video has
+// files for, smallest first
+func (h *VideosHandler) progressiveQualities(v sqlc.Video) []string {
+	return h.storage.ProgressiveQualities(v.Filename, video.RenditionResolutions, v.StoragePath)
+}
+
+// streamQualityPath returns the path of a video's progressive file at
+// quality, and the quality it actually is: the main file's is
+// streamQualitySource
+func (h *VideosHandler) streamQualityPath(video sqlc.Video, quality string) (string, string) {
+	if quality != "" && quality != streamQualitySource {
+		if path := h.storage.GetProgressiveQualityPath(video.Filename, quality, video.StoragePath); storage.FileExists(path) {
+			return path, quality
+		}
+	}
+	return h.storage.GetProgressiveVideoPath(video.Filename, video.StoragePath), streamQualitySource
+}
+
+// streamLimits returns the bandwidth limits for a stream by the user keyed
+// by key ("" if unknown). If the DB config can't be read, streams aren't
+// throttled rather than failing.
+func (h *VideosHandler) streamLimits(ctx context.Context, key string) streamLimits {
+	limits, err := loadStreamLimits(ctx, h.db.Queries)
+	if err != nil {
+		log.Printf("Error getting stream limits: %v", err)
+		return streamLimits{}
+	}
+	limits.Key = key
+	return limits
+}
+
+// StreamURL handles POST /api/videos/{short_id}/stream-url
+// Returns a time-limited signed stream URL for players that can't send a token
+func (h *VideosHandler) StreamURL(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	shortID := r.PathValue("short_id")
+	if shortID == "" {
+		response.BadRequest(w, "Video ID is required")
+		return
+	}
+
 	// Get current user for access control
 	userID, ok := middleware.GetUserID(ctx)
 	if !ok {
@@ -1464,8 +2792,82 @@ func (h *VideosHandler) Stream(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.storage.IsProgressiveAvailable(video.Filename, video.StoragePath) {
+		response.NotFound(w, "Progressive stream not available")
+		return
+	}
+
+	path, expiresAt := auth.SignStreamURL(video.ShortID, userID, h.config.HLSSigningSecret, h.config.StreamURLExpiry)
+
+	response.OK(w, StreamURLResponse{
+		URL:       h.config.FrontendBaseURL + path,
+		ExpiresAt: expiresAt,
+	})
+}
+
+// ProcessingProgress handles GET /api/videos/{short_id}/processing-progress
+// Only the owner or an admin can follow a video's processing.
+func (h *VideosHandler) ProcessingProgress(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	shortID := r.PathValue("short_id")
+	if shortID == "" {
+		response.BadRequest(w, "Video ID is required")
+		return
+	}
+
+	// Get current user
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+	isAdmin := middleware.IsAdmin(ctx)
+
+	// Get video
+	videoRecord, err := h.db.Queries.GetVideoByShortID(ctx, shortID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.NotFound(w, "Video not found")
+			return
+		}
+		log.Printf("Error getting video: %v", err)
+		response.InternalServerError(w, "Failed to get video")
+		return
+	}
+
+	// Check permission
+	if !isVideoOwnerOrAdmin(videoRecord, userID, isAdmin) {
+		response.Forbidden(w, "You don't have permission to view this video's processing")
+		return
+	}
+
+	result := ProcessingProgressResponse{
+		ProcessingStatus: string(videoRecord.ProcessingStatus),
+	}
+	if progress, ok := video.GetProcessingProgress(videoRecord.ID.String()); ok {
+		result.Phase = &progress.Phase
+		result.Percent = &progress.Percent
+		result.UpdatedAt = &progress.UpdatedAt
+	}
+
+	response.OK(w, result)
+}
+
+// streamVideo serves a video's progressive file at quality, falling back to
+// the main file, honouring Range requests, at no more than the given
+// bandwidth limits. X-Stream-Quality says which file was served.
+func (h *VideosHandler) streamVideo(w http.ResponseWriter, r *http.Request, video sqlc.Video, quality string, limits streamLimits) {
+	videoPath, servedQuality := h.streamQualityPath(video, quality)
+	w.Header().Set("X-Stream-Quality", servedQuality)
+
+	if h.config.StreamAccelRedirectPrefix != "" {
+		h.accelRedirectVideo(w, video, videoPath, limits)
+		return
+	}
+
 	// Open video file
-	file, fileSize, err := h.storage.OpenVideoFile(video.Filename, nil)
+	file, err := os.Open(videoPath)
 	if err != nil {
 		log.Printf("Error opening video file: %v", err)
 		response.NotFound(w, "Video file not found")
@@ -1473,6 +2875,14 @@ func (h *VideosHandler) Stream(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
+	stat, err := file.Stat()
+	if err != nil {
+		log.Printf("Error getting video file stat: %v", err)
+		response.InternalServerError(w, "Failed to stream video")
+		return
+	}
+	fileSize := stat.Size()
+
 	// Parse Range header
 	rangeHeader := r.Header.Get("Range")
 
@@ -1480,13 +2890,13 @@ func (h *VideosHandler) Stream(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "video/mp4")
 	w.Header().Set("Accept-Ranges", "bytes")
 	w.Header().Set("Content-Encoding", "identity")
-	w.Header().Set("Access-Control-Expose-Headers", "content-type, accept-ranges, content-length, content-range, content-encoding")
+	w.Header().Set("Access-Control-Expose-Headers", "content-type, accept-ranges, content-length, content-range, content-encoding, x-stream-quality")
 
 	if rangeHeader == "" {
 		// No Range header - send full file
 		w.Header().Set("Content-Length", strconv.FormatInt(fileSize, 10))
 		w.WriteHeader(http.StatusOK)
-		h.streamFile(w, file, 0, fileSize-1)
+		h.streamBody(w, r, file, 0, fileSize-1, limits)
 		return
 	}
 
@@ -1503,9 +2913,58 @@ func (h *VideosHandler) Stream(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, fileSize))
 	w.WriteHeader(http.StatusPartialContent)
 
+	h.streamBody(w, r, file, start, end, limits)
+}
+
+// streamBody sends bytes start through end of file, paced to the stream's
+// bandwidth limits. A HEAD request gets only the headers already written,
+// without reading the file or taking from the limits.
+func (h *VideosHandler) streamBody(w http.ResponseWriter, r *http.Request, file *os.File, start, end int64, limits streamLimits) {
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	w, release := h.bandwidth.Writer(r.Context(), w, limits.Key, limits.PerConnection, limits.PerUser)
+	defer release()
+
 	h.streamFile(w, file, start, end)
 }
 
+// accelRedirectVideo hands a progressive stream to nginx with
+// X-Accel-Redirect, pointing at the internal location STREAM_ACCEL_REDIRECT_PREFIX
+// maps to the video storage directory, which holds every video's storage path. Nginx answers Range requests itself and
+// keeps the Content-Type and Content-Disposition set here. Nginx can only
+// apply the per-connection limit, through X-Accel-Limit-Rate.
+func (h *VideosHandler) accelRedirectVideo(w http.ResponseWriter, video sqlc.Video, videoPath string, limits streamLimits) {
+	if !storage.FileExists(videoPath) {
+		log.Printf("Error opening video file: %s not found", videoPath)
+		response.NotFound(w, "Video file not found")
+		return
+	}
+
+	// Sharded or flat, relative to the storage directory the location maps to
+	rel, err := h.storage.VideoRelativePath(videoPath)
+	if err != nil {
+		log.Printf("Error resolving video path for X-Accel-Redirect: %v", err)
+		response.InternalServerError(w, "Failed to stream video")
+		return
+	}
+
+	// FormatMediaType returns "" if it can't format the filename
+	disposition := mime.FormatMediaType("inline", map[string]string{"filename": video.Title + ".mp4"})
+	if disposition == "" {
+		disposition = "inline"
+	}
+
+	w.Header().Set("Content-Type", "video/mp4")
+	w.Header().Set("Content-Disposition", disposition)
+	w.Header().Set("X-Accel-Redirect", path.Join(h.config.StreamAccelRedirectPrefix, rel))
+	if limits.PerConnection > 0 {
+		w.Header().Set("X-Accel-Limit-Rate", strconv.FormatInt(limits.PerConnection, 10))
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
 // streamFile streams a portion of the file from start to end (inclusive)
 func (h *VideosHandler) streamFile(w http.ResponseWriter, file *os.File, start, end int64) {
 	// Seek to start position
@@ -1620,6 +3079,11 @@ func (h *VideosHandler) HLS(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "HLS filename is required")
 		return
 	}
+	// Rendition playlists are in subdirectories, but never outside the video's HLS directory
+	if slices.Contains(strings.Split(hlsFilename, "/"), "..") {
+		response.BadRequest(w, "Invalid HLS filename")
+		return
+	}
 
 	// Get current user for access control
 	userID, ok := middleware.GetUserID(ctx)
@@ -1650,10 +3114,11 @@ func (h *VideosHandler) HLS(w http.ResponseWriter, r *http.Request) {
 	// Handle based on file type
 	lowerFilename := strings.ToLower(hlsFilename)
 
-	if strings.HasSuffix(lowerFilename, ".ts") {
-		// Segment files should be served by nginx with secure_link validation
+	if strings.HasSuffix(lowerFilename, ".ts") || strings.HasSuffix(lowerFilename, ".m4s") || strings.HasSuffix(lowerFilename, ".mp4") {
+		// Segments are requested from their signed /hls/ URLs, served by nginx
+		// with secure_link validation or, with HLS_SERVE_SEGMENTS, by HLSSegment.
 		// Return 410 Gone to indicate this endpoint doesn't serve segments
-		http.Error(w, "HLS segments should be served by nginx. Configure nginx with secure_link for /hls/ path.", http.StatusGone)
+		http.Error(w, "HLS segments are served from signed /hls/ URLs. Configure nginx with secure_link for the /hls/ path, or set HLS_SERVE_SEGMENTS=true to serve them from the backend.", http.StatusGone)
 		return
 	}
 
@@ -1662,8 +3127,51 @@ func (h *VideosHandler) HLS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.serveHLSPlaylist(w, video, hlsFilename)
+}
+
+// AllowSignedPlaylist serves HLS playlist requests carrying a signed URL,
+// as master playlists list their rendition playlists with, without
+// authentication, and passes the rest on to next
+func (h *VideosHandler) AllowSignedPlaylist(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		signature := query.Get("signature")
+		if signature == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		shortID := r.PathValue("short_id")
+		hlsFilename := r.PathValue("filename")
+		expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+		if err != nil || !auth.ValidatePlaylistSignature(shortID, hlsFilename, signature, expires, h.config.HLSSigningSecrets()) {
+			response.Forbidden(w, "Invalid or expired playlist URL")
+			return
+		}
+
+		video, err := h.db.Queries.GetVideoByShortID(r.Context(), shortID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				response.NotFound(w, "Video not found")
+				return
+			}
+			log.Printf("Error getting video: %v", err)
+			response.InternalServerError(w, "Failed to get video")
+			return
+		}
+
+		// Only playlists are signed, never outside the HLS directory
+		h.serveHLSPlaylist(w, video, hlsFilename)
+	})
+}
+
+// serveHLSPlaylist sends one of a video's HLS playlists, with its rendition
+// playlists and segments rewritten to signed URLs. The response is private:
+// it answers an authenticated or signed request.
+func (h *VideosHandler) serveHLSPlaylist(w http.ResponseWriter, video sqlc.Video, hlsFilename string) {
 	// Read the manifest file
-	hlsPath := h.storage.GetHLSFilePath(video.Filename, hlsFilename, nil)
+	hlsPath := h.storage.GetHLSFilePath(video.Filename, hlsFilename, video.StoragePath)
 	content, err := os.ReadFile(hlsPath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -1675,28 +3183,233 @@ func (h *VideosHandler) HLS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Rewrite segment URLs to signed nginx URLs
-	hlsDir := storage.GetHLSDirectoryName(video.Filename)
-	rewrittenContent := h.rewriteHLSManifest(string(content), hlsDir)
+	// Rewrite segment URLs to signed nginx URLs. A rendition's segments are
+	// in the same directory as its playlist, which signed paths give relative
+	// to the video storage directory, whatever the video's storage path.
+	hlsDir, err := h.storage.VideoRelativePath(filepath.Dir(hlsPath))
+	if err != nil {
+		log.Printf("Error resolving HLS directory of video %s: %v", video.ID, err)
+		response.InternalServerError(w, "Failed to read HLS manifest")
+		return
+	}
+	signPlaylist := func(uri string) string {
+		playlist := path.Join(path.Dir(hlsFilename), uri)
+		return uri + "?" + auth.SignPlaylistQuery(video.ShortID, playlist, h.config.HLSSigningSecret, auth.HLSDefaultExpiry)
+	}
+	rewrittenContent := h.rewriteHLSManifest(string(content), hlsDir, signPlaylist)
 
 	// Send response
 	w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
-	w.Header().Set("Cache-Control", "public, max-age=3600") // 1 hour cache
+	w.Header().Set("Cache-Control", "private, max-age=3600") // 1 hour cache
 	w.Header().Set("Content-Length", strconv.Itoa(len(rewrittenContent)))
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(rewrittenContent))
 }
 
-// segmentRegex matches HLS segment filenames like "segment000.ts"
-// Note: Go regexp doesn't support negative lookahead, so we handle the ? case in the replacement function
-var segmentRegex = regexp.MustCompile(`segment\d+\.ts`)
+// DASH handles GET /api/videos/{short_id}/dash/{filename...}
+// Serves the DASH manifest of a video with fMP4 HLS segments, with its
+// segment URLs signed like those of HLS playlists
+func (h *VideosHandler) DASH(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	shortID := r.PathValue("short_id")
+	if shortID == "" {
+		response.BadRequest(w, "Video ID is required")
+		return
+	}
+
+	// Segments are requested from their signed /hls/ URLs, so the manifest
+	// is the only file served here
+	if r.PathValue("filename") != storage.DASHManifestName {
+		response.NotFound(w, "DASH manifest not found")
+		return
+	}
+
+	// Get current user for access control
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+	isAdmin := middleware.IsAdmin(ctx)
+
+	// Get video
+	video, err := h.db.Queries.GetVideoByShortID(ctx, shortID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.NotFound(w, "Video not found")
+			return
+		}
+		log.Printf("Error getting video: %v", err)
+		response.InternalServerError(w, "Failed to get video")
+		return
+	}
+
+	// Check access
+	if !hasVideoAccess(video, userID, isAdmin) {
+		response.Forbidden(w, "You don't have permission to view this video")
+		return
+	}
 
-// rewriteHLSManifest rewrites segment URLs in the manifest to signed nginx URLs
-func (h *VideosHandler) rewriteHLSManifest(manifest string, hlsDir string) string {
-	return segmentRegex.ReplaceAllStringFunc(manifest, func(segment string) string {
-		// Build the full path for signing: "hlsDir/segment000.ts"
-		segmentPath := fmt.Sprintf("%s/%s", hlsDir, segment)
-		// Generate signed URL
-		return auth.GenerateSignedHLSURLWithDefaults(segmentPath, h.config.HLSSigningSecret)
+	manifestPath := h.storage.GetDASHManifestPath(video.Filename, video.StoragePath)
+	content, err := os.ReadFile(manifestPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			response.NotFound(w, "DASH manifest not found")
+			return
+		}
+		log.Printf("Error reading DASH manifest: %v", err)
+		response.InternalServerError(w, "Failed to read DASH manifest")
+		return
+	}
+
+	// Segment URLs are relative to the HLS directory, which holds the manifest
+	hlsDir, err := h.storage.VideoRelativePath(filepath.Dir(manifestPath))
+	if err != nil {
+		log.Printf("Error resolving HLS directory of video %s: %v", video.ID, err)
+		response.InternalServerError(w, "Failed to read DASH manifest")
+		return
+	}
+	rewrittenContent := h.rewriteDASHManifest(string(content), hlsDir)
+
+	w.Header().Set("Content-Type", "application/dash+xml")
+	w.Header().Set("Cache-Control", "private, max-age=3600") // 1 hour cache, like HLS manifests
+	w.Header().Set("Content-Length", strconv.Itoa(len(rewrittenContent)))
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(rewrittenContent))
+}
+
+// dashURLRegex matches the init segment and segment URLs of a DASH manifest
+var dashURLRegex = regexp.MustCompile(`\b(sourceURL|media)="([^"]*)"`)
+
+// rewriteDASHManifest rewrites the segment URLs in a DASH manifest to signed
+// nginx URLs, the same ones HLS playlists list. hlsDir is the directory the
+// URLs are relative to.
+func (h *VideosHandler) rewriteDASHManifest(manifest string, hlsDir string) string {
+	return dashURLRegex.ReplaceAllStringFunc(manifest, func(attr string) string {
+		match := dashURLRegex.FindStringSubmatch(attr)
+		segmentPath := path.Join(hlsDir, html.UnescapeString(match[2]))
+		signedURL := auth.GenerateSignedHLSURLWithDefaults(segmentPath, h.config.HLSSigningSecrets())
+		return fmt.Sprintf(`%s="%s"`, match[1], html.EscapeString(signedURL))
 	})
 }
+
+// hlsSegmentTypes maps the extensions of HLS segments to their MIME types
+var hlsSegmentTypes = map[string]string{
+	".ts":  "video/mp2t",
+	".m4s": "video/iso.segment",
+	".mp4": "video/mp4", // fMP4 init segment
+}
+
+// HLSSegment handles GET /hls/{path...}, registered with HLS_SERVE_SEGMENTS
+// for deployments without nginx. It checks the segment URL signature from
+// rewriteHLSManifest the way nginx's secure_link does (403 if invalid, 410 if
+// expired) and serves the file from the video storage directory, with Range
+// support. Segment URLs don't say who they were issued to, so only the
+// per-connection bandwidth limit applies.
+func (h *VideosHandler) HLSSegment(w http.ResponseWriter, r *http.Request) {
+	segmentPath := r.PathValue("path")
+	contentType, ok := hlsSegmentTypes[strings.ToLower(path.Ext(segmentPath))]
+	if !ok || slices.Contains(strings.Split(segmentPath, "/"), "..") {
+		response.NotFound(w, "HLS segment not found")
+		return
+	}
+
+	query := r.URL.Query()
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil {
+		response.Forbidden(w, "Invalid HLS segment URL")
+		return
+	}
+	if time.Now().Unix() > expires {
+		response.Error(w, http.StatusGone, "HLS segment URL has expired")
+		return
+	}
+	if !auth.ValidateHLSSignature(r.URL.Path, query.Get("md5"), expires, h.config.HLSSigningSecrets()) {
+		response.Forbidden(w, "Invalid HLS segment URL")
+		return
+	}
+
+	// Signed paths are relative to the video storage directory, shard
+	// directories and videos' storage paths included
+	file, err := os.Open(filepath.Join(h.storage.Config().VideoPath, filepath.FromSlash(segmentPath)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			response.NotFound(w, "HLS segment not found")
+			return
+		}
+		log.Printf("Error opening HLS segment: %v", err)
+		response.InternalServerError(w, "Failed to read HLS segment")
+		return
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil || stat.IsDir() {
+		response.NotFound(w, "HLS segment not found")
+		return
+	}
+
+	// A segment never changes once written, and its URL carries its expiry
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	limits := h.streamLimits(r.Context(), "")
+	w, release := h.bandwidth.Writer(r.Context(), w, limits.Key, limits.PerConnection, limits.PerUser)
+	defer release()
+
+	http.ServeContent(w, r, segmentPath, stat.ModTime(), file)
+}
+
+// hlsURITags are the playlist tags whose URI attribute points at a
+// playlist or segment: init segments, audio renditions and I-frame playlists
+var hlsURITags = []string{"#EXT-X-MAP:", "#EXT-X-MEDIA:", "#EXT-X-I-FRAME-STREAM-INF:"}
+
+// hlsURIAttrRegex matches a tag's URI attribute
+var hlsURIAttrRegex = regexp.MustCompile(`URI="([^"]*)"`)
+
+// rewriteHLSManifest rewrites the URIs in a master or media playlist: the URI
+// lines and the URI attributes of hlsURITags. Segments, fMP4 init segments
+// included, get signed /hls/ URLs; hlsDir is the directory the manifest's
+// relative URIs resolve against. Playlists stay relative, so players request
+// them from this handler too, signed by signPlaylist for players (like
+// Safari's) that can't send a token. Other tags and comments, and URIs
+// already signed or absolute, are left as they are.
+func (h *VideosHandler) rewriteHLSManifest(manifest string, hlsDir string, signPlaylist func(uri string) string) string {
+	lines := strings.Split(manifest, "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#"):
+			if !slices.ContainsFunc(hlsURITags, func(tag string) bool { return strings.HasPrefix(line, tag) }) {
+				continue
+			}
+			lines[i] = hlsURIAttrRegex.ReplaceAllStringFunc(line, func(attr string) string {
+				uri := hlsURIAttrRegex.FindStringSubmatch(attr)[1]
+				return `URI="` + h.rewriteHLSURI(uri, hlsDir, signPlaylist) + `"`
+			})
+		default:
+			lines[i] = h.rewriteHLSURI(line, hlsDir, signPlaylist)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// rewriteHLSURI rewrites one URI of a manifest for rewriteHLSManifest
+func (h *VideosHandler) rewriteHLSURI(uri string, hlsDir string, signPlaylist func(uri string) string) string {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.IsAbs() || strings.HasPrefix(parsed.Path, "/") || parsed.RawQuery != "" {
+		// Already signed, or not ours to sign
+		return uri
+	}
+
+	if strings.HasSuffix(strings.ToLower(parsed.Path), ".m3u8") {
+		return signPlaylist(parsed.Path)
+	}
+
+	// Segments in a subdirectory of the playlist's, like a rendition's, keep
+	// their directory
+	return auth.GenerateSignedHLSURLWithDefaults(path.Join(hlsDir, parsed.Path), h.config.HLSSigningSecrets())
+}
@@ -0,0 +1,396 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/clipset/clipset-go/internal/config"
+	"github.com/clipset/clipset-go/internal/db/sqlc"
+	"github.com/clipset/clipset-go/internal/domain"
+	"github.com/clipset/clipset-go/internal/services/auth"
+	"github.com/clipset/clipset-go/internal/services/upload"
+)
+
+const testSigningSecret = "test-signing-secret-0123456789"
+
+// signedSegmentRegex matches the signed /hls/ URLs rewriteHLSManifest writes
+var signedSegmentRegex = regexp.MustCompile(`(/hls/[^"?\s]+)\?md5=([^&"\s]+)&expires=(\d+)`)
+
+// fakeSignPlaylist marks the playlist URIs rewriteHLSManifest signs
+func fakeSignPlaylist(uri string) string {
+	return uri + "?signed"
+}
+
+// normalizeSigned replaces each validly signed segment URL in a rewritten
+// manifest with SIGNED(path), as signatures change with the time. URLs
+// signed otherwise are left as they are.
+func normalizeSigned(manifest string) string {
+	return signedSegmentRegex.ReplaceAllStringFunc(manifest, func(signed string) string {
+		match := signedSegmentRegex.FindStringSubmatch(signed)
+		expires, _ := strconv.ParseInt(match[3], 10, 64)
+		if !auth.ValidateHLSSignature(match[1], match[2], expires, []string{testSigningSecret}) {
+			return signed
+		}
+		return "SIGNED(" + match[1] + ")"
+	})
+}
+
+func TestRewriteHLSManifest(t *testing.T) {
+	tests := []struct {
+		name     string
+		hlsDir   string
+		manifest string
+		want     string
+	}{
+		{
+			name:   "media playlist",
+			hlsDir: "ab/cd/abcd_hls",
+			manifest: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-TARGETDURATION:4
+#EXTINF:4.000000,
+segment000.ts
+#EXTINF:3.500000,
+segment001.ts
+#EXT-X-ENDLIST
+`,
+			want: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-TARGETDURATION:4
+#EXTINF:4.000000,
+SIGNED(/hls/ab/cd/abcd_hls/segment000.ts)
+#EXTINF:3.500000,
+SIGNED(/hls/ab/cd/abcd_hls/segment001.ts)
+#EXT-X-ENDLIST
+`,
+		},
+		{
+			name:   "fMP4 media playlist in a rendition subdirectory",
+			hlsDir: "ab/cd/abcd_hls/720p",
+			manifest: `#EXTM3U
+#EXT-X-VERSION:7
+#EXT-X-MAP:URI="init.mp4"
+#EXTINF:4.000000,
+segment000.m4s
+`,
+			want: `#EXTM3U
+#EXT-X-VERSION:7
+#EXT-X-MAP:URI="SIGNED(/hls/ab/cd/abcd_hls/720p/init.mp4)"
+#EXTINF:4.000000,
+SIGNED(/hls/ab/cd/abcd_hls/720p/segment000.m4s)
+`,
+		},
+		{
+			name:   "master playlist",
+			hlsDir: "ab/cd/abcd_hls",
+			manifest: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:BANDWIDTH=8421376,AVERAGE-BANDWIDTH=6123008,RESOLUTION=1920x1080
+1080p/index.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=5210112,AVERAGE-BANDWIDTH=3874816,RESOLUTION=1280x720
+720p/index.m3u8
+`,
+			want: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:BANDWIDTH=8421376,AVERAGE-BANDWIDTH=6123008,RESOLUTION=1920x1080
+1080p/index.m3u8?signed
+#EXT-X-STREAM-INF:BANDWIDTH=5210112,AVERAGE-BANDWIDTH=3874816,RESOLUTION=1280x720
+720p/index.m3u8?signed
+`,
+		},
+		{
+			name:   "URI attributes of audio renditions and I-frame playlists",
+			hlsDir: "abcd_hls",
+			manifest: `#EXTM3U
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aud",NAME="English",DEFAULT=YES,URI="audio/en/index.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aud",NAME="Commentary",URI="audio/commentary.m3u8"
+#EXT-X-I-FRAME-STREAM-INF:BANDWIDTH=86000,URI="720p/iframes.m3u8"
+#EXT-X-STREAM-INF:BANDWIDTH=5210112,AUDIO="aud"
+720p/index.m3u8
+`,
+			want: `#EXTM3U
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aud",NAME="English",DEFAULT=YES,URI="audio/en/index.m3u8?signed"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aud",NAME="Commentary",URI="audio/commentary.m3u8?signed"
+#EXT-X-I-FRAME-STREAM-INF:BANDWIDTH=86000,URI="720p/iframes.m3u8?signed"
+#EXT-X-STREAM-INF:BANDWIDTH=5210112,AUDIO="aud"
+720p/index.m3u8?signed
+`,
+		},
+		{
+			name:   "already signed, absolute and rooted URIs",
+			hlsDir: "abcd_hls",
+			manifest: `#EXTM3U
+#EXT-X-MAP:URI="/hls/abcd_hls/init.mp4?md5=abc&expires=1"
+#EXTINF:4.000000,
+/hls/abcd_hls/segment000.m4s?md5=def&expires=1
+#EXTINF:4.000000,
+https://cdn.example.com/segment001.m4s
+#EXTINF:4.000000,
+/media/segment002.m4s
+#EXTINF:4.000000,
+segment003.m4s?md5=ghi&expires=1
+`,
+			want: `#EXTM3U
+#EXT-X-MAP:URI="/hls/abcd_hls/init.mp4?md5=abc&expires=1"
+#EXTINF:4.000000,
+/hls/abcd_hls/segment000.m4s?md5=def&expires=1
+#EXTINF:4.000000,
+https://cdn.example.com/segment001.m4s
+#EXTINF:4.000000,
+/media/segment002.m4s
+#EXTINF:4.000000,
+segment003.m4s?md5=ghi&expires=1
+`,
+		},
+		{
+			name:   "comments and other tags are untouched",
+			hlsDir: "abcd_hls",
+			manifest: `#EXTM3U
+# segment000.ts is the first segment
+#EXT-X-SESSION-DATA:DATA-ID="com.example.title",URI="title.json"
+#EXT-X-KEY:METHOD=NONE
+#EXTINF:4.000000,segment title
+segment000.ts
+`,
+			want: `#EXTM3U
+# segment000.ts is the first segment
+#EXT-X-SESSION-DATA:DATA-ID="com.example.title",URI="title.json"
+#EXT-X-KEY:METHOD=NONE
+#EXTINF:4.000000,segment title
+SIGNED(/hls/abcd_hls/segment000.ts)
+`,
+		},
+	}
+
+	h := &VideosHandler{config: &config.Config{HLSSigningSecret: testSigningSecret}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := normalizeSigned(h.rewriteHLSManifest(tt.manifest, tt.hlsDir, fakeSignPlaylist))
+			if got != tt.want {
+				t.Errorf("rewriteHLSManifest() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRewriteHLSManifestIsIdempotent(t *testing.T) {
+	manifest := `#EXTM3U
+#EXT-X-MAP:URI="init.mp4"
+#EXTINF:4.000000,
+segment000.m4s
+#EXT-X-STREAM-INF:BANDWIDTH=5210112
+720p/index.m3u8
+`
+	h := &VideosHandler{config: &config.Config{HLSSigningSecret: testSigningSecret}}
+	once := h.rewriteHLSManifest(manifest, "abcd_hls", fakeSignPlaylist)
+	if twice := h.rewriteHLSManifest(once, "abcd_hls", fakeSignPlaylist); twice != once {
+		t.Errorf("rewriting a rewritten manifest changed it:\n%s\nwant\n%s", twice, once)
+	}
+}
+
+func TestAllowSignedStreamChecksUser(t *testing.T) {
+	issuedTo := uuid.New()
+	signed, _ := auth.SignStreamURL("abc123", issuedTo, testSigningSecret, time.Hour)
+
+	tests := []struct {
+		name     string
+		user     string // Replaces the user in the signed URL, if set
+		accepted bool
+	}{
+		{"user the URL was issued to", "", true},
+		{"another user", uuid.NewString(), false},
+		{"invalid user", "nobody", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, signed, nil)
+			if tt.user != "" {
+				query := req.URL.Query()
+				query.Set("user", tt.user)
+				req.URL.RawQuery = query.Encode()
+			}
+			req.SetPathValue("short_id", "abc123")
+
+			// Accepted URLs look up the user they stream as, who doesn't exist here
+			fake := newFakeDB().returns("GetVideoByShortID", sqlc.Video{ShortID: "abc123"})
+			h := &VideosHandler{db: fake.db(), config: &config.Config{HLSSigningSecret: testSigningSecret}}
+			rec := httptest.NewRecorder()
+			h.AllowSignedStream(http.NotFoundHandler()).ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusForbidden {
+				t.Fatalf("got %d, want 403: %s", rec.Code, rec.Body)
+			}
+			if !tt.accepted {
+				if n := fake.count("GetUserByID"); n != 0 {
+					t.Errorf("looked up a user for a URL with an invalid signature")
+				}
+				return
+			}
+			if args := fake.lastArgs("GetUserByID"); len(args) == 0 || args[0] != issuedTo {
+				t.Errorf("looked up user %v, want %s", args, issuedTo)
+			}
+		})
+	}
+}
+
+func TestPrecheckValidatesSHA256(t *testing.T) {
+	digest := strings.Repeat("0123456789abcdef", 4)
+	tests := []struct {
+		name   string
+		sha256 string
+		want   string // Hash looked up, or "" for a rejected request
+	}{
+		{"lowercase", digest, digest},
+		{"uppercase", strings.ToUpper(digest), digest},
+		{"surrounding space", " " + digest + "\n", digest},
+		{"empty", "", ""},
+		{"too short", digest[:63], ""},
+		{"too long", digest + "0", ""},
+		{"not hex", strings.Repeat("g", 64), ""},
+		{"64 bytes, not 64 characters", strings.Repeat("é", 32), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeDB()
+			h := &VideosHandler{db: fake.db(), config: &config.Config{}}
+
+			body, _ := json.Marshal(UploadPrecheckRequest{SHA256: tt.sha256, Filename: "clip.mp4", SizeBytes: 1024})
+			req := httptest.NewRequest(http.MethodPost, "/api/videos/precheck", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			h.Precheck(rec, withUser(req, uuid.New(), domain.UserRoleUser))
+
+			if tt.want == "" {
+				if rec.Code != http.StatusBadRequest {
+					t.Errorf("Precheck returned %d, want 400: %s", rec.Code, rec.Body)
+				}
+				if n := fake.count("GetVideoByUploaderAndHash"); n != 0 {
+					t.Error("looked up an invalid hash")
+				}
+				return
+			}
+			args := fake.lastArgs("GetVideoByUploaderAndHash")
+			if len(args) < 2 {
+				t.Fatalf("hash wasn't looked up, got %d: %s", rec.Code, rec.Body)
+			}
+			if got, ok := args[1].(*string); !ok || *got != tt.want {
+				t.Errorf("looked up hash %v, want %s", args[1], tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowSignedStreamRejectsInactiveUsers(t *testing.T) {
+	user := sqlc.User{ID: uuid.New(), Username: "viewer", Role: domain.UserRoleUser}
+	signed, _ := auth.SignStreamURL("abc123", user.ID, testSigningSecret, time.Hour)
+
+	tests := []struct {
+		name     string
+		isActive bool
+		want     string
+	}{
+		// The video is another user's and still processing, so an active user
+		// gets as far as the access check
+		{"active", true, "You don't have permission to view this video"},
+		{"deactivated", false, "Invalid or expired stream URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user.IsActive = tt.isActive
+			fake := newFakeDB().
+				returns("GetVideoByShortID", sqlc.Video{ShortID: "abc123", UploadedBy: uuid.New(), ProcessingStatus: domain.ProcessingStatusProcessing}).
+				returns("GetUserByID", user)
+			h := &VideosHandler{db: fake.db(), config: &config.Config{HLSSigningSecret: testSigningSecret}}
+
+			req := httptest.NewRequest(http.MethodGet, signed, nil)
+			req.SetPathValue("short_id", "abc123")
+			rec := httptest.NewRecorder()
+			h.AllowSignedStream(http.NotFoundHandler()).ServeHTTP(rec, req)
+
+			var resp struct {
+				Detail string `json:"detail"`
+			}
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			if rec.Code != http.StatusForbidden || resp.Detail != tt.want {
+				t.Errorf("got %d %q, want 403 %q", rec.Code, resp.Detail, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunkedUploadSessionsBelongToTheirUser(t *testing.T) {
+	owner, other := uuid.New(), uuid.New()
+
+	endpoints := map[string]func(h *VideosHandler, uploadID string, userID uuid.UUID) *httptest.ResponseRecorder{
+		"upload chunk": func(h *VideosHandler, uploadID string, userID uuid.UUID) *httptest.ResponseRecorder {
+			var body bytes.Buffer
+			form := multipart.NewWriter(&body)
+			form.WriteField("upload_id", uploadID)
+			form.WriteField("chunk_index", "1")
+			part, _ := form.CreateFormFile("file", "blob")
+			part.Write([]byte("chunk"))
+			form.Close()
+			req := httptest.NewRequest(http.MethodPost, "/api/videos/upload/chunk", &body)
+			req.Header.Set("Content-Type", form.FormDataContentType())
+			rec := httptest.NewRecorder()
+			h.UploadChunk(rec, withUser(req, userID, domain.UserRoleUser))
+			return rec
+		},
+		"complete": func(h *VideosHandler, uploadID string, userID uuid.UUID) *httptest.ResponseRecorder {
+			body, _ := json.Marshal(ChunkUploadCompleteRequest{UploadID: uploadID, Title: "Clip", Filename: "clip.mp4"})
+			req := httptest.NewRequest(http.MethodPost, "/api/videos/upload/complete", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			h.CompleteChunkedUpload(rec, withUser(req, userID, domain.UserRoleUser))
+			return rec
+		},
+		"abort": func(h *VideosHandler, uploadID string, userID uuid.UUID) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodDelete, "/api/videos/upload/"+uploadID, nil)
+			req.SetPathValue("upload_id", uploadID)
+			rec := httptest.NewRecorder()
+			h.AbortChunkedUpload(rec, withUser(req, userID, domain.UserRoleUser))
+			return rec
+		},
+	}
+
+	for name, call := range endpoints {
+		t.Run(name, func(t *testing.T) {
+			chunksDir := t.TempDir()
+			h := &VideosHandler{config: &config.Config{}, chunkManager: upload.NewChunkedUploadManager(chunksDir, time.Hour)}
+			uploadID, err := h.chunkManager.InitSession(owner.String(), "clip.mp4", 1024)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if rec := call(h, uploadID, other); rec.Code != http.StatusForbidden {
+				t.Errorf("another user got %d, want 403: %s", rec.Code, rec.Body)
+			}
+			if !h.chunkManager.SessionExists(uploadID) {
+				t.Fatal("another user removed the session")
+			}
+			if n, _ := h.chunkManager.GetChunkCount(uploadID); n != 0 {
+				t.Errorf("another user added %d chunks", n)
+			}
+
+			if rec := call(h, "../"+filepath.Base(chunksDir), owner); rec.Code != http.StatusBadRequest {
+				t.Errorf("path as upload ID got %d, want 400: %s", rec.Code, rec.Body)
+			}
+			if _, err := os.Stat(chunksDir); err != nil {
+				t.Errorf("chunks directory gone after a path as upload ID: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/clipset/clipset-go/internal/api/middleware"
+	"github.com/clipset/clipset-go/internal/api/response"
+	"github.com/clipset/clipset-go/internal/config"
+	"github.com/clipset/clipset-go/internal/db"
+	"github.com/clipset/clipset-go/internal/db/sqlc"
+	"github.com/clipset/clipset-go/internal/services/webhook"
+)
+
+// Webhook settings
+const (
+	webhookSecretBytes       = 32   // Random bytes of a generated secret
+	webhookMaxURLLength      = 2048 // Longest webhook URL accepted
+	webhookMinSecretLength   = 16   // Shortest secret accepted
+	webhookDeliveriesPerPage = 50   // Deliveries listed by default, and at most
+)
+
+// WebhooksHandler serves the admin webhook endpoints
+type WebhooksHandler struct {
+	db     *db.DB
+	config *config.Config
+}
+
+// NewWebhooksHandler creates a new webhooks handler
+func NewWebhooksHandler(database *db.DB, cfg *config.Config) *WebhooksHandler {
+	return &WebhooksHandler{
+		db:     database,
+		config: cfg,
+	}
+}
+
+// WebhookResponse represents a webhook. The secret is only returned when the
+// webhook is created.
+type WebhookResponse struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    *string   `json:"secret,omitempty"`
+	CreatedBy *string   `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookDeliveryResponse represents one delivery attempt
+type WebhookDeliveryResponse struct {
+	ID         string    `json:"id"`
+	Event      string    `json:"event"`
+	Attempt    int32     `json:"attempt"`
+	StatusCode *int32    `json:"status_code"` // Null if the receiver didn't respond
+	Error      *string   `json:"error"`
+	DurationMs int32     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WebhookCreateRequest represents the create webhook request. Leaving out
+// the secret generates one.
+type WebhookCreateRequest struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+}
+
+// buildWebhookResponse converts a database webhook to a response
+func buildWebhookResponse(hook sqlc.Webhook) WebhookResponse {
+	var createdBy *string
+	if hook.CreatedBy.Valid {
+		id := uuid.UUID(hook.CreatedBy.Bytes).String()
+		createdBy = &id
+	}
+
+	return WebhookResponse{
+		ID:        hook.ID.String(),
+		URL:       hook.Url,
+		Events:    hook.Events,
+		CreatedBy: createdBy,
+		CreatedAt: hook.CreatedAt,
+	}
+}
+
+// validateWebhookURL checks that a webhook URL is an absolute http(s) URL
+func validateWebhookURL(raw string) bool {
+	if len(raw) > webhookMaxURLLength {
+		return false
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// parseWebhookID parses the webhook_id path value, writing a 400 if it's invalid
+func parseWebhookID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	webhookID, err := uuid.Parse(r.PathValue("webhook_id"))
+	if err != nil {
+		response.BadRequest(w, "Invalid webhook ID format")
+		return uuid.Nil, false
+	}
+	return webhookID, true
+}
+
+// Create handles POST /api/admin/webhooks (admin only)
+func (h *WebhooksHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		response.Unauthorized(w, "Not authenticated")
+		return
+	}
+
+	var req WebhookCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	hookURL := strings.TrimSpace(req.URL)
+	if !validateWebhookURL(hookURL) {
+		response.BadRequest(w, "url must be an http or https URL")
+		return
+	}
+
+	if len(req.Events) == 0 {
+		response.BadRequest(w, "events must list at least one event")
+		return
+	}
+	events := make([]string, 0, len(req.Events))
+	for _, event := range req.Events {
+		if !webhook.ValidEvent(event) {
+			response.BadRequest(w, "events must be from: "+strings.Join(webhook.Events, ", "))
+			return
+		}
+		if !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		generated, err := generateURLSafeToken(webhookSecretBytes)
+		if err != nil {
+			log.Printf("Error generating webhook secret: %v", err)
+			response.InternalServerError(w, "Failed to create webhook")
+			return
+		}
+		secret = generated
+	} else if len(secret) < webhookMinSecretLength {
+		response.BadRequest(w, "secret must be at least 16 characters")
+		return
+	}
+
+	hook, err := h.db.Queries.CreateWebhook(ctx, sqlc.CreateWebhookParams{
+		Url:       hookURL,
+		Secret:    secret,
+		Events:    events,
+		CreatedBy: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		log.Printf("Error creating webhook: %v", err)
+		response.InternalServerError(w, "Failed to create webhook")
+		return
+	}
+
+	recordAudit(ctx, h.db.Queries, auditEntry{
+		Action:     auditWebhookCreate,
+		TargetType: auditTargetWebhook,
+		TargetID:   hook.ID,
+		Details: map[string]interface{}{
+			"url":    hook.Url,
+			"events": hook.Events,
+		},
+	})
+
+	resp := buildWebhookResponse(hook)
+	resp.Secret = &hook.Secret
+	response.Created(w, resp)
+}
+
+// List handles GET /api/admin/webhooks (admin only)
+func (h *WebhooksHandler) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	hooks, err := h.db.Queries.ListWebhooks(ctx)
+	if err != nil {
+		log.Printf("Error listing webhooks: %v", err)
+		response.InternalServerError(w, "Failed to list webhooks")
+		return
+	}
+
+	resp := make([]WebhookResponse, len(hooks))
+	for i, hook := range hooks {
+		resp[i] = buildWebhookResponse(hook)
+	}
+	response.OK(w, resp)
+}
+
+// Delete handles DELETE /api/admin/webhooks/{webhook_id} (admin only).
+// Deliveries still queued for it are dropped.
+func (h *WebhooksHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	webhookID, ok := parseWebhookID(w, r)
+	if !ok {
+		return
+	}
+
+	deleted, err := h.db.Queries.DeleteWebhook(ctx, webhookID)
+	if err != nil {
+		log.Printf("Error deleting webhook: %v", err)
+		response.InternalServerError(w, "Failed to delete webhook")
+		return
+	}
+	if deleted == 0 {
+		response.NotFound(w, "Webhook not found")
+		return
+	}
+
+	recordAudit(ctx, h.db.Queries, auditEntry{
+		Action:     auditWebhookDelete,
+		TargetType: auditTargetWebhook,
+		TargetID:   webhookID,
+	})
+
+	response.NoContent(w)
+}
+
+// Deliveries handles GET /api/admin/webhooks/{webhook_id}/deliveries (admin only).
+// Lists the webhook's latest delivery attempts, newest first; limit is 1-50.
+func (h *WebhooksHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	webhookID, ok := parseWebhookID(w, r)
+	if !ok {
+		return
+	}
+
+	limit := webhookDeliveriesPerPage
+	if l := r.URL.Query().Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 1 || parsed > webhookDeliveriesPerPage {
+			response.BadRequest(w, "limit must be between 1 and 50")
+			return
+		}
+		limit = parsed
+	}
+
+	if _, err := h.db.Queries.GetWebhook(ctx, webhookID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.NotFound(w, "Webhook not found")
+			return
+		}
+		log.Printf("Error getting webhook: %v", err)
+		response.InternalServerError(w, "Failed to get webhook")
+		return
+	}
+
+	deliveries, err := h.db.Queries.ListWebhookDeliveries(ctx, sqlc.ListWebhookDeliveriesParams{
+		WebhookID: webhookID,
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		log.Printf("Error listing webhook deliveries: %v", err)
+		response.InternalServerError(w, "Failed to list webhook deliveries")
+		return
+	}
+
+	resp := make([]WebhookDeliveryResponse, len(deliveries))
+	for i, d := range deliveries {
+		resp[i] = WebhookDeliveryResponse{
+			ID:         d.ID.String(),
+			Event:      d.Event,
+			Attempt:    d.Attempt,
+			StatusCode: d.StatusCode,
+			Error:      d.Error,
+			DurationMs: d.DurationMs,
+			CreatedAt:  d.CreatedAt,
+		}
+	}
+	response.OK(w, resp)
+}
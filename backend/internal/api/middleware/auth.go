@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net/http"
 	"strings"
 
@@ -9,7 +11,9 @@ import (
 
 	"github.com/clipset/clipset-go/internal/api/response"
 	"github.com/clipset/clipset-go/internal/domain"
+	"github.com/clipset/clipset-go/internal/services/apitoken"
 	"github.com/clipset/clipset-go/internal/services/auth"
+	"github.com/clipset/clipset-go/internal/services/session"
 )
 
 // Context keys for user information
@@ -23,15 +27,49 @@ const (
 )
 
 // Auth creates authentication middleware
-func Auth(jwtService *auth.JWTService) func(http.Handler) http.Handler {
+// Accepts a JWT belonging to a session that hasn't been revoked, or a
+// personal access token (cst_ prefix). With cookieMode, a JWT may also come
+// from the auth cookie, in which case mutating requests need a CSRF token.
+func Auth(jwtService *auth.JWTService, sessions *session.Store, tokens *apitoken.Store, cookieMode bool) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			token := extractToken(r)
+			token, fromCookie := extractToken(r, cookieMode)
 			if token == "" {
 				response.Unauthorized(w, "Missing authentication token")
 				return
 			}
 
+			if fromCookie && !isSafeMethod(r.Method) && !ValidCSRF(r) {
+				response.Forbidden(w, "Invalid CSRF token")
+				return
+			}
+
+			if apitoken.IsToken(token) {
+				principal, err := tokens.Resolve(r.Context(), token)
+				if err != nil {
+					if errors.Is(err, apitoken.ErrInvalidToken) {
+						response.Unauthorized(w, "Invalid token")
+						return
+					}
+					log.Printf("Error resolving access token: %v", err)
+					response.InternalServerError(w, "Failed to verify token")
+					return
+				}
+
+				if principal.Scope == apitoken.ScopeUpload && !isUploadRequest(r) {
+					response.Forbidden(w, "This token can only be used for uploads")
+					return
+				}
+
+				// Same context values as a JWT, minus the claims
+				ctx := context.WithValue(r.Context(), UserIDKey, principal.UserID)
+				ctx = context.WithValue(ctx, UsernameKey, principal.Username)
+				ctx = context.WithValue(ctx, UserRoleKey, principal.Role)
+
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
 			claims, err := jwtService.ValidateToken(token)
 			if err != nil {
 				if err == auth.ErrExpiredToken {
@@ -42,6 +80,22 @@ func Auth(jwtService *auth.JWTService) func(http.Handler) http.Handler {
 				return
 			}
 
+			if claims.Scope == auth.ScopeMedia && !isMediaRequest(r) {
+				response.Forbidden(w, "This token can only be used to load images")
+				return
+			}
+
+			active, err := sessionActive(r.Context(), sessions, claims)
+			if err != nil {
+				log.Printf("Error checking session: %v", err)
+				response.InternalServerError(w, "Failed to verify session")
+				return
+			}
+			if !active {
+				response.Unauthorized(w, "Session has been revoked")
+				return
+			}
+
 			// Add user info to context
 			ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
 			ctx = context.WithValue(ctx, UsernameKey, claims.Username)
@@ -55,13 +109,21 @@ func Auth(jwtService *auth.JWTService) func(http.Handler) http.Handler {
 
 // OptionalAuth creates optional authentication middleware
 // Allows unauthenticated requests but adds user info if token is present
-func OptionalAuth(jwtService *auth.JWTService) func(http.Handler) http.Handler {
+func OptionalAuth(jwtService *auth.JWTService, sessions *session.Store, cookieMode bool) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			token := extractToken(r)
+			token, fromCookie := extractToken(r, cookieMode)
+			if fromCookie && !isSafeMethod(r.Method) && !ValidCSRF(r) {
+				token = ""
+			}
 			if token != "" {
 				claims, err := jwtService.ValidateToken(token)
-				if err == nil {
+				if err == nil && (claims.Scope != auth.ScopeMedia || isMediaRequest(r)) {
+					if active, err := sessionActive(r.Context(), sessions, claims); err != nil || !active {
+						next.ServeHTTP(w, r)
+						return
+					}
+
 					ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
 					ctx = context.WithValue(ctx, UsernameKey, claims.Username)
 					ctx = context.WithValue(ctx, UserRoleKey, claims.Role)
@@ -86,21 +148,84 @@ func AdminOnly(next http.Handler) http.Handler {
 	})
 }
 
+// ModeratorOnly creates middleware that requires moderator or admin role
+func ModeratorOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		role, ok := r.Context().Value(UserRoleKey).(domain.UserRole)
+		if !ok || !role.IsModerator() {
+			response.Forbidden(w, "Moderator access required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// sessionActive checks the token's session hasn't been revoked.
+// Tokens without a session ID predate session tracking and are rejected.
+func sessionActive(ctx context.Context, sessions *session.Store, claims *auth.TokenClaims) (bool, error) {
+	sessionID, err := claims.SessionID()
+	if err != nil {
+		return false, nil
+	}
+	return sessions.IsActive(ctx, sessionID, claims.UserID)
+}
+
+// isUploadRequest reports whether an upload-scoped token may be used for the request
+func isUploadRequest(r *http.Request) bool {
+	path := r.URL.Path
+	return strings.HasPrefix(path, "/api/videos/upload") ||
+		path == "/api/videos/precheck" ||
+		path == "/api/videos/quota/me"
+}
+
+// isMediaRequest reports whether a media-scoped token may be used for the
+// request: loading a video thumbnail, category image or avatar
+func isMediaRequest(r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	// /api/{collection}/{id}/{image}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/"), "/")
+	if len(parts) != 3 || parts[1] == "" {
+		return false
+	}
+	switch parts[0] {
+	case "videos":
+		return parts[2] == "thumbnail"
+	case "categories":
+		return parts[2] == "image"
+	case "users":
+		return parts[2] == "avatar"
+	}
+	return false
+}
+
 // extractToken extracts the JWT token from the request
-// Supports both Authorization header and query parameter
-func extractToken(r *http.Request) string {
+// Supports the Authorization header, a query parameter and, in cookie mode,
+// the auth cookie. fromCookie reports whether the token came from the cookie.
+func extractToken(r *http.Request, cookieMode bool) (token string, fromCookie bool) {
 	// Try Authorization header first
 	authHeader := r.Header.Get("Authorization")
 	if authHeader != "" {
 		// Bearer token format
 		if strings.HasPrefix(authHeader, "Bearer ") {
-			return strings.TrimPrefix(authHeader, "Bearer ")
+			return strings.TrimPrefix(authHeader, "Bearer "), false
 		}
-		return authHeader
+		return authHeader, false
 	}
 
 	// Fall back to query parameter (for video streaming)
-	return r.URL.Query().Get("token")
+	if token := r.URL.Query().Get("token"); token != "" {
+		return token, false
+	}
+
+	if cookieMode {
+		if cookie, err := r.Cookie(AuthCookieName); err == nil && cookie.Value != "" {
+			return cookie.Value, true
+		}
+	}
+	return "", false
 }
 
 // GetUserID extracts the user ID from the context
@@ -109,7 +234,8 @@ func GetUserID(ctx context.Context) (uuid.UUID, bool) {
 	return userID, ok
 }
 
-// GetUsername extracts the username from the context
+// GetUsername extracts the username from the context. It comes from the token
+// and may be stale after a username change; load the user by ID where it matters.
 func GetUsername(ctx context.Context) (string, bool) {
 	username, ok := ctx.Value(UsernameKey).(string)
 	return username, ok
@@ -133,6 +259,13 @@ func IsAdmin(ctx context.Context) bool {
 	return ok && role == domain.UserRoleAdmin
 }
 
+// IsModerator checks if the current user is a moderator or admin.
+// Moderators are not admins: IsAdmin stays false for them.
+func IsModerator(ctx context.Context) bool {
+	role, ok := GetUserRole(ctx)
+	return ok && role.IsModerator()
+}
+
 // IsAuthenticated checks if the request has a valid authentication
 func IsAuthenticated(ctx context.Context) bool {
 	_, ok := GetUserID(ctx)
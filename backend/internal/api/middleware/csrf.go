@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+)
+
+// Cookie-based authentication (AUTH_COOKIE_MODE)
+const (
+	AuthCookieName    = "clipset_token"   // httpOnly access token
+	RefreshCookieName = "clipset_refresh" // httpOnly refresh token, only sent to /api/auth
+	CSRFCookieName    = "clipset_csrf"    // Readable by scripts so they can echo it back
+	CSRFHeaderName    = "X-CSRF-Token"
+)
+
+// ValidCSRF checks the double-submit CSRF token: the header must match the
+// cookie. A cross-site page can make the browser send the cookie but can't
+// read it to set the header.
+func ValidCSRF(r *http.Request) bool {
+	header := r.Header.Get(CSRFHeaderName)
+	if header == "" {
+		return false
+	}
+	cookie, err := r.Cookie(CSRFCookieName)
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(header), []byte(cookie.Value)) == 1
+}
+
+// isSafeMethod reports whether a request method doesn't change state
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
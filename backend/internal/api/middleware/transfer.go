@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// deadlineStep is how long a deadline is left alone after being extended, so
+// every small read or write doesn't reset it
+const deadlineStep = time.Second
+
+// Transfer returns a middleware for routes that move large bodies, like
+// streams and uploads. The server's read and write timeouts cap a whole
+// request, which a long stream or a large upload over a slow connection
+// outlasts. Here they're pushed back as the body moves instead, so the
+// connection only times out once no bytes have been read or written for
+// timeout.
+func Transfer(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rc := http.NewResponseController(w)
+			now := time.Now()
+
+			// If the writer doesn't support deadlines, the server's apply
+			rc.SetReadDeadline(now.Add(timeout))
+			rc.SetWriteDeadline(now.Add(timeout))
+
+			if r.Body != nil && r.Body != http.NoBody {
+				// The response comes after the upload, so reading pushes
+				// back both deadlines
+				extend := func(deadline time.Time) error {
+					rc.SetWriteDeadline(deadline)
+					return rc.SetReadDeadline(deadline)
+				}
+				r.Body = &transferBody{ReadCloser: r.Body, extend: extend, timeout: timeout, extended: now}
+			}
+			next.ServeHTTP(&transferWriter{ResponseWriter: w, extend: rc.SetWriteDeadline, timeout: timeout, extended: now}, r)
+		})
+	}
+}
+
+// transferBody extends the deadlines as the request body is read
+type transferBody struct {
+	io.ReadCloser
+	extend   func(time.Time) error
+	timeout  time.Duration
+	extended time.Time
+}
+
+func (b *transferBody) Read(p []byte) (int, error) {
+	if now := time.Now(); now.Sub(b.extended) >= deadlineStep {
+		b.extend(now.Add(b.timeout))
+		b.extended = now
+	}
+	return b.ReadCloser.Read(p)
+}
+
+// transferWriter extends the write deadline as the response is written
+type transferWriter struct {
+	http.ResponseWriter
+	extend   func(time.Time) error
+	timeout  time.Duration
+	extended time.Time
+}
+
+func (w *transferWriter) Write(p []byte) (int, error) {
+	if now := time.Now(); now.Sub(w.extended) >= deadlineStep {
+		w.extend(now.Add(w.timeout))
+		w.extended = now
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController
+func (w *transferWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// The server's timeouts stand in for the 15s defaults, scaled down so the
+// tests run in seconds. Transfers last several times longer than them.
+const (
+	serverTimeout   = time.Second
+	transferTimeout = 2 * time.Second
+	transferLength  = 3500 * time.Millisecond
+	socketBuffer    = 64 * 1024
+)
+
+// smallBufferListener shrinks the send and receive buffers of accepted
+// connections, so a slow client holds the server's writes back instead of
+// the kernel buffering the whole response
+type smallBufferListener struct {
+	net.Listener
+}
+
+func (l smallBufferListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		tcp.SetWriteBuffer(socketBuffer)
+		tcp.SetReadBuffer(socketBuffer)
+	}
+	return conn, nil
+}
+
+// newTimeoutServer starts a server with short read and write timeouts, like
+// the production server's
+func newTimeoutServer(t *testing.T, handler http.Handler) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewUnstartedServer(handler)
+	ts.Listener = smallBufferListener{ts.Listener}
+	ts.Config.ReadTimeout = serverTimeout
+	ts.Config.WriteTimeout = serverTimeout
+	ts.Start()
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// newSlowClient returns a client whose connections have small receive buffers
+func newSlowClient() *http.Client {
+	dialer := &net.Dialer{}
+	return &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, network, addr)
+			if tcp, ok := conn.(*net.TCPConn); ok {
+				tcp.SetReadBuffer(socketBuffer)
+				tcp.SetWriteBuffer(socketBuffer)
+			}
+			return conn, err
+		},
+	}}
+}
+
+// streamHandler writes size bytes in small flushed chunks, like the
+// progressive stream handler
+func streamHandler(size int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(size))
+		chunk := bytes.Repeat([]byte("x"), 4096)
+		for written := 0; written < size; written += len(chunk) {
+			if _, err := w.Write(chunk); err != nil {
+				return
+			}
+			http.NewResponseController(w).Flush()
+		}
+	})
+}
+
+// readSlowly reads body in 16 KB reads spread over transferLength, returning
+// how many bytes it got
+func readSlowly(body io.Reader, size int) (int, error) {
+	const readSize = 16 * 1024
+	pause := transferLength / time.Duration(size/readSize)
+
+	buf := make([]byte, readSize)
+	total := 0
+	for {
+		n, err := io.ReadFull(body, buf)
+		total += n
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			if total < size {
+				return total, fmt.Errorf("body ended after %d of %d bytes", total, size)
+			}
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+		time.Sleep(pause)
+	}
+}
+
+func TestTransferSlowReader(t *testing.T) {
+	const size = 512 * 1024
+
+	tests := []struct {
+		name     string
+		handler  http.Handler
+		complete bool
+	}{
+		{"without Transfer the write timeout cuts the stream", streamHandler(size), false},
+		{"with Transfer the stream outlasts the write timeout", Transfer(transferTimeout)(streamHandler(size)), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTimeoutServer(t, tt.handler)
+
+			start := time.Now()
+			resp, err := newSlowClient().Get(ts.URL)
+			if err != nil {
+				t.Fatalf("GET failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			got, err := readSlowly(resp.Body, size)
+			elapsed := time.Since(start)
+
+			if !tt.complete {
+				if err == nil {
+					t.Fatalf("read all %d bytes over %v, want the stream cut after the %v write timeout", got, elapsed, serverTimeout)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("stream failed after %v: %v", elapsed, err)
+			}
+			if elapsed < 2*serverTimeout {
+				t.Fatalf("stream took %v, too fast to show it outlasts the %v write timeout", elapsed, serverTimeout)
+			}
+		})
+	}
+}
+
+// slowBody yields size bytes in 16 KB pieces spread over transferLength,
+// like an upload over a slow connection
+type slowBody struct {
+	remaining int
+	pause     time.Duration
+}
+
+func (b *slowBody) Read(p []byte) (int, error) {
+	if b.remaining == 0 {
+		return 0, io.EOF
+	}
+	time.Sleep(b.pause)
+	n := min(len(p), b.remaining, 16*1024)
+	b.remaining -= n
+	return n, nil
+}
+
+func TestTransferSlowUpload(t *testing.T) {
+	const size = 512 * 1024
+
+	// Counts the bytes uploaded, like the upload handlers reading the body
+	upload := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, err := io.Copy(io.Discard, r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, n)
+	})
+	ts := newTimeoutServer(t, Transfer(transferTimeout)(upload))
+
+	body := &slowBody{remaining: size, pause: transferLength / (size / (16 * 1024))}
+	req, err := http.NewRequest(http.MethodPost, ts.URL, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.ContentLength = size
+
+	start := time.Now()
+	resp, err := newSlowClient().Do(req)
+	if err != nil {
+		t.Fatalf("upload failed after %v: %v", time.Since(start), err)
+	}
+	defer resp.Body.Close()
+
+	got, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(got) != strconv.Itoa(size) {
+		t.Fatalf("got %d %q, want 200 %q", resp.StatusCode, got, strconv.Itoa(size))
+	}
+	if elapsed := time.Since(start); elapsed < 2*serverTimeout {
+		t.Fatalf("upload took %v, too fast to show it outlasts the %v read timeout", elapsed, serverTimeout)
+	}
+}
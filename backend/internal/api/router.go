@@ -7,8 +7,10 @@ import (
 	"github.com/clipset/clipset-go/internal/api/middleware"
 	"github.com/clipset/clipset-go/internal/config"
 	"github.com/clipset/clipset-go/internal/db"
+	"github.com/clipset/clipset-go/internal/services/apitoken"
 	"github.com/clipset/clipset-go/internal/services/auth"
 	"github.com/clipset/clipset-go/internal/services/image"
+	"github.com/clipset/clipset-go/internal/services/session"
 	"github.com/clipset/clipset-go/internal/services/storage"
 	"github.com/clipset/clipset-go/internal/services/upload"
 )
@@ -19,6 +21,8 @@ type Router struct {
 	db         *db.DB
 	config     *config.Config
 	jwtService *auth.JWTService
+	sessions   *session.Store
+	tokens     *apitoken.Store
 
 	// Handlers
 	health      *handlers.HealthHandler
@@ -29,13 +33,23 @@ type Router struct {
 	playlists   *handlers.PlaylistsHandler
 	comments    *handlers.CommentsHandler
 	invitations *handlers.InvitationsHandler
+	audit       *handlers.AuditHandler
+	storage     *handlers.StorageHandler
+	jobs        *handlers.JobsHandler
 	configH     *handlers.ConfigHandler
+	webhooks    *handlers.WebhooksHandler
 }
 
 // NewRouter creates a new router with all dependencies
 func NewRouter(database *db.DB, cfg *config.Config) *Router {
 	// Create JWT service
-	jwtService := auth.NewJWTService(cfg.JWTSecret, cfg.JWTExpiryHours)
+	jwtService := auth.NewJWTServiceWithKeys(cfg.JWTSecrets, cfg.JWTExpiryHours)
+
+	// Create session store (checked on every authenticated request)
+	sessions := session.NewStore(database.Queries, cfg.SessionCacheTTL)
+
+	// Create personal access token store
+	tokens := apitoken.NewStore(database.Queries)
 
 	// Create shared image processor
 	imgProcessor := image.NewProcessor(image.ProcessorConfig{
@@ -67,7 +81,7 @@ func NewRouter(database *db.DB, cfg *config.Config) *Router {
 	}
 
 	// Create chunked upload manager
-	chunkManager := upload.NewChunkedUploadManager(cfg.ChunksStoragePath)
+	chunkManager := upload.NewChunkedUploadManager(cfg.ChunksStoragePath, cfg.ChunkSessionTTL)
 	if err := chunkManager.EnsureBasePath(); err != nil {
 		panic("failed to create chunks directory: " + err.Error())
 	}
@@ -77,15 +91,21 @@ func NewRouter(database *db.DB, cfg *config.Config) *Router {
 		db:          database,
 		config:      cfg,
 		jwtService:  jwtService,
+		sessions:    sessions,
+		tokens:      tokens,
 		health:      handlers.NewHealthHandler(),
-		auth:        handlers.NewAuthHandler(database, jwtService),
-		users:       handlers.NewUsersHandler(database, cfg, imgProcessor),
+		auth:        handlers.NewAuthHandler(database, cfg, jwtService, sessions),
+		users:       handlers.NewUsersHandler(database, cfg, imgProcessor, sessions),
 		categories:  handlers.NewCategoriesHandler(database, cfg, imgProcessor),
 		videos:      handlers.NewVideosHandler(database, cfg, videoStorage, chunkManager),
 		playlists:   handlers.NewPlaylistsHandler(database, cfg),
 		comments:    handlers.NewCommentsHandler(database, cfg),
 		invitations: handlers.NewInvitationsHandler(database, cfg),
+		audit:       handlers.NewAuditHandler(database, cfg),
+		storage:     handlers.NewStorageHandler(database, cfg),
+		jobs:        handlers.NewJobsHandler(database, cfg),
 		configH:     handlers.NewConfigHandler(database, cfg),
+		webhooks:    handlers.NewWebhooksHandler(database, cfg),
 	}
 
 	r.registerRoutes()
@@ -97,6 +117,21 @@ func (r *Router) VideosHandler() *handlers.VideosHandler {
 	return r.videos
 }
 
+// StorageHandler returns the storage handler for external configuration
+func (r *Router) StorageHandler() *handlers.StorageHandler {
+	return r.storage
+}
+
+// JobsHandler returns the jobs handler for external configuration
+func (r *Router) JobsHandler() *handlers.JobsHandler {
+	return r.jobs
+}
+
+// ConfigHandler returns the config handler for external configuration
+func (r *Router) ConfigHandler() *handlers.ConfigHandler {
+	return r.configH
+}
+
 // registerRoutes registers all HTTP routes
 func (r *Router) registerRoutes() {
 	// Health endpoints (public)
@@ -106,12 +141,20 @@ func (r *Router) registerRoutes() {
 	// Auth routes (public)
 	r.mux.HandleFunc("POST /api/auth/register", r.auth.Register)
 	r.mux.HandleFunc("POST /api/auth/login", r.auth.Login)
+	r.mux.HandleFunc("POST /api/auth/refresh", r.auth.Refresh)
+	r.mux.HandleFunc("GET /api/auth/csrf", r.auth.CSRFToken)
 	r.mux.HandleFunc("POST /api/auth/forgot-password", r.auth.ForgotPassword)
 	r.mux.HandleFunc("GET /api/auth/verify-reset-token", r.auth.VerifyResetToken)
 	r.mux.HandleFunc("POST /api/auth/reset-password", r.auth.ResetPassword)
+	r.mux.HandleFunc("POST /api/auth/confirm-email-change", r.auth.ConfirmEmailChange)
 
 	// Auth routes (authenticated)
 	r.mux.Handle("GET /api/auth/me", r.requireAuth(http.HandlerFunc(r.auth.Me)))
+	r.mux.Handle("GET /api/auth/media-token", r.requireAuth(http.HandlerFunc(r.auth.MediaToken)))
+	r.mux.Handle("GET /api/auth/sessions", r.requireAuth(http.HandlerFunc(r.auth.ListSessions)))
+	r.mux.Handle("DELETE /api/auth/sessions/{session_id}", r.requireAuth(http.HandlerFunc(r.auth.RevokeSession)))
+	r.mux.Handle("POST /api/auth/logout", r.requireAuth(http.HandlerFunc(r.auth.Logout)))
+	r.mux.Handle("POST /api/auth/logout-all", r.requireAuth(http.HandlerFunc(r.auth.LogoutAll)))
 
 	// User routes (admin only)
 	r.mux.Handle("GET /api/users/", r.requireAdmin(http.HandlerFunc(r.users.List)))
@@ -120,32 +163,49 @@ func (r *Router) registerRoutes() {
 	r.mux.Handle("GET /api/users/directory", r.requireAuth(http.HandlerFunc(r.users.Directory)))
 	r.mux.Handle("GET /api/users/by-username/{username}", r.requireAuth(http.HandlerFunc(r.users.GetByUsername)))
 	r.mux.Handle("GET /api/users/{user_id}", r.requireAuth(http.HandlerFunc(r.users.GetByID)))
-	r.mux.Handle("POST /api/users/me/avatar", r.requireAuth(http.HandlerFunc(r.users.UploadAvatar)))
+	r.mux.HandleFunc("GET /api/users/{user_id}/avatar", r.users.ServeAvatar)
+	r.mux.Handle("GET /api/users/{user_id}/storage", r.requireAuth(http.HandlerFunc(r.users.GetStorage)))
+	r.mux.Handle("POST /api/users/me/avatar", r.transfer(r.requireAuth(http.HandlerFunc(r.users.UploadAvatar))))
 	r.mux.Handle("DELETE /api/users/me/avatar", r.requireAuth(http.HandlerFunc(r.users.DeleteAvatar)))
+	r.mux.Handle("PATCH /api/users/me", r.requireAuth(http.HandlerFunc(r.users.UpdateMe)))
+	r.mux.Handle("POST /api/users/me/email", r.requireAuth(http.HandlerFunc(r.users.RequestEmailChange)))
+	r.mux.Handle("PATCH /api/users/me/username", r.requireAuth(http.HandlerFunc(r.auth.ChangeUsername)))
+	r.mux.Handle("GET /api/users/me/export", r.requireAuth(http.HandlerFunc(r.users.Export)))
+	r.mux.Handle("GET /api/users/me/tokens", r.requireAuth(http.HandlerFunc(r.users.ListTokens)))
+	r.mux.Handle("POST /api/users/me/tokens", r.requireAuth(http.HandlerFunc(r.users.CreateToken)))
+	r.mux.Handle("DELETE /api/users/me/tokens/{token_id}", r.requireAuth(http.HandlerFunc(r.users.DeleteToken)))
+	r.mux.Handle("GET /api/users/me/followed-playlists", r.requireAuth(http.HandlerFunc(r.playlists.ListFollowed)))
 
 	// User routes (admin only - management)
 	r.mux.Handle("DELETE /api/users/{user_id}", r.requireAdmin(http.HandlerFunc(r.users.Deactivate)))
 	r.mux.Handle("POST /api/users/{user_id}/activate", r.requireAdmin(http.HandlerFunc(r.users.Activate)))
+	r.mux.Handle("POST /api/users/bulk-status", r.requireAdmin(http.HandlerFunc(r.users.BulkSetStatus)))
 	r.mux.Handle("POST /api/users/{user_id}/generate-reset-link", r.requireAdmin(http.HandlerFunc(r.users.GenerateResetLink)))
+	r.mux.Handle("PATCH /api/users/{user_id}/quota", r.requireAdmin(http.HandlerFunc(r.users.SetQuotaOverride)))
+	r.mux.Handle("GET /api/users/{user_id}/quota/history", r.requireAdmin(http.HandlerFunc(r.users.GetQuotaHistory)))
 
 	// Category routes
 	// Note: Go 1.22 ServeMux has strict conflict detection, so we use a catch-all pattern
 	// to handle both /api/categories/slug/{slug} and /api/categories/{id}/image without conflict
 	// The catch-all handles: /, /{id}, /slug/{slug}, /{id}/image
 	r.mux.Handle("GET /api/categories/{path...}", r.requireAuth(http.HandlerFunc(r.categories.HandleCategoryGet)))
-	r.mux.Handle("POST /api/categories/{path...}", r.requireAdmin(http.HandlerFunc(r.categories.HandleCategoryPost)))
+	r.mux.Handle("POST /api/categories/{path...}", r.transfer(r.requireAdmin(http.HandlerFunc(r.categories.HandleCategoryPost))))
 	r.mux.Handle("PATCH /api/categories/{category_id}", r.requireAdmin(http.HandlerFunc(r.categories.Update)))
 	r.mux.Handle("DELETE /api/categories/{path...}", r.requireAdmin(http.HandlerFunc(r.categories.HandleCategoryDelete)))
 
 	// Video routes (authenticated)
 	// Upload endpoints
-	r.mux.Handle("POST /api/videos/upload", r.requireAuth(http.HandlerFunc(r.videos.Upload)))
+	r.mux.Handle("POST /api/videos/upload", r.transfer(r.requireAuth(http.HandlerFunc(r.videos.Upload))))
+	r.mux.Handle("POST /api/videos/upload/batch", r.transfer(r.requireAuth(http.HandlerFunc(r.videos.UploadBatch))))
 	r.mux.Handle("POST /api/videos/upload/init", r.requireAuth(http.HandlerFunc(r.videos.InitChunkedUpload)))
-	r.mux.Handle("POST /api/videos/upload/chunk", r.requireAuth(http.HandlerFunc(r.videos.UploadChunk)))
+	r.mux.Handle("POST /api/videos/upload/chunk", r.transfer(r.requireAuth(http.HandlerFunc(r.videos.UploadChunk))))
 	r.mux.Handle("POST /api/videos/upload/complete", r.requireAuth(http.HandlerFunc(r.videos.CompleteChunkedUpload)))
+	r.mux.Handle("DELETE /api/videos/upload/{upload_id}", r.requireAuth(http.HandlerFunc(r.videos.AbortChunkedUpload)))
+	r.mux.Handle("POST /api/videos/precheck", r.requireAuth(http.HandlerFunc(r.videos.Precheck)))
 
 	// Quota endpoints
 	r.mux.Handle("GET /api/videos/quota/me", r.requireAuth(http.HandlerFunc(r.videos.GetMyQuota)))
+	r.mux.Handle("GET /api/videos/quota/me/history", r.requireAuth(http.HandlerFunc(r.videos.GetMyQuotaHistory)))
 
 	// Video CRUD endpoints
 	r.mux.Handle("GET /api/videos/", r.requireAuth(http.HandlerFunc(r.videos.List)))
@@ -154,14 +214,28 @@ func (r *Router) registerRoutes() {
 	r.mux.Handle("DELETE /api/videos/{short_id}", r.requireAuth(http.HandlerFunc(r.videos.Delete)))
 
 	// Video streaming endpoints (Phase 7)
-	r.mux.Handle("GET /api/videos/{short_id}/stream", r.requireAuth(http.HandlerFunc(r.videos.Stream)))
-	r.mux.Handle("GET /api/videos/{short_id}/hls/{filename...}", r.requireAuth(http.HandlerFunc(r.videos.HLS)))
+	// GET patterns also match HEAD, so players can probe a stream, segment or
+	// image's size and Range support; the handlers send headers only
+	// Signed stream URLs (for external players) and signed rendition playlist
+	// URLs (listed by master playlists) bypass the auth middleware
+	r.mux.Handle("GET /api/videos/{short_id}/stream", r.transfer(r.videos.AllowSignedStream(r.requireAuth(http.HandlerFunc(r.videos.Stream)))))
+	r.mux.Handle("POST /api/videos/{short_id}/stream-url", r.requireAuth(http.HandlerFunc(r.videos.StreamURL)))
+	r.mux.Handle("GET /api/videos/{short_id}/hls/{filename...}", r.videos.AllowSignedPlaylist(r.requireAuth(http.HandlerFunc(r.videos.HLS))))
+	r.mux.Handle("GET /api/videos/{short_id}/dash/{filename...}", r.requireAuth(http.HandlerFunc(r.videos.DASH)))
+	if r.config.HLSServeSegments {
+		// Segment URLs in manifests are signed, so they need no auth
+		r.mux.Handle("GET /hls/{path...}", r.transfer(http.HandlerFunc(r.videos.HLSSegment)))
+	}
 	r.mux.Handle("GET /api/videos/{short_id}/stream-info", r.requireAuth(http.HandlerFunc(r.videos.StreamInfo)))
 	r.mux.Handle("GET /api/videos/{short_id}/thumbnail", r.requireAuth(http.HandlerFunc(r.videos.Thumbnail)))
+	r.mux.Handle("GET /api/videos/{short_id}/processing-progress", r.requireAuth(http.HandlerFunc(r.videos.ProcessingProgress)))
 	r.mux.Handle("POST /api/videos/{short_id}/view", r.requireAuth(http.HandlerFunc(r.videos.IncrementView)))
 
 	// Video routes (admin only)
 	r.mux.Handle("POST /api/videos/admin/quota/reset-all", r.requireAdmin(http.HandlerFunc(r.videos.ResetAllQuotas)))
+	r.mux.Handle("GET /api/videos/admin/upload-sessions", r.requireAdmin(http.HandlerFunc(r.videos.ListUploadSessions)))
+	r.mux.Handle("GET /api/videos/{short_id}/transcode-log", r.requireAdmin(http.HandlerFunc(r.videos.TranscodeLog)))
+	r.mux.Handle("POST /api/videos/{short_id}/verify", r.requireAdmin(http.HandlerFunc(r.videos.Verify)))
 
 	// Playlist routes (authenticated)
 	// Specific literal paths first to avoid conflicts with {short_id} wildcard
@@ -171,9 +245,14 @@ func (r *Router) registerRoutes() {
 	// Playlist CRUD
 	r.mux.Handle("GET /api/playlists/", r.requireAuth(http.HandlerFunc(r.playlists.GetUserPlaylists))) // Alias for listing user's own playlists
 	r.mux.Handle("POST /api/playlists/", r.requireAuth(http.HandlerFunc(r.playlists.Create)))
+	r.mux.Handle("POST /api/playlists/smart", r.requireAdmin(http.HandlerFunc(r.playlists.CreateSmart)))
 	r.mux.Handle("GET /api/playlists/{short_id}", r.requireAuth(http.HandlerFunc(r.playlists.GetByShortID)))
 	r.mux.Handle("PATCH /api/playlists/{short_id}", r.requireAuth(http.HandlerFunc(r.playlists.Update)))
 	r.mux.Handle("DELETE /api/playlists/{short_id}", r.requireAuth(http.HandlerFunc(r.playlists.Delete)))
+	r.mux.Handle("PATCH /api/playlists/{short_id}/visibility", r.requireAuth(http.HandlerFunc(r.playlists.SetVisibility)))
+	r.mux.Handle("GET /api/playlists/{short_id}/navigate", r.requireAuth(http.HandlerFunc(r.playlists.Navigate)))
+	r.mux.Handle("POST /api/playlists/{short_id}/follow", r.requireAuth(http.HandlerFunc(r.playlists.Follow)))
+	r.mux.Handle("DELETE /api/playlists/{short_id}/follow", r.requireAuth(http.HandlerFunc(r.playlists.Unfollow)))
 
 	// Playlist video management
 	r.mux.Handle("POST /api/playlists/{short_id}/videos/batch", r.requireAuth(http.HandlerFunc(r.playlists.AddVideosBatch)))
@@ -185,8 +264,39 @@ func (r *Router) registerRoutes() {
 	r.mux.Handle("GET /api/videos/{video_id}/comments", r.requireAuth(http.HandlerFunc(r.comments.ListByVideo)))
 	r.mux.Handle("POST /api/videos/{video_id}/comments", r.requireAuth(http.HandlerFunc(r.comments.Create)))
 	r.mux.Handle("GET /api/videos/{video_id}/comment-markers", r.requireAuth(http.HandlerFunc(r.comments.GetMarkers)))
+	r.mux.Handle("GET /api/comments/{comment_id}/replies", r.requireAuth(http.HandlerFunc(r.comments.ListReplies)))
 	r.mux.Handle("PATCH /api/comments/{comment_id}", r.requireAuth(http.HandlerFunc(r.comments.Update)))
+	r.mux.Handle("GET /api/comments/{comment_id}/history", r.requireAuth(http.HandlerFunc(r.comments.History)))
 	r.mux.Handle("DELETE /api/comments/{comment_id}", r.requireAuth(http.HandlerFunc(r.comments.Delete)))
+	r.mux.Handle("POST /api/comments/{comment_id}/report", r.requireAuth(http.HandlerFunc(r.comments.Report)))
+
+	// Comment moderation routes (moderators and admins)
+	r.mux.Handle("GET /api/admin/comment-reports", r.requireModerator(http.HandlerFunc(r.comments.ListReports)))
+	r.mux.Handle("POST /api/admin/comment-reports/{report_id}/resolve", r.requireModerator(http.HandlerFunc(r.comments.ResolveReport)))
+
+	// Audit log (admin only)
+	r.mux.Handle("GET /api/admin/audit-log", r.requireAdmin(http.HandlerFunc(r.audit.List)))
+
+	// Storage (admin only)
+	r.mux.Handle("GET /api/admin/storage-stats", r.requireAdmin(http.HandlerFunc(r.storage.Stats)))
+	r.mux.Handle("POST /api/admin/storage/scan", r.requireAdmin(http.HandlerFunc(r.storage.Scan)))
+	r.mux.Handle("GET /api/admin/storage/scan-report", r.requireAdmin(http.HandlerFunc(r.storage.ScanReport)))
+	r.mux.Handle("POST /api/admin/storage/cleanup", r.requireAdmin(http.HandlerFunc(r.storage.Cleanup)))
+	r.mux.Handle("POST /api/admin/storage/temp-cleanup", r.requireAdmin(http.HandlerFunc(r.storage.TempCleanup)))
+
+	// Job queue (admin only)
+	r.mux.Handle("GET /api/admin/jobs", r.requireAdmin(http.HandlerFunc(r.jobs.List)))
+
+	// Failed videos (admin only)
+	r.mux.Handle("GET /api/admin/videos/failed", r.requireAdmin(http.HandlerFunc(r.videos.ListFailed)))
+	r.mux.Handle("POST /api/admin/videos/failed/retry-all", r.requireAdmin(http.HandlerFunc(r.videos.RetryAllFailed)))
+	r.mux.Handle("POST /api/admin/videos/failed/purge", r.requireAdmin(http.HandlerFunc(r.videos.PurgeFailed)))
+
+	// Webhooks (admin only)
+	r.mux.Handle("POST /api/admin/webhooks", r.requireAdmin(http.HandlerFunc(r.webhooks.Create)))
+	r.mux.Handle("GET /api/admin/webhooks", r.requireAdmin(http.HandlerFunc(r.webhooks.List)))
+	r.mux.Handle("DELETE /api/admin/webhooks/{webhook_id}", r.requireAdmin(http.HandlerFunc(r.webhooks.Delete)))
+	r.mux.Handle("GET /api/admin/webhooks/{webhook_id}/deliveries", r.requireAdmin(http.HandlerFunc(r.webhooks.Deliveries)))
 
 	// Invitation routes
 	// Validate is PUBLIC - no authentication required
@@ -195,22 +305,44 @@ func (r *Router) registerRoutes() {
 	r.mux.Handle("POST /api/invitations/", r.requireAdmin(http.HandlerFunc(r.invitations.Create)))
 	r.mux.Handle("GET /api/invitations/", r.requireAdmin(http.HandlerFunc(r.invitations.List)))
 	r.mux.Handle("DELETE /api/invitations/{invitation_id}", r.requireAdmin(http.HandlerFunc(r.invitations.Delete)))
+	r.mux.Handle("POST /api/invitations/{invitation_id}/resend", r.requireAdmin(http.HandlerFunc(r.invitations.Resend)))
 
 	// Config routes (admin only)
 	r.mux.Handle("GET /api/config/", r.requireAdmin(http.HandlerFunc(r.configH.Get)))
 	r.mux.Handle("PATCH /api/config/", r.requireAdmin(http.HandlerFunc(r.configH.Update)))
+	r.mux.Handle("POST /api/config/validate", r.requireAdmin(http.HandlerFunc(r.configH.Validate)))
+	r.mux.Handle("GET /api/config/history", r.requireAdmin(http.HandlerFunc(r.configH.History)))
+	r.mux.Handle("GET /api/config/export", r.requireAdmin(http.HandlerFunc(r.configH.Export)))
+	r.mux.Handle("POST /api/config/import", r.requireAdmin(http.HandlerFunc(r.configH.Import)))
 	r.mux.Handle("GET /api/config/encoders", r.requireAdmin(http.HandlerFunc(r.configH.GetEncoders)))
 	r.mux.Handle("GET /api/config/hls-migration-status", r.requireAdmin(http.HandlerFunc(r.configH.GetHLSMigrationStatus)))
+	r.mux.Handle("POST /api/config/hls-migration/start", r.requireAdmin(http.HandlerFunc(r.configH.StartHLSMigration)))
+	r.mux.Handle("POST /api/config/hls-migration/cancel", r.requireAdmin(http.HandlerFunc(r.configH.CancelHLSMigration)))
+	r.mux.Handle("POST /api/config/hls-migration/pause", r.requireAdmin(http.HandlerFunc(r.configH.PauseHLSMigration)))
+	r.mux.Handle("POST /api/config/hls-migration/resume", r.requireAdmin(http.HandlerFunc(r.configH.ResumeHLSMigration)))
+	r.mux.Handle("POST /api/config/benchmark", r.requireAdmin(http.HandlerFunc(r.configH.StartBenchmark)))
+	r.mux.Handle("GET /api/config/benchmark-status", r.requireAdmin(http.HandlerFunc(r.configH.GetBenchmarkStatus)))
 }
 
 // requireAuth wraps a handler with authentication middleware
 func (r *Router) requireAuth(handler http.Handler) http.Handler {
-	return middleware.Auth(r.jwtService)(handler)
+	return middleware.Auth(r.jwtService, r.sessions, r.tokens, r.config.AuthCookieMode)(handler)
 }
 
 // requireAdmin wraps a handler with authentication and admin middleware
 func (r *Router) requireAdmin(handler http.Handler) http.Handler {
-	return middleware.Auth(r.jwtService)(middleware.AdminOnly(handler))
+	return middleware.Auth(r.jwtService, r.sessions, r.tokens, r.config.AuthCookieMode)(middleware.AdminOnly(handler))
+}
+
+// requireModerator wraps a handler with authentication and moderator middleware
+func (r *Router) requireModerator(handler http.Handler) http.Handler {
+	return middleware.Auth(r.jwtService, r.sessions, r.tokens, r.config.AuthCookieMode)(middleware.ModeratorOnly(handler))
+}
+
+// transfer wraps a handler that streams or receives large bodies, so the
+// server's timeouts only apply while no bytes move
+func (r *Router) transfer(handler http.Handler) http.Handler {
+	return middleware.Transfer(r.config.HTTPTransferTimeout)(handler)
 }
 
 // Handler returns the HTTP handler with all middleware applied
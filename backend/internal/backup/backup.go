@@ -0,0 +1,487 @@
+// Package backup writes a disaster recovery backup: every table exported to
+// JSON, plus a manifest of the media files the database references with
+// their sizes and checksums, and optionally a tar of those files. A backup's
+// manifest can later be verified against the files on disk or in the tar.
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/clipset/clipset-go/internal/db"
+	"github.com/clipset/clipset-go/internal/db/sqlc"
+	"github.com/clipset/clipset-go/internal/domain"
+	"github.com/clipset/clipset-go/internal/migrate"
+	"github.com/clipset/clipset-go/internal/services/storage"
+	"github.com/clipset/clipset-go/internal/services/video"
+)
+
+// Media that can be left out of a backup with --exclude
+const (
+	ExcludeVideos         = "videos"          // Progressive MP4s and HLS directories
+	ExcludeHLSSegments    = "hls-segments"    // HLS media and init segments; playlists are kept
+	ExcludeThumbnails     = "thumbnails"      // Video thumbnails
+	ExcludeAvatars        = "avatars"         // User avatars
+	ExcludeCategoryImages = "category-images" // Category images
+)
+
+// exclusions lists the valid --exclude values
+var exclusions = []string{ExcludeVideos, ExcludeHLSSegments, ExcludeThumbnails, ExcludeAvatars, ExcludeCategoryImages}
+
+// Names written to the output directory
+const (
+	manifestFile = "manifest.json"
+	databaseDir  = "database"
+	archiveFile  = "media.tar"
+)
+
+// Options holds the backup configuration
+type Options struct {
+	DatabaseURL       string
+	Output            string
+	VideoPath         string // VIDEO_STORAGE_PATH, where videos without a storage_path live
+	ThumbnailPath     string
+	AvatarPath        string
+	CategoryImagePath string
+	Exclude           []string
+	Tar               bool
+}
+
+// ParseExclusions splits a comma-separated --exclude value, rejecting
+// unknown names
+func ParseExclusions(value string) ([]string, error) {
+	result := []string{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !slices.Contains(exclusions, name) {
+			return nil, fmt.Errorf("unknown exclusion %q (valid: %s)", name, strings.Join(exclusions, ", "))
+		}
+		if !slices.Contains(result, name) {
+			result = append(result, name)
+		}
+	}
+	return result, nil
+}
+
+// Run writes a backup to opts.Output, which must be empty or not exist yet.
+// The tables are read in one read-only snapshot, so the dumps and the media
+// manifest agree with each other. Files the database references but that
+// are missing from disk are listed in the summary, not treated as errors.
+func Run(ctx context.Context, opts Options) error {
+	if err := prepareOutput(opts.Output); err != nil {
+		return err
+	}
+
+	PrintHeader(opts.Output, opts.Exclude, opts.Tar)
+
+	database, err := db.Connect(ctx, opts.DatabaseURL)
+	if err != nil {
+		return fmt.Errorf("database connection failed: %w", err)
+	}
+	defer database.Close()
+
+	tx, err := database.Pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	manifest := &Manifest{
+		Version:   manifestVersion,
+		CreatedAt: time.Now().UTC(),
+		Tables:    []TableDump{},
+		Files:     []MediaFile{},
+		Excluded:  opts.Exclude,
+	}
+	if err := tx.QueryRow(ctx, "SELECT version FROM schema_migrations").Scan(&manifest.SchemaVersion); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	// 1. Table dumps
+	tables, err := listTables(ctx, tx)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Exporting tables...")
+	for _, table := range tables {
+		dump, err := dumpTable(ctx, tx, opts.Output, table)
+		if err != nil {
+			return err
+		}
+		manifest.Tables = append(manifest.Tables, dump)
+		PrintTable(dump)
+	}
+
+	// 2. Media files
+	refs, skipped, err := collectMedia(ctx, database.Queries.WithTx(tx), opts)
+	if err != nil {
+		return err
+	}
+	tx.Rollback(ctx)
+
+	fmt.Println()
+	fmt.Printf("Hashing %d media files...\n", len(refs))
+	if skipped > 0 {
+		fmt.Printf("  Skipped %d videos that aren't processed yet\n", skipped)
+	}
+
+	summary := Summary{StartTime: time.Now()}
+	if opts.Tar {
+		manifest.Archive = archiveFile
+	}
+	manifest.Files, summary.Missing, err = writeMedia(ctx, refs, opts)
+	if err != nil {
+		return err
+	}
+	for _, f := range manifest.Files {
+		summary.Bytes += f.Size
+	}
+	summary.Files = len(manifest.Files)
+	summary.Tables = len(manifest.Tables)
+	summary.EndTime = time.Now()
+
+	if err := writeManifest(filepath.Join(opts.Output, manifestFile), manifest); err != nil {
+		return err
+	}
+	summary.Print(filepath.Join(opts.Output, manifestFile))
+	return nil
+}
+
+// prepareOutput creates the output directory, refusing one with files in it
+// so backups are never mixed
+func prepareOutput(dir string) error {
+	entries, err := os.ReadDir(dir)
+	switch {
+	case err == nil && len(entries) > 0:
+		return fmt.Errorf("output directory is not empty: %s", dir)
+	case err != nil && !os.IsNotExist(err):
+		return fmt.Errorf("failed to read output directory: %w", err)
+	}
+
+	// Dumps hold password hashes and tokens, so only the owner may read them
+	if err := os.MkdirAll(filepath.Join(dir, databaseDir), 0700); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	return os.Chmod(dir, 0700)
+}
+
+// listTables returns the application tables, in the migration's dependency
+// order and then by name. River's and golang-migrate's tables are left out.
+func listTables(ctx context.Context, tx pgx.Tx) ([]string, error) {
+	rows, err := tx.Query(ctx, `SELECT table_name FROM information_schema.tables
+		WHERE table_schema = 'public' AND table_type = 'BASE TABLE'
+		ORDER BY table_name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	tables := []string{}
+	for _, table := range migrate.AppTables() {
+		if slices.Contains(names, table) {
+			tables = append(tables, table)
+		}
+	}
+	for _, table := range names {
+		if !migrate.IsExternalTable(table) && !slices.Contains(tables, table) {
+			tables = append(tables, table)
+		}
+	}
+	return tables, nil
+}
+
+// dumpTable writes a table as a JSON array with one row per line
+func dumpTable(ctx context.Context, tx pgx.Tx, output, table string) (TableDump, error) {
+	dump := TableDump{Name: table, File: filepath.ToSlash(filepath.Join(databaseDir, table+".json"))}
+
+	file, err := os.OpenFile(filepath.Join(output, filepath.FromSlash(dump.File)), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return dump, fmt.Errorf("failed to create dump of %s: %w", table, err)
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	w := bufio.NewWriter(io.MultiWriter(file, hasher))
+
+	rows, err := tx.Query(ctx, fmt.Sprintf("SELECT row_to_json(t)::text FROM %s t", pgx.Identifier{table}.Sanitize()))
+	if err != nil {
+		return dump, fmt.Errorf("failed to read %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	w.WriteString("[")
+	for rows.Next() {
+		var row string
+		if err := rows.Scan(&row); err != nil {
+			return dump, fmt.Errorf("failed to read %s: %w", table, err)
+		}
+		if dump.Rows > 0 {
+			w.WriteString(",")
+		}
+		w.WriteString("\n")
+		w.WriteString(row)
+		dump.Rows++
+	}
+	if err := rows.Err(); err != nil {
+		return dump, fmt.Errorf("failed to read %s: %w", table, err)
+	}
+	w.WriteString("\n]\n")
+
+	if err := w.Flush(); err != nil {
+		return dump, fmt.Errorf("failed to write dump of %s: %w", table, err)
+	}
+	if err := file.Sync(); err != nil {
+		return dump, fmt.Errorf("failed to sync dump of %s: %w", table, err)
+	}
+	dump.SHA256 = hex.EncodeToString(hasher.Sum(nil))
+	return dump, nil
+}
+
+// mediaRef is a media file the database references, before it's hashed
+type mediaRef struct {
+	Kind  string
+	Owner string
+	Root  string
+	Path  string // Full path on disk
+}
+
+// collectMedia lists the media files the database references, minus the
+// excluded ones. Videos not processed yet have no output files; it returns
+// how many were skipped.
+func collectMedia(ctx context.Context, q *sqlc.Queries, opts Options) ([]mediaRef, int, error) {
+	refs := []mediaRef{}
+	skipped := 0
+
+	videos, err := q.ListVideoFiles(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list videos: %w", err)
+	}
+	for _, v := range videos {
+		if v.ProcessingStatus != domain.ProcessingStatusCompleted {
+			skipped++
+			continue
+		}
+
+		if !slices.Contains(opts.Exclude, ExcludeVideos) {
+			root := opts.VideoPath
+			if v.StoragePath != nil && *v.StoragePath != "" {
+				root = *v.StoragePath
+			}
+			videoRefs, err := videoMedia(root, v.ID.String(), v.Filename, slices.Contains(opts.Exclude, ExcludeHLSSegments))
+			if err != nil {
+				return nil, 0, err
+			}
+			refs = append(refs, videoRefs...)
+		}
+
+		if v.ThumbnailFilename != nil && *v.ThumbnailFilename != "" && !slices.Contains(opts.Exclude, ExcludeThumbnails) {
+			refs = append(refs, mediaRef{
+				Kind:  KindThumbnail,
+				Owner: v.ID.String(),
+				Root:  opts.ThumbnailPath,
+				Path:  storage.ResolvePath(opts.ThumbnailPath, *v.ThumbnailFilename),
+			})
+
+			// Videos processed before WebP variants have none; not missing
+			if webp := storage.ResolvePath(opts.ThumbnailPath, storage.WebPThumbnailName(*v.ThumbnailFilename)); storage.FileExists(webp) {
+				refs = append(refs, mediaRef{Kind: KindThumbnail, Owner: v.ID.String(), Root: opts.ThumbnailPath, Path: webp})
+			}
+		}
+	}
+
+	if !slices.Contains(opts.Exclude, ExcludeAvatars) {
+		users, err := q.ListUserAvatarFiles(ctx)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to list avatars: %w", err)
+		}
+		for _, u := range users {
+			refs = append(refs, mediaRef{
+				Kind:  KindAvatar,
+				Owner: u.ID.String(),
+				Root:  opts.AvatarPath,
+				Path:  filepath.Join(opts.AvatarPath, *u.AvatarFilename),
+			})
+		}
+	}
+
+	if !slices.Contains(opts.Exclude, ExcludeCategoryImages) {
+		categories, err := q.ListCategoryImageFiles(ctx)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to list category images: %w", err)
+		}
+		for _, c := range categories {
+			refs = append(refs, mediaRef{
+				Kind:  KindCategoryImage,
+				Owner: c.ID.String(),
+				Root:  opts.CategoryImagePath,
+				Path:  filepath.Join(opts.CategoryImagePath, *c.ImageFilename),
+			})
+		}
+	}
+
+	return refs, skipped, nil
+}
+
+// videoMedia lists a processed video's output: its progressive MP4 and any
+// lower quality files, or every file of its HLS directory
+func videoMedia(root, owner, filename string, skipSegments bool) ([]mediaRef, error) {
+	if strings.EqualFold(filepath.Ext(filename), ".mp4") {
+		refs := []mediaRef{{Kind: KindVideo, Owner: owner, Root: root, Path: storage.ResolvePath(root, filename)}}
+		qualityDir := storage.ResolvePath(root, storage.GetHLSDirectoryName(filename))
+		for _, quality := range video.RenditionResolutions {
+			path := filepath.Join(qualityDir, storage.ProgressiveQualityName(quality))
+			if storage.FileExists(path) {
+				refs = append(refs, mediaRef{Kind: KindVideo, Owner: owner, Root: root, Path: path})
+			}
+		}
+		return refs, nil
+	}
+
+	hlsDir := storage.ResolvePath(root, storage.GetHLSDirectoryName(filename))
+	if _, err := os.Stat(hlsDir); err != nil {
+		// Reported as missing like any other file
+		return []mediaRef{{Kind: KindHLS, Owner: owner, Root: root, Path: filepath.Join(hlsDir, "master.m3u8")}}, nil
+	}
+
+	refs := []mediaRef{}
+	err := filepath.WalkDir(hlsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if skipSegments && isHLSSegment(d.Name()) {
+			return nil
+		}
+		refs = append(refs, mediaRef{Kind: KindHLS, Owner: owner, Root: root, Path: path})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list HLS files of video %s: %w", owner, err)
+	}
+	return refs, nil
+}
+
+// isHLSSegment reports whether an HLS file is a media or init segment
+// rather than a playlist
+func isHLSSegment(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".ts" || ext == ".m4s" || name == "init.mp4"
+}
+
+// writeMedia hashes each referenced file and, with opts.Tar, adds it to the
+// media tar in the same read. It returns the files found and the paths of
+// those missing.
+func writeMedia(ctx context.Context, refs []mediaRef, opts Options) ([]MediaFile, []string, error) {
+	var archive *os.File
+	var buffered *bufio.Writer
+	var tw *tar.Writer
+	if opts.Tar {
+		var err error
+		archive, err = os.OpenFile(filepath.Join(opts.Output, archiveFile), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create media archive: %w", err)
+		}
+		defer archive.Close()
+		buffered = bufio.NewWriterSize(archive, 1<<20)
+		tw = tar.NewWriter(buffered)
+	}
+
+	files := []MediaFile{}
+	missing := []string{}
+	for i, ref := range refs {
+		if ctx.Err() != nil {
+			return nil, nil, errors.New("interrupted; delete the output directory and rerun the backup")
+		}
+
+		rel, err := filepath.Rel(ref.Root, ref.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+		file := MediaFile{Kind: ref.Kind, Owner: ref.Owner, Root: ref.Root, Path: filepath.ToSlash(rel)}
+
+		file.Size, file.SHA256, err = hashMedia(ref.Path, file.ArchiveName(), tw)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				missing = append(missing, ref.Path)
+				PrintMissing(ref)
+				continue
+			}
+			return nil, nil, fmt.Errorf("%s: %w", ref.Path, err)
+		}
+		files = append(files, file)
+
+		if (i+1)%250 == 0 {
+			fmt.Printf("  %d/%d files\n", i+1, len(refs))
+		}
+	}
+
+	if tw != nil {
+		if err := tw.Close(); err != nil {
+			return nil, nil, fmt.Errorf("failed to finish media archive: %w", err)
+		}
+		if err := buffered.Flush(); err != nil {
+			return nil, nil, fmt.Errorf("failed to finish media archive: %w", err)
+		}
+		if err := archive.Sync(); err != nil {
+			return nil, nil, fmt.Errorf("failed to sync media archive: %w", err)
+		}
+	}
+	return files, missing, nil
+}
+
+// hashMedia returns a file's size and hex SHA-256. With tw set, the file is
+// also written to the tar under name.
+func hashMedia(path, name string, tw *tar.Writer) (int64, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, "", err
+	}
+
+	hasher := sha256.New()
+	var dst io.Writer = hasher
+	if tw != nil {
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return 0, "", err
+		}
+		header.Name = name
+		if err := tw.WriteHeader(header); err != nil {
+			return 0, "", fmt.Errorf("failed to add to media archive: %w", err)
+		}
+		dst = io.MultiWriter(tw, hasher)
+	}
+
+	written, err := io.Copy(dst, file)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to read: %w", err)
+	}
+	if written != info.Size() {
+		return 0, "", fmt.Errorf("file changed while backing up: expected %d bytes, read %d", info.Size(), written)
+	}
+	return written, hex.EncodeToString(hasher.Sum(nil)), nil
+}
@@ -0,0 +1,94 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"time"
+)
+
+// manifestVersion is bumped when the manifest format changes
+const manifestVersion = 1
+
+// Kinds of media files
+const (
+	KindVideo         = "video"          // Progressive MP4
+	KindHLS           = "hls"            // File of an HLS directory
+	KindThumbnail     = "thumbnail"      // Video thumbnail
+	KindAvatar        = "avatar"         // User avatar
+	KindCategoryImage = "category_image" // Category image
+)
+
+// Manifest describes a backup: the table dumps and every media file the
+// database referenced when it was taken
+type Manifest struct {
+	Version       int         `json:"version"`
+	CreatedAt     time.Time   `json:"created_at"`
+	SchemaVersion int64       `json:"schema_version"` // Last applied database migration
+	Tables        []TableDump `json:"tables"`
+	Files         []MediaFile `json:"files"`
+	Excluded      []string    `json:"excluded"`          // Media left out with --exclude
+	Archive       string      `json:"archive,omitempty"` // Tar of the media files, if written
+}
+
+// TableDump is one table exported as a JSON array of rows
+type TableDump struct {
+	Name   string `json:"name"`
+	File   string `json:"file"` // Relative to the manifest
+	Rows   int64  `json:"rows"`
+	SHA256 string `json:"sha256"`
+}
+
+// MediaFile is one media file a database row references
+type MediaFile struct {
+	Kind   string `json:"kind"`
+	Owner  string `json:"owner"` // ID of the video, user or category referencing it
+	Root   string `json:"root"`  // Storage directory the file is in
+	Path   string `json:"path"`  // Relative to Root, with forward slashes
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ArchiveName returns the file's name inside the media tar
+func (f MediaFile) ArchiveName() string {
+	dir := "videos"
+	switch f.Kind {
+	case KindThumbnail:
+		dir = "thumbnails"
+	case KindAvatar:
+		dir = "avatars"
+	case KindCategoryImage:
+		dir = "category-images"
+	}
+	return path.Join(dir, f.Path)
+}
+
+// writeManifest writes the manifest as indented JSON
+func writeManifest(filename string, m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := os.WriteFile(filename, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// ReadManifest reads a backup manifest
+func ReadManifest(filename string) (*Manifest, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if m.Version != manifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version %d (expected %d)", m.Version, manifestVersion)
+	}
+	return &m, nil
+}
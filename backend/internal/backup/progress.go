@@ -0,0 +1,78 @@
+package backup
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Summary holds the result of a backup
+type Summary struct {
+	Tables    int
+	Files     int
+	Bytes     int64
+	Missing   []string
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// Print prints the backup summary
+func (s *Summary) Print(manifestPath string) {
+	fmt.Println()
+	fmt.Println("Backup finished.")
+	fmt.Println("=====================================")
+	fmt.Printf("Tables exported: %d\n", s.Tables)
+	fmt.Printf("Media files:     %d (%s)\n", s.Files, formatBytes(s.Bytes))
+	if len(s.Missing) > 0 {
+		fmt.Printf("Missing files:   %d (referenced, but not on disk)\n", len(s.Missing))
+	}
+	fmt.Printf("Media time:      %s\n", s.EndTime.Sub(s.StartTime).Round(time.Second))
+	fmt.Printf("Manifest:        %s\n", manifestPath)
+}
+
+// PrintHeader prints the backup header
+func PrintHeader(output string, exclude []string, tar bool) {
+	fmt.Println("Clipset Backup")
+	fmt.Println("=====================================")
+	fmt.Printf("  %-9s %s\n", "Output:", output)
+	if len(exclude) > 0 {
+		fmt.Printf("  %-9s %s\n", "Exclude:", strings.Join(exclude, ", "))
+	}
+	if tar {
+		fmt.Printf("  %-9s %s\n", "Archive:", archiveFile)
+	}
+	fmt.Println()
+}
+
+// PrintTable prints an exported table
+func PrintTable(dump TableDump) {
+	fmt.Printf("  %-28s %d rows\n", dump.Name, dump.Rows)
+}
+
+// PrintMissing prints a referenced media file that isn't on disk
+func PrintMissing(ref mediaRef) {
+	fmt.Printf("  MISSING %s %s (%s)\n", ref.Kind, ref.Path, ref.Owner)
+}
+
+// PrintDrift prints a media file or table dump that doesn't match the manifest
+func PrintDrift(status, kind, path, owner string) {
+	if owner == "" {
+		fmt.Printf("  %-8s %s %s\n", status, kind, path)
+		return
+	}
+	fmt.Printf("  %-8s %s %s (%s)\n", status, kind, path, owner)
+}
+
+// formatBytes formats a byte count in binary units
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
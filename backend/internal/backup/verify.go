@@ -0,0 +1,210 @@
+package backup
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/clipset/clipset-go/internal/services/storage"
+)
+
+// VerifyOptions holds the verify configuration
+type VerifyOptions struct {
+	Manifest string
+	Archive  bool // Check the media tar instead of the storage directories
+}
+
+// verifyResult counts what a verify found
+type verifyResult struct {
+	Checked int
+	Missing int
+	Changed int
+}
+
+// drifted reports whether anything didn't match the manifest
+func (r verifyResult) drifted() bool {
+	return r.Missing > 0 || r.Changed > 0
+}
+
+// Verify recomputes the checksums of a backup's table dumps and of its media
+// files, on disk or in the media tar, and reports every one that's missing or
+// doesn't match the manifest. It returns an error if any drifted.
+func Verify(ctx context.Context, opts VerifyOptions) error {
+	manifest, err := ReadManifest(opts.Manifest)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(opts.Manifest)
+
+	fmt.Println("Clipset Backup Verify")
+	fmt.Println("=====================================")
+	fmt.Printf("  %-9s %s\n", "Manifest:", opts.Manifest)
+	fmt.Printf("  %-9s %s\n", "Created:", manifest.CreatedAt.Format("2006-01-02 15:04:05 MST"))
+	if opts.Archive {
+		fmt.Printf("  %-9s %s\n", "Archive:", manifest.Archive)
+	}
+	fmt.Println()
+
+	var result verifyResult
+
+	fmt.Println("Checking table dumps...")
+	for _, dump := range manifest.Tables {
+		if ctx.Err() != nil {
+			return errors.New("interrupted")
+		}
+		result.Checked++
+		_, sum, err := hashFile(filepath.Join(dir, filepath.FromSlash(dump.File)))
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			result.Missing++
+			PrintDrift("MISSING", "table", dump.File, "")
+		case err != nil:
+			return fmt.Errorf("%s: %w", dump.File, err)
+		case sum != dump.SHA256:
+			result.Changed++
+			PrintDrift("CHANGED", "table", dump.File, "")
+		}
+	}
+
+	fmt.Printf("Checking %d media files...\n", len(manifest.Files))
+	if opts.Archive {
+		if manifest.Archive == "" {
+			return errors.New("backup has no media archive; it was taken without --tar")
+		}
+		err = verifyArchive(ctx, filepath.Join(dir, manifest.Archive), manifest.Files, &result)
+	} else {
+		err = verifyFiles(ctx, manifest.Files, &result)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Println("Verify finished.")
+	fmt.Println("=====================================")
+	fmt.Printf("Checked: %d\n", result.Checked)
+	fmt.Printf("Missing: %d\n", result.Missing)
+	fmt.Printf("Changed: %d\n", result.Changed)
+
+	if result.drifted() {
+		return fmt.Errorf("backup drifted: %d missing, %d changed", result.Missing, result.Changed)
+	}
+	return nil
+}
+
+// verifyFiles checks the media files in the storage directories. A file
+// moved between the flat and sharded layouts since the backup still counts.
+func verifyFiles(ctx context.Context, files []MediaFile, result *verifyResult) error {
+	for _, f := range files {
+		if ctx.Err() != nil {
+			return errors.New("interrupted")
+		}
+		result.Checked++
+
+		size, sum, err := hashFile(filepath.Join(f.Root, filepath.FromSlash(f.Path)))
+		if errors.Is(err, fs.ErrNotExist) {
+			if alt := alternatePath(f); alt != "" {
+				size, sum, err = hashFile(filepath.Join(f.Root, filepath.FromSlash(alt)))
+			}
+		}
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%s: %w", f.Path, err)
+		}
+		checkMedia(f, err == nil, size, sum, result)
+	}
+	return nil
+}
+
+// verifyArchive checks the media files in the backup's tar
+func verifyArchive(ctx context.Context, archive string, files []MediaFile, result *verifyResult) error {
+	file, err := os.Open(archive)
+	if err != nil {
+		return fmt.Errorf("failed to open media archive: %w", err)
+	}
+	defer file.Close()
+
+	type entry struct {
+		size int64
+		sum  string
+	}
+	entries := make(map[string]entry)
+
+	tr := tar.NewReader(file)
+	for {
+		if ctx.Err() != nil {
+			return errors.New("interrupted")
+		}
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read media archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		hasher := sha256.New()
+		size, err := io.Copy(hasher, tr)
+		if err != nil {
+			return fmt.Errorf("failed to read %s from media archive: %w", header.Name, err)
+		}
+		entries[header.Name] = entry{size: size, sum: hex.EncodeToString(hasher.Sum(nil))}
+	}
+
+	for _, f := range files {
+		result.Checked++
+		e, ok := entries[f.ArchiveName()]
+		checkMedia(f, ok, e.size, e.sum, result)
+	}
+	return nil
+}
+
+// checkMedia compares a media file with its manifest entry, printing and
+// counting any drift
+func checkMedia(f MediaFile, found bool, size int64, sum string, result *verifyResult) {
+	switch {
+	case !found:
+		result.Missing++
+		PrintDrift("MISSING", f.Kind, f.Path, f.Owner)
+	case size != f.Size:
+		result.Changed++
+		PrintDrift("SIZE", f.Kind, f.Path, f.Owner)
+	case sum != f.SHA256:
+		result.Changed++
+		PrintDrift("CHECKSUM", f.Kind, f.Path, f.Owner)
+	}
+}
+
+// alternatePath returns where a video, HLS file or thumbnail would be in the
+// other storage layout: flat if the manifest has it sharded, and sharded if
+// the manifest has it flat. It returns "" for files that are never sharded.
+func alternatePath(f MediaFile) string {
+	if f.Kind != KindVideo && f.Kind != KindHLS && f.Kind != KindThumbnail {
+		return ""
+	}
+
+	parts := strings.Split(f.Path, "/")
+	if len(parts) >= 3 && storage.ShardDir(parts[2]) == path.Join(parts[0], parts[1]) {
+		return path.Join(parts[2:]...)
+	}
+	if shard := storage.ShardDir(parts[0]); shard != "" {
+		return path.Join(shard, f.Path)
+	}
+	return ""
+}
+
+// hashFile returns a file's size and hex SHA-256
+func hashFile(filename string) (int64, string, error) {
+	return hashMedia(filename, "", nil)
+}
@@ -18,9 +18,28 @@ type Config struct {
 	DatabaseURL string `env:"DATABASE_URL,required"`
 
 	// JWT settings
-	JWTSecret      string        `env:"JWT_SECRET,required"`
+	// Comma-separated; the first secret signs new tokens, older ones still verify during rotation
+	JWTSecrets     []string      `env:"JWT_SECRET,required" envSeparator:","`
 	JWTExpiryHours time.Duration `env:"JWT_EXPIRY_HOURS" envDefault:"720h"` // 30 days
 
+	// Refresh tokens outlive access tokens; each use extends the session by this much
+	RefreshTokenExpiry time.Duration `env:"REFRESH_TOKEN_EXPIRY" envDefault:"2160h"` // 90 days
+
+	// Cookie mode keeps tokens in httpOnly cookies instead of handing them to
+	// scripts; Bearer headers keep working for API clients either way
+	AuthCookieMode   bool `env:"AUTH_COOKIE_MODE" envDefault:"false"`
+	AuthCookieSecure bool `env:"AUTH_COOKIE_SECURE" envDefault:"true"` // Disable only for plain-HTTP development
+
+	// Password policy for new passwords; the defaults only require a minimum length
+	PasswordMinLength        int  `env:"PASSWORD_MIN_LENGTH" envDefault:"8"`
+	PasswordRequireMixedCase bool `env:"PASSWORD_REQUIRE_MIXED_CASE" envDefault:"false"`
+	PasswordRequireDigit     bool `env:"PASSWORD_REQUIRE_DIGIT" envDefault:"false"`
+	PasswordRequireSymbol    bool `env:"PASSWORD_REQUIRE_SYMBOL" envDefault:"false"`
+	PasswordRejectCommon     bool `env:"PASSWORD_REJECT_COMMON" envDefault:"false"`
+
+	// How long a session validity check is cached before re-checking the DB
+	SessionCacheTTL time.Duration `env:"SESSION_CACHE_TTL" envDefault:"30s"`
+
 	// Storage paths
 	VideoStoragePath         string `env:"VIDEO_STORAGE_PATH" envDefault:"./data/uploads/videos"`
 	ThumbnailStoragePath     string `env:"THUMBNAIL_STORAGE_PATH" envDefault:"./data/uploads/thumbnails"`
@@ -29,6 +48,24 @@ type Config struct {
 	CategoryImageStoragePath string `env:"CATEGORY_IMAGE_STORAGE_PATH" envDefault:"./data/uploads/category-images"`
 	AvatarStoragePath        string `env:"AVATAR_STORAGE_PATH" envDefault:"./data/uploads/avatars"`
 
+	// Chunked upload sessions unused for this long are considered abandoned
+	ChunkSessionTTL time.Duration `env:"CHUNK_SESSION_TTL" envDefault:"24h"`
+
+	// How often the worker re-measures storage directory usage
+	StorageStatsInterval time.Duration `env:"STORAGE_STATS_INTERVAL" envDefault:"15m"`
+
+	// How often the worker removes stale files from the temp directory, and
+	// how old a file must be to count as stale
+	TempCleanupInterval time.Duration `env:"TEMP_CLEANUP_INTERVAL" envDefault:"1h"`
+	TempFileMaxAge      time.Duration `env:"TEMP_FILE_MAX_AGE" envDefault:"24h"`
+
+	// Uploads and transcodes are refused when they would leave less free disk
+	// space than this on the storage volume
+	MinFreeDiskBytes int64 `env:"MIN_FREE_DISK_BYTES" envDefault:"1073741824"` // 1GB
+
+	// Number of replies returned inline with each top-level comment
+	CommentReplyPreviewCount int `env:"COMMENT_REPLY_PREVIEW_COUNT" envDefault:"3"`
+
 	// Initial admin credentials (for first startup)
 	InitialAdminEmail    string `env:"INITIAL_ADMIN_EMAIL" envDefault:"admin@example.com"`
 	InitialAdminUsername string `env:"INITIAL_ADMIN_USERNAME" envDefault:"admin"`
@@ -37,9 +74,24 @@ type Config struct {
 	// HLS signing (required for secure video streaming)
 	HLSSigningSecret string `env:"HLS_SIGNING_SECRET,required"`
 
+	// Previous HLS signing secret, still accepted while rotating to a new one
+	HLSSigningSecretPrevious string `env:"HLS_SIGNING_SECRET_PREVIOUS"`
+
+	// Serve signed HLS segments (/hls/...) from the backend instead of nginx,
+	// for deployments without nginx secure_link
+	HLSServeSegments bool `env:"HLS_SERVE_SEGMENTS" envDefault:"false"`
+
 	// Streaming settings
 	StreamChunkSize int `env:"STREAM_CHUNK_SIZE_BYTES" envDefault:"65536"` // 64KB default
 
+	// Lifetime of signed stream URLs handed to external players
+	StreamURLExpiry time.Duration `env:"STREAM_URL_EXPIRY" envDefault:"4h"`
+
+	// Internal nginx location mapped to the video storage path. When set,
+	// progressive streams are handed to nginx with X-Accel-Redirect instead
+	// of being copied through the backend.
+	StreamAccelRedirectPrefix string `env:"STREAM_ACCEL_REDIRECT_PREFIX"`
+
 	// CORS
 	CORSOrigins []string `env:"CORS_ORIGINS" envSeparator:"," envDefault:"http://localhost:5173,http://localhost:3000"`
 
@@ -59,20 +111,38 @@ type Config struct {
 	MaxFileSizeBytes     int64    `env:"MAX_FILE_SIZE_BYTES" envDefault:"2147483648"`       // 2GB fallback
 	WeeklyUploadLimit    int64    `env:"WEEKLY_UPLOAD_LIMIT_BYTES" envDefault:"4294967296"` // 4GB fallback
 
+	// Weekday (e.g. "monday") on which every quota resets at 00:00 UTC.
+	// Empty resets each user 7 days after their last reset.
+	QuotaResetDay string `env:"QUOTA_RESET_DAY"`
+
 	// FFmpeg settings
 	FFmpegPath             string        `env:"FFMPEG_PATH" envDefault:"ffmpeg"`
 	FFprobePath            string        `env:"FFPROBE_PATH" envDefault:"ffprobe"`
-	VideoProcessingTimeout time.Duration `env:"VIDEO_PROCESSING_TIMEOUT" envDefault:"2h"`
+	VideoProcessingTimeout time.Duration `env:"VIDEO_PROCESSING_TIMEOUT" envDefault:"2h"` // When a video's duration is unknown
+
+	// A transcode may take the base plus the factor times the video's
+	// duration (e.g. 3 allows 3 minutes per minute of video), up to the max
+	VideoProcessingTimeoutBase   time.Duration `env:"VIDEO_PROCESSING_TIMEOUT_BASE" envDefault:"10m"`
+	VideoProcessingTimeoutFactor float64       `env:"VIDEO_PROCESSING_TIMEOUT_FACTOR" envDefault:"3"`
+	VideoProcessingTimeoutMax    time.Duration `env:"VIDEO_PROCESSING_TIMEOUT_MAX" envDefault:"12h"`
+
+	// Transcode concurrency (fallback defaults - actual limits come from DB config)
+	WorkerConcurrency int `env:"WORKER_CONCURRENCY" envDefault:"1"` // Transcodes at once
+	GPUConcurrency    int `env:"GPU_CONCURRENCY" envDefault:"1"`    // GPU transcodes at once
 
 	// Environment
 	Environment string `env:"ENVIRONMENT" envDefault:"development"`
 
 	// HTTP Server Timeouts
-	HTTPReadTimeout  time.Duration `env:"HTTP_READ_TIMEOUT" envDefault:"10m"`  // For large uploads
-	HTTPWriteTimeout time.Duration `env:"HTTP_WRITE_TIMEOUT" envDefault:"5m"`  // For video streaming
-	HTTPIdleTimeout  time.Duration `env:"HTTP_IDLE_TIMEOUT" envDefault:"120s"` // Keep-alive
+	HTTPReadTimeout     time.Duration `env:"HTTP_READ_TIMEOUT" envDefault:"1m"`     // Whole request, outside streams and uploads
+	HTTPWriteTimeout    time.Duration `env:"HTTP_WRITE_TIMEOUT" envDefault:"2m"`    // Whole response, outside streams and uploads
+	HTTPIdleTimeout     time.Duration `env:"HTTP_IDLE_TIMEOUT" envDefault:"120s"`   // Keep-alive
+	HTTPTransferTimeout time.Duration `env:"HTTP_TRANSFER_TIMEOUT" envDefault:"2m"` // Streams and uploads: longest without progress
 }
 
+// MaxWorkerConcurrency is the most transcodes the worker runs at once
+const MaxWorkerConcurrency = 8
+
 // Load reads configuration from environment variables
 func Load() (*Config, error) {
 	cfg := &Config{}
@@ -81,14 +151,79 @@ func Load() (*Config, error) {
 	}
 
 	// Validate required fields
-	if len(cfg.JWTSecret) < 32 {
-		return nil, fmt.Errorf("JWT_SECRET must be at least 32 characters")
+	for i, secret := range cfg.JWTSecrets {
+		cfg.JWTSecrets[i] = strings.TrimSpace(secret)
+		if len(cfg.JWTSecrets[i]) < 32 {
+			return nil, fmt.Errorf("each JWT_SECRET must be at least 32 characters")
+		}
+	}
+
+	if cfg.PasswordMinLength < 1 {
+		return nil, fmt.Errorf("PASSWORD_MIN_LENGTH must be at least 1")
+	}
+
+	if cfg.RefreshTokenExpiry < cfg.JWTExpiryHours {
+		return nil, fmt.Errorf("REFRESH_TOKEN_EXPIRY must not be shorter than JWT_EXPIRY_HOURS")
 	}
 
 	if len(cfg.HLSSigningSecret) < 16 {
 		return nil, fmt.Errorf("HLS_SIGNING_SECRET must be at least 16 characters")
 	}
 
+	if cfg.HLSSigningSecretPrevious != "" && len(cfg.HLSSigningSecretPrevious) < 16 {
+		return nil, fmt.Errorf("HLS_SIGNING_SECRET_PREVIOUS must be at least 16 characters")
+	}
+
+	if cfg.StreamURLExpiry <= 0 {
+		return nil, fmt.Errorf("STREAM_URL_EXPIRY must be positive")
+	}
+
+	if cfg.StreamAccelRedirectPrefix != "" && !strings.HasPrefix(cfg.StreamAccelRedirectPrefix, "/") {
+		return nil, fmt.Errorf("STREAM_ACCEL_REDIRECT_PREFIX must be a path starting with /")
+	}
+
+	if cfg.HTTPTransferTimeout <= 0 {
+		return nil, fmt.Errorf("HTTP_TRANSFER_TIMEOUT must be positive")
+	}
+
+	if cfg.StorageStatsInterval <= 0 {
+		return nil, fmt.Errorf("STORAGE_STATS_INTERVAL must be positive")
+	}
+
+	if cfg.TempCleanupInterval <= 0 {
+		return nil, fmt.Errorf("TEMP_CLEANUP_INTERVAL must be positive")
+	}
+
+	if cfg.TempFileMaxAge <= 0 {
+		return nil, fmt.Errorf("TEMP_FILE_MAX_AGE must be positive")
+	}
+
+	if cfg.MinFreeDiskBytes < 0 {
+		return nil, fmt.Errorf("MIN_FREE_DISK_BYTES must not be negative")
+	}
+
+	if cfg.QuotaResetDay != "" {
+		if _, ok := parseWeekday(cfg.QuotaResetDay); !ok {
+			return nil, fmt.Errorf("QUOTA_RESET_DAY must be a weekday name, e.g. monday")
+		}
+	}
+
+	if cfg.VideoProcessingTimeout <= 0 || cfg.VideoProcessingTimeoutMax <= 0 {
+		return nil, fmt.Errorf("VIDEO_PROCESSING_TIMEOUT and VIDEO_PROCESSING_TIMEOUT_MAX must be positive")
+	}
+
+	if cfg.VideoProcessingTimeoutBase < 0 || cfg.VideoProcessingTimeoutFactor < 0 {
+		return nil, fmt.Errorf("VIDEO_PROCESSING_TIMEOUT_BASE and VIDEO_PROCESSING_TIMEOUT_FACTOR must not be negative")
+	}
+
+	if cfg.WorkerConcurrency < 1 || cfg.WorkerConcurrency > MaxWorkerConcurrency {
+		return nil, fmt.Errorf("WORKER_CONCURRENCY must be between 1 and %d", MaxWorkerConcurrency)
+	}
+
+	if cfg.GPUConcurrency < 1 || cfg.GPUConcurrency > MaxWorkerConcurrency {
+		return nil, fmt.Errorf("GPU_CONCURRENCY must be between 1 and %d", MaxWorkerConcurrency)
+	}
+
 	// Validate stream chunk size (minimum 8KB, maximum 1MB)
 	if cfg.StreamChunkSize < 8192 {
 		cfg.StreamChunkSize = 8192
@@ -99,6 +234,15 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
+// HLSSigningSecrets returns the secrets signed URLs are checked against. The
+// first is current and signs new URLs; the previous one follows if set.
+func (c *Config) HLSSigningSecrets() []string {
+	if c.HLSSigningSecretPrevious == "" {
+		return []string{c.HLSSigningSecret}
+	}
+	return []string{c.HLSSigningSecret, c.HLSSigningSecretPrevious}
+}
+
 // Address returns the server address
 func (c *Config) Address() string {
 	return fmt.Sprintf("%s:%d", c.Host, c.Port)
@@ -129,3 +273,23 @@ func (c *Config) IsAcceptedVideoFormat(ext string) bool {
 func (c *Config) AcceptedFormatsString() string {
 	return strings.Join(c.AcceptedVideoFormats, ", ")
 }
+
+// QuotaResetWeekday returns the weekday quotas reset on, or false if quotas
+// reset 7 days after each user's last reset
+func (c *Config) QuotaResetWeekday() (time.Weekday, bool) {
+	if c.QuotaResetDay == "" {
+		return 0, false
+	}
+	return parseWeekday(c.QuotaResetDay)
+}
+
+// parseWeekday parses an English weekday name, case-insensitively
+func parseWeekday(name string) (time.Weekday, bool) {
+	name = strings.TrimSpace(name)
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(name, day.String()) {
+			return day, true
+		}
+	}
+	return 0, false
+}
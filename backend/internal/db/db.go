@@ -64,6 +64,24 @@ func Connect(ctx context.Context, databaseURL string) (*DB, error) {
 	}, nil
 }
 
+// InTx runs fn inside a transaction, committing if fn returns nil and rolling back otherwise
+func (db *DB) InTx(ctx context.Context, fn func(q *sqlc.Queries) error) error {
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(db.Queries.WithTx(tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
 // Close closes the database connection pool
 func (db *DB) Close() {
 	if db.Pool != nil {
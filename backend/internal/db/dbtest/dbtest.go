@@ -0,0 +1,70 @@
+// Package dbtest connects tests to a PostgreSQL database.
+package dbtest
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/clipset/clipset-go/internal/db"
+)
+
+// EnvVar names the database URL for tests that need PostgreSQL. Its contents
+// are deleted by every test using it.
+const EnvVar = "TEST_DATABASE_URL"
+
+// Advisory lock held by each test using the database, so packages tested in
+// parallel take turns instead of emptying tables under each other
+const testLockKey = 0x636c6970736574
+
+// Empties every table but the migration versions and the config row
+const truncateAll = `
+DO $$
+DECLARE t record;
+BEGIN
+    FOR t IN SELECT tablename FROM pg_tables
+             WHERE schemaname = current_schema()
+             AND tablename NOT IN ('schema_migrations', 'river_migration', 'config')
+    LOOP
+        EXECUTE 'TRUNCATE TABLE ' || quote_ident(t.tablename) || ' CASCADE';
+    END LOOP;
+END $$`
+
+// Connect migrates the test database and returns a connection to it with
+// every table emptied. The database is the test's until it ends. The test is
+// skipped when TEST_DATABASE_URL isn't set.
+func Connect(t testing.TB) *db.DB {
+	t.Helper()
+
+	databaseURL := os.Getenv(EnvVar)
+	if databaseURL == "" {
+		t.Skipf("%s not set", EnvVar)
+	}
+	ctx := context.Background()
+
+	lockConn, err := pgx.Connect(ctx, databaseURL)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	t.Cleanup(func() { lockConn.Close(ctx) })
+	if _, err := lockConn.Exec(ctx, "SELECT pg_advisory_lock($1)", testLockKey); err != nil {
+		t.Fatalf("failed to lock test database: %v", err)
+	}
+
+	if err := db.RunMigrations(databaseURL); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	database, err := db.Connect(ctx, databaseURL)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	t.Cleanup(database.Close)
+
+	if _, err := database.Pool.Exec(ctx, truncateAll); err != nil {
+		t.Fatalf("failed to empty test database: %v", err)
+	}
+	return database
+}
@@ -0,0 +1,5 @@
+-- Rollback video content hash
+
+DROP INDEX IF EXISTS idx_videos_uploaded_by_content_sha256;
+
+ALTER TABLE videos DROP COLUMN IF EXISTS content_sha256;
@@ -0,0 +1,5 @@
+-- Store the SHA-256 of the originally uploaded file for duplicate detection
+
+ALTER TABLE videos ADD COLUMN content_sha256 TEXT;
+
+CREATE INDEX idx_videos_uploaded_by_content_sha256 ON videos(uploaded_by, content_sha256);
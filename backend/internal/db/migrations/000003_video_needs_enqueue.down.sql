@@ -0,0 +1,5 @@
+-- Rollback video enqueue outbox marker
+
+DROP INDEX IF EXISTS idx_videos_needs_enqueue;
+
+ALTER TABLE videos DROP COLUMN IF EXISTS needs_enqueue;
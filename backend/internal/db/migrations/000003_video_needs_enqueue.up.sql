@@ -0,0 +1,5 @@
+-- Outbox marker for videos whose transcode job has not been enqueued yet
+
+ALTER TABLE videos ADD COLUMN needs_enqueue BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_videos_needs_enqueue ON videos(created_at) WHERE needs_enqueue;
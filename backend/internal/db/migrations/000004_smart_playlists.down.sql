@@ -0,0 +1,11 @@
+-- Rollback smart playlists
+
+DROP INDEX IF EXISTS idx_playlists_smart_category_id;
+
+ALTER TABLE playlists
+    DROP COLUMN IF EXISTS smart_limit,
+    DROP COLUMN IF EXISTS smart_sort,
+    DROP COLUMN IF EXISTS smart_category_id,
+    DROP COLUMN IF EXISTS playlist_type;
+
+DROP TYPE IF EXISTS playlist_type;
@@ -0,0 +1,11 @@
+-- Smart playlists: contents are resolved from a stored rule at read time
+
+CREATE TYPE playlist_type AS ENUM ('manual', 'smart');
+
+ALTER TABLE playlists
+    ADD COLUMN playlist_type playlist_type NOT NULL DEFAULT 'manual',
+    ADD COLUMN smart_category_id UUID REFERENCES categories(id) ON DELETE CASCADE,
+    ADD COLUMN smart_sort TEXT,
+    ADD COLUMN smart_limit INTEGER;
+
+CREATE INDEX idx_playlists_smart_category_id ON playlists(smart_category_id) WHERE smart_category_id IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Rollback playlist followers
+
+DROP TABLE IF EXISTS playlist_followers;
@@ -0,0 +1,11 @@
+-- Users following other users' public playlists
+
+CREATE TABLE playlist_followers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    playlist_id UUID NOT NULL REFERENCES playlists(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(playlist_id, user_id)
+);
+
+CREATE INDEX idx_playlist_followers_user_id ON playlist_followers(user_id, created_at DESC);
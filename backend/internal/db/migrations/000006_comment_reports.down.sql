@@ -0,0 +1,3 @@
+-- Rollback comment reports
+
+DROP TABLE IF EXISTS comment_reports;
@@ -0,0 +1,14 @@
+-- User reports of comments for admin moderation
+
+CREATE TABLE comment_reports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    comment_id UUID NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMPTZ,
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    UNIQUE(comment_id, reporter_id)
+);
+
+CREATE INDEX idx_comment_reports_open ON comment_reports(created_at) WHERE resolved_at IS NULL;
@@ -0,0 +1,3 @@
+-- Rollback comment revisions
+
+DROP TABLE IF EXISTS comment_revisions;
@@ -0,0 +1,5 @@
+-- Rollback comment rate limits
+
+ALTER TABLE config
+    DROP COLUMN IF EXISTS comment_rate_per_hour,
+    DROP COLUMN IF EXISTS comment_rate_per_minute;
@@ -0,0 +1,5 @@
+-- Per-user comment rate limits (0 disables a limit)
+
+ALTER TABLE config
+    ADD COLUMN comment_rate_per_minute INTEGER NOT NULL DEFAULT 5,
+    ADD COLUMN comment_rate_per_hour INTEGER NOT NULL DEFAULT 60;
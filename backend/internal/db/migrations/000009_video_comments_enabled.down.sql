@@ -0,0 +1,3 @@
+-- Rollback per-video comments switch
+
+ALTER TABLE videos DROP COLUMN IF EXISTS comments_enabled;
@@ -0,0 +1,3 @@
+-- Per-video switch for accepting new comments
+
+ALTER TABLE videos ADD COLUMN comments_enabled BOOLEAN NOT NULL DEFAULT TRUE;
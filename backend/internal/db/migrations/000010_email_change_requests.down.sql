@@ -0,0 +1,3 @@
+-- Rollback email change requests
+
+DROP TABLE IF EXISTS email_change_requests;
@@ -0,0 +1,3 @@
+-- Rollback user display name
+
+ALTER TABLE users DROP COLUMN IF EXISTS display_name;
@@ -0,0 +1,3 @@
+-- Optional display name shown in place of the (lowercased) username
+
+ALTER TABLE users ADD COLUMN display_name VARCHAR(50);
@@ -0,0 +1,11 @@
+-- Rollback moderator role (moderators become regular users)
+
+UPDATE users SET role = 'user' WHERE role = 'moderator';
+
+ALTER TYPE user_role RENAME TO user_role_old;
+CREATE TYPE user_role AS ENUM ('user', 'admin');
+ALTER TABLE users
+    ALTER COLUMN role DROP DEFAULT,
+    ALTER COLUMN role TYPE user_role USING role::text::user_role,
+    ALTER COLUMN role SET DEFAULT 'user';
+DROP TYPE IF EXISTS user_role_old;
@@ -0,0 +1,3 @@
+-- Moderator role: comment moderation and video takedown, no admin settings
+
+ALTER TYPE user_role ADD VALUE IF NOT EXISTS 'moderator';
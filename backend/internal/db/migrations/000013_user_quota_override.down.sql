@@ -0,0 +1,3 @@
+-- Rollback per-user upload limit override
+
+ALTER TABLE users DROP COLUMN IF EXISTS weekly_upload_limit_override_bytes;
@@ -0,0 +1,3 @@
+-- Per-user weekly upload limit, overriding the global config value when set
+
+ALTER TABLE users ADD COLUMN weekly_upload_limit_override_bytes BIGINT;
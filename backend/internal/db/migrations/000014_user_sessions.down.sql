@@ -0,0 +1,3 @@
+-- Rollback user sessions
+
+DROP TABLE IF EXISTS user_sessions;
@@ -0,0 +1,3 @@
+-- Rollback username change tracking
+
+ALTER TABLE users DROP COLUMN IF EXISTS username_changed_at;
@@ -0,0 +1,3 @@
+-- Track when a user last changed their username
+
+ALTER TABLE users ADD COLUMN username_changed_at TIMESTAMPTZ;
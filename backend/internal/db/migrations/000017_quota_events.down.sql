@@ -0,0 +1,3 @@
+-- Rollback quota events
+
+DROP TABLE IF EXISTS quota_events;
@@ -0,0 +1,14 @@
+-- Audit trail of changes to users' weekly upload usage
+
+CREATE TABLE quota_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_type VARCHAR(20) NOT NULL CHECK (event_type IN ('upload', 'weekly_reset', 'admin_reset')),
+    bytes_delta BIGINT NOT NULL,
+    used_bytes_after BIGINT NOT NULL,
+    video_id UUID REFERENCES videos(id) ON DELETE SET NULL,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_quota_events_user_created ON quota_events(user_id, created_at DESC);
@@ -0,0 +1,3 @@
+-- Rollback refresh tokens
+
+DROP TABLE IF EXISTS refresh_tokens;
@@ -0,0 +1,9 @@
+-- Rollback invitation roles and multi-use invitations
+
+DELETE FROM invitations WHERE email IS NULL;
+
+ALTER TABLE invitations
+    DROP COLUMN IF EXISTS use_count,
+    DROP COLUMN IF EXISTS max_uses,
+    DROP COLUMN IF EXISTS role,
+    ALTER COLUMN email SET NOT NULL;
@@ -0,0 +1,9 @@
+-- Invitations can grant a role and, when not bound to an email, be used several times
+
+ALTER TABLE invitations
+    ALTER COLUMN email DROP NOT NULL,
+    ADD COLUMN role user_role NOT NULL DEFAULT 'user',
+    ADD COLUMN max_uses INTEGER NOT NULL DEFAULT 1 CHECK (max_uses >= 1),
+    ADD COLUMN use_count INTEGER NOT NULL DEFAULT 0;
+
+UPDATE invitations SET use_count = 1 WHERE used = TRUE;
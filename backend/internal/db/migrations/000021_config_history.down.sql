@@ -0,0 +1,3 @@
+-- Rollback config history
+
+DROP TABLE IF EXISTS config_history;
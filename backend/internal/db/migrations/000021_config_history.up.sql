@@ -0,0 +1,10 @@
+-- History of system configuration changes
+
+CREATE TABLE config_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    changes JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_config_history_created ON config_history(created_at DESC);
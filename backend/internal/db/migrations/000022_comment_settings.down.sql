@@ -0,0 +1,6 @@
+-- Rollback comment settings
+
+ALTER TABLE config
+    DROP COLUMN IF EXISTS comment_edit_window_hours,
+    DROP COLUMN IF EXISTS comment_max_length,
+    DROP COLUMN IF EXISTS comments_enabled;
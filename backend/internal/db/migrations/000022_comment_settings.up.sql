@@ -0,0 +1,6 @@
+-- Site-wide comment settings
+
+ALTER TABLE config
+    ADD COLUMN comments_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN comment_max_length INTEGER NOT NULL DEFAULT 2000,
+    ADD COLUMN comment_edit_window_hours INTEGER NOT NULL DEFAULT 24;
@@ -0,0 +1,4 @@
+-- Rollback GPU backend setting
+
+ALTER TABLE config
+    DROP COLUMN IF EXISTS gpu_backend;
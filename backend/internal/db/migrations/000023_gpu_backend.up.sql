@@ -0,0 +1,4 @@
+-- Hardware encoder used when GPU transcoding is enabled
+
+ALTER TABLE config
+    ADD COLUMN gpu_backend VARCHAR(10) NOT NULL DEFAULT 'nvenc';
@@ -0,0 +1,4 @@
+-- Rollback rendition ladder
+
+ALTER TABLE config
+    DROP COLUMN IF EXISTS rendition_ladder;
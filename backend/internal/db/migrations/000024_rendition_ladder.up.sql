@@ -0,0 +1,5 @@
+-- Ordered list of renditions to transcode; empty keeps the single rendition
+-- from the global transcoding settings
+
+ALTER TABLE config
+    ADD COLUMN rendition_ladder JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,5 @@
+-- Rollback worker concurrency settings
+
+ALTER TABLE config
+    DROP COLUMN IF EXISTS gpu_concurrency,
+    DROP COLUMN IF EXISTS worker_concurrency;
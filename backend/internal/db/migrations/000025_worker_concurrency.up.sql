@@ -0,0 +1,5 @@
+-- Transcode jobs the worker runs at once, overall and on the GPU
+
+ALTER TABLE config
+    ADD COLUMN worker_concurrency INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN gpu_concurrency INTEGER NOT NULL DEFAULT 1;
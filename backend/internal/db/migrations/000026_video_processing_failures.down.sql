@@ -0,0 +1,3 @@
+-- Rollback video processing failures
+
+DROP TABLE IF EXISTS video_processing_failures;
@@ -0,0 +1,7 @@
+-- Failed processing attempts per video, for the failed videos view
+
+CREATE TABLE video_processing_failures (
+    video_id UUID PRIMARY KEY REFERENCES videos(id) ON DELETE CASCADE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,5 @@
+-- Rollback audio normalization settings
+
+ALTER TABLE config
+    DROP COLUMN IF EXISTS loudness_target_lufs,
+    DROP COLUMN IF EXISTS normalize_audio;
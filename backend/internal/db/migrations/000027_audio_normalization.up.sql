@@ -0,0 +1,5 @@
+-- Optional EBU R128 loudness normalization of transcoded audio
+
+ALTER TABLE config
+    ADD COLUMN normalize_audio BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN loudness_target_lufs INTEGER NOT NULL DEFAULT -16;
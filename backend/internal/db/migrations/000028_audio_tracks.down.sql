@@ -0,0 +1,7 @@
+-- Rollback audio track settings
+
+ALTER TABLE videos
+    DROP COLUMN IF EXISTS audio_track_count;
+
+ALTER TABLE config
+    DROP COLUMN IF EXISTS audio_track_mode;
@@ -0,0 +1,8 @@
+-- How multi-track audio is transcoded, and the audio tracks each output has
+
+ALTER TABLE config
+    ADD COLUMN audio_track_mode VARCHAR(10) NOT NULL DEFAULT 'first';
+
+-- NULL for videos processed before tracks were counted
+ALTER TABLE videos
+    ADD COLUMN audio_track_count INTEGER;
@@ -0,0 +1,3 @@
+-- Rollback transcode logs
+
+DROP TABLE IF EXISTS transcode_logs;
@@ -0,0 +1,12 @@
+-- FFmpeg commands and output of each processing job, for debugging failures
+
+CREATE TABLE transcode_logs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    video_id UUID NOT NULL REFERENCES videos(id) ON DELETE CASCADE,
+    job_kind VARCHAR(20) NOT NULL,
+    success BOOLEAN NOT NULL,
+    output TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_transcode_logs_video ON transcode_logs(video_id, created_at DESC);
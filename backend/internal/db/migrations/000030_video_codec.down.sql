@@ -0,0 +1,7 @@
+-- Rollback video codec settings
+
+ALTER TABLE videos
+    DROP COLUMN IF EXISTS video_codec;
+
+ALTER TABLE config
+    DROP COLUMN IF EXISTS video_codec;
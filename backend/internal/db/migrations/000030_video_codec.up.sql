@@ -0,0 +1,8 @@
+-- Output video codec setting, and the codec each video was encoded with
+
+ALTER TABLE config
+    ADD COLUMN video_codec VARCHAR(10) NOT NULL DEFAULT 'h264';
+
+-- NULL for videos processed before the codec was recorded (all H.264)
+ALTER TABLE videos
+    ADD COLUMN video_codec VARCHAR(10);
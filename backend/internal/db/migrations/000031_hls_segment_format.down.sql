@@ -0,0 +1,4 @@
+-- Rollback HLS segment format setting
+
+ALTER TABLE config
+    DROP COLUMN IF EXISTS hls_segment_format;
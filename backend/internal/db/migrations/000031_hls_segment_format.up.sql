@@ -0,0 +1,4 @@
+-- HLS segment container setting (MPEG-TS or fragmented MP4)
+
+ALTER TABLE config
+    ADD COLUMN hls_segment_format VARCHAR(10) NOT NULL DEFAULT 'ts';
@@ -0,0 +1,4 @@
+-- Rollback hardware decoding setting
+
+ALTER TABLE config
+    DROP COLUMN IF EXISTS gpu_decoding;
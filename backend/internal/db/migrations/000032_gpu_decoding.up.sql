@@ -0,0 +1,4 @@
+-- Hardware (NVDEC) decoding setting for GPU transcoding
+
+ALTER TABLE config
+    ADD COLUMN gpu_decoding BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,4 @@
+-- Rollback webhooks
+
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
@@ -0,0 +1,3 @@
+-- Rollback video output checksums
+
+ALTER TABLE videos DROP COLUMN IF EXISTS output_sha256;
@@ -0,0 +1,4 @@
+-- SHA-256 of each video's stored output, for integrity checks
+
+-- NULL until the worker hashes it; videos processed earlier are backfilled
+ALTER TABLE videos ADD COLUMN output_sha256 TEXT;
@@ -0,0 +1,5 @@
+-- Rollback stream bandwidth limits
+
+ALTER TABLE config
+    DROP COLUMN IF EXISTS stream_rate_limit_per_user,
+    DROP COLUMN IF EXISTS stream_rate_limit_per_connection;
@@ -0,0 +1,5 @@
+-- Bandwidth limits for streams, in bytes per second (0 means unlimited)
+
+ALTER TABLE config
+    ADD COLUMN stream_rate_limit_per_connection BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN stream_rate_limit_per_user BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,27 @@
+-- name: CreateAuditLogEntry :exec
+INSERT INTO audit_log (
+    actor_id, action, target_type, target_id, details
+) VALUES (
+    $1, $2, $3, $4, $5
+);
+
+-- name: ListAuditLog :many
+-- Filters are optional: a NULL actor or date bound and an empty action match everything
+SELECT
+    al.*,
+    a.username as actor_username
+FROM audit_log al
+LEFT JOIN users a ON al.actor_id = a.id
+WHERE (sqlc.narg('actor_id')::uuid IS NULL OR al.actor_id = sqlc.narg('actor_id'))
+  AND (@action::text = '' OR al.action = @action)
+  AND (sqlc.narg('since')::timestamptz IS NULL OR al.created_at >= sqlc.narg('since'))
+  AND (sqlc.narg('until')::timestamptz IS NULL OR al.created_at < sqlc.narg('until'))
+ORDER BY al.created_at DESC
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountAuditLog :one
+SELECT COUNT(*) FROM audit_log
+WHERE (sqlc.narg('actor_id')::uuid IS NULL OR actor_id = sqlc.narg('actor_id'))
+  AND (@action::text = '' OR action = @action)
+  AND (sqlc.narg('since')::timestamptz IS NULL OR created_at >= sqlc.narg('since'))
+  AND (sqlc.narg('until')::timestamptz IS NULL OR created_at < sqlc.narg('until'));
@@ -46,6 +46,11 @@ LEFT JOIN videos v ON v.category_id = c.id AND v.processing_status = 'completed'
 GROUP BY c.id
 ORDER BY c.name ASC;
 
+-- name: ListCategoryImageFiles :many
+SELECT id, image_filename FROM categories
+WHERE image_filename IS NOT NULL
+ORDER BY created_at ASC;
+
 -- name: CategoryExistsByName :one
 SELECT EXISTS(SELECT 1 FROM categories WHERE LOWER(name) = LOWER($1));
 
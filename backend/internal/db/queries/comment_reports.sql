@@ -0,0 +1,40 @@
+-- name: CreateCommentReport :exec
+INSERT INTO comment_reports (
+    comment_id, reporter_id, reason
+) VALUES (
+    $1, $2, $3
+) ON CONFLICT (comment_id, reporter_id) DO NOTHING;
+
+-- name: GetCommentReportByID :one
+SELECT * FROM comment_reports WHERE id = $1;
+
+-- name: ListOpenCommentReports :many
+-- Open reports with comment and reporter info; most-reported comments first
+SELECT 
+    cr.id, cr.comment_id, cr.reporter_id, cr.reason, cr.created_at,
+    ru.username as reporter_username,
+    c.content as comment_content,
+    c.video_id as comment_video_id,
+    c.user_id as comment_author_id,
+    au.username as comment_author_username,
+    (
+        SELECT COUNT(*) FROM comment_reports cr2
+        WHERE cr2.comment_id = cr.comment_id AND cr2.resolved_at IS NULL
+    ) as open_report_count
+FROM comment_reports cr
+JOIN comments c ON cr.comment_id = c.id
+JOIN users ru ON cr.reporter_id = ru.id
+JOIN users au ON c.user_id = au.id
+WHERE cr.resolved_at IS NULL
+ORDER BY open_report_count DESC, cr.created_at ASC
+LIMIT $1 OFFSET $2;
+
+-- name: CountOpenCommentReports :one
+SELECT COUNT(*) FROM comment_reports WHERE resolved_at IS NULL;
+
+-- name: ResolveCommentReports :exec
+-- Resolves every open report on a comment at once
+UPDATE comment_reports SET
+    resolved_at = NOW(),
+    resolved_by = $2
+WHERE comment_id = $1 AND resolved_at IS NULL;
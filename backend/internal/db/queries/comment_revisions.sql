@@ -0,0 +1,11 @@
+-- name: CreateCommentRevision :exec
+INSERT INTO comment_revisions (
+    comment_id, content
+) VALUES (
+    $1, $2
+);
+
+-- name: ListCommentRevisions :many
+SELECT * FROM comment_revisions
+WHERE comment_id = $1
+ORDER BY edited_at DESC;
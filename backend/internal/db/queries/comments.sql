@@ -22,6 +22,7 @@ DELETE FROM comments WHERE id = $1;
 SELECT 
     c.*,
     u.username as author_username,
+    COALESCE(u.display_name, u.username) as author_display_name,
     u.avatar_filename as author_avatar,
     (SELECT COUNT(*) FROM comments r WHERE r.parent_id = c.id) as reply_count
 FROM comments c
@@ -38,31 +39,67 @@ LIMIT $3 OFFSET $4;
 SELECT 
     c.*,
     u.username as author_username,
+    COALESCE(u.display_name, u.username) as author_display_name,
     u.avatar_filename as author_avatar
 FROM comments c
 JOIN users u ON c.user_id = u.id
 WHERE c.parent_id = $1
-ORDER BY c.created_at ASC;
+ORDER BY c.created_at ASC
+LIMIT $2 OFFSET $3;
+
+-- name: CountRepliesByComment :one
+SELECT COUNT(*) FROM comments WHERE parent_id = $1;
+
+-- name: ListRepliesByParents :many
+-- First replies (oldest first, up to per_parent each) for a page of top-level comments
+-- in one query (grouped by parent in Go)
+SELECT 
+    c.id, c.video_id, c.user_id, c.content, c.timestamp_seconds, c.parent_id, c.created_at, c.updated_at,
+    u.username as author_username,
+    COALESCE(u.display_name, u.username) as author_display_name,
+    u.avatar_filename as author_avatar
+FROM (
+    SELECT r.*, ROW_NUMBER() OVER (PARTITION BY r.parent_id ORDER BY r.created_at ASC) as reply_rank
+    FROM comments r
+    WHERE r.parent_id = ANY(@parent_ids::uuid[])
+) c
+JOIN users u ON c.user_id = u.id
+WHERE c.reply_rank <= @per_parent::int
+ORDER BY c.parent_id, c.created_at ASC;
 
 -- name: CountCommentsByVideo :one
 SELECT COUNT(*) FROM comments WHERE video_id = $1 AND parent_id IS NULL;
 
 -- name: GetCommentMarkers :many
 SELECT 
-    timestamp_seconds,
+    c.timestamp_seconds,
     COUNT(*) as count
-FROM comments
-WHERE video_id = $1 AND timestamp_seconds IS NOT NULL
-GROUP BY timestamp_seconds
-ORDER BY timestamp_seconds ASC;
+FROM comments c
+JOIN videos v ON c.video_id = v.id
+WHERE c.video_id = $1 AND c.timestamp_seconds IS NOT NULL
+    -- Skip markers past the end of the video once its duration is known
+    AND (v.duration_seconds IS NULL OR c.timestamp_seconds <= v.duration_seconds)
+GROUP BY c.timestamp_seconds
+ORDER BY c.timestamp_seconds ASC;
 
 -- name: GetCommentWithAuthor :one
 SELECT 
     c.*,
     u.username as author_username,
+    COALESCE(u.display_name, u.username) as author_display_name,
     u.avatar_filename as author_avatar,
     v.uploaded_by as video_owner_id
 FROM comments c
 JOIN users u ON c.user_id = u.id
 JOIN videos v ON c.video_id = v.id
 WHERE c.id = $1;
+
+-- name: ListCommentsByUser :many
+SELECT 
+    c.*,
+    v.short_id as video_short_id,
+    v.title as video_title
+FROM comments c
+JOIN videos v ON c.video_id = v.id
+WHERE c.user_id = $1
+ORDER BY c.created_at ASC;
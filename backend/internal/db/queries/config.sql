@@ -19,6 +19,23 @@ UPDATE config SET
     audio_bitrate = COALESCE(NULLIF($14, ''), audio_bitrate),
     transcode_preset_mode = COALESCE(NULLIF($15, ''), transcode_preset_mode),
     video_output_format = COALESCE(NULLIF($16, ''), video_output_format),
+    comment_rate_per_minute = COALESCE($18, comment_rate_per_minute),
+    comment_rate_per_hour = COALESCE($19, comment_rate_per_hour),
+    comments_enabled = COALESCE($20, comments_enabled),
+    comment_max_length = COALESCE($21, comment_max_length),
+    comment_edit_window_hours = COALESCE($22, comment_edit_window_hours),
+    gpu_backend = COALESCE(NULLIF($23, ''), gpu_backend),
+    rendition_ladder = COALESCE($24, rendition_ladder),
+    worker_concurrency = COALESCE($25, worker_concurrency),
+    gpu_concurrency = COALESCE($26, gpu_concurrency),
+    normalize_audio = COALESCE($27, normalize_audio),
+    loudness_target_lufs = COALESCE($28, loudness_target_lufs),
+    audio_track_mode = COALESCE(NULLIF($29, ''), audio_track_mode),
+    video_codec = COALESCE(NULLIF($30, ''), video_codec),
+    hls_segment_format = COALESCE(NULLIF($31, ''), hls_segment_format),
+    gpu_decoding = COALESCE($32, gpu_decoding),
+    stream_rate_limit_per_connection = COALESCE($33, stream_rate_limit_per_connection),
+    stream_rate_limit_per_user = COALESCE($34, stream_rate_limit_per_user),
     updated_at = NOW(),
     updated_by = $17
 WHERE id = 1
@@ -0,0 +1,15 @@
+-- name: CreateConfigHistoryEntry :exec
+INSERT INTO config_history (changed_by, changes)
+VALUES ($1, $2);
+
+-- name: ListConfigHistory :many
+SELECT
+    ch.*,
+    u.username as changed_by_username
+FROM config_history ch
+LEFT JOIN users u ON ch.changed_by = u.id
+ORDER BY ch.created_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: CountConfigHistory :one
+SELECT COUNT(*) FROM config_history;
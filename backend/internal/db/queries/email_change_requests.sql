@@ -0,0 +1,16 @@
+-- name: CreateEmailChangeRequest :one
+INSERT INTO email_change_requests (
+    user_id, new_email, token_hash, expires_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetValidEmailChangeByHash :one
+SELECT * FROM email_change_requests 
+WHERE token_hash = $1 AND expires_at > NOW();
+
+-- name: DeleteEmailChangeRequest :exec
+DELETE FROM email_change_requests WHERE id = $1;
+
+-- name: DeleteEmailChangeRequestsByUser :exec
+DELETE FROM email_change_requests WHERE user_id = $1;
@@ -5,17 +5,31 @@ SELECT * FROM invitations WHERE id = $1;
 SELECT * FROM invitations WHERE token = $1;
 
 -- name: CreateInvitation :one
+-- A NULL email makes a generic invitation that anyone with the link can use
 INSERT INTO invitations (
-    email, token, created_by, expires_at
+    email, token, created_by, expires_at, role, max_uses
 ) VALUES (
-    LOWER($1), $2, $3, $4
+    $1, $2, $3, $4, $5, $6
 ) RETURNING *;
 
--- name: MarkInvitationUsed :exec
+-- name: ConsumeInvitationUse :one
+-- Takes one use atomically; returns no rows if the invitation is used up or expired
 UPDATE invitations SET
-    used = TRUE,
+    use_count = use_count + 1,
+    used = use_count + 1 >= max_uses,
     used_at = NOW()
-WHERE id = $1;
+WHERE id = $1
+AND used = FALSE
+AND use_count < max_uses
+AND expires_at > NOW()
+RETURNING *;
+
+-- name: RegenerateInvitationToken :one
+UPDATE invitations SET
+    token = $2,
+    expires_at = $3
+WHERE id = $1
+RETURNING *;
 
 -- name: DeleteInvitation :exec
 DELETE FROM invitations WHERE id = $1;
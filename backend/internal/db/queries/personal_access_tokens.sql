@@ -0,0 +1,32 @@
+-- name: CreatePersonalAccessToken :one
+INSERT INTO personal_access_tokens (
+    user_id, name, token_hash, scope
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: ListPersonalAccessTokensByUser :many
+SELECT * FROM personal_access_tokens
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: GetPersonalAccessTokenByHash :one
+-- Only resolves tokens of active users
+SELECT 
+    t.id,
+    t.user_id,
+    t.scope,
+    t.last_used_at,
+    u.username,
+    u.role
+FROM personal_access_tokens t
+JOIN users u ON t.user_id = u.id
+WHERE t.token_hash = $1 AND u.is_active = TRUE;
+
+-- name: TouchPersonalAccessToken :exec
+UPDATE personal_access_tokens SET last_used_at = NOW() WHERE id = $1;
+
+-- name: DeletePersonalAccessToken :one
+DELETE FROM personal_access_tokens
+WHERE id = $1 AND user_id = $2
+RETURNING id;
@@ -0,0 +1,61 @@
+-- name: FollowPlaylist :exec
+INSERT INTO playlist_followers (
+    playlist_id, user_id
+) VALUES (
+    $1, $2
+) ON CONFLICT (playlist_id, user_id) DO NOTHING;
+
+-- name: UnfollowPlaylist :exec
+DELETE FROM playlist_followers WHERE playlist_id = $1 AND user_id = $2;
+
+-- name: IsFollowingPlaylist :one
+SELECT EXISTS(
+    SELECT 1 FROM playlist_followers WHERE playlist_id = $1 AND user_id = $2
+);
+
+-- name: CountPlaylistFollowers :one
+SELECT COUNT(*) FROM playlist_followers WHERE playlist_id = $1;
+
+-- name: ListFollowedPlaylists :many
+-- Followed playlists that are still visible to the follower, most recently followed first
+-- unless sorted otherwise
+SELECT 
+    p.*,
+    u.username as creator_username,
+    COALESCE(u.display_name, u.username) as creator_display_name,
+    COUNT(v.id) as video_count,
+    (
+        SELECT v2.thumbnail_filename
+        FROM playlist_videos pv2
+        JOIN videos v2 ON v2.id = pv2.video_id
+        WHERE pv2.playlist_id = p.id
+        AND (v2.processing_status = 'completed' OR v2.uploaded_by = @user_id OR @is_admin::boolean)
+        ORDER BY pv2.position ASC
+        LIMIT 1
+    ) as first_video_thumbnail,
+    (SELECT COUNT(*) FROM playlist_followers pf2 WHERE pf2.playlist_id = p.id) as follower_count
+FROM playlist_followers pf
+JOIN playlists p ON p.id = pf.playlist_id
+JOIN users u ON p.created_by = u.id
+LEFT JOIN playlist_videos pv ON pv.playlist_id = p.id
+LEFT JOIN videos v ON v.id = pv.video_id
+    AND (v.processing_status = 'completed' OR v.uploaded_by = @user_id OR @is_admin::boolean)
+WHERE pf.user_id = @user_id
+AND (p.is_public = TRUE OR p.created_by = @user_id)
+GROUP BY p.id, u.username, u.display_name, pf.created_at
+ORDER BY
+    CASE WHEN @sort::text = 'newest' THEN p.created_at END DESC,
+    CASE WHEN @sort::text = 'oldest' THEN p.created_at END ASC,
+    CASE WHEN @sort::text = 'name' THEN LOWER(p.name) END ASC,
+    CASE WHEN @sort::text = 'most_videos' THEN COUNT(v.id) END DESC,
+    CASE WHEN @sort::text = 'recently_updated' THEN p.updated_at END DESC,
+    pf.created_at DESC,
+    p.id
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountFollowedPlaylists :one
+SELECT COUNT(*)
+FROM playlist_followers pf
+JOIN playlists p ON p.id = pf.playlist_id
+WHERE pf.user_id = @user_id
+AND (p.is_public = TRUE OR p.created_by = @user_id);
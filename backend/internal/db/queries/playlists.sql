@@ -11,6 +11,14 @@ INSERT INTO playlists (
     $1, $2, $3, $4, $5
 ) RETURNING *;
 
+-- name: CreateSmartPlaylist :one
+INSERT INTO playlists (
+    short_id, name, description, created_by, is_public,
+    playlist_type, smart_category_id, smart_sort, smart_limit
+) VALUES (
+    $1, $2, $3, $4, $5, 'smart', $6, $7, $8
+) RETURNING *;
+
 -- name: UpdatePlaylist :one
 UPDATE playlists SET
     name = COALESCE(NULLIF($2, ''), name),
@@ -27,6 +35,7 @@ DELETE FROM playlists WHERE id = $1;
 SELECT 
     p.*,
     u.username as creator_username,
+    COALESCE(u.display_name, u.username) as creator_display_name,
     COUNT(pv.id) as video_count,
     (
         SELECT v.thumbnail_filename 
@@ -41,13 +50,14 @@ JOIN users u ON p.created_by = u.id
 LEFT JOIN playlist_videos pv ON pv.playlist_id = p.id
 WHERE p.created_by = $1
 AND (p.is_public = TRUE OR p.created_by = $2)
-GROUP BY p.id, u.username
+GROUP BY p.id, u.username, u.display_name
 ORDER BY p.updated_at DESC;
 
 -- name: GetPlaylistWithVideos :one
 SELECT 
     p.*,
-    u.username as creator_username
+    u.username as creator_username,
+    COALESCE(u.display_name, u.username) as creator_display_name
 FROM playlists p
 JOIN users u ON p.created_by = u.id
 WHERE p.short_id = $1;
@@ -74,7 +84,9 @@ SELECT
     v.view_count as video_view_count,
     v.processing_status as video_status,
     v.created_at as video_created_at,
-    vu.username as video_uploader_username
+    v.uploaded_by as video_uploaded_by,
+    vu.username as video_uploader_username,
+    COALESCE(vu.display_name, vu.username) as video_uploader_display_name
 FROM playlist_videos pv
 JOIN videos v ON pv.video_id = v.id
 JOIN users vu ON v.uploaded_by = vu.id
@@ -88,17 +100,26 @@ SELECT COALESCE(MAX(position), -1)::int AS max_position FROM playlist_videos WHE
 UPDATE playlist_videos SET position = $3
 WHERE playlist_id = $1 AND video_id = $2;
 
+-- name: ReorderPlaylistVideos :exec
+UPDATE playlist_videos pv
+SET position = np.position
+FROM (
+    SELECT UNNEST(@video_ids::uuid[]) AS video_id, UNNEST(@positions::int[]) AS position
+) AS np
+WHERE pv.playlist_id = @playlist_id AND pv.video_id = np.video_id;
+
 -- name: GetPlaylistsContainingVideo :many
 SELECT 
     p.*,
     u.username as creator_username,
+    COALESCE(u.display_name, u.username) as creator_display_name,
     COUNT(pv.id) as video_count
 FROM playlists p
 JOIN users u ON p.created_by = u.id
 JOIN playlist_videos pv ON pv.playlist_id = p.id
 WHERE pv.video_id = $1
 AND (p.is_public = TRUE OR p.created_by = $2)
-GROUP BY p.id, u.username
+GROUP BY p.id, u.username, u.display_name
 ORDER BY p.name ASC;
 
 -- name: CountUserPlaylists :one
@@ -124,42 +145,112 @@ UPDATE playlist_videos
 SET position = position - 1 
 WHERE playlist_id = $1 AND position > $2;
 
+-- name: IncrementPlaylistPositions :exec
+UPDATE playlist_videos
+SET position = position + 1
+WHERE playlist_id = $1 AND position >= $2;
+
 -- name: ListUserPlaylistsWithThumbnail :many
 SELECT 
     p.*,
     u.username as creator_username,
-    COUNT(pv.id) as video_count,
+    COALESCE(u.display_name, u.username) as creator_display_name,
+    COUNT(v.id) as video_count,
     (
-        SELECT v.thumbnail_filename 
-        FROM playlist_videos pv2 
-        JOIN videos v ON v.id = pv2.video_id 
-        WHERE pv2.playlist_id = p.id 
-        ORDER BY pv2.position ASC 
+        SELECT v2.thumbnail_filename
+        FROM playlist_videos pv2
+        JOIN videos v2 ON v2.id = pv2.video_id
+        WHERE pv2.playlist_id = p.id
+        AND (v2.processing_status = 'completed' OR v2.uploaded_by = @created_by OR @is_admin::boolean)
+        ORDER BY pv2.position ASC
         LIMIT 1
-    ) as first_video_thumbnail
+    ) as first_video_thumbnail,
+    EXISTS(
+        SELECT 1 FROM playlist_videos pv3
+        WHERE pv3.playlist_id = p.id AND pv3.video_id = @video_id
+    ) as contains_video,
+    (SELECT COUNT(*) FROM playlist_followers pf WHERE pf.playlist_id = p.id) as follower_count
 FROM playlists p
 JOIN users u ON p.created_by = u.id
 LEFT JOIN playlist_videos pv ON pv.playlist_id = p.id
-WHERE p.created_by = $1
-GROUP BY p.id, u.username
-ORDER BY p.updated_at DESC;
+LEFT JOIN videos v ON v.id = pv.video_id
+    AND (v.processing_status = 'completed' OR v.uploaded_by = @created_by OR @is_admin::boolean)
+WHERE p.created_by = @created_by
+GROUP BY p.id, u.username, u.display_name
+ORDER BY
+    CASE WHEN @sort::text = 'newest' THEN p.created_at END DESC,
+    CASE WHEN @sort::text = 'oldest' THEN p.created_at END ASC,
+    CASE WHEN @sort::text = 'name' THEN LOWER(p.name) END ASC,
+    CASE WHEN @sort::text = 'most_videos' THEN COUNT(v.id) END DESC,
+    p.updated_at DESC,
+    p.id
+LIMIT @page_limit OFFSET @page_offset;
 
 -- name: GetPlaylistsByUsername :many
 SELECT 
     p.*,
     u.username as creator_username,
-    COUNT(pv.id) as video_count,
+    COALESCE(u.display_name, u.username) as creator_display_name,
+    COUNT(v.id) as video_count,
     (
-        SELECT v.thumbnail_filename 
-        FROM playlist_videos pv2 
-        JOIN videos v ON v.id = pv2.video_id 
-        WHERE pv2.playlist_id = p.id 
-        ORDER BY pv2.position ASC 
+        SELECT v2.thumbnail_filename
+        FROM playlist_videos pv2
+        JOIN videos v2 ON v2.id = pv2.video_id
+        WHERE pv2.playlist_id = p.id
+        AND (v2.processing_status = 'completed' OR v2.uploaded_by = @viewer_id OR @is_admin::boolean)
+        ORDER BY pv2.position ASC
         LIMIT 1
-    ) as first_video_thumbnail
+    ) as first_video_thumbnail,
+    (SELECT COUNT(*) FROM playlist_followers pf WHERE pf.playlist_id = p.id) as follower_count,
+    EXISTS(
+        SELECT 1 FROM playlist_followers pf2
+        WHERE pf2.playlist_id = p.id AND pf2.user_id = @viewer_id
+    ) as followed_by_me
 FROM playlists p
 JOIN users u ON p.created_by = u.id
 LEFT JOIN playlist_videos pv ON pv.playlist_id = p.id
-WHERE LOWER(u.username) = LOWER($1)
-GROUP BY p.id, u.username
-ORDER BY p.updated_at DESC;
+LEFT JOIN videos v ON v.id = pv.video_id
+    AND (v.processing_status = 'completed' OR v.uploaded_by = @viewer_id OR @is_admin::boolean)
+WHERE LOWER(u.username) = LOWER(@username)
+AND (p.is_public = TRUE OR p.created_by = @viewer_id OR @is_admin::boolean)
+GROUP BY p.id, u.username, u.display_name
+ORDER BY
+    CASE WHEN @sort::text = 'newest' THEN p.created_at END DESC,
+    CASE WHEN @sort::text = 'oldest' THEN p.created_at END ASC,
+    CASE WHEN @sort::text = 'name' THEN LOWER(p.name) END ASC,
+    CASE WHEN @sort::text = 'most_videos' THEN COUNT(v.id) END DESC,
+    p.updated_at DESC,
+    p.id
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountPlaylistsByUsername :one
+SELECT COUNT(*)
+FROM playlists p
+JOIN users u ON p.created_by = u.id
+WHERE LOWER(u.username) = LOWER(@username)
+AND (p.is_public = TRUE OR p.created_by = @viewer_id OR @is_admin::boolean);
+
+-- name: ListSmartPlaylistVideos :many
+-- Resolves a smart playlist rule: newest/oldest/most viewed completed videos in a category
+SELECT
+    v.id as video_id,
+    v.short_id as video_short_id,
+    v.title as video_title,
+    v.description as video_description,
+    v.thumbnail_filename as video_thumbnail,
+    v.duration_seconds as video_duration,
+    v.view_count as video_view_count,
+    v.processing_status as video_status,
+    v.created_at as video_created_at,
+    v.uploaded_by as video_uploaded_by,
+    vu.username as video_uploader_username,
+    COALESCE(vu.display_name, vu.username) as video_uploader_display_name
+FROM videos v
+JOIN users vu ON v.uploaded_by = vu.id
+WHERE v.category_id = @category_id
+AND v.processing_status = 'completed'
+ORDER BY
+    CASE WHEN @sort::text = 'oldest' THEN v.created_at END ASC,
+    CASE WHEN @sort::text = 'most_viewed' THEN v.view_count END DESC,
+    v.created_at DESC
+LIMIT @max_videos;
@@ -0,0 +1,29 @@
+-- name: CreateQuotaEvent :exec
+-- Stores the usage after the change, so call it after updating the quota
+INSERT INTO quota_events (
+    user_id, event_type, bytes_delta, used_bytes_after, video_id, actor_id
+) VALUES (
+    $1, $2, $3, (SELECT weekly_upload_bytes FROM users WHERE id = $1), $4, $5
+);
+
+-- name: CreateQuotaResetEvents :exec
+-- Records a reset for every user; call it before zeroing their usage
+INSERT INTO quota_events (user_id, event_type, bytes_delta, used_bytes_after, actor_id)
+SELECT id, $1, -weekly_upload_bytes, 0, $2
+FROM users;
+
+-- name: ListQuotaEventsByUser :many
+SELECT
+    qe.*,
+    v.short_id as video_short_id,
+    v.title as video_title,
+    a.username as actor_username
+FROM quota_events qe
+LEFT JOIN videos v ON qe.video_id = v.id
+LEFT JOIN users a ON qe.actor_id = a.id
+WHERE qe.user_id = $1
+ORDER BY qe.created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountQuotaEventsByUser :one
+SELECT COUNT(*) FROM quota_events WHERE user_id = $1;
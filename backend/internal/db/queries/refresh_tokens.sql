@@ -0,0 +1,24 @@
+-- name: CreateRefreshToken :exec
+INSERT INTO refresh_tokens (
+    session_id, token_hash, expires_at
+) VALUES (
+    $1, $2, $3
+);
+
+-- name: GetRefreshTokenByHash :one
+SELECT
+    rt.id,
+    rt.session_id,
+    rt.expires_at,
+    rt.used_at,
+    s.user_id,
+    s.revoked_at as session_revoked_at
+FROM refresh_tokens rt
+JOIN user_sessions s ON rt.session_id = s.id
+WHERE rt.token_hash = $1;
+
+-- name: ConsumeRefreshToken :one
+-- Returns no rows if the token was already used
+UPDATE refresh_tokens SET used_at = NOW()
+WHERE id = $1 AND used_at IS NULL
+RETURNING id;
@@ -0,0 +1,19 @@
+-- name: CreateTranscodeLog :exec
+INSERT INTO transcode_logs (video_id, job_kind, success, output)
+VALUES ($1, $2, $3, $4);
+
+-- name: ListTranscodeLogsByVideo :many
+SELECT * FROM transcode_logs
+WHERE video_id = $1
+ORDER BY created_at DESC;
+
+-- name: PruneTranscodeLogs :exec
+-- Deletes all but a video's newest logs
+DELETE FROM transcode_logs
+WHERE video_id = $1
+  AND id NOT IN (
+      SELECT id FROM transcode_logs
+      WHERE video_id = $1
+      ORDER BY created_at DESC
+      LIMIT $2
+  );
@@ -0,0 +1,30 @@
+-- name: CreateUserSession :one
+INSERT INTO user_sessions (
+    user_id, user_agent, ip_address, expires_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: TouchUserSession :one
+-- Returns no rows if the session is revoked or expired
+UPDATE user_sessions SET last_seen_at = NOW()
+WHERE id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+RETURNING user_id;
+
+-- name: ListActiveUserSessions :many
+SELECT * FROM user_sessions
+WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+ORDER BY last_seen_at DESC;
+
+-- name: RevokeUserSession :one
+UPDATE user_sessions SET revoked_at = NOW()
+WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+RETURNING id;
+
+-- name: RevokeUserSessions :exec
+UPDATE user_sessions SET revoked_at = NOW()
+WHERE user_id = $1 AND revoked_at IS NULL;
+
+-- name: ExtendUserSession :exec
+UPDATE user_sessions SET expires_at = $2
+WHERE id = $1;
@@ -23,6 +23,17 @@ UPDATE users SET
 WHERE id = $1
 RETURNING *;
 
+-- name: UpdateUserDisplayName :exec
+UPDATE users SET display_name = $2 WHERE id = $1;
+
+-- name: UpdateUserEmail :exec
+UPDATE users SET email = $2 WHERE id = $1;
+
+-- name: UpdateUsername :one
+UPDATE users SET username = $2, username_changed_at = NOW()
+WHERE id = $1
+RETURNING *;
+
 -- name: UpdateUserAvatar :one
 UPDATE users SET avatar_filename = $2
 WHERE id = $1
@@ -39,6 +50,11 @@ UPDATE users SET is_active = FALSE WHERE id = $1;
 -- name: ActivateUser :exec
 UPDATE users SET is_active = TRUE WHERE id = $1;
 
+-- name: ListUserAvatarFiles :many
+SELECT id, avatar_filename FROM users
+WHERE avatar_filename IS NOT NULL
+ORDER BY created_at ASC;
+
 -- name: ListUsers :many
 SELECT * FROM users
 ORDER BY created_at DESC
@@ -55,7 +71,8 @@ LEFT JOIN playlists p ON p.created_by = u.id AND p.is_public = TRUE
 WHERE u.is_active = TRUE
 AND (
     $1::text IS NULL OR $1 = '' OR
-    LOWER(u.username) LIKE '%' || LOWER($1) || '%'
+    LOWER(u.username) LIKE '%' || LOWER($1) || '%' OR
+    LOWER(u.display_name) LIKE '%' || LOWER($1) || '%'
 )
 GROUP BY u.id
 ORDER BY
@@ -84,8 +101,34 @@ UPDATE users SET
     weekly_upload_bytes = 0,
     last_upload_reset = NOW();
 
+-- name: ResetExpiredUploadQuotas :one
+-- Resets usage for users last reset before the cutoff and records a
+-- weekly_reset event for each, in one statement; returns how many were reset
+WITH expired AS (
+    SELECT id, weekly_upload_bytes FROM users
+    WHERE last_upload_reset < @cutoff
+    FOR UPDATE
+), reset AS (
+    UPDATE users u SET
+        weekly_upload_bytes = 0,
+        last_upload_reset = NOW()
+    FROM expired
+    WHERE u.id = expired.id
+    RETURNING u.id, expired.weekly_upload_bytes AS previous_bytes
+), events AS (
+    INSERT INTO quota_events (user_id, event_type, bytes_delta, used_bytes_after)
+    SELECT id, 'weekly_reset', -previous_bytes, 0 FROM reset
+)
+SELECT COUNT(*) FROM reset;
+
 -- name: GetUserQuota :one
-SELECT weekly_upload_bytes, last_upload_reset FROM users WHERE id = $1;
+SELECT weekly_upload_bytes, last_upload_reset, weekly_upload_limit_override_bytes FROM users WHERE id = $1;
+
+-- name: UpdateUploadLimitOverride :one
+-- A NULL override falls back to the global weekly limit
+UPDATE users SET weekly_upload_limit_override_bytes = $2
+WHERE id = $1
+RETURNING *;
 
 -- name: UserExistsByEmail :one
 SELECT EXISTS(SELECT 1 FROM users WHERE LOWER(email) = LOWER($1));
@@ -97,16 +140,38 @@ SELECT EXISTS(SELECT 1 FROM users WHERE LOWER(username) = LOWER($1));
 SELECT COUNT(*) FROM users WHERE role = 'admin' AND is_active = TRUE;
 
 -- name: ListUsersWithCounts :many
+-- Admin user list. Filters are optional (empty string = no filter);
+-- unknown sort values fall back to newest first.
 SELECT 
     u.*,
     COUNT(DISTINCT v.id) as video_count,
-    COUNT(DISTINCT p.id) as playlist_count
+    COUNT(DISTINCT p.id) as playlist_count,
+    EXISTS(
+        SELECT 1 FROM email_change_requests ecr
+        WHERE ecr.user_id = u.id AND ecr.expires_at > NOW()
+    ) as pending_email_change,
+    (SELECT COALESCE(SUM(sv.file_size_bytes), 0) FROM videos sv WHERE sv.uploaded_by = u.id)::bigint as storage_bytes
 FROM users u
 LEFT JOIN videos v ON v.uploaded_by = u.id
 LEFT JOIN playlists p ON p.created_by = u.id
+WHERE
+    ($1::text = '' OR LOWER(u.username) LIKE '%' || LOWER($1) || '%' OR LOWER(u.email) LIKE '%' || LOWER($1) || '%')
+    AND ($2::text = '' OR u.role::text = $2)
+    AND ($3::text = '' OR u.is_active::text = $3)
 GROUP BY u.id
-ORDER BY u.created_at DESC
-LIMIT $1 OFFSET $2;
+ORDER BY
+    CASE WHEN $4 = 'username' THEN LOWER(u.username) END ASC,
+    CASE WHEN $4 = 'video_count' THEN COUNT(DISTINCT v.id) END DESC,
+    CASE WHEN $4 = 'storage' THEN (SELECT COALESCE(SUM(sv.file_size_bytes), 0) FROM videos sv WHERE sv.uploaded_by = u.id) END DESC,
+    u.created_at DESC
+LIMIT $5 OFFSET $6;
+
+-- name: CountUsersWithFilters :one
+SELECT COUNT(*) FROM users u
+WHERE
+    ($1::text = '' OR LOWER(u.username) LIKE '%' || LOWER($1) || '%' OR LOWER(u.email) LIKE '%' || LOWER($1) || '%')
+    AND ($2::text = '' OR u.role::text = $2)
+    AND ($3::text = '' OR u.is_active::text = $3);
 
 -- name: GetUserWithCounts :one
 SELECT 
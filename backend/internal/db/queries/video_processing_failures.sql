@@ -0,0 +1,6 @@
+-- name: RecordVideoProcessingFailure :exec
+INSERT INTO video_processing_failures (video_id, attempts, last_failed_at)
+VALUES ($1, 1, NOW())
+ON CONFLICT (video_id) DO UPDATE SET
+    attempts = video_processing_failures.attempts + 1,
+    last_failed_at = NOW();
@@ -7,16 +7,26 @@ SELECT * FROM videos WHERE short_id = $1;
 -- name: CreateVideo :one
 INSERT INTO videos (
     short_id, title, description, filename, original_filename,
-    file_size_bytes, uploaded_by, category_id, storage_path
+    file_size_bytes, uploaded_by, category_id, storage_path, content_sha256,
+    needs_enqueue
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, TRUE
 ) RETURNING *;
 
+-- name: GetVideoByUploaderAndHash :one
+SELECT * FROM videos
+WHERE uploaded_by = $1
+    AND content_sha256 = $2
+    AND processing_status != 'failed'
+ORDER BY created_at ASC
+LIMIT 1;
+
 -- name: UpdateVideo :one
 UPDATE videos SET
     title = COALESCE(NULLIF($2, ''), title),
     description = $3,
-    category_id = $4
+    category_id = $4,
+    comments_enabled = $5
 WHERE id = $1
 RETURNING *;
 
@@ -31,6 +41,18 @@ UPDATE videos SET
 WHERE id = $1
 RETURNING *;
 
+-- name: UpdateVideoAudioTrackCount :exec
+UPDATE videos SET audio_track_count = $2 WHERE id = $1;
+
+-- name: UpdateVideoCodec :exec
+UPDATE videos SET video_codec = $2 WHERE id = $1;
+
+-- name: UpdateVideoStoragePath :exec
+UPDATE videos SET storage_path = $2 WHERE id = $1;
+
+-- name: UpdateVideoOutputSha256 :exec
+UPDATE videos SET output_sha256 = $2 WHERE id = $1;
+
 -- name: DeleteVideo :exec
 DELETE FROM videos WHERE id = $1;
 
@@ -38,6 +60,7 @@ DELETE FROM videos WHERE id = $1;
 SELECT 
     v.*,
     u.username as uploader_username,
+    COALESCE(u.display_name, u.username) as uploader_display_name,
     c.name as category_name,
     c.slug as category_slug
 FROM videos v
@@ -83,6 +106,7 @@ RETURNING view_count;
 SELECT 
     v.*,
     u.username as uploader_username,
+    COALESCE(u.display_name, u.username) as uploader_display_name,
     c.name as category_name,
     c.slug as category_slug
 FROM videos v
@@ -93,10 +117,23 @@ WHERE v.short_id = $1;
 -- name: CountUserVideos :one
 SELECT COUNT(*) FROM videos WHERE uploaded_by = $1;
 
+-- name: GetUserStorageByStatus :many
+SELECT
+    processing_status,
+    COUNT(*) as video_count,
+    COALESCE(SUM(file_size_bytes), 0)::bigint as total_bytes
+FROM videos
+WHERE uploaded_by = $1
+GROUP BY processing_status
+ORDER BY processing_status;
+
+-- name: GetTotalStorageBytes :one
+SELECT COALESCE(SUM(file_size_bytes), 0)::bigint as total_bytes FROM videos;
+
 -- name: ListVideosWithoutHLS :many
 SELECT * FROM videos
 WHERE processing_status = 'completed'
-AND filename NOT LIKE '%/master.m3u8'
+AND filename ILIKE '%.mp4'
 ORDER BY created_at ASC;
 
 -- name: VideoExistsByShortID :one
@@ -110,8 +147,10 @@ SELECT EXISTS(SELECT 1 FROM videos WHERE short_id = $1);
 SELECT 
     v.*,
     u.username as uploader_username,
+    COALESCE(u.display_name, u.username) as uploader_display_name,
     c.name as category_name,
-    c.slug as category_slug
+    c.slug as category_slug,
+    (SELECT COUNT(*) FROM comments cm WHERE cm.video_id = v.id) as comment_count
 FROM videos v
 JOIN users u ON v.uploaded_by = u.id
 LEFT JOIN categories c ON v.category_id = c.id
@@ -147,8 +186,10 @@ WHERE
 SELECT 
     v.*,
     u.username as uploader_username,
+    COALESCE(u.display_name, u.username) as uploader_display_name,
     c.name as category_name,
-    c.slug as category_slug
+    c.slug as category_slug,
+    (SELECT COUNT(*) FROM comments cm WHERE cm.video_id = v.id) as comment_count
 FROM videos v
 JOIN users u ON v.uploaded_by = u.id
 LEFT JOIN categories c ON v.category_id = c.id
@@ -159,9 +200,64 @@ WHERE v.short_id = $1;
 SELECT 
     v.*,
     u.username as uploader_username,
+    COALESCE(u.display_name, u.username) as uploader_display_name,
     c.name as category_name,
-    c.slug as category_slug
+    c.slug as category_slug,
+    (SELECT COUNT(*) FROM comments cm WHERE cm.video_id = v.id) as comment_count
 FROM videos v
 JOIN users u ON v.uploaded_by = u.id
 LEFT JOIN categories c ON v.category_id = c.id
 WHERE v.id = $1;
+
+-- name: ClearVideoNeedsEnqueue :exec
+UPDATE videos SET needs_enqueue = FALSE WHERE id = $1;
+
+-- name: ListVideosNeedingEnqueue :many
+SELECT id FROM videos
+WHERE needs_enqueue = TRUE AND created_at < $1
+ORDER BY created_at ASC
+LIMIT $2;
+
+-- name: ListVideoFiles :many
+SELECT id, filename, thumbnail_filename, storage_path, processing_status FROM videos
+ORDER BY created_at ASC;
+
+-- name: ListVideosWithoutOutputSha256 :many
+SELECT id, filename, storage_path FROM videos
+WHERE processing_status = 'completed' AND output_sha256 IS NULL
+AND id <> ALL(@skip_ids::uuid[])
+ORDER BY created_at ASC
+LIMIT @batch_size::int;
+
+-- name: GetVideoFilesForUpdate :one
+-- Locks the video row until the transaction ends, so its files can't change under a move
+SELECT filename, storage_path, processing_status FROM videos
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListFailedVideos :many
+SELECT
+    v.id, v.short_id, v.title, v.filename, v.thumbnail_filename, v.storage_path, v.error_message, v.created_at, v.uploaded_by,
+    u.username as uploader_username,
+    COALESCE(f.attempts, 0)::int as attempts,
+    f.last_failed_at
+FROM videos v
+JOIN users u ON v.uploaded_by = u.id
+LEFT JOIN video_processing_failures f ON f.video_id = v.id
+WHERE v.processing_status = 'failed'
+ORDER BY f.last_failed_at DESC NULLS LAST, v.created_at DESC;
+
+-- name: RequeueFailedVideo :execrows
+-- Marks a failed video for processing again; the enqueue outbox picks it up
+-- if enqueueing it directly fails
+UPDATE videos SET processing_status = 'pending', error_message = NULL, needs_enqueue = TRUE
+WHERE id = $1 AND processing_status = 'failed';
+
+-- name: ListVideoSummariesByIDs :many
+SELECT id, short_id, title FROM videos
+WHERE id = ANY(@ids::uuid[]);
+
+-- name: ListVideosByUploader :many
+SELECT * FROM videos
+WHERE uploaded_by = $1
+ORDER BY created_at ASC;
@@ -0,0 +1,39 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (url, secret, events, created_by)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetWebhook :one
+SELECT * FROM webhooks WHERE id = $1;
+
+-- name: ListWebhooks :many
+SELECT * FROM webhooks ORDER BY created_at;
+
+-- name: ListWebhooksForEvent :many
+SELECT * FROM webhooks
+WHERE @event::text = ANY(events)
+ORDER BY created_at;
+
+-- name: DeleteWebhook :execrows
+DELETE FROM webhooks WHERE id = $1;
+
+-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (webhook_id, event, attempt, status_code, error, duration_ms)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE webhook_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
+
+-- name: PruneWebhookDeliveries :exec
+-- Deletes all but a webhook's newest deliveries
+DELETE FROM webhook_deliveries
+WHERE webhook_id = $1
+  AND id NOT IN (
+      SELECT id FROM webhook_deliveries
+      WHERE webhook_id = $1
+      ORDER BY created_at DESC
+      LIMIT $2
+  );
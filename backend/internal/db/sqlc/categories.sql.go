@@ -289,6 +289,37 @@ func (q *Queries) ListCategories(ctx context.Context) ([]ListCategoriesRow, erro
 	return items, nil
 }
 
+const listCategoryImageFiles = `-- name: ListCategoryImageFiles :many
+SELECT id, image_filename FROM categories
+WHERE image_filename IS NOT NULL
+ORDER BY created_at ASC
+`
+
+type ListCategoryImageFilesRow struct {
+	ID            uuid.UUID `json:"id"`
+	ImageFilename *string   `json:"image_filename"`
+}
+
+func (q *Queries) ListCategoryImageFiles(ctx context.Context) ([]ListCategoryImageFilesRow, error) {
+	rows, err := q.db.Query(ctx, listCategoryImageFiles)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListCategoryImageFilesRow{}
+	for rows.Next() {
+		var i ListCategoryImageFilesRow
+		if err := rows.Scan(&i.ID, &i.ImageFilename); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateCategory = `-- name: UpdateCategory :one
 UPDATE categories SET
     name = COALESCE(NULLIF($2, ''), name),
@@ -24,6 +24,17 @@ func (q *Queries) CountCommentsByVideo(ctx context.Context, videoID uuid.UUID) (
 	return count, err
 }
 
+const countRepliesByComment = `-- name: CountRepliesByComment :one
+SELECT COUNT(*) FROM comments WHERE parent_id = $1
+`
+
+func (q *Queries) CountRepliesByComment(ctx context.Context, parentID pgtype.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countRepliesByComment, parentID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createComment = `-- name: CreateComment :one
 INSERT INTO comments (
     video_id, user_id, content, timestamp_seconds, parent_id
@@ -93,12 +104,15 @@ func (q *Queries) GetCommentByID(ctx context.Context, id uuid.UUID) (Comment, er
 
 const getCommentMarkers = `-- name: GetCommentMarkers :many
 SELECT 
-    timestamp_seconds,
+    c.timestamp_seconds,
     COUNT(*) as count
-FROM comments
-WHERE video_id = $1 AND timestamp_seconds IS NOT NULL
-GROUP BY timestamp_seconds
-ORDER BY timestamp_seconds ASC
+FROM comments c
+JOIN videos v ON c.video_id = v.id
+WHERE c.video_id = $1 AND c.timestamp_seconds IS NOT NULL
+    -- Skip markers past the end of the video once its duration is known
+    AND (v.duration_seconds IS NULL OR c.timestamp_seconds <= v.duration_seconds)
+GROUP BY c.timestamp_seconds
+ORDER BY c.timestamp_seconds ASC
 `
 
 type GetCommentMarkersRow struct {
@@ -130,6 +144,7 @@ const getCommentWithAuthor = `-- name: GetCommentWithAuthor :one
 SELECT 
     c.id, c.video_id, c.user_id, c.content, c.timestamp_seconds, c.parent_id, c.created_at, c.updated_at,
     u.username as author_username,
+    COALESCE(u.display_name, u.username) as author_display_name,
     u.avatar_filename as author_avatar,
     v.uploaded_by as video_owner_id
 FROM comments c
@@ -139,17 +154,18 @@ WHERE c.id = $1
 `
 
 type GetCommentWithAuthorRow struct {
-	ID               uuid.UUID   `json:"id"`
-	VideoID          uuid.UUID   `json:"video_id"`
-	UserID           uuid.UUID   `json:"user_id"`
-	Content          string      `json:"content"`
-	TimestampSeconds *int32      `json:"timestamp_seconds"`
-	ParentID         pgtype.UUID `json:"parent_id"`
-	CreatedAt        time.Time   `json:"created_at"`
-	UpdatedAt        time.Time   `json:"updated_at"`
-	AuthorUsername   string      `json:"author_username"`
-	AuthorAvatar     *string     `json:"author_avatar"`
-	VideoOwnerID     uuid.UUID   `json:"video_owner_id"`
+	ID                uuid.UUID   `json:"id"`
+	VideoID           uuid.UUID   `json:"video_id"`
+	UserID            uuid.UUID   `json:"user_id"`
+	Content           string      `json:"content"`
+	TimestampSeconds  *int32      `json:"timestamp_seconds"`
+	ParentID          pgtype.UUID `json:"parent_id"`
+	CreatedAt         time.Time   `json:"created_at"`
+	UpdatedAt         time.Time   `json:"updated_at"`
+	AuthorUsername    string      `json:"author_username"`
+	AuthorDisplayName string      `json:"author_display_name"`
+	AuthorAvatar      *string     `json:"author_avatar"`
+	VideoOwnerID      uuid.UUID   `json:"video_owner_id"`
 }
 
 func (q *Queries) GetCommentWithAuthor(ctx context.Context, id uuid.UUID) (GetCommentWithAuthorRow, error) {
@@ -165,16 +181,73 @@ func (q *Queries) GetCommentWithAuthor(ctx context.Context, id uuid.UUID) (GetCo
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.AuthorUsername,
+		&i.AuthorDisplayName,
 		&i.AuthorAvatar,
 		&i.VideoOwnerID,
 	)
 	return i, err
 }
 
+const listCommentsByUser = `-- name: ListCommentsByUser :many
+SELECT 
+    c.id, c.video_id, c.user_id, c.content, c.timestamp_seconds, c.parent_id, c.created_at, c.updated_at,
+    v.short_id as video_short_id,
+    v.title as video_title
+FROM comments c
+JOIN videos v ON c.video_id = v.id
+WHERE c.user_id = $1
+ORDER BY c.created_at ASC
+`
+
+type ListCommentsByUserRow struct {
+	ID               uuid.UUID   `json:"id"`
+	VideoID          uuid.UUID   `json:"video_id"`
+	UserID           uuid.UUID   `json:"user_id"`
+	Content          string      `json:"content"`
+	TimestampSeconds *int32      `json:"timestamp_seconds"`
+	ParentID         pgtype.UUID `json:"parent_id"`
+	CreatedAt        time.Time   `json:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+	VideoShortID     string      `json:"video_short_id"`
+	VideoTitle       string      `json:"video_title"`
+}
+
+func (q *Queries) ListCommentsByUser(ctx context.Context, userID uuid.UUID) ([]ListCommentsByUserRow, error) {
+	rows, err := q.db.Query(ctx, listCommentsByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListCommentsByUserRow{}
+	for rows.Next() {
+		var i ListCommentsByUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.VideoID,
+			&i.UserID,
+			&i.Content,
+			&i.TimestampSeconds,
+			&i.ParentID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.VideoShortID,
+			&i.VideoTitle,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listCommentsByVideo = `-- name: ListCommentsByVideo :many
 SELECT 
     c.id, c.video_id, c.user_id, c.content, c.timestamp_seconds, c.parent_id, c.created_at, c.updated_at,
     u.username as author_username,
+    COALESCE(u.display_name, u.username) as author_display_name,
     u.avatar_filename as author_avatar,
     (SELECT COUNT(*) FROM comments r WHERE r.parent_id = c.id) as reply_count
 FROM comments c
@@ -196,17 +269,18 @@ type ListCommentsByVideoParams struct {
 }
 
 type ListCommentsByVideoRow struct {
-	ID               uuid.UUID   `json:"id"`
-	VideoID          uuid.UUID   `json:"video_id"`
-	UserID           uuid.UUID   `json:"user_id"`
-	Content          string      `json:"content"`
-	TimestampSeconds *int32      `json:"timestamp_seconds"`
-	ParentID         pgtype.UUID `json:"parent_id"`
-	CreatedAt        time.Time   `json:"created_at"`
-	UpdatedAt        time.Time   `json:"updated_at"`
-	AuthorUsername   string      `json:"author_username"`
-	AuthorAvatar     *string     `json:"author_avatar"`
-	ReplyCount       int64       `json:"reply_count"`
+	ID                uuid.UUID   `json:"id"`
+	VideoID           uuid.UUID   `json:"video_id"`
+	UserID            uuid.UUID   `json:"user_id"`
+	Content           string      `json:"content"`
+	TimestampSeconds  *int32      `json:"timestamp_seconds"`
+	ParentID          pgtype.UUID `json:"parent_id"`
+	CreatedAt         time.Time   `json:"created_at"`
+	UpdatedAt         time.Time   `json:"updated_at"`
+	AuthorUsername    string      `json:"author_username"`
+	AuthorDisplayName string      `json:"author_display_name"`
+	AuthorAvatar      *string     `json:"author_avatar"`
+	ReplyCount        int64       `json:"reply_count"`
 }
 
 func (q *Queries) ListCommentsByVideo(ctx context.Context, arg ListCommentsByVideoParams) ([]ListCommentsByVideoRow, error) {
@@ -233,6 +307,7 @@ func (q *Queries) ListCommentsByVideo(ctx context.Context, arg ListCommentsByVid
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.AuthorUsername,
+			&i.AuthorDisplayName,
 			&i.AuthorAvatar,
 			&i.ReplyCount,
 		); err != nil {
@@ -250,28 +325,37 @@ const listRepliesByComment = `-- name: ListRepliesByComment :many
 SELECT 
     c.id, c.video_id, c.user_id, c.content, c.timestamp_seconds, c.parent_id, c.created_at, c.updated_at,
     u.username as author_username,
+    COALESCE(u.display_name, u.username) as author_display_name,
     u.avatar_filename as author_avatar
 FROM comments c
 JOIN users u ON c.user_id = u.id
 WHERE c.parent_id = $1
 ORDER BY c.created_at ASC
+LIMIT $2 OFFSET $3
 `
 
+type ListRepliesByCommentParams struct {
+	ParentID pgtype.UUID `json:"parent_id"`
+	Limit    int32       `json:"limit"`
+	Offset   int32       `json:"offset"`
+}
+
 type ListRepliesByCommentRow struct {
-	ID               uuid.UUID   `json:"id"`
-	VideoID          uuid.UUID   `json:"video_id"`
-	UserID           uuid.UUID   `json:"user_id"`
-	Content          string      `json:"content"`
-	TimestampSeconds *int32      `json:"timestamp_seconds"`
-	ParentID         pgtype.UUID `json:"parent_id"`
-	CreatedAt        time.Time   `json:"created_at"`
-	UpdatedAt        time.Time   `json:"updated_at"`
-	AuthorUsername   string      `json:"author_username"`
-	AuthorAvatar     *string     `json:"author_avatar"`
+	ID                uuid.UUID   `json:"id"`
+	VideoID           uuid.UUID   `json:"video_id"`
+	UserID            uuid.UUID   `json:"user_id"`
+	Content           string      `json:"content"`
+	TimestampSeconds  *int32      `json:"timestamp_seconds"`
+	ParentID          pgtype.UUID `json:"parent_id"`
+	CreatedAt         time.Time   `json:"created_at"`
+	UpdatedAt         time.Time   `json:"updated_at"`
+	AuthorUsername    string      `json:"author_username"`
+	AuthorDisplayName string      `json:"author_display_name"`
+	AuthorAvatar      *string     `json:"author_avatar"`
 }
 
-func (q *Queries) ListRepliesByComment(ctx context.Context, parentID pgtype.UUID) ([]ListRepliesByCommentRow, error) {
-	rows, err := q.db.Query(ctx, listRepliesByComment, parentID)
+func (q *Queries) ListRepliesByComment(ctx context.Context, arg ListRepliesByCommentParams) ([]ListRepliesByCommentRow, error) {
+	rows, err := q.db.Query(ctx, listRepliesByComment, arg.ParentID, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -289,6 +373,76 @@ func (q *Queries) ListRepliesByComment(ctx context.Context, parentID pgtype.UUID
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.AuthorUsername,
+			&i.AuthorDisplayName,
+			&i.AuthorAvatar,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRepliesByParents = `-- name: ListRepliesByParents :many
+SELECT 
+    c.id, c.video_id, c.user_id, c.content, c.timestamp_seconds, c.parent_id, c.created_at, c.updated_at,
+    u.username as author_username,
+    COALESCE(u.display_name, u.username) as author_display_name,
+    u.avatar_filename as author_avatar
+FROM (
+    SELECT r.*, ROW_NUMBER() OVER (PARTITION BY r.parent_id ORDER BY r.created_at ASC) as reply_rank
+    FROM comments r
+    WHERE r.parent_id = ANY($1::uuid[])
+) c
+JOIN users u ON c.user_id = u.id
+WHERE c.reply_rank <= $2::int
+ORDER BY c.parent_id, c.created_at ASC
+`
+
+type ListRepliesByParentsParams struct {
+	ParentIds []uuid.UUID `json:"parent_ids"`
+	PerParent int32       `json:"per_parent"`
+}
+
+type ListRepliesByParentsRow struct {
+	ID                uuid.UUID   `json:"id"`
+	VideoID           uuid.UUID   `json:"video_id"`
+	UserID            uuid.UUID   `json:"user_id"`
+	Content           string      `json:"content"`
+	TimestampSeconds  *int32      `json:"timestamp_seconds"`
+	ParentID          pgtype.UUID `json:"parent_id"`
+	CreatedAt         time.Time   `json:"created_at"`
+	UpdatedAt         time.Time   `json:"updated_at"`
+	AuthorUsername    string      `json:"author_username"`
+	AuthorDisplayName string      `json:"author_display_name"`
+	AuthorAvatar      *string     `json:"author_avatar"`
+}
+
+// First replies (oldest first, up to per_parent each) for a page of top-level comments
+// in one query (grouped by parent in Go)
+func (q *Queries) ListRepliesByParents(ctx context.Context, arg ListRepliesByParentsParams) ([]ListRepliesByParentsRow, error) {
+	rows, err := q.db.Query(ctx, listRepliesByParents, arg.ParentIds, arg.PerParent)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListRepliesByParentsRow{}
+	for rows.Next() {
+		var i ListRepliesByParentsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.VideoID,
+			&i.UserID,
+			&i.Content,
+			&i.TimestampSeconds,
+			&i.ParentID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.AuthorUsername,
+			&i.AuthorDisplayName,
 			&i.AuthorAvatar,
 		); err != nil {
 			return nil, err
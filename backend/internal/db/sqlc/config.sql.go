@@ -12,7 +12,7 @@ import (
 )
 
 const getConfig = `-- name: GetConfig :one
-SELECT id, max_file_size_bytes, weekly_upload_limit_bytes, video_storage_path, use_gpu_transcoding, gpu_device_id, nvenc_preset, nvenc_cq, nvenc_rate_control, nvenc_max_bitrate, nvenc_buffer_size, cpu_preset, cpu_crf, max_resolution, audio_bitrate, transcode_preset_mode, video_output_format, updated_at, updated_by FROM config WHERE id = 1
+SELECT id, max_file_size_bytes, weekly_upload_limit_bytes, video_storage_path, use_gpu_transcoding, gpu_device_id, nvenc_preset, nvenc_cq, nvenc_rate_control, nvenc_max_bitrate, nvenc_buffer_size, cpu_preset, cpu_crf, max_resolution, audio_bitrate, transcode_preset_mode, video_output_format, updated_at, updated_by, comment_rate_per_minute, comment_rate_per_hour, comments_enabled, comment_max_length, comment_edit_window_hours, gpu_backend, rendition_ladder, worker_concurrency, gpu_concurrency, normalize_audio, loudness_target_lufs, audio_track_mode, video_codec, hls_segment_format, gpu_decoding, stream_rate_limit_per_connection, stream_rate_limit_per_user FROM config WHERE id = 1
 `
 
 func (q *Queries) GetConfig(ctx context.Context) (Config, error) {
@@ -38,6 +38,23 @@ func (q *Queries) GetConfig(ctx context.Context) (Config, error) {
 		&i.VideoOutputFormat,
 		&i.UpdatedAt,
 		&i.UpdatedBy,
+		&i.CommentRatePerMinute,
+		&i.CommentRatePerHour,
+		&i.CommentsEnabled,
+		&i.CommentMaxLength,
+		&i.CommentEditWindowHours,
+		&i.GpuBackend,
+		&i.RenditionLadder,
+		&i.WorkerConcurrency,
+		&i.GpuConcurrency,
+		&i.NormalizeAudio,
+		&i.LoudnessTargetLufs,
+		&i.AudioTrackMode,
+		&i.VideoCodec,
+		&i.HlsSegmentFormat,
+		&i.GpuDecoding,
+		&i.StreamRateLimitPerConnection,
+		&i.StreamRateLimitPerUser,
 	)
 	return i, err
 }
@@ -60,30 +77,64 @@ UPDATE config SET
     audio_bitrate = COALESCE(NULLIF($14, ''), audio_bitrate),
     transcode_preset_mode = COALESCE(NULLIF($15, ''), transcode_preset_mode),
     video_output_format = COALESCE(NULLIF($16, ''), video_output_format),
+    comment_rate_per_minute = COALESCE($18, comment_rate_per_minute),
+    comment_rate_per_hour = COALESCE($19, comment_rate_per_hour),
+    comments_enabled = COALESCE($20, comments_enabled),
+    comment_max_length = COALESCE($21, comment_max_length),
+    comment_edit_window_hours = COALESCE($22, comment_edit_window_hours),
+    gpu_backend = COALESCE(NULLIF($23, ''), gpu_backend),
+    rendition_ladder = COALESCE($24, rendition_ladder),
+    worker_concurrency = COALESCE($25, worker_concurrency),
+    gpu_concurrency = COALESCE($26, gpu_concurrency),
+    normalize_audio = COALESCE($27, normalize_audio),
+    loudness_target_lufs = COALESCE($28, loudness_target_lufs),
+    audio_track_mode = COALESCE(NULLIF($29, ''), audio_track_mode),
+    video_codec = COALESCE(NULLIF($30, ''), video_codec),
+    hls_segment_format = COALESCE(NULLIF($31, ''), hls_segment_format),
+    gpu_decoding = COALESCE($32, gpu_decoding),
+    stream_rate_limit_per_connection = COALESCE($33, stream_rate_limit_per_connection),
+    stream_rate_limit_per_user = COALESCE($34, stream_rate_limit_per_user),
     updated_at = NOW(),
     updated_by = $17
 WHERE id = 1
-RETURNING id, max_file_size_bytes, weekly_upload_limit_bytes, video_storage_path, use_gpu_transcoding, gpu_device_id, nvenc_preset, nvenc_cq, nvenc_rate_control, nvenc_max_bitrate, nvenc_buffer_size, cpu_preset, cpu_crf, max_resolution, audio_bitrate, transcode_preset_mode, video_output_format, updated_at, updated_by
+RETURNING id, max_file_size_bytes, weekly_upload_limit_bytes, video_storage_path, use_gpu_transcoding, gpu_device_id, nvenc_preset, nvenc_cq, nvenc_rate_control, nvenc_max_bitrate, nvenc_buffer_size, cpu_preset, cpu_crf, max_resolution, audio_bitrate, transcode_preset_mode, video_output_format, updated_at, updated_by, comment_rate_per_minute, comment_rate_per_hour, comments_enabled, comment_max_length, comment_edit_window_hours, gpu_backend, rendition_ladder, worker_concurrency, gpu_concurrency, normalize_audio, loudness_target_lufs, audio_track_mode, video_codec, hls_segment_format, gpu_decoding, stream_rate_limit_per_connection, stream_rate_limit_per_user
 `
 
 type UpdateConfigParams struct {
-	MaxFileSizeBytes       int64       `json:"max_file_size_bytes"`
-	WeeklyUploadLimitBytes int64       `json:"weekly_upload_limit_bytes"`
-	Column3                interface{} `json:"column_3"`
-	UseGpuTranscoding      bool        `json:"use_gpu_transcoding"`
-	GpuDeviceID            int32       `json:"gpu_device_id"`
-	Column6                interface{} `json:"column_6"`
-	NvencCq                int32       `json:"nvenc_cq"`
-	Column8                interface{} `json:"column_8"`
-	Column9                interface{} `json:"column_9"`
-	Column10               interface{} `json:"column_10"`
-	Column11               interface{} `json:"column_11"`
-	CpuCrf                 int32       `json:"cpu_crf"`
-	Column13               interface{} `json:"column_13"`
-	Column14               interface{} `json:"column_14"`
-	Column15               interface{} `json:"column_15"`
-	Column16               interface{} `json:"column_16"`
-	UpdatedBy              pgtype.UUID `json:"updated_by"`
+	MaxFileSizeBytes             int64       `json:"max_file_size_bytes"`
+	WeeklyUploadLimitBytes       int64       `json:"weekly_upload_limit_bytes"`
+	Column3                      interface{} `json:"column_3"`
+	UseGpuTranscoding            bool        `json:"use_gpu_transcoding"`
+	GpuDeviceID                  int32       `json:"gpu_device_id"`
+	Column6                      interface{} `json:"column_6"`
+	NvencCq                      int32       `json:"nvenc_cq"`
+	Column8                      interface{} `json:"column_8"`
+	Column9                      interface{} `json:"column_9"`
+	Column10                     interface{} `json:"column_10"`
+	Column11                     interface{} `json:"column_11"`
+	CpuCrf                       int32       `json:"cpu_crf"`
+	Column13                     interface{} `json:"column_13"`
+	Column14                     interface{} `json:"column_14"`
+	Column15                     interface{} `json:"column_15"`
+	Column16                     interface{} `json:"column_16"`
+	UpdatedBy                    pgtype.UUID `json:"updated_by"`
+	CommentRatePerMinute         int32       `json:"comment_rate_per_minute"`
+	CommentRatePerHour           int32       `json:"comment_rate_per_hour"`
+	CommentsEnabled              bool        `json:"comments_enabled"`
+	CommentMaxLength             int32       `json:"comment_max_length"`
+	CommentEditWindowHours       int32       `json:"comment_edit_window_hours"`
+	Column23                     interface{} `json:"column_23"`
+	RenditionLadder              []byte      `json:"rendition_ladder"`
+	WorkerConcurrency            int32       `json:"worker_concurrency"`
+	GpuConcurrency               int32       `json:"gpu_concurrency"`
+	NormalizeAudio               bool        `json:"normalize_audio"`
+	LoudnessTargetLufs           int32       `json:"loudness_target_lufs"`
+	Column29                     interface{} `json:"column_29"`
+	Column30                     interface{} `json:"column_30"`
+	Column31                     interface{} `json:"column_31"`
+	GpuDecoding                  bool        `json:"gpu_decoding"`
+	StreamRateLimitPerConnection int64       `json:"stream_rate_limit_per_connection"`
+	StreamRateLimitPerUser       int64       `json:"stream_rate_limit_per_user"`
 }
 
 func (q *Queries) UpdateConfig(ctx context.Context, arg UpdateConfigParams) (Config, error) {
@@ -105,6 +156,23 @@ func (q *Queries) UpdateConfig(ctx context.Context, arg UpdateConfigParams) (Con
 		arg.Column15,
 		arg.Column16,
 		arg.UpdatedBy,
+		arg.CommentRatePerMinute,
+		arg.CommentRatePerHour,
+		arg.CommentsEnabled,
+		arg.CommentMaxLength,
+		arg.CommentEditWindowHours,
+		arg.Column23,
+		arg.RenditionLadder,
+		arg.WorkerConcurrency,
+		arg.GpuConcurrency,
+		arg.NormalizeAudio,
+		arg.LoudnessTargetLufs,
+		arg.Column29,
+		arg.Column30,
+		arg.Column31,
+		arg.GpuDecoding,
+		arg.StreamRateLimitPerConnection,
+		arg.StreamRateLimitPerUser,
 	)
 	var i Config
 	err := row.Scan(
@@ -127,6 +195,23 @@ func (q *Queries) UpdateConfig(ctx context.Context, arg UpdateConfigParams) (Con
 		&i.VideoOutputFormat,
 		&i.UpdatedAt,
 		&i.UpdatedBy,
+		&i.CommentRatePerMinute,
+		&i.CommentRatePerHour,
+		&i.CommentsEnabled,
+		&i.CommentMaxLength,
+		&i.CommentEditWindowHours,
+		&i.GpuBackend,
+		&i.RenditionLadder,
+		&i.WorkerConcurrency,
+		&i.GpuConcurrency,
+		&i.NormalizeAudio,
+		&i.LoudnessTargetLufs,
+		&i.AudioTrackMode,
+		&i.VideoCodec,
+		&i.HlsSegmentFormat,
+		&i.GpuDecoding,
+		&i.StreamRateLimitPerConnection,
+		&i.StreamRateLimitPerUser,
 	)
 	return i, err
 }
@@ -11,8 +11,42 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/clipset/clipset-go/internal/domain"
 )
 
+const consumeInvitationUse = `-- name: ConsumeInvitationUse :one
+UPDATE invitations SET
+    use_count = use_count + 1,
+    used = use_count + 1 >= max_uses,
+    used_at = NOW()
+WHERE id = $1
+AND used = FALSE
+AND use_count < max_uses
+AND expires_at > NOW()
+RETURNING id, email, token, created_by, created_at, expires_at, used, used_at, role, max_uses, use_count
+`
+
+// Takes one use atomically; returns no rows if the invitation is used up or expired
+func (q *Queries) ConsumeInvitationUse(ctx context.Context, id uuid.UUID) (Invitation, error) {
+	row := q.db.QueryRow(ctx, consumeInvitationUse, id)
+	var i Invitation
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Token,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.Used,
+		&i.UsedAt,
+		&i.Role,
+		&i.MaxUses,
+		&i.UseCount,
+	)
+	return i, err
+}
+
 const countInvitations = `-- name: CountInvitations :one
 SELECT COUNT(*) FROM invitations
 `
@@ -26,25 +60,30 @@ func (q *Queries) CountInvitations(ctx context.Context) (int64, error) {
 
 const createInvitation = `-- name: CreateInvitation :one
 INSERT INTO invitations (
-    email, token, created_by, expires_at
+    email, token, created_by, expires_at, role, max_uses
 ) VALUES (
-    LOWER($1), $2, $3, $4
-) RETURNING id, email, token, created_by, created_at, expires_at, used, used_at
+    $1, $2, $3, $4, $5, $6
+) RETURNING id, email, token, created_by, created_at, expires_at, used, used_at, role, max_uses, use_count
 `
 
 type CreateInvitationParams struct {
-	Lower     string    `json:"lower"`
-	Token     string    `json:"token"`
-	CreatedBy uuid.UUID `json:"created_by"`
-	ExpiresAt time.Time `json:"expires_at"`
+	Email     *string         `json:"email"`
+	Token     string          `json:"token"`
+	CreatedBy uuid.UUID       `json:"created_by"`
+	ExpiresAt time.Time       `json:"expires_at"`
+	Role      domain.UserRole `json:"role"`
+	MaxUses   int32           `json:"max_uses"`
 }
 
+// A NULL email makes a generic invitation that anyone with the link can use
 func (q *Queries) CreateInvitation(ctx context.Context, arg CreateInvitationParams) (Invitation, error) {
 	row := q.db.QueryRow(ctx, createInvitation,
-		arg.Lower,
+		arg.Email,
 		arg.Token,
 		arg.CreatedBy,
 		arg.ExpiresAt,
+		arg.Role,
+		arg.MaxUses,
 	)
 	var i Invitation
 	err := row.Scan(
@@ -56,6 +95,9 @@ func (q *Queries) CreateInvitation(ctx context.Context, arg CreateInvitationPara
 		&i.ExpiresAt,
 		&i.Used,
 		&i.UsedAt,
+		&i.Role,
+		&i.MaxUses,
+		&i.UseCount,
 	)
 	return i, err
 }
@@ -70,7 +112,7 @@ func (q *Queries) DeleteInvitation(ctx context.Context, id uuid.UUID) error {
 }
 
 const getInvitationByID = `-- name: GetInvitationByID :one
-SELECT id, email, token, created_by, created_at, expires_at, used, used_at FROM invitations WHERE id = $1
+SELECT id, email, token, created_by, created_at, expires_at, used, used_at, role, max_uses, use_count FROM invitations WHERE id = $1
 `
 
 func (q *Queries) GetInvitationByID(ctx context.Context, id uuid.UUID) (Invitation, error) {
@@ -85,12 +127,15 @@ func (q *Queries) GetInvitationByID(ctx context.Context, id uuid.UUID) (Invitati
 		&i.ExpiresAt,
 		&i.Used,
 		&i.UsedAt,
+		&i.Role,
+		&i.MaxUses,
+		&i.UseCount,
 	)
 	return i, err
 }
 
 const getInvitationByToken = `-- name: GetInvitationByToken :one
-SELECT id, email, token, created_by, created_at, expires_at, used, used_at FROM invitations WHERE token = $1
+SELECT id, email, token, created_by, created_at, expires_at, used, used_at, role, max_uses, use_count FROM invitations WHERE token = $1
 `
 
 func (q *Queries) GetInvitationByToken(ctx context.Context, token string) (Invitation, error) {
@@ -105,12 +150,15 @@ func (q *Queries) GetInvitationByToken(ctx context.Context, token string) (Invit
 		&i.ExpiresAt,
 		&i.Used,
 		&i.UsedAt,
+		&i.Role,
+		&i.MaxUses,
+		&i.UseCount,
 	)
 	return i, err
 }
 
 const getValidInvitationByToken = `-- name: GetValidInvitationByToken :one
-SELECT id, email, token, created_by, created_at, expires_at, used, used_at FROM invitations 
+SELECT id, email, token, created_by, created_at, expires_at, used, used_at, role, max_uses, use_count FROM invitations 
 WHERE token = $1 
 AND used = FALSE 
 AND expires_at > NOW()
@@ -128,13 +176,16 @@ func (q *Queries) GetValidInvitationByToken(ctx context.Context, token string) (
 		&i.ExpiresAt,
 		&i.Used,
 		&i.UsedAt,
+		&i.Role,
+		&i.MaxUses,
+		&i.UseCount,
 	)
 	return i, err
 }
 
 const listInvitations = `-- name: ListInvitations :many
 SELECT 
-    i.id, i.email, i.token, i.created_by, i.created_at, i.expires_at, i.used, i.used_at,
+    i.id, i.email, i.token, i.created_by, i.created_at, i.expires_at, i.used, i.used_at, i.role, i.max_uses, i.use_count,
     u.username as creator_username
 FROM invitations i
 JOIN users u ON i.created_by = u.id
@@ -149,13 +200,16 @@ type ListInvitationsParams struct {
 
 type ListInvitationsRow struct {
 	ID              uuid.UUID          `json:"id"`
-	Email           string             `json:"email"`
+	Email           *string            `json:"email"`
 	Token           string             `json:"token"`
 	CreatedBy       uuid.UUID          `json:"created_by"`
 	CreatedAt       time.Time          `json:"created_at"`
 	ExpiresAt       time.Time          `json:"expires_at"`
 	Used            bool               `json:"used"`
 	UsedAt          pgtype.Timestamptz `json:"used_at"`
+	Role            domain.UserRole    `json:"role"`
+	MaxUses         int32              `json:"max_uses"`
+	UseCount        int32              `json:"use_count"`
 	CreatorUsername string             `json:"creator_username"`
 }
 
@@ -177,6 +231,9 @@ func (q *Queries) ListInvitations(ctx context.Context, arg ListInvitationsParams
 			&i.ExpiresAt,
 			&i.Used,
 			&i.UsedAt,
+			&i.Role,
+			&i.MaxUses,
+			&i.UseCount,
 			&i.CreatorUsername,
 		); err != nil {
 			return nil, err
@@ -189,14 +246,35 @@ func (q *Queries) ListInvitations(ctx context.Context, arg ListInvitationsParams
 	return items, nil
 }
 
-const markInvitationUsed = `-- name: MarkInvitationUsed :exec
+const regenerateInvitationToken = `-- name: RegenerateInvitationToken :one
 UPDATE invitations SET
-    used = TRUE,
-    used_at = NOW()
+    token = $2,
+    expires_at = $3
 WHERE id = $1
+RETURNING id, email, token, created_by, created_at, expires_at, used, used_at, role, max_uses, use_count
 `
 
-func (q *Queries) MarkInvitationUsed(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.Exec(ctx, markInvitationUsed, id)
-	return err
+type RegenerateInvitationTokenParams struct {
+	ID        uuid.UUID `json:"id"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) RegenerateInvitationToken(ctx context.Context, arg RegenerateInvitationTokenParams) (Invitation, error) {
+	row := q.db.QueryRow(ctx, regenerateInvitationToken, arg.ID, arg.Token, arg.ExpiresAt)
+	var i Invitation
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Token,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.Used,
+		&i.UsedAt,
+		&i.Role,
+		&i.MaxUses,
+		&i.UseCount,
+	)
+	return i, err
 }
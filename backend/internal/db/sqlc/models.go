@@ -14,6 +14,48 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type PlaylistType string
+
+const (
+	PlaylistTypeManual PlaylistType = "manual"
+	PlaylistTypeSmart  PlaylistType = "smart"
+)
+
+func (e *PlaylistType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PlaylistType(s)
+	case string:
+		*e = PlaylistType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PlaylistType: %T", src)
+	}
+	return nil
+}
+
+type NullPlaylistType struct {
+	PlaylistType PlaylistType `json:"playlist_type"`
+	Valid        bool         `json:"valid"` // Valid is true if PlaylistType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPlaylistType) Scan(value interface{}) error {
+	if value == nil {
+		ns.PlaylistType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PlaylistType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPlaylistType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PlaylistType), nil
+}
+
 type ProcessingStatus string
 
 const (
@@ -61,8 +103,9 @@ func (ns NullProcessingStatus) Value() (driver.Value, error) {
 type UserRole string
 
 const (
-	UserRoleUser  UserRole = "user"
-	UserRoleAdmin UserRole = "admin"
+	UserRoleUser      UserRole = "user"
+	UserRoleAdmin     UserRole = "admin"
+	UserRoleModerator UserRole = "moderator"
 )
 
 func (e *UserRole) Scan(src interface{}) error {
@@ -100,6 +143,16 @@ func (ns NullUserRole) Value() (driver.Value, error) {
 	return string(ns.UserRole), nil
 }
 
+type AuditLog struct {
+	ID         uuid.UUID   `json:"id"`
+	ActorID    pgtype.UUID `json:"actor_id"`
+	Action     string      `json:"action"`
+	TargetType string      `json:"target_type"`
+	TargetID   pgtype.UUID `json:"target_id"`
+	Details    []byte      `json:"details"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
 type Category struct {
 	ID            uuid.UUID `json:"id"`
 	Name          string    `json:"name"`
@@ -122,37 +175,90 @@ type Comment struct {
 	UpdatedAt        time.Time   `json:"updated_at"`
 }
 
+type CommentReport struct {
+	ID         uuid.UUID   `json:"id"`
+	CommentID  uuid.UUID   `json:"comment_id"`
+	ReporterID uuid.UUID   `json:"reporter_id"`
+	Reason     *string     `json:"reason"`
+	CreatedAt  time.Time   `json:"created_at"`
+	ResolvedAt *time.Time  `json:"resolved_at"`
+	ResolvedBy pgtype.UUID `json:"resolved_by"`
+}
+
+type CommentRevision struct {
+	ID        uuid.UUID `json:"id"`
+	CommentID uuid.UUID `json:"comment_id"`
+	Content   string    `json:"content"`
+	EditedAt  time.Time `json:"edited_at"`
+}
+
 type Config struct {
-	ID                     int32       `json:"id"`
-	MaxFileSizeBytes       int64       `json:"max_file_size_bytes"`
-	WeeklyUploadLimitBytes int64       `json:"weekly_upload_limit_bytes"`
-	VideoStoragePath       string      `json:"video_storage_path"`
-	UseGpuTranscoding      bool        `json:"use_gpu_transcoding"`
-	GpuDeviceID            int32       `json:"gpu_device_id"`
-	NvencPreset            string      `json:"nvenc_preset"`
-	NvencCq                int32       `json:"nvenc_cq"`
-	NvencRateControl       string      `json:"nvenc_rate_control"`
-	NvencMaxBitrate        string      `json:"nvenc_max_bitrate"`
-	NvencBufferSize        string      `json:"nvenc_buffer_size"`
-	CpuPreset              string      `json:"cpu_preset"`
-	CpuCrf                 int32       `json:"cpu_crf"`
-	MaxResolution          string      `json:"max_resolution"`
-	AudioBitrate           string      `json:"audio_bitrate"`
-	TranscodePresetMode    string      `json:"transcode_preset_mode"`
-	VideoOutputFormat      string      `json:"video_output_format"`
-	UpdatedAt              time.Time   `json:"updated_at"`
-	UpdatedBy              pgtype.UUID `json:"updated_by"`
+	ID                           int32       `json:"id"`
+	MaxFileSizeBytes             int64       `json:"max_file_size_bytes"`
+	WeeklyUploadLimitBytes       int64       `json:"weekly_upload_limit_bytes"`
+	VideoStoragePath             string      `json:"video_storage_path"`
+	UseGpuTranscoding            bool        `json:"use_gpu_transcoding"`
+	GpuDeviceID                  int32       `json:"gpu_device_id"`
+	NvencPreset                  string      `json:"nvenc_preset"`
+	NvencCq                      int32       `json:"nvenc_cq"`
+	NvencRateControl             string      `json:"nvenc_rate_control"`
+	NvencMaxBitrate              string      `json:"nvenc_max_bitrate"`
+	NvencBufferSize              string      `json:"nvenc_buffer_size"`
+	CpuPreset                    string      `json:"cpu_preset"`
+	CpuCrf                       int32       `json:"cpu_crf"`
+	MaxResolution                string      `json:"max_resolution"`
+	AudioBitrate                 string      `json:"audio_bitrate"`
+	TranscodePresetMode          string      `json:"transcode_preset_mode"`
+	VideoOutputFormat            string      `json:"video_output_format"`
+	UpdatedAt                    time.Time   `json:"updated_at"`
+	UpdatedBy                    pgtype.UUID `json:"updated_by"`
+	CommentRatePerMinute         int32       `json:"comment_rate_per_minute"`
+	CommentRatePerHour           int32       `json:"comment_rate_per_hour"`
+	CommentsEnabled              bool        `json:"comments_enabled"`
+	CommentMaxLength             int32       `json:"comment_max_length"`
+	CommentEditWindowHours       int32       `json:"comment_edit_window_hours"`
+	GpuBackend                   string      `json:"gpu_backend"`
+	RenditionLadder              []byte      `json:"rendition_ladder"`
+	WorkerConcurrency            int32       `json:"worker_concurrency"`
+	GpuConcurrency               int32       `json:"gpu_concurrency"`
+	NormalizeAudio               bool        `json:"normalize_audio"`
+	LoudnessTargetLufs           int32       `json:"loudness_target_lufs"`
+	AudioTrackMode               string      `json:"audio_track_mode"`
+	VideoCodec                   string      `json:"video_codec"`
+	HlsSegmentFormat             string      `json:"hls_segment_format"`
+	GpuDecoding                  bool        `json:"gpu_decoding"`
+	StreamRateLimitPerConnection int64       `json:"stream_rate_limit_per_connection"`
+	StreamRateLimitPerUser       int64       `json:"stream_rate_limit_per_user"`
+}
+
+type ConfigHistory struct {
+	ID        uuid.UUID   `json:"id"`
+	ChangedBy pgtype.UUID `json:"changed_by"`
+	Changes   []byte      `json:"changes"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+type EmailChangeRequest struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	NewEmail  string    `json:"new_email"`
+	TokenHash string    `json:"token_hash"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type Invitation struct {
 	ID        uuid.UUID          `json:"id"`
-	Email     string             `json:"email"`
+	Email     *string            `json:"email"`
 	Token     string             `json:"token"`
 	CreatedBy uuid.UUID          `json:"created_by"`
 	CreatedAt time.Time          `json:"created_at"`
 	ExpiresAt time.Time          `json:"expires_at"`
 	Used      bool               `json:"used"`
 	UsedAt    pgtype.Timestamptz `json:"used_at"`
+	Role      domain.UserRole    `json:"role"`
+	MaxUses   int32              `json:"max_uses"`
+	UseCount  int32              `json:"use_count"`
 }
 
 type PasswordResetToken struct {
@@ -163,15 +269,36 @@ type PasswordResetToken struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type PersonalAccessToken struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	Name       string     `json:"name"`
+	TokenHash  string     `json:"token_hash"`
+	Scope      string     `json:"scope"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
 type Playlist struct {
-	ID          uuid.UUID `json:"id"`
-	ShortID     string    `json:"short_id"`
-	Name        string    `json:"name"`
-	Description *string   `json:"description"`
-	CreatedBy   uuid.UUID `json:"created_by"`
-	IsPublic    bool      `json:"is_public"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID              uuid.UUID           `json:"id"`
+	ShortID         string              `json:"short_id"`
+	Name            string              `json:"name"`
+	Description     *string             `json:"description"`
+	CreatedBy       uuid.UUID           `json:"created_by"`
+	IsPublic        bool                `json:"is_public"`
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+	PlaylistType    domain.PlaylistType `json:"playlist_type"`
+	SmartCategoryID pgtype.UUID         `json:"smart_category_id"`
+	SmartSort       *string             `json:"smart_sort"`
+	SmartLimit      *int32              `json:"smart_limit"`
+}
+
+type PlaylistFollower struct {
+	ID         uuid.UUID `json:"id"`
+	PlaylistID uuid.UUID `json:"playlist_id"`
+	UserID     uuid.UUID `json:"user_id"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
 type PlaylistVideo struct {
@@ -183,17 +310,60 @@ type PlaylistVideo struct {
 	AddedBy    pgtype.UUID `json:"added_by"`
 }
 
+type QuotaEvent struct {
+	ID             uuid.UUID   `json:"id"`
+	UserID         uuid.UUID   `json:"user_id"`
+	EventType      string      `json:"event_type"`
+	BytesDelta     int64       `json:"bytes_delta"`
+	UsedBytesAfter int64       `json:"used_bytes_after"`
+	VideoID        pgtype.UUID `json:"video_id"`
+	ActorID        pgtype.UUID `json:"actor_id"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+type RefreshToken struct {
+	ID        uuid.UUID  `json:"id"`
+	SessionID uuid.UUID  `json:"session_id"`
+	TokenHash string     `json:"token_hash"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+}
+
+type TranscodeLog struct {
+	ID        uuid.UUID `json:"id"`
+	VideoID   uuid.UUID `json:"video_id"`
+	JobKind   string    `json:"job_kind"`
+	Success   bool      `json:"success"`
+	Output    string    `json:"output"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 type User struct {
-	ID                uuid.UUID       `json:"id"`
-	Email             string          `json:"email"`
-	Username          string          `json:"username"`
-	PasswordHash      string          `json:"password_hash"`
-	Role              domain.UserRole `json:"role"`
-	CreatedAt         time.Time       `json:"created_at"`
-	IsActive          bool            `json:"is_active"`
-	AvatarFilename    *string         `json:"avatar_filename"`
-	WeeklyUploadBytes int64           `json:"weekly_upload_bytes"`
-	LastUploadReset   time.Time       `json:"last_upload_reset"`
+	ID                             uuid.UUID       `json:"id"`
+	Email                          string          `json:"email"`
+	Username                       string          `json:"username"`
+	PasswordHash                   string          `json:"password_hash"`
+	Role                           domain.UserRole `json:"role"`
+	CreatedAt                      time.Time       `json:"created_at"`
+	IsActive                       bool            `json:"is_active"`
+	AvatarFilename                 *string         `json:"avatar_filename"`
+	WeeklyUploadBytes              int64           `json:"weekly_upload_bytes"`
+	LastUploadReset                time.Time       `json:"last_upload_reset"`
+	DisplayName                    *string         `json:"display_name"`
+	WeeklyUploadLimitOverrideBytes *int64          `json:"weekly_upload_limit_override_bytes"`
+	UsernameChangedAt              *time.Time      `json:"username_changed_at"`
+}
+
+type UserSession struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	UserAgent  *string    `json:"user_agent"`
+	IpAddress  *string    `json:"ip_address"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
 }
 
 type Video struct {
@@ -213,4 +383,36 @@ type Video struct {
 	ProcessingStatus  domain.ProcessingStatus `json:"processing_status"`
 	ErrorMessage      *string                 `json:"error_message"`
 	CreatedAt         time.Time               `json:"created_at"`
+	ContentSha256     *string                 `json:"content_sha256"`
+	NeedsEnqueue      bool                    `json:"needs_enqueue"`
+	CommentsEnabled   bool                    `json:"comments_enabled"`
+	AudioTrackCount   *int32                  `json:"audio_track_count"`
+	VideoCodec        *string                 `json:"video_codec"`
+	OutputSha256      *string                 `json:"output_sha256"`
+}
+
+type VideoProcessingFailure struct {
+	VideoID      uuid.UUID `json:"video_id"`
+	Attempts     int32     `json:"attempts"`
+	LastFailedAt time.Time `json:"last_failed_at"`
+}
+
+type Webhook struct {
+	ID        uuid.UUID   `json:"id"`
+	Url       string      `json:"url"`
+	Secret    string      `json:"secret"`
+	Events    []string    `json:"events"`
+	CreatedBy pgtype.UUID `json:"created_by"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID         uuid.UUID `json:"id"`
+	WebhookID  uuid.UUID `json:"webhook_id"`
+	Event      string    `json:"event"`
+	Attempt    int32     `json:"attempt"`
+	StatusCode *int32    `json:"status_code"`
+	Error      *string   `json:"error"`
+	DurationMs int32     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
 }
@@ -59,6 +59,27 @@ func (q *Queries) CountPlaylistVideos(ctx context.Context, playlistID uuid.UUID)
 	return count, err
 }
 
+const countPlaylistsByUsername = `-- name: CountPlaylistsByUsername :one
+SELECT COUNT(*)
+FROM playlists p
+JOIN users u ON p.created_by = u.id
+WHERE LOWER(u.username) = LOWER($1)
+AND (p.is_public = TRUE OR p.created_by = $2 OR $3::boolean)
+`
+
+type CountPlaylistsByUsernameParams struct {
+	Username string    `json:"username"`
+	ViewerID uuid.UUID `json:"viewer_id"`
+	IsAdmin  bool      `json:"is_admin"`
+}
+
+func (q *Queries) CountPlaylistsByUsername(ctx context.Context, arg CountPlaylistsByUsernameParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countPlaylistsByUsername, arg.Username, arg.ViewerID, arg.IsAdmin)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countUserPlaylists = `-- name: CountUserPlaylists :one
 SELECT COUNT(*) FROM playlists WHERE created_by = $1
 `
@@ -75,7 +96,7 @@ INSERT INTO playlists (
     short_id, name, description, created_by, is_public
 ) VALUES (
     $1, $2, $3, $4, $5
-) RETURNING id, short_id, name, description, created_by, is_public, created_at, updated_at
+) RETURNING id, short_id, name, description, created_by, is_public, created_at, updated_at, playlist_type, smart_category_id, smart_sort, smart_limit
 `
 
 type CreatePlaylistParams struct {
@@ -104,6 +125,59 @@ func (q *Queries) CreatePlaylist(ctx context.Context, arg CreatePlaylistParams)
 		&i.IsPublic,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PlaylistType,
+		&i.SmartCategoryID,
+		&i.SmartSort,
+		&i.SmartLimit,
+	)
+	return i, err
+}
+
+const createSmartPlaylist = `-- name: CreateSmartPlaylist :one
+INSERT INTO playlists (
+    short_id, name, description, created_by, is_public,
+    playlist_type, smart_category_id, smart_sort, smart_limit
+) VALUES (
+    $1, $2, $3, $4, $5, 'smart', $6, $7, $8
+) RETURNING id, short_id, name, description, created_by, is_public, created_at, updated_at, playlist_type, smart_category_id, smart_sort, smart_limit
+`
+
+type CreateSmartPlaylistParams struct {
+	ShortID         string      `json:"short_id"`
+	Name            string      `json:"name"`
+	Description     *string     `json:"description"`
+	CreatedBy       uuid.UUID   `json:"created_by"`
+	IsPublic        bool        `json:"is_public"`
+	SmartCategoryID pgtype.UUID `json:"smart_category_id"`
+	SmartSort       *string     `json:"smart_sort"`
+	SmartLimit      *int32      `json:"smart_limit"`
+}
+
+func (q *Queries) CreateSmartPlaylist(ctx context.Context, arg CreateSmartPlaylistParams) (Playlist, error) {
+	row := q.db.QueryRow(ctx, createSmartPlaylist,
+		arg.ShortID,
+		arg.Name,
+		arg.Description,
+		arg.CreatedBy,
+		arg.IsPublic,
+		arg.SmartCategoryID,
+		arg.SmartSort,
+		arg.SmartLimit,
+	)
+	var i Playlist
+	err := row.Scan(
+		&i.ID,
+		&i.ShortID,
+		&i.Name,
+		&i.Description,
+		&i.CreatedBy,
+		&i.IsPublic,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PlaylistType,
+		&i.SmartCategoryID,
+		&i.SmartSort,
+		&i.SmartLimit,
 	)
 	return i, err
 }
@@ -145,7 +219,7 @@ func (q *Queries) GetMaxPlaylistPosition(ctx context.Context, playlistID uuid.UU
 }
 
 const getPlaylistByID = `-- name: GetPlaylistByID :one
-SELECT id, short_id, name, description, created_by, is_public, created_at, updated_at FROM playlists WHERE id = $1
+SELECT id, short_id, name, description, created_by, is_public, created_at, updated_at, playlist_type, smart_category_id, smart_sort, smart_limit FROM playlists WHERE id = $1
 `
 
 func (q *Queries) GetPlaylistByID(ctx context.Context, id uuid.UUID) (Playlist, error) {
@@ -160,12 +234,16 @@ func (q *Queries) GetPlaylistByID(ctx context.Context, id uuid.UUID) (Playlist,
 		&i.IsPublic,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PlaylistType,
+		&i.SmartCategoryID,
+		&i.SmartSort,
+		&i.SmartLimit,
 	)
 	return i, err
 }
 
 const getPlaylistByShortID = `-- name: GetPlaylistByShortID :one
-SELECT id, short_id, name, description, created_by, is_public, created_at, updated_at FROM playlists WHERE short_id = $1
+SELECT id, short_id, name, description, created_by, is_public, created_at, updated_at, playlist_type, smart_category_id, smart_sort, smart_limit FROM playlists WHERE short_id = $1
 `
 
 func (q *Queries) GetPlaylistByShortID(ctx context.Context, shortID string) (Playlist, error) {
@@ -180,6 +258,10 @@ func (q *Queries) GetPlaylistByShortID(ctx context.Context, shortID string) (Pla
 		&i.IsPublic,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PlaylistType,
+		&i.SmartCategoryID,
+		&i.SmartSort,
+		&i.SmartLimit,
 	)
 	return i, err
 }
@@ -219,7 +301,9 @@ SELECT
     v.view_count as video_view_count,
     v.processing_status as video_status,
     v.created_at as video_created_at,
-    vu.username as video_uploader_username
+    v.uploaded_by as video_uploaded_by,
+    vu.username as video_uploader_username,
+    COALESCE(vu.display_name, vu.username) as video_uploader_display_name
 FROM playlist_videos pv
 JOIN videos v ON pv.video_id = v.id
 JOIN users vu ON v.uploaded_by = vu.id
@@ -228,21 +312,23 @@ ORDER BY pv.position ASC
 `
 
 type GetPlaylistVideosRow struct {
-	ID                    uuid.UUID               `json:"id"`
-	PlaylistID            uuid.UUID               `json:"playlist_id"`
-	VideoID               uuid.UUID               `json:"video_id"`
-	Position              int32                   `json:"position"`
-	AddedAt               time.Time               `json:"added_at"`
-	AddedBy               pgtype.UUID             `json:"added_by"`
-	VideoShortID          string                  `json:"video_short_id"`
-	VideoTitle            string                  `json:"video_title"`
-	VideoDescription      *string                 `json:"video_description"`
-	VideoThumbnail        *string                 `json:"video_thumbnail"`
-	VideoDuration         *int32                  `json:"video_duration"`
-	VideoViewCount        int32                   `json:"video_view_count"`
-	VideoStatus           domain.ProcessingStatus `json:"video_status"`
-	VideoCreatedAt        time.Time               `json:"video_created_at"`
-	VideoUploaderUsername string                  `json:"video_uploader_username"`
+	ID                       uuid.UUID               `json:"id"`
+	PlaylistID               uuid.UUID               `json:"playlist_id"`
+	VideoID                  uuid.UUID               `json:"video_id"`
+	Position                 int32                   `json:"position"`
+	AddedAt                  time.Time               `json:"added_at"`
+	AddedBy                  pgtype.UUID             `json:"added_by"`
+	VideoShortID             string                  `json:"video_short_id"`
+	VideoTitle               string                  `json:"video_title"`
+	VideoDescription         *string                 `json:"video_description"`
+	VideoThumbnail           *string                 `json:"video_thumbnail"`
+	VideoDuration            *int32                  `json:"video_duration"`
+	VideoViewCount           int32                   `json:"video_view_count"`
+	VideoStatus              domain.ProcessingStatus `json:"video_status"`
+	VideoCreatedAt           time.Time               `json:"video_created_at"`
+	VideoUploadedBy          uuid.UUID               `json:"video_uploaded_by"`
+	VideoUploaderUsername    string                  `json:"video_uploader_username"`
+	VideoUploaderDisplayName string                  `json:"video_uploader_display_name"`
 }
 
 func (q *Queries) GetPlaylistVideos(ctx context.Context, playlistID uuid.UUID) ([]GetPlaylistVideosRow, error) {
@@ -269,7 +355,9 @@ func (q *Queries) GetPlaylistVideos(ctx context.Context, playlistID uuid.UUID) (
 			&i.VideoViewCount,
 			&i.VideoStatus,
 			&i.VideoCreatedAt,
+			&i.VideoUploadedBy,
 			&i.VideoUploaderUsername,
+			&i.VideoUploaderDisplayName,
 		); err != nil {
 			return nil, err
 		}
@@ -283,23 +371,29 @@ func (q *Queries) GetPlaylistVideos(ctx context.Context, playlistID uuid.UUID) (
 
 const getPlaylistWithVideos = `-- name: GetPlaylistWithVideos :one
 SELECT 
-    p.id, p.short_id, p.name, p.description, p.created_by, p.is_public, p.created_at, p.updated_at,
-    u.username as creator_username
+    p.id, p.short_id, p.name, p.description, p.created_by, p.is_public, p.created_at, p.updated_at, p.playlist_type, p.smart_category_id, p.smart_sort, p.smart_limit,
+    u.username as creator_username,
+    COALESCE(u.display_name, u.username) as creator_display_name
 FROM playlists p
 JOIN users u ON p.created_by = u.id
 WHERE p.short_id = $1
 `
 
 type GetPlaylistWithVideosRow struct {
-	ID              uuid.UUID `json:"id"`
-	ShortID         string    `json:"short_id"`
-	Name            string    `json:"name"`
-	Description     *string   `json:"description"`
-	CreatedBy       uuid.UUID `json:"created_by"`
-	IsPublic        bool      `json:"is_public"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
-	CreatorUsername string    `json:"creator_username"`
+	ID                 uuid.UUID           `json:"id"`
+	ShortID            string              `json:"short_id"`
+	Name               string              `json:"name"`
+	Description        *string             `json:"description"`
+	CreatedBy          uuid.UUID           `json:"created_by"`
+	IsPublic           bool                `json:"is_public"`
+	CreatedAt          time.Time           `json:"created_at"`
+	UpdatedAt          time.Time           `json:"updated_at"`
+	PlaylistType       domain.PlaylistType `json:"playlist_type"`
+	SmartCategoryID    pgtype.UUID         `json:"smart_category_id"`
+	SmartSort          *string             `json:"smart_sort"`
+	SmartLimit         *int32              `json:"smart_limit"`
+	CreatorUsername    string              `json:"creator_username"`
+	CreatorDisplayName string              `json:"creator_display_name"`
 }
 
 func (q *Queries) GetPlaylistWithVideos(ctx context.Context, shortID string) (GetPlaylistWithVideosRow, error) {
@@ -314,48 +408,93 @@ func (q *Queries) GetPlaylistWithVideos(ctx context.Context, shortID string) (Ge
 		&i.IsPublic,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PlaylistType,
+		&i.SmartCategoryID,
+		&i.SmartSort,
+		&i.SmartLimit,
 		&i.CreatorUsername,
+		&i.CreatorDisplayName,
 	)
 	return i, err
 }
 
 const getPlaylistsByUsername = `-- name: GetPlaylistsByUsername :many
 SELECT 
-    p.id, p.short_id, p.name, p.description, p.created_by, p.is_public, p.created_at, p.updated_at,
+    p.id, p.short_id, p.name, p.description, p.created_by, p.is_public, p.created_at, p.updated_at, p.playlist_type, p.smart_category_id, p.smart_sort, p.smart_limit,
     u.username as creator_username,
-    COUNT(pv.id) as video_count,
+    COALESCE(u.display_name, u.username) as creator_display_name,
+    COUNT(v.id) as video_count,
     (
-        SELECT v.thumbnail_filename 
-        FROM playlist_videos pv2 
-        JOIN videos v ON v.id = pv2.video_id 
-        WHERE pv2.playlist_id = p.id 
-        ORDER BY pv2.position ASC 
+        SELECT v2.thumbnail_filename
+        FROM playlist_videos pv2
+        JOIN videos v2 ON v2.id = pv2.video_id
+        WHERE pv2.playlist_id = p.id
+        AND (v2.processing_status = 'completed' OR v2.uploaded_by = $1 OR $2::boolean)
+        ORDER BY pv2.position ASC
         LIMIT 1
-    ) as first_video_thumbnail
+    ) as first_video_thumbnail,
+    (SELECT COUNT(*) FROM playlist_followers pf WHERE pf.playlist_id = p.id) as follower_count,
+    EXISTS(
+        SELECT 1 FROM playlist_followers pf2
+        WHERE pf2.playlist_id = p.id AND pf2.user_id = $1
+    ) as followed_by_me
 FROM playlists p
 JOIN users u ON p.created_by = u.id
 LEFT JOIN playlist_videos pv ON pv.playlist_id = p.id
-WHERE LOWER(u.username) = LOWER($1)
-GROUP BY p.id, u.username
-ORDER BY p.updated_at DESC
+LEFT JOIN videos v ON v.id = pv.video_id
+    AND (v.processing_status = 'completed' OR v.uploaded_by = $1 OR $2::boolean)
+WHERE LOWER(u.username) = LOWER($3)
+AND (p.is_public = TRUE OR p.created_by = $1 OR $2::boolean)
+GROUP BY p.id, u.username, u.display_name
+ORDER BY
+    CASE WHEN $4::text = 'newest' THEN p.created_at END DESC,
+    CASE WHEN $4::text = 'oldest' THEN p.created_at END ASC,
+    CASE WHEN $4::text = 'name' THEN LOWER(p.name) END ASC,
+    CASE WHEN $4::text = 'most_videos' THEN COUNT(v.id) END DESC,
+    p.updated_at DESC,
+    p.id
+LIMIT $6 OFFSET $5
 `
 
+type GetPlaylistsByUsernameParams struct {
+	ViewerID   uuid.UUID `json:"viewer_id"`
+	IsAdmin    bool      `json:"is_admin"`
+	Username   string    `json:"username"`
+	Sort       string    `json:"sort"`
+	PageOffset int32     `json:"page_offset"`
+	PageLimit  int32     `json:"page_limit"`
+}
+
 type GetPlaylistsByUsernameRow struct {
-	ID                  uuid.UUID `json:"id"`
-	ShortID             string    `json:"short_id"`
-	Name                string    `json:"name"`
-	Description         *string   `json:"description"`
-	CreatedBy           uuid.UUID `json:"created_by"`
-	IsPublic            bool      `json:"is_public"`
-	CreatedAt           time.Time `json:"created_at"`
-	UpdatedAt           time.Time `json:"updated_at"`
-	CreatorUsername     string    `json:"creator_username"`
-	VideoCount          int64     `json:"video_count"`
-	FirstVideoThumbnail *string   `json:"first_video_thumbnail"`
-}
-
-func (q *Queries) GetPlaylistsByUsername(ctx context.Context, lower string) ([]GetPlaylistsByUsernameRow, error) {
-	rows, err := q.db.Query(ctx, getPlaylistsByUsername, lower)
+	ID                  uuid.UUID           `json:"id"`
+	ShortID             string              `json:"short_id"`
+	Name                string              `json:"name"`
+	Description         *string             `json:"description"`
+	CreatedBy           uuid.UUID           `json:"created_by"`
+	IsPublic            bool                `json:"is_public"`
+	CreatedAt           time.Time           `json:"created_at"`
+	UpdatedAt           time.Time           `json:"updated_at"`
+	PlaylistType        domain.PlaylistType `json:"playlist_type"`
+	SmartCategoryID     pgtype.UUID         `json:"smart_category_id"`
+	SmartSort           *string             `json:"smart_sort"`
+	SmartLimit          *int32              `json:"smart_limit"`
+	CreatorUsername     string              `json:"creator_username"`
+	CreatorDisplayName  string              `json:"creator_display_name"`
+	VideoCount          int64               `json:"video_count"`
+	FirstVideoThumbnail *string             `json:"first_video_thumbnail"`
+	FollowerCount       int64               `json:"follower_count"`
+	FollowedByMe        bool                `json:"followed_by_me"`
+}
+
+func (q *Queries) GetPlaylistsByUsername(ctx context.Context, arg GetPlaylistsByUsernameParams) ([]GetPlaylistsByUsernameRow, error) {
+	rows, err := q.db.Query(ctx, getPlaylistsByUsername,
+		arg.ViewerID,
+		arg.IsAdmin,
+		arg.Username,
+		arg.Sort,
+		arg.PageOffset,
+		arg.PageLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -372,9 +511,16 @@ func (q *Queries) GetPlaylistsByUsername(ctx context.Context, lower string) ([]G
 			&i.IsPublic,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.PlaylistType,
+			&i.SmartCategoryID,
+			&i.SmartSort,
+			&i.SmartLimit,
 			&i.CreatorUsername,
+			&i.CreatorDisplayName,
 			&i.VideoCount,
 			&i.FirstVideoThumbnail,
+			&i.FollowerCount,
+			&i.FollowedByMe,
 		); err != nil {
 			return nil, err
 		}
@@ -388,15 +534,16 @@ func (q *Queries) GetPlaylistsByUsername(ctx context.Context, lower string) ([]G
 
 const getPlaylistsContainingVideo = `-- name: GetPlaylistsContainingVideo :many
 SELECT 
-    p.id, p.short_id, p.name, p.description, p.created_by, p.is_public, p.created_at, p.updated_at,
+    p.id, p.short_id, p.name, p.description, p.created_by, p.is_public, p.created_at, p.updated_at, p.playlist_type, p.smart_category_id, p.smart_sort, p.smart_limit,
     u.username as creator_username,
+    COALESCE(u.display_name, u.username) as creator_display_name,
     COUNT(pv.id) as video_count
 FROM playlists p
 JOIN users u ON p.created_by = u.id
 JOIN playlist_videos pv ON pv.playlist_id = p.id
 WHERE pv.video_id = $1
 AND (p.is_public = TRUE OR p.created_by = $2)
-GROUP BY p.id, u.username
+GROUP BY p.id, u.username, u.display_name
 ORDER BY p.name ASC
 `
 
@@ -406,16 +553,21 @@ type GetPlaylistsContainingVideoParams struct {
 }
 
 type GetPlaylistsContainingVideoRow struct {
-	ID              uuid.UUID `json:"id"`
-	ShortID         string    `json:"short_id"`
-	Name            string    `json:"name"`
-	Description     *string   `json:"description"`
-	CreatedBy       uuid.UUID `json:"created_by"`
-	IsPublic        bool      `json:"is_public"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
-	CreatorUsername string    `json:"creator_username"`
-	VideoCount      int64     `json:"video_count"`
+	ID                 uuid.UUID           `json:"id"`
+	ShortID            string              `json:"short_id"`
+	Name               string              `json:"name"`
+	Description        *string             `json:"description"`
+	CreatedBy          uuid.UUID           `json:"created_by"`
+	IsPublic           bool                `json:"is_public"`
+	CreatedAt          time.Time           `json:"created_at"`
+	UpdatedAt          time.Time           `json:"updated_at"`
+	PlaylistType       domain.PlaylistType `json:"playlist_type"`
+	SmartCategoryID    pgtype.UUID         `json:"smart_category_id"`
+	SmartSort          *string             `json:"smart_sort"`
+	SmartLimit         *int32              `json:"smart_limit"`
+	CreatorUsername    string              `json:"creator_username"`
+	CreatorDisplayName string              `json:"creator_display_name"`
+	VideoCount         int64               `json:"video_count"`
 }
 
 func (q *Queries) GetPlaylistsContainingVideo(ctx context.Context, arg GetPlaylistsContainingVideoParams) ([]GetPlaylistsContainingVideoRow, error) {
@@ -436,7 +588,12 @@ func (q *Queries) GetPlaylistsContainingVideo(ctx context.Context, arg GetPlayli
 			&i.IsPublic,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.PlaylistType,
+			&i.SmartCategoryID,
+			&i.SmartSort,
+			&i.SmartLimit,
 			&i.CreatorUsername,
+			&i.CreatorDisplayName,
 			&i.VideoCount,
 		); err != nil {
 			return nil, err
@@ -449,10 +606,27 @@ func (q *Queries) GetPlaylistsContainingVideo(ctx context.Context, arg GetPlayli
 	return items, nil
 }
 
+const incrementPlaylistPositions = `-- name: IncrementPlaylistPositions :exec
+UPDATE playlist_videos
+SET position = position + 1
+WHERE playlist_id = $1 AND position >= $2
+`
+
+type IncrementPlaylistPositionsParams struct {
+	PlaylistID uuid.UUID `json:"playlist_id"`
+	Position   int32     `json:"position"`
+}
+
+func (q *Queries) IncrementPlaylistPositions(ctx context.Context, arg IncrementPlaylistPositionsParams) error {
+	_, err := q.db.Exec(ctx, incrementPlaylistPositions, arg.PlaylistID, arg.Position)
+	return err
+}
+
 const listPlaylistsByUser = `-- name: ListPlaylistsByUser :many
 SELECT 
-    p.id, p.short_id, p.name, p.description, p.created_by, p.is_public, p.created_at, p.updated_at,
+    p.id, p.short_id, p.name, p.description, p.created_by, p.is_public, p.created_at, p.updated_at, p.playlist_type, p.smart_category_id, p.smart_sort, p.smart_limit,
     u.username as creator_username,
+    COALESCE(u.display_name, u.username) as creator_display_name,
     COUNT(pv.id) as video_count,
     (
         SELECT v.thumbnail_filename 
@@ -467,7 +641,7 @@ JOIN users u ON p.created_by = u.id
 LEFT JOIN playlist_videos pv ON pv.playlist_id = p.id
 WHERE p.created_by = $1
 AND (p.is_public = TRUE OR p.created_by = $2)
-GROUP BY p.id, u.username
+GROUP BY p.id, u.username, u.display_name
 ORDER BY p.updated_at DESC
 `
 
@@ -477,17 +651,22 @@ type ListPlaylistsByUserParams struct {
 }
 
 type ListPlaylistsByUserRow struct {
-	ID                  uuid.UUID `json:"id"`
-	ShortID             string    `json:"short_id"`
-	Name                string    `json:"name"`
-	Description         *string   `json:"description"`
-	CreatedBy           uuid.UUID `json:"created_by"`
-	IsPublic            bool      `json:"is_public"`
-	CreatedAt           time.Time `json:"created_at"`
-	UpdatedAt           time.Time `json:"updated_at"`
-	CreatorUsername     string    `json:"creator_username"`
-	VideoCount          int64     `json:"video_count"`
-	FirstVideoThumbnail *string   `json:"first_video_thumbnail"`
+	ID                  uuid.UUID           `json:"id"`
+	ShortID             string              `json:"short_id"`
+	Name                string              `json:"name"`
+	Description         *string             `json:"description"`
+	CreatedBy           uuid.UUID           `json:"created_by"`
+	IsPublic            bool                `json:"is_public"`
+	CreatedAt           time.Time           `json:"created_at"`
+	UpdatedAt           time.Time           `json:"updated_at"`
+	PlaylistType        domain.PlaylistType `json:"playlist_type"`
+	SmartCategoryID     pgtype.UUID         `json:"smart_category_id"`
+	SmartSort           *string             `json:"smart_sort"`
+	SmartLimit          *int32              `json:"smart_limit"`
+	CreatorUsername     string              `json:"creator_username"`
+	CreatorDisplayName  string              `json:"creator_display_name"`
+	VideoCount          int64               `json:"video_count"`
+	FirstVideoThumbnail *string             `json:"first_video_thumbnail"`
 }
 
 func (q *Queries) ListPlaylistsByUser(ctx context.Context, arg ListPlaylistsByUserParams) ([]ListPlaylistsByUserRow, error) {
@@ -508,7 +687,12 @@ func (q *Queries) ListPlaylistsByUser(ctx context.Context, arg ListPlaylistsByUs
 			&i.IsPublic,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.PlaylistType,
+			&i.SmartCategoryID,
+			&i.SmartSort,
+			&i.SmartLimit,
 			&i.CreatorUsername,
+			&i.CreatorDisplayName,
 			&i.VideoCount,
 			&i.FirstVideoThumbnail,
 		); err != nil {
@@ -522,43 +706,162 @@ func (q *Queries) ListPlaylistsByUser(ctx context.Context, arg ListPlaylistsByUs
 	return items, nil
 }
 
+const listSmartPlaylistVideos = `-- name: ListSmartPlaylistVideos :many
+SELECT
+    v.id as video_id,
+    v.short_id as video_short_id,
+    v.title as video_title,
+    v.description as video_description,
+    v.thumbnail_filename as video_thumbnail,
+    v.duration_seconds as video_duration,
+    v.view_count as video_view_count,
+    v.processing_status as video_status,
+    v.created_at as video_created_at,
+    v.uploaded_by as video_uploaded_by,
+    vu.username as video_uploader_username,
+    COALESCE(vu.display_name, vu.username) as video_uploader_display_name
+FROM videos v
+JOIN users vu ON v.uploaded_by = vu.id
+WHERE v.category_id = $1
+AND v.processing_status = 'completed'
+ORDER BY
+    CASE WHEN $2::text = 'oldest' THEN v.created_at END ASC,
+    CASE WHEN $2::text = 'most_viewed' THEN v.view_count END DESC,
+    v.created_at DESC
+LIMIT $3
+`
+
+type ListSmartPlaylistVideosParams struct {
+	CategoryID pgtype.UUID `json:"category_id"`
+	Sort       string      `json:"sort"`
+	MaxVideos  int32       `json:"max_videos"`
+}
+
+type ListSmartPlaylistVideosRow struct {
+	VideoID                  uuid.UUID               `json:"video_id"`
+	VideoShortID             string                  `json:"video_short_id"`
+	VideoTitle               string                  `json:"video_title"`
+	VideoDescription         *string                 `json:"video_description"`
+	VideoThumbnail           *string                 `json:"video_thumbnail"`
+	VideoDuration            *int32                  `json:"video_duration"`
+	VideoViewCount           int32                   `json:"video_view_count"`
+	VideoStatus              domain.ProcessingStatus `json:"video_status"`
+	VideoCreatedAt           time.Time               `json:"video_created_at"`
+	VideoUploadedBy          uuid.UUID               `json:"video_uploaded_by"`
+	VideoUploaderUsername    string                  `json:"video_uploader_username"`
+	VideoUploaderDisplayName string                  `json:"video_uploader_display_name"`
+}
+
+// Resolves a smart playlist rule: newest/oldest/most viewed completed videos in a category
+func (q *Queries) ListSmartPlaylistVideos(ctx context.Context, arg ListSmartPlaylistVideosParams) ([]ListSmartPlaylistVideosRow, error) {
+	rows, err := q.db.Query(ctx, listSmartPlaylistVideos, arg.CategoryID, arg.Sort, arg.MaxVideos)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListSmartPlaylistVideosRow{}
+	for rows.Next() {
+		var i ListSmartPlaylistVideosRow
+		if err := rows.Scan(
+			&i.VideoID,
+			&i.VideoShortID,
+			&i.VideoTitle,
+			&i.VideoDescription,
+			&i.VideoThumbnail,
+			&i.VideoDuration,
+			&i.VideoViewCount,
+			&i.VideoStatus,
+			&i.VideoCreatedAt,
+			&i.VideoUploadedBy,
+			&i.VideoUploaderUsername,
+			&i.VideoUploaderDisplayName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listUserPlaylistsWithThumbnail = `-- name: ListUserPlaylistsWithThumbnail :many
 SELECT 
-    p.id, p.short_id, p.name, p.description, p.created_by, p.is_public, p.created_at, p.updated_at,
+    p.id, p.short_id, p.name, p.description, p.created_by, p.is_public, p.created_at, p.updated_at, p.playlist_type, p.smart_category_id, p.smart_sort, p.smart_limit,
     u.username as creator_username,
-    COUNT(pv.id) as video_count,
+    COALESCE(u.display_name, u.username) as creator_display_name,
+    COUNT(v.id) as video_count,
     (
-        SELECT v.thumbnail_filename 
-        FROM playlist_videos pv2 
-        JOIN videos v ON v.id = pv2.video_id 
-        WHERE pv2.playlist_id = p.id 
-        ORDER BY pv2.position ASC 
+        SELECT v2.thumbnail_filename
+        FROM playlist_videos pv2
+        JOIN videos v2 ON v2.id = pv2.video_id
+        WHERE pv2.playlist_id = p.id
+        AND (v2.processing_status = 'completed' OR v2.uploaded_by = $1 OR $2::boolean)
+        ORDER BY pv2.position ASC
         LIMIT 1
-    ) as first_video_thumbnail
+    ) as first_video_thumbnail,
+    EXISTS(
+        SELECT 1 FROM playlist_videos pv3
+        WHERE pv3.playlist_id = p.id AND pv3.video_id = $3
+    ) as contains_video,
+    (SELECT COUNT(*) FROM playlist_followers pf WHERE pf.playlist_id = p.id) as follower_count
 FROM playlists p
 JOIN users u ON p.created_by = u.id
 LEFT JOIN playlist_videos pv ON pv.playlist_id = p.id
+LEFT JOIN videos v ON v.id = pv.video_id
+    AND (v.processing_status = 'completed' OR v.uploaded_by = $1 OR $2::boolean)
 WHERE p.created_by = $1
-GROUP BY p.id, u.username
-ORDER BY p.updated_at DESC
+GROUP BY p.id, u.username, u.display_name
+ORDER BY
+    CASE WHEN $4::text = 'newest' THEN p.created_at END DESC,
+    CASE WHEN $4::text = 'oldest' THEN p.created_at END ASC,
+    CASE WHEN $4::text = 'name' THEN LOWER(p.name) END ASC,
+    CASE WHEN $4::text = 'most_videos' THEN COUNT(v.id) END DESC,
+    p.updated_at DESC,
+    p.id
+LIMIT $6 OFFSET $5
 `
 
+type ListUserPlaylistsWithThumbnailParams struct {
+	CreatedBy  uuid.UUID `json:"created_by"`
+	IsAdmin    bool      `json:"is_admin"`
+	VideoID    uuid.UUID `json:"video_id"`
+	Sort       string    `json:"sort"`
+	PageOffset int32     `json:"page_offset"`
+	PageLimit  int32     `json:"page_limit"`
+}
+
 type ListUserPlaylistsWithThumbnailRow struct {
-	ID                  uuid.UUID `json:"id"`
-	ShortID             string    `json:"short_id"`
-	Name                string    `json:"name"`
-	Description         *string   `json:"description"`
-	CreatedBy           uuid.UUID `json:"created_by"`
-	IsPublic            bool      `json:"is_public"`
-	CreatedAt           time.Time `json:"created_at"`
-	UpdatedAt           time.Time `json:"updated_at"`
-	CreatorUsername     string    `json:"creator_username"`
-	VideoCount          int64     `json:"video_count"`
-	FirstVideoThumbnail *string   `json:"first_video_thumbnail"`
-}
-
-func (q *Queries) ListUserPlaylistsWithThumbnail(ctx context.Context, createdBy uuid.UUID) ([]ListUserPlaylistsWithThumbnailRow, error) {
-	rows, err := q.db.Query(ctx, listUserPlaylistsWithThumbnail, createdBy)
+	ID                  uuid.UUID           `json:"id"`
+	ShortID             string              `json:"short_id"`
+	Name                string              `json:"name"`
+	Description         *string             `json:"description"`
+	CreatedBy           uuid.UUID           `json:"created_by"`
+	IsPublic            bool                `json:"is_public"`
+	CreatedAt           time.Time           `json:"created_at"`
+	UpdatedAt           time.Time           `json:"updated_at"`
+	PlaylistType        domain.PlaylistType `json:"playlist_type"`
+	SmartCategoryID     pgtype.UUID         `json:"smart_category_id"`
+	SmartSort           *string             `json:"smart_sort"`
+	SmartLimit          *int32              `json:"smart_limit"`
+	CreatorUsername     string              `json:"creator_username"`
+	CreatorDisplayName  string              `json:"creator_display_name"`
+	VideoCount          int64               `json:"video_count"`
+	FirstVideoThumbnail *string             `json:"first_video_thumbnail"`
+	ContainsVideo       bool                `json:"contains_video"`
+	FollowerCount       int64               `json:"follower_count"`
+}
+
+func (q *Queries) ListUserPlaylistsWithThumbnail(ctx context.Context, arg ListUserPlaylistsWithThumbnailParams) ([]ListUserPlaylistsWithThumbnailRow, error) {
+	rows, err := q.db.Query(ctx, listUserPlaylistsWithThumbnail,
+		arg.CreatedBy,
+		arg.IsAdmin,
+		arg.VideoID,
+		arg.Sort,
+		arg.PageOffset,
+		arg.PageLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -575,9 +878,16 @@ func (q *Queries) ListUserPlaylistsWithThumbnail(ctx context.Context, createdBy
 			&i.IsPublic,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.PlaylistType,
+			&i.SmartCategoryID,
+			&i.SmartSort,
+			&i.SmartLimit,
 			&i.CreatorUsername,
+			&i.CreatorDisplayName,
 			&i.VideoCount,
 			&i.FirstVideoThumbnail,
+			&i.ContainsVideo,
+			&i.FollowerCount,
 		); err != nil {
 			return nil, err
 		}
@@ -615,6 +925,26 @@ func (q *Queries) RemoveVideoFromPlaylist(ctx context.Context, arg RemoveVideoFr
 	return err
 }
 
+const reorderPlaylistVideos = `-- name: ReorderPlaylistVideos :exec
+UPDATE playlist_videos pv
+SET position = np.position
+FROM (
+    SELECT UNNEST($2::uuid[]) AS video_id, UNNEST($3::int[]) AS position
+) AS np
+WHERE pv.playlist_id = $1 AND pv.video_id = np.video_id
+`
+
+type ReorderPlaylistVideosParams struct {
+	PlaylistID uuid.UUID   `json:"playlist_id"`
+	VideoIds   []uuid.UUID `json:"video_ids"`
+	Positions  []int32     `json:"positions"`
+}
+
+func (q *Queries) ReorderPlaylistVideos(ctx context.Context, arg ReorderPlaylistVideosParams) error {
+	_, err := q.db.Exec(ctx, reorderPlaylistVideos, arg.PlaylistID, arg.VideoIds, arg.Positions)
+	return err
+}
+
 const updatePlaylist = `-- name: UpdatePlaylist :one
 UPDATE playlists SET
     name = COALESCE(NULLIF($2, ''), name),
@@ -622,7 +952,7 @@ UPDATE playlists SET
     is_public = COALESCE($4, is_public),
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, short_id, name, description, created_by, is_public, created_at, updated_at
+RETURNING id, short_id, name, description, created_by, is_public, created_at, updated_at, playlist_type, smart_category_id, smart_sort, smart_limit
 `
 
 type UpdatePlaylistParams struct {
@@ -649,6 +979,10 @@ func (q *Queries) UpdatePlaylist(ctx context.Context, arg UpdatePlaylistParams)
 		&i.IsPublic,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PlaylistType,
+		&i.SmartCategoryID,
+		&i.SmartSort,
+		&i.SmartLimit,
 	)
 	return i, err
 }
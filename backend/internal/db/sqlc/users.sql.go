@@ -44,12 +44,33 @@ func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const countUsersWithFilters = `-- name: CountUsersWithFilters :one
+SELECT COUNT(*) FROM users u
+WHERE
+    ($1::text = '' OR LOWER(u.username) LIKE '%' || LOWER($1) || '%' OR LOWER(u.email) LIKE '%' || LOWER($1) || '%')
+    AND ($2::text = '' OR u.role::text = $2)
+    AND ($3::text = '' OR u.is_active::text = $3)
+`
+
+type CountUsersWithFiltersParams struct {
+	Column1 string `json:"column_1"`
+	Column2 string `json:"column_2"`
+	Column3 string `json:"column_3"`
+}
+
+func (q *Queries) CountUsersWithFilters(ctx context.Context, arg CountUsersWithFiltersParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countUsersWithFilters, arg.Column1, arg.Column2, arg.Column3)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (
     email, username, password_hash, role
 ) VALUES (
     $1, $2, $3, $4
-) RETURNING id, email, username, password_hash, role, created_at, is_active, avatar_filename, weekly_upload_bytes, last_upload_reset
+) RETURNING id, email, username, password_hash, role, created_at, is_active, avatar_filename, weekly_upload_bytes, last_upload_reset, display_name, weekly_upload_limit_override_bytes, username_changed_at
 `
 
 type CreateUserParams struct {
@@ -78,6 +99,9 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.AvatarFilename,
 		&i.WeeklyUploadBytes,
 		&i.LastUploadReset,
+		&i.DisplayName,
+		&i.WeeklyUploadLimitOverrideBytes,
+		&i.UsernameChangedAt,
 	)
 	return i, err
 }
@@ -94,7 +118,7 @@ func (q *Queries) DeactivateUser(ctx context.Context, id uuid.UUID) error {
 const deleteUserAvatar = `-- name: DeleteUserAvatar :one
 UPDATE users SET avatar_filename = NULL
 WHERE id = $1
-RETURNING id, email, username, password_hash, role, created_at, is_active, avatar_filename, weekly_upload_bytes, last_upload_reset
+RETURNING id, email, username, password_hash, role, created_at, is_active, avatar_filename, weekly_upload_bytes, last_upload_reset, display_name, weekly_upload_limit_override_bytes, username_changed_at
 `
 
 func (q *Queries) DeleteUserAvatar(ctx context.Context, id uuid.UUID) (User, error) {
@@ -111,12 +135,15 @@ func (q *Queries) DeleteUserAvatar(ctx context.Context, id uuid.UUID) (User, err
 		&i.AvatarFilename,
 		&i.WeeklyUploadBytes,
 		&i.LastUploadReset,
+		&i.DisplayName,
+		&i.WeeklyUploadLimitOverrideBytes,
+		&i.UsernameChangedAt,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, username, password_hash, role, created_at, is_active, avatar_filename, weekly_upload_bytes, last_upload_reset FROM users WHERE LOWER(email) = LOWER($1)
+SELECT id, email, username, password_hash, role, created_at, is_active, avatar_filename, weekly_upload_bytes, last_upload_reset, display_name, weekly_upload_limit_override_bytes, username_changed_at FROM users WHERE LOWER(email) = LOWER($1)
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, lower string) (User, error) {
@@ -133,12 +160,15 @@ func (q *Queries) GetUserByEmail(ctx context.Context, lower string) (User, error
 		&i.AvatarFilename,
 		&i.WeeklyUploadBytes,
 		&i.LastUploadReset,
+		&i.DisplayName,
+		&i.WeeklyUploadLimitOverrideBytes,
+		&i.UsernameChangedAt,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, email, username, password_hash, role, created_at, is_active, avatar_filename, weekly_upload_bytes, last_upload_reset FROM users WHERE id = $1
+SELECT id, email, username, password_hash, role, created_at, is_active, avatar_filename, weekly_upload_bytes, last_upload_reset, display_name, weekly_upload_limit_override_bytes, username_changed_at FROM users WHERE id = $1
 `
 
 func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
@@ -155,12 +185,15 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.AvatarFilename,
 		&i.WeeklyUploadBytes,
 		&i.LastUploadReset,
+		&i.DisplayName,
+		&i.WeeklyUploadLimitOverrideBytes,
+		&i.UsernameChangedAt,
 	)
 	return i, err
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, email, username, password_hash, role, created_at, is_active, avatar_filename, weekly_upload_bytes, last_upload_reset FROM users WHERE LOWER(username) = LOWER($1)
+SELECT id, email, username, password_hash, role, created_at, is_active, avatar_filename, weekly_upload_bytes, last_upload_reset, display_name, weekly_upload_limit_override_bytes, username_changed_at FROM users WHERE LOWER(username) = LOWER($1)
 `
 
 func (q *Queries) GetUserByUsername(ctx context.Context, lower string) (User, error) {
@@ -177,13 +210,16 @@ func (q *Queries) GetUserByUsername(ctx context.Context, lower string) (User, er
 		&i.AvatarFilename,
 		&i.WeeklyUploadBytes,
 		&i.LastUploadReset,
+		&i.DisplayName,
+		&i.WeeklyUploadLimitOverrideBytes,
+		&i.UsernameChangedAt,
 	)
 	return i, err
 }
 
 const getUserByUsernameWithCounts = `-- name: GetUserByUsernameWithCounts :one
 SELECT 
-    u.id, u.email, u.username, u.password_hash, u.role, u.created_at, u.is_active, u.avatar_filename, u.weekly_upload_bytes, u.last_upload_reset,
+    u.id, u.email, u.username, u.password_hash, u.role, u.created_at, u.is_active, u.avatar_filename, u.weekly_upload_bytes, u.last_upload_reset, u.display_name, u.weekly_upload_limit_override_bytes, u.username_changed_at,
     COUNT(DISTINCT v.id) as video_count,
     COUNT(DISTINCT p.id) as playlist_count
 FROM users u
@@ -194,18 +230,21 @@ GROUP BY u.id
 `
 
 type GetUserByUsernameWithCountsRow struct {
-	ID                uuid.UUID       `json:"id"`
-	Email             string          `json:"email"`
-	Username          string          `json:"username"`
-	PasswordHash      string          `json:"password_hash"`
-	Role              domain.UserRole `json:"role"`
-	CreatedAt         time.Time       `json:"created_at"`
-	IsActive          bool            `json:"is_active"`
-	AvatarFilename    *string         `json:"avatar_filename"`
-	WeeklyUploadBytes int64           `json:"weekly_upload_bytes"`
-	LastUploadReset   time.Time       `json:"last_upload_reset"`
-	VideoCount        int64           `json:"video_count"`
-	PlaylistCount     int64           `json:"playlist_count"`
+	ID                             uuid.UUID       `json:"id"`
+	Email                          string          `json:"email"`
+	Username                       string          `json:"username"`
+	PasswordHash                   string          `json:"password_hash"`
+	Role                           domain.UserRole `json:"role"`
+	CreatedAt                      time.Time       `json:"created_at"`
+	IsActive                       bool            `json:"is_active"`
+	AvatarFilename                 *string         `json:"avatar_filename"`
+	WeeklyUploadBytes              int64           `json:"weekly_upload_bytes"`
+	LastUploadReset                time.Time       `json:"last_upload_reset"`
+	DisplayName                    *string         `json:"display_name"`
+	WeeklyUploadLimitOverrideBytes *int64          `json:"weekly_upload_limit_override_bytes"`
+	UsernameChangedAt              *time.Time      `json:"username_changed_at"`
+	VideoCount                     int64           `json:"video_count"`
+	PlaylistCount                  int64           `json:"playlist_count"`
 }
 
 func (q *Queries) GetUserByUsernameWithCounts(ctx context.Context, lower string) (GetUserByUsernameWithCountsRow, error) {
@@ -222,6 +261,9 @@ func (q *Queries) GetUserByUsernameWithCounts(ctx context.Context, lower string)
 		&i.AvatarFilename,
 		&i.WeeklyUploadBytes,
 		&i.LastUploadReset,
+		&i.DisplayName,
+		&i.WeeklyUploadLimitOverrideBytes,
+		&i.UsernameChangedAt,
 		&i.VideoCount,
 		&i.PlaylistCount,
 	)
@@ -229,24 +271,25 @@ func (q *Queries) GetUserByUsernameWithCounts(ctx context.Context, lower string)
 }
 
 const getUserQuota = `-- name: GetUserQuota :one
-SELECT weekly_upload_bytes, last_upload_reset FROM users WHERE id = $1
+SELECT weekly_upload_bytes, last_upload_reset, weekly_upload_limit_override_bytes FROM users WHERE id = $1
 `
 
 type GetUserQuotaRow struct {
-	WeeklyUploadBytes int64     `json:"weekly_upload_bytes"`
-	LastUploadReset   time.Time `json:"last_upload_reset"`
+	WeeklyUploadBytes              int64     `json:"weekly_upload_bytes"`
+	LastUploadReset                time.Time `json:"last_upload_reset"`
+	WeeklyUploadLimitOverrideBytes *int64    `json:"weekly_upload_limit_override_bytes"`
 }
 
 func (q *Queries) GetUserQuota(ctx context.Context, id uuid.UUID) (GetUserQuotaRow, error) {
 	row := q.db.QueryRow(ctx, getUserQuota, id)
 	var i GetUserQuotaRow
-	err := row.Scan(&i.WeeklyUploadBytes, &i.LastUploadReset)
+	err := row.Scan(&i.WeeklyUploadBytes, &i.LastUploadReset, &i.WeeklyUploadLimitOverrideBytes)
 	return i, err
 }
 
 const getUserWithCounts = `-- name: GetUserWithCounts :one
 SELECT 
-    u.id, u.email, u.username, u.password_hash, u.role, u.created_at, u.is_active, u.avatar_filename, u.weekly_upload_bytes, u.last_upload_reset,
+    u.id, u.email, u.username, u.password_hash, u.role, u.created_at, u.is_active, u.avatar_filename, u.weekly_upload_bytes, u.last_upload_reset, u.display_name, u.weekly_upload_limit_override_bytes, u.username_changed_at,
     COUNT(DISTINCT v.id) as video_count,
     COUNT(DISTINCT p.id) as playlist_count
 FROM users u
@@ -257,18 +300,21 @@ GROUP BY u.id
 `
 
 type GetUserWithCountsRow struct {
-	ID                uuid.UUID       `json:"id"`
-	Email             string          `json:"email"`
-	Username          string          `json:"username"`
-	PasswordHash      string          `json:"password_hash"`
-	Role              domain.UserRole `json:"role"`
-	CreatedAt         time.Time       `json:"created_at"`
-	IsActive          bool            `json:"is_active"`
-	AvatarFilename    *string         `json:"avatar_filename"`
-	WeeklyUploadBytes int64           `json:"weekly_upload_bytes"`
-	LastUploadReset   time.Time       `json:"last_upload_reset"`
-	VideoCount        int64           `json:"video_count"`
-	PlaylistCount     int64           `json:"playlist_count"`
+	ID                             uuid.UUID       `json:"id"`
+	Email                          string          `json:"email"`
+	Username                       string          `json:"username"`
+	PasswordHash                   string          `json:"password_hash"`
+	Role                           domain.UserRole `json:"role"`
+	CreatedAt                      time.Time       `json:"created_at"`
+	IsActive                       bool            `json:"is_active"`
+	AvatarFilename                 *string         `json:"avatar_filename"`
+	WeeklyUploadBytes              int64           `json:"weekly_upload_bytes"`
+	LastUploadReset                time.Time       `json:"last_upload_reset"`
+	DisplayName                    *string         `json:"display_name"`
+	WeeklyUploadLimitOverrideBytes *int64          `json:"weekly_upload_limit_override_bytes"`
+	UsernameChangedAt              *time.Time      `json:"username_changed_at"`
+	VideoCount                     int64           `json:"video_count"`
+	PlaylistCount                  int64           `json:"playlist_count"`
 }
 
 func (q *Queries) GetUserWithCounts(ctx context.Context, id uuid.UUID) (GetUserWithCountsRow, error) {
@@ -285,14 +331,48 @@ func (q *Queries) GetUserWithCounts(ctx context.Context, id uuid.UUID) (GetUserW
 		&i.AvatarFilename,
 		&i.WeeklyUploadBytes,
 		&i.LastUploadReset,
+		&i.DisplayName,
+		&i.WeeklyUploadLimitOverrideBytes,
+		&i.UsernameChangedAt,
 		&i.VideoCount,
 		&i.PlaylistCount,
 	)
 	return i, err
 }
 
+const listUserAvatarFiles = `-- name: ListUserAvatarFiles :many
+SELECT id, avatar_filename FROM users
+WHERE avatar_filename IS NOT NULL
+ORDER BY created_at ASC
+`
+
+type ListUserAvatarFilesRow struct {
+	ID             uuid.UUID `json:"id"`
+	AvatarFilename *string   `json:"avatar_filename"`
+}
+
+func (q *Queries) ListUserAvatarFiles(ctx context.Context) ([]ListUserAvatarFilesRow, error) {
+	rows, err := q.db.Query(ctx, listUserAvatarFiles)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUserAvatarFilesRow{}
+	for rows.Next() {
+		var i ListUserAvatarFilesRow
+		if err := rows.Scan(&i.ID, &i.AvatarFilename); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listUsers = `-- name: ListUsers :many
-SELECT id, email, username, password_hash, role, created_at, is_active, avatar_filename, weekly_upload_bytes, last_upload_reset FROM users
+SELECT id, email, username, password_hash, role, created_at, is_active, avatar_filename, weekly_upload_bytes, last_upload_reset, display_name, weekly_upload_limit_override_bytes, username_changed_at FROM users
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2
 `
@@ -322,6 +402,9 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 			&i.AvatarFilename,
 			&i.WeeklyUploadBytes,
 			&i.LastUploadReset,
+			&i.DisplayName,
+			&i.WeeklyUploadLimitOverrideBytes,
+			&i.UsernameChangedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -335,7 +418,7 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 
 const listUsersDirectory = `-- name: ListUsersDirectory :many
 SELECT 
-    u.id, u.email, u.username, u.password_hash, u.role, u.created_at, u.is_active, u.avatar_filename, u.weekly_upload_bytes, u.last_upload_reset,
+    u.id, u.email, u.username, u.password_hash, u.role, u.created_at, u.is_active, u.avatar_filename, u.weekly_upload_bytes, u.last_upload_reset, u.display_name, u.weekly_upload_limit_override_bytes, u.username_changed_at,
     COUNT(DISTINCT v.id) as video_count,
     COUNT(DISTINCT p.id) as playlist_count
 FROM users u
@@ -344,7 +427,8 @@ LEFT JOIN playlists p ON p.created_by = u.id AND p.is_public = TRUE
 WHERE u.is_active = TRUE
 AND (
     $1::text IS NULL OR $1 = '' OR
-    LOWER(u.username) LIKE '%' || LOWER($1) || '%'
+    LOWER(u.username) LIKE '%' || LOWER($1) || '%' OR
+    LOWER(u.display_name) LIKE '%' || LOWER($1) || '%'
 )
 GROUP BY u.id
 ORDER BY
@@ -361,18 +445,21 @@ type ListUsersDirectoryParams struct {
 }
 
 type ListUsersDirectoryRow struct {
-	ID                uuid.UUID       `json:"id"`
-	Email             string          `json:"email"`
-	Username          string          `json:"username"`
-	PasswordHash      string          `json:"password_hash"`
-	Role              domain.UserRole `json:"role"`
-	CreatedAt         time.Time       `json:"created_at"`
-	IsActive          bool            `json:"is_active"`
-	AvatarFilename    *string         `json:"avatar_filename"`
-	WeeklyUploadBytes int64           `json:"weekly_upload_bytes"`
-	LastUploadReset   time.Time       `json:"last_upload_reset"`
-	VideoCount        int64           `json:"video_count"`
-	PlaylistCount     int64           `json:"playlist_count"`
+	ID                             uuid.UUID       `json:"id"`
+	Email                          string          `json:"email"`
+	Username                       string          `json:"username"`
+	PasswordHash                   string          `json:"password_hash"`
+	Role                           domain.UserRole `json:"role"`
+	CreatedAt                      time.Time       `json:"created_at"`
+	IsActive                       bool            `json:"is_active"`
+	AvatarFilename                 *string         `json:"avatar_filename"`
+	WeeklyUploadBytes              int64           `json:"weekly_upload_bytes"`
+	LastUploadReset                time.Time       `json:"last_upload_reset"`
+	DisplayName                    *string         `json:"display_name"`
+	WeeklyUploadLimitOverrideBytes *int64          `json:"weekly_upload_limit_override_bytes"`
+	UsernameChangedAt              *time.Time      `json:"username_changed_at"`
+	VideoCount                     int64           `json:"video_count"`
+	PlaylistCount                  int64           `json:"playlist_count"`
 }
 
 func (q *Queries) ListUsersDirectory(ctx context.Context, arg ListUsersDirectoryParams) ([]ListUsersDirectoryRow, error) {
@@ -395,6 +482,9 @@ func (q *Queries) ListUsersDirectory(ctx context.Context, arg ListUsersDirectory
 			&i.AvatarFilename,
 			&i.WeeklyUploadBytes,
 			&i.LastUploadReset,
+			&i.DisplayName,
+			&i.WeeklyUploadLimitOverrideBytes,
+			&i.UsernameChangedAt,
 			&i.VideoCount,
 			&i.PlaylistCount,
 		); err != nil {
@@ -410,39 +500,70 @@ func (q *Queries) ListUsersDirectory(ctx context.Context, arg ListUsersDirectory
 
 const listUsersWithCounts = `-- name: ListUsersWithCounts :many
 SELECT 
-    u.id, u.email, u.username, u.password_hash, u.role, u.created_at, u.is_active, u.avatar_filename, u.weekly_upload_bytes, u.last_upload_reset,
+    u.id, u.email, u.username, u.password_hash, u.role, u.created_at, u.is_active, u.avatar_filename, u.weekly_upload_bytes, u.last_upload_reset, u.display_name, u.weekly_upload_limit_override_bytes, u.username_changed_at,
     COUNT(DISTINCT v.id) as video_count,
-    COUNT(DISTINCT p.id) as playlist_count
+    COUNT(DISTINCT p.id) as playlist_count,
+    EXISTS(
+        SELECT 1 FROM email_change_requests ecr
+        WHERE ecr.user_id = u.id AND ecr.expires_at > NOW()
+    ) as pending_email_change,
+    (SELECT COALESCE(SUM(sv.file_size_bytes), 0) FROM videos sv WHERE sv.uploaded_by = u.id)::bigint as storage_bytes
 FROM users u
 LEFT JOIN videos v ON v.uploaded_by = u.id
 LEFT JOIN playlists p ON p.created_by = u.id
+WHERE
+    ($1::text = '' OR LOWER(u.username) LIKE '%' || LOWER($1) || '%' OR LOWER(u.email) LIKE '%' || LOWER($1) || '%')
+    AND ($2::text = '' OR u.role::text = $2)
+    AND ($3::text = '' OR u.is_active::text = $3)
 GROUP BY u.id
-ORDER BY u.created_at DESC
-LIMIT $1 OFFSET $2
+ORDER BY
+    CASE WHEN $4 = 'username' THEN LOWER(u.username) END ASC,
+    CASE WHEN $4 = 'video_count' THEN COUNT(DISTINCT v.id) END DESC,
+    CASE WHEN $4 = 'storage' THEN (SELECT COALESCE(SUM(sv.file_size_bytes), 0) FROM videos sv WHERE sv.uploaded_by = u.id) END DESC,
+    u.created_at DESC
+LIMIT $5 OFFSET $6
 `
 
 type ListUsersWithCountsParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	Column1 string      `json:"column_1"`
+	Column2 string      `json:"column_2"`
+	Column3 string      `json:"column_3"`
+	Column4 interface{} `json:"column_4"`
+	Limit   int32       `json:"limit"`
+	Offset  int32       `json:"offset"`
 }
 
 type ListUsersWithCountsRow struct {
-	ID                uuid.UUID       `json:"id"`
-	Email             string          `json:"email"`
-	Username          string          `json:"username"`
-	PasswordHash      string          `json:"password_hash"`
-	Role              domain.UserRole `json:"role"`
-	CreatedAt         time.Time       `json:"created_at"`
-	IsActive          bool            `json:"is_active"`
-	AvatarFilename    *string         `json:"avatar_filename"`
-	WeeklyUploadBytes int64           `json:"weekly_upload_bytes"`
-	LastUploadReset   time.Time       `json:"last_upload_reset"`
-	VideoCount        int64           `json:"video_count"`
-	PlaylistCount     int64           `json:"playlist_count"`
-}
-
+	ID                             uuid.UUID       `json:"id"`
+	Email                          string          `json:"email"`
+	Username                       string          `json:"username"`
+	PasswordHash                   string          `json:"password_hash"`
+	Role                           domain.UserRole `json:"role"`
+	CreatedAt                      time.Time       `json:"created_at"`
+	IsActive                       bool            `json:"is_active"`
+	AvatarFilename                 *string         `json:"avatar_filename"`
+	WeeklyUploadBytes              int64           `json:"weekly_upload_bytes"`
+	LastUploadReset                time.Time       `json:"last_upload_reset"`
+	DisplayName                    *string         `json:"display_name"`
+	WeeklyUploadLimitOverrideBytes *int64          `json:"weekly_upload_limit_override_bytes"`
+	UsernameChangedAt              *time.Time      `json:"username_changed_at"`
+	VideoCount                     int64           `json:"video_count"`
+	PlaylistCount                  int64           `json:"playlist_count"`
+	PendingEmailChange             bool            `json:"pending_email_change"`
+	StorageBytes                   int64           `json:"storage_bytes"`
+}
+
+// Admin user list. Filters are optional (empty string = no filter);
+// unknown sort values fall back to newest first.
 func (q *Queries) ListUsersWithCounts(ctx context.Context, arg ListUsersWithCountsParams) ([]ListUsersWithCountsRow, error) {
-	rows, err := q.db.Query(ctx, listUsersWithCounts, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listUsersWithCounts,
+		arg.Column1,
+		arg.Column2,
+		arg.Column3,
+		arg.Column4,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -461,8 +582,13 @@ func (q *Queries) ListUsersWithCounts(ctx context.Context, arg ListUsersWithCoun
 			&i.AvatarFilename,
 			&i.WeeklyUploadBytes,
 			&i.LastUploadReset,
+			&i.DisplayName,
+			&i.WeeklyUploadLimitOverrideBytes,
+			&i.UsernameChangedAt,
 			&i.VideoCount,
 			&i.PlaylistCount,
+			&i.PendingEmailChange,
+			&i.StorageBytes,
 		); err != nil {
 			return nil, err
 		}
@@ -485,6 +611,34 @@ func (q *Queries) ResetAllUploadQuotas(ctx context.Context) error {
 	return err
 }
 
+const resetExpiredUploadQuotas = `-- name: ResetExpiredUploadQuotas :one
+WITH expired AS (
+    SELECT id, weekly_upload_bytes FROM users
+    WHERE last_upload_reset < $1
+    FOR UPDATE
+), reset AS (
+    UPDATE users u SET
+        weekly_upload_bytes = 0,
+        last_upload_reset = NOW()
+    FROM expired
+    WHERE u.id = expired.id
+    RETURNING u.id, expired.weekly_upload_bytes AS previous_bytes
+), events AS (
+    INSERT INTO quota_events (user_id, event_type, bytes_delta, used_bytes_after)
+    SELECT id, 'weekly_reset', -previous_bytes, 0 FROM reset
+)
+SELECT COUNT(*) FROM reset
+`
+
+// Resets usage for users last reset before the cutoff and records a
+// weekly_reset event for each, in one statement; returns how many were reset
+func (q *Queries) ResetExpiredUploadQuotas(ctx context.Context, cutoff time.Time) (int64, error) {
+	row := q.db.QueryRow(ctx, resetExpiredUploadQuotas, cutoff)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const resetUploadQuota = `-- name: ResetUploadQuota :exec
 UPDATE users SET 
     weekly_upload_bytes = 0,
@@ -497,6 +651,39 @@ func (q *Queries) ResetUploadQuota(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const updateUploadLimitOverride = `-- name: UpdateUploadLimitOverride :one
+UPDATE users SET weekly_upload_limit_override_bytes = $2
+WHERE id = $1
+RETURNING id, email, username, password_hash, role, created_at, is_active, avatar_filename, weekly_upload_bytes, last_upload_reset, display_name, weekly_upload_limit_override_bytes, username_changed_at
+`
+
+type UpdateUploadLimitOverrideParams struct {
+	ID                             uuid.UUID `json:"id"`
+	WeeklyUploadLimitOverrideBytes *int64    `json:"weekly_upload_limit_override_bytes"`
+}
+
+// A NULL override falls back to the global weekly limit
+func (q *Queries) UpdateUploadLimitOverride(ctx context.Context, arg UpdateUploadLimitOverrideParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUploadLimitOverride, arg.ID, arg.WeeklyUploadLimitOverrideBytes)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.PasswordHash,
+		&i.Role,
+		&i.CreatedAt,
+		&i.IsActive,
+		&i.AvatarFilename,
+		&i.WeeklyUploadBytes,
+		&i.LastUploadReset,
+		&i.DisplayName,
+		&i.WeeklyUploadLimitOverrideBytes,
+		&i.UsernameChangedAt,
+	)
+	return i, err
+}
+
 const updateUploadQuota = `-- name: UpdateUploadQuota :exec
 UPDATE users SET 
     weekly_upload_bytes = weekly_upload_bytes + $2
@@ -520,7 +707,7 @@ UPDATE users SET
     avatar_filename = $4,
     is_active = COALESCE($5, is_active)
 WHERE id = $1
-RETURNING id, email, username, password_hash, role, created_at, is_active, avatar_filename, weekly_upload_bytes, last_upload_reset
+RETURNING id, email, username, password_hash, role, created_at, is_active, avatar_filename, weekly_upload_bytes, last_upload_reset, display_name, weekly_upload_limit_override_bytes, username_changed_at
 `
 
 type UpdateUserParams struct {
@@ -551,6 +738,9 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.AvatarFilename,
 		&i.WeeklyUploadBytes,
 		&i.LastUploadReset,
+		&i.DisplayName,
+		&i.WeeklyUploadLimitOverrideBytes,
+		&i.UsernameChangedAt,
 	)
 	return i, err
 }
@@ -558,7 +748,7 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 const updateUserAvatar = `-- name: UpdateUserAvatar :one
 UPDATE users SET avatar_filename = $2
 WHERE id = $1
-RETURNING id, email, username, password_hash, role, created_at, is_active, avatar_filename, weekly_upload_bytes, last_upload_reset
+RETURNING id, email, username, password_hash, role, created_at, is_active, avatar_filename, weekly_upload_bytes, last_upload_reset, display_name, weekly_upload_limit_override_bytes, username_changed_at
 `
 
 type UpdateUserAvatarParams struct {
@@ -580,6 +770,69 @@ func (q *Queries) UpdateUserAvatar(ctx context.Context, arg UpdateUserAvatarPara
 		&i.AvatarFilename,
 		&i.WeeklyUploadBytes,
 		&i.LastUploadReset,
+		&i.DisplayName,
+		&i.WeeklyUploadLimitOverrideBytes,
+		&i.UsernameChangedAt,
+	)
+	return i, err
+}
+
+const updateUserDisplayName = `-- name: UpdateUserDisplayName :exec
+UPDATE users SET display_name = $2 WHERE id = $1
+`
+
+type UpdateUserDisplayNameParams struct {
+	ID          uuid.UUID `json:"id"`
+	DisplayName *string   `json:"display_name"`
+}
+
+func (q *Queries) UpdateUserDisplayName(ctx context.Context, arg UpdateUserDisplayNameParams) error {
+	_, err := q.db.Exec(ctx, updateUserDisplayName, arg.ID, arg.DisplayName)
+	return err
+}
+
+const updateUserEmail = `-- name: UpdateUserEmail :exec
+UPDATE users SET email = $2 WHERE id = $1
+`
+
+type UpdateUserEmailParams struct {
+	ID    uuid.UUID `json:"id"`
+	Email string    `json:"email"`
+}
+
+func (q *Queries) UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) error {
+	_, err := q.db.Exec(ctx, updateUserEmail, arg.ID, arg.Email)
+	return err
+}
+
+const updateUsername = `-- name: UpdateUsername :one
+UPDATE users SET username = $2, username_changed_at = NOW()
+WHERE id = $1
+RETURNING id, email, username, password_hash, role, created_at, is_active, avatar_filename, weekly_upload_bytes, last_upload_reset, display_name, weekly_upload_limit_override_bytes, username_changed_at
+`
+
+type UpdateUsernameParams struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+}
+
+func (q *Queries) UpdateUsername(ctx context.Context, arg UpdateUsernameParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUsername, arg.ID, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.PasswordHash,
+		&i.Role,
+		&i.CreatedAt,
+		&i.IsActive,
+		&i.AvatarFilename,
+		&i.WeeklyUploadBytes,
+		&i.LastUploadReset,
+		&i.DisplayName,
+		&i.WeeklyUploadLimitOverrideBytes,
+		&i.UsernameChangedAt,
 	)
 	return i, err
 }
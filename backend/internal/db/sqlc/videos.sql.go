@@ -14,6 +14,15 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const clearVideoNeedsEnqueue = `-- name: ClearVideoNeedsEnqueue :exec
+UPDATE videos SET needs_enqueue = FALSE WHERE id = $1
+`
+
+func (q *Queries) ClearVideoNeedsEnqueue(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, clearVideoNeedsEnqueue, id)
+	return err
+}
+
 const countUserVideos = `-- name: CountUserVideos :one
 SELECT COUNT(*) FROM videos WHERE uploaded_by = $1
 `
@@ -93,10 +102,11 @@ func (q *Queries) CountVideosWithAccess(ctx context.Context, arg CountVideosWith
 const createVideo = `-- name: CreateVideo :one
 INSERT INTO videos (
     short_id, title, description, filename, original_filename,
-    file_size_bytes, uploaded_by, category_id, storage_path
+    file_size_bytes, uploaded_by, category_id, storage_path, content_sha256,
+    needs_enqueue
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9
-) RETURNING id, short_id, title, description, filename, thumbnail_filename, original_filename, storage_path, file_size_bytes, duration_seconds, uploaded_by, category_id, view_count, processing_status, error_message, created_at
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, TRUE
+) RETURNING id, short_id, title, description, filename, thumbnail_filename, original_filename, storage_path, file_size_bytes, duration_seconds, uploaded_by, category_id, view_count, processing_status, error_message, created_at, content_sha256, needs_enqueue, comments_enabled, audio_track_count, video_codec, output_sha256
 `
 
 type CreateVideoParams struct {
@@ -109,6 +119,7 @@ type CreateVideoParams struct {
 	UploadedBy       uuid.UUID   `json:"uploaded_by"`
 	CategoryID       pgtype.UUID `json:"category_id"`
 	StoragePath      *string     `json:"storage_path"`
+	ContentSha256    *string     `json:"content_sha256"`
 }
 
 func (q *Queries) CreateVideo(ctx context.Context, arg CreateVideoParams) (Video, error) {
@@ -122,6 +133,7 @@ func (q *Queries) CreateVideo(ctx context.Context, arg CreateVideoParams) (Video
 		arg.UploadedBy,
 		arg.CategoryID,
 		arg.StoragePath,
+		arg.ContentSha256,
 	)
 	var i Video
 	err := row.Scan(
@@ -141,6 +153,12 @@ func (q *Queries) CreateVideo(ctx context.Context, arg CreateVideoParams) (Video
 		&i.ProcessingStatus,
 		&i.ErrorMessage,
 		&i.CreatedAt,
+		&i.ContentSha256,
+		&i.NeedsEnqueue,
+		&i.CommentsEnabled,
+		&i.AudioTrackCount,
+		&i.VideoCodec,
+		&i.OutputSha256,
 	)
 	return i, err
 }
@@ -154,8 +172,56 @@ func (q *Queries) DeleteVideo(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const getTotalStorageBytes = `-- name: GetTotalStorageBytes :one
+SELECT COALESCE(SUM(file_size_bytes), 0)::bigint as total_bytes FROM videos
+`
+
+func (q *Queries) GetTotalStorageBytes(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, getTotalStorageBytes)
+	var total_bytes int64
+	err := row.Scan(&total_bytes)
+	return total_bytes, err
+}
+
+const getUserStorageByStatus = `-- name: GetUserStorageByStatus :many
+SELECT
+    processing_status,
+    COUNT(*) as video_count,
+    COALESCE(SUM(file_size_bytes), 0)::bigint as total_bytes
+FROM videos
+WHERE uploaded_by = $1
+GROUP BY processing_status
+ORDER BY processing_status
+`
+
+type GetUserStorageByStatusRow struct {
+	ProcessingStatus domain.ProcessingStatus `json:"processing_status"`
+	VideoCount       int64                   `json:"video_count"`
+	TotalBytes       int64                   `json:"total_bytes"`
+}
+
+func (q *Queries) GetUserStorageByStatus(ctx context.Context, uploadedBy uuid.UUID) ([]GetUserStorageByStatusRow, error) {
+	rows, err := q.db.Query(ctx, getUserStorageByStatus, uploadedBy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetUserStorageByStatusRow{}
+	for rows.Next() {
+		var i GetUserStorageByStatusRow
+		if err := rows.Scan(&i.ProcessingStatus, &i.VideoCount, &i.TotalBytes); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getVideoByID = `-- name: GetVideoByID :one
-SELECT id, short_id, title, description, filename, thumbnail_filename, original_filename, storage_path, file_size_bytes, duration_seconds, uploaded_by, category_id, view_count, processing_status, error_message, created_at FROM videos WHERE id = $1
+SELECT id, short_id, title, description, filename, thumbnail_filename, original_filename, storage_path, file_size_bytes, duration_seconds, uploaded_by, category_id, view_count, processing_status, error_message, created_at, content_sha256, needs_enqueue, comments_enabled, audio_track_count, video_codec, output_sha256 FROM videos WHERE id = $1
 `
 
 func (q *Queries) GetVideoByID(ctx context.Context, id uuid.UUID) (Video, error) {
@@ -178,16 +244,24 @@ func (q *Queries) GetVideoByID(ctx context.Context, id uuid.UUID) (Video, error)
 		&i.ProcessingStatus,
 		&i.ErrorMessage,
 		&i.CreatedAt,
+		&i.ContentSha256,
+		&i.NeedsEnqueue,
+		&i.CommentsEnabled,
+		&i.AudioTrackCount,
+		&i.VideoCodec,
+		&i.OutputSha256,
 	)
 	return i, err
 }
 
 const getVideoByIDWithUploader = `-- name: GetVideoByIDWithUploader :one
 SELECT 
-    v.id, v.short_id, v.title, v.description, v.filename, v.thumbnail_filename, v.original_filename, v.storage_path, v.file_size_bytes, v.duration_seconds, v.uploaded_by, v.category_id, v.view_count, v.processing_status, v.error_message, v.created_at,
+    v.id, v.short_id, v.title, v.description, v.filename, v.thumbnail_filename, v.original_filename, v.storage_path, v.file_size_bytes, v.duration_seconds, v.uploaded_by, v.category_id, v.view_count, v.processing_status, v.error_message, v.created_at, v.content_sha256, v.needs_enqueue, v.comments_enabled, v.audio_track_count, v.video_codec, v.output_sha256,
     u.username as uploader_username,
+    COALESCE(u.display_name, u.username) as uploader_display_name,
     c.name as category_name,
-    c.slug as category_slug
+    c.slug as category_slug,
+    (SELECT COUNT(*) FROM comments cm WHERE cm.video_id = v.id) as comment_count
 FROM videos v
 JOIN users u ON v.uploaded_by = u.id
 LEFT JOIN categories c ON v.category_id = c.id
@@ -195,25 +269,33 @@ WHERE v.id = $1
 `
 
 type GetVideoByIDWithUploaderRow struct {
-	ID                uuid.UUID               `json:"id"`
-	ShortID           string                  `json:"short_id"`
-	Title             string                  `json:"title"`
-	Description       *string                 `json:"description"`
-	Filename          string                  `json:"filename"`
-	ThumbnailFilename *string                 `json:"thumbnail_filename"`
-	OriginalFilename  string                  `json:"original_filename"`
-	StoragePath       *string                 `json:"storage_path"`
-	FileSizeBytes     int64                   `json:"file_size_bytes"`
-	DurationSeconds   *int32                  `json:"duration_seconds"`
-	UploadedBy        uuid.UUID               `json:"uploaded_by"`
-	CategoryID        pgtype.UUID             `json:"category_id"`
-	ViewCount         int32                   `json:"view_count"`
-	ProcessingStatus  domain.ProcessingStatus `json:"processing_status"`
-	ErrorMessage      *string                 `json:"error_message"`
-	CreatedAt         time.Time               `json:"created_at"`
-	UploaderUsername  string                  `json:"uploader_username"`
-	CategoryName      *string                 `json:"category_name"`
-	CategorySlug      *string                 `json:"category_slug"`
+	ID                  uuid.UUID               `json:"id"`
+	ShortID             string                  `json:"short_id"`
+	Title               string                  `json:"title"`
+	Description         *string                 `json:"description"`
+	Filename            string                  `json:"filename"`
+	ThumbnailFilename   *string                 `json:"thumbnail_filename"`
+	OriginalFilename    string                  `json:"original_filename"`
+	StoragePath         *string                 `json:"storage_path"`
+	FileSizeBytes       int64                   `json:"file_size_bytes"`
+	DurationSeconds     *int32                  `json:"duration_seconds"`
+	UploadedBy          uuid.UUID               `json:"uploaded_by"`
+	CategoryID          pgtype.UUID             `json:"category_id"`
+	ViewCount           int32                   `json:"view_count"`
+	ProcessingStatus    domain.ProcessingStatus `json:"processing_status"`
+	ErrorMessage        *string                 `json:"error_message"`
+	CreatedAt           time.Time               `json:"created_at"`
+	ContentSha256       *string                 `json:"content_sha256"`
+	NeedsEnqueue        bool                    `json:"needs_enqueue"`
+	CommentsEnabled     bool                    `json:"comments_enabled"`
+	AudioTrackCount     *int32                  `json:"audio_track_count"`
+	VideoCodec          *string                 `json:"video_codec"`
+	OutputSha256        *string                 `json:"output_sha256"`
+	UploaderUsername    string                  `json:"uploader_username"`
+	UploaderDisplayName string                  `json:"uploader_display_name"`
+	CategoryName        *string                 `json:"category_name"`
+	CategorySlug        *string                 `json:"category_slug"`
+	CommentCount        int64                   `json:"comment_count"`
 }
 
 // Get video by UUID with uploader and category info
@@ -237,15 +319,23 @@ func (q *Queries) GetVideoByIDWithUploader(ctx context.Context, id uuid.UUID) (G
 		&i.ProcessingStatus,
 		&i.ErrorMessage,
 		&i.CreatedAt,
+		&i.ContentSha256,
+		&i.NeedsEnqueue,
+		&i.CommentsEnabled,
+		&i.AudioTrackCount,
+		&i.VideoCodec,
+		&i.OutputSha256,
 		&i.UploaderUsername,
+		&i.UploaderDisplayName,
 		&i.CategoryName,
 		&i.CategorySlug,
+		&i.CommentCount,
 	)
 	return i, err
 }
 
 const getVideoByShortID = `-- name: GetVideoByShortID :one
-SELECT id, short_id, title, description, filename, thumbnail_filename, original_filename, storage_path, file_size_bytes, duration_seconds, uploaded_by, category_id, view_count, processing_status, error_message, created_at FROM videos WHERE short_id = $1
+SELECT id, short_id, title, description, filename, thumbnail_filename, original_filename, storage_path, file_size_bytes, duration_seconds, uploaded_by, category_id, view_count, processing_status, error_message, created_at, content_sha256, needs_enqueue, comments_enabled, audio_track_count, video_codec, output_sha256 FROM videos WHERE short_id = $1
 `
 
 func (q *Queries) GetVideoByShortID(ctx context.Context, shortID string) (Video, error) {
@@ -268,16 +358,24 @@ func (q *Queries) GetVideoByShortID(ctx context.Context, shortID string) (Video,
 		&i.ProcessingStatus,
 		&i.ErrorMessage,
 		&i.CreatedAt,
+		&i.ContentSha256,
+		&i.NeedsEnqueue,
+		&i.CommentsEnabled,
+		&i.AudioTrackCount,
+		&i.VideoCodec,
+		&i.OutputSha256,
 	)
 	return i, err
 }
 
 const getVideoByShortIDWithUploader = `-- name: GetVideoByShortIDWithUploader :one
 SELECT 
-    v.id, v.short_id, v.title, v.description, v.filename, v.thumbnail_filename, v.original_filename, v.storage_path, v.file_size_bytes, v.duration_seconds, v.uploaded_by, v.category_id, v.view_count, v.processing_status, v.error_message, v.created_at,
+    v.id, v.short_id, v.title, v.description, v.filename, v.thumbnail_filename, v.original_filename, v.storage_path, v.file_size_bytes, v.duration_seconds, v.uploaded_by, v.category_id, v.view_count, v.processing_status, v.error_message, v.created_at, v.content_sha256, v.needs_enqueue, v.comments_enabled, v.audio_track_count, v.video_codec, v.output_sha256,
     u.username as uploader_username,
+    COALESCE(u.display_name, u.username) as uploader_display_name,
     c.name as category_name,
-    c.slug as category_slug
+    c.slug as category_slug,
+    (SELECT COUNT(*) FROM comments cm WHERE cm.video_id = v.id) as comment_count
 FROM videos v
 JOIN users u ON v.uploaded_by = u.id
 LEFT JOIN categories c ON v.category_id = c.id
@@ -285,25 +383,33 @@ WHERE v.short_id = $1
 `
 
 type GetVideoByShortIDWithUploaderRow struct {
-	ID                uuid.UUID               `json:"id"`
-	ShortID           string                  `json:"short_id"`
-	Title             string                  `json:"title"`
-	Description       *string                 `json:"description"`
-	Filename          string                  `json:"filename"`
-	ThumbnailFilename *string                 `json:"thumbnail_filename"`
-	OriginalFilename  string                  `json:"original_filename"`
-	StoragePath       *string                 `json:"storage_path"`
-	FileSizeBytes     int64                   `json:"file_size_bytes"`
-	DurationSeconds   *int32                  `json:"duration_seconds"`
-	UploadedBy        uuid.UUID               `json:"uploaded_by"`
-	CategoryID        pgtype.UUID             `json:"category_id"`
-	ViewCount         int32                   `json:"view_count"`
-	ProcessingStatus  domain.ProcessingStatus `json:"processing_status"`
-	ErrorMessage      *string                 `json:"error_message"`
-	CreatedAt         time.Time               `json:"created_at"`
-	UploaderUsername  string                  `json:"uploader_username"`
-	CategoryName      *string                 `json:"category_name"`
-	CategorySlug      *string                 `json:"category_slug"`
+	ID                  uuid.UUID               `json:"id"`
+	ShortID             string                  `json:"short_id"`
+	Title               string                  `json:"title"`
+	Description         *string                 `json:"description"`
+	Filename            string                  `json:"filename"`
+	ThumbnailFilename   *string                 `json:"thumbnail_filename"`
+	OriginalFilename    string                  `json:"original_filename"`
+	StoragePath         *string                 `json:"storage_path"`
+	FileSizeBytes       int64                   `json:"file_size_bytes"`
+	DurationSeconds     *int32                  `json:"duration_seconds"`
+	UploadedBy          uuid.UUID               `json:"uploaded_by"`
+	CategoryID          pgtype.UUID             `json:"category_id"`
+	ViewCount           int32                   `json:"view_count"`
+	ProcessingStatus    domain.ProcessingStatus `json:"processing_status"`
+	ErrorMessage        *string                 `json:"error_message"`
+	CreatedAt           time.Time               `json:"created_at"`
+	ContentSha256       *string                 `json:"content_sha256"`
+	NeedsEnqueue        bool                    `json:"needs_enqueue"`
+	CommentsEnabled     bool                    `json:"comments_enabled"`
+	AudioTrackCount     *int32                  `json:"audio_track_count"`
+	VideoCodec          *string                 `json:"video_codec"`
+	OutputSha256        *string                 `json:"output_sha256"`
+	UploaderUsername    string                  `json:"uploader_username"`
+	UploaderDisplayName string                  `json:"uploader_display_name"`
+	CategoryName        *string                 `json:"category_name"`
+	CategorySlug        *string                 `json:"category_slug"`
+	CommentCount        int64                   `json:"comment_count"`
 }
 
 // Get video with uploader and category info (no access control - handler checks access)
@@ -327,17 +433,90 @@ func (q *Queries) GetVideoByShortIDWithUploader(ctx context.Context, shortID str
 		&i.ProcessingStatus,
 		&i.ErrorMessage,
 		&i.CreatedAt,
+		&i.ContentSha256,
+		&i.NeedsEnqueue,
+		&i.CommentsEnabled,
+		&i.AudioTrackCount,
+		&i.VideoCodec,
+		&i.OutputSha256,
 		&i.UploaderUsername,
+		&i.UploaderDisplayName,
 		&i.CategoryName,
 		&i.CategorySlug,
+		&i.CommentCount,
+	)
+	return i, err
+}
+
+const getVideoByUploaderAndHash = `-- name: GetVideoByUploaderAndHash :one
+SELECT id, short_id, title, description, filename, thumbnail_filename, original_filename, storage_path, file_size_bytes, duration_seconds, uploaded_by, category_id, view_count, processing_status, error_message, created_at, content_sha256, needs_enqueue, comments_enabled, audio_track_count, video_codec, output_sha256 FROM videos
+WHERE uploaded_by = $1
+    AND content_sha256 = $2
+    AND processing_status != 'failed'
+ORDER BY created_at ASC
+LIMIT 1
+`
+
+type GetVideoByUploaderAndHashParams struct {
+	UploadedBy    uuid.UUID `json:"uploaded_by"`
+	ContentSha256 *string   `json:"content_sha256"`
+}
+
+func (q *Queries) GetVideoByUploaderAndHash(ctx context.Context, arg GetVideoByUploaderAndHashParams) (Video, error) {
+	row := q.db.QueryRow(ctx, getVideoByUploaderAndHash, arg.UploadedBy, arg.ContentSha256)
+	var i Video
+	err := row.Scan(
+		&i.ID,
+		&i.ShortID,
+		&i.Title,
+		&i.Description,
+		&i.Filename,
+		&i.ThumbnailFilename,
+		&i.OriginalFilename,
+		&i.StoragePath,
+		&i.FileSizeBytes,
+		&i.DurationSeconds,
+		&i.UploadedBy,
+		&i.CategoryID,
+		&i.ViewCount,
+		&i.ProcessingStatus,
+		&i.ErrorMessage,
+		&i.CreatedAt,
+		&i.ContentSha256,
+		&i.NeedsEnqueue,
+		&i.CommentsEnabled,
+		&i.AudioTrackCount,
+		&i.VideoCodec,
+		&i.OutputSha256,
 	)
 	return i, err
 }
 
+const getVideoFilesForUpdate = `-- name: GetVideoFilesForUpdate :one
+SELECT filename, storage_path, processing_status FROM videos
+WHERE id = $1
+FOR UPDATE
+`
+
+type GetVideoFilesForUpdateRow struct {
+	Filename         string                  `json:"filename"`
+	StoragePath      *string                 `json:"storage_path"`
+	ProcessingStatus domain.ProcessingStatus `json:"processing_status"`
+}
+
+// Locks the video row until the transaction ends, so its files can't change under a move
+func (q *Queries) GetVideoFilesForUpdate(ctx context.Context, id uuid.UUID) (GetVideoFilesForUpdateRow, error) {
+	row := q.db.QueryRow(ctx, getVideoFilesForUpdate, id)
+	var i GetVideoFilesForUpdateRow
+	err := row.Scan(&i.Filename, &i.StoragePath, &i.ProcessingStatus)
+	return i, err
+}
+
 const getVideoWithUploader = `-- name: GetVideoWithUploader :one
 SELECT 
-    v.id, v.short_id, v.title, v.description, v.filename, v.thumbnail_filename, v.original_filename, v.storage_path, v.file_size_bytes, v.duration_seconds, v.uploaded_by, v.category_id, v.view_count, v.processing_status, v.error_message, v.created_at,
+    v.id, v.short_id, v.title, v.description, v.filename, v.thumbnail_filename, v.original_filename, v.storage_path, v.file_size_bytes, v.duration_seconds, v.uploaded_by, v.category_id, v.view_count, v.processing_status, v.error_message, v.created_at, v.content_sha256, v.needs_enqueue, v.comments_enabled, v.audio_track_count, v.video_codec, v.output_sha256,
     u.username as uploader_username,
+    COALESCE(u.display_name, u.username) as uploader_display_name,
     c.name as category_name,
     c.slug as category_slug
 FROM videos v
@@ -347,25 +526,32 @@ WHERE v.short_id = $1
 `
 
 type GetVideoWithUploaderRow struct {
-	ID                uuid.UUID               `json:"id"`
-	ShortID           string                  `json:"short_id"`
-	Title             string                  `json:"title"`
-	Description       *string                 `json:"description"`
-	Filename          string                  `json:"filename"`
-	ThumbnailFilename *string                 `json:"thumbnail_filename"`
-	OriginalFilename  string                  `json:"original_filename"`
-	StoragePath       *string                 `json:"storage_path"`
-	FileSizeBytes     int64                   `json:"file_size_bytes"`
-	DurationSeconds   *int32                  `json:"duration_seconds"`
-	UploadedBy        uuid.UUID               `json:"uploaded_by"`
-	CategoryID        pgtype.UUID             `json:"category_id"`
-	ViewCount         int32                   `json:"view_count"`
-	ProcessingStatus  domain.ProcessingStatus `json:"processing_status"`
-	ErrorMessage      *string                 `json:"error_message"`
-	CreatedAt         time.Time               `json:"created_at"`
-	UploaderUsername  string                  `json:"uploader_username"`
-	CategoryName      *string                 `json:"category_name"`
-	CategorySlug      *string                 `json:"category_slug"`
+	ID                  uuid.UUID               `json:"id"`
+	ShortID             string                  `json:"short_id"`
+	Title               string                  `json:"title"`
+	Description         *string                 `json:"description"`
+	Filename            string                  `json:"filename"`
+	ThumbnailFilename   *string                 `json:"thumbnail_filename"`
+	OriginalFilename    string                  `json:"original_filename"`
+	StoragePath         *string                 `json:"storage_path"`
+	FileSizeBytes       int64                   `json:"file_size_bytes"`
+	DurationSeconds     *int32                  `json:"duration_seconds"`
+	UploadedBy          uuid.UUID               `json:"uploaded_by"`
+	CategoryID          pgtype.UUID             `json:"category_id"`
+	ViewCount           int32                   `json:"view_count"`
+	ProcessingStatus    domain.ProcessingStatus `json:"processing_status"`
+	ErrorMessage        *string                 `json:"error_message"`
+	CreatedAt           time.Time               `json:"created_at"`
+	ContentSha256       *string                 `json:"content_sha256"`
+	NeedsEnqueue        bool                    `json:"needs_enqueue"`
+	CommentsEnabled     bool                    `json:"comments_enabled"`
+	AudioTrackCount     *int32                  `json:"audio_track_count"`
+	VideoCodec          *string                 `json:"video_codec"`
+	OutputSha256        *string                 `json:"output_sha256"`
+	UploaderUsername    string                  `json:"uploader_username"`
+	UploaderDisplayName string                  `json:"uploader_display_name"`
+	CategoryName        *string                 `json:"category_name"`
+	CategorySlug        *string                 `json:"category_slug"`
 }
 
 func (q *Queries) GetVideoWithUploader(ctx context.Context, shortID string) (GetVideoWithUploaderRow, error) {
@@ -388,7 +574,14 @@ func (q *Queries) GetVideoWithUploader(ctx context.Context, shortID string) (Get
 		&i.ProcessingStatus,
 		&i.ErrorMessage,
 		&i.CreatedAt,
+		&i.ContentSha256,
+		&i.NeedsEnqueue,
+		&i.CommentsEnabled,
+		&i.AudioTrackCount,
+		&i.VideoCodec,
+		&i.OutputSha256,
 		&i.UploaderUsername,
+		&i.UploaderDisplayName,
 		&i.CategoryName,
 		&i.CategorySlug,
 	)
@@ -408,10 +601,142 @@ func (q *Queries) IncrementViewCount(ctx context.Context, id uuid.UUID) (int32,
 	return view_count, err
 }
 
+const listFailedVideos = `-- name: ListFailedVideos :many
+SELECT
+    v.id, v.short_id, v.title, v.filename, v.thumbnail_filename, v.storage_path, v.error_message, v.created_at, v.uploaded_by,
+    u.username as uploader_username,
+    COALESCE(f.attempts, 0)::int as attempts,
+    f.last_failed_at
+FROM videos v
+JOIN users u ON v.uploaded_by = u.id
+LEFT JOIN video_processing_failures f ON f.video_id = v.id
+WHERE v.processing_status = 'failed'
+ORDER BY f.last_failed_at DESC NULLS LAST, v.created_at DESC
+`
+
+type ListFailedVideosRow struct {
+	ID                uuid.UUID  `json:"id"`
+	ShortID           string     `json:"short_id"`
+	Title             string     `json:"title"`
+	Filename          string     `json:"filename"`
+	ThumbnailFilename *string    `json:"thumbnail_filename"`
+	StoragePath       *string    `json:"storage_path"`
+	ErrorMessage      *string    `json:"error_message"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UploadedBy        uuid.UUID  `json:"uploaded_by"`
+	UploaderUsername  string     `json:"uploader_username"`
+	Attempts          int32      `json:"attempts"`
+	LastFailedAt      *time.Time `json:"last_failed_at"`
+}
+
+func (q *Queries) ListFailedVideos(ctx context.Context) ([]ListFailedVideosRow, error) {
+	rows, err := q.db.Query(ctx, listFailedVideos)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListFailedVideosRow{}
+	for rows.Next() {
+		var i ListFailedVideosRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ShortID,
+			&i.Title,
+			&i.Filename,
+			&i.ThumbnailFilename,
+			&i.StoragePath,
+			&i.ErrorMessage,
+			&i.CreatedAt,
+			&i.UploadedBy,
+			&i.UploaderUsername,
+			&i.Attempts,
+			&i.LastFailedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listVideoFiles = `-- name: ListVideoFiles :many
+SELECT id, filename, thumbnail_filename, storage_path, processing_status FROM videos
+ORDER BY created_at ASC
+`
+
+type ListVideoFilesRow struct {
+	ID                uuid.UUID               `json:"id"`
+	Filename          string                  `json:"filename"`
+	ThumbnailFilename *string                 `json:"thumbnail_filename"`
+	StoragePath       *string                 `json:"storage_path"`
+	ProcessingStatus  domain.ProcessingStatus `json:"processing_status"`
+}
+
+func (q *Queries) ListVideoFiles(ctx context.Context) ([]ListVideoFilesRow, error) {
+	rows, err := q.db.Query(ctx, listVideoFiles)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListVideoFilesRow{}
+	for rows.Next() {
+		var i ListVideoFilesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Filename,
+			&i.ThumbnailFilename,
+			&i.StoragePath,
+			&i.ProcessingStatus,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listVideoSummariesByIDs = `-- name: ListVideoSummariesByIDs :many
+SELECT id, short_id, title FROM videos
+WHERE id = ANY($1::uuid[])
+`
+
+type ListVideoSummariesByIDsRow struct {
+	ID      uuid.UUID `json:"id"`
+	ShortID string    `json:"short_id"`
+	Title   string    `json:"title"`
+}
+
+func (q *Queries) ListVideoSummariesByIDs(ctx context.Context, ids []uuid.UUID) ([]ListVideoSummariesByIDsRow, error) {
+	rows, err := q.db.Query(ctx, listVideoSummariesByIDs, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListVideoSummariesByIDsRow{}
+	for rows.Next() {
+		var i ListVideoSummariesByIDsRow
+		if err := rows.Scan(&i.ID, &i.ShortID, &i.Title); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listVideos = `-- name: ListVideos :many
 SELECT 
-    v.id, v.short_id, v.title, v.description, v.filename, v.thumbnail_filename, v.original_filename, v.storage_path, v.file_size_bytes, v.duration_seconds, v.uploaded_by, v.category_id, v.view_count, v.processing_status, v.error_message, v.created_at,
+    v.id, v.short_id, v.title, v.description, v.filename, v.thumbnail_filename, v.original_filename, v.storage_path, v.file_size_bytes, v.duration_seconds, v.uploaded_by, v.category_id, v.view_count, v.processing_status, v.error_message, v.created_at, v.content_sha256, v.needs_enqueue, v.comments_enabled, v.audio_track_count, v.video_codec, v.output_sha256,
     u.username as uploader_username,
+    COALESCE(u.display_name, u.username) as uploader_display_name,
     c.name as category_name,
     c.slug as category_slug
 FROM videos v
@@ -449,25 +774,32 @@ type ListVideosParams struct {
 }
 
 type ListVideosRow struct {
-	ID                uuid.UUID               `json:"id"`
-	ShortID           string                  `json:"short_id"`
-	Title             string                  `json:"title"`
-	Description       *string                 `json:"description"`
-	Filename          string                  `json:"filename"`
-	ThumbnailFilename *string                 `json:"thumbnail_filename"`
-	OriginalFilename  string                  `json:"original_filename"`
-	StoragePath       *string                 `json:"storage_path"`
-	FileSizeBytes     int64                   `json:"file_size_bytes"`
-	DurationSeconds   *int32                  `json:"duration_seconds"`
-	UploadedBy        uuid.UUID               `json:"uploaded_by"`
-	CategoryID        pgtype.UUID             `json:"category_id"`
-	ViewCount         int32                   `json:"view_count"`
-	ProcessingStatus  domain.ProcessingStatus `json:"processing_status"`
-	ErrorMessage      *string                 `json:"error_message"`
-	CreatedAt         time.Time               `json:"created_at"`
-	UploaderUsername  string                  `json:"uploader_username"`
-	CategoryName      *string                 `json:"category_name"`
-	CategorySlug      *string                 `json:"category_slug"`
+	ID                  uuid.UUID               `json:"id"`
+	ShortID             string                  `json:"short_id"`
+	Title               string                  `json:"title"`
+	Description         *string                 `json:"description"`
+	Filename            string                  `json:"filename"`
+	ThumbnailFilename   *string                 `json:"thumbnail_filename"`
+	OriginalFilename    string                  `json:"original_filename"`
+	StoragePath         *string                 `json:"storage_path"`
+	FileSizeBytes       int64                   `json:"file_size_bytes"`
+	DurationSeconds     *int32                  `json:"duration_seconds"`
+	UploadedBy          uuid.UUID               `json:"uploaded_by"`
+	CategoryID          pgtype.UUID             `json:"category_id"`
+	ViewCount           int32                   `json:"view_count"`
+	ProcessingStatus    domain.ProcessingStatus `json:"processing_status"`
+	ErrorMessage        *string                 `json:"error_message"`
+	CreatedAt           time.Time               `json:"created_at"`
+	ContentSha256       *string                 `json:"content_sha256"`
+	NeedsEnqueue        bool                    `json:"needs_enqueue"`
+	CommentsEnabled     bool                    `json:"comments_enabled"`
+	AudioTrackCount     *int32                  `json:"audio_track_count"`
+	VideoCodec          *string                 `json:"video_codec"`
+	OutputSha256        *string                 `json:"output_sha256"`
+	UploaderUsername    string                  `json:"uploader_username"`
+	UploaderDisplayName string                  `json:"uploader_display_name"`
+	CategoryName        *string                 `json:"category_name"`
+	CategorySlug        *string                 `json:"category_slug"`
 }
 
 func (q *Queries) ListVideos(ctx context.Context, arg ListVideosParams) ([]ListVideosRow, error) {
@@ -505,7 +837,14 @@ func (q *Queries) ListVideos(ctx context.Context, arg ListVideosParams) ([]ListV
 			&i.ProcessingStatus,
 			&i.ErrorMessage,
 			&i.CreatedAt,
+			&i.ContentSha256,
+			&i.NeedsEnqueue,
+			&i.CommentsEnabled,
+			&i.AudioTrackCount,
+			&i.VideoCodec,
+			&i.OutputSha256,
 			&i.UploaderUsername,
+			&i.UploaderDisplayName,
 			&i.CategoryName,
 			&i.CategorySlug,
 		); err != nil {
@@ -519,12 +858,95 @@ func (q *Queries) ListVideos(ctx context.Context, arg ListVideosParams) ([]ListV
 	return items, nil
 }
 
+const listVideosByUploader = `-- name: ListVideosByUploader :many
+SELECT id, short_id, title, description, filename, thumbnail_filename, original_filename, storage_path, file_size_bytes, duration_seconds, uploaded_by, category_id, view_count, processing_status, error_message, created_at, content_sha256, needs_enqueue, comments_enabled, audio_track_count, video_codec, output_sha256 FROM videos
+WHERE uploaded_by = $1
+ORDER BY created_at ASC
+`
+
+func (q *Queries) ListVideosByUploader(ctx context.Context, uploadedBy uuid.UUID) ([]Video, error) {
+	rows, err := q.db.Query(ctx, listVideosByUploader, uploadedBy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Video{}
+	for rows.Next() {
+		var i Video
+		if err := rows.Scan(
+			&i.ID,
+			&i.ShortID,
+			&i.Title,
+			&i.Description,
+			&i.Filename,
+			&i.ThumbnailFilename,
+			&i.OriginalFilename,
+			&i.StoragePath,
+			&i.FileSizeBytes,
+			&i.DurationSeconds,
+			&i.UploadedBy,
+			&i.CategoryID,
+			&i.ViewCount,
+			&i.ProcessingStatus,
+			&i.ErrorMessage,
+			&i.CreatedAt,
+			&i.ContentSha256,
+			&i.NeedsEnqueue,
+			&i.CommentsEnabled,
+			&i.AudioTrackCount,
+			&i.VideoCodec,
+			&i.OutputSha256,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listVideosNeedingEnqueue = `-- name: ListVideosNeedingEnqueue :many
+SELECT id FROM videos
+WHERE needs_enqueue = TRUE AND created_at < $1
+ORDER BY created_at ASC
+LIMIT $2
+`
+
+type ListVideosNeedingEnqueueParams struct {
+	CreatedAt time.Time `json:"created_at"`
+	Limit     int32     `json:"limit"`
+}
+
+func (q *Queries) ListVideosNeedingEnqueue(ctx context.Context, arg ListVideosNeedingEnqueueParams) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, listVideosNeedingEnqueue, arg.CreatedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listVideosWithAccess = `-- name: ListVideosWithAccess :many
 SELECT 
-    v.id, v.short_id, v.title, v.description, v.filename, v.thumbnail_filename, v.original_filename, v.storage_path, v.file_size_bytes, v.duration_seconds, v.uploaded_by, v.category_id, v.view_count, v.processing_status, v.error_message, v.created_at,
+    v.id, v.short_id, v.title, v.description, v.filename, v.thumbnail_filename, v.original_filename, v.storage_path, v.file_size_bytes, v.duration_seconds, v.uploaded_by, v.category_id, v.view_count, v.processing_status, v.error_message, v.created_at, v.content_sha256, v.needs_enqueue, v.comments_enabled, v.audio_track_count, v.video_codec, v.output_sha256,
     u.username as uploader_username,
+    COALESCE(u.display_name, u.username) as uploader_display_name,
     c.name as category_name,
-    c.slug as category_slug
+    c.slug as category_slug,
+    (SELECT COUNT(*) FROM comments cm WHERE cm.video_id = v.id) as comment_count
 FROM videos v
 JOIN users u ON v.uploaded_by = u.id
 LEFT JOIN categories c ON v.category_id = c.id
@@ -561,25 +983,33 @@ type ListVideosWithAccessParams struct {
 }
 
 type ListVideosWithAccessRow struct {
-	ID                uuid.UUID               `json:"id"`
-	ShortID           string                  `json:"short_id"`
-	Title             string                  `json:"title"`
-	Description       *string                 `json:"description"`
-	Filename          string                  `json:"filename"`
-	ThumbnailFilename *string                 `json:"thumbnail_filename"`
-	OriginalFilename  string                  `json:"original_filename"`
-	StoragePath       *string                 `json:"storage_path"`
-	FileSizeBytes     int64                   `json:"file_size_bytes"`
-	DurationSeconds   *int32                  `json:"duration_seconds"`
-	UploadedBy        uuid.UUID               `json:"uploaded_by"`
-	CategoryID        pgtype.UUID             `json:"category_id"`
-	ViewCount         int32                   `json:"view_count"`
-	ProcessingStatus  domain.ProcessingStatus `json:"processing_status"`
-	ErrorMessage      *string                 `json:"error_message"`
-	CreatedAt         time.Time               `json:"created_at"`
-	UploaderUsername  string                  `json:"uploader_username"`
-	CategoryName      *string                 `json:"category_name"`
-	CategorySlug      *string                 `json:"category_slug"`
+	ID                  uuid.UUID               `json:"id"`
+	ShortID             string                  `json:"short_id"`
+	Title               string                  `json:"title"`
+	Description         *string                 `json:"description"`
+	Filename            string                  `json:"filename"`
+	ThumbnailFilename   *string                 `json:"thumbnail_filename"`
+	OriginalFilename    string                  `json:"original_filename"`
+	StoragePath         *string                 `json:"storage_path"`
+	FileSizeBytes       int64                   `json:"file_size_bytes"`
+	DurationSeconds     *int32                  `json:"duration_seconds"`
+	UploadedBy          uuid.UUID               `json:"uploaded_by"`
+	CategoryID          pgtype.UUID             `json:"category_id"`
+	ViewCount           int32                   `json:"view_count"`
+	ProcessingStatus    domain.ProcessingStatus `json:"processing_status"`
+	ErrorMessage        *string                 `json:"error_message"`
+	CreatedAt           time.Time               `json:"created_at"`
+	ContentSha256       *string                 `json:"content_sha256"`
+	NeedsEnqueue        bool                    `json:"needs_enqueue"`
+	CommentsEnabled     bool                    `json:"comments_enabled"`
+	AudioTrackCount     *int32                  `json:"audio_track_count"`
+	VideoCodec          *string                 `json:"video_codec"`
+	OutputSha256        *string                 `json:"output_sha256"`
+	UploaderUsername    string                  `json:"uploader_username"`
+	UploaderDisplayName string                  `json:"uploader_display_name"`
+	CategoryName        *string                 `json:"category_name"`
+	CategorySlug        *string                 `json:"category_slug"`
+	CommentCount        int64                   `json:"comment_count"`
 }
 
 // Non-admin: only COMPLETED videos OR own videos
@@ -623,9 +1053,17 @@ func (q *Queries) ListVideosWithAccess(ctx context.Context, arg ListVideosWithAc
 			&i.ProcessingStatus,
 			&i.ErrorMessage,
 			&i.CreatedAt,
+			&i.ContentSha256,
+			&i.NeedsEnqueue,
+			&i.CommentsEnabled,
+			&i.AudioTrackCount,
+			&i.VideoCodec,
+			&i.OutputSha256,
 			&i.UploaderUsername,
+			&i.UploaderDisplayName,
 			&i.CategoryName,
 			&i.CategorySlug,
+			&i.CommentCount,
 		); err != nil {
 			return nil, err
 		}
@@ -638,9 +1076,9 @@ func (q *Queries) ListVideosWithAccess(ctx context.Context, arg ListVideosWithAc
 }
 
 const listVideosWithoutHLS = `-- name: ListVideosWithoutHLS :many
-SELECT id, short_id, title, description, filename, thumbnail_filename, original_filename, storage_path, file_size_bytes, duration_seconds, uploaded_by, category_id, view_count, processing_status, error_message, created_at FROM videos
+SELECT id, short_id, title, description, filename, thumbnail_filename, original_filename, storage_path, file_size_bytes, duration_seconds, uploaded_by, category_id, view_count, processing_status, error_message, created_at, content_sha256, needs_enqueue, comments_enabled, audio_track_count, video_codec, output_sha256 FROM videos
 WHERE processing_status = 'completed'
-AND filename NOT LIKE '%/master.m3u8'
+AND filename ILIKE '%.mp4'
 ORDER BY created_at ASC
 `
 
@@ -670,6 +1108,12 @@ func (q *Queries) ListVideosWithoutHLS(ctx context.Context) ([]Video, error) {
 			&i.ProcessingStatus,
 			&i.ErrorMessage,
 			&i.CreatedAt,
+			&i.ContentSha256,
+			&i.NeedsEnqueue,
+			&i.CommentsEnabled,
+			&i.AudioTrackCount,
+			&i.VideoCodec,
+			&i.OutputSha256,
 		); err != nil {
 			return nil, err
 		}
@@ -681,20 +1125,76 @@ func (q *Queries) ListVideosWithoutHLS(ctx context.Context) ([]Video, error) {
 	return items, nil
 }
 
+const listVideosWithoutOutputSha256 = `-- name: ListVideosWithoutOutputSha256 :many
+SELECT id, filename, storage_path FROM videos
+WHERE processing_status = 'completed' AND output_sha256 IS NULL
+AND id <> ALL($1::uuid[])
+ORDER BY created_at ASC
+LIMIT $2::int
+`
+
+type ListVideosWithoutOutputSha256Params struct {
+	SkipIds   []uuid.UUID `json:"skip_ids"`
+	BatchSize int32       `json:"batch_size"`
+}
+
+type ListVideosWithoutOutputSha256Row struct {
+	ID          uuid.UUID `json:"id"`
+	Filename    string    `json:"filename"`
+	StoragePath *string   `json:"storage_path"`
+}
+
+func (q *Queries) ListVideosWithoutOutputSha256(ctx context.Context, arg ListVideosWithoutOutputSha256Params) ([]ListVideosWithoutOutputSha256Row, error) {
+	rows, err := q.db.Query(ctx, listVideosWithoutOutputSha256, arg.SkipIds, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListVideosWithoutOutputSha256Row{}
+	for rows.Next() {
+		var i ListVideosWithoutOutputSha256Row
+		if err := rows.Scan(&i.ID, &i.Filename, &i.StoragePath); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const requeueFailedVideo = `-- name: RequeueFailedVideo :execrows
+UPDATE videos SET processing_status = 'pending', error_message = NULL, needs_enqueue = TRUE
+WHERE id = $1 AND processing_status = 'failed'
+`
+
+// Marks a failed video for processing again; the enqueue outbox picks it up
+// if enqueueing it directly fails
+func (q *Queries) RequeueFailedVideo(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, requeueFailedVideo, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateVideo = `-- name: UpdateVideo :one
 UPDATE videos SET
     title = COALESCE(NULLIF($2, ''), title),
     description = $3,
-    category_id = $4
+    category_id = $4,
+    comments_enabled = $5
 WHERE id = $1
-RETURNING id, short_id, title, description, filename, thumbnail_filename, original_filename, storage_path, file_size_bytes, duration_seconds, uploaded_by, category_id, view_count, processing_status, error_message, created_at
+RETURNING id, short_id, title, description, filename, thumbnail_filename, original_filename, storage_path, file_size_bytes, duration_seconds, uploaded_by, category_id, view_count, processing_status, error_message, created_at, content_sha256, needs_enqueue, comments_enabled, audio_track_count, video_codec, output_sha256
 `
 
 type UpdateVideoParams struct {
-	ID          uuid.UUID   `json:"id"`
-	Column2     interface{} `json:"column_2"`
-	Description *string     `json:"description"`
-	CategoryID  pgtype.UUID `json:"category_id"`
+	ID              uuid.UUID   `json:"id"`
+	Column2         interface{} `json:"column_2"`
+	Description     *string     `json:"description"`
+	CategoryID      pgtype.UUID `json:"category_id"`
+	CommentsEnabled bool        `json:"comments_enabled"`
 }
 
 func (q *Queries) UpdateVideo(ctx context.Context, arg UpdateVideoParams) (Video, error) {
@@ -703,6 +1203,7 @@ func (q *Queries) UpdateVideo(ctx context.Context, arg UpdateVideoParams) (Video
 		arg.Column2,
 		arg.Description,
 		arg.CategoryID,
+		arg.CommentsEnabled,
 	)
 	var i Video
 	err := row.Scan(
@@ -722,10 +1223,58 @@ func (q *Queries) UpdateVideo(ctx context.Context, arg UpdateVideoParams) (Video
 		&i.ProcessingStatus,
 		&i.ErrorMessage,
 		&i.CreatedAt,
+		&i.ContentSha256,
+		&i.NeedsEnqueue,
+		&i.CommentsEnabled,
+		&i.AudioTrackCount,
+		&i.VideoCodec,
+		&i.OutputSha256,
 	)
 	return i, err
 }
 
+const updateVideoAudioTrackCount = `-- name: UpdateVideoAudioTrackCount :exec
+UPDATE videos SET audio_track_count = $2 WHERE id = $1
+`
+
+type UpdateVideoAudioTrackCountParams struct {
+	ID              uuid.UUID `json:"id"`
+	AudioTrackCount *int32    `json:"audio_track_count"`
+}
+
+func (q *Queries) UpdateVideoAudioTrackCount(ctx context.Context, arg UpdateVideoAudioTrackCountParams) error {
+	_, err := q.db.Exec(ctx, updateVideoAudioTrackCount, arg.ID, arg.AudioTrackCount)
+	return err
+}
+
+const updateVideoCodec = `-- name: UpdateVideoCodec :exec
+UPDATE videos SET video_codec = $2 WHERE id = $1
+`
+
+type UpdateVideoCodecParams struct {
+	ID         uuid.UUID `json:"id"`
+	VideoCodec *string   `json:"video_codec"`
+}
+
+func (q *Queries) UpdateVideoCodec(ctx context.Context, arg UpdateVideoCodecParams) error {
+	_, err := q.db.Exec(ctx, updateVideoCodec, arg.ID, arg.VideoCodec)
+	return err
+}
+
+const updateVideoOutputSha256 = `-- name: UpdateVideoOutputSha256 :exec
+UPDATE videos SET output_sha256 = $2 WHERE id = $1
+`
+
+type UpdateVideoOutputSha256Params struct {
+	ID           uuid.UUID `json:"id"`
+	OutputSha256 *string   `json:"output_sha256"`
+}
+
+func (q *Queries) UpdateVideoOutputSha256(ctx context.Context, arg UpdateVideoOutputSha256Params) error {
+	_, err := q.db.Exec(ctx, updateVideoOutputSha256, arg.ID, arg.OutputSha256)
+	return err
+}
+
 const updateVideoProcessing = `-- name: UpdateVideoProcessing :one
 UPDATE videos SET
     processing_status = $2,
@@ -735,7 +1284,7 @@ UPDATE videos SET
     filename = COALESCE(NULLIF($6, ''), filename),
     thumbnail_filename = COALESCE(NULLIF($7, ''), thumbnail_filename)
 WHERE id = $1
-RETURNING id, short_id, title, description, filename, thumbnail_filename, original_filename, storage_path, file_size_bytes, duration_seconds, uploaded_by, category_id, view_count, processing_status, error_message, created_at
+RETURNING id, short_id, title, description, filename, thumbnail_filename, original_filename, storage_path, file_size_bytes, duration_seconds, uploaded_by, category_id, view_count, processing_status, error_message, created_at, content_sha256, needs_enqueue, comments_enabled, audio_track_count, video_codec, output_sha256
 `
 
 type UpdateVideoProcessingParams struct {
@@ -776,10 +1325,30 @@ func (q *Queries) UpdateVideoProcessing(ctx context.Context, arg UpdateVideoProc
 		&i.ProcessingStatus,
 		&i.ErrorMessage,
 		&i.CreatedAt,
+		&i.ContentSha256,
+		&i.NeedsEnqueue,
+		&i.CommentsEnabled,
+		&i.AudioTrackCount,
+		&i.VideoCodec,
+		&i.OutputSha256,
 	)
 	return i, err
 }
 
+const updateVideoStoragePath = `-- name: UpdateVideoStoragePath :exec
+UPDATE videos SET storage_path = $2 WHERE id = $1
+`
+
+type UpdateVideoStoragePathParams struct {
+	ID          uuid.UUID `json:"id"`
+	StoragePath *string   `json:"storage_path"`
+}
+
+func (q *Queries) UpdateVideoStoragePath(ctx context.Context, arg UpdateVideoStoragePathParams) error {
+	_, err := q.db.Exec(ctx, updateVideoStoragePath, arg.ID, arg.StoragePath)
+	return err
+}
+
 const videoExistsByShortID = `-- name: VideoExistsByShortID :one
 SELECT EXISTS(SELECT 1 FROM videos WHERE short_id = $1)
 `
@@ -9,8 +9,9 @@ import (
 type UserRole string
 
 const (
-	UserRoleUser  UserRole = "user"
-	UserRoleAdmin UserRole = "admin"
+	UserRoleUser      UserRole = "user"
+	UserRoleModerator UserRole = "moderator"
+	UserRoleAdmin     UserRole = "admin"
 )
 
 // Scan implements the sql.Scanner interface
@@ -34,12 +35,17 @@ func (r UserRole) Value() (driver.Value, error) {
 // IsValid checks if the role is valid
 func (r UserRole) IsValid() bool {
 	switch r {
-	case UserRoleUser, UserRoleAdmin:
+	case UserRoleUser, UserRoleModerator, UserRoleAdmin:
 		return true
 	}
 	return false
 }
 
+// IsModerator checks if the role has moderation rights (admins included)
+func (r UserRole) IsModerator() bool {
+	return r == UserRoleModerator || r == UserRoleAdmin
+}
+
 // ProcessingStatus represents the status of video processing
 type ProcessingStatus string
 
@@ -76,3 +82,38 @@ func (s ProcessingStatus) IsValid() bool {
 	}
 	return false
 }
+
+// PlaylistType represents how a playlist's contents are determined
+type PlaylistType string
+
+const (
+	PlaylistTypeManual PlaylistType = "manual"
+	PlaylistTypeSmart  PlaylistType = "smart"
+)
+
+// Scan implements the sql.Scanner interface
+func (t *PlaylistType) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		*t = PlaylistType(v)
+	case []byte:
+		*t = PlaylistType(string(v))
+	default:
+		return fmt.Errorf("cannot scan %T into PlaylistType", src)
+	}
+	return nil
+}
+
+// Value implements the driver.Valuer interface
+func (t PlaylistType) Value() (driver.Value, error) {
+	return string(t), nil
+}
+
+// IsValid checks if the playlist type is valid
+func (t PlaylistType) IsValid() bool {
+	switch t {
+	case PlaylistTypeManual, PlaylistTypeSmart:
+		return true
+	}
+	return false
+}
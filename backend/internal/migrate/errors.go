@@ -0,0 +1,58 @@
+package migrate
+
+import "errors"
+
+// Exit codes of clipset migrate, so scripts can tell why a migration failed.
+// ExitValidation matches the flag package's exit code for bad flags.
+const (
+	ExitValidation = 2 // Bad options, or PostgreSQL isn't ready for the selected tables
+	ExitConnection = 3 // SQLite or PostgreSQL couldn't be reached
+	ExitData       = 4 // Reading, copying or verifying rows or media failed
+)
+
+// Error is a migration failure, with the exit code it maps to
+type Error struct {
+	Code  int
+	Table string // The table being migrated, if any
+	Err   error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// kind names the error's exit code for JSON output
+func (e *Error) kind() string {
+	switch e.Code {
+	case ExitValidation:
+		return "validation"
+	case ExitConnection:
+		return "connection"
+	default:
+		return "data"
+	}
+}
+
+// validationError marks err as a validation failure
+func validationError(err error) error {
+	return &Error{Code: ExitValidation, Err: err}
+}
+
+// connectionError marks err as a connection failure
+func connectionError(err error) error {
+	return &Error{Code: ExitConnection, Err: err}
+}
+
+// ExitCode returns the exit code for an error returned by Run. Errors not
+// otherwise classified are data errors.
+func ExitCode(err error) int {
+	var merr *Error
+	if errors.As(err, &merr) {
+		return merr.Code
+	}
+	return ExitData
+}
@@ -0,0 +1,170 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/clipset/clipset-go/internal/services/storage"
+	"github.com/clipset/clipset-go/internal/storagemigrate"
+)
+
+// Subdirectories of a media root, as laid out by both backends' default
+// storage paths (e.g. /data/uploads/videos)
+const (
+	videosDir         = "videos"
+	thumbnailsDir     = "thumbnails"
+	avatarsDir        = "avatars"
+	categoryImagesDir = "category-images"
+)
+
+// MissingMedia is a file a migrated row references that couldn't be found
+// in the source media root
+type MissingMedia struct {
+	Table  string
+	ID     string
+	Path   string
+	Reason string
+}
+
+// MediaCopier copies the files of migrated rows from the Python install's
+// media root to the Go deployment's, keeping their layout under each
+// subdirectory. Missing files are collected for a report rather than
+// stopping the migration.
+type MediaCopier struct {
+	source  string
+	target  string
+	Copied  int
+	Skipped int // Already in the target with the same size, from an earlier run
+	Bytes   int64
+	Missing []MissingMedia
+}
+
+// NewMediaCopier creates a media copier from source to target media roots
+func NewMediaCopier(source, target string) *MediaCopier {
+	return &MediaCopier{source: source, target: target}
+}
+
+// copyVideo copies a video's progressive MP4, HLS directory and thumbnail.
+// The files are looked for in the video's storage path, then in the source
+// root's videos directory, since Python storage paths often don't exist in
+// the Go deployment. Once copied, the video's storage path is rewritten to
+// the target's videos directory.
+func (m *MediaCopier) copyVideo(v *SQLiteVideo) error {
+	src := filepath.Join(m.source, videosDir)
+	if v.StoragePath != nil && *v.StoragePath != "" && storage.FileExists(*v.StoragePath) {
+		src = *v.StoragePath
+	}
+	dst := filepath.Join(m.target, videosDir)
+
+	files, err := storagemigrate.ListVideoFiles(src, v.Filename)
+	if err != nil {
+		m.Missing = append(m.Missing, MissingMedia{Table: "videos", ID: v.ID, Path: filepath.Join(src, v.Filename), Reason: err.Error()})
+	} else {
+		for _, f := range files {
+			if err := m.copyFile(filepath.Join(src, f.Path), filepath.Join(dst, f.Path), f.Size); err != nil {
+				return fmt.Errorf("video %s: %w", v.ID, err)
+			}
+		}
+		v.StoragePath = &dst
+	}
+
+	if v.ThumbnailFilename != nil && *v.ThumbnailFilename != "" {
+		if err := m.copyNamed("videos", v.ID, thumbnailsDir, *v.ThumbnailFilename); err != nil {
+			return fmt.Errorf("video %s thumbnail: %w", v.ID, err)
+		}
+	}
+	return nil
+}
+
+// copyAvatar copies a user's avatar, if they have one
+func (m *MediaCopier) copyAvatar(u SQLiteUser) error {
+	if u.AvatarFilename == nil || *u.AvatarFilename == "" {
+		return nil
+	}
+	if err := m.copyNamed("users", u.ID, avatarsDir, *u.AvatarFilename); err != nil {
+		return fmt.Errorf("user %s avatar: %w", u.ID, err)
+	}
+	return nil
+}
+
+// copyCategoryImage copies a category's image, if it has one
+func (m *MediaCopier) copyCategoryImage(c SQLiteCategory) error {
+	if c.ImageFilename == nil || *c.ImageFilename == "" {
+		return nil
+	}
+	if err := m.copyNamed("categories", c.ID, categoryImagesDir, *c.ImageFilename); err != nil {
+		return fmt.Errorf("category %s image: %w", c.ID, err)
+	}
+	return nil
+}
+
+// copyNamed copies the file name from dir under the source root to the
+// same place under the target root, sharded or flat as it's stored
+func (m *MediaCopier) copyNamed(table, id, dir, name string) error {
+	base := filepath.Join(m.source, dir)
+	src := storage.ResolvePath(base, name)
+	info, err := os.Stat(src)
+	if err != nil || !info.Mode().IsRegular() {
+		reason := "not a regular file"
+		if err != nil {
+			reason = err.Error()
+		}
+		m.Missing = append(m.Missing, MissingMedia{Table: table, ID: id, Path: filepath.Join(base, name), Reason: reason})
+		return nil
+	}
+
+	rel, err := filepath.Rel(base, src)
+	if err != nil {
+		return err
+	}
+	return m.copyFile(src, filepath.Join(m.target, dir, rel), info.Size())
+}
+
+// copyFile copies src to dst, verifying the copy, unless dst is already
+// there with the same size
+func (m *MediaCopier) copyFile(src, dst string, size int64) error {
+	if existing, err := storage.GetFileSize(dst); err == nil && existing == size {
+		m.Skipped++
+		return nil
+	}
+	if err := storagemigrate.CopyVerified(src, dst, size); err != nil {
+		return err
+	}
+	m.Copied++
+	m.Bytes += size
+	return nil
+}
+
+// WriteReport writes the missing files as tab-separated lines of table, row
+// ID, path and reason
+func (m *MediaCopier) WriteReport(path string) error {
+	var b strings.Builder
+	b.WriteString("table\tid\tpath\treason\n")
+	for _, missing := range m.Missing {
+		fmt.Fprintf(&b, "%s\t%s\t%s\t%s\n", missing.Table, missing.ID, missing.Path, strings.ReplaceAll(missing.Reason, "\t", " "))
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create missing media report directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write missing media report: %w", err)
+	}
+	return nil
+}
+
+// checkMediaRoots verifies the media roots of a migration that copies media
+func checkMediaRoots(source, target string) error {
+	if source == "" || target == "" {
+		return errors.New("copying media needs both a source and a target media root")
+	}
+	if info, err := os.Stat(source); err != nil || !info.IsDir() {
+		return fmt.Errorf("source media root %s is not a directory", source)
+	}
+	if filepath.Clean(source) == filepath.Clean(target) {
+		return errors.New("source and target media roots are the same")
+	}
+	return nil
+}
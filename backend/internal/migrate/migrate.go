@@ -2,7 +2,11 @@ package migrate
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -14,6 +18,21 @@ type Options struct {
 	PostgresURL string
 	DryRun      bool
 	BatchSize   int
+	Resume      bool     // Continue an interrupted migration into non-empty tables
+	Tables      []string // Only migrate these tables; all if empty
+	SkipTables  []string // Don't migrate these tables
+
+	// Copy the files of migrated rows from the Python install's media root
+	// to the Go deployment's, each holding videos, thumbnails, avatars and
+	// category-images directories
+	CopyMedia       bool
+	SourceMediaRoot string
+	TargetMediaRoot string
+	MediaReport     string // Where to list missing files; defaults to the target root
+
+	// Write progress and the summary to stdout as newline-delimited JSON,
+	// and the human-readable output to stderr
+	JSON bool
 }
 
 // DefaultBatchSize is the default number of rows per batch
@@ -37,10 +56,52 @@ var appTables = []string{
 	"playlists",
 	"playlist_videos",
 	"comments",
+	"password_reset_tokens",
 }
 
-// Run executes the migration
-func Run(ctx context.Context, opts Options) error {
+// tableDependencies lists the tables each application table references.
+// A table can only be migrated once the tables it references are.
+var tableDependencies = map[string][]string{
+	"config":                {"users"},
+	"invitations":           {"users"},
+	"categories":            {"users"},
+	"videos":                {"users", "categories"},
+	"playlists":             {"users"},
+	"playlist_videos":       {"users", "playlists", "videos"},
+	"comments":              {"users", "videos"},
+	"password_reset_tokens": {"users"},
+}
+
+// IsExternalTable reports whether a table is managed by River or
+// golang-migrate rather than holding application data
+func IsExternalTable(table string) bool {
+	return skipTables[table]
+}
+
+// AppTables returns the application tables the migration copies, parents
+// before the tables referencing them
+func AppTables() []string {
+	return slices.Clone(appTables)
+}
+
+// Run executes the migration. Errors are *Error values, whose codes tell
+// validation, connection and data failures apart.
+func Run(ctx context.Context, opts Options) (err error) {
+	if opts.JSON {
+		enableJSONOutput()
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		var merr *Error
+		if !errors.As(err, &merr) {
+			merr = &Error{Code: ExitData, Err: err}
+			err = merr
+		}
+		emitError(merr)
+	}()
+
 	if opts.BatchSize <= 0 {
 		opts.BatchSize = DefaultBatchSize
 	}
@@ -51,7 +112,7 @@ func Run(ctx context.Context, opts Options) error {
 	// Connect to SQLite
 	sqlite, err := ConnectSQLite(opts.SQLitePath)
 	if err != nil {
-		return fmt.Errorf("SQLite connection failed: %w", err)
+		return connectionError(fmt.Errorf("SQLite connection failed: %w", err))
 	}
 	defer sqlite.Close()
 	PrintConnected("SQLite", opts.SQLitePath+" (read-only)")
@@ -59,7 +120,7 @@ func Run(ctx context.Context, opts Options) error {
 	// Connect to PostgreSQL
 	pgConfig, err := pgxpool.ParseConfig(opts.PostgresURL)
 	if err != nil {
-		return fmt.Errorf("failed to parse PostgreSQL URL: %w", err)
+		return validationError(fmt.Errorf("failed to parse PostgreSQL URL: %w", err))
 	}
 
 	// Use smaller pool for migration
@@ -68,23 +129,42 @@ func Run(ctx context.Context, opts Options) error {
 
 	pg, err := pgxpool.NewWithConfig(ctx, pgConfig)
 	if err != nil {
-		return fmt.Errorf("PostgreSQL connection failed: %w", err)
+		return connectionError(fmt.Errorf("PostgreSQL connection failed: %w", err))
 	}
 	defer pg.Close()
 
 	// Verify connection
 	if err := pg.Ping(ctx); err != nil {
-		return fmt.Errorf("PostgreSQL ping failed: %w", err)
+		return connectionError(fmt.Errorf("PostgreSQL ping failed: %w", err))
 	}
 
 	// Mask password in URL for display
 	displayURL := maskPassword(opts.PostgresURL)
 	PrintConnected("PostgreSQL", displayURL)
 
-	// Check PostgreSQL tables are empty
-	PrintCheckingTables()
-	if err := checkEmptyTables(ctx, pg); err != nil {
-		return err
+	tables, err := selectTables(opts.Tables, opts.SkipTables)
+	if err != nil {
+		return validationError(err)
+	}
+
+	if opts.CopyMedia {
+		if err := checkMediaRoots(opts.SourceMediaRoot, opts.TargetMediaRoot); err != nil {
+			return validationError(err)
+		}
+		if opts.MediaReport == "" {
+			opts.MediaReport = filepath.Join(opts.TargetMediaRoot, "migration-missing-media.tsv")
+		}
+	}
+
+	// Check PostgreSQL tables are empty, unless continuing a migration that
+	// stopped partway
+	if opts.Resume {
+		PrintResuming()
+	} else {
+		PrintCheckingTables()
+		if err := checkEmptyTables(ctx, pg, tables); err != nil {
+			return err
+		}
 	}
 
 	// Get counts from SQLite
@@ -93,19 +173,91 @@ func Run(ctx context.Context, opts Options) error {
 		return fmt.Errorf("failed to get SQLite counts: %w", err)
 	}
 
+	if err := checkDependencies(ctx, pg, tables, counts); err != nil {
+		return err
+	}
+
 	// Dry run: just show what would be migrated
 	if opts.DryRun {
-		PrintDryRun(counts)
+		PrintDryRun(tables, counts)
 		return nil
 	}
 
 	// Run the actual migration
-	return runMigration(ctx, sqlite, pg, opts, counts)
+	return runMigration(ctx, sqlite, pg, opts, tables)
+}
+
+// ParseTables splits a comma-separated --tables or --skip-tables value,
+// rejecting unknown tables
+func ParseTables(value string) ([]string, error) {
+	result := []string{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !slices.Contains(appTables, name) {
+			return nil, fmt.Errorf("unknown table %q (valid: %s)", name, strings.Join(appTables, ", "))
+		}
+		if !slices.Contains(result, name) {
+			result = append(result, name)
+		}
+	}
+	return result, nil
 }
 
-// checkEmptyTables verifies that PostgreSQL application tables are empty
-func checkEmptyTables(ctx context.Context, pg *pgxpool.Pool) error {
+// selectTables returns the application tables to migrate, in dependency
+// order: those in only (all if empty), less those in skip
+func selectTables(only, skip []string) ([]string, error) {
+	for _, table := range slices.Concat(only, skip) {
+		if !slices.Contains(appTables, table) {
+			return nil, fmt.Errorf("unknown table %q (valid: %s)", table, strings.Join(appTables, ", "))
+		}
+	}
+
+	var tables []string
 	for _, table := range appTables {
+		if (len(only) == 0 || slices.Contains(only, table)) && !slices.Contains(skip, table) {
+			tables = append(tables, table)
+		}
+	}
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("no tables selected")
+	}
+	return tables, nil
+}
+
+// checkDependencies verifies that the tables referenced by each selected
+// table are either selected too, or already hold all their SQLite rows in
+// PostgreSQL. Otherwise the copy would fail on foreign keys partway through.
+func checkDependencies(ctx context.Context, pg *pgxpool.Pool, tables []string, counts map[string]int64) error {
+	checked := make(map[string]bool)
+	for _, table := range tables {
+		if counts[table] == 0 {
+			continue
+		}
+		for _, dep := range tableDependencies[table] {
+			if slices.Contains(tables, dep) || checked[dep] {
+				continue
+			}
+
+			pgCount, err := countPGTable(ctx, pg, dep)
+			if err != nil {
+				return err
+			}
+			if pgCount < counts[dep] {
+				return validationError(fmt.Errorf("table %s references %s, which isn't selected and has %d of %d rows in PostgreSQL; migrate %s first or include it",
+					table, dep, pgCount, counts[dep], dep))
+			}
+			checked[dep] = true
+		}
+	}
+	return nil
+}
+
+// checkEmptyTables verifies that the PostgreSQL tables to migrate are empty
+func checkEmptyTables(ctx context.Context, pg *pgxpool.Pool, tables []string) error {
+	for _, table := range tables {
 		// Config table is special - it has a default row
 		if table == "config" {
 			count, err := countPGTable(ctx, pg, table)
@@ -115,7 +267,7 @@ func checkEmptyTables(ctx context.Context, pg *pgxpool.Pool) error {
 			// Config should have exactly 1 row (the default)
 			if count > 1 {
 				PrintTableStatus(table, false)
-				return fmt.Errorf("table %s has %d rows (expected 0 or 1)", table, count)
+				return validationError(fmt.Errorf("table %s has %d rows (expected 0 or 1)", table, count))
 			}
 			PrintTableStatus(table, true)
 			continue
@@ -127,7 +279,7 @@ func checkEmptyTables(ctx context.Context, pg *pgxpool.Pool) error {
 		}
 		if count > 0 {
 			PrintTableStatus(table, false)
-			return fmt.Errorf("table %s is not empty (has %d rows)", table, count)
+			return validationError(fmt.Errorf("table %s is not empty (has %d rows); use --resume to continue an interrupted migration", table, count))
 		}
 		PrintTableStatus(table, true)
 	}
@@ -146,88 +298,83 @@ func countPGTable(ctx context.Context, pg *pgxpool.Pool, table string) (int64, e
 	return count, nil
 }
 
-// runMigration performs the actual data migration
-func runMigration(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool, opts Options, counts map[string]int64) error {
-	PrintMigrating()
-
-	summary := NewSummary()
-	progress := &Progress{}
-
-	// 1. Migrate users
-	rows, err := MigrateUsers(ctx, sqlite, pg, opts.BatchSize, opts.DryRun, progress)
-	if err != nil {
-		PrintError("users", err)
-		return err
-	}
-	summary.Add(MigrationResult{Table: "users", Rows: rows, Duration: time.Since(progress.StartTime)})
-
-	// 2. Migrate config (UPDATE only)
-	configStart := time.Now()
-	if err := MigrateConfig(ctx, sqlite, pg, opts.DryRun, progress); err != nil {
-		PrintError("config", err)
-		return err
+// tableMigrator copies one table, returning the number of rows processed
+type tableMigrator func(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool, batchSize int, dryRun bool, progress *Progress) (int64, error)
+
+// tableMigrators maps each application table to its migration. Tables
+// with files copy them with media, if it's set.
+func tableMigrators(media *MediaCopier) map[string]tableMigrator {
+	return map[string]tableMigrator{
+		"users": func(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool, batchSize int, dryRun bool, progress *Progress) (int64, error) {
+			return MigrateUsers(ctx, sqlite, pg, batchSize, dryRun, progress, media)
+		},
+		"config":      migrateConfigRow,
+		"invitations": MigrateInvitations,
+		"categories": func(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool, batchSize int, dryRun bool, progress *Progress) (int64, error) {
+			return MigrateCategories(ctx, sqlite, pg, batchSize, dryRun, progress, media)
+		},
+		"videos": func(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool, batchSize int, dryRun bool, progress *Progress) (int64, error) {
+			return MigrateVideos(ctx, sqlite, pg, batchSize, dryRun, progress, media)
+		},
+		"playlists":             MigratePlaylists,
+		"playlist_videos":       MigratePlaylistVideos,
+		"comments":              MigrateComments,
+		"password_reset_tokens": MigratePasswordResetTokens,
 	}
-	configRows := int64(0)
-	if counts["config"] > 0 {
-		configRows = 1
-	}
-	summary.Add(MigrationResult{Table: "config", Rows: configRows, Duration: time.Since(configStart)})
+}
 
-	// 3. Migrate invitations
-	rows, err = MigrateInvitations(ctx, sqlite, pg, opts.BatchSize, opts.DryRun, progress)
-	if err != nil {
-		PrintError("invitations", err)
-		return err
+// migrateConfigRow adapts MigrateConfig to tableMigrator. Config is a single
+// row, updated rather than copied.
+func migrateConfigRow(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool, batchSize int, dryRun bool, progress *Progress) (int64, error) {
+	if err := MigrateConfig(ctx, sqlite, pg, dryRun, progress); err != nil {
+		return 0, err
 	}
-	summary.Add(MigrationResult{Table: "invitations", Rows: rows, Duration: time.Since(progress.StartTime)})
+	return progress.Total, nil
+}
 
-	// 4. Migrate categories
-	rows, err = MigrateCategories(ctx, sqlite, pg, opts.BatchSize, opts.DryRun, progress)
-	if err != nil {
-		PrintError("categories", err)
-		return err
-	}
-	summary.Add(MigrationResult{Table: "categories", Rows: rows, Duration: time.Since(progress.StartTime)})
+// runMigration performs the actual data migration of the selected tables,
+// in dependency order
+func runMigration(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool, opts Options, tables []string) error {
+	PrintMigrating()
 
-	// 5. Migrate videos
-	rows, err = MigrateVideos(ctx, sqlite, pg, opts.BatchSize, opts.DryRun, progress)
-	if err != nil {
-		PrintError("videos", err)
-		return err
-	}
-	summary.Add(MigrationResult{Table: "videos", Rows: rows, Duration: time.Since(progress.StartTime)})
+	summary := NewSummary()
+	progress := &Progress{}
 
-	// 6. Migrate playlists
-	rows, err = MigratePlaylists(ctx, sqlite, pg, opts.BatchSize, opts.DryRun, progress)
-	if err != nil {
-		PrintError("playlists", err)
-		return err
+	var media *MediaCopier
+	if opts.CopyMedia {
+		media = NewMediaCopier(opts.SourceMediaRoot, opts.TargetMediaRoot)
+		summary.Media = media
+		summary.MediaReport = opts.MediaReport
 	}
-	summary.Add(MigrationResult{Table: "playlists", Rows: rows, Duration: time.Since(progress.StartTime)})
+	migrators := tableMigrators(media)
 
-	// 7. Migrate playlist_videos
-	rows, err = MigratePlaylistVideos(ctx, sqlite, pg, opts.BatchSize, opts.DryRun, progress)
-	if err != nil {
-		PrintError("playlist_videos", err)
-		return err
+	// Report what's missing so far even if a table fails
+	reportMedia := func() {
+		if media == nil || len(media.Missing) == 0 {
+			return
+		}
+		if err := media.WriteReport(opts.MediaReport); err != nil {
+			fmt.Fprintf(humanOut, "\nWarning: %v\n", err)
+			summary.Warnings = append(summary.Warnings, err.Error())
+		}
 	}
-	summary.Add(MigrationResult{Table: "playlist_videos", Rows: rows, Duration: time.Since(progress.StartTime)})
 
-	// 8. Migrate comments
-	rows, err = MigrateComments(ctx, sqlite, pg, opts.BatchSize, opts.DryRun, progress)
-	if err != nil {
-		PrintError("comments", err)
-		return err
-	}
-	summary.Add(MigrationResult{Table: "comments", Rows: rows, Duration: time.Since(progress.StartTime)})
+	for _, table := range tables {
+		// Reset for tables that skip Start when SQLite has no rows
+		progress.Start(table, 0)
 
-	// Print skipped table
-	if prtCount := counts["password_reset_tokens"]; prtCount > 0 {
-		PrintSkipped("password_reset_tokens", "ephemeral data")
+		rows, err := migrators[table](ctx, sqlite, pg, opts.BatchSize, opts.DryRun, progress)
+		if err != nil {
+			PrintError(table, err)
+			reportMedia()
+			return &Error{Code: ExitData, Table: table, Err: err}
+		}
+		summary.Add(MigrationResult{Table: table, Rows: rows, Existing: progress.Existing, Duration: time.Since(progress.StartTime)})
 	}
+	reportMedia()
 
 	// Verify migration
-	if err := verifyMigration(ctx, sqlite, pg); err != nil {
+	if err := verifyMigration(ctx, sqlite, pg, tables); err != nil {
 		return fmt.Errorf("migration verification failed: %w", err)
 	}
 
@@ -237,14 +384,15 @@ func runMigration(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool, opts
 	return nil
 }
 
-// verifyMigration checks that row counts match between SQLite and PostgreSQL
-func verifyMigration(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool) error {
-	tables := []string{
-		"users", "invitations", "categories", "videos",
-		"playlists", "playlist_videos", "comments",
-	}
-
+// verifyMigration checks that row counts of the migrated tables match
+// between SQLite and PostgreSQL
+func verifyMigration(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool, tables []string) error {
 	for _, table := range tables {
+		if table == "config" {
+			// Updated in place; PostgreSQL always has its one row
+			continue
+		}
+
 		var sqliteCount int64
 		var pgCount int64
 		var err error
@@ -264,6 +412,8 @@ func verifyMigration(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool) er
 			sqliteCount, err = sqlite.CountPlaylistVideos(ctx)
 		case "comments":
 			sqliteCount, err = sqlite.CountComments(ctx)
+		case "password_reset_tokens":
+			sqliteCount, err = sqlite.CountPasswordResetTokens(ctx)
 		}
 		if err != nil {
 			return fmt.Errorf("failed to count SQLite %s: %w", table, err)
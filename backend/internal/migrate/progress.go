@@ -1,16 +1,92 @@
 package migrate
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"strings"
 	"time"
 )
 
+// Human-readable output goes to humanOut. With JSON output, it moves to
+// stderr, and stdout carries only newline-delimited JSON events.
+var (
+	humanOut io.Writer = os.Stdout
+	jsonOut  *json.Encoder
+)
+
+// enableJSONOutput switches to JSON events on stdout
+func enableJSONOutput() {
+	humanOut = os.Stderr
+	jsonOut = json.NewEncoder(os.Stdout)
+}
+
+// emitJSON writes a JSON event if JSON output is enabled
+func emitJSON(event any) {
+	if jsonOut != nil {
+		jsonOut.Encode(event)
+	}
+}
+
+// progressEvent reports a table's progress
+type progressEvent struct {
+	Table    string `json:"table"`
+	Done     int64  `json:"done"`
+	Total    int64  `json:"total"`
+	Existing int64  `json:"existing,omitempty"`
+	Phase    string `json:"phase"` // "copy" while copying, "done" once finished
+}
+
+// tableEvent is a table's line in the summary or dry run events
+type tableEvent struct {
+	Table      string `json:"table"`
+	Rows       int64  `json:"rows"` // Migrated, or that would be for a dry run
+	Existing   int64  `json:"existing,omitempty"`
+	DurationMS int64  `json:"duration_ms,omitempty"`
+}
+
+// mediaEvent reports the media files copied with --copy-media
+type mediaEvent struct {
+	Copied  int    `json:"copied"`
+	Skipped int    `json:"skipped"`
+	Bytes   int64  `json:"bytes"`
+	Missing int    `json:"missing"`
+	Report  string `json:"report,omitempty"`
+}
+
+// summaryEvent is the last event of a migration: "summary" once it's
+// finished, or "dry_run" with what would be migrated
+type summaryEvent struct {
+	Phase        string       `json:"phase"`
+	Tables       []tableEvent `json:"tables"`
+	TotalRows    int64        `json:"total_rows"`
+	ExistingRows int64        `json:"existing_rows"`
+	DurationMS   int64        `json:"duration_ms"`
+	Media        *mediaEvent  `json:"media,omitempty"`
+	Warnings     []string     `json:"warnings"`
+}
+
+// errorEvent is the last event of a failed migration
+type errorEvent struct {
+	Phase    string `json:"phase"` // Always "error"
+	Kind     string `json:"kind"`  // "validation", "connection" or "data"
+	ExitCode int    `json:"exit_code"`
+	Table    string `json:"table,omitempty"`
+	Error    string `json:"error"`
+}
+
+// emitError writes the error event of a failed migration
+func emitError(err *Error) {
+	emitJSON(errorEvent{Phase: "error", Kind: err.kind(), ExitCode: err.Code, Table: err.Table, Error: err.Error()})
+}
+
 // Progress tracks migration progress for a table
 type Progress struct {
 	Table     string
 	Total     int64
 	Migrated  int64
+	Existing  int64 // Rows already in PostgreSQL, from an earlier run
 	StartTime time.Time
 }
 
@@ -19,6 +95,7 @@ func (p *Progress) Start(table string, total int64) {
 	p.Table = table
 	p.Total = total
 	p.Migrated = 0
+	p.Existing = 0
 	p.StartTime = time.Now()
 }
 
@@ -32,11 +109,22 @@ func (p *Progress) Update(migrated int64) {
 func (p *Progress) Complete() {
 	p.Migrated = p.Total
 	p.print(true)
-	fmt.Println()
 }
 
-// print outputs the current progress
+// print outputs the current progress: a JSON event, or a progress bar
+// redrawn in place
 func (p *Progress) print(complete bool) {
+	if jsonOut != nil {
+		phase := "copy"
+		if complete {
+			phase = "done"
+		}
+		emitJSON(progressEvent{Table: p.Table, Done: p.Migrated, Total: p.Total, Existing: p.Existing, Phase: phase})
+		return
+	}
+	if complete {
+		defer fmt.Fprintln(humanOut)
+	}
 	if p.Total == 0 {
 		return
 	}
@@ -50,15 +138,18 @@ func (p *Progress) print(complete bool) {
 
 	status := ""
 	if complete {
-		status = fmt.Sprintf("\r  %-17s [%s] %s/%s (%.0f%%) - %.1fs",
+		status = fmt.Sprintf("\r  %-23s [%s] %s/%s (%.0f%%) - %.1fs",
 			p.Table,
 			bar,
 			formatNumber(p.Migrated),
 			formatNumber(p.Total),
 			percent,
 			elapsed.Seconds())
+		if p.Existing > 0 {
+			status += fmt.Sprintf(", %s already present", formatNumber(p.Existing))
+		}
 	} else {
-		status = fmt.Sprintf("\r  %-17s [%s] %s/%s (%.0f%%)",
+		status = fmt.Sprintf("\r  %-23s [%s] %s/%s (%.0f%%)",
 			p.Table,
 			bar,
 			formatNumber(p.Migrated),
@@ -66,13 +157,14 @@ func (p *Progress) print(complete bool) {
 			percent)
 	}
 
-	fmt.Print(status)
+	fmt.Fprint(humanOut, status)
 }
 
 // MigrationResult holds the result of migrating a single table
 type MigrationResult struct {
 	Table    string
 	Rows     int64
+	Existing int64 // Of Rows, how many were already in PostgreSQL
 	Duration time.Duration
 	Skipped  bool
 	Error    error
@@ -80,9 +172,12 @@ type MigrationResult struct {
 
 // MigrationSummary holds all migration results
 type MigrationSummary struct {
-	Results   []MigrationResult
-	StartTime time.Time
-	EndTime   time.Time
+	Results     []MigrationResult
+	StartTime   time.Time
+	EndTime     time.Time
+	Media       *MediaCopier // Set when copying media
+	MediaReport string
+	Warnings    []string
 }
 
 // NewSummary creates a new migration summary
@@ -108,59 +203,110 @@ func (s *MigrationSummary) TotalRows() int64 {
 	var total int64
 	for _, r := range s.Results {
 		if !r.Skipped {
-			total += r.Rows
+			total += r.Rows - r.Existing
 		}
 	}
 	return total
 }
 
+// TotalExisting returns the total number of rows skipped as already in
+// PostgreSQL
+func (s *MigrationSummary) TotalExisting() int64 {
+	var total int64
+	for _, r := range s.Results {
+		total += r.Existing
+	}
+	return total
+}
+
 // TotalDuration returns the total migration duration
 func (s *MigrationSummary) TotalDuration() time.Duration {
 	return s.EndTime.Sub(s.StartTime)
 }
 
-// Print prints the migration summary
+// Print prints the migration summary, and emits it as a JSON event
 func (s *MigrationSummary) Print() {
-	fmt.Println()
-	fmt.Println("Migration completed successfully!")
-	fmt.Println("=====================================")
-	fmt.Printf("Total rows migrated: %s\n", formatNumber(s.TotalRows()))
-	fmt.Printf("Total time: %.1fs\n", s.TotalDuration().Seconds())
+	s.emit()
+
+	fmt.Fprintln(humanOut)
+	fmt.Fprintln(humanOut, "Migration completed successfully!")
+	fmt.Fprintln(humanOut, "=====================================")
+	fmt.Fprintf(humanOut, "Total rows migrated: %s\n", formatNumber(s.TotalRows()))
+	if existing := s.TotalExisting(); existing > 0 {
+		fmt.Fprintf(humanOut, "Rows already present: %s (skipped)\n", formatNumber(existing))
+	}
+	fmt.Fprintf(humanOut, "Total time: %.1fs\n", s.TotalDuration().Seconds())
+
+	if media := s.Media; media != nil {
+		fmt.Fprintf(humanOut, "Media files copied: %s (%.1f MB)\n", formatNumber(int64(media.Copied)), float64(media.Bytes)/(1024*1024))
+		if media.Skipped > 0 {
+			fmt.Fprintf(humanOut, "Media files already present: %s (skipped)\n", formatNumber(int64(media.Skipped)))
+		}
+		if len(media.Missing) > 0 {
+			fmt.Fprintf(humanOut, "Media files missing: %s (listed in %s)\n", formatNumber(int64(len(media.Missing))), s.MediaReport)
+		}
+	}
 }
 
-// PrintDryRun prints what would be migrated
-func PrintDryRun(counts map[string]int64) {
-	fmt.Println()
-	fmt.Println("Would migrate:")
+// emit writes the summary event. Each missing media file is a warning.
+func (s *MigrationSummary) emit() {
+	if jsonOut == nil {
+		return
+	}
 
-	tables := []string{
-		"users", "config", "invitations", "categories",
-		"videos", "playlists", "playlist_videos", "comments",
+	event := summaryEvent{
+		Phase:        "summary",
+		Tables:       make([]tableEvent, 0, len(s.Results)),
+		TotalRows:    s.TotalRows(),
+		ExistingRows: s.TotalExisting(),
+		DurationMS:   s.TotalDuration().Milliseconds(),
+		Warnings:     []string{},
+	}
+	for _, r := range s.Results {
+		event.Tables = append(event.Tables, tableEvent{Table: r.Table, Rows: r.Rows - r.Existing, Existing: r.Existing, DurationMS: r.Duration.Milliseconds()})
+	}
+	if media := s.Media; media != nil {
+		event.Media = &mediaEvent{Copied: media.Copied, Skipped: media.Skipped, Bytes: media.Bytes, Missing: len(media.Missing)}
+		if len(media.Missing) > 0 {
+			event.Media.Report = s.MediaReport
+		}
+		for _, missing := range media.Missing {
+			event.Warnings = append(event.Warnings, fmt.Sprintf("%s %s: missing %s: %s", missing.Table, missing.ID, missing.Path, missing.Reason))
+		}
 	}
+	event.Warnings = append(event.Warnings, s.Warnings...)
+	emitJSON(event)
+}
+
+// PrintDryRun prints what would be migrated from the selected tables
+func PrintDryRun(tables []string, counts map[string]int64) {
+	fmt.Fprintln(humanOut)
+	fmt.Fprintln(humanOut, "Would migrate:")
 
 	for _, table := range tables {
 		count := counts[table]
 		if table == "config" {
-			fmt.Printf("  %-17s %s row (update)\n", table+":", formatNumber(count))
+			fmt.Fprintf(humanOut, "  %-23s %s row (update)\n", table+":", formatNumber(count))
 		} else {
-			fmt.Printf("  %-17s %s rows\n", table+":", formatNumber(count))
+			fmt.Fprintf(humanOut, "  %-23s %s rows\n", table+":", formatNumber(count))
 		}
 	}
 
-	// Show skipped table
-	if count, ok := counts["password_reset_tokens"]; ok && count > 0 {
-		fmt.Printf("\n  [SKIP] password_reset_tokens: %s rows (ephemeral data)\n", formatNumber(count))
+	var total int64
+	for _, table := range tables {
+		total += counts[table]
 	}
 
-	var total int64
-	for table, count := range counts {
-		if table != "password_reset_tokens" {
-			total += count
+	fmt.Fprintf(humanOut, "\nTotal: %s rows would be migrated\n", formatNumber(total))
+	fmt.Fprintln(humanOut, "\nNo changes made (dry run).")
+
+	if jsonOut != nil {
+		event := summaryEvent{Phase: "dry_run", Tables: make([]tableEvent, 0, len(tables)), TotalRows: total, Warnings: []string{}}
+		for _, table := range tables {
+			event.Tables = append(event.Tables, tableEvent{Table: table, Rows: counts[table]})
 		}
+		emitJSON(event)
 	}
-
-	fmt.Printf("\nTotal: %s rows would be migrated\n", formatNumber(total))
-	fmt.Println("\nNo changes made (dry run).")
 }
 
 // formatNumber formats a number with commas for readability
@@ -186,28 +332,34 @@ func formatNumber(n int64) string {
 // PrintHeader prints the migration header
 func PrintHeader(dryRun bool) {
 	if dryRun {
-		fmt.Println("Clipset SQLite -> PostgreSQL Migration (DRY RUN)")
+		fmt.Fprintln(humanOut, "Clipset SQLite -> PostgreSQL Migration (DRY RUN)")
 	} else {
-		fmt.Println("Clipset SQLite -> PostgreSQL Migration")
+		fmt.Fprintln(humanOut, "Clipset SQLite -> PostgreSQL Migration")
 	}
-	fmt.Println("=====================================")
-	fmt.Println()
+	fmt.Fprintln(humanOut, "=====================================")
+	fmt.Fprintln(humanOut)
 }
 
 // PrintConnecting prints connection status
 func PrintConnecting() {
-	fmt.Println("Connecting to databases...")
+	fmt.Fprintln(humanOut, "Connecting to databases...")
 }
 
 // PrintConnected prints successful connection
 func PrintConnected(dbType, path string) {
-	fmt.Printf("  %-11s %s\n", dbType+":", path)
+	fmt.Fprintf(humanOut, "  %-11s %s\n", dbType+":", path)
 }
 
 // PrintCheckingTables prints table checking status
 func PrintCheckingTables() {
-	fmt.Println()
-	fmt.Println("Checking PostgreSQL tables are empty...")
+	fmt.Fprintln(humanOut)
+	fmt.Fprintln(humanOut, "Checking PostgreSQL tables are empty...")
+}
+
+// PrintResuming prints that the empty table check is skipped
+func PrintResuming() {
+	fmt.Fprintln(humanOut)
+	fmt.Fprintln(humanOut, "Resuming: rows already in PostgreSQL will be skipped")
 }
 
 // PrintTableStatus prints the status of a table check
@@ -219,23 +371,24 @@ func PrintTableStatus(table string, empty bool) {
 	if !empty {
 		status = "HAS DATA"
 	}
-	fmt.Printf("  %-17s %s\n", table+":", status)
+	fmt.Fprintf(humanOut, "  %-23s %s\n", table+":", status)
 }
 
 // PrintMigrating prints the migration start header
 func PrintMigrating() {
-	fmt.Println()
-	fmt.Println("Migrating data...")
-	fmt.Println()
+	fmt.Fprintln(humanOut)
+	fmt.Fprintln(humanOut, "Migrating data...")
+	fmt.Fprintln(humanOut)
 }
 
 // PrintSkipped prints a skipped table message
 func PrintSkipped(table, reason string) {
-	fmt.Printf("\n  [SKIPPED] %s (%s)\n", table, reason)
+	fmt.Fprintf(humanOut, "\n  [SKIPPED] %s (%s)\n", table, reason)
 }
 
 // PrintError prints an error message
 func PrintError(table string, err error) {
-	fmt.Printf("\n\nError: failed to migrate %s: %v\n", table, err)
-	fmt.Println("\nMigration aborted. No partial changes committed.")
+	fmt.Fprintf(humanOut, "\n\nError: failed to migrate %s: %v\n", table, err)
+	fmt.Fprintln(humanOut, "\nMigration aborted. Batches already copied are kept; run again with")
+	fmt.Fprintln(humanOut, "--resume to copy the rest.")
 }
@@ -130,6 +130,16 @@ type SQLiteComment struct {
 	UpdatedAt        string
 }
 
+// SQLitePasswordResetToken represents a password_reset_tokens row from SQLite.
+// TokenHash is the hex SHA-256 of the emailed token, as the Go backend stores it.
+type SQLitePasswordResetToken struct {
+	ID        string
+	UserID    string
+	TokenHash string
+	ExpiresAt string
+	CreatedAt *string
+}
+
 // SQLiteConfig represents the config row from SQLite
 type SQLiteConfig struct {
 	ID                     int
@@ -447,6 +457,33 @@ func (s *SQLiteDB) GetComments(ctx context.Context, offset, limit int) ([]SQLite
 	return comments, rows.Err()
 }
 
+// GetPasswordResetTokens returns password reset tokens with pagination
+func (s *SQLiteDB) GetPasswordResetTokens(ctx context.Context, offset, limit int) ([]SQLitePasswordResetToken, error) {
+	query := `
+		SELECT id, user_id, token_hash, expires_at, created_at
+		FROM password_reset_tokens
+		ORDER BY created_at ASC
+		LIMIT ? OFFSET ?`
+
+	rows, err := s.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query password_reset_tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []SQLitePasswordResetToken
+	for rows.Next() {
+		var t SQLitePasswordResetToken
+		err := rows.Scan(&t.ID, &t.UserID, &t.TokenHash, &t.ExpiresAt, &t.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan password_reset_tokens row: %w", err)
+		}
+		tokens = append(tokens, t)
+	}
+
+	return tokens, rows.Err()
+}
+
 // GetConfig returns the config row (if exists)
 func (s *SQLiteDB) GetConfig(ctx context.Context) (*SQLiteConfig, error) {
 	query := `
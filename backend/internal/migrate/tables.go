@@ -3,6 +3,7 @@ package migrate
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,8 +11,8 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// MigrateUsers migrates the users table
-func MigrateUsers(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool, batchSize int, dryRun bool, progress *Progress) (int64, error) {
+// MigrateUsers migrates the users table, copying avatars if media is set
+func MigrateUsers(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool, batchSize int, dryRun bool, progress *Progress, media *MediaCopier) (int64, error) {
 	total, err := sqlite.CountUsers(ctx)
 	if err != nil {
 		return 0, err
@@ -41,16 +42,27 @@ func MigrateUsers(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool, batch
 			break
 		}
 
+		// Copy the batch's files first, so rows only reach PostgreSQL once
+		// their files are in place
+		if media != nil {
+			for i := range users {
+				if err := media.copyAvatar(users[i]); err != nil {
+					return migrated, err
+				}
+			}
+		}
+
 		// Start transaction for this batch
 		tx, err := pg.Begin(ctx)
 		if err != nil {
 			return migrated, fmt.Errorf("failed to begin transaction: %w", err)
 		}
 
-		// Use COPY for fast bulk insert
-		_, err = tx.CopyFrom(
+		// Use COPY for fast bulk insert, skipping rows already migrated
+		inserted, err := copyNew(
 			ctx,
-			pgx.Identifier{"users"},
+			tx,
+			"users",
 			[]string{"id", "email", "username", "password_hash", "role", "created_at",
 				"is_active", "avatar_filename", "weekly_upload_bytes", "last_upload_reset"},
 			pgx.CopyFromSlice(len(users), func(i int) ([]any, error) {
@@ -99,6 +111,7 @@ func MigrateUsers(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool, batch
 			return migrated, fmt.Errorf("failed to commit users: %w", err)
 		}
 
+		progress.Existing += int64(len(users)) - inserted
 		migrated += int64(len(users))
 		progress.Update(migrated)
 		offset += batchSize
@@ -228,9 +241,10 @@ func MigrateInvitations(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool,
 			return migrated, fmt.Errorf("failed to begin transaction: %w", err)
 		}
 
-		_, err = tx.CopyFrom(
+		inserted, err := copyNew(
 			ctx,
-			pgx.Identifier{"invitations"},
+			tx,
+			"invitations",
 			[]string{"id", "email", "token", "created_by", "created_at", "expires_at", "used", "used_at"},
 			pgx.CopyFromSlice(len(invitations), func(i int) ([]any, error) {
 				inv := invitations[i]
@@ -281,6 +295,7 @@ func MigrateInvitations(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool,
 			return migrated, fmt.Errorf("failed to commit invitations: %w", err)
 		}
 
+		progress.Existing += int64(len(invitations)) - inserted
 		migrated += int64(len(invitations))
 		progress.Update(migrated)
 		offset += batchSize
@@ -290,8 +305,9 @@ func MigrateInvitations(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool,
 	return migrated, nil
 }
 
-// MigrateCategories migrates the categories table
-func MigrateCategories(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool, batchSize int, dryRun bool, progress *Progress) (int64, error) {
+// MigrateCategories migrates the categories table, copying their images if
+// media is set
+func MigrateCategories(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool, batchSize int, dryRun bool, progress *Progress, media *MediaCopier) (int64, error) {
 	total, err := sqlite.CountCategories(ctx)
 	if err != nil {
 		return 0, err
@@ -323,14 +339,25 @@ func MigrateCategories(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool,
 			break
 		}
 
+		// Copy the batch's files first, so rows only reach PostgreSQL once
+		// their files are in place
+		if media != nil {
+			for i := range categories {
+				if err := media.copyCategoryImage(categories[i]); err != nil {
+					return migrated, err
+				}
+			}
+		}
+
 		tx, err := pg.Begin(ctx)
 		if err != nil {
 			return migrated, fmt.Errorf("failed to begin transaction: %w", err)
 		}
 
-		_, err = tx.CopyFrom(
+		inserted, err := copyNew(
 			ctx,
-			pgx.Identifier{"categories"},
+			tx,
+			"categories",
 			[]string{"id", "name", "slug", "description", "image_filename", "created_by", "created_at", "updated_at"},
 			pgx.CopyFromSlice(len(categories), func(i int) ([]any, error) {
 				c := categories[i]
@@ -382,6 +409,7 @@ func MigrateCategories(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool,
 			return migrated, fmt.Errorf("failed to commit categories: %w", err)
 		}
 
+		progress.Existing += int64(len(categories)) - inserted
 		migrated += int64(len(categories))
 		progress.Update(migrated)
 		offset += batchSize
@@ -391,8 +419,9 @@ func MigrateCategories(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool,
 	return migrated, nil
 }
 
-// MigrateVideos migrates the videos table
-func MigrateVideos(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool, batchSize int, dryRun bool, progress *Progress) (int64, error) {
+// MigrateVideos migrates the videos table. If media is set, each video's
+// files are copied and its storage path rewritten to the target.
+func MigrateVideos(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool, batchSize int, dryRun bool, progress *Progress, media *MediaCopier) (int64, error) {
 	total, err := sqlite.CountVideos(ctx)
 	if err != nil {
 		return 0, err
@@ -424,14 +453,25 @@ func MigrateVideos(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool, batc
 			break
 		}
 
+		// Copy the batch's files first, so rows only reach PostgreSQL once
+		// their files are in place
+		if media != nil {
+			for i := range videos {
+				if err := media.copyVideo(&videos[i]); err != nil {
+					return migrated, err
+				}
+			}
+		}
+
 		tx, err := pg.Begin(ctx)
 		if err != nil {
 			return migrated, fmt.Errorf("failed to begin transaction: %w", err)
 		}
 
-		_, err = tx.CopyFrom(
+		inserted, err := copyNew(
 			ctx,
-			pgx.Identifier{"videos"},
+			tx,
+			"videos",
 			[]string{"id", "short_id", "title", "description", "filename", "thumbnail_filename",
 				"original_filename", "storage_path", "file_size_bytes", "duration_seconds",
 				"uploaded_by", "category_id", "view_count", "processing_status", "error_message", "created_at"},
@@ -498,6 +538,7 @@ func MigrateVideos(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool, batc
 			return migrated, fmt.Errorf("failed to commit videos: %w", err)
 		}
 
+		progress.Existing += int64(len(videos)) - inserted
 		migrated += int64(len(videos))
 		progress.Update(migrated)
 		offset += batchSize
@@ -545,9 +586,10 @@ func MigratePlaylists(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool, b
 			return migrated, fmt.Errorf("failed to begin transaction: %w", err)
 		}
 
-		_, err = tx.CopyFrom(
+		inserted, err := copyNew(
 			ctx,
-			pgx.Identifier{"playlists"},
+			tx,
+			"playlists",
 			[]string{"id", "short_id", "name", "description", "created_by", "is_public", "created_at", "updated_at"},
 			pgx.CopyFromSlice(len(playlists), func(i int) ([]any, error) {
 				p := playlists[i]
@@ -593,6 +635,7 @@ func MigratePlaylists(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool, b
 			return migrated, fmt.Errorf("failed to commit playlists: %w", err)
 		}
 
+		progress.Existing += int64(len(playlists)) - inserted
 		migrated += int64(len(playlists))
 		progress.Update(migrated)
 		offset += batchSize
@@ -640,9 +683,10 @@ func MigratePlaylistVideos(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Po
 			return migrated, fmt.Errorf("failed to begin transaction: %w", err)
 		}
 
-		_, err = tx.CopyFrom(
+		inserted, err := copyNew(
 			ctx,
-			pgx.Identifier{"playlist_videos"},
+			tx,
+			"playlist_videos",
 			[]string{"id", "playlist_id", "video_id", "position", "added_at", "added_by"},
 			pgx.CopyFromSlice(len(pvs), func(i int) ([]any, error) {
 				pv := pvs[i]
@@ -691,6 +735,7 @@ func MigratePlaylistVideos(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Po
 			return migrated, fmt.Errorf("failed to commit playlist_videos: %w", err)
 		}
 
+		progress.Existing += int64(len(pvs)) - inserted
 		migrated += int64(len(pvs))
 		progress.Update(migrated)
 		offset += batchSize
@@ -744,9 +789,10 @@ func MigrateComments(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool, ba
 		return 0, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
-	_, err = tx.CopyFrom(
+	inserted, err := copyNew(
 		ctx,
-		pgx.Identifier{"comments"},
+		tx,
+		"comments",
 		[]string{"id", "video_id", "user_id", "content", "timestamp_seconds", "parent_id", "created_at", "updated_at"},
 		pgx.CopyFromSlice(len(allComments), func(i int) ([]any, error) {
 			c := allComments[i]
@@ -799,6 +845,7 @@ func MigrateComments(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool, ba
 		tx.Rollback(ctx)
 		return 0, fmt.Errorf("failed to copy comments (pass 1): %w", err)
 	}
+	progress.Existing = int64(len(allComments)) - inserted
 
 	// Pass 2: Update parent_id for replies
 	repliesUpdated := 0
@@ -834,3 +881,131 @@ func MigrateComments(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool, ba
 	progress.Complete()
 	return int64(len(allComments)), nil
 }
+
+// MigratePasswordResetTokens migrates the password_reset_tokens table, so
+// reset links sent before the migration keep working until they expire.
+// Both backends store the token's hex SHA-256, so hashes are copied as is.
+func MigratePasswordResetTokens(ctx context.Context, sqlite *SQLiteDB, pg *pgxpool.Pool, batchSize int, dryRun bool, progress *Progress) (int64, error) {
+	total, err := sqlite.CountPasswordResetTokens(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if total == 0 {
+		progress.Start("password_reset_tokens", 0)
+		progress.Complete()
+		return 0, nil
+	}
+
+	progress.Start("password_reset_tokens", total)
+
+	if dryRun {
+		progress.Complete()
+		return total, nil
+	}
+
+	var migrated int64
+	offset := 0
+	migratedAt := time.Now().UTC()
+
+	for {
+		tokens, err := sqlite.GetPasswordResetTokens(ctx, offset, batchSize)
+		if err != nil {
+			return migrated, err
+		}
+
+		if len(tokens) == 0 {
+			break
+		}
+
+		tx, err := pg.Begin(ctx)
+		if err != nil {
+			return migrated, fmt.Errorf("failed to begin transaction: %w", err)
+		}
+
+		inserted, err := copyNew(
+			ctx,
+			tx,
+			"password_reset_tokens",
+			[]string{"id", "user_id", "token_hash", "expires_at", "created_at"},
+			pgx.CopyFromSlice(len(tokens), func(i int) ([]any, error) {
+				t := tokens[i]
+
+				id, err := ParseUUID(t.ID)
+				if err != nil {
+					return nil, fmt.Errorf("password reset token %d: %w", offset+i, err)
+				}
+
+				userID, err := ParseUUID(t.UserID)
+				if err != nil {
+					return nil, fmt.Errorf("password reset token %d user_id: %w", offset+i, err)
+				}
+
+				expiresAt, err := ParseTimestamp(t.ExpiresAt)
+				if err != nil {
+					return nil, fmt.Errorf("password reset token %d expires_at: %w", offset+i, err)
+				}
+
+				// created_at is NOT NULL in PostgreSQL; rows without one
+				// count as created by the migration
+				createdAt, err := ParseNullableTimestamp(t.CreatedAt)
+				if err != nil {
+					return nil, fmt.Errorf("password reset token %d created_at: %w", offset+i, err)
+				}
+				if createdAt == nil {
+					createdAt = &migratedAt
+				}
+
+				// Lookups compare lowercase hex
+				return []any{
+					id,
+					userID,
+					strings.ToLower(t.TokenHash),
+					expiresAt,
+					*createdAt,
+				}, nil
+			}),
+		)
+		if err != nil {
+			tx.Rollback(ctx)
+			return migrated, fmt.Errorf("failed to copy password_reset_tokens: %w", err)
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return migrated, fmt.Errorf("failed to commit password_reset_tokens: %w", err)
+		}
+
+		progress.Existing += int64(len(tokens)) - inserted
+		migrated += int64(len(tokens))
+		progress.Update(migrated)
+		offset += batchSize
+	}
+
+	progress.Complete()
+	return migrated, nil
+}
+
+// copyNew copies rows into table, skipping those whose ID is already there,
+// so a migration that stopped partway can be run again. COPY can't skip
+// conflicts, so rows are copied into a temporary table dropped at commit,
+// then inserted from it. Returns the number of rows inserted.
+func copyNew(ctx context.Context, tx pgx.Tx, table string, columns []string, rows pgx.CopyFromSource) (int64, error) {
+	staging := pgx.Identifier{"migrate_" + table}.Sanitize()
+	target := pgx.Identifier{table}.Sanitize()
+
+	_, err := tx.Exec(ctx, fmt.Sprintf("CREATE TEMP TABLE %s (LIKE %s INCLUDING DEFAULTS) ON COMMIT DROP", staging, target))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create staging table: %w", err)
+	}
+
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"migrate_" + table}, columns, rows); err != nil {
+		return 0, err
+	}
+
+	list := strings.Join(columns, ", ")
+	tag, err := tx.Exec(ctx, fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s ON CONFLICT (id) DO NOTHING", target, list, list, staging))
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert from staging table: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,93 @@
+package apitoken
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/clipset/clipset-go/internal/db/sqlc"
+	"github.com/clipset/clipset-go/internal/domain"
+	"github.com/clipset/clipset-go/internal/services/auth"
+)
+
+// Prefix marks a bearer token as a personal access token rather than a JWT
+const Prefix = "cst_"
+
+// Token scopes
+const (
+	ScopeFull   = "full"   // Everything the owner can do
+	ScopeUpload = "upload" // Upload endpoints only
+)
+
+// last_used_at is only rewritten once it is at least this stale
+const touchInterval = time.Minute
+
+var ErrInvalidToken = errors.New("invalid access token")
+
+// IsValidScope checks if the scope is a known value
+func IsValidScope(scope string) bool {
+	return scope == ScopeFull || scope == ScopeUpload
+}
+
+// Principal is the user a personal access token acts as
+type Principal struct {
+	TokenID  uuid.UUID
+	UserID   uuid.UUID
+	Username string
+	Role     domain.UserRole
+	Scope    string
+}
+
+// Store resolves personal access tokens
+type Store struct {
+	queries *sqlc.Queries
+}
+
+// NewStore creates a new personal access token store
+func NewStore(queries *sqlc.Queries) *Store {
+	return &Store{queries: queries}
+}
+
+// Generate returns a new raw token and the hash to store for it
+func Generate() (token string, tokenHash string, err error) {
+	secret, err := auth.GenerateSecureToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	token = Prefix + secret
+	return token, auth.HashToken(token), nil
+}
+
+// IsToken reports whether a bearer token looks like a personal access token
+func IsToken(token string) bool {
+	return strings.HasPrefix(token, Prefix)
+}
+
+// Resolve looks up the user a token belongs to. Returns ErrInvalidToken for
+// unknown tokens and tokens of deactivated users.
+func (s *Store) Resolve(ctx context.Context, token string) (*Principal, error) {
+	row, err := s.queries.GetPersonalAccessTokenByHash(ctx, auth.HashToken(token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	if row.LastUsedAt == nil || time.Since(*row.LastUsedAt) >= touchInterval {
+		// Usage tracking is best-effort and shouldn't fail the request
+		_ = s.queries.TouchPersonalAccessToken(ctx, row.ID)
+	}
+
+	return &Principal{
+		TokenID:  row.ID,
+		UserID:   row.UserID,
+		Username: row.Username,
+		Role:     row.Role,
+		Scope:    row.Scope,
+	}, nil
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"crypto/md5"
+	"crypto/subtle"
 	"encoding/base64"
 	"fmt"
 	"strings"
@@ -47,24 +48,32 @@ func GenerateSignedHLSURL(path string, secret string, expiresIn time.Duration) s
 	return fmt.Sprintf("%s?md5=%s&expires=%d", uri, token, expires)
 }
 
-// GenerateSignedHLSURLWithDefaults generates a signed URL using the default expiry time
-func GenerateSignedHLSURLWithDefaults(path string, secret string) string {
-	return GenerateSignedHLSURL(path, secret, HLSDefaultExpiry)
+// GenerateSignedHLSURLWithDefaults generates a signed URL using the default
+// expiry time. New URLs are always signed with the first (current) secret;
+// any others are previous secrets that are only still accepted by validation.
+func GenerateSignedHLSURLWithDefaults(path string, secrets []string) string {
+	return GenerateSignedHLSURL(path, secrets[0], HLSDefaultExpiry)
 }
 
-// ValidateHLSSignature validates an HLS signed URL (useful for testing)
-// Returns true if the signature is valid and not expired
-func ValidateHLSSignature(uri string, providedMD5 string, expires int64, secret string) bool {
+// ValidateHLSSignature validates an HLS signed URL against each of the secrets,
+// so URLs signed before a rotation keep working while the old secret is listed.
+// Returns true if the signature is valid for any secret and not expired
+func ValidateHLSSignature(uri string, providedMD5 string, expires int64, secrets []string) bool {
 	// Check if expired
 	if time.Now().Unix() > expires {
 		return false
 	}
 
-	// Recalculate expected hash
-	toSign := fmt.Sprintf("%d%s %s", expires, uri, secret)
-	hash := md5.Sum([]byte(toSign))
-	expectedToken := base64.URLEncoding.EncodeToString(hash[:])
-	expectedToken = strings.TrimRight(expectedToken, "=")
+	for _, secret := range secrets {
+		// Recalculate expected hash
+		toSign := fmt.Sprintf("%d%s %s", expires, uri, secret)
+		hash := md5.Sum([]byte(toSign))
+		expectedToken := base64.URLEncoding.EncodeToString(hash[:])
+		expectedToken = strings.TrimRight(expectedToken, "=")
 
-	return expectedToken == providedMD5
+		if subtle.ConstantTimeCompare([]byte(expectedToken), []byte(providedMD5)) == 1 {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	currentSecret  = "current-secret-0123456789"
+	previousSecret = "previous-secret-0123456789"
+	unknownSecret  = "unknown-secret-0123456789"
+)
+
+// parseSignedHLSURL splits a signed HLS URL into the URI nginx hashes, the
+// md5 parameter and the expiry
+func parseSignedHLSURL(t *testing.T, signed string) (string, string, int64) {
+	t.Helper()
+	parsed, err := url.Parse(signed)
+	if err != nil {
+		t.Fatalf("invalid signed URL %q: %v", signed, err)
+	}
+	expires, err := strconv.ParseInt(parsed.Query().Get("expires"), 10, 64)
+	if err != nil {
+		t.Fatalf("invalid expires in %q: %v", signed, err)
+	}
+	return parsed.Path, parsed.Query().Get("md5"), expires
+}
+
+func TestGenerateSignedHLSURL(t *testing.T) {
+	signed := GenerateSignedHLSURL("ab/cd/abcd_hls/720p/segment000.ts", currentSecret, time.Hour)
+
+	uri, md5, expires := parseSignedHLSURL(t, signed)
+	if uri != "/hls/ab/cd/abcd_hls/720p/segment000.ts" {
+		t.Errorf("uri = %q, want the path under /hls/", uri)
+	}
+	if md5 == "" || strings.ContainsAny(md5, "=+/") {
+		t.Errorf("md5 = %q, want unpadded base64url", md5)
+	}
+	if until := time.Until(time.Unix(expires, 0)); until < 59*time.Minute || until > time.Hour {
+		t.Errorf("expires in %v, want an hour", until)
+	}
+}
+
+func TestGenerateSignedHLSURLWithDefaultsSignsWithCurrentSecret(t *testing.T) {
+	signed := GenerateSignedHLSURLWithDefaults("abcd/segment000.ts", []string{currentSecret, previousSecret})
+
+	uri, md5, expires := parseSignedHLSURL(t, signed)
+	if !ValidateHLSSignature(uri, md5, expires, []string{currentSecret}) {
+		t.Error("URL isn't signed with the current secret")
+	}
+	if ValidateHLSSignature(uri, md5, expires, []string{previousSecret}) {
+		t.Error("URL is signed with the previous secret")
+	}
+}
+
+func TestValidateHLSSignature(t *testing.T) {
+	tests := []struct {
+		name      string
+		signedBy  string
+		expiresIn time.Duration
+		secrets   []string
+		uri       string // Requested instead of the signed URI, if set
+		want      bool
+	}{
+		{"current secret", currentSecret, time.Hour, []string{currentSecret}, "", true},
+		{"current secret during rotation", currentSecret, time.Hour, []string{currentSecret, previousSecret}, "", true},
+		{"previous secret during rotation", previousSecret, time.Hour, []string{currentSecret, previousSecret}, "", true},
+		{"previous secret after rotation", previousSecret, time.Hour, []string{currentSecret}, "", false},
+		{"unknown secret", unknownSecret, time.Hour, []string{currentSecret, previousSecret}, "", false},
+		{"expired", currentSecret, -time.Minute, []string{currentSecret}, "", false},
+		{"expired with previous secret", previousSecret, -time.Minute, []string{currentSecret, previousSecret}, "", false},
+		{"other segment", currentSecret, time.Hour, []string{currentSecret}, "/hls/abcd/segment001.ts", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uri, md5, expires := parseSignedHLSURL(t, GenerateSignedHLSURL("abcd/segment000.ts", tt.signedBy, tt.expiresIn))
+			if tt.uri != "" {
+				uri = tt.uri
+			}
+			if got := ValidateHLSSignature(uri, md5, expires, tt.secrets); got != tt.want {
+				t.Errorf("ValidateHLSSignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateHLSSignatureRejectsExtendedExpiry(t *testing.T) {
+	uri, md5, expires := parseSignedHLSURL(t, GenerateSignedHLSURL("abcd/segment000.ts", currentSecret, time.Hour))
+	if ValidateHLSSignature(uri, md5, expires+3600, []string{currentSecret}) {
+		t.Error("signature accepted with a later expiry than it was signed for")
+	}
+}
@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"time"
 
@@ -15,44 +17,90 @@ var (
 	ErrExpiredToken = errors.New("token has expired")
 )
 
+// ScopeMedia marks a short-lived token that may only load images, for use in
+// <img src> URLs. Session tokens have no scope.
+const ScopeMedia = "media"
+
 // TokenClaims represents the claims in a JWT token
 type TokenClaims struct {
 	UserID   uuid.UUID       `json:"user_id"`
 	Username string          `json:"username"`
 	Role     domain.UserRole `json:"role"`
+	Scope    string          `json:"scope,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// signingKey is an HMAC secret and the key ID tokens signed with it carry
+type signingKey struct {
+	id     string
+	secret []byte
+}
+
 // JWTService handles JWT token operations
 type JWTService struct {
-	secret     []byte
+	keys       []signingKey // keys[0] signs; all keys verify
 	expiration time.Duration
 }
 
-// NewJWTService creates a new JWT service
+// NewJWTService creates a new JWT service with a single signing key
 func NewJWTService(secret string, expiration time.Duration) *JWTService {
+	return NewJWTServiceWithKeys([]string{secret}, expiration)
+}
+
+// NewJWTServiceWithKeys creates a JWT service for key rotation. Tokens are
+// signed with the first secret; the rest only verify tokens issued before the
+// rotation. Key IDs are derived from the secrets, so reordering them is safe.
+func NewJWTServiceWithKeys(secrets []string, expiration time.Duration) *JWTService {
+	keys := make([]signingKey, len(secrets))
+	for i, secret := range secrets {
+		sum := sha256.Sum256([]byte(secret))
+		keys[i] = signingKey{
+			id:     hex.EncodeToString(sum[:8]),
+			secret: []byte(secret),
+		}
+	}
+
 	return &JWTService{
-		secret:     []byte(secret),
+		keys:       keys,
 		expiration: expiration,
 	}
 }
 
-// GenerateToken creates a new JWT token for a user
-func (s *JWTService) GenerateToken(userID uuid.UUID, username string, role domain.UserRole) (string, error) {
+// SessionID returns the server-side session the token was issued for (the jti claim)
+func (c *TokenClaims) SessionID() (uuid.UUID, error) {
+	return uuid.Parse(c.ID)
+}
+
+// GenerateToken creates a new JWT token for a user's session
+func (s *JWTService) GenerateToken(userID uuid.UUID, username string, role domain.UserRole, sessionID uuid.UUID) (string, error) {
+	return s.generate(userID, username, role, sessionID, "", s.expiration)
+}
+
+// GenerateMediaToken creates a media-scoped token for a user's session. It is
+// revoked along with the session.
+func (s *JWTService) GenerateMediaToken(userID uuid.UUID, username string, role domain.UserRole, sessionID uuid.UUID, expiration time.Duration) (string, error) {
+	return s.generate(userID, username, role, sessionID, ScopeMedia, expiration)
+}
+
+// generate signs a token with the current key
+func (s *JWTService) generate(userID uuid.UUID, username string, role domain.UserRole, sessionID uuid.UUID, scope string, expiration time.Duration) (string, error) {
 	now := time.Now()
 	claims := TokenClaims{
 		UserID:   userID,
 		Username: username,
 		Role:     role,
+		Scope:    scope,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(s.expiration)),
+			ID:        sessionID.String(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiration)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(s.secret)
+	token.Header["kid"] = s.keys[0].id
+	return token.SignedString(s.keys[0].secret)
 }
 
 // ValidateToken validates a JWT token and returns the claims
@@ -62,7 +110,7 @@ func (s *JWTService) ValidateToken(tokenString string) (*TokenClaims, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, ErrInvalidToken
 		}
-		return s.secret, nil
+		return s.verificationKeys(token), nil
 	})
 
 	if err != nil {
@@ -80,6 +128,25 @@ func (s *JWTService) ValidateToken(tokenString string) (*TokenClaims, error) {
 	return claims, nil
 }
 
+// verificationKeys returns the keys to try for a token: the one named by its
+// kid header first, then the rest. Tokens issued before kid was added have none.
+func (s *JWTService) verificationKeys(token *jwt.Token) jwt.VerificationKeySet {
+	kid, _ := token.Header["kid"].(string)
+
+	set := jwt.VerificationKeySet{Keys: make([]jwt.VerificationKey, 0, len(s.keys))}
+	for _, key := range s.keys {
+		if key.id == kid {
+			set.Keys = append(set.Keys, key.secret)
+		}
+	}
+	for _, key := range s.keys {
+		if key.id != kid {
+			set.Keys = append(set.Keys, key.secret)
+		}
+	}
+	return set
+}
+
 // GetExpiration returns the token expiration duration
 func (s *JWTService) GetExpiration() time.Duration {
 	return s.expiration
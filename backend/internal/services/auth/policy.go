@@ -0,0 +1,86 @@
+package auth
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Frequently used passwords, one per line in lowercase. Replace the file with
+// a longer list (e.g. a published top-10k list) to reject more of them.
+//
+//go:embed common_passwords.txt
+var commonPasswordsFile string
+
+var (
+	commonPasswordsOnce sync.Once
+	commonPasswords     map[string]struct{}
+)
+
+// PasswordPolicy describes the rules new passwords must satisfy
+type PasswordPolicy struct {
+	MinLength        int
+	RequireMixedCase bool // At least one upper and one lower case letter
+	RequireDigit     bool
+	RequireSymbol    bool // At least one character that is not a letter or digit
+	RejectCommon     bool // Reject passwords on the embedded common password list
+}
+
+// isCommonPassword reports whether the password is on the embedded list,
+// ignoring case
+func isCommonPassword(password string) bool {
+	commonPasswordsOnce.Do(func() {
+		commonPasswords = make(map[string]struct{})
+		for _, line := range strings.Split(commonPasswordsFile, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				commonPasswords[line] = struct{}{}
+			}
+		}
+	})
+
+	_, ok := commonPasswords[strings.ToLower(password)]
+	return ok
+}
+
+// ValidatePassword checks a new password against the policy. The error
+// message lists every rule the password fails and is safe to show to users.
+func ValidatePassword(policy PasswordPolicy, password string) error {
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, c := range password {
+		switch {
+		case unicode.IsUpper(c):
+			hasUpper = true
+		case unicode.IsLower(c):
+			hasLower = true
+		case unicode.IsDigit(c):
+			hasDigit = true
+		case !unicode.IsLetter(c):
+			hasSymbol = true
+		}
+	}
+
+	var failed []string
+	if len(password) < policy.MinLength {
+		failed = append(failed, fmt.Sprintf("be at least %d characters", policy.MinLength))
+	}
+	if policy.RequireMixedCase && !(hasUpper && hasLower) {
+		failed = append(failed, "contain both upper and lower case letters")
+	}
+	if policy.RequireDigit && !hasDigit {
+		failed = append(failed, "contain a digit")
+	}
+	if policy.RequireSymbol && !hasSymbol {
+		failed = append(failed, "contain a symbol")
+	}
+	if policy.RejectCommon && isCommonPassword(password) {
+		failed = append(failed, "not be a commonly used password")
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+	return errors.New("Password must " + strings.Join(failed, ", "))
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SignStreamURL returns a time-limited URL for a video's progressive stream
+// that works without an auth token, for external players like mpv or VLC.
+// The URL names the user it was issued to, whose bandwidth limits apply.
+//
+// The signature is an HMAC-SHA256 over the short ID, user ID and expiry, so it
+// can't be moved to another video or user, or extended.
+func SignStreamURL(shortID string, userID uuid.UUID, secret string, expiresIn time.Duration) (string, time.Time) {
+	expiresAt := time.Now().Add(expiresIn)
+	expires := expiresAt.Unix()
+
+	query := url.Values{}
+	query.Set("user", userID.String())
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", streamSignature(shortID, userID, expires, secret))
+
+	return fmt.Sprintf("/api/videos/%s/stream?%s", url.PathEscape(shortID), query.Encode()), expiresAt
+}
+
+// ValidateStreamSignature checks a signature made by SignStreamURL with any of
+// the secrets (the current one and, during a rotation, the previous one).
+// Returns true if the signature is valid and not expired
+func ValidateStreamSignature(shortID string, userID uuid.UUID, signature string, expires int64, secrets []string) bool {
+	if time.Now().Unix() > expires {
+		return false
+	}
+
+	for _, secret := range secrets {
+		expected := streamSignature(shortID, userID, expires, secret)
+		if hmac.Equal([]byte(expected), []byte(signature)) {
+			return true
+		}
+	}
+	return false
+}
+
+// streamSignature computes the base64url (unpadded) HMAC for a stream URL
+func streamSignature(shortID string, userID uuid.UUID, expires int64, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "stream:%s:%s:%d", shortID, userID, expires)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SignPlaylistQuery returns the query of a time-limited URL for one of a
+// video's HLS playlists, filename being its path in the video's HLS
+// directory. Master playlists list their rendition playlists with it, so
+// players that can't send a token fetch them without one being copied into
+// the manifest.
+func SignPlaylistQuery(shortID string, filename string, secret string, expiresIn time.Duration) string {
+	expires := time.Now().Add(expiresIn).Unix()
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", playlistSignature(shortID, filename, expires, secret))
+	return query.Encode()
+}
+
+// ValidatePlaylistSignature checks a signature made by SignPlaylistQuery with
+// any of the secrets. Returns true if the signature is valid and not expired
+func ValidatePlaylistSignature(shortID string, filename string, signature string, expires int64, secrets []string) bool {
+	if time.Now().Unix() > expires {
+		return false
+	}
+
+	for _, secret := range secrets {
+		expected := playlistSignature(shortID, filename, expires, secret)
+		if hmac.Equal([]byte(expected), []byte(signature)) {
+			return true
+		}
+	}
+	return false
+}
+
+// playlistSignature computes the base64url (unpadded) HMAC for a playlist URL
+func playlistSignature(shortID string, filename string, expires int64, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "playlist:%s:%s:%d", shortID, filename, expires)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
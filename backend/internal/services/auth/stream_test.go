@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// parseSignedQuery returns the signature and expiry of a signed stream or
+// playlist URL's query
+func parseSignedQuery(t *testing.T, rawQuery string) (string, int64) {
+	t.Helper()
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		t.Fatalf("invalid query %q: %v", rawQuery, err)
+	}
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil {
+		t.Fatalf("invalid expires in %q: %v", rawQuery, err)
+	}
+	return query.Get("signature"), expires
+}
+
+// streamUser is the user stream URLs are signed for
+var streamUser = uuid.MustParse("6f1c2a4e-8d3b-4c5a-9e7f-0a1b2c3d4e5f")
+
+func TestSignStreamURL(t *testing.T) {
+	signed, expiresAt := SignStreamURL("abc123", streamUser, currentSecret, time.Hour)
+
+	path, rawQuery, _ := strings.Cut(signed, "?")
+	if path != "/api/videos/abc123/stream" {
+		t.Errorf("path = %q, want the video's stream", path)
+	}
+	query, _ := url.ParseQuery(rawQuery)
+	if query.Get("user") != streamUser.String() {
+		t.Errorf("user = %q, want %s", query.Get("user"), streamUser)
+	}
+	signature, expires := parseSignedQuery(t, rawQuery)
+	if expires != expiresAt.Unix() {
+		t.Errorf("expires = %d, want %d", expires, expiresAt.Unix())
+	}
+	if !ValidateStreamSignature("abc123", streamUser, signature, expires, []string{currentSecret}) {
+		t.Error("fresh URL doesn't validate")
+	}
+}
+
+func TestValidateStreamSignature(t *testing.T) {
+	tests := []struct {
+		name      string
+		signedBy  string
+		expiresIn time.Duration
+		secrets   []string
+		shortID   string
+		userID    uuid.UUID
+		want      bool
+	}{
+		{"current secret", currentSecret, time.Hour, []string{currentSecret}, "abc123", streamUser, true},
+		{"current secret during rotation", currentSecret, time.Hour, []string{currentSecret, previousSecret}, "abc123", streamUser, true},
+		{"previous secret during rotation", previousSecret, time.Hour, []string{currentSecret, previousSecret}, "abc123", streamUser, true},
+		{"previous secret after rotation", previousSecret, time.Hour, []string{currentSecret}, "abc123", streamUser, false},
+		{"unknown secret", unknownSecret, time.Hour, []string{currentSecret, previousSecret}, "abc123", streamUser, false},
+		{"expired", currentSecret, -time.Minute, []string{currentSecret}, "abc123", streamUser, false},
+		{"expired with previous secret", previousSecret, -time.Minute, []string{currentSecret, previousSecret}, "abc123", streamUser, false},
+		{"other video", currentSecret, time.Hour, []string{currentSecret}, "xyz789", streamUser, false},
+		{"other user", currentSecret, time.Hour, []string{currentSecret}, "abc123", uuid.New(), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signed, _ := SignStreamURL("abc123", streamUser, tt.signedBy, tt.expiresIn)
+			_, rawQuery, _ := strings.Cut(signed, "?")
+			signature, expires := parseSignedQuery(t, rawQuery)
+			if got := ValidateStreamSignature(tt.shortID, tt.userID, signature, expires, tt.secrets); got != tt.want {
+				t.Errorf("ValidateStreamSignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePlaylistSignature(t *testing.T) {
+	tests := []struct {
+		name      string
+		signedBy  string
+		expiresIn time.Duration
+		secrets   []string
+		shortID   string
+		filename  string
+		want      bool
+	}{
+		{"current secret", currentSecret, time.Hour, []string{currentSecret}, "abc123", "720p/index.m3u8", true},
+		{"previous secret during rotation", previousSecret, time.Hour, []string{currentSecret, previousSecret}, "abc123", "720p/index.m3u8", true},
+		{"previous secret after rotation", previousSecret, time.Hour, []string{currentSecret}, "abc123", "720p/index.m3u8", false},
+		{"expired", currentSecret, -time.Minute, []string{currentSecret}, "abc123", "720p/index.m3u8", false},
+		{"other video", currentSecret, time.Hour, []string{currentSecret}, "xyz789", "720p/index.m3u8", false},
+		{"other playlist", currentSecret, time.Hour, []string{currentSecret}, "abc123", "1080p/index.m3u8", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signature, expires := parseSignedQuery(t, SignPlaylistQuery("abc123", "720p/index.m3u8", tt.signedBy, tt.expiresIn))
+			if got := ValidatePlaylistSignature(tt.shortID, tt.filename, signature, expires, tt.secrets); got != tt.want {
+				t.Errorf("ValidatePlaylistSignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -104,19 +104,57 @@ func (p *Processor) ValidateCategoryImage(filePath string) error {
 	return p.ValidateImage(filePath, p.maxCategorySize)
 }
 
-// processImage is a generic image processing function
-func (p *Processor) processImage(inputPath, outputPath string, size int) error {
+// contentTypes maps the extensions processed images are saved with to their
+// MIME types
+var contentTypes = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".webp": "image/webp",
+}
+
+// ContentType returns the MIME type of a stored image from its filename.
+// Images stored before transparency was kept are all JPEG.
+func ContentType(filename string) string {
+	if contentType, ok := contentTypes[strings.ToLower(filepath.Ext(filename))]; ok {
+		return contentType
+	}
+	return "image/jpeg"
+}
+
+// hasTransparency reports whether any pixel of an image isn't fully opaque.
+// JPEGs decode to an always-opaque type, so only PNG, GIF and the like are
+// scanned.
+func hasTransparency(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return !o.Opaque()
+	}
+	return false
+}
+
+// processImage is a generic image processing function. The image is saved to
+// dir as stem plus the extension of the format used: PNG on a transparent
+// canvas if the source has transparency, otherwise JPEG on white. Returns the
+// output filename.
+func (p *Processor) processImage(inputPath, dir, stem string, size int) (string, error) {
 	// Open the source image
 	src, err := imaging.Open(inputPath)
 	if err != nil {
-		return fmt.Errorf("failed to open image: %w", err)
+		return "", fmt.Errorf("failed to open image: %w", err)
 	}
 
 	// Resize to fit within target dimensions while maintaining aspect ratio
 	resized := imaging.Fit(src, size, size, imaging.Lanczos)
 
-	// Create a square canvas with white background
-	canvas := imaging.New(size, size, color.White)
+	// Create a square canvas, keeping the source's transparency
+	transparent := hasTransparency(src)
+	background := color.Color(color.White)
+	ext := ".jpg"
+	if transparent {
+		background = color.Transparent
+		ext = ".png"
+	}
+	canvas := imaging.New(size, size, background)
 
 	// Center the resized image on the canvas
 	offsetX := (size - resized.Bounds().Dx()) / 2
@@ -124,52 +162,43 @@ func (p *Processor) processImage(inputPath, outputPath string, size int) error {
 	canvas = imaging.Paste(canvas, resized, image.Pt(offsetX, offsetY))
 
 	// Ensure output directory exists
-	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	// Save as JPEG
-	err = imaging.Save(canvas, outputPath, imaging.JPEGQuality(p.jpegQuality))
+	// Save as PNG or JPEG, picked by the extension
+	filename := stem + ext
+	if transparent {
+		err = imaging.Save(canvas, filepath.Join(dir, filename))
+	} else {
+		err = imaging.Save(canvas, filepath.Join(dir, filename), imaging.JPEGQuality(p.jpegQuality))
+	}
 	if err != nil {
-		return fmt.Errorf("failed to save processed image: %w", err)
+		return "", fmt.Errorf("failed to save processed image: %w", err)
 	}
 
-	return nil
+	return filename, nil
 }
 
 // ProcessAvatar processes an uploaded avatar image:
 // - Resizes maintaining aspect ratio to fit within target size
 // - Centers on a square canvas
-// - Converts to JPEG format
+// - Converts to PNG if it has transparency, JPEG otherwise
 // Returns the output filename
 func (p *Processor) ProcessAvatar(inputPath string, userID string) (string, error) {
 	// Generate unique filename
 	uniqueSuffix := uuid.New().String()[:8]
-	filename := fmt.Sprintf("%s_%s.jpg", userID, uniqueSuffix)
-	outputPath := filepath.Join(p.avatarPath, filename)
-
-	if err := p.processImage(inputPath, outputPath, p.avatarSize); err != nil {
-		return "", err
-	}
-
-	return filename, nil
+	return p.processImage(inputPath, p.avatarPath, fmt.Sprintf("%s_%s", userID, uniqueSuffix), p.avatarSize)
 }
 
 // ProcessCategoryImage processes an uploaded category image:
 // - Resizes maintaining aspect ratio to fit within target size (400x400)
 // - Centers on a square canvas
-// - Converts to JPEG format
+// - Converts to PNG if it has transparency, JPEG otherwise
 // Returns the output filename
 func (p *Processor) ProcessCategoryImage(inputPath string, categoryID string) (string, error) {
 	// Category images use the category ID as the filename
-	filename := fmt.Sprintf("%s.jpg", categoryID)
-	outputPath := filepath.Join(p.categoryImagePath, filename)
-
-	if err := p.processImage(inputPath, outputPath, p.categoryImageSize); err != nil {
-		return "", err
-	}
-
-	return filename, nil
+	return p.processImage(inputPath, p.categoryImagePath, categoryID, p.categoryImageSize)
 }
 
 // SaveUploadToTemp saves an uploaded file to a temporary location
@@ -239,6 +268,11 @@ func (p *Processor) DeleteCategoryImage(filename string) error {
 	return p.DeleteFile(filePath)
 }
 
+// GetAvatarPath returns the full path to an avatar file
+func (p *Processor) GetAvatarPath(filename string) string {
+	return filepath.Join(p.avatarPath, filename)
+}
+
 // GetCategoryImagePath returns the full path to a category image file
 func (p *Processor) GetCategoryImagePath(filename string) string {
 	return filepath.Join(p.categoryImagePath, filename)
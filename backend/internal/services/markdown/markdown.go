@@ -0,0 +1,94 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Renderer converts user-written text into sanitized HTML
+type Renderer interface {
+	Render(source string) string
+}
+
+// Inline code spans are matched first so their contents are never formatted
+var codeSpanPattern = regexp.MustCompile("`([^`\n]+)`")
+
+// Markdown links with an http(s) target, or bare http(s) URLs
+var linkPattern = regexp.MustCompile(`\[([^\]\n]+)\]\((https?://[^\s)]+)\)|https?://[^\s<>"]+`)
+
+var (
+	boldPattern       = regexp.MustCompile(`\*\*([^*\n]+)\*\*`)
+	italicStarPattern = regexp.MustCompile(`\*([^*\n]+)\*`)
+	italicUndPattern  = regexp.MustCompile(`(^|[^\w])_([^_\n]+)_([^\w]|$)`)
+)
+
+// Punctuation that usually ends a sentence rather than a bare URL
+const urlTrailingPunctuation = ".,;:!?)'"
+
+// CommentRenderer renders a limited markdown subset: links, bold, italic,
+// inline code and line breaks. Everything else, including raw HTML and
+// images, is escaped and shown as text.
+type CommentRenderer struct{}
+
+// NewCommentRenderer creates a new comment markdown renderer
+func NewCommentRenderer() *CommentRenderer {
+	return &CommentRenderer{}
+}
+
+// Render converts source text to sanitized HTML
+func (r *CommentRenderer) Render(source string) string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+
+	var b strings.Builder
+	last := 0
+	for _, m := range codeSpanPattern.FindAllStringSubmatchIndex(source, -1) {
+		renderLinks(&b, source[last:m[0]])
+		b.WriteString("<code>")
+		b.WriteString(html.EscapeString(source[m[2]:m[3]]))
+		b.WriteString("</code>")
+		last = m[1]
+	}
+	renderLinks(&b, source[last:])
+
+	return b.String()
+}
+
+// renderLinks writes text with links turned into anchors
+func renderLinks(b *strings.Builder, text string) {
+	last := 0
+	for _, m := range linkPattern.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(renderInline(text[last:m[0]]))
+
+		var label, href string
+		end := m[1]
+		if m[2] >= 0 {
+			label = renderInline(text[m[2]:m[3]])
+			href = text[m[4]:m[5]]
+		} else {
+			href = strings.TrimRight(text[m[0]:m[1]], urlTrailingPunctuation)
+			end = m[0] + len(href)
+			label = html.EscapeString(href)
+		}
+
+		b.WriteString(`<a href="`)
+		b.WriteString(html.EscapeString(href))
+		b.WriteString(`" rel="nofollow noopener noreferrer" target="_blank">`)
+		b.WriteString(label)
+		b.WriteString("</a>")
+
+		// Trimmed punctuation is rendered as ordinary text
+		b.WriteString(renderInline(text[end:m[1]]))
+		last = m[1]
+	}
+	b.WriteString(renderInline(text[last:]))
+}
+
+// renderInline escapes text and applies emphasis and line breaks
+func renderInline(text string) string {
+	s := html.EscapeString(text)
+	s = boldPattern.ReplaceAllString(s, "<strong>$1</strong>")
+	s = italicStarPattern.ReplaceAllString(s, "<em>$1</em>")
+	s = italicUndPattern.ReplaceAllString(s, "$1<em>$2</em>$3")
+	return strings.ReplaceAll(s, "\n", "<br>\n")
+}
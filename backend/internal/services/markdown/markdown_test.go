@@ -0,0 +1,45 @@
+package markdown
+
+import "testing"
+
+func TestCommentRendererRender(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"plain text", "nice clip", "nice clip"},
+		{"bold", "**wow**", "<strong>wow</strong>"},
+		{"italic", "*so* _good_", "<em>so</em> <em>good</em>"},
+		{"underscores inside words", "snake_case_name", "snake_case_name"},
+		{"inline code is not formatted", "`**not bold** <b>`", "<code>**not bold** &lt;b&gt;</code>"},
+		{"line breaks", "one\r\ntwo", "one<br>\ntwo"},
+		{
+			"markdown link",
+			"[the **docs**](https://example.com/a?b=1&c=2)",
+			`<a href="https://example.com/a?b=1&amp;c=2" rel="nofollow noopener noreferrer" target="_blank">the <strong>docs</strong></a>`,
+		},
+		{
+			"bare URL drops trailing punctuation",
+			"see https://example.com/clip.",
+			`see <a href="https://example.com/clip" rel="nofollow noopener noreferrer" target="_blank">https://example.com/clip</a>.`,
+		},
+		{"raw HTML is escaped", `<script>alert("x")</script>`, "&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;"},
+		{"images are text", "![img](https://example.com/a.png)", `!<a href="https://example.com/a.png" rel="nofollow noopener noreferrer" target="_blank">img</a>`},
+		{"javascript links are text", "[click](javascript:alert(1))", "[click](javascript:alert(1))"},
+		{
+			"quotes can't break out of href",
+			`https://example.com/"onmouseover="x`,
+			`<a href="https://example.com/" rel="nofollow noopener noreferrer" target="_blank">https://example.com/</a>&#34;onmouseover=&#34;x`,
+		},
+	}
+
+	r := NewCommentRenderer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.Render(tt.source); got != tt.want {
+				t.Errorf("Render(%q) =\n%s\nwant\n%s", tt.source, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// throttleChunk is the most written at once by a throttled writer, so large
+// writes are paced smoothly rather than in one burst per second
+const throttleChunk = 32 * 1024
+
+// byteBucket is a token bucket counting bytes. It holds at most one second
+// of tokens, and may go into debt: a writer takes what it needs, then waits
+// until the debt is paid off.
+type byteBucket struct {
+	mu     sync.Mutex
+	rate   float64 // Bytes per second
+	tokens float64
+	last   time.Time
+	users  int // Writers sharing the bucket
+}
+
+// take consumes n bytes and returns how long the caller must wait before
+// writing them
+func (b *byteBucket) take(n int, now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+	b.last = now
+
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// Bandwidth limits the bytes per second written to responses, per
+// connection and per key (e.g. a user ID). A key's limit is shared by all its
+// connections, so opening more doesn't raise it.
+type Bandwidth struct {
+	mu   sync.Mutex
+	keys map[string]*byteBucket
+}
+
+// NewBandwidth creates a new bandwidth limiter
+func NewBandwidth() *Bandwidth {
+	return &Bandwidth{keys: make(map[string]*byteBucket)}
+}
+
+// Writer wraps w so writes are paced to at most perConnection bytes per
+// second, and to perKey bytes per second across all of key's writers. A
+// limit of 0 or less, or an empty key, means unlimited. A key's limit takes
+// the value given by its latest writer, so config changes apply as new
+// requests arrive. The returned release function must be called once the
+// response is done.
+func (b *Bandwidth) Writer(ctx context.Context, w http.ResponseWriter, key string, perConnection, perKey int64) (http.ResponseWriter, func()) {
+	now := time.Now()
+	var buckets []*byteBucket
+	if perConnection > 0 {
+		buckets = append(buckets, &byteBucket{rate: float64(perConnection), tokens: float64(perConnection), last: now})
+	}
+
+	release := func() {}
+	if key != "" && perKey > 0 {
+		b.mu.Lock()
+		bucket, ok := b.keys[key]
+		if !ok {
+			bucket = &byteBucket{tokens: float64(perKey), last: now}
+			b.keys[key] = bucket
+		}
+		bucket.mu.Lock()
+		bucket.rate = float64(perKey)
+		bucket.users++
+		bucket.mu.Unlock()
+		b.mu.Unlock()
+
+		buckets = append(buckets, bucket)
+		release = func() { b.release(key, bucket) }
+	}
+
+	if len(buckets) == 0 {
+		return w, release
+	}
+	return &throttledWriter{ResponseWriter: w, ctx: ctx, buckets: buckets}, release
+}
+
+// release drops a writer from a key's bucket, forgetting the bucket once
+// no writer uses it
+func (b *Bandwidth) release(key string, bucket *byteBucket) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bucket.mu.Lock()
+	bucket.users--
+	unused := bucket.users == 0
+	bucket.mu.Unlock()
+
+	if unused && b.keys[key] == bucket {
+		delete(b.keys, key)
+	}
+}
+
+// throttledWriter paces writes to its buckets. It hides the underlying
+// writer's ReadFrom, so sendfile can't bypass the limit; Range responses and
+// flushing work as usual.
+type throttledWriter struct {
+	http.ResponseWriter
+	ctx     context.Context
+	buckets []*byteBucket
+}
+
+// Write writes p in chunks, waiting before each until every bucket allows it
+func (w *throttledWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p[:min(len(p), throttleChunk)]
+
+		var wait time.Duration
+		now := time.Now()
+		for _, b := range w.buckets {
+			wait = max(wait, b.take(len(chunk), now))
+		}
+		if wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-w.ctx.Done():
+				timer.Stop()
+				return written, w.ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		n, err := w.ResponseWriter.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// Flush flushes the underlying writer if it supports it
+func (w *throttledWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController
+func (w *throttledWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,140 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit allows at most Max events per Period
+type Limit struct {
+	Max    int
+	Period time.Duration
+}
+
+// Limiter decides whether an event for a key is allowed under a set of limits.
+// When not allowed, retryAfter reports how long until the next event would pass.
+// Refund gives back what an allowed event took, for events that failed before
+// doing their work.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limits ...Limit) (allowed bool, retryAfter time.Duration, err error)
+	Refund(ctx context.Context, key string, limits ...Limit) error
+}
+
+// Idle buckets are swept at most this often
+const sweepInterval = 10 * time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	limit  Limit
+}
+
+// refill tops up the bucket for the time elapsed since the last update
+func (b *bucket) refill(now time.Time) {
+	rate := float64(b.limit.Max) / b.limit.Period.Seconds()
+	b.tokens = math.Min(float64(b.limit.Max), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+}
+
+// MemoryLimiter is an in-process token bucket limiter. Each limit gets its own
+// bucket per key; an event is only allowed when every bucket has a token.
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewMemoryLimiter creates a new in-memory limiter
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow consumes one token from each of the key's buckets if all have one.
+// Limits with a non-positive Max or Period are ignored.
+func (l *MemoryLimiter) Allow(ctx context.Context, key string, limits ...Limit) (bool, time.Duration, error) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	active := make([]*bucket, 0, len(limits))
+	var retryAfter time.Duration
+	for _, limit := range limits {
+		if limit.Max <= 0 || limit.Period <= 0 {
+			continue
+		}
+
+		b, ok := l.buckets[bucketKey(key, limit)]
+		if !ok {
+			b = &bucket{tokens: float64(limit.Max), last: now, limit: limit}
+			l.buckets[bucketKey(key, limit)] = b
+		}
+		b.refill(now)
+
+		if b.tokens < 1 {
+			rate := float64(limit.Max) / limit.Period.Seconds()
+			wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+			if wait > retryAfter {
+				retryAfter = wait
+			}
+		}
+		active = append(active, b)
+	}
+
+	if retryAfter > 0 {
+		return false, retryAfter, nil
+	}
+
+	for _, b := range active {
+		b.tokens--
+	}
+	return true, 0, nil
+}
+
+// Refund returns one token to each of the key's buckets, up to their limit
+func (l *MemoryLimiter) Refund(ctx context.Context, key string, limits ...Limit) error {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, limit := range limits {
+		// Missing buckets were swept when full
+		b, ok := l.buckets[bucketKey(key, limit)]
+		if !ok {
+			continue
+		}
+		b.refill(now)
+		b.tokens = math.Min(float64(limit.Max), b.tokens+1)
+	}
+	return nil
+}
+
+// bucketKey names a key's bucket for a limit. Keying on the limit itself means
+// a config change starts a fresh bucket.
+func bucketKey(key string, limit Limit) string {
+	return fmt.Sprintf("%s:%d/%s", key, limit.Max, limit.Period)
+}
+
+// sweep drops buckets that have refilled completely, since they hold no state
+func (l *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for k, b := range l.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.limit.Max) {
+			delete(l.buckets, k)
+		}
+	}
+}
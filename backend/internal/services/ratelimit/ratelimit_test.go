@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryLimiterRefund(t *testing.T) {
+	ctx := context.Background()
+	daily := Limit{Max: 1, Period: 24 * time.Hour}
+
+	tests := []struct {
+		name    string
+		refunds int
+		want    []bool // Whether each Allow after the first, refunded one passes
+	}{
+		{"no refund", 0, []bool{false}},
+		{"refund", 1, []bool{true, false}},
+		{"refunds are capped at the limit", 3, []bool{true, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewMemoryLimiter()
+			if allowed, _, _ := l.Allow(ctx, "export:user", daily); !allowed {
+				t.Fatal("first event not allowed")
+			}
+			for range tt.refunds {
+				if err := l.Refund(ctx, "export:user", daily); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for i, want := range tt.want {
+				if allowed, _, _ := l.Allow(ctx, "export:user", daily); allowed != want {
+					t.Errorf("Allow() %d = %v, want %v", i, allowed, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryLimiterRefundOnlyAffectsKey(t *testing.T) {
+	ctx := context.Background()
+	daily := Limit{Max: 1, Period: 24 * time.Hour}
+
+	l := NewMemoryLimiter()
+	l.Allow(ctx, "export:a", daily)
+	l.Allow(ctx, "export:b", daily)
+	l.Refund(ctx, "export:a", daily)
+
+	if allowed, _, _ := l.Allow(ctx, "export:b", daily); allowed {
+		t.Error("refunding one key let another through")
+	}
+}
@@ -0,0 +1,136 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/clipset/clipset-go/internal/db/sqlc"
+)
+
+// Store tracks issued login sessions. Validation results are cached for a
+// short time so the auth middleware doesn't query the database on every
+// request; revocations made through the Store take effect immediately, while
+// revocations from another process take effect within the cache TTL.
+type Store struct {
+	queries *sqlc.Queries
+	ttl     time.Duration
+
+	mu        sync.Mutex
+	cache     map[uuid.UUID]cacheEntry
+	lastSweep time.Time
+}
+
+type cacheEntry struct {
+	userID    uuid.UUID
+	active    bool
+	checkedAt time.Time
+}
+
+// NewStore creates a new session store
+func NewStore(queries *sqlc.Queries, ttl time.Duration) *Store {
+	return &Store{
+		queries:   queries,
+		ttl:       ttl,
+		cache:     make(map[uuid.UUID]cacheEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+// Create records a new session for a login
+func (s *Store) Create(ctx context.Context, userID uuid.UUID, userAgent, ipAddress string, expiresAt time.Time) (sqlc.UserSession, error) {
+	params := sqlc.CreateUserSessionParams{
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}
+	if userAgent != "" {
+		params.UserAgent = &userAgent
+	}
+	if ipAddress != "" {
+		params.IpAddress = &ipAddress
+	}
+	return s.queries.CreateUserSession(ctx, params)
+}
+
+// IsActive reports whether a session belongs to the user and is neither
+// revoked nor expired. Cache misses also record the session as last seen now.
+func (s *Store) IsActive(ctx context.Context, sessionID, userID uuid.UUID) (bool, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	s.sweep(now)
+	entry, ok := s.cache[sessionID]
+	s.mu.Unlock()
+
+	if ok && now.Sub(entry.checkedAt) < s.ttl {
+		return entry.active && entry.userID == userID, nil
+	}
+
+	owner, err := s.queries.TouchUserSession(ctx, sessionID)
+	active := err == nil
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return false, err
+	}
+
+	s.mu.Lock()
+	s.cache[sessionID] = cacheEntry{userID: owner, active: active, checkedAt: now}
+	s.mu.Unlock()
+
+	return active && owner == userID, nil
+}
+
+// Revoke revokes one of a user's sessions. Returns pgx.ErrNoRows if the
+// session doesn't exist, isn't the user's, or is already revoked.
+func (s *Store) Revoke(ctx context.Context, sessionID, userID uuid.UUID) error {
+	if _, err := s.queries.RevokeUserSession(ctx, sqlc.RevokeUserSessionParams{
+		ID:     sessionID,
+		UserID: userID,
+	}); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.cache, sessionID)
+	s.mu.Unlock()
+	return nil
+}
+
+// RevokeAll revokes every session of a user
+func (s *Store) RevokeAll(ctx context.Context, userID uuid.UUID) error {
+	if err := s.queries.RevokeUserSessions(ctx, userID); err != nil {
+		return err
+	}
+
+	s.Forget(userID)
+	return nil
+}
+
+// Forget drops cached results for a user's sessions. Use it after revoking
+// sessions with queries outside the Store, e.g. inside a transaction.
+func (s *Store) Forget(userID uuid.UUID) {
+	s.mu.Lock()
+	for id, entry := range s.cache {
+		if entry.userID == userID {
+			delete(s.cache, id)
+		}
+	}
+	s.mu.Unlock()
+}
+
+// sweep drops stale cache entries, at most once per TTL. Callers hold s.mu.
+func (s *Store) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.ttl {
+		return
+	}
+	s.lastSweep = now
+
+	for id, entry := range s.cache {
+		if now.Sub(entry.checkedAt) >= s.ttl {
+			delete(s.cache, id)
+		}
+	}
+}
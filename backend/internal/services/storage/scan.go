@@ -0,0 +1,314 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kinds of scanned files
+const (
+	ScanKindVideo     = "video"
+	ScanKindHLS       = "hls"
+	ScanKindThumbnail = "thumbnail"
+	ScanKindTemp      = "temp"
+	ScanKindChunks    = "chunks"
+)
+
+var (
+	// ErrScanRunning is returned when a scan is already in progress
+	ErrScanRunning = errors.New("a storage scan is already running")
+	// ErrNoScanReport is returned when no scan has finished yet
+	ErrNoScanReport = errors.New("no storage scan report available")
+)
+
+// ScanConfig holds the directories a scan walks
+type ScanConfig struct {
+	VideoPath       string
+	ThumbnailPath   string
+	TempPath        string
+	ChunksPath      string
+	ChunkSessionTTL time.Duration // Chunk sessions older than this are orphaned
+	MinAge          time.Duration // Younger files are skipped, they may belong to an upload in progress
+}
+
+// VideoFiles are the files a video row references
+type VideoFiles struct {
+	VideoID           string
+	Filename          string
+	ThumbnailFilename *string
+	StoragePath       *string
+	Completed         bool // Only completed videos are expected to have output files
+}
+
+// OrphanedFile is a file or directory no video references
+type OrphanedFile struct {
+	Path  string
+	Kind  string
+	Bytes int64
+	IsDir bool
+}
+
+// MissingFile is a file a video references that doesn't exist
+type MissingFile struct {
+	VideoID string
+	Path    string
+	Kind    string
+}
+
+// ScanReport is the result of diffing the storage directories against the videos table
+type ScanReport struct {
+	Orphans       []OrphanedFile
+	Missing       []MissingFile
+	OrphanedBytes int64
+	Errors        []string // Directories that couldn't be read
+	StartedAt     time.Time
+	FinishedAt    time.Time
+}
+
+// CleanupResult describes the orphans removed (or, for a dry run, that would be)
+type CleanupResult struct {
+	Removed      []OrphanedFile
+	RemovedBytes int64
+	Errors       []string
+}
+
+var (
+	scanMu      sync.Mutex
+	scanRunning bool
+	latestScan  *ScanReport
+)
+
+// ScanFiles diffs the storage directories against the videos' files
+func ScanFiles(cfg ScanConfig, videos []VideoFiles) ScanReport {
+	report := ScanReport{
+		Orphans:   []OrphanedFile{},
+		Missing:   []MissingFile{},
+		Errors:    []string{},
+		StartedAt: time.Now(),
+	}
+
+	// Names each directory's entries are matched against
+	videoStems := make(map[string]bool)
+	tempFiles := make(map[string]bool)
+	thumbnails := make(map[string]bool)
+	// Directories in the video directory holding videos moved there with
+	// `clipset storage migrate`
+	storageRoots := make(map[string]bool)
+
+	for _, v := range videos {
+		base := cfg.VideoPath
+		if v.StoragePath != nil && *v.StoragePath != "" {
+			base = *v.StoragePath
+		}
+
+		if filepath.Clean(base) == filepath.Clean(cfg.VideoPath) {
+			videoStems[GetFilenameWithoutExt(v.Filename)] = true
+		} else if rel, err := RelativeToVideoPath(cfg.VideoPath, base); err == nil {
+			storageRoots[strings.SplitN(rel, "/", 2)[0]] = true
+		}
+		if !v.Completed {
+			// Not processed yet, the upload is still in the temp directory
+			tempFiles[v.Filename] = true
+		}
+		if v.ThumbnailFilename != nil && *v.ThumbnailFilename != "" {
+			thumbnails[*v.ThumbnailFilename] = true
+			thumbnails[WebPThumbnailName(*v.ThumbnailFilename)] = true
+		}
+
+		if !v.Completed {
+			continue
+		}
+		if strings.EqualFold(filepath.Ext(v.Filename), ".mp4") {
+			if path := ResolvePath(base, v.Filename); !FileExists(path) {
+				report.Missing = append(report.Missing, MissingFile{VideoID: v.VideoID, Path: path, Kind: ScanKindVideo})
+			}
+		} else if path := filepath.Join(ResolvePath(base, v.Filename), "master.m3u8"); !FileExists(path) {
+			report.Missing = append(report.Missing, MissingFile{VideoID: v.VideoID, Path: path, Kind: ScanKindHLS})
+		}
+		if v.ThumbnailFilename != nil && *v.ThumbnailFilename != "" {
+			if path := ResolvePath(cfg.ThumbnailPath, *v.ThumbnailFilename); !FileExists(path) {
+				report.Missing = append(report.Missing, MissingFile{VideoID: v.VideoID, Path: path, Kind: ScanKindThumbnail})
+			}
+		}
+	}
+
+	cutoff := report.StartedAt.Add(-cfg.MinAge)
+	chunkCutoff := report.StartedAt.Add(-cfg.ChunkSessionTTL)
+
+	report.scanShardedDirectory(cfg.VideoPath, func(name string, isDir bool, modTime time.Time) (string, bool) {
+		if isDir && storageRoots[name] {
+			return "", false
+		}
+		kind := ScanKindVideo
+		if isDir {
+			kind = ScanKindHLS
+		}
+		return kind, !videoStems[GetFilenameWithoutExt(name)] && modTime.Before(cutoff)
+	})
+	report.scanShardedDirectory(cfg.ThumbnailPath, func(name string, isDir bool, modTime time.Time) (string, bool) {
+		return ScanKindThumbnail, !thumbnails[name] && modTime.Before(cutoff)
+	})
+	report.scanDirectory(cfg.TempPath, func(name string, isDir bool, modTime time.Time) (string, bool) {
+		return ScanKindTemp, !tempFiles[name] && modTime.Before(cutoff)
+	})
+	report.scanDirectory(cfg.ChunksPath, func(name string, isDir bool, modTime time.Time) (string, bool) {
+		// Sessions aren't in the database; expired ones the cleanup job missed are orphans
+		return ScanKindChunks, modTime.Before(chunkCutoff)
+	})
+
+	report.FinishedAt = time.Now()
+	return report
+}
+
+// scanDirectory checks each entry of dir, adding those isOrphan reports as orphaned
+func (r *ScanReport) scanDirectory(dir string, isOrphan func(name string, isDir bool, modTime time.Time) (string, bool)) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			r.Errors = append(r.Errors, fmt.Sprintf("%s: %v", dir, err))
+		}
+		return
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		kind, orphaned := isOrphan(entry.Name(), entry.IsDir(), info.ModTime())
+		if !orphaned {
+			continue
+		}
+
+		orphan := OrphanedFile{
+			Path:  filepath.Join(dir, entry.Name()),
+			Kind:  kind,
+			Bytes: info.Size(),
+			IsDir: entry.IsDir(),
+		}
+		if orphan.IsDir {
+			usage := measureDirectory(UsageDirectory{Path: orphan.Path})
+			orphan.Bytes = usage.Bytes
+		}
+		r.Orphans = append(r.Orphans, orphan)
+		r.OrphanedBytes += orphan.Bytes
+	}
+}
+
+// scanShardedDirectory checks the entries of dir and of the shard directories
+// under it. Shard directories themselves are never orphans, even when empty.
+func (r *ScanReport) scanShardedDirectory(dir string, isOrphan func(name string, isDir bool, modTime time.Time) (string, bool)) {
+	r.scanDirectory(dir, func(name string, isDir bool, modTime time.Time) (string, bool) {
+		if isDir && IsShardDirName(name) {
+			return "", false
+		}
+		return isOrphan(name, isDir, modTime)
+	})
+
+	for _, outer := range shardSubdirectories(dir) {
+		for _, inner := range shardSubdirectories(outer) {
+			r.scanDirectory(inner, isOrphan)
+		}
+	}
+}
+
+// shardSubdirectories returns the paths of the shard directories directly in dir
+func shardSubdirectories(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() && IsShardDirName(entry.Name()) {
+			dirs = append(dirs, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return dirs
+}
+
+// BeginScan marks a scan as running. Returns ErrScanRunning if one already is.
+func BeginScan() error {
+	scanMu.Lock()
+	defer scanMu.Unlock()
+	if scanRunning {
+		return ErrScanRunning
+	}
+	scanRunning = true
+	return nil
+}
+
+// FinishScan publishes a scan report and marks the scan as finished. A nil
+// report keeps the previous one, e.g. when the scan failed.
+func FinishScan(report *ScanReport) {
+	scanMu.Lock()
+	defer scanMu.Unlock()
+	scanRunning = false
+	if report != nil {
+		latestScan = report
+	}
+}
+
+// LatestScan returns the most recent scan report and whether a new scan is running
+func LatestScan() (report ScanReport, ok bool, running bool) {
+	scanMu.Lock()
+	defer scanMu.Unlock()
+	if latestScan == nil {
+		return ScanReport{}, false, scanRunning
+	}
+	return *latestScan, true, scanRunning
+}
+
+// CleanupOrphans deletes the orphans listed in the latest scan report.
+// With dryRun set, nothing is deleted and the result lists what would be.
+// Removed orphans are dropped from the report.
+func CleanupOrphans(dryRun bool) (CleanupResult, error) {
+	scanMu.Lock()
+	defer scanMu.Unlock()
+	if scanRunning {
+		return CleanupResult{}, ErrScanRunning
+	}
+	if latestScan == nil {
+		return CleanupResult{}, ErrNoScanReport
+	}
+
+	result := CleanupResult{
+		Removed: []OrphanedFile{},
+		Errors:  []string{},
+	}
+	remaining := []OrphanedFile{}
+
+	for _, orphan := range latestScan.Orphans {
+		if dryRun {
+			result.Removed = append(result.Removed, orphan)
+			result.RemovedBytes += orphan.Bytes
+			continue
+		}
+
+		var err error
+		if orphan.IsDir {
+			err = os.RemoveAll(orphan.Path)
+		} else {
+			err = os.Remove(orphan.Path)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", orphan.Path, err))
+			remaining = append(remaining, orphan)
+			continue
+		}
+		result.Removed = append(result.Removed, orphan)
+		result.RemovedBytes += orphan.Bytes
+	}
+
+	if !dryRun {
+		latestScan.Orphans = remaining
+		latestScan.OrphanedBytes -= result.RemovedBytes
+	}
+	return result, nil
+}
@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Video files, HLS directories and thumbnails are stored under two levels of
+// shard directories named after the first four characters of their name
+// (ab/cd/abcd1234_20240101120000.mp4), so no directory grows to tens of
+// thousands of entries. Files stored before sharding stay directly in the
+// storage directory until moved with `clipset storage reshard`; lookups try
+// the sharded location first and fall back to the flat one.
+
+// shardPrefixLength is how many leading characters of a name pick its shard
+const shardPrefixLength = 4
+
+// ShardDir returns the shard directory of a file name relative to the storage
+// directory, e.g. "ab/cd" for "abcd1234.mp4". Names shorter than four
+// characters or not starting with four ASCII letters or digits aren't
+// sharded; for those it returns "".
+func ShardDir(name string) string {
+	if len(name) < shardPrefixLength {
+		return ""
+	}
+	for i := 0; i < shardPrefixLength; i++ {
+		if !isShardChar(name[i]) {
+			return ""
+		}
+	}
+	return path.Join(name[0:2], name[2:4])
+}
+
+// isShardChar reports whether c may appear in a shard directory name
+func isShardChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// IsShardDirName reports whether a directory name is one level of a shard
+// directory. Video and HLS directory names are always longer.
+func IsShardDirName(name string) bool {
+	return len(name) == 2 && isShardChar(name[0]) && isShardChar(name[1])
+}
+
+// ShardedPath returns where a file or directory named name is stored under
+// base in the sharded layout
+func ShardedPath(base, name string) string {
+	return filepath.Join(base, filepath.FromSlash(ShardDir(name)), name)
+}
+
+// ResolvePath returns the path of a file or directory under base: the sharded
+// location if it exists, else the flat legacy location if that does, else the
+// sharded one, where new files go
+func ResolvePath(base, name string) string {
+	sharded := ShardedPath(base, name)
+	if FileExists(sharded) {
+		return sharded
+	}
+	if legacy := filepath.Join(base, name); FileExists(legacy) {
+		return legacy
+	}
+	return sharded
+}
+
+// ResolveRelativePath is ResolvePath relative to base, with forward slashes,
+// for building URLs
+func ResolveRelativePath(base, name string) string {
+	sharded := path.Join(ShardDir(name), name)
+	if !FileExists(filepath.Join(base, filepath.FromSlash(sharded))) && FileExists(filepath.Join(base, name)) {
+		return name
+	}
+	return sharded
+}
+
+// MoveToShard moves a file or directory stored flat in base to its sharded
+// location, reporting whether it did. Names that aren't sharded, or that
+// aren't stored flat, are left alone. The move is a rename within base, so
+// it's atomic: an interrupted reshard never leaves a partial copy.
+func MoveToShard(base, name string) (bool, error) {
+	if ShardDir(name) == "" {
+		return false, nil
+	}
+	legacy := filepath.Join(base, name)
+	if !FileExists(legacy) {
+		return false, nil
+	}
+
+	sharded := ShardedPath(base, name)
+	if FileExists(sharded) {
+		return false, fmt.Errorf("%s is stored both flat and sharded", name)
+	}
+	if err := os.MkdirAll(filepath.Dir(sharded), 0755); err != nil {
+		return false, fmt.Errorf("failed to create shard directory: %w", err)
+	}
+	if err := os.Rename(legacy, sharded); err != nil {
+		return false, fmt.Errorf("failed to move %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// ErrOutsideVideoPath is returned for paths outside the video storage
+// directory. Nginx and the signed /hls/ URLs only reach files inside it.
+var ErrOutsideVideoPath = errors.New("path is outside the video storage directory")
+
+// RelativeToVideoPath returns p relative to the video storage directory
+// videoPath, with forward slashes, for signed /hls/ URLs and
+// X-Accel-Redirect. It's "." for videoPath itself.
+func RelativeToVideoPath(videoPath, p string) (string, error) {
+	base, err := filepath.Abs(videoPath)
+	if err != nil {
+		return "", err
+	}
+	target, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(base, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", ErrOutsideVideoPath
+	}
+	return filepath.ToSlash(rel), nil
+}
@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInsufficientStorage means a write would leave less free space than the
+// configured minimum
+var ErrInsufficientStorage = errors.New("insufficient storage")
+
+// FreeSpace returns the free space available to unprivileged users and the
+// total size of the filesystem containing path
+func FreeSpace(path string) (free uint64, capacity uint64, err error) {
+	return filesystemSpace(path)
+}
+
+// CheckFreeSpace returns ErrInsufficientStorage if writing needed bytes under
+// path would leave less than minFree bytes free on its filesystem. Any other
+// error means the free space couldn't be read, e.g. on an unsupported
+// platform.
+func CheckFreeSpace(path string, needed, minFree int64) error {
+	free, _, err := filesystemSpace(path)
+	if err != nil {
+		return fmt.Errorf("failed to read free space of %s: %w", path, err)
+	}
+
+	if needed < 0 {
+		needed = 0
+	}
+	if int64(free)-needed < minFree {
+		return fmt.Errorf("%w: %d bytes free on %s, need %d plus %d to keep free",
+			ErrInsufficientStorage, free, path, needed, minFree)
+	}
+	return nil
+}
@@ -2,12 +2,19 @@
 package storage
 
 import (
+	"bufio"
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"log"
+	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
@@ -32,6 +39,16 @@ var videoSignatures = []struct {
 	{"flv", 0, []byte("FLV")},
 }
 
+// sniffLen is the number of leading bytes inspected by content sniffing (same as http.DetectContentType)
+const sniffLen = 512
+
+var (
+	// ErrEmptyFile is returned when an upload contains no data
+	ErrEmptyFile = errors.New("file is empty")
+	// ErrNotVideo is returned when sniffed content is clearly not a video container
+	ErrNotVideo = errors.New("file does not appear to be a valid video")
+)
+
 // StorageConfig holds storage configuration
 type StorageConfig struct {
 	VideoPath     string
@@ -87,28 +104,31 @@ func GetFilenameWithoutExt(filename string) string {
 	return strings.TrimSuffix(filename, filepath.Ext(filename))
 }
 
-// SaveUploadedFile saves an uploaded file to the specified path
-func (s *Storage) SaveUploadedFile(src io.Reader, destPath string) (int64, error) {
+// SaveUploadedFile saves an uploaded file to the specified path, returning its
+// size and hex-encoded SHA-256. The hash is taken while writing, so large
+// uploads aren't read back.
+func (s *Storage) SaveUploadedFile(src io.Reader, destPath string) (int64, string, error) {
 	// Ensure parent directory exists
 	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
-		return 0, fmt.Errorf("failed to create directory: %w", err)
+		return 0, "", fmt.Errorf("failed to create directory: %w", err)
 	}
 
 	dest, err := os.Create(destPath)
 	if err != nil {
-		return 0, fmt.Errorf("failed to create file: %w", err)
+		return 0, "", fmt.Errorf("failed to create file: %w", err)
 	}
 	defer dest.Close()
 
-	written, err := io.Copy(dest, src)
+	hasher := sha256.New()
+	written, err := io.Copy(io.MultiWriter(dest, hasher), src)
 	if err != nil {
 		// Clean up partial file
 		os.Remove(destPath)
-		return 0, fmt.Errorf("failed to write file: %w", err)
+		return 0, "", fmt.Errorf("failed to write file: %w", err)
 	}
 
 	log.Printf("Saved file to %s (%d bytes)", destPath, written)
-	return written, nil
+	return written, hex.EncodeToString(hasher.Sum(nil)), nil
 }
 
 // TempPath returns the full path for a temp file
@@ -116,14 +136,26 @@ func (s *Storage) TempPath(filename string) string {
 	return filepath.Join(s.config.TempPath, filename)
 }
 
-// VideoPath returns the full path for a video file
+// VideoPath returns the full path for a video file, sharded or legacy
 func (s *Storage) VideoPath(filename string) string {
-	return filepath.Join(s.config.VideoPath, filename)
+	return ResolvePath(s.config.VideoPath, filename)
 }
 
-// ThumbnailPath returns the full path for a thumbnail file
+// ThumbnailPath returns the full path for a thumbnail file, sharded or legacy
 func (s *Storage) ThumbnailPath(filename string) string {
-	return filepath.Join(s.config.ThumbnailPath, filename)
+	return ResolvePath(s.config.ThumbnailPath, filename)
+}
+
+// WebPThumbnailPath returns the full path for the WebP variant of a
+// thumbnail, sharded or legacy
+func (s *Storage) WebPThumbnailPath(filename string) string {
+	return ResolvePath(s.config.ThumbnailPath, WebPThumbnailName(filename))
+}
+
+// WebPThumbnailName returns the name of a JPEG thumbnail's WebP variant,
+// stored next to it. Thumbnails from before WebP variants have none.
+func WebPThumbnailName(filename string) string {
+	return GetFilenameWithoutExt(filename) + ".webp"
 }
 
 // ChunksPath returns the full path for chunk storage
@@ -190,6 +222,100 @@ func GetFileSize(path string) (int64, error) {
 	return info.Size(), nil
 }
 
+// ComputeSHA256 returns the hex-encoded SHA-256 of a file's contents
+func ComputeSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", fmt.Errorf("failed to hash file: %w", err)
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// ComputeOutputSHA256 returns the hex-encoded SHA-256 of a processed video's
+// output in base. A progressive MP4 is hashed as a file. An HLS directory is
+// hashed as a tree: each file's relative path and SHA-256, in path order, so
+// a changed, missing or extra file all change the hash.
+func ComputeOutputSHA256(base, filename string) (string, error) {
+	if strings.EqualFold(filepath.Ext(filename), ".mp4") {
+		return ComputeSHA256(ResolvePath(base, filename))
+	}
+
+	hlsDir := ResolvePath(base, GetHLSDirectoryName(filename))
+	if _, err := os.Stat(hlsDir); err != nil {
+		return "", fmt.Errorf("failed to open HLS directory: %w", err)
+	}
+
+	tree := sha256.New()
+	err := filepath.WalkDir(hlsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(hlsDir, path)
+		if err != nil {
+			return err
+		}
+		sum, err := ComputeSHA256(path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(tree, "%s %s\n", sum, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash HLS directory: %w", err)
+	}
+
+	return hex.EncodeToString(tree.Sum(nil)), nil
+}
+
+// SniffVideoReader inspects the first bytes of src without consuming them and rejects
+// empty input (ErrEmptyFile) or content that isn't a known video container (ErrNotVideo).
+// The returned reader yields the complete stream, including the sniffed bytes.
+func SniffVideoReader(src io.Reader) (io.Reader, error) {
+	br := bufio.NewReaderSize(src, sniffLen)
+	header, err := br.Peek(sniffLen)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read file header: %w", err)
+	}
+
+	if len(header) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	// Reject anything the standard library positively identifies as non-video
+	// (text, images, archives...). Containers it doesn't know come back as octet-stream.
+	contentType := http.DetectContentType(header)
+	if !strings.HasPrefix(contentType, "video/") && contentType != "application/octet-stream" {
+		return nil, ErrNotVideo
+	}
+
+	// Require a known container signature
+	if !hasVideoSignature(header) {
+		return nil, ErrNotVideo
+	}
+
+	return br, nil
+}
+
+// hasVideoSignature checks a file header against known video magic bytes
+func hasVideoSignature(header []byte) bool {
+	for _, sig := range videoSignatures {
+		if sig.offset+len(sig.pattern) <= len(header) {
+			if bytes.Equal(header[sig.offset:sig.offset+len(sig.pattern)], sig.pattern) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // ValidateVideoFile checks if a file is a valid video by examining magic bytes
 func ValidateVideoFile(path string) error {
 	file, err := os.Open(path)
@@ -209,12 +335,8 @@ func ValidateVideoFile(path string) error {
 	}
 
 	// Check against known video signatures
-	for _, sig := range videoSignatures {
-		if sig.offset+len(sig.pattern) <= n {
-			if bytes.Equal(header[sig.offset:sig.offset+len(sig.pattern)], sig.pattern) {
-				return nil // Valid video signature found
-			}
-		}
+	if hasVideoSignature(header[:n]) {
+		return nil
 	}
 
 	return fmt.Errorf("file does not appear to be a valid video (unrecognized format)")
@@ -227,12 +349,16 @@ func GetHLSDirectoryName(filename string) string {
 
 // IsHLSVideo checks if a video has HLS files
 func (s *Storage) IsHLSVideo(filename string) bool {
-	hlsDir := filepath.Join(s.config.VideoPath, GetHLSDirectoryName(filename))
+	hlsDir := ResolvePath(s.config.VideoPath, GetHLSDirectoryName(filename))
 	manifestPath := filepath.Join(hlsDir, "master.m3u8")
 	return FileExists(manifestPath)
 }
 
-// DeleteVideoFiles deletes all files associated with a video (HLS dir, MP4, thumbnail)
+// DeleteVideoFiles deletes all files associated with a video (HLS dir, MP4,
+// thumbnail and its WebP variant).
+// The HLS directory is removed whole, whether its segments are MPEG-TS or fMP4;
+// for a progressive video, it holds the lower quality files.
+// Each is looked for in the sharded location first, then the legacy flat one.
 func (s *Storage) DeleteVideoFiles(filename string, thumbnailFilename *string, storagePath *string) error {
 	var errs []string
 
@@ -243,24 +369,23 @@ func (s *Storage) DeleteVideoFiles(filename string, thumbnailFilename *string, s
 	}
 
 	// Try to delete HLS directory
-	hlsDir := filepath.Join(videoBase, GetHLSDirectoryName(filename))
+	hlsDir := ResolvePath(videoBase, GetHLSDirectoryName(filename))
 	if err := s.DeleteDirectory(hlsDir); err != nil {
 		errs = append(errs, fmt.Sprintf("HLS dir: %v", err))
 	}
 
 	// Try to delete progressive MP4
-	mp4Path := filepath.Join(videoBase, filename)
-	if !strings.HasSuffix(mp4Path, ".mp4") {
-		mp4Path = mp4Path + ".mp4"
+	mp4Name := filename
+	if !strings.HasSuffix(mp4Name, ".mp4") {
+		mp4Name = mp4Name + ".mp4"
 	}
-	if err := s.DeleteFile(mp4Path); err != nil {
+	if err := s.DeleteFile(ResolvePath(videoBase, mp4Name)); err != nil {
 		errs = append(errs, fmt.Sprintf("MP4: %v", err))
 	}
 
 	// Also try without adding extension (for files that already have it)
-	mp4PathOriginal := filepath.Join(videoBase, filename)
-	if mp4PathOriginal != mp4Path {
-		s.DeleteFile(mp4PathOriginal) // Ignore error
+	if mp4Name != filename {
+		s.DeleteFile(ResolvePath(videoBase, filename)) // Ignore error
 	}
 
 	// Delete thumbnail if exists
@@ -269,6 +394,13 @@ func (s *Storage) DeleteVideoFiles(filename string, thumbnailFilename *string, s
 		if err := s.DeleteFile(thumbPath); err != nil {
 			errs = append(errs, fmt.Sprintf("thumbnail: %v", err))
 		}
+
+		// Older videos have no WebP variant
+		if webpPath := s.WebPThumbnailPath(*thumbnailFilename); FileExists(webpPath) {
+			if err := s.DeleteFile(webpPath); err != nil {
+				errs = append(errs, fmt.Sprintf("WebP thumbnail: %v", err))
+			}
+		}
 	}
 
 	if len(errs) > 0 {
@@ -283,10 +415,16 @@ func (s *Storage) Config() StorageConfig {
 	return s.config
 }
 
+// VideoRelativePath returns the path of a video file or directory relative
+// to the video storage directory (see RelativeToVideoPath)
+func (s *Storage) VideoRelativePath(p string) (string, error) {
+	return RelativeToVideoPath(s.config.VideoPath, p)
+}
+
 // --- Streaming helper methods ---
 
-// GetProgressiveVideoPath returns the full path to a progressive MP4 video file.
-// It handles both cases:
+// GetProgressiveVideoPath returns the full path to a progressive MP4 video file,
+// sharded or legacy. It handles both cases:
 // 1. filename is just the stem (e.g., "uuid_timestamp") -> returns path + ".mp4"
 // 2. filename already has .mp4 extension -> returns path as-is
 func (s *Storage) GetProgressiveVideoPath(filename string, storagePath *string) string {
@@ -297,11 +435,11 @@ func (s *Storage) GetProgressiveVideoPath(filename string, storagePath *string)
 
 	// If filename already has .mp4 extension, use it as-is
 	if strings.HasSuffix(strings.ToLower(filename), ".mp4") {
-		return filepath.Join(base, filename)
+		return ResolvePath(base, filename)
 	}
 
 	// Otherwise, append .mp4
-	return filepath.Join(base, filename+".mp4")
+	return ResolvePath(base, filename+".mp4")
 }
 
 // GetHLSManifestPath returns the full path to an HLS master.m3u8 manifest file.
@@ -312,29 +450,109 @@ func (s *Storage) GetHLSManifestPath(filename string, storagePath *string) strin
 		base = *storagePath
 	}
 
-	hlsDir := GetHLSDirectoryName(filename)
-	return filepath.Join(base, hlsDir, "master.m3u8")
+	hlsDir := ResolvePath(base, GetHLSDirectoryName(filename))
+	return filepath.Join(hlsDir, "master.m3u8")
 }
 
 // GetHLSFilePath returns the full path to any HLS file (manifest or segment).
-// The hlsFilename parameter is the relative path within the HLS directory (e.g., "master.m3u8" or "segment000.ts").
+// The hlsFilename parameter is the relative path within the HLS directory (e.g., "master.m3u8", "segment000.ts" or "init.mp4").
 func (s *Storage) GetHLSFilePath(videoFilename string, hlsFilename string, storagePath *string) string {
 	base := s.config.VideoPath
 	if storagePath != nil && *storagePath != "" {
 		base = *storagePath
 	}
 
-	hlsDir := GetHLSDirectoryName(videoFilename)
-	return filepath.Join(base, hlsDir, hlsFilename)
+	hlsDir := ResolvePath(base, GetHLSDirectoryName(videoFilename))
+	return filepath.Join(hlsDir, hlsFilename)
 }
 
 // IsHLSAvailable checks if HLS streaming is available for a video.
-// Returns true if the master.m3u8 manifest file exists.
+// Returns true if the master.m3u8 manifest file exists. Multi-rendition videos
+// also need every media playlist it lists; older videos have a single media
+// playlist named master.m3u8. fMP4 media playlists also need their init
+// segment; MPEG-TS ones have none.
 func (s *Storage) IsHLSAvailable(filename string, storagePath *string) bool {
 	manifestPath := s.GetHLSManifestPath(filename, storagePath)
-	return FileExists(manifestPath)
+	content, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return false
+	}
+
+	variants := HLSVariantPlaylists(string(content))
+	if len(variants) == 0 {
+		return hlsInitSegmentExists(manifestPath, string(content))
+	}
+	for _, variant := range variants {
+		playlistPath := filepath.Join(filepath.Dir(manifestPath), filepath.FromSlash(variant))
+		playlist, err := os.ReadFile(playlistPath)
+		if err != nil || !hlsInitSegmentExists(playlistPath, string(playlist)) {
+			return false
+		}
+	}
+	return true
+}
+
+// DASHManifestName is the MPEG-DASH manifest written next to master.m3u8
+// when a video's HLS segments are fMP4, listing the same segments
+const DASHManifestName = "manifest.mpd"
+
+// GetDASHManifestPath returns the full path to a video's DASH manifest, in
+// its HLS directory
+func (s *Storage) GetDASHManifestPath(filename string, storagePath *string) string {
+	return s.GetHLSFilePath(filename, DASHManifestName, storagePath)
+}
+
+// IsDASHAvailable checks if DASH streaming is available for a video: its
+// HLS is available and has a DASH manifest. Videos with MPEG-TS segments
+// have none.
+func (s *Storage) IsDASHAvailable(filename string, storagePath *string) bool {
+	return FileExists(s.GetDASHManifestPath(filename, storagePath)) && s.IsHLSAvailable(filename, storagePath)
+}
+
+// hlsInitSegmentExists reports whether a media playlist's init segment
+// (#EXT-X-MAP URI attribute) exists, or the playlist has none
+func hlsInitSegmentExists(playlistPath, playlist string) bool {
+	for _, line := range strings.Split(playlist, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "#EXT-X-MAP:") {
+			continue
+		}
+		match := hlsURIAttribute.FindStringSubmatch(line)
+		if match == nil {
+			return false
+		}
+		return FileExists(filepath.Join(filepath.Dir(playlistPath), filepath.FromSlash(match[1])))
+	}
+	return true
+}
+
+// HLSVariantPlaylists returns the media playlists listed by a master playlist,
+// relative to it: the video renditions and any audio renditions
+// (#EXT-X-MEDIA URI attributes). A media playlist lists none.
+func HLSVariantPlaylists(manifest string) []string {
+	var variants []string
+	streamInf := false
+	for _, line := range strings.Split(manifest, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "#EXT-X-STREAM-INF"):
+			streamInf = true
+		case strings.HasPrefix(line, "#EXT-X-MEDIA:"):
+			if match := hlsURIAttribute.FindStringSubmatch(line); match != nil {
+				variants = append(variants, match[1])
+			}
+		case line == "" || strings.HasPrefix(line, "#"):
+		case streamInf:
+			variants = append(variants, line)
+			streamInf = false
+		}
+	}
+	return variants
 }
 
+// hlsURIAttribute matches the URI attribute of a playlist tag
+var hlsURIAttribute = regexp.MustCompile(`URI="([^"]*)"`)
+
 // IsProgressiveAvailable checks if progressive (MP4) streaming is available for a video.
 // Returns true if the .mp4 file exists.
 func (s *Storage) IsProgressiveAvailable(filename string, storagePath *string) bool {
@@ -342,6 +560,31 @@ func (s *Storage) IsProgressiveAvailable(filename string, storagePath *string) b
 	return FileExists(mp4Path)
 }
 
+// ProgressiveQualityName returns the name of a progressive video's file at a
+// lower quality, e.g. "720p.mp4". These files are stored in the video's
+// directory, the one HLS videos keep their files in.
+func ProgressiveQualityName(quality string) string {
+	return quality + ".mp4"
+}
+
+// GetProgressiveQualityPath returns the full path to a progressive video's
+// file at a lower quality, e.g. "720p"
+func (s *Storage) GetProgressiveQualityPath(filename, quality string, storagePath *string) string {
+	return s.GetHLSFilePath(filename, ProgressiveQualityName(quality), storagePath)
+}
+
+// ProgressiveQualities returns those of qualities a progressive video has a
+// file for, in the same order
+func (s *Storage) ProgressiveQualities(filename string, qualities []string, storagePath *string) []string {
+	available := []string{}
+	for _, quality := range qualities {
+		if FileExists(s.GetProgressiveQualityPath(filename, quality, storagePath)) {
+			available = append(available, quality)
+		}
+	}
+	return available
+}
+
 // ReadHLSManifest reads the HLS manifest file content.
 // Returns the content as a string and any error encountered.
 func (s *Storage) ReadHLSManifest(filename string, storagePath *string) (string, error) {
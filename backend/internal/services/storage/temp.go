@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// TempCleanupConfig selects the temp files CleanTempFiles removes
+type TempCleanupConfig struct {
+	TempPath   string
+	ChunksPath string          // Never removed when inside the temp directory; chunk sessions expire on their own
+	MaxAge     time.Duration   // Entries modified more recently are kept
+	InUse      map[string]bool // Names of entries still needed, e.g. the sources of videos not processed yet
+	DryRun     bool
+}
+
+// tempCleanupMu keeps a scheduled cleanup and one started by an admin from
+// racing over the same files
+var tempCleanupMu sync.Mutex
+
+// CleanTempFiles removes the entries of the temp directory older than MaxAge
+// and not in use. A directory's age is that of the newest file in it, so a
+// directory still being written to is kept. With DryRun set, nothing is
+// deleted and the result lists what would be.
+func CleanTempFiles(cfg TempCleanupConfig) (CleanupResult, error) {
+	tempCleanupMu.Lock()
+	defer tempCleanupMu.Unlock()
+
+	result := CleanupResult{
+		Removed: []OrphanedFile{},
+		Errors:  []string{},
+	}
+
+	entries, err := os.ReadDir(cfg.TempPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return result, nil
+		}
+		return result, fmt.Errorf("failed to read temp directory: %w", err)
+	}
+
+	cutoff := time.Now().Add(-cfg.MaxAge)
+	for _, entry := range entries {
+		path := filepath.Join(cfg.TempPath, entry.Name())
+		if cfg.InUse[entry.Name()] || (cfg.ChunksPath != "" && filepath.Clean(path) == filepath.Clean(cfg.ChunksPath)) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			// Removed since the directory was read, e.g. a finished transcode's source
+			continue
+		}
+
+		file := OrphanedFile{
+			Path:  path,
+			Kind:  ScanKindTemp,
+			Bytes: info.Size(),
+			IsDir: entry.IsDir(),
+		}
+		modTime := info.ModTime()
+		if file.IsDir {
+			modTime, file.Bytes, err = newestModTime(path, modTime)
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", path, err))
+				continue
+			}
+		}
+		if !modTime.Before(cutoff) {
+			continue
+		}
+
+		if !cfg.DryRun {
+			if file.IsDir {
+				err = os.RemoveAll(path)
+			} else {
+				err = os.Remove(path)
+			}
+			if err != nil && !os.IsNotExist(err) {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", path, err))
+				continue
+			}
+		}
+		result.Removed = append(result.Removed, file)
+		result.RemovedBytes += file.Bytes
+	}
+
+	return result, nil
+}
+
+// newestModTime returns the latest modification time under dir, starting
+// from its own, and the total size of its files
+func newestModTime(dir string, dirModTime time.Time) (time.Time, int64, error) {
+	newest := dirModTime
+	var bytes int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+		if !d.IsDir() {
+			bytes += info.Size()
+		}
+		return nil
+	})
+	return newest, bytes, err
+}
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// UsageScope selects which files of a directory are counted
+type UsageScope int
+
+const (
+	UsageAll      UsageScope = iota // Every file under the directory
+	UsageTopLevel                   // Only files directly in the directory
+	UsageSubdirs                    // Only files in its subdirectories
+)
+
+// UsageDirectory names a directory to measure
+type UsageDirectory struct {
+	Name  string
+	Path  string
+	Scope UsageScope
+}
+
+// DirectoryUsage is the measured usage of one directory
+type DirectoryUsage struct {
+	Name          string
+	Path          string
+	Bytes         int64
+	Files         int64
+	FreeBytes     uint64 // Free space on the directory's filesystem
+	CapacityBytes uint64 // Size of the directory's filesystem
+	Error         string // Set if the directory couldn't be fully measured
+}
+
+// UsageReport is the usage of all measured directories
+type UsageReport struct {
+	Directories []DirectoryUsage
+	TotalBytes  int64
+	TotalFiles  int64
+	RefreshedAt time.Time
+}
+
+var (
+	usageMu     sync.RWMutex
+	latestUsage *UsageReport
+)
+
+// MeasureUsage walks each directory and totals its files. This reads the
+// whole tree, so callers should run it in the background and publish the
+// result with SetLatestUsage.
+func MeasureUsage(dirs []UsageDirectory) UsageReport {
+	report := UsageReport{
+		Directories: make([]DirectoryUsage, 0, len(dirs)),
+	}
+
+	for _, dir := range dirs {
+		usage := measureDirectory(dir)
+		report.TotalBytes += usage.Bytes
+		report.TotalFiles += usage.Files
+		report.Directories = append(report.Directories, usage)
+	}
+
+	report.RefreshedAt = time.Now()
+	return report
+}
+
+// measureDirectory totals the files of one directory
+func measureDirectory(dir UsageDirectory) DirectoryUsage {
+	usage := DirectoryUsage{Name: dir.Name, Path: dir.Path}
+
+	err := filepath.WalkDir(dir.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		inSubdir := strings.ContainsRune(unshardedPath(relativePath(dir.Path, path)), filepath.Separator)
+		if (dir.Scope == UsageTopLevel && inSubdir) || (dir.Scope == UsageSubdirs && !inSubdir) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			// Removed while walking, e.g. a finished temp file
+			return nil
+		}
+		usage.Bytes += info.Size()
+		usage.Files++
+		return nil
+	})
+	if err != nil {
+		usage.Error = err.Error()
+	}
+
+	free, capacity, err := filesystemSpace(dir.Path)
+	if err != nil {
+		if usage.Error == "" {
+			usage.Error = err.Error()
+		}
+	} else {
+		usage.FreeBytes = free
+		usage.CapacityBytes = capacity
+	}
+
+	return usage
+}
+
+// relativePath returns path relative to root, or path itself if it isn't under root
+func relativePath(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// unshardedPath strips the shard directories from a path relative to a
+// storage directory, so a sharded file counts as top level like a legacy one
+func unshardedPath(rel string) string {
+	parts := strings.SplitN(rel, string(filepath.Separator), 3)
+	if len(parts) == 3 && filepath.Join(parts[0], parts[1]) == filepath.FromSlash(ShardDir(parts[2])) {
+		return parts[2]
+	}
+	return rel
+}
+
+// SetLatestUsage publishes a usage report for LatestUsage
+func SetLatestUsage(report UsageReport) {
+	usageMu.Lock()
+	defer usageMu.Unlock()
+	latestUsage = &report
+}
+
+// LatestUsage returns the most recently published usage report, or false if
+// none has been measured yet
+func LatestUsage() (UsageReport, bool) {
+	usageMu.RLock()
+	defer usageMu.RUnlock()
+	if latestUsage == nil {
+		return UsageReport{}, false
+	}
+	return *latestUsage, true
+}
@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || freebsd)
+
+package storage
+
+import "errors"
+
+// filesystemSpace isn't supported on this platform
+func filesystemSpace(path string) (free uint64, capacity uint64, err error) {
+	return 0, 0, errors.New("filesystem space is not available on this platform")
+}
@@ -2,28 +2,76 @@
 package upload
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// sessionMetaFile is the name of the metadata file stored in each session directory
+const sessionMetaFile = "session.json"
+
+var (
+	// ErrChecksumMismatch is returned when uploaded data doesn't match the client-supplied SHA-256
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	// ErrSessionNotFound is returned when an upload session doesn't exist
+	ErrSessionNotFound = errors.New("upload session not found")
+	// ErrInvalidSessionID is returned for upload IDs that aren't session IDs
+	ErrInvalidSessionID = errors.New("invalid upload session ID")
+	// ErrSessionNotOwned is returned when an upload session belongs to another user
+	ErrSessionNotOwned = errors.New("upload session belongs to another user")
+	// ErrSessionExpired is returned when an upload session has been idle longer than the session TTL
+	ErrSessionExpired = errors.New("upload session expired")
+)
+
+// SessionMeta is persisted alongside the chunks of an upload session
+type SessionMeta struct {
+	UserID       string    `json:"user_id"`
+	Filename     string    `json:"filename"`
+	ExpectedSize int64     `json:"expected_size"`
+	CreatedAt    time.Time `json:"created_at"`
+	// Last time the session was used, kept as the session directory's
+	// modification time so concurrent chunks don't rewrite the metadata
+	LastActivityAt time.Time `json:"-"`
+}
+
+// SessionInfo describes an upload session currently on disk
+type SessionInfo struct {
+	UploadID   string
+	Meta       SessionMeta
+	ChunkCount int
+	SizeBytes  int64
+	Age        time.Duration
+	Expired    bool
+}
+
 // ChunkedUploadManager handles chunked file uploads
 type ChunkedUploadManager struct {
-	basePath string
+	basePath   string
+	sessionTTL time.Duration
 }
 
-// NewChunkedUploadManager creates a new chunked upload manager
-func NewChunkedUploadManager(basePath string) *ChunkedUploadManager {
-	return &ChunkedUploadManager{basePath: basePath}
+// NewChunkedUploadManager creates a new chunked upload manager.
+// Sessions unused for longer than sessionTTL are treated as expired (0 disables expiry).
+func NewChunkedUploadManager(basePath string, sessionTTL time.Duration) *ChunkedUploadManager {
+	return &ChunkedUploadManager{
+		basePath:   basePath,
+		sessionTTL: sessionTTL,
+	}
 }
 
 // InitSession creates a new upload session and returns a unique ID
-func (m *ChunkedUploadManager) InitSession() (string, error) {
+func (m *ChunkedUploadManager) InitSession(userID string, filename string, expectedSize int64) (string, error) {
 	uploadID := uuid.New().String()
 	sessionPath := m.sessionPath(uploadID)
 
@@ -31,10 +79,154 @@ func (m *ChunkedUploadManager) InitSession() (string, error) {
 		return "", fmt.Errorf("failed to create session directory: %w", err)
 	}
 
+	meta := SessionMeta{
+		UserID:       userID,
+		Filename:     filename,
+		ExpectedSize: expectedSize,
+		CreatedAt:    time.Now().UTC(),
+	}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		os.RemoveAll(sessionPath)
+		return "", fmt.Errorf("failed to encode session metadata: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(sessionPath, sessionMetaFile), data, 0644); err != nil {
+		os.RemoveAll(sessionPath)
+		return "", fmt.Errorf("failed to write session metadata: %w", err)
+	}
+
 	log.Printf("Initialized chunked upload session: %s", uploadID)
 	return uploadID, nil
 }
 
+// GetSessionMeta reads the metadata of an upload session.
+// Sessions created before metadata was recorded fall back to the directory's modification time.
+func (m *ChunkedUploadManager) GetSessionMeta(uploadID string) (SessionMeta, error) {
+	sessionPath := m.sessionPath(uploadID)
+
+	info, err := os.Stat(sessionPath)
+	if err != nil || !info.IsDir() {
+		return SessionMeta{}, ErrSessionNotFound
+	}
+
+	data, err := os.ReadFile(filepath.Join(sessionPath, sessionMetaFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return SessionMeta{CreatedAt: info.ModTime().UTC(), LastActivityAt: info.ModTime().UTC()}, nil
+		}
+		return SessionMeta{}, fmt.Errorf("failed to read session metadata: %w", err)
+	}
+
+	var meta SessionMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return SessionMeta{}, fmt.Errorf("failed to decode session metadata: %w", err)
+	}
+	meta.LastActivityAt = info.ModTime().UTC()
+	return meta, nil
+}
+
+// CheckSession verifies that a session exists, belongs to the user and has not
+// expired, extending its expiry as it is being used.
+// Returns ErrInvalidSessionID, ErrSessionNotFound, ErrSessionNotOwned or
+// ErrSessionExpired accordingly.
+func (m *ChunkedUploadManager) CheckSession(uploadID string, userID string) error {
+	// Only accept well-formed session IDs so the path can't escape the chunks directory
+	if _, err := uuid.Parse(uploadID); err != nil {
+		return ErrInvalidSessionID
+	}
+
+	meta, err := m.GetSessionMeta(uploadID)
+	if err != nil {
+		return err
+	}
+	if meta.UserID != userID {
+		return ErrSessionNotOwned
+	}
+	if m.isExpired(meta) {
+		return ErrSessionExpired
+	}
+	m.touchSession(uploadID)
+	return nil
+}
+
+// ListSessions returns information about all upload sessions on disk
+func (m *ChunkedUploadManager) ListSessions() ([]SessionInfo, error) {
+	entries, err := os.ReadDir(m.basePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []SessionInfo{}, nil
+		}
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	sessions := []SessionInfo{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		uploadID := entry.Name()
+
+		meta, err := m.GetSessionMeta(uploadID)
+		if err != nil {
+			log.Printf("Warning: failed to read upload session %s: %v", uploadID, err)
+			continue
+		}
+
+		chunks, err := m.listChunks(m.sessionPath(uploadID))
+		if err != nil {
+			log.Printf("Warning: failed to list chunks for session %s: %v", uploadID, err)
+			continue
+		}
+
+		var size int64
+		for _, chunkPath := range chunks {
+			if info, err := os.Stat(chunkPath); err == nil {
+				size += info.Size()
+			}
+		}
+
+		sessions = append(sessions, SessionInfo{
+			UploadID:   uploadID,
+			Meta:       meta,
+			ChunkCount: len(chunks),
+			SizeBytes:  size,
+			Age:        time.Since(meta.CreatedAt),
+			Expired:    m.isExpired(meta),
+		})
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Meta.CreatedAt.Before(sessions[j].Meta.CreatedAt)
+	})
+
+	return sessions, nil
+}
+
+// CleanupExpiredSessions removes all expired sessions.
+// Returns the number of sessions removed and the bytes reclaimed.
+func (m *ChunkedUploadManager) CleanupExpiredSessions() (int, int64, error) {
+	sessions, err := m.ListSessions()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	removed := 0
+	var reclaimed int64
+	for _, session := range sessions {
+		if !session.Expired {
+			continue
+		}
+		if err := m.CleanupSession(session.UploadID); err != nil {
+			log.Printf("Warning: failed to cleanup expired session %s: %v", session.UploadID, err)
+			continue
+		}
+		removed++
+		reclaimed += session.SizeBytes
+	}
+
+	return removed, reclaimed, nil
+}
+
 // SessionExists checks if an upload session exists
 func (m *ChunkedUploadManager) SessionExists(uploadID string) bool {
 	sessionPath := m.sessionPath(uploadID)
@@ -54,13 +246,16 @@ func (m *ChunkedUploadManager) SaveChunk(uploadID string, chunkIndex int, data [
 	if err := os.WriteFile(chunkPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to save chunk: %w", err)
 	}
+	m.touchSession(uploadID)
 
 	log.Printf("Saved chunk %d for upload %s (%d bytes)", chunkIndex, uploadID, len(data))
 	return nil
 }
 
-// SaveChunkFromReader saves a chunk from a reader
-func (m *ChunkedUploadManager) SaveChunkFromReader(uploadID string, chunkIndex int, reader io.Reader) (int64, error) {
+// SaveChunkFromReader saves a chunk from a reader.
+// If expectedChecksum is non-empty, the chunk is hashed while writing and
+// ErrChecksumMismatch is returned (and the chunk discarded) if the SHA-256 differs.
+func (m *ChunkedUploadManager) SaveChunkFromReader(uploadID string, chunkIndex int, reader io.Reader, expectedChecksum string) (int64, error) {
 	sessionPath := m.sessionPath(uploadID)
 
 	if !m.SessionExists(uploadID) {
@@ -74,68 +269,83 @@ func (m *ChunkedUploadManager) SaveChunkFromReader(uploadID string, chunkIndex i
 	}
 	defer file.Close()
 
-	written, err := io.Copy(file, reader)
+	hasher := sha256.New()
+	written, err := io.Copy(io.MultiWriter(file, hasher), reader)
 	if err != nil {
 		os.Remove(chunkPath) // Clean up partial file
 		return 0, fmt.Errorf("failed to write chunk: %w", err)
 	}
 
+	// Verify checksum if the client provided one
+	if expectedChecksum != "" {
+		actual := hex.EncodeToString(hasher.Sum(nil))
+		if !strings.EqualFold(actual, strings.TrimSpace(expectedChecksum)) {
+			file.Close()
+			os.Remove(chunkPath)
+			return 0, fmt.Errorf("chunk %d: %w", chunkIndex, ErrChecksumMismatch)
+		}
+	}
+	m.touchSession(uploadID)
+
 	log.Printf("Saved chunk %d for upload %s (%d bytes)", chunkIndex, uploadID, written)
 	return written, nil
 }
 
-// MergeChunks merges all chunks in a session into a single file
-func (m *ChunkedUploadManager) MergeChunks(uploadID string, destPath string) (int64, error) {
+// MergeChunks merges all chunks in a session into a single file, returning its
+// size and hex-encoded SHA-256
+func (m *ChunkedUploadManager) MergeChunks(uploadID string, destPath string) (int64, string, error) {
 	sessionPath := m.sessionPath(uploadID)
 
 	if !m.SessionExists(uploadID) {
-		return 0, fmt.Errorf("upload session not found: %s", uploadID)
+		return 0, "", fmt.Errorf("upload session not found: %s", uploadID)
 	}
 
 	// List and sort chunks
 	chunks, err := m.listChunks(sessionPath)
 	if err != nil {
-		return 0, fmt.Errorf("failed to list chunks: %w", err)
+		return 0, "", fmt.Errorf("failed to list chunks: %w", err)
 	}
 
 	if len(chunks) == 0 {
-		return 0, fmt.Errorf("no chunks found for upload: %s", uploadID)
+		return 0, "", fmt.Errorf("no chunks found for upload: %s", uploadID)
 	}
 
 	// Ensure destination directory exists
 	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
-		return 0, fmt.Errorf("failed to create destination directory: %w", err)
+		return 0, "", fmt.Errorf("failed to create destination directory: %w", err)
 	}
 
 	// Create destination file
 	destFile, err := os.Create(destPath)
 	if err != nil {
-		return 0, fmt.Errorf("failed to create destination file: %w", err)
+		return 0, "", fmt.Errorf("failed to create destination file: %w", err)
 	}
 	defer destFile.Close()
 
-	// Merge chunks
+	// Merge chunks, hashing as they're written
+	hasher := sha256.New()
+	dest := io.MultiWriter(destFile, hasher)
 	var totalSize int64
 	for _, chunkPath := range chunks {
 		chunkFile, err := os.Open(chunkPath)
 		if err != nil {
 			os.Remove(destPath) // Clean up partial file
-			return 0, fmt.Errorf("failed to open chunk: %w", err)
+			return 0, "", fmt.Errorf("failed to open chunk: %w", err)
 		}
 
-		written, err := io.Copy(destFile, chunkFile)
+		written, err := io.Copy(dest, chunkFile)
 		chunkFile.Close()
 
 		if err != nil {
 			os.Remove(destPath)
-			return 0, fmt.Errorf("failed to copy chunk: %w", err)
+			return 0, "", fmt.Errorf("failed to copy chunk: %w", err)
 		}
 
 		totalSize += written
 	}
 
 	log.Printf("Merged %d chunks for upload %s into %s (%d bytes)", len(chunks), uploadID, destPath, totalSize)
-	return totalSize, nil
+	return totalSize, hex.EncodeToString(hasher.Sum(nil)), nil
 }
 
 // CleanupSession deletes a chunked upload session and all its chunks
@@ -166,6 +376,26 @@ func (m *ChunkedUploadManager) GetChunkCount(uploadID string) (int, error) {
 	return len(chunks), nil
 }
 
+// isExpired checks whether a session has been idle longer than the session TTL
+func (m *ChunkedUploadManager) isExpired(meta SessionMeta) bool {
+	if m.sessionTTL <= 0 {
+		return false
+	}
+	lastActivity := meta.LastActivityAt
+	if lastActivity.Before(meta.CreatedAt) {
+		lastActivity = meta.CreatedAt
+	}
+	return time.Since(lastActivity) > m.sessionTTL
+}
+
+// touchSession records that a session was just used
+func (m *ChunkedUploadManager) touchSession(uploadID string) {
+	now := time.Now()
+	if err := os.Chtimes(m.sessionPath(uploadID), now, now); err != nil {
+		log.Printf("Warning: failed to update activity of upload session %s: %v", uploadID, err)
+	}
+}
+
 // sessionPath returns the full path for a session directory
 func (m *ChunkedUploadManager) sessionPath(uploadID string) string {
 	return filepath.Join(m.basePath, uploadID)
@@ -0,0 +1,148 @@
+package upload
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ageSession backdates a session to have been created and last used the given
+// durations ago
+func ageSession(t *testing.T, m *ChunkedUploadManager, uploadID string, created, lastUsed time.Duration) {
+	t.Helper()
+	meta, err := m.GetSessionMeta(uploadID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta.CreatedAt = time.Now().Add(-created).UTC()
+	data, _ := json.Marshal(meta)
+	if err := os.WriteFile(filepath.Join(m.sessionPath(uploadID), sessionMetaFile), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	usedAt := time.Now().Add(-lastUsed)
+	if err := os.Chtimes(m.sessionPath(uploadID), usedAt, usedAt); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckSessionExpiresIdleSessions(t *testing.T) {
+	tests := []struct {
+		name     string
+		created  time.Duration
+		lastUsed time.Duration
+		want     error
+	}{
+		{"new", 0, 0, nil},
+		{"old but in use", 3 * time.Hour, 30 * time.Minute, nil},
+		{"idle", 3 * time.Hour, 2 * time.Hour, ErrSessionExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewChunkedUploadManager(t.TempDir(), time.Hour)
+			uploadID, err := m.InitSession("user", "clip.mp4", 1024)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ageSession(t, m, uploadID, tt.created, tt.lastUsed)
+
+			if err := m.CheckSession(uploadID, "user"); !errors.Is(err, tt.want) {
+				t.Errorf("CheckSession() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckSessionChecksOwner(t *testing.T) {
+	m := NewChunkedUploadManager(t.TempDir(), time.Hour)
+	uploadID, err := m.InitSession("owner", "clip.mp4", 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		uploadID string
+		userID   string
+		want     error
+	}{
+		{"owner", uploadID, "owner", nil},
+		{"other user", uploadID, "other", ErrSessionNotOwned},
+		{"unknown session", "00000000-0000-0000-0000-000000000000", "owner", ErrSessionNotFound},
+		{"path outside the chunks directory", "../" + filepath.Base(m.basePath), "owner", ErrInvalidSessionID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := m.CheckSession(tt.uploadID, tt.userID); !errors.Is(err, tt.want) {
+				t.Errorf("CheckSession() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionUseExtendsExpiry(t *testing.T) {
+	tests := []struct {
+		name string
+		use  func(m *ChunkedUploadManager, uploadID string) error
+	}{
+		{"check", func(m *ChunkedUploadManager, uploadID string) error {
+			return m.CheckSession(uploadID, "user")
+		}},
+		{"save chunk", func(m *ChunkedUploadManager, uploadID string) error {
+			return m.SaveChunk(uploadID, 0, []byte("chunk"))
+		}},
+		{"save chunk from reader", func(m *ChunkedUploadManager, uploadID string) error {
+			_, err := m.SaveChunkFromReader(uploadID, 0, strings.NewReader("chunk"), "")
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewChunkedUploadManager(t.TempDir(), time.Hour)
+			uploadID, err := m.InitSession("user", "clip.mp4", 1024)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ageSession(t, m, uploadID, 3*time.Hour, 50*time.Minute)
+
+			if err := tt.use(m, uploadID); err != nil {
+				t.Fatal(err)
+			}
+
+			// Expiry now counts from the use, not from when the session was created
+			meta, err := m.GetSessionMeta(uploadID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if idle := time.Since(meta.LastActivityAt); idle > time.Minute {
+				t.Errorf("session idle for %s after use", idle)
+			}
+			if m.isExpired(meta) {
+				t.Error("session expired after use")
+			}
+		})
+	}
+}
+
+func TestCleanupExpiredSessionsKeepsActiveSessions(t *testing.T) {
+	m := NewChunkedUploadManager(t.TempDir(), time.Hour)
+	active, _ := m.InitSession("user", "active.mp4", 1024)
+	idle, _ := m.InitSession("user", "idle.mp4", 1024)
+	ageSession(t, m, active, 3*time.Hour, 10*time.Minute)
+	ageSession(t, m, idle, 3*time.Hour, 2*time.Hour)
+
+	removed, _, err := m.CleanupExpiredSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 || m.SessionExists(idle) || !m.SessionExists(active) {
+		t.Errorf("removed %d sessions, idle exists = %v, active exists = %v",
+			removed, m.SessionExists(idle), m.SessionExists(active))
+	}
+}
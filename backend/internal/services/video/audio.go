@@ -0,0 +1,215 @@
+package video
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Audio track modes: how an input with several audio tracks is transcoded
+const (
+	AudioTrackModeFirst = "first" // Only the track ffmpeg picks by default
+	AudioTrackModeMix   = "mix"   // Every track downmixed into one
+	AudioTrackModeAll   = "all"   // Every track kept, with its language and name
+)
+
+// AudioTrack is an audio stream of a file
+type AudioTrack struct {
+	Language string // ISO 639-2 code; empty or "und" if unknown
+	Title    string
+}
+
+// audioPlan is how the input's audio is turned into the output's. filters
+// has an entry per output track: its loudness filter, or "" to leave it as is.
+type audioPlan struct {
+	mode    string // Mix and all are only used with two or more input tracks
+	tracks  []AudioTrack
+	filters []string
+}
+
+// ProbeAudioTracks lists the audio streams of a file, in order
+func (f *FFmpeg) ProbeAudioTracks(ctx context.Context, path string) ([]AudioTrack, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, f.config.FFprobePath,
+		"-v", "error",
+		"-select_streams", "a",
+		"-show_entries", "stream=index:stream_tags=language,title,handler_name",
+		"-of", "json",
+		path,
+	)
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to probe audio tracks: %w", err)
+	}
+
+	var data struct {
+		Streams []struct {
+			Tags struct {
+				Language    string `json:"language"`
+				Title       string `json:"title"`
+				HandlerName string `json:"handler_name"`
+			} `json:"tags"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(output, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse audio tracks: %w", err)
+	}
+
+	tracks := make([]AudioTrack, 0, len(data.Streams))
+	for _, stream := range data.Streams {
+		// MP4 keeps a track's name as its handler name
+		title := stream.Tags.Title
+		if title == "" && stream.Tags.HandlerName != "SoundHandler" {
+			title = stream.Tags.HandlerName
+		}
+		tracks = append(tracks, AudioTrack{Language: stream.Tags.Language, Title: title})
+	}
+	return tracks, nil
+}
+
+// CountOutputAudioTracks returns the audio tracks of a transcoded video: an
+// MP4 file, or an HLS directory whose audio is either muxed into the video
+// segments or split into audio renditions
+func (f *FFmpeg) CountOutputAudioTracks(ctx context.Context, path string) (int, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	if !info.IsDir() {
+		tracks, err := f.ProbeAudioTracks(ctx, path)
+		return len(tracks), err
+	}
+
+	manifestPath := filepath.Join(path, "master.m3u8")
+	content, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return 0, err
+	}
+	if count := strings.Count(string(content), "#EXT-X-MEDIA:TYPE=AUDIO"); count > 0 {
+		return count, nil
+	}
+
+	// Probe the first rendition, or master.m3u8 itself if it's the only playlist
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			manifestPath = filepath.Join(path, filepath.FromSlash(line))
+			break
+		}
+	}
+	tracks, err := f.ProbeAudioTracks(ctx, manifestPath)
+	return len(tracks), err
+}
+
+// planAudio decides the output audio for cfg.AudioTrackMode, measuring each
+// output track's loudness if normalization is on. An input that can't be
+// probed is treated like one with a single track.
+func (f *FFmpeg) planAudio(ctx context.Context, inputPath string, cfg TranscodeConfig) audioPlan {
+	tracks, err := f.ProbeAudioTracks(ctx, inputPath)
+	if err != nil {
+		tracks = []AudioTrack{{}}
+	}
+
+	plan := audioPlan{mode: cfg.AudioTrackMode, tracks: tracks}
+	if len(tracks) < 2 || (plan.mode != AudioTrackModeMix && plan.mode != AudioTrackModeAll) {
+		plan.mode = AudioTrackModeFirst
+	}
+
+	switch plan.mode {
+	case AudioTrackModeMix:
+		plan.filters = []string{f.loudnessFilter(ctx, inputPath, mixGraph(len(tracks)), cfg)}
+	case AudioTrackModeAll:
+		for i := range tracks {
+			plan.filters = append(plan.filters, f.loudnessFilter(ctx, inputPath, fmt.Sprintf("[0:a:%d]", i), cfg))
+		}
+	default:
+		if len(tracks) > 0 {
+			plan.filters = []string{f.loudnessFilter(ctx, inputPath, "", cfg)}
+		}
+	}
+	return plan
+}
+
+// muxedArgs returns the audio args for an output with its audio muxed in
+func (p audioPlan) muxedArgs(bitrate string) []string {
+	var args []string
+	switch p.mode {
+	case AudioTrackModeMix:
+		args = append(args,
+			"-filter_complex", appendFilter(mixGraph(len(p.tracks)), p.filters[0])+"[aout]",
+			"-map", "0:v:0",
+			"-map", "[aout]",
+		)
+	case AudioTrackModeAll:
+		args = append(args, "-map", "0:v:0")
+		for i, track := range p.tracks {
+			args = append(args, "-map", fmt.Sprintf("0:a:%d", i))
+			if p.filters[i] != "" {
+				args = append(args, fmt.Sprintf("-filter:a:%d", i), p.filters[i])
+			}
+			args = append(args, track.metadataArgs(i)...)
+		}
+	default:
+		if len(p.filters) > 0 && p.filters[0] != "" {
+			args = append(args, "-af", p.filters[0])
+		}
+	}
+	return append(args, "-c:a", "aac", "-b:a", bitrate)
+}
+
+// metadataArgs sets an output audio stream's language and name. MP4 stores
+// the name as the track's handler name.
+func (t AudioTrack) metadataArgs(stream int) []string {
+	var args []string
+	spec := fmt.Sprintf("-metadata:s:a:%d", stream)
+	if t.Language != "" {
+		args = append(args, spec, "language="+t.Language)
+	}
+	if t.Title != "" {
+		args = append(args, spec, "title="+t.Title, spec, "handler_name="+t.Title)
+	}
+	return args
+}
+
+// name is how the track is labelled in an HLS master playlist
+func (t AudioTrack) name(index int) string {
+	switch {
+	case t.Title != "":
+		return t.Title
+	case t.Language != "" && t.Language != "und":
+		return t.Language
+	default:
+		return fmt.Sprintf("Track %d", index+1)
+	}
+}
+
+// mixGraph downmixes the input's first n audio tracks into one. Tracks are
+// summed rather than averaged, so a quiet mic track doesn't halve the game's
+// volume.
+func mixGraph(n int) string {
+	var b strings.Builder
+	for i := range n {
+		fmt.Fprintf(&b, "[0:a:%d]", i)
+	}
+	fmt.Fprintf(&b, "amix=inputs=%d:duration=longest:normalize=0", n)
+	return b.String()
+}
+
+// appendFilter chains filter onto the end of graph
+func appendFilter(graph, filter string) string {
+	if filter == "" {
+		return graph
+	}
+	if strings.HasSuffix(graph, "]") {
+		return graph + filter
+	}
+	return graph + "," + filter
+}
@@ -0,0 +1,193 @@
+package video
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Candidate presets compared by a benchmark. The GPU presets apply to every
+// hardware backend (see TranscodeConfig).
+var (
+	BenchmarkCPUPresets = []string{"veryfast", "faster", "fast", "medium", "slow", "slower"}
+	BenchmarkGPUPresets = []string{"p1", "p2", "p3", "p4", "p5", "p6", "p7"}
+)
+
+var (
+	// ErrBenchmarkRunning is returned when a benchmark is already in progress
+	ErrBenchmarkRunning = errors.New("an encoder benchmark is already running")
+	// ErrQueueBusy is returned when video jobs are queued or running, which
+	// would skew the benchmark and be slowed down by it
+	ErrQueueBusy = errors.New("the job queue has active video jobs")
+)
+
+// BenchmarkResult is the outcome of encoding the sample with one preset
+type BenchmarkResult struct {
+	Encoder     string
+	Preset      string
+	Frames      int64
+	EncodeFPS   float64
+	WallTime    time.Duration
+	OutputBytes int64
+	Error       string // Set if the encode failed or was cut off by the timeout
+}
+
+// BenchmarkReport is the result of a benchmark run
+type BenchmarkReport struct {
+	Source        string // "generated" or "video"
+	VideoID       string
+	SampleSeconds int
+	Width         int
+	Height        int
+	Results       []BenchmarkResult
+	Error         string // Set if the benchmark couldn't run at all
+	StartedAt     time.Time
+	FinishedAt    time.Time
+}
+
+var (
+	benchmarkMu      sync.Mutex
+	benchmarkRunning bool
+	latestBenchmark  *BenchmarkReport
+)
+
+// GenerateTestClip writes a synthetic 1080p30 clip with audio to outputPath.
+// It's encoded at high quality so it doesn't flatter the presets being compared.
+func (f *FFmpeg) GenerateTestClip(ctx context.Context, outputPath string, seconds int) error {
+	args := []string{
+		"-hide_banner",
+		"-f", "lavfi", "-i", fmt.Sprintf("testsrc2=size=1920x1080:rate=30:duration=%d", seconds),
+		"-f", "lavfi", "-i", fmt.Sprintf("sine=frequency=440:duration=%d", seconds),
+		"-c:v", "libx264",
+		"-preset", "ultrafast",
+		"-crf", "10",
+		"-pix_fmt", "yuv420p",
+		"-c:a", "aac",
+		"-shortest",
+		"-y", outputPath,
+	}
+
+	cmd := exec.CommandContext(ctx, f.config.FFmpegPath, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("test clip generation failed: %v, stderr: %s", err, stderr.String())
+	}
+	return nil
+}
+
+// BenchmarkEncode encodes the first seconds of inputPath to outputPath with
+// the encoder and preset in cfg, and measures the encode speed. Unlike the
+// transcode methods it never falls back from GPU to CPU, and decoding is
+// always in software and audio dropped so only the video encoder is measured.
+func (f *FFmpeg) BenchmarkEncode(ctx context.Context, inputPath, outputPath string, seconds int, cfg TranscodeConfig) BenchmarkResult {
+	cfg.HWDecode = false
+	encoder, encodeArgs := videoEncodeArgs(inputPath, buildScaleFilter(cfg.MaxWidth, cfg.MaxHeight, false), "yuv420p", cfg)
+	result := BenchmarkResult{
+		Encoder: encoder,
+		Preset:  cfg.CPUPreset,
+	}
+	if cfg.UseGPU {
+		result.Preset = cfg.NVENCPreset
+	}
+
+	// -t before the inputs limits how much of the input is read
+	args := []string{"-hide_banner", "-nostats", "-progress", "pipe:1", "-t", strconv.Itoa(seconds)}
+	args = append(args, encodeArgs...)
+	args = append(args, "-an", "-y", outputPath)
+
+	cmd := exec.CommandContext(ctx, f.config.FFmpegPath, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	result.WallTime = time.Since(start)
+
+	if err != nil {
+		if ctx.Err() != nil {
+			result.Error = "stopped by the benchmark timeout"
+		} else {
+			result.Error = fmt.Sprintf("encode failed: %v, stderr: %s", err, lastLines(stderr.String(), 5))
+		}
+		return result
+	}
+
+	result.Frames = progressFrames(stdout.String())
+	if secs := result.WallTime.Seconds(); secs > 0 {
+		result.EncodeFPS = float64(result.Frames) / secs
+	}
+	if info, err := os.Stat(outputPath); err == nil {
+		result.OutputBytes = info.Size()
+	}
+
+	log.Printf("Benchmark %s %s: %d frames in %s (%.1f fps)",
+		result.Encoder, result.Preset, result.Frames, result.WallTime, result.EncodeFPS)
+	return result
+}
+
+// progressFrames returns the last frame count reported by ffmpeg -progress
+func progressFrames(progress string) int64 {
+	var frames int64
+	scanner := bufio.NewScanner(strings.NewReader(progress))
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "frame="); ok {
+			if n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err == nil {
+				frames = n
+			}
+		}
+	}
+	return frames
+}
+
+// lastLines returns the last n lines of s
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// BeginBenchmark marks a benchmark as running. Returns ErrBenchmarkRunning if one already is.
+func BeginBenchmark() error {
+	benchmarkMu.Lock()
+	defer benchmarkMu.Unlock()
+	if benchmarkRunning {
+		return ErrBenchmarkRunning
+	}
+	benchmarkRunning = true
+	return nil
+}
+
+// FinishBenchmark publishes a benchmark report and marks the benchmark as
+// finished. A nil report keeps the previous one, e.g. when it never started.
+func FinishBenchmark(report *BenchmarkReport) {
+	benchmarkMu.Lock()
+	defer benchmarkMu.Unlock()
+	benchmarkRunning = false
+	if report != nil {
+		latestBenchmark = report
+	}
+}
+
+// LatestBenchmark returns the most recent benchmark report and whether a new benchmark is running
+func LatestBenchmark() (report BenchmarkReport, ok bool, running bool) {
+	benchmarkMu.Lock()
+	defer benchmarkMu.Unlock()
+	if latestBenchmark == nil {
+		return BenchmarkReport{}, false, benchmarkRunning
+	}
+	return *latestBenchmark, true, benchmarkRunning
+}
@@ -0,0 +1,92 @@
+package video
+
+import "strconv"
+
+// Output video codecs
+const (
+	VideoCodecH264 = "h264"
+	VideoCodecHEVC = "hevc"
+	VideoCodecAV1  = "av1"
+)
+
+// cpuEncoders maps each codec to its software encoder, which is also the
+// fallback when its hardware encoder fails
+var cpuEncoders = map[string]string{
+	VideoCodecH264: "libx264",
+	VideoCodecHEVC: "libx265",
+	VideoCodecAV1:  "libsvtav1",
+}
+
+// svtAV1Presets maps the x264 preset names used for every CPU encoder to
+// SVT-AV1's numbered presets (0 is the slowest)
+var svtAV1Presets = map[string]int{
+	"ultrafast": 12, "superfast": 11, "veryfast": 10, "faster": 9, "fast": 8,
+	"medium": 7, "slow": 6, "slower": 5, "veryslow": 4,
+}
+
+// OutputCodec returns the codec cfg encodes to; empty means H.264
+func (cfg TranscodeConfig) OutputCodec() string {
+	if _, ok := cpuEncoders[cfg.VideoCodec]; ok {
+		return cfg.VideoCodec
+	}
+	return VideoCodecH264
+}
+
+// CodecEncoder returns the encoder a codec is transcoded with: the hardware
+// backend's with GPU transcoding on (e.g. hevc_nvenc), otherwise the CPU one
+// (e.g. libx265). Empty values mean H.264 and NVENC.
+func CodecEncoder(codec string, useGPU bool, backend string) string {
+	codec = TranscodeConfig{VideoCodec: codec}.OutputCodec()
+	if !useGPU {
+		return cpuEncoders[codec]
+	}
+	if backend == "" {
+		backend = GPUBackendNVENC
+	}
+	return codec + "_" + backend
+}
+
+// cpuQualityArgs returns the preset and constant rate factor args for the
+// CPU encoder. CPUCRF is on libx264's scale: libx265 gets it 5 higher, as
+// x265's CRF 28 looks like x264's 23, and SVT-AV1 gets it scaled onto its
+// 0-63 range.
+func cpuQualityArgs(cfg TranscodeConfig) []string {
+	switch cfg.OutputCodec() {
+	case VideoCodecHEVC:
+		return []string{
+			"-preset", cfg.CPUPreset,
+			"-crf", strconv.Itoa(min(cfg.CPUCRF+5, 51)),
+			"-x265-params", "log-level=error",
+		}
+	case VideoCodecAV1:
+		preset, ok := svtAV1Presets[cfg.CPUPreset]
+		if !ok {
+			preset = svtAV1Presets["medium"]
+		}
+		return []string{
+			"-preset", strconv.Itoa(preset),
+			"-crf", strconv.Itoa((cfg.CPUCRF*63 + 25) / 51),
+		}
+	default:
+		return []string{"-preset", cfg.CPUPreset, "-crf", strconv.Itoa(cfg.CPUCRF)}
+	}
+}
+
+// codecTagArgs sets the sample entry Apple players need: HEVC must be tagged
+// hvc1 rather than FFmpeg's default hev1 to play in Safari
+func codecTagArgs(cfg TranscodeConfig) []string {
+	if cfg.OutputCodec() == VideoCodecHEVC {
+		return []string{"-tag:v", "hvc1"}
+	}
+	return nil
+}
+
+// outputPixelFormat returns the pixel format to encode to. Full-range H.264
+// keeps the yuvj420p it always used; the other encoders don't take the
+// deprecated J formats, so their range is only flagged with -color_range.
+func outputPixelFormat(cfg TranscodeConfig, isFullRange bool) string {
+	if isFullRange && cfg.OutputCodec() == VideoCodecH264 {
+		return "yuvj420p"
+	}
+	return "yuv420p"
+}
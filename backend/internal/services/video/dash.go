@@ -0,0 +1,461 @@
+package video
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"math/bits"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/clipset/clipset-go/internal/services/storage"
+)
+
+// dashTimescale is the MPD's time unit per second
+const dashTimescale = 1000
+
+// MPD elements, in the order the DASH schema lists them. Segments are listed
+// one by one (SegmentList) rather than with a template, so the API can sign
+// each URL like it signs HLS segment URLs.
+type dashMPD struct {
+	XMLName                   xml.Name   `xml:"urn:mpeg:dash:schema:mpd:2011 MPD"`
+	Profiles                  string     `xml:"profiles,attr"`
+	Type                      string     `xml:"type,attr"`
+	MediaPresentationDuration string     `xml:"mediaPresentationDuration,attr"`
+	MinBufferTime             string     `xml:"minBufferTime,attr"`
+	Period                    dashPeriod `xml:"Period"`
+}
+
+type dashPeriod struct {
+	ID             string              `xml:"id,attr"`
+	AdaptationSets []dashAdaptationSet `xml:"AdaptationSet"`
+}
+
+type dashAdaptationSet struct {
+	ID               int                  `xml:"id,attr"`
+	ContentType      string               `xml:"contentType,attr"`
+	MimeType         string               `xml:"mimeType,attr"`
+	Lang             string               `xml:"lang,attr,omitempty"`
+	SegmentAlignment bool                 `xml:"segmentAlignment,attr"`
+	StartWithSAP     int                  `xml:"startWithSAP,attr"`
+	Role             *dashRole            `xml:"Role"`
+	Representations  []dashRepresentation `xml:"Representation"`
+}
+
+type dashRole struct {
+	SchemeIDURI string `xml:"schemeIdUri,attr"`
+	Value       string `xml:"value,attr"`
+}
+
+type dashRepresentation struct {
+	ID          string          `xml:"id,attr"`
+	Bandwidth   int64           `xml:"bandwidth,attr"`
+	Width       int             `xml:"width,attr,omitempty"`
+	Height      int             `xml:"height,attr,omitempty"`
+	Codecs      string          `xml:"codecs,attr"`
+	SegmentList dashSegmentList `xml:"SegmentList"`
+}
+
+type dashSegmentList struct {
+	Timescale      int              `xml:"timescale,attr"`
+	Initialization dashURL          `xml:"Initialization"`
+	Timeline       []dashTimelineS  `xml:"SegmentTimeline>S"`
+	SegmentURLs    []dashSegmentURL `xml:"SegmentURL"`
+}
+
+type dashURL struct {
+	SourceURL string `xml:"sourceURL,attr"`
+}
+
+// dashTimelineS is a run of R+1 segments of duration D; the first starts at 0
+type dashTimelineS struct {
+	D int64 `xml:"d,attr"`
+	R int   `xml:"r,attr,omitempty"`
+}
+
+type dashSegmentURL struct {
+	Media string `xml:"media,attr"`
+}
+
+// hlsMediaPlaylist is an fMP4 media playlist's init segment and segments,
+// with URIs relative to the playlist
+type hlsMediaPlaylist struct {
+	Init      string
+	Segments  []string
+	Durations []float64 // Seconds, one per segment
+}
+
+// writeDASHManifest writes an MPD listing the same fMP4 segments as the
+// variants and audio renditions of master.m3u8, so DASH players can play
+// them too. Playlist URIs are relative to outputDir. It's written to a
+// temporary file and renamed, so it's never seen half-written.
+func writeDASHManifest(outputDir string, variants []hlsVariant, audioRenditions []hlsAudioRendition) error {
+	videoSet := dashAdaptationSet{
+		ID:               0,
+		ContentType:      "video",
+		MimeType:         "video/mp4",
+		SegmentAlignment: true,
+		StartWithSAP:     1,
+	}
+	var duration float64
+	for _, v := range variants {
+		representation, total, err := dashRepresentationFor(outputDir, v.URI, v.Bandwidth)
+		if err != nil {
+			return err
+		}
+		representation.Width, representation.Height = v.Width, v.Height
+		videoSet.Representations = append(videoSet.Representations, representation)
+		duration = max(duration, total)
+	}
+
+	sets := []dashAdaptationSet{videoSet}
+	for i, a := range audioRenditions {
+		representation, total, err := dashRepresentationFor(outputDir, a.URI, a.Bandwidth)
+		if err != nil {
+			return err
+		}
+		set := dashAdaptationSet{
+			ID:               i + 1,
+			ContentType:      "audio",
+			MimeType:         "audio/mp4",
+			SegmentAlignment: true,
+			StartWithSAP:     1,
+			Representations:  []dashRepresentation{representation},
+		}
+		if a.Track.Language != "" && a.Track.Language != "und" {
+			set.Lang = a.Track.Language
+		}
+		if i == 0 {
+			set.Role = &dashRole{SchemeIDURI: "urn:mpeg:dash:role:2011", Value: "main"}
+		}
+		sets = append(sets, set)
+		duration = max(duration, total)
+	}
+
+	mpd := dashMPD{
+		Profiles:                  "urn:mpeg:dash:profile:isoff-main:2011",
+		Type:                      "static",
+		MediaPresentationDuration: fmt.Sprintf("PT%.3fS", duration),
+		MinBufferTime:             "PT" + hlsSegmentTime + "S",
+		Period:                    dashPeriod{ID: "0", AdaptationSets: sets},
+	}
+	content, err := xml.MarshalIndent(mpd, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode DASH manifest: %w", err)
+	}
+	content = append([]byte(xml.Header), content...)
+	content = append(content, '\n')
+
+	manifestPath := filepath.Join(outputDir, storage.DASHManifestName)
+	tmpPath := manifestPath + ".tmp"
+	if err := os.WriteFile(tmpPath, content, 0644); err != nil {
+		return fmt.Errorf("failed to write DASH manifest: %w", err)
+	}
+	if err := os.Rename(tmpPath, manifestPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write DASH manifest: %w", err)
+	}
+	return nil
+}
+
+// writeSingleDASHManifest writes the MPD of a video whose only media
+// playlist is playlistName, with muxed audio
+func (f *FFmpeg) writeSingleDASHManifest(ctx context.Context, outputDir, playlistName string) error {
+	playlistPath := filepath.Join(outputDir, playlistName)
+	variant := hlsVariant{URI: playlistName}
+	var err error
+	variant.Bandwidth, variant.AverageBandwidth, err = measureHLSBandwidth(playlistPath)
+	if err != nil {
+		return err
+	}
+	metadata, err := f.GetMetadata(ctx, playlistPath)
+	if err != nil {
+		return err
+	}
+	variant.Width, variant.Height = metadata.Width, metadata.Height
+	return writeDASHManifest(outputDir, []hlsVariant{variant}, nil)
+}
+
+// dashRepresentationFor builds the representation of a media playlist, and
+// returns its duration in seconds. Its ID is the playlist's directory, or
+// "video" for a playlist at the top of outputDir.
+func dashRepresentationFor(outputDir, playlistURI string, bandwidth int64) (dashRepresentation, float64, error) {
+	playlist, err := readHLSMediaPlaylist(filepath.Join(outputDir, filepath.FromSlash(playlistURI)))
+	if err != nil {
+		return dashRepresentation{}, 0, err
+	}
+
+	dir := path.Dir(playlistURI)
+	initPath := filepath.Join(outputDir, filepath.FromSlash(path.Join(dir, playlist.Init)))
+	codecs, err := initSegmentCodecs(initPath)
+	if err != nil {
+		return dashRepresentation{}, 0, fmt.Errorf("%s: %w", playlistURI, err)
+	}
+
+	id := dir
+	if id == "." {
+		id = "video"
+	}
+	representation := dashRepresentation{
+		ID:        id,
+		Bandwidth: bandwidth,
+		Codecs:    codecs,
+		SegmentList: dashSegmentList{
+			Timescale:      dashTimescale,
+			Initialization: dashURL{SourceURL: path.Join(dir, playlist.Init)},
+		},
+	}
+
+	// Segment start times are rounded from the running total, so rounding
+	// each duration doesn't drift the timeline
+	var elapsed float64
+	var start int64
+	for i, segment := range playlist.Segments {
+		elapsed += playlist.Durations[i]
+		end := int64(math.Round(elapsed * dashTimescale))
+		d := end - start
+		start = end
+
+		timeline := &representation.SegmentList.Timeline
+		if n := len(*timeline); n > 0 && (*timeline)[n-1].D == d {
+			(*timeline)[n-1].R++
+		} else {
+			*timeline = append(*timeline, dashTimelineS{D: d})
+		}
+		representation.SegmentList.SegmentURLs = append(representation.SegmentList.SegmentURLs, dashSegmentURL{Media: path.Join(dir, segment)})
+	}
+	return representation, elapsed, nil
+}
+
+// readHLSMediaPlaylist reads an fMP4 media playlist's init segment and
+// segments
+func readHLSMediaPlaylist(playlistPath string) (hlsMediaPlaylist, error) {
+	file, err := os.Open(playlistPath)
+	if err != nil {
+		return hlsMediaPlaylist{}, fmt.Errorf("failed to open playlist: %w", err)
+	}
+	defer file.Close()
+
+	var playlist hlsMediaPlaylist
+	duration := -1.0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if value, ok := strings.CutPrefix(line, "#EXT-X-MAP:"); ok {
+			if _, uri, ok := strings.Cut(value, `URI="`); ok {
+				playlist.Init, _, _ = strings.Cut(uri, `"`)
+			}
+			continue
+		}
+		if value, ok := strings.CutPrefix(line, "#EXTINF:"); ok {
+			value, _, _ = strings.Cut(value, ",")
+			duration, _ = strconv.ParseFloat(value, 64)
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") || duration < 0 {
+			continue
+		}
+		playlist.Segments = append(playlist.Segments, line)
+		playlist.Durations = append(playlist.Durations, duration)
+		duration = -1
+	}
+	if err := scanner.Err(); err != nil {
+		return hlsMediaPlaylist{}, fmt.Errorf("failed to read playlist: %w", err)
+	}
+	if playlist.Init == "" {
+		return hlsMediaPlaylist{}, fmt.Errorf("playlist has no init segment")
+	}
+	if len(playlist.Segments) == 0 {
+		return hlsMediaPlaylist{}, fmt.Errorf("playlist has no segments")
+	}
+	return playlist, nil
+}
+
+// initSegmentCodecs returns the RFC 6381 codecs of an fMP4 init segment's
+// tracks, e.g. "avc1.640028,mp4a.40.2", read from each track's sample
+// description
+func initSegmentCodecs(initPath string) (string, error) {
+	data, err := os.ReadFile(initPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read init segment: %w", err)
+	}
+
+	moov, ok := mp4Box(data, "moov")
+	if !ok {
+		return "", fmt.Errorf("init segment has no moov box")
+	}
+
+	var codecs []string
+	for _, trak := range mp4Boxes(moov, "trak") {
+		stsd, ok := mp4BoxPath(trak, "mdia", "minf", "stbl", "stsd")
+		if !ok || len(stsd) < 8 {
+			return "", fmt.Errorf("init segment track has no sample description")
+		}
+		// Version, flags and entry count come before the first sample entry
+		entryType, entry, _, ok := mp4FirstBox(stsd[8:])
+		if !ok {
+			return "", fmt.Errorf("init segment track has no sample entry")
+		}
+		codec, err := sampleEntryCodec(entryType, entry)
+		if err != nil {
+			return "", err
+		}
+		codecs = append(codecs, codec)
+	}
+	if len(codecs) == 0 {
+		return "", fmt.Errorf("init segment has no tracks")
+	}
+	return strings.Join(codecs, ","), nil
+}
+
+// sampleEntryCodec returns the RFC 6381 codec of a sample entry
+func sampleEntryCodec(entryType string, entry []byte) (string, error) {
+	switch entryType {
+	case "mp4a":
+		// Audio is always encoded with FFmpeg's AAC-LC encoder
+		return "mp4a.40.2", nil
+	case "avc1", "avc3", "hvc1", "hev1", "av01":
+	default:
+		return "", fmt.Errorf("unsupported sample entry %q", entryType)
+	}
+
+	// Configuration boxes follow the visual sample entry's fixed fields
+	const visualSampleEntrySize = 78
+	if len(entry) < visualSampleEntrySize {
+		return "", fmt.Errorf("%s sample entry is truncated", entryType)
+	}
+	boxes := entry[visualSampleEntrySize:]
+
+	switch entryType {
+	case "avc1", "avc3":
+		avcC, ok := mp4Box(boxes, "avcC")
+		if !ok || len(avcC) < 4 {
+			return "", fmt.Errorf("%s sample entry has no avcC box", entryType)
+		}
+		// Profile, constraint flags and level
+		return fmt.Sprintf("%s.%02x%02x%02x", entryType, avcC[1], avcC[2], avcC[3]), nil
+
+	case "hvc1", "hev1":
+		hvcC, ok := mp4Box(boxes, "hvcC")
+		if !ok || len(hvcC) < 13 {
+			return "", fmt.Errorf("%s sample entry has no hvcC box", entryType)
+		}
+		return hevcCodec(entryType, hvcC), nil
+
+	default:
+		av1C, ok := mp4Box(boxes, "av1C")
+		if !ok || len(av1C) < 3 {
+			return "", fmt.Errorf("av01 sample entry has no av1C box")
+		}
+		tier := "M"
+		if av1C[2]&0x80 != 0 {
+			tier = "H"
+		}
+		bitDepth := 8
+		if av1C[2]&0x40 != 0 {
+			bitDepth = 10
+			if av1C[2]&0x20 != 0 {
+				bitDepth = 12
+			}
+		}
+		return fmt.Sprintf("av01.%d.%02d%s.%02d", av1C[1]>>5, av1C[1]&0x1f, tier, bitDepth), nil
+	}
+}
+
+// hevcCodec formats an HEVC codec from its hvcC box as ISO/IEC 14496-15
+// describes: profile space and profile, compatibility flags in reverse bit
+// order, tier and level, then the constraint flags without trailing zeros
+func hevcCodec(entryType string, hvcC []byte) string {
+	var b strings.Builder
+	b.WriteString(entryType + ".")
+	if space := hvcC[1] >> 6; space > 0 {
+		b.WriteByte('A' + space - 1)
+	}
+	fmt.Fprintf(&b, "%d.%x.", hvcC[1]&0x1f, bits.Reverse32(binary.BigEndian.Uint32(hvcC[2:6])))
+	if hvcC[1]&0x20 != 0 {
+		b.WriteByte('H')
+	} else {
+		b.WriteByte('L')
+	}
+	fmt.Fprintf(&b, "%d", hvcC[12])
+
+	constraints := hvcC[6:12]
+	for len(constraints) > 0 && constraints[len(constraints)-1] == 0 {
+		constraints = constraints[:len(constraints)-1]
+	}
+	for _, c := range constraints {
+		fmt.Fprintf(&b, ".%02X", c)
+	}
+	return b.String()
+}
+
+// mp4FirstBox returns the type, payload and total size of the first box in
+// data
+func mp4FirstBox(data []byte) (string, []byte, int, bool) {
+	if len(data) < 8 {
+		return "", nil, 0, false
+	}
+	size := int(binary.BigEndian.Uint32(data))
+	header := 8
+	switch size {
+	case 0:
+		// Extends to the end of the data
+		size = len(data)
+	case 1:
+		if len(data) < 16 {
+			return "", nil, 0, false
+		}
+		large := binary.BigEndian.Uint64(data[8:])
+		if large > uint64(len(data)) {
+			return "", nil, 0, false
+		}
+		size, header = int(large), 16
+	}
+	if size < header || size > len(data) {
+		return "", nil, 0, false
+	}
+	return string(data[4:8]), data[header:size], size, true
+}
+
+// mp4Boxes returns the payloads of the boxes of type boxType directly in data
+func mp4Boxes(data []byte, boxType string) [][]byte {
+	var payloads [][]byte
+	for len(data) > 0 {
+		typ, payload, size, ok := mp4FirstBox(data)
+		if !ok {
+			break
+		}
+		if typ == boxType {
+			payloads = append(payloads, payload)
+		}
+		data = data[size:]
+	}
+	return payloads
+}
+
+// mp4Box returns the payload of the first box of type boxType directly in data
+func mp4Box(data []byte, boxType string) ([]byte, bool) {
+	boxes := mp4Boxes(data, boxType)
+	if len(boxes) == 0 {
+		return nil, false
+	}
+	return boxes[0], true
+}
+
+// mp4BoxPath returns the payload of the box found by descending through the
+// nested box types
+func mp4BoxPath(data []byte, boxTypes ...string) ([]byte, bool) {
+	for _, boxType := range boxTypes {
+		var ok bool
+		if data, ok = mp4Box(data, boxType); !ok {
+			return nil, false
+		}
+	}
+	return data, true
+}
@@ -7,8 +7,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -43,8 +45,9 @@ func NewFFmpeg(cfg FFmpegConfig) *FFmpeg {
 // VideoMetadata contains extracted video information
 type VideoMetadata struct {
 	Duration       int    // Duration in seconds
-	Width          int    // Video width
-	Height         int    // Video height
+	Width          int    // Video width, as displayed (after rotation)
+	Height         int    // Video height, as displayed (after rotation)
+	Rotation       int    // Display rotation, clockwise: 0, 90, 180 or 270
 	Codec          string // Video codec name
 	ColorRange     string // Color range (pc/tv)
 	ColorSpace     string // Color space
@@ -53,34 +56,70 @@ type VideoMetadata struct {
 	PixFmt         string // Pixel format
 }
 
-// ColorInfo holds color-related metadata for transcoding
+// ColorInfo holds color-related metadata for transcoding, and the source's
+// codec and display rotation
 type ColorInfo struct {
 	ColorRange     string
 	ColorSpace     string
 	ColorTransfer  string
 	ColorPrimaries string
 	PixFmt         string
+	Rotation       int
+	Codec          string
 }
 
-// TranscodeConfig holds transcoding settings
+// Hardware encoder backends used when GPU transcoding is enabled
+const (
+	GPUBackendNVENC = "nvenc" // NVIDIA, h264_nvenc
+	GPUBackendVAAPI = "vaapi" // Intel/AMD via VA-API, h264_vaapi
+	GPUBackendQSV   = "qsv"   // Intel Quick Sync, h264_qsv
+)
+
+// gpuBackendEncoders maps each hardware backend to its H.264 encoder
+var gpuBackendEncoders = map[string]string{
+	GPUBackendNVENC: "h264_nvenc",
+	GPUBackendVAAPI: "h264_vaapi",
+	GPUBackendQSV:   "h264_qsv",
+}
+
+// qsvPresets maps the NVENC p1-p7 presets, which are shared by all hardware
+// backends, to the closest QSV preset
+var qsvPresets = map[string]string{
+	"p1": "veryfast", "p2": "faster", "p3": "fast", "p4": "medium",
+	"p5": "slow", "p6": "slower", "p7": "veryslow",
+}
+
+// TranscodeConfig holds transcoding settings. The NVENC preset, quality and
+// bitrate settings also apply to the VAAPI and QSV backends.
 type TranscodeConfig struct {
 	UseGPU           bool
+	GPUBackend       string // nvenc, vaapi or qsv; empty means nvenc
+	GPUDevice        int    // Render node index for vaapi and qsv (/dev/dri/renderD128 + n)
 	MaxWidth         int
 	MaxHeight        int
 	AudioBitrate     string
 	CPUPreset        string
 	CPUCRF           int
+	CPUMaxBitrate    string // Optional libx264 bitrate cap; the buffer is twice the cap
 	NVENCPreset      string
 	NVENCCQ          int
 	NVENCRateControl string
 	NVENCMaxBitrate  string
 	NVENCBufferSize  string
+	Renditions       []Rendition // Bitrate ladder; empty means a single rendition from the settings above
+	NormalizeAudio   bool        // Apply EBU R128 loudness normalization
+	LoudnessTarget   int         // Integrated loudness target in LUFS
+	AudioTrackMode   string      // first, mix or all; empty means first
+	VideoCodec       string      // h264, hevc or av1; empty means h264
+	HLSSegmentFormat string      // ts or fmp4; empty means ts
+	HWDecode         bool        // Decode with NVDEC when transcoding with NVENC, where the source allows
 }
 
 // DefaultTranscodeConfig returns sensible defaults
 func DefaultTranscodeConfig() TranscodeConfig {
 	return TranscodeConfig{
 		UseGPU:           false,
+		GPUBackend:       GPUBackendNVENC,
 		MaxWidth:         1920,
 		MaxHeight:        1080,
 		AudioBitrate:     "192k",
@@ -91,6 +130,9 @@ func DefaultTranscodeConfig() TranscodeConfig {
 		NVENCRateControl: "vbr",
 		NVENCMaxBitrate:  "8M",
 		NVENCBufferSize:  "16M",
+		LoudnessTarget:   DefaultLoudnessTarget,
+		VideoCodec:       VideoCodecH264,
+		HLSSegmentFormat: HLSSegmentFormatTS,
 	}
 }
 
@@ -98,13 +140,15 @@ func DefaultTranscodeConfig() TranscodeConfig {
 type EncoderInfo struct {
 	GPUAvailable bool     `json:"gpu_available"`
 	GPUName      string   `json:"gpu_name,omitempty"`
+	GPUBackends  []string `json:"gpu_backends"` // Usable hardware backends
 	Encoders     []string `json:"encoders"`
 }
 
 // DetectEncoders checks which video encoders are available
 func (f *FFmpeg) DetectEncoders(ctx context.Context) EncoderInfo {
 	result := EncoderInfo{
-		Encoders: []string{},
+		GPUBackends: []string{},
+		Encoders:    []string{},
 	}
 
 	// Get encoders from FFmpeg
@@ -123,8 +167,15 @@ func (f *FFmpeg) DetectEncoders(ctx context.Context) EncoderInfo {
 		"h264_nvenc",
 		"hevc_nvenc",
 		"av1_nvenc",
+		"h264_vaapi",
+		"hevc_vaapi",
+		"av1_vaapi",
+		"h264_qsv",
+		"hevc_qsv",
+		"av1_qsv",
 		"libx264",
 		"libx265",
+		"libsvtav1",
 	}
 
 	outputStr := string(output)
@@ -134,16 +185,11 @@ func (f *FFmpeg) DetectEncoders(ctx context.Context) EncoderInfo {
 		}
 	}
 
-	// Check if NVENC is available
-	for _, enc := range result.Encoders {
-		if strings.Contains(enc, "nvenc") {
-			result.GPUAvailable = true
-			break
-		}
-	}
+	result.GPUBackends = UsableGPUBackends(result.Encoders)
+	result.GPUAvailable = len(result.GPUBackends) > 0
 
 	// Try to get GPU name using nvidia-smi
-	if result.GPUAvailable {
+	if slices.Contains(result.GPUBackends, GPUBackendNVENC) {
 		nvidiaCtx, nvidiaCancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer nvidiaCancel()
 
@@ -156,10 +202,27 @@ func (f *FFmpeg) DetectEncoders(ctx context.Context) EncoderInfo {
 		}
 	}
 
-	log.Printf("Encoder detection result: GPU=%v, encoders=%v", result.GPUAvailable, result.Encoders)
+	log.Printf("Encoder detection result: GPU=%v, backends=%v, encoders=%v", result.GPUAvailable, result.GPUBackends, result.Encoders)
 	return result
 }
 
+// UsableGPUBackends returns the hardware backends whose encoder is among
+// encoders. VAAPI and QSV also need a DRI render node to be present.
+func UsableGPUBackends(encoders []string) []string {
+	backends := []string{}
+	renderNodes, _ := filepath.Glob("/dev/dri/renderD*")
+	for _, backend := range []string{GPUBackendNVENC, GPUBackendVAAPI, GPUBackendQSV} {
+		if !slices.Contains(encoders, gpuBackendEncoders[backend]) {
+			continue
+		}
+		if backend != GPUBackendNVENC && len(renderNodes) == 0 {
+			continue
+		}
+		backends = append(backends, backend)
+	}
+	return backends
+}
+
 // ValidateVideo checks if a file is a valid video using ffprobe
 func (f *FFmpeg) ValidateVideo(ctx context.Context, filepath string) (bool, string) {
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
@@ -196,7 +259,7 @@ func (f *FFmpeg) GetMetadata(ctx context.Context, filepath string) (*VideoMetada
 
 	cmd := exec.CommandContext(ctx, f.config.FFprobePath,
 		"-v", "error",
-		"-show_entries", "format=duration:stream=width,height,codec_name,color_range,color_space,color_transfer,color_primaries,pix_fmt",
+		"-show_entries", "format=duration:stream=width,height,codec_name,color_range,color_space,color_transfer,color_primaries,pix_fmt:stream_side_data=rotation:stream_tags=rotate",
 		"-of", "json",
 		filepath,
 	)
@@ -219,6 +282,12 @@ func (f *FFmpeg) GetMetadata(ctx context.Context, filepath string) (*VideoMetada
 			ColorTransfer  string `json:"color_transfer"`
 			ColorPrimaries string `json:"color_primaries"`
 			PixFmt         string `json:"pix_fmt"`
+			SideDataList   []struct {
+				Rotation *float64 `json:"rotation"`
+			} `json:"side_data_list"`
+			Tags struct {
+				Rotate string `json:"rotate"`
+			} `json:"tags"`
 		} `json:"streams"`
 	}
 
@@ -240,6 +309,24 @@ func (f *FFmpeg) GetMetadata(ctx context.Context, filepath string) (*VideoMetada
 		stream := data.Streams[0]
 		metadata.Width = stream.Width
 		metadata.Height = stream.Height
+
+		// Phones record in the sensor's orientation and flag the rotation,
+		// as a display matrix or, from older muxers, a rotate tag. The
+		// matrix's angle is counterclockwise.
+		for _, sideData := range stream.SideDataList {
+			if sideData.Rotation != nil {
+				metadata.Rotation = normalizeRotation(-*sideData.Rotation)
+				break
+			}
+		}
+		if metadata.Rotation == 0 && stream.Tags.Rotate != "" {
+			if rotate, err := strconv.ParseFloat(stream.Tags.Rotate, 64); err == nil {
+				metadata.Rotation = normalizeRotation(rotate)
+			}
+		}
+		if isQuarterTurn(metadata.Rotation) {
+			metadata.Width, metadata.Height = metadata.Height, metadata.Width
+		}
 		metadata.Codec = stream.CodecName
 		metadata.ColorRange = stream.ColorRange
 		metadata.ColorSpace = stream.ColorSpace
@@ -248,12 +335,23 @@ func (f *FFmpeg) GetMetadata(ctx context.Context, filepath string) (*VideoMetada
 		metadata.PixFmt = stream.PixFmt
 	}
 
-	log.Printf("Extracted metadata from %s: duration=%ds, %dx%d, codec=%s",
-		filepath, metadata.Duration, metadata.Width, metadata.Height, metadata.Codec)
+	log.Printf("Extracted metadata from %s: duration=%ds, %dx%d, rotation=%d, codec=%s",
+		filepath, metadata.Duration, metadata.Width, metadata.Height, metadata.Rotation, metadata.Codec)
 
 	return metadata, nil
 }
 
+// normalizeRotation rounds a clockwise angle in degrees to 0, 90, 180 or 270
+func normalizeRotation(degrees float64) int {
+	quarters := int(math.Round(degrees / 90))
+	return ((quarters % 4) + 4) % 4 * 90
+}
+
+// isQuarterTurn reports whether a rotation swaps the width and height
+func isQuarterTurn(rotation int) bool {
+	return rotation == 90 || rotation == 270
+}
+
 // NeedsTranscoding checks if video needs transcoding for web compatibility
 func (f *FFmpeg) NeedsTranscoding(ctx context.Context, filepath string) bool {
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
@@ -305,6 +403,18 @@ func (f *FFmpeg) NeedsTranscoding(ctx context.Context, filepath string) bool {
 	return needsTranscode
 }
 
+// scaleBounds returns the max output size for a source. ffmpeg applies the
+// source's display rotation before the video filters, so the scale filter
+// sees portrait frames from a rotated phone video; the bounds are turned with
+// them, so a 1080p box keeps a 1080x1920 clip whole rather than shrinking it
+// to 608x1080.
+func scaleBounds(cfg TranscodeConfig, colorInfo *ColorInfo) (int, int) {
+	if colorInfo != nil && isQuarterTurn(colorInfo.Rotation) {
+		return cfg.MaxHeight, cfg.MaxWidth
+	}
+	return cfg.MaxWidth, cfg.MaxHeight
+}
+
 // buildScaleFilter creates the scale filter string
 func buildScaleFilter(maxWidth, maxHeight int, isFullRange bool) string {
 	if isFullRange {
@@ -323,55 +433,144 @@ func isFullColorRange(colorInfo *ColorInfo) bool {
 	return colorInfo.ColorRange == "pc" || strings.HasPrefix(colorInfo.PixFmt, "yuvj")
 }
 
-// TranscodeProgressiveMP4 transcodes video to H.264 MP4 optimized for web streaming
-func (f *FFmpeg) TranscodeProgressiveMP4(ctx context.Context, inputPath, outputPath string, cfg TranscodeConfig, colorInfo *ColorInfo) error {
-	ctx, cancel := context.WithTimeout(ctx, f.config.Timeout)
-	defer cancel()
+// renderNode returns the DRI render node for a VAAPI or QSV device index
+func renderNode(device int) string {
+	return fmt.Sprintf("/dev/dri/renderD%d", 128+device)
+}
 
-	isFullRange := isFullColorRange(colorInfo)
-	outputPixFmt := "yuv420p"
-	outputColorRange := "tv"
-	if isFullRange {
-		outputPixFmt = "yuvj420p"
-		outputColorRange = "pc"
+// videoEncodeArgs builds the input and video encoding arguments for the
+// encoder cfg selects, and returns the encoder's name. The codec picks the
+// encoder family (see CodecEncoder). Hardware backends share the NVENC
+// settings: the preset (mapped for QSV), CQ as the constant quality and, for
+// CBR, the max bitrate. VAAPI and QSV encode from GPU memory, so frames are
+// scaled in software and then uploaded. With cfg.HWDecode, NVENC is fed
+// frames decoded into GPU memory, and scaleFilter must be a CUDA filter.
+func videoEncodeArgs(inputPath, scaleFilter, outputPixFmt string, cfg TranscodeConfig) (string, []string) {
+	encoder := CodecEncoder(cfg.VideoCodec, cfg.UseGPU, cfg.GPUBackend)
+	if !cfg.UseGPU {
+		args := []string{
+			"-i", inputPath,
+			"-vf", scaleFilter,
+			"-c:v", encoder,
+			"-pix_fmt", outputPixFmt,
+		}
+		args = append(args, cpuQualityArgs(cfg)...)
+		if bits, ok := parseBitrate(cfg.CPUMaxBitrate); ok {
+			args = append(args,
+				"-maxrate", cfg.CPUMaxBitrate,
+				"-bufsize", fmt.Sprintf("%dk", bits*2/1000),
+			)
+		}
+		return encoder, append(args, codecTagArgs(cfg)...)
 	}
 
-	scaleFilter := buildScaleFilter(cfg.MaxWidth, cfg.MaxHeight, isFullRange)
-
-	var args []string
-
-	if cfg.UseGPU {
-		// GPU encoding with NVENC
-		log.Printf("Transcoding with GPU (h264_nvenc): %s -> %s", inputPath, outputPath)
+	switch cfg.GPUBackend {
+	case GPUBackendVAAPI:
+		args := []string{
+			"-vaapi_device", renderNode(cfg.GPUDevice),
+			"-i", inputPath,
+			"-vf", scaleFilter + ",format=nv12,hwupload",
+			"-c:v", encoder,
+		}
+		if cfg.NVENCRateControl == "cbr" {
+			args = append(args,
+				"-rc_mode", "CBR",
+				"-b:v", cfg.NVENCMaxBitrate,
+				"-maxrate", cfg.NVENCMaxBitrate,
+				"-bufsize", cfg.NVENCBufferSize,
+			)
+		} else {
+			args = append(args, "-rc_mode", "CQP", "-qp", strconv.Itoa(cfg.NVENCCQ))
+		}
+		return encoder, append(args, codecTagArgs(cfg)...)
 
-		args = []string{
+	case GPUBackendQSV:
+		preset, ok := qsvPresets[cfg.NVENCPreset]
+		if !ok {
+			preset = "medium"
+		}
+		args := []string{
+			"-init_hw_device", "vaapi=va:" + renderNode(cfg.GPUDevice),
+			"-init_hw_device", "qsv=qs@va",
+			"-filter_hw_device", "qs",
 			"-i", inputPath,
-			"-vf", scaleFilter,
-			"-c:v", "h264_nvenc",
-			"-pix_fmt", outputPixFmt,
+			"-vf", scaleFilter + ",format=nv12,hwupload=extra_hw_frames=64",
+			"-c:v", encoder,
+			"-preset", preset,
+		}
+		if cfg.NVENCRateControl == "cbr" {
+			args = append(args,
+				"-b:v", cfg.NVENCMaxBitrate,
+				"-maxrate", cfg.NVENCMaxBitrate,
+				"-bufsize", cfg.NVENCBufferSize,
+			)
+		} else {
+			args = append(args, "-global_quality", strconv.Itoa(cfg.NVENCCQ))
+		}
+		return encoder, append(args, codecTagArgs(cfg)...)
+
+	default:
+		var args []string
+		if cfg.HWDecode {
+			args = []string{
+				"-hwaccel", "cuda",
+				"-hwaccel_output_format", "cuda",
+				"-hwaccel_device", strconv.Itoa(cfg.GPUDevice),
+				"-i", inputPath,
+				"-vf", scaleFilter,
+				"-c:v", encoder,
+			}
+		} else {
+			args = []string{
+				"-i", inputPath,
+				"-vf", scaleFilter,
+				"-c:v", encoder,
+				"-pix_fmt", outputPixFmt,
+			}
+		}
+		args = append(args,
 			"-preset", cfg.NVENCPreset,
 			"-rc", cfg.NVENCRateControl,
 			"-cq", strconv.Itoa(cfg.NVENCCQ),
 			"-b:v", "0",
 			"-maxrate", cfg.NVENCMaxBitrate,
 			"-bufsize", cfg.NVENCBufferSize,
-			"-color_range", outputColorRange,
-		}
-	} else {
-		// CPU encoding with libx264
-		log.Printf("Transcoding with CPU (libx264): %s -> %s", inputPath, outputPath)
+		)
+		return encoder, append(args, codecTagArgs(cfg)...)
+	}
+}
 
-		args = []string{
-			"-i", inputPath,
-			"-vf", scaleFilter,
-			"-c:v", "libx264",
-			"-pix_fmt", outputPixFmt,
-			"-preset", cfg.CPUPreset,
-			"-crf", strconv.Itoa(cfg.CPUCRF),
-			"-color_range", outputColorRange,
-		}
+// TranscodeProgressiveMP4 transcodes video to H.264 MP4 optimized for web streaming.
+// progress is optional.
+func (f *FFmpeg) TranscodeProgressiveMP4(ctx context.Context, inputPath, outputPath string, cfg TranscodeConfig, colorInfo *ColorInfo, progress *TranscodeProgress) error {
+	audio := f.planAudio(ctx, inputPath, cfg)
+	return f.transcodeProgressiveMP4(ctx, inputPath, outputPath, cfg, colorInfo, audio, progress)
+}
+
+// transcodeProgressiveMP4 does the transcode, with the audio as planned
+func (f *FFmpeg) transcodeProgressiveMP4(ctx context.Context, inputPath, outputPath string, cfg TranscodeConfig, colorInfo *ColorInfo, audio audioPlan, progress *TranscodeProgress) error {
+	ctx, cancel := context.WithTimeout(ctx, f.config.Timeout)
+	defer cancel()
+
+	isFullRange := isFullColorRange(colorInfo)
+	outputPixFmt := outputPixelFormat(cfg, isFullRange)
+	outputColorRange := "tv"
+	if isFullRange {
+		outputColorRange = "pc"
 	}
 
+	cfg = planDecode(ctx, cfg, colorInfo)
+	maxWidth, maxHeight := scaleBounds(cfg, colorInfo)
+	scaleFilter := buildScaleFilter(maxWidth, maxHeight, isFullRange)
+	if cfg.HWDecode {
+		scaleFilter = buildCUDAScaleFilter(maxWidth, maxHeight)
+	}
+
+	encoder, encodeArgs := videoEncodeArgs(inputPath, scaleFilter, outputPixFmt, cfg)
+	args := append([]string{"-nostats", "-progress", "pipe:1"}, encodeArgs...)
+	args = append(args, "-color_range", outputColorRange)
+	log.Printf("Transcoding with %s: %s -> %s", encoder, inputPath, outputPath)
+
 	// Add color metadata if available
 	if colorInfo != nil {
 		if colorInfo.ColorSpace != "" {
@@ -386,9 +585,8 @@ func (f *FFmpeg) TranscodeProgressiveMP4(ctx context.Context, inputPath, outputP
 	}
 
 	// Add audio and output settings
+	args = append(args, audio.muxedArgs(cfg.AudioBitrate)...)
 	args = append(args,
-		"-c:a", "aac",
-		"-b:a", cfg.AudioBitrate,
 		"-movflags", "+faststart",
 		"-y", outputPath,
 	)
@@ -397,13 +595,22 @@ func (f *FFmpeg) TranscodeProgressiveMP4(ctx context.Context, inputPath, outputP
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
-		// If GPU failed, try CPU fallback
+	err := runWithProgress(cmd, progress)
+	logCommand(ctx, cmd, stderr.String(), err)
+	if err != nil {
+		// If GPU decoding failed, retry decoding in software; if the GPU
+		// encoder failed, try CPU fallback
+		if cfg.HWDecode {
+			logNote(ctx, "Hardware decoding failed, falling back to software decoding: %v", err)
+			swCfg := cfg
+			swCfg.HWDecode = false
+			return f.transcodeProgressiveMP4(ctx, inputPath, outputPath, swCfg, colorInfo, audio, progress)
+		}
 		if cfg.UseGPU {
-			log.Printf("GPU transcoding failed, falling back to CPU: %v", err)
+			log.Printf("GPU transcoding failed, falling back to %s: %v", CodecEncoder(cfg.VideoCodec, false, ""), err)
 			cpuCfg := cfg
 			cpuCfg.UseGPU = false
-			return f.TranscodeProgressiveMP4(ctx, inputPath, outputPath, cpuCfg, colorInfo)
+			return f.transcodeProgressiveMP4(ctx, inputPath, outputPath, cpuCfg, colorInfo, audio, progress)
 		}
 
 		return fmt.Errorf("transcoding failed: %v, stderr: %s", err, stderr.String())
@@ -413,56 +620,60 @@ func (f *FFmpeg) TranscodeProgressiveMP4(ctx context.Context, inputPath, outputP
 	return nil
 }
 
-// TranscodeHLS transcodes video to HLS format (segmented streaming)
-func (f *FFmpeg) TranscodeHLS(ctx context.Context, inputPath, outputDir string, cfg TranscodeConfig, colorInfo *ColorInfo) error {
+// TranscodeHLS transcodes video to HLS format (segmented streaming).
+// With a rendition ladder, or with every audio track kept, master.m3u8 is a
+// master playlist listing a media playlist per rendition and audio track;
+// otherwise master.m3u8 is the only media playlist. With fMP4 segments, a
+// DASH manifest listing the same segments is written too. progress is
+// optional.
+func (f *FFmpeg) TranscodeHLS(ctx context.Context, inputPath, outputDir string, cfg TranscodeConfig, colorInfo *ColorInfo, progress *TranscodeProgress) error {
+	audio := f.planAudio(ctx, inputPath, cfg)
+	if len(cfg.Renditions) > 0 || audio.mode == AudioTrackModeAll {
+		return f.transcodeHLSRenditions(ctx, inputPath, outputDir, cfg, colorInfo, audio, progress)
+	}
+
+	if err := f.transcodeHLSPlaylist(ctx, inputPath, outputDir, "master.m3u8", cfg, colorInfo, audio.muxedArgs(cfg.AudioBitrate), progress); err != nil {
+		return err
+	}
+
+	// DASH players can play fMP4 segments too; the video still plays over
+	// HLS without the MPD
+	if cfg.hlsSegmentFormat() == HLSSegmentFormatFMP4 {
+		if err := f.writeSingleDASHManifest(ctx, outputDir, "master.m3u8"); err != nil {
+			log.Printf("Warning: failed to write DASH manifest: %v", err)
+		}
+	}
+
+	log.Printf("HLS transcoding completed: %s", outputDir)
+	return nil
+}
+
+// transcodeHLSPlaylist encodes one rendition into outputDir as the media
+// playlist playlistName and its segments. audioArgs map and encode the audio.
+func (f *FFmpeg) transcodeHLSPlaylist(ctx context.Context, inputPath, outputDir, playlistName string, cfg TranscodeConfig, colorInfo *ColorInfo, audioArgs []string, progress *TranscodeProgress) error {
 	ctx, cancel := context.WithTimeout(ctx, f.config.Timeout)
 	defer cancel()
 
 	isFullRange := isFullColorRange(colorInfo)
-	outputPixFmt := "yuv420p"
+	outputPixFmt := outputPixelFormat(cfg, isFullRange)
 	outputColorRange := "tv"
 	if isFullRange {
-		outputPixFmt = "yuvj420p"
 		outputColorRange = "pc"
 	}
 
-	scaleFilter := buildScaleFilter(cfg.MaxWidth, cfg.MaxHeight, isFullRange)
-
-	manifestPath := filepath.Join(outputDir, "master.m3u8")
-	segmentPattern := filepath.Join(outputDir, "segment%03d.ts")
-	hlsTime := "4" // 4-second segments
+	cfg = planDecode(ctx, cfg, colorInfo)
+	maxWidth, maxHeight := scaleBounds(cfg, colorInfo)
+	scaleFilter := buildScaleFilter(maxWidth, maxHeight, isFullRange)
+	if cfg.HWDecode {
+		scaleFilter = buildCUDAScaleFilter(maxWidth, maxHeight)
+	}
 
-	var args []string
+	manifestPath := filepath.Join(outputDir, playlistName)
 
-	if cfg.UseGPU {
-		log.Printf("HLS transcoding with GPU (h264_nvenc): %s -> %s", inputPath, outputDir)
-
-		args = []string{
-			"-i", inputPath,
-			"-vf", scaleFilter,
-			"-c:v", "h264_nvenc",
-			"-pix_fmt", outputPixFmt,
-			"-preset", cfg.NVENCPreset,
-			"-rc", cfg.NVENCRateControl,
-			"-cq", strconv.Itoa(cfg.NVENCCQ),
-			"-b:v", "0",
-			"-maxrate", cfg.NVENCMaxBitrate,
-			"-bufsize", cfg.NVENCBufferSize,
-			"-color_range", outputColorRange,
-		}
-	} else {
-		log.Printf("HLS transcoding with CPU (libx264): %s -> %s", inputPath, outputDir)
-
-		args = []string{
-			"-i", inputPath,
-			"-vf", scaleFilter,
-			"-c:v", "libx264",
-			"-pix_fmt", outputPixFmt,
-			"-preset", cfg.CPUPreset,
-			"-crf", strconv.Itoa(cfg.CPUCRF),
-			"-color_range", outputColorRange,
-		}
-	}
+	encoder, encodeArgs := videoEncodeArgs(inputPath, scaleFilter, outputPixFmt, cfg)
+	args := append([]string{"-nostats", "-progress", "pipe:1"}, encodeArgs...)
+	args = append(args, "-color_range", outputColorRange)
+	log.Printf("HLS transcoding with %s: %s -> %s", encoder, inputPath, manifestPath)
 
 	// Add color metadata if available
 	if colorInfo != nil {
@@ -477,39 +688,49 @@ func (f *FFmpeg) TranscodeHLS(ctx context.Context, inputPath, outputDir string,
 		}
 	}
 
-	// Add audio and HLS output settings
+	// Add audio and HLS output settings. A keyframe at every segment boundary
+	// keeps the segments of different renditions aligned for quality switching.
+	args = append(args, "-force_key_frames", "expr:gte(t,n_forced*"+hlsSegmentTime+")")
+	args = append(args, audioArgs...)
 	args = append(args,
-		"-c:a", "aac",
-		"-b:a", cfg.AudioBitrate,
 		"-f", "hls",
-		"-hls_time", hlsTime,
+		"-hls_time", hlsSegmentTime,
 		"-hls_list_size", "0",
-		"-hls_segment_type", "mpegts",
-		"-hls_segment_filename", segmentPattern,
-		"-y", manifestPath,
 	)
+	args = append(args, hlsSegmentArgs(outputDir, cfg)...)
+	args = append(args, "-y", manifestPath)
 
 	cmd := exec.CommandContext(ctx, f.config.FFmpegPath, args...)
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
-		// If GPU failed, try CPU fallback
+	err := runWithProgress(cmd, progress)
+	logCommand(ctx, cmd, stderr.String(), err)
+	if err != nil {
+		// If GPU decoding failed, retry decoding in software; if the GPU
+		// encoder failed, try CPU fallback
+		if cfg.HWDecode {
+			logNote(ctx, "Hardware decoding failed, falling back to software decoding: %v", err)
+			swCfg := cfg
+			swCfg.HWDecode = false
+			return f.transcodeHLSPlaylist(ctx, inputPath, outputDir, playlistName, swCfg, colorInfo, audioArgs, progress)
+		}
 		if cfg.UseGPU {
-			log.Printf("GPU HLS transcoding failed, falling back to CPU: %v", err)
+			log.Printf("GPU HLS transcoding failed, falling back to %s: %v", CodecEncoder(cfg.VideoCodec, false, ""), err)
 			cpuCfg := cfg
 			cpuCfg.UseGPU = false
-			return f.TranscodeHLS(ctx, inputPath, outputDir, cpuCfg, colorInfo)
+			return f.transcodeHLSPlaylist(ctx, inputPath, outputDir, playlistName, cpuCfg, colorInfo, audioArgs, progress)
 		}
 
 		return fmt.Errorf("HLS transcoding failed: %v, stderr: %s", err, stderr.String())
 	}
 
-	log.Printf("HLS transcoding completed: %s", outputDir)
 	return nil
 }
 
-// ExtractThumbnail extracts a thumbnail from video at specified timestamp
+// ExtractThumbnail extracts a thumbnail from video at specified timestamp.
+// ffmpeg applies the video's display rotation before scaling, so thumbnails
+// of rotated phone videos come out upright.
 func (f *FFmpeg) ExtractThumbnail(ctx context.Context, videoPath, thumbnailPath string, timestampSec float64) error {
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
@@ -527,10 +748,39 @@ func (f *FFmpeg) ExtractThumbnail(ctx context.Context, videoPath, thumbnailPath
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
+	err := cmd.Run()
+	logCommand(ctx, cmd, stderr.String(), err)
+	if err != nil {
 		return fmt.Errorf("thumbnail extraction failed: %v, stderr: %s", err, stderr.String())
 	}
 
 	log.Printf("Thumbnail extracted: %s", thumbnailPath)
 	return nil
 }
+
+// EncodeWebPThumbnail writes a WebP copy of a JPEG thumbnail, for browsers
+// that accept it. The JPEG is extracted at high quality, so re-encoding it
+// loses little.
+func (f *FFmpeg) EncodeWebPThumbnail(ctx context.Context, jpegPath, webpPath string) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	args := []string{
+		"-i", jpegPath,
+		"-c:v", "libwebp",
+		"-quality", "80",
+		"-y", webpPath,
+	}
+
+	cmd := exec.CommandContext(ctx, f.config.FFmpegPath, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	logCommand(ctx, cmd, stderr.String(), err)
+	if err != nil {
+		return fmt.Errorf("WebP thumbnail encoding failed: %v, stderr: %s", err, stderr.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,327 @@
+package video
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// hlsVariantPlaylist is the media playlist in each rendition's directory
+const hlsVariantPlaylist = "index.m3u8"
+
+// hlsSegmentTime is the target segment length in seconds
+const hlsSegmentTime = "4"
+
+// hlsAudioGroup is the group audio renditions are listed under
+const hlsAudioGroup = "audio"
+
+// HLS segment containers
+const (
+	HLSSegmentFormatTS   = "ts"   // MPEG-TS
+	HLSSegmentFormatFMP4 = "fmp4" // Fragmented MP4 (CMAF)
+)
+
+// hlsInitSegment is the init segment of each fMP4 media playlist
+const hlsInitSegment = "init.mp4"
+
+// hlsVariant is a video rendition listed in the master playlist
+type hlsVariant struct {
+	URI              string // Media playlist, relative to the master playlist
+	Bandwidth        int64  // Peak segment bitrate in bits/s
+	AverageBandwidth int64
+	Width            int
+	Height           int
+}
+
+// hlsAudioRendition is an audio track listed in the master playlist
+type hlsAudioRendition struct {
+	URI              string
+	Track            AudioTrack
+	Bandwidth        int64
+	AverageBandwidth int64
+}
+
+// hlsRendition is a video rendition to encode, into the directory Name
+type hlsRendition struct {
+	Name   string
+	Config TranscodeConfig
+}
+
+// hlsRenditions returns the video renditions to encode: the ladder's, less
+// those that would upscale the source, or one named "video" from the global
+// settings without a ladder
+func (f *FFmpeg) hlsRenditions(ctx context.Context, inputPath string, cfg TranscodeConfig) []hlsRendition {
+	if len(cfg.Renditions) == 0 {
+		return []hlsRendition{{Name: "video", Config: cfg}}
+	}
+
+	ladder := cfg.Renditions
+	if metadata, err := f.GetMetadata(ctx, inputPath); err != nil {
+		log.Printf("Warning: failed to get source size, encoding every rendition: %v", err)
+	} else {
+		// Rotated sources are scaled in turned bounds (see scaleBounds), so
+		// compare the ladder against their stored size
+		width, height := metadata.Width, metadata.Height
+		if isQuarterTurn(metadata.Rotation) {
+			width, height = height, width
+		}
+		ladder = renditionsForSource(cfg.Renditions, width, height)
+	}
+
+	renditions := make([]hlsRendition, 0, len(ladder))
+	for _, rendition := range ladder {
+		renditions = append(renditions, hlsRendition{Name: rendition.Resolution, Config: rendition.apply(cfg)})
+	}
+	return renditions
+}
+
+// transcodeHLSRenditions encodes each video rendition into its own directory
+// (e.g. 720p/index.m3u8 and 720p/segment000.ts), then writes master.m3u8
+// listing them. With every audio track kept, the tracks are encoded as audio
+// renditions (audio_0/index.m3u8 and so on) that players can switch between,
+// and the video renditions have no audio of their own.
+func (f *FFmpeg) transcodeHLSRenditions(ctx context.Context, inputPath, outputDir string, cfg TranscodeConfig, colorInfo *ColorInfo, audio audioPlan, progress *TranscodeProgress) error {
+	renditions := f.hlsRenditions(ctx, inputPath, cfg)
+	separateAudio := audio.mode == AudioTrackModeAll
+	steps := len(renditions)
+	if separateAudio {
+		steps += len(audio.tracks)
+	}
+
+	// Each rendition and audio track is an equal share of the overall progress
+	stepProgress := func(step int) *TranscodeProgress {
+		return progress.step(step, steps)
+	}
+
+	variants := make([]hlsVariant, 0, len(renditions))
+	for i, rendition := range renditions {
+		renditionDir := filepath.Join(outputDir, rendition.Name)
+		if err := os.MkdirAll(renditionDir, 0755); err != nil {
+			return fmt.Errorf("failed to create rendition directory: %w", err)
+		}
+
+		audioArgs := []string{"-map", "0:v:0", "-an"}
+		if !separateAudio {
+			audioArgs = audio.muxedArgs(rendition.Config.AudioBitrate)
+		}
+		if err := f.transcodeHLSPlaylist(ctx, inputPath, renditionDir, hlsVariantPlaylist, rendition.Config, colorInfo, audioArgs, stepProgress(i)); err != nil {
+			return fmt.Errorf("%s rendition: %w", rendition.Name, err)
+		}
+
+		playlistPath := filepath.Join(renditionDir, hlsVariantPlaylist)
+		variant := hlsVariant{URI: rendition.Name + "/" + hlsVariantPlaylist}
+		var err error
+		variant.Bandwidth, variant.AverageBandwidth, err = measureHLSBandwidth(playlistPath)
+		if err != nil {
+			return fmt.Errorf("%s rendition: %w", rendition.Name, err)
+		}
+		metadata, err := f.GetMetadata(ctx, playlistPath)
+		if err != nil {
+			return fmt.Errorf("%s rendition: %w", rendition.Name, err)
+		}
+		variant.Width, variant.Height = metadata.Width, metadata.Height
+		variants = append(variants, variant)
+	}
+
+	var audioRenditions []hlsAudioRendition
+	if separateAudio {
+		for i, track := range audio.tracks {
+			name := fmt.Sprintf("audio_%d", i)
+			trackDir := filepath.Join(outputDir, name)
+			if err := os.MkdirAll(trackDir, 0755); err != nil {
+				return fmt.Errorf("failed to create audio directory: %w", err)
+			}
+
+			args := []string{"-map", fmt.Sprintf("0:a:%d", i)}
+			if audio.filters[i] != "" {
+				args = append(args, "-af", audio.filters[i])
+			}
+			args = append(args, "-c:a", "aac", "-b:a", cfg.AudioBitrate)
+			args = append(args, track.metadataArgs(0)...)
+			if err := f.transcodeHLSAudioPlaylist(ctx, inputPath, trackDir, args, cfg, stepProgress(len(renditions)+i)); err != nil {
+				return fmt.Errorf("audio track %d: %w", i, err)
+			}
+
+			rendition := hlsAudioRendition{URI: name + "/" + hlsVariantPlaylist, Track: track}
+			var err error
+			rendition.Bandwidth, rendition.AverageBandwidth, err = measureHLSBandwidth(filepath.Join(trackDir, hlsVariantPlaylist))
+			if err != nil {
+				return fmt.Errorf("audio track %d: %w", i, err)
+			}
+			audioRenditions = append(audioRenditions, rendition)
+		}
+	}
+
+	// DASH players can play fMP4 segments too; the video still plays over
+	// HLS without the MPD
+	if cfg.hlsSegmentFormat() == HLSSegmentFormatFMP4 {
+		if err := writeDASHManifest(outputDir, variants, audioRenditions); err != nil {
+			log.Printf("Warning: failed to write DASH manifest: %v", err)
+		}
+	}
+
+	// Written last, so the video isn't playable until every rendition is
+	if err := writeHLSMasterPlaylist(filepath.Join(outputDir, "master.m3u8"), variants, audioRenditions); err != nil {
+		return err
+	}
+
+	log.Printf("HLS transcoding completed with %d renditions and %d audio renditions: %s", len(variants), len(audioRenditions), outputDir)
+	return nil
+}
+
+// transcodeHLSAudioPlaylist encodes an audio-only media playlist into
+// outputDir, segmented like the video. audioArgs map and encode the track.
+func (f *FFmpeg) transcodeHLSAudioPlaylist(ctx context.Context, inputPath, outputDir string, audioArgs []string, cfg TranscodeConfig, progress *TranscodeProgress) error {
+	ctx, cancel := context.WithTimeout(ctx, f.config.Timeout)
+	defer cancel()
+
+	args := []string{"-nostats", "-progress", "pipe:1", "-i", inputPath}
+	args = append(args, audioArgs...)
+	args = append(args,
+		"-f", "hls",
+		"-hls_time", hlsSegmentTime,
+		"-hls_list_size", "0",
+	)
+	args = append(args, hlsSegmentArgs(outputDir, cfg)...)
+	args = append(args, "-y", filepath.Join(outputDir, hlsVariantPlaylist))
+
+	cmd := exec.CommandContext(ctx, f.config.FFmpegPath, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	err := runWithProgress(cmd, progress)
+	logCommand(ctx, cmd, stderr.String(), err)
+	if err != nil {
+		return fmt.Errorf("HLS audio transcoding failed: %v, stderr: %s", err, stderr.String())
+	}
+	return nil
+}
+
+// hlsSegmentFormat returns the segment container cfg muxes HLS into. HEVC
+// and AV1 are always segmented into fMP4, as players don't take them in
+// MPEG-TS.
+func (cfg TranscodeConfig) hlsSegmentFormat() string {
+	if cfg.HLSSegmentFormat == HLSSegmentFormatFMP4 || cfg.OutputCodec() != VideoCodecH264 {
+		return HLSSegmentFormatFMP4
+	}
+	return HLSSegmentFormatTS
+}
+
+// hlsSegmentArgs returns the segment muxing args for a media playlist in
+// outputDir: segment000.ts, ... for MPEG-TS, or an init.mp4 init segment and
+// segment000.m4s, ... for fMP4
+func hlsSegmentArgs(outputDir string, cfg TranscodeConfig) []string {
+	if cfg.hlsSegmentFormat() == HLSSegmentFormatTS {
+		return []string{
+			"-hls_segment_type", "mpegts",
+			"-hls_segment_filename", filepath.Join(outputDir, "segment%03d.ts"),
+		}
+	}
+	return []string{
+		"-hls_segment_type", "fmp4",
+		"-hls_fmp4_init_filename", hlsInitSegment,
+		"-hls_segment_filename", filepath.Join(outputDir, "segment%03d.m4s"),
+	}
+}
+
+// measureHLSBandwidth returns the peak and average bitrate of a media
+// playlist's segments, from their sizes and durations
+func measureHLSBandwidth(playlistPath string) (peak, average int64, err error) {
+	file, err := os.Open(playlistPath)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to open playlist: %w", err)
+	}
+	defer file.Close()
+
+	var totalBits, totalSeconds float64
+	var duration float64
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if value, ok := strings.CutPrefix(line, "#EXTINF:"); ok {
+			value, _, _ = strings.Cut(value, ",")
+			duration, _ = strconv.ParseFloat(value, 64)
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") || duration <= 0 {
+			continue
+		}
+
+		info, err := os.Stat(filepath.Join(filepath.Dir(playlistPath), line))
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to stat segment: %w", err)
+		}
+		bits := float64(info.Size() * 8)
+		peak = max(peak, int64(bits/duration))
+		totalBits += bits
+		totalSeconds += duration
+		duration = 0
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, 0, fmt.Errorf("failed to read playlist: %w", err)
+	}
+	if totalSeconds == 0 {
+		return 0, 0, fmt.Errorf("playlist has no segments")
+	}
+
+	return peak, int64(totalBits / totalSeconds), nil
+}
+
+// writeHLSMasterPlaylist writes a master playlist listing the variants, and
+// the audio renditions they share if there are any. It's written to a
+// temporary file and renamed, so it's never seen half-written.
+func writeHLSMasterPlaylist(path string, variants []hlsVariant, audioRenditions []hlsAudioRendition) error {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:3\n")
+
+	// A variant's bandwidth includes the largest audio rendition it may be played with
+	var audioBandwidth, audioAverageBandwidth int64
+	for i, a := range audioRenditions {
+		fmt.Fprintf(&b, "#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID=%s,NAME=%s,", hlsQuote(hlsAudioGroup), hlsQuote(a.Track.name(i)))
+		if a.Track.Language != "" && a.Track.Language != "und" {
+			fmt.Fprintf(&b, "LANGUAGE=%s,", hlsQuote(a.Track.Language))
+		}
+		if i == 0 {
+			b.WriteString("DEFAULT=YES,")
+		} else {
+			b.WriteString("DEFAULT=NO,")
+		}
+		fmt.Fprintf(&b, "AUTOSELECT=YES,URI=%s\n", hlsQuote(a.URI))
+		audioBandwidth = max(audioBandwidth, a.Bandwidth)
+		audioAverageBandwidth = max(audioAverageBandwidth, a.AverageBandwidth)
+	}
+
+	for _, v := range variants {
+		fmt.Fprintf(&b, "#EXT-X-STREAM-INF:BANDWIDTH=%d,AVERAGE-BANDWIDTH=%d,RESOLUTION=%dx%d",
+			v.Bandwidth+audioBandwidth, v.AverageBandwidth+audioAverageBandwidth, v.Width, v.Height)
+		if len(audioRenditions) > 0 {
+			fmt.Fprintf(&b, ",AUDIO=%s", hlsQuote(hlsAudioGroup))
+		}
+		fmt.Fprintf(&b, "\n%s\n", v.URI)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write master playlist: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write master playlist: %w", err)
+	}
+	return nil
+}
+
+// hlsQuoteReplacer drops the characters a playlist's quoted strings can't hold
+var hlsQuoteReplacer = strings.NewReplacer(`"`, "'", "\r", " ", "\n", " ")
+
+// hlsQuote makes a quoted-string playlist attribute value
+func hlsQuote(s string) string {
+	return `"` + hlsQuoteReplacer.Replace(s) + `"`
+}
@@ -0,0 +1,65 @@
+package video
+
+import (
+	"context"
+	"fmt"
+)
+
+// nvdecCodecs are the source codecs NVDEC can decode. Profiles it can't
+// (e.g. 4:2:2 H.264) fail the transcode, which is then retried in software.
+var nvdecCodecs = map[string]bool{
+	"h264":       true,
+	"hevc":       true,
+	"av1":        true,
+	"vp8":        true,
+	"vp9":        true,
+	"mpeg2video": true,
+	"mpeg4":      true,
+	"vc1":        true,
+}
+
+// hwDecodeUnsupported returns why a source can't be decoded on the GPU with
+// cfg, or "" if it can. Decoded frames stay in GPU memory up to the encoder,
+// so it needs NVENC, and a source needing no filter but scaling: ffmpeg's
+// autorotation and the full-range scaling run on the CPU.
+func hwDecodeUnsupported(cfg TranscodeConfig, colorInfo *ColorInfo) string {
+	switch {
+	case !cfg.UseGPU || (cfg.GPUBackend != "" && cfg.GPUBackend != GPUBackendNVENC):
+		return "needs NVENC transcoding"
+	case colorInfo == nil || colorInfo.Codec == "":
+		return "source codec unknown"
+	case !nvdecCodecs[colorInfo.Codec]:
+		return fmt.Sprintf("NVDEC can't decode %s", colorInfo.Codec)
+	case colorInfo.Rotation != 0:
+		return "source is rotated"
+	case isFullColorRange(colorInfo):
+		return "source is full range"
+	}
+	return ""
+}
+
+// planDecode decides whether a transcode with hardware decoding on decodes
+// on the GPU, logs the pipeline picked, and returns cfg with HWDecode set to
+// match
+func planDecode(ctx context.Context, cfg TranscodeConfig, colorInfo *ColorInfo) TranscodeConfig {
+	if !cfg.HWDecode {
+		return cfg
+	}
+
+	encoder := CodecEncoder(cfg.VideoCodec, cfg.UseGPU, cfg.GPUBackend)
+	if reason := hwDecodeUnsupported(cfg, colorInfo); reason != "" {
+		logNote(ctx, "Decode pipeline: software decode -> scale -> %s (hardware decoding skipped: %s)", encoder, reason)
+		cfg.HWDecode = false
+		return cfg
+	}
+	logNote(ctx, "Decode pipeline: NVDEC %s -> scale_cuda -> %s", colorInfo.Codec, encoder)
+	return cfg
+}
+
+// buildCUDAScaleFilter is buildScaleFilter for frames decoded into GPU
+// memory. It also converts 10-bit sources to the 8-bit 4:2:0 the encoders
+// take.
+func buildCUDAScaleFilter(maxWidth, maxHeight int) string {
+	return fmt.Sprintf("scale_cuda=w='min(%d,iw)':h='min(%d,ih)':force_original_aspect_ratio=decrease:format=yuv420p",
+		maxWidth, maxHeight)
+}
@@ -0,0 +1,102 @@
+package video
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+)
+
+// Integrated loudness targets accepted by ffmpeg's loudnorm filter, in LUFS
+const (
+	MinLoudnessTarget     = -70
+	MaxLoudnessTarget     = -5
+	DefaultLoudnessTarget = -16
+)
+
+// Fixed loudnorm settings: the EBU R128 true peak ceiling and loudness range
+const (
+	loudnessTruePeak = -1.5
+	loudnessRange    = 11
+)
+
+// loudnessMeasurement is the first loudnorm pass's analysis of the input.
+// loudnorm prints the values as strings.
+type loudnessMeasurement struct {
+	InputI       string `json:"input_i"`
+	InputTP      string `json:"input_tp"`
+	InputLRA     string `json:"input_lra"`
+	InputThresh  string `json:"input_thresh"`
+	TargetOffset string `json:"target_offset"`
+}
+
+// loudnessFilter returns the audio filter normalizing the input's loudness to
+// cfg.LoudnessTarget, or "" with normalization off. graph is the audio it's
+// applied to: a filter graph like "[0:a:1]", or "" for the audio stream ffmpeg
+// picks by default. The audio is measured first so the second pass can apply
+// a linear gain; if that fails (e.g. the input has no audio), the audio is
+// left as it is.
+func (f *FFmpeg) loudnessFilter(ctx context.Context, inputPath, graph string, cfg TranscodeConfig) string {
+	if !cfg.NormalizeAudio {
+		return ""
+	}
+
+	target := fmt.Sprintf("I=%d:TP=%g:LRA=%d", cfg.LoudnessTarget, loudnessTruePeak, loudnessRange)
+	measured, err := f.measureLoudness(ctx, inputPath, graph, target)
+	if err != nil {
+		log.Printf("Warning: loudness analysis failed, not normalizing audio: %v", err)
+		return ""
+	}
+
+	// loudnorm resamples to 192kHz internally, so resample back for AAC
+	return fmt.Sprintf("loudnorm=%s:measured_I=%s:measured_TP=%s:measured_LRA=%s:measured_thresh=%s:offset=%s:linear=true,aresample=48000",
+		target, measured.InputI, measured.InputTP, measured.InputLRA, measured.InputThresh, measured.TargetOffset)
+}
+
+// measureLoudness runs loudnorm's analysis pass over the audio graph
+// describes (see loudnessFilter)
+func (f *FFmpeg) measureLoudness(ctx context.Context, inputPath, graph, target string) (*loudnessMeasurement, error) {
+	ctx, cancel := context.WithTimeout(ctx, f.config.Timeout)
+	defer cancel()
+
+	analysis := "loudnorm=" + target + ":print_format=json"
+	args := []string{"-hide_banner", "-nostats", "-i", inputPath}
+	if graph == "" {
+		args = append(args, "-vn", "-af", analysis)
+	} else {
+		args = append(args, "-filter_complex", appendFilter(graph, analysis))
+	}
+	args = append(args, "-f", "null", "-")
+
+	cmd := exec.CommandContext(ctx, f.config.FFmpegPath, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	logCommand(ctx, cmd, stderr.String(), err)
+	if err != nil {
+		return nil, fmt.Errorf("%v, stderr: %s", err, stderr.String())
+	}
+
+	// The measurement is the JSON object at the end of the output
+	output := stderr.String()
+	start, end := strings.LastIndex(output, "{"), strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no loudness measurement in output")
+	}
+	var measured loudnessMeasurement
+	if err := json.Unmarshal([]byte(output[start:end+1]), &measured); err != nil {
+		return nil, fmt.Errorf("failed to parse loudness measurement: %w", err)
+	}
+	// Silent input measures -inf, which the second pass rejects
+	for _, value := range []string{measured.InputI, measured.InputTP, measured.InputLRA, measured.InputThresh, measured.TargetOffset} {
+		if value == "" || strings.Contains(value, "inf") {
+			return nil, fmt.Errorf("unusable loudness measurement %q", value)
+		}
+	}
+
+	return &measured, nil
+}
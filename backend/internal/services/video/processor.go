@@ -2,12 +2,15 @@ package video
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/clipset/clipset-go/internal/services/storage"
 )
 
 // ProcessResult contains the result of video processing
@@ -20,6 +23,8 @@ type ProcessResult struct {
 	Codec        string // Video codec
 	OutputFormat string // "hls" or "progressive"
 	FileSize     int64  // Final file size in bytes
+	AudioTracks  int    // Audio tracks in the output; -1 if they couldn't be counted
+	VideoCodec   string // Codec of the output video
 }
 
 // Processor handles the complete video processing pipeline
@@ -28,30 +33,47 @@ type Processor struct {
 	tempPath      string
 	videoPath     string
 	thumbnailPath string
+
+	processTimeout    time.Duration
+	timeoutBase       time.Duration
+	timeoutFactor     float64
+	maxProcessTimeout time.Duration
 }
 
-// ProcessorConfig holds configuration for the video processor
+// ProcessorConfig holds configuration for the video processor. A transcode
+// may run for TimeoutBase plus TimeoutFactor times the video's duration, up
+// to MaxProcessTimeout; ProcessTimeout is used when the duration is unknown.
 type ProcessorConfig struct {
-	FFmpegPath     string
-	FFprobePath    string
-	ProcessTimeout time.Duration
-	TempPath       string
-	VideoPath      string
-	ThumbnailPath  string
+	FFmpegPath        string
+	FFprobePath       string
+	ProcessTimeout    time.Duration
+	TimeoutBase       time.Duration
+	TimeoutFactor     float64
+	MaxProcessTimeout time.Duration
+	TempPath          string
+	VideoPath         string
+	ThumbnailPath     string
 }
 
 // NewProcessor creates a new video processor
 func NewProcessor(cfg ProcessorConfig) *Processor {
+	// Each FFmpeg command is only capped by the longest job allowed; the
+	// transcode's own timeout is applied by the processor
 	ffmpegCfg := FFmpegConfig{
 		FFmpegPath:  cfg.FFmpegPath,
 		FFprobePath: cfg.FFprobePath,
+		Timeout:     cfg.MaxProcessTimeout,
 	}
 
 	return &Processor{
-		ffmpeg:        NewFFmpeg(ffmpegCfg),
-		tempPath:      cfg.TempPath,
-		videoPath:     cfg.VideoPath,
-		thumbnailPath: cfg.ThumbnailPath,
+		ffmpeg:            NewFFmpeg(ffmpegCfg),
+		tempPath:          cfg.TempPath,
+		videoPath:         cfg.VideoPath,
+		thumbnailPath:     cfg.ThumbnailPath,
+		processTimeout:    cfg.ProcessTimeout,
+		timeoutBase:       cfg.TimeoutBase,
+		timeoutFactor:     cfg.TimeoutFactor,
+		maxProcessTimeout: cfg.MaxProcessTimeout,
 	}
 }
 
@@ -62,13 +84,23 @@ func NewProcessor(cfg ProcessorConfig) *Processor {
 // 2. Extract metadata
 // 3. Transcode based on output format config (hls or progressive)
 // 4. Extract thumbnail
-func (p *Processor) ProcessVideo(ctx context.Context, inputPath, outputFilename, thumbnailFilename string, transcodeCfg TranscodeConfig, outputFormat string) (*ProcessResult, error) {
+//
+// onProgress, if set, is called as each step starts and with the transcode
+// percentage while transcoding.
+func (p *Processor) ProcessVideo(ctx context.Context, inputPath, outputFilename, thumbnailFilename string, transcodeCfg TranscodeConfig, outputFormat string, onProgress ProgressFunc) (*ProcessResult, error) {
 	result := &ProcessResult{
 		Success:      false,
 		OutputFormat: outputFormat,
 	}
 
+	report := func(phase string, percent float64) {
+		if onProgress != nil {
+			onProgress(phase, percent)
+		}
+	}
+
 	// 1. Validate video file
+	report(PhaseProbing, 0)
 	valid, errMsg := p.ffmpeg.ValidateVideo(ctx, inputPath)
 	if !valid {
 		result.Error = errMsg
@@ -96,14 +128,27 @@ func (p *Processor) ProcessVideo(ctx context.Context, inputPath, outputFilename,
 			ColorTransfer:  metadata.ColorTransfer,
 			ColorPrimaries: metadata.ColorPrimaries,
 			PixFmt:         metadata.PixFmt,
+			Rotation:       metadata.Rotation,
+			Codec:          metadata.Codec,
 		}
 	}
 
 	// 3. Transcode based on output format
+	report(PhaseTranscoding, 0)
+	var progress *TranscodeProgress
+	if metadata != nil {
+		progress = &TranscodeProgress{
+			Duration: time.Duration(metadata.Duration) * time.Second,
+			Report: func(percent float64) {
+				report(PhaseTranscoding, percent)
+			},
+		}
+	}
+
 	if outputFormat == "hls" {
 		// HLS output - create directory with segments
 		// outputFilename is used as the directory name (stem without extension)
-		hlsDir := filepath.Join(p.videoPath, stemWithoutExt(outputFilename))
+		hlsDir := storage.ShardedPath(p.videoPath, stemWithoutExt(outputFilename))
 		if err := os.MkdirAll(hlsDir, 0755); err != nil {
 			result.Error = fmt.Sprintf("Failed to create HLS directory: %v", err)
 			return result, fmt.Errorf("failed to create HLS directory: %w", err)
@@ -111,7 +156,10 @@ func (p *Processor) ProcessVideo(ctx context.Context, inputPath, outputFilename,
 
 		log.Printf("Processing video for HLS output: %s -> %s", inputPath, hlsDir)
 
-		if err := p.ffmpeg.TranscodeHLS(ctx, inputPath, hlsDir, transcodeCfg, colorInfo); err != nil {
+		err := p.withTranscodeTimeout(ctx, result.Duration, func(ctx context.Context) error {
+			return p.ffmpeg.TranscodeHLS(ctx, inputPath, hlsDir, transcodeCfg, colorInfo, progress)
+		})
+		if err != nil {
 			result.Error = fmt.Sprintf("HLS transcoding failed: %v", err)
 			return result, fmt.Errorf("HLS transcoding failed: %w", err)
 		}
@@ -125,21 +173,39 @@ func (p *Processor) ProcessVideo(ctx context.Context, inputPath, outputFilename,
 		}
 
 		result.OutputFormat = "hls"
+		result.VideoCodec = transcodeCfg.OutputCodec()
+		result.AudioTracks = p.countAudioTracks(ctx, hlsDir)
 
 	} else {
 		// Progressive output - single MP4 file
-		outputPath := filepath.Join(p.videoPath, ensureMP4Ext(outputFilename))
+		outputPath := storage.ShardedPath(p.videoPath, ensureMP4Ext(outputFilename))
+		if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+			result.Error = fmt.Sprintf("Failed to create video directory: %v", err)
+			return result, fmt.Errorf("failed to create video directory: %w", err)
+		}
 
-		// Check if we need to transcode
-		needsTranscode := p.ffmpeg.NeedsTranscoding(ctx, inputPath)
+		// Check if we need to transcode. Normalizing audio always does.
+		needsTranscode := transcodeCfg.NormalizeAudio || p.ffmpeg.NeedsTranscoding(ctx, inputPath)
+
+		// With a rendition ladder, each rendition is also encoded as a lower
+		// quality file; the main file is an equal share of the progress
+		var renditions []hlsRendition
+		if len(transcodeCfg.Renditions) > 0 {
+			renditions = p.ffmpeg.hlsRenditions(ctx, inputPath, transcodeCfg)
+		}
+		steps := len(renditions) + 1
 
 		if needsTranscode {
 			log.Printf("Processing video for progressive output: %s -> %s", inputPath, outputPath)
 
-			if err := p.ffmpeg.TranscodeProgressiveMP4(ctx, inputPath, outputPath, transcodeCfg, colorInfo); err != nil {
+			err := p.withTranscodeTimeout(ctx, result.Duration, func(ctx context.Context) error {
+				return p.ffmpeg.TranscodeProgressiveMP4(ctx, inputPath, outputPath, transcodeCfg, colorInfo, progress.step(0, steps))
+			})
+			if err != nil {
 				result.Error = fmt.Sprintf("Progressive transcoding failed: %v", err)
 				return result, fmt.Errorf("progressive transcoding failed: %w", err)
 			}
+			result.VideoCodec = transcodeCfg.OutputCodec()
 		} else {
 			// Video is already compatible, just copy it
 			log.Printf("Video already compatible, copying: %s -> %s", inputPath, outputPath)
@@ -147,6 +213,7 @@ func (p *Processor) ProcessVideo(ctx context.Context, inputPath, outputFilename,
 				result.Error = fmt.Sprintf("Failed to copy video: %v", err)
 				return result, fmt.Errorf("failed to copy video: %w", err)
 			}
+			result.VideoCodec = VideoCodecH264
 		}
 
 		// Get output file size
@@ -157,11 +224,28 @@ func (p *Processor) ProcessVideo(ctx context.Context, inputPath, outputFilename,
 			result.FileSize = info.Size()
 		}
 
+		if len(renditions) > 0 {
+			// Lower qualities keep the main file's codec, even when it was copied
+			qualityDir := storage.ShardedPath(p.videoPath, stemWithoutExt(outputFilename))
+			for i := range renditions {
+				renditions[i].Config.VideoCodec = result.VideoCodec
+			}
+			p.transcodeQualities(ctx, inputPath, qualityDir, renditions, colorInfo, result.Duration, progress, steps)
+
+			if size, err := calculateDirSize(qualityDir); err != nil {
+				log.Printf("Warning: failed to calculate quality files size: %v", err)
+			} else {
+				result.FileSize += size
+			}
+		}
+
 		result.OutputFormat = "progressive"
+		result.AudioTracks = p.countAudioTracks(ctx, outputPath)
 	}
 
 	// 4. Extract thumbnail
-	thumbnailPath := filepath.Join(p.thumbnailPath, thumbnailFilename)
+	report(PhaseThumbnail, 100)
+	thumbnailPath := storage.ShardedPath(p.thumbnailPath, thumbnailFilename)
 	if err := os.MkdirAll(filepath.Dir(thumbnailPath), 0755); err != nil {
 		log.Printf("Warning: failed to create thumbnail directory: %v", err)
 	}
@@ -169,15 +253,21 @@ func (p *Processor) ProcessVideo(ctx context.Context, inputPath, outputFilename,
 	// For HLS, extract from input; for progressive, extract from output
 	thumbnailSource := inputPath
 	if outputFormat != "hls" {
-		outputPath := filepath.Join(p.videoPath, ensureMP4Ext(outputFilename))
+		outputPath := storage.ShardedPath(p.videoPath, ensureMP4Ext(outputFilename))
 		if _, err := os.Stat(outputPath); err == nil {
 			thumbnailSource = outputPath
 		}
 	}
 
-	if err := p.ffmpeg.ExtractThumbnail(ctx, thumbnailSource, thumbnailPath, 1.0); err != nil {
+	if err := p.ffmpeg.ExtractRepresentativeThumbnail(ctx, thumbnailSource, thumbnailPath, float64(result.Duration)); err != nil {
 		log.Printf("Warning: thumbnail extraction failed (non-critical): %v", err)
 		// Continue - thumbnail is non-critical
+	} else {
+		// Without the WebP variant, the JPEG is served to every browser
+		webpPath := storage.ShardedPath(p.thumbnailPath, storage.WebPThumbnailName(thumbnailFilename))
+		if err := p.ffmpeg.EncodeWebPThumbnail(ctx, thumbnailPath, webpPath); err != nil {
+			log.Printf("Warning: %v", err)
+		}
 	}
 
 	result.Success = true
@@ -186,6 +276,119 @@ func (p *Processor) ProcessVideo(ctx context.Context, inputPath, outputFilename,
 	return result, nil
 }
 
+// transcodeQualities encodes each rendition as a lower quality progressive
+// file in dir, named by storage.ProgressiveQualityName. They take the
+// progress steps after the first of steps. A rendition that fails is left
+// out, as streams fall back to the main file.
+func (p *Processor) transcodeQualities(ctx context.Context, inputPath, dir string, renditions []hlsRendition, colorInfo *ColorInfo, duration int, progress *TranscodeProgress, steps int) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Printf("Warning: failed to create quality directory: %v", err)
+		return
+	}
+
+	for i, rendition := range renditions {
+		if ctx.Err() != nil {
+			return
+		}
+
+		outputPath := filepath.Join(dir, storage.ProgressiveQualityName(rendition.Name))
+		log.Printf("Encoding %s quality: %s -> %s", rendition.Name, inputPath, outputPath)
+		err := p.withTranscodeTimeout(ctx, duration, func(ctx context.Context) error {
+			return p.ffmpeg.TranscodeProgressiveMP4(ctx, inputPath, outputPath, rendition.Config, colorInfo, progress.step(i+1, steps))
+		})
+		if err != nil {
+			log.Printf("Warning: %s quality failed, streams fall back to the main file: %v", rendition.Name, err)
+			os.Remove(outputPath)
+		}
+	}
+}
+
+// ConvertToHLS transcodes an existing progressive MP4 into HLS segments in
+// outputDir. Returns the total size of the HLS files.
+func (p *Processor) ConvertToHLS(ctx context.Context, inputPath, outputDir string, transcodeCfg TranscodeConfig) (int64, error) {
+	// Keep the source color range when re-encoding
+	var colorInfo *ColorInfo
+	var duration int
+	if metadata, err := p.ffmpeg.GetMetadata(ctx, inputPath); err != nil {
+		log.Printf("Warning: failed to extract metadata: %v", err)
+	} else {
+		duration = metadata.Duration
+		colorInfo = &ColorInfo{
+			ColorRange:     metadata.ColorRange,
+			ColorSpace:     metadata.ColorSpace,
+			ColorTransfer:  metadata.ColorTransfer,
+			ColorPrimaries: metadata.ColorPrimaries,
+			PixFmt:         metadata.PixFmt,
+			Rotation:       metadata.Rotation,
+			Codec:          metadata.Codec,
+		}
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create HLS directory: %w", err)
+	}
+
+	log.Printf("Converting video to HLS: %s -> %s", inputPath, outputDir)
+
+	err := p.withTranscodeTimeout(ctx, duration, func(ctx context.Context) error {
+		return p.ffmpeg.TranscodeHLS(ctx, inputPath, outputDir, transcodeCfg, colorInfo, nil)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("HLS transcoding failed: %w", err)
+	}
+
+	totalSize, err := calculateDirSize(outputDir)
+	if err != nil {
+		log.Printf("Warning: failed to calculate HLS size: %v", err)
+	}
+	return totalSize, nil
+}
+
+// transcodeTimeout returns how long transcoding a video of the given
+// duration in seconds may take
+func (p *Processor) transcodeTimeout(durationSec int) time.Duration {
+	timeout := p.processTimeout
+	if durationSec > 0 {
+		timeout = p.timeoutBase + time.Duration(float64(durationSec)*p.timeoutFactor*float64(time.Second))
+	}
+	if p.maxProcessTimeout > 0 {
+		timeout = min(timeout, p.maxProcessTimeout)
+	}
+	return timeout
+}
+
+// withTranscodeTimeout runs transcode with the timeout for the video's
+// duration. Running out of time is reported as such, rather than as FFmpeg
+// being killed.
+func (p *Processor) withTranscodeTimeout(ctx context.Context, durationSec int, transcode func(ctx context.Context) error) error {
+	timeout := p.transcodeTimeout(durationSec)
+	if timeout <= 0 {
+		return transcode(ctx)
+	}
+
+	transcodeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := transcode(transcodeCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(transcodeCtx.Err(), context.DeadlineExceeded) {
+		if durationSec > 0 {
+			return fmt.Errorf("timed out after %s for %d-second video", timeout, durationSec)
+		}
+		return fmt.Errorf("timed out after %s for video of unknown duration", timeout)
+	}
+	return err
+}
+
+// countAudioTracks counts the audio tracks of an output, or returns -1
+func (p *Processor) countAudioTracks(ctx context.Context, outputPath string) int {
+	count, err := p.ffmpeg.CountOutputAudioTracks(ctx, outputPath)
+	if err != nil {
+		log.Printf("Warning: failed to count audio tracks: %v", err)
+		return -1
+	}
+	return count
+}
+
 // GetFFmpeg returns the underlying FFmpeg service for direct access
 func (p *Processor) GetFFmpeg() *FFmpeg {
 	return p.ffmpeg
@@ -0,0 +1,125 @@
+package video
+
+import (
+	"bufio"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Processing phases reported while a video is processed
+const (
+	PhaseProbing     = "probing"
+	PhaseTranscoding = "transcoding"
+	PhaseThumbnail   = "thumbnail"
+	PhaseFinalizing  = "finalizing"
+)
+
+// progressReportInterval throttles transcode progress updates; ffmpeg reports
+// several times a second
+const progressReportInterval = 2 * time.Second
+
+// ProgressFunc receives the current processing phase and the transcode
+// percentage (0-100)
+type ProgressFunc func(phase string, percent float64)
+
+// TranscodeProgress reports how far a transcode has got. Duration is the
+// input's length, which ffmpeg's output timestamp is measured against.
+type TranscodeProgress struct {
+	Duration time.Duration
+	Report   func(percent float64)
+}
+
+// step returns the progress of one of steps equal shares of p, the first
+// being step 0. It's nil if p is.
+func (p *TranscodeProgress) step(step, steps int) *TranscodeProgress {
+	if p == nil || p.Report == nil {
+		return nil
+	}
+	done, total := float64(step), float64(steps)
+	return &TranscodeProgress{
+		Duration: p.Duration,
+		Report: func(percent float64) {
+			p.Report((done*100 + percent) / total)
+		},
+	}
+}
+
+// ProcessingProgress is the latest progress reported for a video
+type ProcessingProgress struct {
+	Phase     string
+	Percent   float64
+	UpdatedAt time.Time
+}
+
+var (
+	progressMu         sync.Mutex
+	processingProgress = make(map[string]ProcessingProgress)
+)
+
+// runWithProgress runs an ffmpeg command started with -progress pipe:1 and
+// reports out_time_ms against the input duration, at most once every
+// progressReportInterval. Without a usable progress it just runs the command.
+func runWithProgress(cmd *exec.Cmd, progress *TranscodeProgress) error {
+	if progress == nil || progress.Report == nil || progress.Duration <= 0 {
+		return cmd.Run()
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	var lastReport time.Time
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		// Despite the name, out_time_ms is in microseconds. It's N/A until
+		// the first frame is written.
+		value, ok := strings.CutPrefix(scanner.Text(), "out_time_ms=")
+		if !ok {
+			continue
+		}
+		outTime, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil || outTime < 0 || time.Since(lastReport) < progressReportInterval {
+			continue
+		}
+		lastReport = time.Now()
+		progress.Report(min(100, float64(outTime)/float64(progress.Duration.Microseconds())*100))
+	}
+	// Drain the rest so ffmpeg never blocks on a full pipe
+	io.Copy(io.Discard, stdout)
+
+	return cmd.Wait()
+}
+
+// SetProcessingProgress records the progress of a video being processed
+func SetProcessingProgress(videoID, phase string, percent float64) {
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	processingProgress[videoID] = ProcessingProgress{
+		Phase:     phase,
+		Percent:   percent,
+		UpdatedAt: time.Now(),
+	}
+}
+
+// ClearProcessingProgress forgets a video's progress once processing has ended
+func ClearProcessingProgress(videoID string) {
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	delete(processingProgress, videoID)
+}
+
+// GetProcessingProgress returns a video's progress, if it's being processed
+func GetProcessingProgress(videoID string) (ProcessingProgress, bool) {
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	progress, ok := processingProgress[videoID]
+	return progress, ok
+}
@@ -0,0 +1,129 @@
+package video
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// MaxRenditions is the most entries a rendition ladder may have
+const MaxRenditions = 6
+
+// RenditionHeights maps the resolutions a rendition can use to their height
+var RenditionHeights = map[string]int{
+	"360p":  360,
+	"480p":  480,
+	"720p":  720,
+	"1080p": 1080,
+	"1440p": 1440,
+	"4k":    2160,
+}
+
+// RenditionResolutions lists the keys of RenditionHeights, smallest first
+var RenditionResolutions = []string{"360p", "480p", "720p", "1080p", "1440p", "4k"}
+
+// Rendition is one entry of the bitrate ladder. Unset fields fall back to
+// the global transcoding settings.
+type Rendition struct {
+	Resolution   string `json:"resolution"`              // A key of RenditionHeights
+	VideoBitrate string `json:"video_bitrate,omitempty"` // Bitrate cap, e.g. 5M
+	CRF          *int   `json:"crf,omitempty"`           // libx264 quality
+	CQ           *int   `json:"cq,omitempty"`            // Hardware encoder quality
+	AudioBitrate string `json:"audio_bitrate,omitempty"` // e.g. 128k
+}
+
+// Dimensions returns the box a rendition is scaled to fit, assuming 16:9
+func (r Rendition) Dimensions() (int, int) {
+	height := RenditionHeights[r.Resolution]
+	return height * 16 / 9, height
+}
+
+// ParseRenditionLadder decodes the ladder stored in the config table. An
+// empty ladder means a single rendition from the global settings.
+func ParseRenditionLadder(data []byte) ([]Rendition, error) {
+	ladder := []Rendition{}
+	if len(data) == 0 {
+		return ladder, nil
+	}
+	if err := json.Unmarshal(data, &ladder); err != nil {
+		return []Rendition{}, fmt.Errorf("invalid rendition ladder: %w", err)
+	}
+	return ladder, nil
+}
+
+// apply returns cfg with the rendition's size and overrides. A video bitrate
+// caps both the CPU and hardware encoders, with a buffer of twice the cap.
+func (r Rendition) apply(cfg TranscodeConfig) TranscodeConfig {
+	cfg.MaxWidth, cfg.MaxHeight = r.Dimensions()
+	if r.VideoBitrate != "" {
+		cfg.CPUMaxBitrate = r.VideoBitrate
+		cfg.NVENCMaxBitrate = r.VideoBitrate
+		if bits, ok := parseBitrate(r.VideoBitrate); ok {
+			cfg.NVENCBufferSize = fmt.Sprintf("%dk", bits*2/1000)
+		}
+	}
+	if r.CRF != nil {
+		cfg.CPUCRF = *r.CRF
+	}
+	if r.CQ != nil {
+		cfg.NVENCCQ = *r.CQ
+	}
+	if r.AudioBitrate != "" {
+		cfg.AudioBitrate = r.AudioBitrate
+	}
+	cfg.Renditions = nil
+	return cfg
+}
+
+// renditionsForSource drops the renditions that would upscale a source of the
+// given size. Renditions whose box holds the whole source all encode it at its
+// own size, so only the smallest of them is kept. An unknown size keeps all.
+func renditionsForSource(ladder []Rendition, width, height int) []Rendition {
+	if width <= 0 || height <= 0 {
+		return ladder
+	}
+
+	smallestContaining := -1
+	for i, r := range ladder {
+		w, h := r.Dimensions()
+		if w < width || h < height {
+			continue
+		}
+		if smallestContaining < 0 || h < RenditionHeights[ladder[smallestContaining].Resolution] {
+			smallestContaining = i
+		}
+	}
+
+	kept := make([]Rendition, 0, len(ladder))
+	for i, r := range ladder {
+		w, h := r.Dimensions()
+		if (w >= width && h >= height) && i != smallestContaining {
+			continue
+		}
+		kept = append(kept, r)
+	}
+	return kept
+}
+
+// parseBitrate converts a bitrate like 8M or 2500k to bits per second
+func parseBitrate(s string) (int64, bool) {
+	multiplier := int64(1)
+	if s != "" {
+		switch s[len(s)-1] {
+		case 'k', 'K':
+			multiplier = 1000
+		case 'm', 'M':
+			multiplier = 1000 * 1000
+		case 'g', 'G':
+			multiplier = 1000 * 1000 * 1000
+		}
+	}
+	if multiplier > 1 {
+		s = s[:len(s)-1]
+	}
+	value, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || value < 0 {
+		return 0, false
+	}
+	return value * multiplier, true
+}
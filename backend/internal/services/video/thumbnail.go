@@ -0,0 +1,148 @@
+package video
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os/exec"
+	"time"
+)
+
+// thumbnailCandidates are where frames are sampled, as fractions of the
+// duration. The start is skipped, as clips often fade in from black.
+var thumbnailCandidates = []float64{0.1, 0.25, 0.4, 0.55, 0.7}
+
+// Candidate frames are analysed as grayscale at this size
+const (
+	thumbnailSampleWidth  = 256
+	thumbnailSampleHeight = 144
+)
+
+// A frame darker on average than thumbnailMinLuma, or whose luma varies less
+// than thumbnailMinStdDev (a fade or a flat color), is only picked if every
+// candidate is
+const (
+	thumbnailMinLuma   = 24
+	thumbnailMinStdDev = 10
+)
+
+// frameStats describes a candidate frame's luma
+type frameStats struct {
+	Mean      float64
+	Variance  float64
+	Sharpness float64 // Variance of the Laplacian; low for blurry frames
+}
+
+// usable reports whether the frame is neither near-black nor flat
+func (s frameStats) usable() bool {
+	return s.Mean >= thumbnailMinLuma && s.Variance >= thumbnailMinStdDev*thumbnailMinStdDev
+}
+
+// ExtractRepresentativeThumbnail extracts a thumbnail from the sharpest of
+// several frames spread over the video, passing over black and flat frames.
+// If the frames can't be analysed, it falls back to the frame at 1 second.
+func (f *FFmpeg) ExtractRepresentativeThumbnail(ctx context.Context, videoPath, thumbnailPath string, duration float64) error {
+	timestamp, err := f.pickThumbnailTimestamp(ctx, videoPath, duration)
+	if err != nil {
+		log.Printf("Warning: thumbnail analysis failed, using fixed timestamp: %v", err)
+		timestamp = 1.0
+	}
+	return f.ExtractThumbnail(ctx, videoPath, thumbnailPath, timestamp)
+}
+
+// pickThumbnailTimestamp samples the candidate frames and returns the
+// timestamp of the sharpest usable one, or the one whose luma varies most if
+// none is usable
+func (f *FFmpeg) pickThumbnailTimestamp(ctx context.Context, videoPath string, duration float64) (float64, error) {
+	if duration <= 0 {
+		return 0, fmt.Errorf("unknown duration")
+	}
+
+	best, bestStats := -1.0, frameStats{}
+	var lastErr error
+	for _, fraction := range thumbnailCandidates {
+		timestamp := duration * fraction
+		stats, err := f.sampleFrameStats(ctx, videoPath, timestamp)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if best < 0 || betterThumbnail(stats, bestStats) {
+			best, bestStats = timestamp, stats
+		}
+	}
+	if best < 0 {
+		return 0, lastErr
+	}
+	return best, nil
+}
+
+// betterThumbnail reports whether a frame makes a better thumbnail than the
+// best one so far
+func betterThumbnail(candidate, best frameStats) bool {
+	if candidate.usable() != best.usable() {
+		return candidate.usable()
+	}
+	if !candidate.usable() {
+		return candidate.Variance > best.Variance
+	}
+	return candidate.Sharpness > best.Sharpness
+}
+
+// sampleFrameStats decodes the frame at timestamp as grayscale and measures it
+func (f *FFmpeg) sampleFrameStats(ctx context.Context, videoPath string, timestamp float64) (frameStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, f.config.FFmpegPath,
+		"-ss", fmt.Sprintf("%.2f", timestamp),
+		"-i", videoPath,
+		"-frames:v", "1",
+		"-vf", fmt.Sprintf("scale=%d:%d,format=gray", thumbnailSampleWidth, thumbnailSampleHeight),
+		"-f", "rawvideo",
+		"-",
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return frameStats{}, fmt.Errorf("frame sampling failed: %v, stderr: %s", err, stderr.String())
+	}
+	if len(output) != thumbnailSampleWidth*thumbnailSampleHeight {
+		return frameStats{}, fmt.Errorf("no frame at %.2fs", timestamp)
+	}
+
+	return measureFrame(output, thumbnailSampleWidth, thumbnailSampleHeight), nil
+}
+
+// measureFrame computes the luma statistics of an 8-bit grayscale frame
+func measureFrame(pixels []byte, width, height int) frameStats {
+	var sum, sumSquares float64
+	for _, p := range pixels {
+		v := float64(p)
+		sum += v
+		sumSquares += v * v
+	}
+	n := float64(len(pixels))
+	stats := frameStats{Mean: sum / n}
+	stats.Variance = sumSquares/n - stats.Mean*stats.Mean
+
+	// 4-neighbour Laplacian over the interior pixels
+	var lapSum, lapSquares float64
+	for y := 1; y < height-1; y++ {
+		for x := 1; x < width-1; x++ {
+			i := y*width + x
+			lap := float64(pixels[i-width]) + float64(pixels[i+width]) +
+				float64(pixels[i-1]) + float64(pixels[i+1]) - 4*float64(pixels[i])
+			lapSum += lap
+			lapSquares += lap * lap
+		}
+	}
+	interior := float64((width - 2) * (height - 2))
+	lapMean := lapSum / interior
+	stats.Sharpness = lapSquares/interior - lapMean*lapMean
+
+	return stats
+}
@@ -0,0 +1,84 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// MaxTranscodeLogBytes caps a job's log; beyond it the oldest output is dropped
+const MaxTranscodeLogBytes = 256 << 10
+
+// transcodeLogTruncated marks a log whose start was dropped
+const transcodeLogTruncated = "[earlier output truncated]\n"
+
+// TranscodeLog collects the FFmpeg commands a processing job runs, with
+// their stderr, so a failure can be looked into after the fact
+type TranscodeLog struct {
+	mu        sync.Mutex
+	buf       []byte
+	truncated bool
+}
+
+type transcodeLogKey struct{}
+
+// WithTranscodeLog returns a context whose FFmpeg commands are recorded to l
+func WithTranscodeLog(ctx context.Context, l *TranscodeLog) context.Context {
+	return context.WithValue(ctx, transcodeLogKey{}, l)
+}
+
+// String returns the log, keeping its last MaxTranscodeLogBytes
+func (l *TranscodeLog) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.truncated {
+		return transcodeLogTruncated + string(l.buf)
+	}
+	return string(l.buf)
+}
+
+// write appends to the log, dropping the oldest output past the cap
+func (l *TranscodeLog) write(s string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.buf = append(l.buf, s...)
+	if over := len(l.buf) - MaxTranscodeLogBytes; over > 0 {
+		l.buf = append(l.buf[:0], l.buf[over:]...)
+		l.truncated = true
+	}
+}
+
+// logCommand records a finished command, its stderr and how it exited to
+// the context's transcode log, if it has one
+func logCommand(ctx context.Context, cmd *exec.Cmd, stderr string, err error) {
+	l, ok := ctx.Value(transcodeLogKey{}).(*TranscodeLog)
+	if !ok || l == nil {
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "$ %s\n", strings.Join(cmd.Args, " "))
+	b.WriteString(stderr)
+	if stderr != "" && !strings.HasSuffix(stderr, "\n") {
+		b.WriteString("\n")
+	}
+	if err != nil {
+		fmt.Fprintf(&b, "[exit: %v]\n\n", err)
+	} else {
+		b.WriteString("[exit: ok]\n\n")
+	}
+	l.write(b.String())
+}
+
+// logNote logs a processing decision, and records it to the context's
+// transcode log, if it has one
+func logNote(ctx context.Context, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	log.Print(msg)
+	if l, ok := ctx.Value(transcodeLogKey{}).(*TranscodeLog); ok && l != nil {
+		l.write("# " + msg + "\n\n")
+	}
+}
@@ -0,0 +1,132 @@
+// Package webhook signs and delivers video event notifications to the
+// URLs admins register.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Video events webhooks can subscribe to
+const (
+	EventVideoUploaded  = "video.uploaded"  // Upload finished, processing queued
+	EventVideoCompleted = "video.completed" // Processing succeeded; the video is live
+	EventVideoFailed    = "video.failed"    // Processing failed and won't be retried
+)
+
+// Events lists every event, in the order they happen to a video
+var Events = []string{EventVideoUploaded, EventVideoCompleted, EventVideoFailed}
+
+// Request headers sent with each delivery
+const (
+	// SignatureHeader carries "sha256=" and the hex HMAC-SHA256 of the body,
+	// keyed with the webhook's secret
+	SignatureHeader = "X-Clipset-Signature"
+	EventHeader     = "X-Clipset-Event"
+)
+
+// DeliveryTimeout bounds a delivery, so a slow receiver only holds up its
+// own queue
+const DeliveryTimeout = 10 * time.Second
+
+// maxResponseBytes is how much of a failed response's body is kept in the
+// delivery error
+const maxResponseBytes = 512
+
+// Uploader identifies who uploaded the video
+type Uploader struct {
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+}
+
+// Video describes the video an event is about
+type Video struct {
+	ShortID         string   `json:"short_id"`
+	Title           string   `json:"title"`
+	URL             string   `json:"url"`
+	Uploader        Uploader `json:"uploader"`
+	DurationSeconds *int32   `json:"duration_seconds"`
+	ThumbnailURL    *string  `json:"thumbnail_url"`
+	Error           *string  `json:"error,omitempty"` // Why processing failed (video.failed)
+}
+
+// Payload is the JSON body of a delivery. Content is a one-line summary, so
+// chat webhooks like Discord's can take the payload as it is.
+type Payload struct {
+	Event     string    `json:"event"`
+	Timestamp time.Time `json:"timestamp"`
+	Content   string    `json:"content"`
+	Video     Video     `json:"video"`
+}
+
+// NewPayload builds the payload for an event about v
+func NewPayload(event string, v Video) Payload {
+	return Payload{
+		Event:     event,
+		Timestamp: time.Now().UTC(),
+		Content:   summary(event, v),
+		Video:     v,
+	}
+}
+
+// summary describes the event in one line
+func summary(event string, v Video) string {
+	switch event {
+	case EventVideoUploaded:
+		return fmt.Sprintf("%s uploaded \"%s\"", v.Uploader.DisplayName, v.Title)
+	case EventVideoCompleted:
+		return fmt.Sprintf("New clip from %s: \"%s\" %s", v.Uploader.DisplayName, v.Title, v.URL)
+	case EventVideoFailed:
+		return fmt.Sprintf("Processing failed for \"%s\" by %s", v.Title, v.Uploader.DisplayName)
+	}
+	return event
+}
+
+// ValidEvent reports whether event is one webhooks can subscribe to
+func ValidEvent(event string) bool {
+	return slices.Contains(Events, event)
+}
+
+// Sign returns the SignatureHeader value for body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Deliver POSTs a signed payload to url. It returns the response's status
+// code (0 if there was no response), and an error unless the status is 2xx.
+func Deliver(ctx context.Context, client *http.Client, url, secret, event string, body []byte) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, DeliveryTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("invalid request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Clipset-Webhook/1.0")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(SignatureHeader, Sign(secret, body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+		return resp.StatusCode, fmt.Errorf("receiver returned %s: %s", resp.Status, bytes.TrimSpace(snippet))
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,153 @@
+package storagemigrate
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/clipset/clipset-go/internal/services/storage"
+)
+
+// VideoFile is one file of a video, relative to its storage path
+type VideoFile struct {
+	Path string
+	Size int64
+}
+
+// videoRoots returns the names, relative to the storage path, of a video's
+// progressive MP4 and HLS directory. Either may not exist.
+func videoRoots(filename string) (mp4 string, hlsDir string) {
+	mp4 = filename
+	if !strings.HasSuffix(strings.ToLower(filename), ".mp4") {
+		mp4 = filename + ".mp4"
+	}
+	return mp4, storage.GetHLSDirectoryName(filename)
+}
+
+// ListVideoFiles lists the files of a video stored in base. Thumbnails live
+// in the thumbnail directory, whatever the video's storage path, so they
+// aren't included. Paths keep the layout of the source, sharded or flat.
+func ListVideoFiles(base, filename string) ([]VideoFile, error) {
+	mp4, hlsDir := videoRoots(filename)
+	files := []VideoFile{}
+
+	mp4Path := storage.ResolvePath(base, mp4)
+	if info, err := os.Stat(mp4Path); err == nil && info.Mode().IsRegular() {
+		rel, err := filepath.Rel(base, mp4Path)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, VideoFile{Path: rel, Size: info.Size()})
+	}
+
+	hlsPath := storage.ResolvePath(base, hlsDir)
+	if info, err := os.Stat(hlsPath); err == nil && info.IsDir() {
+		err := filepath.WalkDir(hlsPath, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || !d.Type().IsRegular() {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(base, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, VideoFile{Path: rel, Size: info.Size()})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list HLS files: %w", err)
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files found for %s in %s", filename, base)
+	}
+	return files, nil
+}
+
+// CopyVerified copies src to dst, then checks dst's size and SHA-256 against
+// src's. The copy is written next to dst and renamed into place, so an
+// interrupted run never leaves a partial file under dst's name.
+func CopyVerified(src, dst string, size int64) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	srcSum, written, err := copyFile(src, dst+".part")
+	if err != nil {
+		os.Remove(dst + ".part")
+		return err
+	}
+	if written != size {
+		os.Remove(dst + ".part")
+		return fmt.Errorf("source changed during copy: expected %d bytes, copied %d", size, written)
+	}
+	if err := os.Rename(dst+".part", dst); err != nil {
+		os.Remove(dst + ".part")
+		return fmt.Errorf("failed to rename copy: %w", err)
+	}
+
+	dstSize, err := storage.GetFileSize(dst)
+	if err != nil {
+		return fmt.Errorf("failed to stat copy: %w", err)
+	}
+	if dstSize != size {
+		return fmt.Errorf("size mismatch: source %d bytes, copy %d bytes", size, dstSize)
+	}
+	dstSum, err := storage.ComputeSHA256(dst)
+	if err != nil {
+		return fmt.Errorf("failed to checksum copy: %w", err)
+	}
+	if dstSum != srcSum {
+		return errors.New("checksum mismatch between source and copy")
+	}
+	return nil
+}
+
+// copyFile copies src to dst, syncing dst to disk, and returns the hex
+// SHA-256 of what was read and the bytes written
+func copyFile(src, dst string) (string, int64, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to open source: %w", err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create copy: %w", err)
+	}
+
+	hasher := sha256.New()
+	written, err := io.Copy(out, io.TeeReader(in, hasher))
+	if err != nil {
+		out.Close()
+		return "", 0, fmt.Errorf("failed to copy: %w", err)
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return "", 0, fmt.Errorf("failed to sync copy: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return "", 0, fmt.Errorf("failed to close copy: %w", err)
+	}
+
+	return fmt.Sprintf("%x", hasher.Sum(nil)), written, nil
+}
+
+// removeVideoFiles deletes a video's progressive MP4 and HLS directory from base
+func removeVideoFiles(base, filename string) error {
+	mp4, hlsDir := videoRoots(filename)
+	if err := os.Remove(storage.ResolvePath(base, mp4)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.RemoveAll(storage.ResolvePath(base, hlsDir))
+}
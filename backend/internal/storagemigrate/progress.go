@@ -0,0 +1,126 @@
+package storagemigrate
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Summary holds the result of a storage migration
+type Summary struct {
+	Moved       int
+	Failed      int
+	Bytes       int64
+	Interrupted bool
+	StartTime   time.Time
+	EndTime     time.Time
+}
+
+// Print prints the storage migration summary
+func (s *Summary) Print() {
+	fmt.Println()
+	if s.Interrupted {
+		fmt.Println("Storage migration interrupted.")
+	} else {
+		fmt.Println("Storage migration finished.")
+	}
+	fmt.Println("=====================================")
+	fmt.Printf("Videos moved:  %d\n", s.Moved)
+	fmt.Printf("Videos failed: %d\n", s.Failed)
+	fmt.Printf("Data copied:   %s\n", formatBytes(s.Bytes))
+	fmt.Printf("Total time:    %s\n", s.EndTime.Sub(s.StartTime).Round(time.Second))
+}
+
+// PrintHeader prints the storage migration header
+func PrintHeader(dryRun bool, from, to string) {
+	if dryRun {
+		fmt.Println("Clipset Storage Migration (DRY RUN)")
+	} else {
+		fmt.Println("Clipset Storage Migration")
+	}
+	fmt.Println("=====================================")
+	fmt.Printf("  %-6s %s\n", "From:", from)
+	fmt.Printf("  %-6s %s\n", "To:", to)
+	fmt.Println()
+}
+
+// PrintPlan prints how many videos will be moved and skipped
+func PrintPlan(p plan) {
+	fmt.Printf("  %-24s %d\n", "Videos to move:", len(p.Videos))
+	fmt.Printf("  %-24s %d\n", "Already in target:", p.Moved)
+	if p.Pending > 0 {
+		fmt.Printf("  %-24s %d\n", "Skipped (not processed):", p.Pending)
+	}
+}
+
+// PrintVideoMoved prints a moved video with its progress through the run
+func PrintVideoMoved(n, total int, id uuid.UUID, bytes int64, elapsed time.Duration) {
+	rate := float64(bytes) / elapsed.Seconds()
+	fmt.Printf("  [%d/%d] %s moved %s (%s/s)\n", n, total, id, formatBytes(bytes), formatBytes(int64(rate)))
+}
+
+// PrintVideoError prints a video that couldn't be moved
+func PrintVideoError(n, total int, id uuid.UUID, err error) {
+	fmt.Printf("  [%d/%d] %s FAILED: %v\n", n, total, id, err)
+}
+
+// ReshardSummary holds the result of a reshard
+type ReshardSummary struct {
+	Moved       int
+	Failed      int
+	Interrupted bool
+	StartTime   time.Time
+	EndTime     time.Time
+}
+
+// Print prints the reshard summary
+func (s *ReshardSummary) Print(dryRun bool) {
+	fmt.Println()
+	switch {
+	case dryRun:
+		fmt.Printf("Would move %d files.\n", s.Moved)
+		fmt.Println("\nNo changes made (dry run).")
+		return
+	case s.Interrupted:
+		fmt.Println("Reshard interrupted.")
+	default:
+		fmt.Println("Reshard finished.")
+	}
+	fmt.Println("=====================================")
+	fmt.Printf("Files moved:  %d\n", s.Moved)
+	fmt.Printf("Files failed: %d\n", s.Failed)
+	fmt.Printf("Total time:   %s\n", s.EndTime.Sub(s.StartTime).Round(time.Second))
+}
+
+// PrintReshardHeader prints the reshard header
+func PrintReshardHeader(dryRun bool, videoPath, thumbnailPath string) {
+	if dryRun {
+		fmt.Println("Clipset Storage Reshard (DRY RUN)")
+	} else {
+		fmt.Println("Clipset Storage Reshard")
+	}
+	fmt.Println("=====================================")
+	fmt.Printf("  %-12s %s\n", "Videos:", videoPath)
+	fmt.Printf("  %-12s %s\n", "Thumbnails:", thumbnailPath)
+	fmt.Println()
+}
+
+// PrintReshardError prints a file of a video that couldn't be moved
+func PrintReshardError(videoID string, err error) {
+	fmt.Printf("  %s FAILED: %v\n", videoID, err)
+}
+
+// formatBytes formats a byte count in binary units
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
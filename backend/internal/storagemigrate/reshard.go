@@ -0,0 +1,93 @@
+package storagemigrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/clipset/clipset-go/internal/db"
+	"github.com/clipset/clipset-go/internal/services/storage"
+)
+
+// ReshardOptions holds the reshard configuration
+type ReshardOptions struct {
+	DatabaseURL   string
+	VideoPath     string // VIDEO_STORAGE_PATH, where videos without a storage_path live
+	ThumbnailPath string // THUMBNAIL_STORAGE_PATH
+	DryRun        bool
+}
+
+// shardEntry is a file or directory of a video, named relative to its storage directory
+type shardEntry struct {
+	Base string
+	Name string
+}
+
+// Reshard moves the files of every video still stored flat, from before
+// storage was sharded, into their shard directories. Each file or directory
+// is renamed in place, so a move is atomic and an interrupted run can simply
+// be rerun: files already sharded are skipped.
+func Reshard(ctx context.Context, opts ReshardOptions) error {
+	PrintReshardHeader(opts.DryRun, opts.VideoPath, opts.ThumbnailPath)
+
+	database, err := db.Connect(ctx, opts.DatabaseURL)
+	if err != nil {
+		return fmt.Errorf("database connection failed: %w", err)
+	}
+	defer database.Close()
+
+	rows, err := database.Queries.ListVideoFiles(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list videos: %w", err)
+	}
+
+	summary := ReshardSummary{StartTime: time.Now()}
+	for _, row := range rows {
+		if ctx.Err() != nil {
+			summary.Interrupted = true
+			break
+		}
+
+		base := effectivePath(row.StoragePath, opts.VideoPath)
+		mp4, hlsDir := videoRoots(row.Filename)
+		entries := []shardEntry{{Base: base, Name: mp4}, {Base: base, Name: hlsDir}}
+		if row.ThumbnailFilename != nil && *row.ThumbnailFilename != "" {
+			entries = append(entries,
+				shardEntry{Base: opts.ThumbnailPath, Name: *row.ThumbnailFilename},
+				shardEntry{Base: opts.ThumbnailPath, Name: storage.WebPThumbnailName(*row.ThumbnailFilename)},
+			)
+		}
+
+		for _, e := range entries {
+			if opts.DryRun {
+				if storage.ShardDir(e.Name) != "" && storage.FileExists(filepath.Join(e.Base, e.Name)) {
+					summary.Moved++
+					fmt.Printf("  %s -> %s\n", filepath.Join(e.Base, e.Name), storage.ShardedPath(e.Base, e.Name))
+				}
+				continue
+			}
+
+			moved, err := storage.MoveToShard(e.Base, e.Name)
+			if err != nil {
+				summary.Failed++
+				PrintReshardError(row.ID.String(), err)
+				continue
+			}
+			if moved {
+				summary.Moved++
+			}
+		}
+	}
+	summary.EndTime = time.Now()
+	summary.Print(opts.DryRun)
+
+	if summary.Interrupted {
+		return errors.New("interrupted; rerun the command to move the remaining files")
+	}
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d files could not be moved; rerun the command to retry them", summary.Failed)
+	}
+	return nil
+}
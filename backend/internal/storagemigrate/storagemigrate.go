@@ -0,0 +1,241 @@
+// Package storagemigrate moves stored videos from one storage path to
+// another, updating each video's storage_path as it goes, and moves files
+// stored before sharding into their shard directories.
+package storagemigrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/clipset/clipset-go/internal/db"
+	"github.com/clipset/clipset-go/internal/db/sqlc"
+	"github.com/clipset/clipset-go/internal/domain"
+	"github.com/clipset/clipset-go/internal/services/storage"
+)
+
+// Options holds the storage migration configuration
+type Options struct {
+	DatabaseURL  string
+	DefaultPath  string // VIDEO_STORAGE_PATH, where videos without a storage_path live
+	From         string
+	To           string
+	DryRun       bool
+	DeleteSource bool
+}
+
+// errVideoChanged means a video was reprocessed, deleted or moved while its
+// files were being copied
+var errVideoChanged = errors.New("video changed while its files were copied")
+
+// candidate is a video stored in the source path
+type candidate struct {
+	ID       uuid.UUID
+	Filename string
+}
+
+// plan is what a run has to do
+type plan struct {
+	Videos  []candidate
+	Moved   int // Already pointing at the target, from an earlier run
+	Pending int // Stored in the source path but not processed yet
+}
+
+// Run moves every processed video stored in opts.From to opts.To. Each
+// video's files are copied and verified, then its storage_path is updated,
+// then (with DeleteSource) the source files are removed. A video that fails
+// is reported and left in place; rerunning picks up where it stopped.
+func Run(ctx context.Context, opts Options) error {
+	from := filepath.Clean(opts.From)
+	to := filepath.Clean(opts.To)
+	if from == to {
+		return fmt.Errorf("source and target are the same path: %s", from)
+	}
+	if info, err := os.Stat(from); err != nil || !info.IsDir() {
+		return fmt.Errorf("source directory not found: %s", from)
+	}
+	if err := checkTarget(opts.DefaultPath, to); err != nil {
+		return err
+	}
+
+	PrintHeader(opts.DryRun, from, to)
+
+	database, err := db.Connect(ctx, opts.DatabaseURL)
+	if err != nil {
+		return fmt.Errorf("database connection failed: %w", err)
+	}
+	defer database.Close()
+
+	rows, err := database.Queries.ListVideoFiles(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list videos: %w", err)
+	}
+	p := buildPlan(rows, opts.DefaultPath, from, to)
+	PrintPlan(p)
+
+	if len(p.Videos) == 0 {
+		fmt.Println("\nNothing to move.")
+		return nil
+	}
+
+	if opts.DryRun {
+		return dryRun(p, from)
+	}
+
+	if err := os.MkdirAll(to, 0755); err != nil {
+		return fmt.Errorf("failed to create target directory: %w", err)
+	}
+
+	summary := Summary{StartTime: time.Now()}
+	for i, v := range p.Videos {
+		if ctx.Err() != nil {
+			summary.Interrupted = true
+			break
+		}
+
+		start := time.Now()
+		bytes, err := moveVideo(ctx, database, v, from, to, opts.DeleteSource)
+		if err != nil {
+			summary.Failed++
+			PrintVideoError(i+1, len(p.Videos), v.ID, err)
+			continue
+		}
+		summary.Moved++
+		summary.Bytes += bytes
+		PrintVideoMoved(i+1, len(p.Videos), v.ID, bytes, time.Since(start))
+	}
+	summary.EndTime = time.Now()
+	summary.Print()
+
+	if summary.Interrupted {
+		return errors.New("interrupted; rerun the command to move the remaining videos")
+	}
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d videos could not be moved; rerun the command to retry them", summary.Failed)
+	}
+	return nil
+}
+
+// checkTarget verifies playback can serve videos from to. Signed /hls/ URLs
+// and X-Accel-Redirect paths are relative to the video storage directory,
+// which nginx serves, so to must be inside it, e.g. a new disk mounted in it.
+// Its first directory can't look like a shard directory.
+func checkTarget(videoPath, to string) error {
+	rel, err := storage.RelativeToVideoPath(videoPath, to)
+	if err != nil {
+		return fmt.Errorf("target %s is outside VIDEO_STORAGE_PATH (%s), so its videos couldn't be played; mount the new storage inside it, e.g. %s",
+			to, videoPath, filepath.Join(videoPath, "disk2"))
+	}
+	if first := strings.SplitN(rel, "/", 2)[0]; storage.IsShardDirName(first) {
+		return fmt.Errorf("target %s would be mistaken for a shard directory; use a longer directory name than %q", to, first)
+	}
+	return nil
+}
+
+// buildPlan picks the videos stored in from. Videos without a storage_path
+// live in defaultPath.
+func buildPlan(rows []sqlc.ListVideoFilesRow, defaultPath, from, to string) plan {
+	p := plan{Videos: []candidate{}}
+	for _, row := range rows {
+		switch effectivePath(row.StoragePath, defaultPath) {
+		case to:
+			p.Moved++
+		case from:
+			if row.ProcessingStatus != domain.ProcessingStatusCompleted {
+				// Pending uploads are still in the temp directory, and failed
+				// ones have no output files
+				p.Pending++
+				continue
+			}
+			p.Videos = append(p.Videos, candidate{ID: row.ID, Filename: row.Filename})
+		}
+	}
+	return p
+}
+
+// effectivePath returns the cleaned directory a video's files are in
+func effectivePath(storagePath *string, defaultPath string) string {
+	if storagePath != nil && *storagePath != "" {
+		return filepath.Clean(*storagePath)
+	}
+	return filepath.Clean(defaultPath)
+}
+
+// dryRun prints the files each video would move
+func dryRun(p plan, from string) error {
+	fmt.Println()
+	fmt.Println("Would move:")
+
+	var total int64
+	missing := 0
+	for i, v := range p.Videos {
+		files, err := ListVideoFiles(from, v.Filename)
+		if err != nil {
+			missing++
+			PrintVideoError(i+1, len(p.Videos), v.ID, err)
+			continue
+		}
+		var bytes int64
+		for _, f := range files {
+			bytes += f.Size
+		}
+		total += bytes
+		fmt.Printf("  %s  %d files, %s\n", v.ID, len(files), formatBytes(bytes))
+	}
+
+	fmt.Printf("\nTotal: %d videos, %s would be moved\n", len(p.Videos)-missing, formatBytes(total))
+	fmt.Println("\nNo changes made (dry run).")
+	return nil
+}
+
+// moveVideo copies a video's files to the target, verifies them, points the
+// video at the target and, with deleteSource, removes the source files. It
+// returns the bytes copied.
+func moveVideo(ctx context.Context, database *db.DB, v candidate, from, to string, deleteSource bool) (int64, error) {
+	files, err := ListVideoFiles(from, v.Filename)
+	if err != nil {
+		return 0, err
+	}
+
+	var bytes int64
+	for _, f := range files {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		if err := CopyVerified(filepath.Join(from, f.Path), filepath.Join(to, f.Path), f.Size); err != nil {
+			return 0, fmt.Errorf("%s: %w", f.Path, err)
+		}
+		bytes += f.Size
+	}
+
+	// Recheck the row under a lock, so a video reprocessed or moved during the
+	// copy keeps the files it has now
+	err = database.InTx(ctx, func(q *sqlc.Queries) error {
+		current, err := q.GetVideoFilesForUpdate(ctx, v.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get video: %w", err)
+		}
+		if current.Filename != v.Filename ||
+			current.ProcessingStatus != domain.ProcessingStatusCompleted ||
+			(current.StoragePath != nil && *current.StoragePath != "" && filepath.Clean(*current.StoragePath) != from) {
+			return errVideoChanged
+		}
+		return q.UpdateVideoStoragePath(ctx, sqlc.UpdateVideoStoragePathParams{ID: v.ID, StoragePath: &to})
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to update storage path (copied files left in %s): %w", to, err)
+	}
+
+	if deleteSource {
+		if err := removeVideoFiles(from, v.Filename); err != nil {
+			return bytes, fmt.Errorf("moved, but failed to delete source files: %w", err)
+		}
+	}
+	return bytes, nil
+}
@@ -0,0 +1,178 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/clipset/clipset-go/internal/config"
+	"github.com/clipset/clipset-go/internal/db"
+	"github.com/clipset/clipset-go/internal/services/storage"
+	"github.com/clipset/clipset-go/internal/services/video"
+)
+
+// benchmarkTimeout is the hard limit for a whole benchmark run; presets not
+// reached by then are reported as stopped
+const benchmarkTimeout = 5 * time.Minute
+
+// BenchmarkJobArgs defines the arguments for an encoder benchmark
+type BenchmarkJobArgs struct {
+	SampleSeconds int    `json:"sample_seconds"`
+	VideoID       string `json:"video_id,omitempty"` // Empty benchmarks a generated test clip
+}
+
+// Kind returns the job type identifier
+func (BenchmarkJobArgs) Kind() string {
+	return "encoder_benchmark"
+}
+
+// BenchmarkWorker encodes a short sample with each candidate preset and
+// publishes the timings for the benchmark status endpoint
+type BenchmarkWorker struct {
+	river.WorkerDefaults[BenchmarkJobArgs]
+	database  *db.DB
+	config    *config.Config
+	processor *video.Processor
+}
+
+// NewBenchmarkWorker creates a new benchmark worker
+func NewBenchmarkWorker(database *db.DB, cfg *config.Config, processor *video.Processor) *BenchmarkWorker {
+	return &BenchmarkWorker{
+		database:  database,
+		config:    cfg,
+		processor: processor,
+	}
+}
+
+// Timeout overrides the client's job timeout, which is sized for full transcodes
+func (w *BenchmarkWorker) Timeout(job *river.Job[BenchmarkJobArgs]) time.Duration {
+	return benchmarkTimeout
+}
+
+// Work runs the benchmark. The report is always published, with the error
+// set if the benchmark couldn't run.
+func (w *BenchmarkWorker) Work(ctx context.Context, job *river.Job[BenchmarkJobArgs]) error {
+	report := video.BenchmarkReport{
+		Source:        "generated",
+		VideoID:       job.Args.VideoID,
+		SampleSeconds: job.Args.SampleSeconds,
+		Results:       []video.BenchmarkResult{},
+		StartedAt:     time.Now(),
+	}
+	defer func() {
+		report.FinishedAt = time.Now()
+		video.FinishBenchmark(&report)
+	}()
+
+	if err := w.run(ctx, job.Args, &report); err != nil {
+		report.Error = err.Error()
+		log.Printf("Encoder benchmark failed: %v", err)
+		return nil
+	}
+
+	log.Printf("Encoder benchmark completed: %d presets in %s", len(report.Results), time.Since(report.StartedAt).Round(time.Second))
+	return nil
+}
+
+// run prepares the sample and encodes it with each candidate preset
+func (w *BenchmarkWorker) run(ctx context.Context, args BenchmarkJobArgs, report *video.BenchmarkReport) error {
+	ffmpeg := w.processor.GetFFmpeg()
+
+	workDir, err := os.MkdirTemp(w.config.TempStoragePath, "benchmark-")
+	if err != nil {
+		return fmt.Errorf("failed to create benchmark directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	inputPath := filepath.Join(workDir, "sample.mp4")
+	if args.VideoID != "" {
+		report.Source = "video"
+		inputPath, err = w.videoSourcePath(ctx, args.VideoID)
+		if err != nil {
+			return err
+		}
+	} else if err := ffmpeg.GenerateTestClip(ctx, inputPath, args.SampleSeconds); err != nil {
+		return err
+	}
+
+	if metadata, err := ffmpeg.GetMetadata(ctx, inputPath); err == nil {
+		report.Width = metadata.Width
+		report.Height = metadata.Height
+	}
+
+	// Encode with the configured settings and GPU backend, varying only the preset
+	dbConfig, err := w.database.Queries.GetConfig(ctx)
+	if err != nil {
+		log.Printf("Warning: failed to get DB config, using defaults: %v", err)
+		dbConfig = defaultDBConfig()
+	}
+	baseCfg := buildTranscodeConfig(dbConfig)
+
+	candidates := make([]video.TranscodeConfig, 0, len(video.BenchmarkCPUPresets)+len(video.BenchmarkGPUPresets))
+	for _, preset := range video.BenchmarkCPUPresets {
+		cfg := baseCfg
+		cfg.UseGPU = false
+		cfg.CPUPreset = preset
+		candidates = append(candidates, cfg)
+	}
+	if slices.Contains(ffmpeg.DetectEncoders(ctx).GPUBackends, baseCfg.GPUBackend) {
+		for _, preset := range video.BenchmarkGPUPresets {
+			cfg := baseCfg
+			cfg.UseGPU = true
+			cfg.NVENCPreset = preset
+			candidates = append(candidates, cfg)
+		}
+	}
+
+	for i, cfg := range candidates {
+		outputPath := filepath.Join(workDir, fmt.Sprintf("output-%d.mp4", i))
+		report.Results = append(report.Results, ffmpeg.BenchmarkEncode(ctx, inputPath, outputPath, args.SampleSeconds, cfg))
+		os.Remove(outputPath)
+	}
+	return nil
+}
+
+// videoSourcePath returns the file ffmpeg reads an existing video from: its
+// MP4, or the master playlist of its HLS directory
+func (w *BenchmarkWorker) videoSourcePath(ctx context.Context, videoID string) (string, error) {
+	videoUUID, err := uuid.Parse(videoID)
+	if err != nil {
+		return "", fmt.Errorf("invalid video ID: %w", err)
+	}
+
+	videoRecord, err := w.database.Queries.GetVideoByID(ctx, videoUUID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get video: %w", err)
+	}
+
+	base := w.config.VideoStoragePath
+	if videoRecord.StoragePath != nil && *videoRecord.StoragePath != "" {
+		base = *videoRecord.StoragePath
+	}
+
+	path := filepath.Join(storage.ResolvePath(base, videoRecord.Filename), "master.m3u8")
+	if strings.EqualFold(filepath.Ext(videoRecord.Filename), ".mp4") {
+		path = storage.ResolvePath(base, videoRecord.Filename)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("video file not found: %w", err)
+	}
+	return path, nil
+}
+
+// activeVideoJobStates are the states of jobs that are running or waiting to run
+var activeVideoJobStates = []rivertype.JobState{
+	rivertype.JobStateAvailable,
+	rivertype.JobStateRunning,
+	rivertype.JobStateRetryable,
+	rivertype.JobStateScheduled,
+}
@@ -0,0 +1,134 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riverqueue/river"
+
+	"github.com/clipset/clipset-go/internal/config"
+	"github.com/clipset/clipset-go/internal/db"
+	"github.com/clipset/clipset-go/internal/db/sqlc"
+	"github.com/clipset/clipset-go/internal/services/storage"
+)
+
+const (
+	// checksumBackfillInterval is how often videos without an output checksum
+	// are looked for
+	checksumBackfillInterval = 15 * time.Minute
+
+	// checksumBackfillBatch is how many videos one backfill job hashes
+	checksumBackfillBatch = 20
+
+	// checksumBackfillPriority runs backfills after regular transcodes
+	// (River priorities go from 1, highest, to 4, lowest)
+	checksumBackfillPriority = 4
+)
+
+// ChecksumBackfillJobArgs defines the arguments for the periodic job hashing
+// the output of videos processed before checksums were stored
+type ChecksumBackfillJobArgs struct{}
+
+// Kind returns the job type identifier
+func (ChecksumBackfillJobArgs) Kind() string {
+	return "checksum_backfill"
+}
+
+// InsertOpts gives backfills the lowest priority; they aren't retried, the
+// next run picks up where a failed one stopped
+func (ChecksumBackfillJobArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{Priority: checksumBackfillPriority, MaxAttempts: 1}
+}
+
+// ChecksumBackfillWorker hashes the output of videos without an output checksum
+type ChecksumBackfillWorker struct {
+	river.WorkerDefaults[ChecksumBackfillJobArgs]
+	database *db.DB
+	config   *config.Config
+
+	// Videos whose output couldn't be hashed, e.g. missing files, skipped
+	// until the next restart so they don't hold up the rest
+	mu      sync.Mutex
+	skipped map[uuid.UUID]bool
+}
+
+// NewChecksumBackfillWorker creates a new checksum backfill worker
+func NewChecksumBackfillWorker(database *db.DB, cfg *config.Config) *ChecksumBackfillWorker {
+	return &ChecksumBackfillWorker{
+		database: database,
+		config:   cfg,
+		skipped:  make(map[uuid.UUID]bool),
+	}
+}
+
+// Work hashes the oldest videos missing an output checksum. A video whose
+// output can't be read is logged and skipped until the worker restarts.
+func (w *ChecksumBackfillWorker) Work(ctx context.Context, job *river.Job[ChecksumBackfillJobArgs]) error {
+	w.mu.Lock()
+	skipIDs := make([]uuid.UUID, 0, len(w.skipped))
+	for id := range w.skipped {
+		skipIDs = append(skipIDs, id)
+	}
+	w.mu.Unlock()
+
+	videos, err := w.database.Queries.ListVideosWithoutOutputSha256(ctx, sqlc.ListVideosWithoutOutputSha256Params{
+		SkipIds:   skipIDs,
+		BatchSize: checksumBackfillBatch,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list videos without checksums: %w", err)
+	}
+
+	hashed := 0
+	for _, v := range videos {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if storeOutputSha256(ctx, w.database, v.ID, videoBasePath(w.config, v.StoragePath), v.Filename) {
+			hashed++
+			continue
+		}
+		w.mu.Lock()
+		w.skipped[v.ID] = true
+		w.mu.Unlock()
+	}
+
+	if len(videos) > 0 {
+		log.Printf("Checksum backfill: hashed %d of %d videos", hashed, len(videos))
+	}
+	return nil
+}
+
+// videoBasePath returns the directory a video's output is stored in
+func videoBasePath(cfg *config.Config, storagePath *string) string {
+	if storagePath != nil && *storagePath != "" {
+		return *storagePath
+	}
+	return cfg.VideoStoragePath
+}
+
+// storeOutputSha256 hashes a video's output and stores the checksum,
+// reporting whether it did. If the output can't be hashed the checksum is
+// cleared, so a stale one from earlier output isn't kept and the backfill
+// tries again. Failures are logged, so they never fail the job.
+func storeOutputSha256(ctx context.Context, database *db.DB, videoID uuid.UUID, base, filename string) bool {
+	var checksum *string
+	if sum, err := storage.ComputeOutputSHA256(base, filename); err != nil {
+		log.Printf("Warning: failed to hash output of video %s: %v", videoID, err)
+	} else {
+		checksum = &sum
+	}
+
+	if err := database.Queries.UpdateVideoOutputSha256(ctx, sqlc.UpdateVideoOutputSha256Params{
+		ID:           videoID,
+		OutputSha256: checksum,
+	}); err != nil {
+		log.Printf("Warning: failed to store output checksum of video %s: %v", videoID, err)
+		return false
+	}
+	return checksum != nil
+}
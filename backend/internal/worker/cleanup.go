@@ -0,0 +1,45 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/riverqueue/river"
+
+	"github.com/clipset/clipset-go/internal/services/upload"
+)
+
+// ChunkCleanupJobArgs defines the arguments for the periodic chunk cleanup job
+type ChunkCleanupJobArgs struct{}
+
+// Kind returns the job type identifier
+func (ChunkCleanupJobArgs) Kind() string {
+	return "chunk_cleanup"
+}
+
+// ChunkCleanupWorker removes abandoned chunked upload sessions
+type ChunkCleanupWorker struct {
+	river.WorkerDefaults[ChunkCleanupJobArgs]
+	chunkManager *upload.ChunkedUploadManager
+}
+
+// NewChunkCleanupWorker creates a new chunk cleanup worker
+func NewChunkCleanupWorker(chunkManager *upload.ChunkedUploadManager) *ChunkCleanupWorker {
+	return &ChunkCleanupWorker{
+		chunkManager: chunkManager,
+	}
+}
+
+// Work removes all expired upload sessions
+func (w *ChunkCleanupWorker) Work(ctx context.Context, job *river.Job[ChunkCleanupJobArgs]) error {
+	removed, reclaimed, err := w.chunkManager.CleanupExpiredSessions()
+	if err != nil {
+		return fmt.Errorf("failed to cleanup expired upload sessions: %w", err)
+	}
+
+	if removed > 0 {
+		log.Printf("Chunk cleanup: removed %d expired upload sessions, reclaimed %d bytes", removed, reclaimed)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/clipset/clipset-go/internal/config"
+	"github.com/clipset/clipset-go/internal/db/sqlc"
+)
+
+// transcodeSlotDelay is how long a transcode waits for a free slot before
+// it's tried again
+const transcodeSlotDelay = 10 * time.Second
+
+// transcodeSlots limits how many transcodes run at once, and how many of them
+// use the GPU. The limits are passed on every acquire, so a config change
+// applies to the next job picked up without a restart.
+type transcodeSlots struct {
+	mu      sync.Mutex
+	running int
+	gpu     int
+}
+
+// transcodeLimiter is shared by the transcode and HLS migration workers
+var transcodeLimiter transcodeSlots
+
+// acquire takes a slot if fewer than limit transcodes (and, for a GPU
+// transcode, fewer than gpuLimit GPU transcodes) are running. The returned
+// release function must be called when the transcode ends.
+func (s *transcodeSlots) acquire(limit, gpuLimit int, useGPU bool) (release func(), ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running >= limit || (useGPU && s.gpu >= gpuLimit) {
+		return nil, false
+	}
+	s.running++
+	if useGPU {
+		s.gpu++
+	}
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.running--
+		if useGPU {
+			s.gpu--
+		}
+	}, true
+}
+
+// acquireTranscodeSlot takes a slot using the limits in the DB config. The
+// env settings are used when the DB config couldn't be read.
+func acquireTranscodeSlot(dbConfig sqlc.Config, appConfig *config.Config) (release func(), ok bool) {
+	limit, gpuLimit := int(dbConfig.WorkerConcurrency), int(dbConfig.GpuConcurrency)
+	if limit < 1 {
+		limit = appConfig.WorkerConcurrency
+	}
+	if gpuLimit < 1 {
+		gpuLimit = appConfig.GPUConcurrency
+	}
+	return transcodeLimiter.acquire(limit, gpuLimit, dbConfig.UseGpuTranscoding)
+}
@@ -0,0 +1,201 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riverqueue/river"
+
+	"github.com/clipset/clipset-go/internal/api/handlers"
+	"github.com/clipset/clipset-go/internal/config"
+	"github.com/clipset/clipset-go/internal/db"
+	"github.com/clipset/clipset-go/internal/db/sqlc"
+	"github.com/clipset/clipset-go/internal/domain"
+	"github.com/clipset/clipset-go/internal/services/storage"
+	"github.com/clipset/clipset-go/internal/services/video"
+)
+
+const (
+	// hlsMigrationPriority runs migration jobs after regular transcodes
+	// (River priorities go from 1, highest, to 4, lowest)
+	hlsMigrationPriority = 4
+
+	// hlsMigrationPausedDelay is how long a job waits before checking again
+	// whether a paused migration has been resumed
+	hlsMigrationPausedDelay = 1 * time.Minute
+)
+
+// HLSMigrationJobArgs defines the arguments for converting a progressive video to HLS
+type HLSMigrationJobArgs struct {
+	VideoID     string `json:"video_id"`
+	MigrationID int64  `json:"migration_id"`
+}
+
+// Kind returns the job type identifier
+func (HLSMigrationJobArgs) Kind() string {
+	return "hls_migration"
+}
+
+// HLSMigrationWorker converts completed progressive videos to HLS and reports
+// progress through the HLS migration state
+type HLSMigrationWorker struct {
+	river.WorkerDefaults[HLSMigrationJobArgs]
+	database  *db.DB
+	config    *config.Config
+	processor *video.Processor
+}
+
+// NewHLSMigrationWorker creates a new HLS migration worker
+func NewHLSMigrationWorker(database *db.DB, cfg *config.Config, processor *video.Processor) *HLSMigrationWorker {
+	return &HLSMigrationWorker{
+		database:  database,
+		config:    cfg,
+		processor: processor,
+	}
+}
+
+// Work converts one video unless its migration was paused or cancelled.
+// Failures are recorded in the migration state and the video keeps playing
+// from its MP4.
+func (w *HLSMigrationWorker) Work(ctx context.Context, job *river.Job[HLSMigrationJobArgs]) error {
+	videoID := job.Args.VideoID
+	migrationID := job.Args.MigrationID
+
+	// Get transcoding config from database
+	dbConfig, err := w.database.Queries.GetConfig(ctx)
+	if err != nil {
+		log.Printf("Warning: failed to get DB config, using defaults: %v", err)
+		dbConfig = defaultDBConfig()
+	}
+
+	// Wait for a free slot if the concurrency limit is reached
+	release, ok := acquireTranscodeSlot(dbConfig, w.config)
+	if !ok {
+		return river.JobSnooze(transcodeSlotDelay)
+	}
+	defer release()
+
+	// Lets a forced cancel stop the transcode
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	switch handlers.BeginMigrationVideo(migrationID, videoID, cancel) {
+	case handlers.MigrationRunning:
+	case handlers.MigrationPaused:
+		return river.JobSnooze(hlsMigrationPausedDelay)
+	default:
+		log.Printf("Skipping HLS migration for video %s: migration was cancelled", videoID)
+		return nil
+	}
+
+	err = w.migrate(ctx, videoID, dbConfig)
+	handlers.FinishMigrationVideo(migrationID, videoID, err)
+	if err != nil {
+		log.Printf("HLS migration failed for video %s: %v", videoID, err)
+		return err
+	}
+	return nil
+}
+
+// migrate transcodes the video's MP4 into an HLS directory, points the video
+// record at it and removes the MP4
+func (w *HLSMigrationWorker) migrate(ctx context.Context, videoID string, dbConfig sqlc.Config) error {
+	videoUUID, err := uuid.Parse(videoID)
+	if err != nil {
+		return fmt.Errorf("invalid video ID: %w", err)
+	}
+
+	videoRecord, err := w.database.Queries.GetVideoByID(ctx, videoUUID)
+	if err != nil {
+		return fmt.Errorf("failed to get video: %w", err)
+	}
+
+	// Already converted or no longer eligible, e.g. re-queued after a restart
+	if videoRecord.ProcessingStatus != domain.ProcessingStatusCompleted ||
+		!strings.EqualFold(filepath.Ext(videoRecord.Filename), ".mp4") {
+		log.Printf("Skipping HLS migration for video %s: not a completed progressive video", videoID)
+		return nil
+	}
+
+	handlers.SetMigrationCurrentVideo(videoRecord.Title)
+
+	base := w.config.VideoStoragePath
+	if videoRecord.StoragePath != nil && *videoRecord.StoragePath != "" {
+		base = *videoRecord.StoragePath
+	}
+	inputPath := storage.ResolvePath(base, videoRecord.Filename)
+	hlsName := stemWithoutExt(videoRecord.Filename)
+	hlsDir := storage.ShardedPath(base, hlsName)
+
+	if _, err := os.Stat(inputPath); err != nil {
+		return fmt.Errorf("video file not found: %w", err)
+	}
+
+	transcodeCfg := buildTranscodeConfig(dbConfig)
+	transcodeLog := &video.TranscodeLog{}
+	size, err := w.processor.ConvertToHLS(video.WithTranscodeLog(ctx, transcodeLog), inputPath, hlsDir, transcodeCfg)
+	saveTranscodeLog(ctx, w.database, videoUUID, HLSMigrationJobArgs{}.Kind(), err == nil, transcodeLog)
+	if err != nil {
+		// The directory also held the video's lower quality files; its
+		// streams fall back to the main file
+		os.RemoveAll(hlsDir)
+		return err
+	}
+
+	// The HLS renditions replace the lower quality progressive files
+	for _, quality := range video.RenditionResolutions {
+		qualityPath := filepath.Join(hlsDir, storage.ProgressiveQualityName(quality))
+		if info, err := os.Stat(qualityPath); err == nil {
+			if err := os.Remove(qualityPath); err != nil {
+				log.Printf("Warning: failed to remove %s quality file: %v", quality, err)
+			} else {
+				size -= info.Size()
+			}
+		}
+	}
+
+	if _, err := w.database.Queries.UpdateVideoProcessing(ctx, sqlc.UpdateVideoProcessingParams{
+		ID:               videoUUID,
+		ProcessingStatus: domain.ProcessingStatusCompleted,
+		ErrorMessage:     nil,
+		DurationSeconds:  videoRecord.DurationSeconds,
+		FileSizeBytes:    size,
+		Column6:          hlsName,
+		Column7:          "", // thumbnail_filename - empty keeps existing
+	}); err != nil {
+		os.RemoveAll(hlsDir)
+		return fmt.Errorf("failed to update video record: %w", err)
+	}
+	if count, err := w.processor.GetFFmpeg().CountOutputAudioTracks(ctx, hlsDir); err != nil {
+		log.Printf("Warning: failed to count audio tracks: %v", err)
+	} else {
+		audioTracks := int32(count)
+		if err := w.database.Queries.UpdateVideoAudioTrackCount(ctx, sqlc.UpdateVideoAudioTrackCountParams{
+			ID:              videoUUID,
+			AudioTrackCount: &audioTracks,
+		}); err != nil {
+			log.Printf("Warning: failed to update audio track count: %v", err)
+		}
+	}
+	videoCodec := transcodeCfg.OutputCodec()
+	if err := w.database.Queries.UpdateVideoCodec(ctx, sqlc.UpdateVideoCodecParams{
+		ID:         videoUUID,
+		VideoCodec: &videoCodec,
+	}); err != nil {
+		log.Printf("Warning: failed to update video codec: %v", err)
+	}
+	storeOutputSha256(ctx, w.database, videoUUID, base, hlsName)
+
+	if err := os.Remove(inputPath); err != nil {
+		log.Printf("Warning: failed to remove migrated MP4: %v", err)
+	}
+
+	log.Printf("HLS migration completed for video: %s (size=%d)", videoID, size)
+	return nil
+}
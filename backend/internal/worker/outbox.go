@@ -0,0 +1,91 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/riverqueue/river"
+
+	"github.com/clipset/clipset-go/internal/db"
+	"github.com/clipset/clipset-go/internal/db/sqlc"
+)
+
+const (
+	// outboxGracePeriod gives upload handlers time to enqueue their own jobs
+	// before the outbox picks up the video
+	outboxGracePeriod = 2 * time.Minute
+
+	// outboxBatchSize is the maximum number of videos enqueued per poll
+	outboxBatchSize = 100
+)
+
+// EnqueueOutboxJobArgs defines the arguments for the periodic enqueue outbox job
+type EnqueueOutboxJobArgs struct{}
+
+// Kind returns the job type identifier
+func (EnqueueOutboxJobArgs) Kind() string {
+	return "enqueue_outbox"
+}
+
+// EnqueueOutboxWorker enqueues transcode jobs for videos still marked needs_enqueue,
+// e.g. because the upload handler failed or the process died before enqueueing
+type EnqueueOutboxWorker struct {
+	river.WorkerDefaults[EnqueueOutboxJobArgs]
+	database *db.DB
+}
+
+// NewEnqueueOutboxWorker creates a new enqueue outbox worker
+func NewEnqueueOutboxWorker(database *db.DB) *EnqueueOutboxWorker {
+	return &EnqueueOutboxWorker{
+		database: database,
+	}
+}
+
+// Work enqueues pending videos, clearing each marker in the same transaction as the job insert
+func (w *EnqueueOutboxWorker) Work(ctx context.Context, job *river.Job[EnqueueOutboxJobArgs]) error {
+	videoIDs, err := w.database.Queries.ListVideosNeedingEnqueue(ctx, sqlc.ListVideosNeedingEnqueueParams{
+		CreatedAt: time.Now().Add(-outboxGracePeriod),
+		Limit:     outboxBatchSize,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list videos needing enqueue: %w", err)
+	}
+
+	if len(videoIDs) == 0 {
+		return nil
+	}
+
+	client := river.ClientFromContext[pgx.Tx](ctx)
+
+	enqueued := 0
+	for _, videoID := range videoIDs {
+		tx, err := w.database.Pool.Begin(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+
+		if _, err := client.InsertTx(ctx, tx, TranscodeJobArgs{VideoID: videoID.String()}, nil); err != nil {
+			tx.Rollback(ctx)
+			log.Printf("Outbox: failed to enqueue transcode job for video %s: %v", videoID, err)
+			continue
+		}
+
+		if err := w.database.Queries.WithTx(tx).ClearVideoNeedsEnqueue(ctx, videoID); err != nil {
+			tx.Rollback(ctx)
+			log.Printf("Outbox: failed to clear needs_enqueue for video %s: %v", videoID, err)
+			continue
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			log.Printf("Outbox: failed to commit enqueue for video %s: %v", videoID, err)
+			continue
+		}
+		enqueued++
+	}
+
+	log.Printf("Outbox: enqueued %d/%d pending transcode jobs", enqueued, len(videoIDs))
+	return nil
+}
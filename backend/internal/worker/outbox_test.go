@@ -0,0 +1,138 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivermigrate"
+	"github.com/riverqueue/river/rivertest"
+
+	"github.com/clipset/clipset-go/internal/db"
+	"github.com/clipset/clipset-go/internal/db/dbtest"
+	"github.com/clipset/clipset-go/internal/db/sqlc"
+	"github.com/clipset/clipset-go/internal/domain"
+)
+
+// newOutboxTest returns a test database with River's tables and a context
+// carrying an insert-only River client, as the outbox job runs with
+func newOutboxTest(t *testing.T) (*db.DB, context.Context) {
+	t.Helper()
+	database := dbtest.Connect(t)
+	ctx := context.Background()
+
+	driver := riverpgxv5.New(database.Pool)
+	migrator, err := rivermigrate.New(driver, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Migrate(ctx, rivermigrate.DirectionUp, nil); err != nil {
+		t.Fatalf("failed to run River migrations: %v", err)
+	}
+
+	client, err := river.NewClient(driver, &river.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return database, rivertest.WorkContext(ctx, client)
+}
+
+// transcodeJobCount returns how many transcode jobs exist for a video
+func transcodeJobCount(t *testing.T, ctx context.Context, database *db.DB, videoID uuid.UUID) int {
+	t.Helper()
+	var count int
+	err := database.Pool.QueryRow(ctx,
+		`SELECT count(*) FROM river_job WHERE kind = $1 AND args->>'video_id' = $2`,
+		TranscodeJobArgs{}.Kind(), videoID.String(),
+	).Scan(&count)
+	if err != nil {
+		t.Fatalf("failed to count transcode jobs: %v", err)
+	}
+	return count
+}
+
+// Each video is left as a process killed at some point of an upload would
+// leave it; repeated outbox runs must converge on one transcode job for every
+// committed upload older than the grace period
+func TestEnqueueOutboxConvergesAfterKilledUploads(t *testing.T) {
+	database, ctx := newOutboxTest(t)
+
+	user, err := database.Queries.CreateUser(ctx, sqlc.CreateUserParams{
+		Email:        "uploader@example.com",
+		Username:     "uploader",
+		PasswordHash: "unused",
+		Role:         domain.UserRoleUser,
+	})
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	client := river.ClientFromContext[pgx.Tx](ctx)
+	tests := []struct {
+		name     string
+		age      time.Duration
+		enqueued bool // The upload handler enqueued the job and cleared the marker
+		wantJobs int
+	}{
+		{"killed after commit, before enqueueing", 5 * time.Minute, false, 1},
+		{"enqueued by the upload handler", 5 * time.Minute, true, 1},
+		{"killed within the grace period", 0, false, 0},
+	}
+
+	videoIDs := make([]uuid.UUID, len(tests))
+	for i, tt := range tests {
+		video, err := database.Queries.CreateVideo(ctx, sqlc.CreateVideoParams{
+			ShortID:          fmt.Sprintf("outbox%d", i),
+			Title:            tt.name,
+			Filename:         uuid.NewString() + ".mp4",
+			OriginalFilename: "upload.mp4",
+			FileSizeBytes:    1024,
+			UploadedBy:       user.ID,
+		})
+		if err != nil {
+			t.Fatalf("failed to create video: %v", err)
+		}
+		videoIDs[i] = video.ID
+
+		if _, err := database.Pool.Exec(ctx, `UPDATE videos SET created_at = NOW() - make_interval(secs => $2) WHERE id = $1`,
+			video.ID, tt.age.Seconds()); err != nil {
+			t.Fatalf("failed to age video: %v", err)
+		}
+		if tt.enqueued {
+			if _, err := client.Insert(ctx, TranscodeJobArgs{VideoID: video.ID.String()}, nil); err != nil {
+				t.Fatalf("failed to enqueue transcode job: %v", err)
+			}
+			if err := database.Queries.ClearVideoNeedsEnqueue(ctx, video.ID); err != nil {
+				t.Fatalf("failed to clear needs_enqueue: %v", err)
+			}
+		}
+	}
+
+	// The outbox job is itself killed and rerun, or runs again on its next poll
+	outbox := NewEnqueueOutboxWorker(database)
+	for run := range 3 {
+		if err := outbox.Work(ctx, &river.Job[EnqueueOutboxJobArgs]{}); err != nil {
+			t.Fatalf("outbox run %d failed: %v", run, err)
+		}
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transcodeJobCount(t, ctx, database, videoIDs[i]); got != tt.wantJobs {
+				t.Errorf("%d transcode jobs, want %d", got, tt.wantJobs)
+			}
+			video, err := database.Queries.GetVideoByID(ctx, videoIDs[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if wantMarked := tt.wantJobs == 0; video.NeedsEnqueue != wantMarked {
+				t.Errorf("needs_enqueue = %v, want %v", video.NeedsEnqueue, wantMarked)
+			}
+		})
+	}
+}
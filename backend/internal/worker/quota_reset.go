@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/riverqueue/river"
+
+	"github.com/clipset/clipset-go/internal/config"
+	"github.com/clipset/clipset-go/internal/db"
+)
+
+// quotaResetCheckInterval is how often the worker looks for quotas due a reset
+const quotaResetCheckInterval = 15 * time.Minute
+
+// QuotaResetJobArgs defines the arguments for the periodic weekly quota reset job
+type QuotaResetJobArgs struct{}
+
+// Kind returns the job type identifier
+func (QuotaResetJobArgs) Kind() string {
+	return "quota_reset"
+}
+
+// QuotaResetWorker resets the weekly upload usage of users whose last reset
+// is older than the current quota period
+type QuotaResetWorker struct {
+	river.WorkerDefaults[QuotaResetJobArgs]
+	database *db.DB
+	config   *config.Config
+}
+
+// NewQuotaResetWorker creates a new quota reset worker
+func NewQuotaResetWorker(database *db.DB, cfg *config.Config) *QuotaResetWorker {
+	return &QuotaResetWorker{
+		database: database,
+		config:   cfg,
+	}
+}
+
+// Work resets every quota due. The reset and the new last_upload_reset are
+// written in one statement, so a restart can neither skip nor repeat a user.
+func (w *QuotaResetWorker) Work(ctx context.Context, job *river.Job[QuotaResetJobArgs]) error {
+	cutoff := quotaResetCutoff(time.Now().UTC(), w.config)
+
+	count, err := w.database.Queries.ResetExpiredUploadQuotas(ctx, cutoff)
+	if err != nil {
+		return fmt.Errorf("failed to reset upload quotas: %w", err)
+	}
+
+	log.Printf("Weekly quota reset: reset %d users (cutoff %s)", count, cutoff.Format(time.RFC3339))
+	return nil
+}
+
+// quotaResetCutoff returns the start of the current quota period; users last
+// reset before it are due. With QUOTA_RESET_DAY set this is the most recent
+// occurrence of that weekday at 00:00 UTC, otherwise 7 days ago.
+func quotaResetCutoff(now time.Time, cfg *config.Config) time.Time {
+	day, ok := cfg.QuotaResetWeekday()
+	if !ok {
+		return now.Add(-7 * 24 * time.Hour)
+	}
+
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	daysSince := (int(now.Weekday()) - int(day) + 7) % 7
+	return midnight.AddDate(0, 0, -daysSince)
+}
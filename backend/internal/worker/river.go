@@ -3,20 +3,33 @@ package worker
 
 import (
 	"context"
+	"encoding/json"
 	"log"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/riverqueue/river"
 	"github.com/riverqueue/river/riverdriver/riverpgxv5"
 	"github.com/riverqueue/river/rivermigrate"
+	"github.com/riverqueue/river/rivertype"
 
+	"github.com/clipset/clipset-go/internal/api/handlers"
 	"github.com/clipset/clipset-go/internal/config"
 	"github.com/clipset/clipset-go/internal/db"
+	"github.com/clipset/clipset-go/internal/services/upload"
 	"github.com/clipset/clipset-go/internal/services/video"
 )
 
+const (
+	// chunkCleanupInterval is how often expired chunked upload sessions are removed
+	chunkCleanupInterval = 1 * time.Hour
+
+	// enqueueOutboxInterval is how often videos marked needs_enqueue are picked up
+	enqueueOutboxInterval = 1 * time.Minute
+)
+
 // Worker manages background job processing
 type Worker struct {
 	client    *river.Client[pgx.Tx]
@@ -37,12 +50,15 @@ type Config struct {
 func New(cfg Config) (*Worker, error) {
 	// Create video processor
 	processorCfg := video.ProcessorConfig{
-		FFmpegPath:     cfg.AppConfig.FFmpegPath,
-		FFprobePath:    cfg.AppConfig.FFprobePath,
-		ProcessTimeout: cfg.AppConfig.VideoProcessingTimeout,
-		TempPath:       cfg.AppConfig.TempStoragePath,
-		VideoPath:      cfg.AppConfig.VideoStoragePath,
-		ThumbnailPath:  cfg.AppConfig.ThumbnailStoragePath,
+		FFmpegPath:        cfg.AppConfig.FFmpegPath,
+		FFprobePath:       cfg.AppConfig.FFprobePath,
+		ProcessTimeout:    cfg.AppConfig.VideoProcessingTimeout,
+		TimeoutBase:       cfg.AppConfig.VideoProcessingTimeoutBase,
+		TimeoutFactor:     cfg.AppConfig.VideoProcessingTimeoutFactor,
+		MaxProcessTimeout: cfg.AppConfig.VideoProcessingTimeoutMax,
+		TempPath:          cfg.AppConfig.TempStoragePath,
+		VideoPath:         cfg.AppConfig.VideoStoragePath,
+		ThumbnailPath:     cfg.AppConfig.ThumbnailStoragePath,
 	}
 
 	processor := video.NewProcessor(processorCfg)
@@ -78,18 +94,83 @@ func (w *Worker) Start(ctx context.Context) error {
 	// Create transcode worker with dependencies
 	transcodeWorker := NewTranscodeWorker(w.database, w.config, w.processor)
 
+	// Create chunk cleanup worker
+	chunkManager := upload.NewChunkedUploadManager(w.config.ChunksStoragePath, w.config.ChunkSessionTTL)
+	chunkCleanupWorker := NewChunkCleanupWorker(chunkManager)
+
 	// Configure River workers
 	workers := river.NewWorkers()
 	river.AddWorker(workers, transcodeWorker)
+	river.AddWorker(workers, NewHLSMigrationWorker(w.database, w.config, w.processor))
+	river.AddWorker(workers, chunkCleanupWorker)
+	river.AddWorker(workers, NewEnqueueOutboxWorker(w.database))
+	river.AddWorker(workers, NewStorageStatsWorker(w.config))
+	river.AddWorker(workers, NewStorageScanWorker(w.database, w.config))
+	river.AddWorker(workers, NewQuotaResetWorker(w.database, w.config))
+	river.AddWorker(workers, NewBenchmarkWorker(w.database, w.config, w.processor))
+	river.AddWorker(workers, NewWebhookDeliveryWorker(w.database))
+	river.AddWorker(workers, NewChecksumBackfillWorker(w.database, w.config))
+	river.AddWorker(workers, NewTempCleanupWorker(w.database, w.config))
+
+	// Periodic maintenance jobs
+	periodicJobs := []*river.PeriodicJob{
+		river.NewPeriodicJob(
+			river.PeriodicInterval(chunkCleanupInterval),
+			func() (river.JobArgs, *river.InsertOpts) {
+				return ChunkCleanupJobArgs{}, nil
+			},
+			&river.PeriodicJobOpts{RunOnStart: true},
+		),
+		river.NewPeriodicJob(
+			river.PeriodicInterval(enqueueOutboxInterval),
+			func() (river.JobArgs, *river.InsertOpts) {
+				return EnqueueOutboxJobArgs{}, nil
+			},
+			&river.PeriodicJobOpts{RunOnStart: true},
+		),
+		river.NewPeriodicJob(
+			river.PeriodicInterval(w.config.StorageStatsInterval),
+			func() (river.JobArgs, *river.InsertOpts) {
+				return StorageStatsJobArgs{}, nil
+			},
+			&river.PeriodicJobOpts{RunOnStart: true},
+		),
+		river.NewPeriodicJob(
+			river.PeriodicInterval(quotaResetCheckInterval),
+			func() (river.JobArgs, *river.InsertOpts) {
+				return QuotaResetJobArgs{}, nil
+			},
+			&river.PeriodicJobOpts{RunOnStart: true},
+		),
+		river.NewPeriodicJob(
+			river.PeriodicInterval(checksumBackfillInterval),
+			func() (river.JobArgs, *river.InsertOpts) {
+				return ChecksumBackfillJobArgs{}, nil
+			},
+			&river.PeriodicJobOpts{RunOnStart: true},
+		),
+		river.NewPeriodicJob(
+			river.PeriodicInterval(w.config.TempCleanupInterval),
+			func() (river.JobArgs, *river.InsertOpts) {
+				return TempCleanupJobArgs{}, nil
+			},
+			&river.PeriodicJobOpts{RunOnStart: true},
+		),
+	}
 
 	// Configure River client
 	riverConfig := &river.Config{
 		Queues: map[string]river.QueueConfig{
-			river.QueueDefault: {MaxWorkers: 2}, // 2 concurrent video processing jobs
+			// Transcodes are limited by the worker_concurrency setting
+			// (see transcodeSlots); the spare workers keep maintenance jobs
+			// running alongside the most transcodes allowed
+			river.QueueDefault: {MaxWorkers: config.MaxWorkerConcurrency + 2},
+			webhookQueue:       {MaxWorkers: webhookQueueWorkers},
 		},
 		Workers:              workers,
-		JobTimeout:           4 * time.Hour, // Long timeout for video processing
-		RescueStuckJobsAfter: 6 * time.Hour,
+		PeriodicJobs:         periodicJobs,
+		JobTimeout:           w.config.VideoProcessingTimeoutMax + time.Hour, // Longest transcode, plus the steps around it
+		RescueStuckJobsAfter: w.config.VideoProcessingTimeoutMax + 3*time.Hour,
 	}
 
 	// Create River client
@@ -145,3 +226,123 @@ func (w *Worker) EnqueueTranscode(ctx context.Context, videoID string) error {
 	log.Printf("Enqueued transcode job for video: %s", videoID)
 	return nil
 }
+
+// EnqueueHLSMigration adds a low-priority job converting a progressive video to HLS.
+// Jobs aren't retried; a failure is reported in the migration status instead.
+func (w *Worker) EnqueueHLSMigration(ctx context.Context, videoID string, migrationID int64) error {
+	_, err := w.client.Insert(ctx, HLSMigrationJobArgs{VideoID: videoID, MigrationID: migrationID}, &river.InsertOpts{
+		MaxAttempts: 1,
+		Priority:    hlsMigrationPriority,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Enqueued HLS migration job for video: %s", videoID)
+	return nil
+}
+
+// EnqueueStorageScan adds an orphaned file scan job to the queue. The scan
+// isn't retried; a failure leaves the previous report in place.
+func (w *Worker) EnqueueStorageScan(ctx context.Context) error {
+	_, err := w.client.Insert(ctx, StorageScanJobArgs{}, &river.InsertOpts{MaxAttempts: 1})
+	if err != nil {
+		return err
+	}
+
+	log.Println("Enqueued storage scan job")
+	return nil
+}
+
+// EnqueueBenchmark adds an encoder benchmark job to the queue. Returns
+// video.ErrQueueBusy while transcode or HLS migration jobs are queued or
+// running, since they would skew the timings.
+func (w *Worker) EnqueueBenchmark(ctx context.Context, sampleSeconds int, videoID string) error {
+	active, err := w.client.JobList(ctx, river.NewJobListParams().
+		Kinds(TranscodeJobArgs{}.Kind(), HLSMigrationJobArgs{}.Kind()).
+		States(activeVideoJobStates...).
+		First(1))
+	if err != nil {
+		return err
+	}
+	if len(active.Jobs) > 0 {
+		return video.ErrQueueBusy
+	}
+
+	_, err = w.client.Insert(ctx, BenchmarkJobArgs{SampleSeconds: sampleSeconds, VideoID: videoID}, &river.InsertOpts{MaxAttempts: 1})
+	if err != nil {
+		return err
+	}
+
+	log.Println("Enqueued encoder benchmark job")
+	return nil
+}
+
+// EnqueueWebhookEvent queues deliveries of a video event to the webhooks
+// subscribed to it
+func (w *Worker) EnqueueWebhookEvent(ctx context.Context, event string, videoID uuid.UUID) error {
+	return enqueueWebhookEvent(ctx, w.client, w.database, w.config, event, videoID, nil)
+}
+
+// transcodeJobCountsSQL counts transcode jobs by state. River's client has no
+// count API, so this reads its river_job table directly.
+const transcodeJobCountsSQL = `SELECT state::text, COUNT(*) FROM river_job WHERE kind = $1 GROUP BY state`
+
+// ListTranscodeJobs returns up to limit transcode jobs in the given states,
+// oldest first, and the number of transcode jobs in every state
+func (w *Worker) ListTranscodeJobs(ctx context.Context, states []string, limit int) (handlers.JobQueueSnapshot, error) {
+	jobStates := make([]rivertype.JobState, len(states))
+	for i, state := range states {
+		jobStates[i] = rivertype.JobState(state)
+	}
+
+	listed, err := w.client.JobList(ctx, river.NewJobListParams().
+		Kinds(TranscodeJobArgs{}.Kind()).
+		States(jobStates...).
+		First(limit))
+	if err != nil {
+		return handlers.JobQueueSnapshot{}, err
+	}
+
+	snapshot := handlers.JobQueueSnapshot{
+		Jobs:   make([]handlers.QueuedJob, 0, len(listed.Jobs)),
+		Counts: make(map[string]int64),
+	}
+	for _, job := range listed.Jobs {
+		var args TranscodeJobArgs
+		if err := json.Unmarshal(job.EncodedArgs, &args); err != nil {
+			log.Printf("Warning: failed to decode args of job %d: %v", job.ID, err)
+		}
+		snapshot.Jobs = append(snapshot.Jobs, handlers.QueuedJob{
+			ID:          job.ID,
+			VideoID:     args.VideoID,
+			State:       string(job.State),
+			Attempt:     job.Attempt,
+			MaxAttempts: job.MaxAttempts,
+			EnqueuedAt:  job.CreatedAt,
+			StartedAt:   job.AttemptedAt,
+		})
+	}
+
+	for _, state := range rivertype.JobStates() {
+		snapshot.Counts[string(state)] = 0
+	}
+	rows, err := w.pool.Query(ctx, transcodeJobCountsSQL, TranscodeJobArgs{}.Kind())
+	if err != nil {
+		return handlers.JobQueueSnapshot{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var state string
+		var count int64
+		if err := rows.Scan(&state, &count); err != nil {
+			return handlers.JobQueueSnapshot{}, err
+		}
+		snapshot.Counts[state] = count
+	}
+	if err := rows.Err(); err != nil {
+		return handlers.JobQueueSnapshot{}, err
+	}
+
+	return snapshot, nil
+}
@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/riverqueue/river"
+
+	"github.com/clipset/clipset-go/internal/config"
+	"github.com/clipset/clipset-go/internal/db"
+	"github.com/clipset/clipset-go/internal/domain"
+	"github.com/clipset/clipset-go/internal/services/storage"
+)
+
+// storageScanMinAge skips files this recent, which may belong to an upload
+// that hasn't created its video row yet
+const storageScanMinAge = 1 * time.Hour
+
+// StorageScanJobArgs defines the arguments for an orphaned file scan
+type StorageScanJobArgs struct{}
+
+// Kind returns the job type identifier
+func (StorageScanJobArgs) Kind() string {
+	return "storage_scan"
+}
+
+// StorageScanWorker diffs the storage directories against the videos table
+// and publishes the report for the storage scan endpoints
+type StorageScanWorker struct {
+	river.WorkerDefaults[StorageScanJobArgs]
+	database *db.DB
+	config   *config.Config
+}
+
+// NewStorageScanWorker creates a new storage scan worker
+func NewStorageScanWorker(database *db.DB, cfg *config.Config) *StorageScanWorker {
+	return &StorageScanWorker{
+		database: database,
+		config:   cfg,
+	}
+}
+
+// Work scans for orphaned and missing files
+func (w *StorageScanWorker) Work(ctx context.Context, job *river.Job[StorageScanJobArgs]) error {
+	rows, err := w.database.Queries.ListVideoFiles(ctx)
+	if err != nil {
+		storage.FinishScan(nil)
+		return fmt.Errorf("failed to list video files: %w", err)
+	}
+
+	videos := make([]storage.VideoFiles, len(rows))
+	for i, row := range rows {
+		videos[i] = storage.VideoFiles{
+			VideoID:           row.ID.String(),
+			Filename:          row.Filename,
+			ThumbnailFilename: row.ThumbnailFilename,
+			StoragePath:       row.StoragePath,
+			Completed:         row.ProcessingStatus == domain.ProcessingStatusCompleted,
+		}
+	}
+
+	report := storage.ScanFiles(storage.ScanConfig{
+		VideoPath:       w.config.VideoStoragePath,
+		ThumbnailPath:   w.config.ThumbnailStoragePath,
+		TempPath:        w.config.TempStoragePath,
+		ChunksPath:      w.config.ChunksStoragePath,
+		ChunkSessionTTL: w.config.ChunkSessionTTL,
+		MinAge:          storageScanMinAge,
+	}, videos)
+	storage.FinishScan(&report)
+
+	log.Printf("Storage scan: %d orphaned files (%d bytes), %d missing files",
+		len(report.Orphans), report.OrphanedBytes, len(report.Missing))
+	return nil
+}
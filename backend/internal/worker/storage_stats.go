@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"context"
+	"log"
+
+	"github.com/riverqueue/river"
+
+	"github.com/clipset/clipset-go/internal/config"
+	"github.com/clipset/clipset-go/internal/services/storage"
+)
+
+// StorageStatsJobArgs defines the arguments for the periodic storage usage job
+type StorageStatsJobArgs struct{}
+
+// Kind returns the job type identifier
+func (StorageStatsJobArgs) Kind() string {
+	return "storage_stats"
+}
+
+// StorageStatsWorker measures the storage directories and publishes the
+// result for the storage stats endpoint
+type StorageStatsWorker struct {
+	river.WorkerDefaults[StorageStatsJobArgs]
+	config *config.Config
+}
+
+// NewStorageStatsWorker creates a new storage stats worker
+func NewStorageStatsWorker(cfg *config.Config) *StorageStatsWorker {
+	return &StorageStatsWorker{
+		config: cfg,
+	}
+}
+
+// Work measures every configured storage directory
+func (w *StorageStatsWorker) Work(ctx context.Context, job *river.Job[StorageStatsJobArgs]) error {
+	report := storage.MeasureUsage([]storage.UsageDirectory{
+		// HLS output lives in per-video directories next to the progressive MP4s
+		{Name: "videos", Path: w.config.VideoStoragePath, Scope: storage.UsageTopLevel},
+		{Name: "hls", Path: w.config.VideoStoragePath, Scope: storage.UsageSubdirs},
+		{Name: "thumbnails", Path: w.config.ThumbnailStoragePath},
+		{Name: "temp", Path: w.config.TempStoragePath},
+		{Name: "chunks", Path: w.config.ChunksStoragePath},
+		{Name: "avatars", Path: w.config.AvatarStoragePath},
+		{Name: "category_images", Path: w.config.CategoryImageStoragePath},
+	})
+	storage.SetLatestUsage(report)
+
+	for _, dir := range report.Directories {
+		if dir.Error != "" {
+			log.Printf("Storage stats: failed to measure %s (%s): %s", dir.Name, dir.Path, dir.Error)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/riverqueue/river"
+
+	"github.com/clipset/clipset-go/internal/config"
+	"github.com/clipset/clipset-go/internal/db"
+	"github.com/clipset/clipset-go/internal/domain"
+	"github.com/clipset/clipset-go/internal/services/storage"
+	"github.com/clipset/clipset-go/internal/services/upload"
+)
+
+// TempCleanupJobArgs defines the arguments for the periodic temp cleanup job
+type TempCleanupJobArgs struct{}
+
+// Kind returns the job type identifier
+func (TempCleanupJobArgs) Kind() string {
+	return "temp_cleanup"
+}
+
+// InsertOpts doesn't retry cleanups; the next run picks up what a failed one missed
+func (TempCleanupJobArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{MaxAttempts: 1}
+}
+
+// TempCleanupWorker removes stale files left in the temp directory by failed
+// uploads and interrupted transcodes
+type TempCleanupWorker struct {
+	river.WorkerDefaults[TempCleanupJobArgs]
+	database *db.DB
+	config   *config.Config
+}
+
+// NewTempCleanupWorker creates a new temp cleanup worker
+func NewTempCleanupWorker(database *db.DB, cfg *config.Config) *TempCleanupWorker {
+	return &TempCleanupWorker{
+		database: database,
+		config:   cfg,
+	}
+}
+
+// Work removes temp files older than TEMP_FILE_MAX_AGE that no video or
+// upload session is using
+func (w *TempCleanupWorker) Work(ctx context.Context, job *river.Job[TempCleanupJobArgs]) error {
+	result, err := cleanTempFiles(ctx, w.database, w.config, false)
+	if err != nil {
+		return err
+	}
+
+	if len(result.Removed) > 0 || len(result.Errors) > 0 {
+		log.Printf("Temp cleanup: removed %d stale files, reclaimed %d bytes (%d errors)",
+			len(result.Removed), result.RemovedBytes, len(result.Errors))
+	}
+	for _, msg := range result.Errors {
+		log.Printf("Warning: temp cleanup: %s", msg)
+	}
+	return nil
+}
+
+// CleanTempFiles removes stale temp files right away, for the admin endpoint.
+// With dryRun set, nothing is deleted and the result lists what would be.
+func (w *Worker) CleanTempFiles(ctx context.Context, dryRun bool) (storage.CleanupResult, error) {
+	result, err := cleanTempFiles(ctx, w.database, w.config, dryRun)
+	if err != nil {
+		return result, err
+	}
+
+	if !dryRun && len(result.Removed) > 0 {
+		log.Printf("Temp cleanup (admin): removed %d stale files, reclaimed %d bytes",
+			len(result.Removed), result.RemovedBytes)
+	}
+	return result, nil
+}
+
+// cleanTempFiles removes temp files older than TEMP_FILE_MAX_AGE, keeping the
+// sources of videos that haven't finished processing (queued, transcoding, or
+// failed and waiting for a retry) and unexpired chunked upload sessions
+func cleanTempFiles(ctx context.Context, database *db.DB, cfg *config.Config, dryRun bool) (storage.CleanupResult, error) {
+	rows, err := database.Queries.ListVideoFiles(ctx)
+	if err != nil {
+		return storage.CleanupResult{}, fmt.Errorf("failed to list video files: %w", err)
+	}
+
+	inUse := make(map[string]bool)
+	for _, row := range rows {
+		if row.ProcessingStatus != domain.ProcessingStatusCompleted {
+			inUse[row.Filename] = true
+		}
+	}
+
+	// Only matters when the chunks directory is the temp directory itself;
+	// expired sessions are left to the chunk cleanup job
+	sessions, err := upload.NewChunkedUploadManager(cfg.ChunksStoragePath, cfg.ChunkSessionTTL).ListSessions()
+	if err != nil {
+		return storage.CleanupResult{}, err
+	}
+	for _, session := range sessions {
+		if !session.Expired {
+			inUse[session.UploadID] = true
+		}
+	}
+
+	return storage.CleanTempFiles(storage.TempCleanupConfig{
+		TempPath:   cfg.TempStoragePath,
+		ChunksPath: cfg.ChunksStoragePath,
+		MaxAge:     cfg.TempFileMaxAge,
+		InUse:      inUse,
+		DryRun:     dryRun,
+	})
+}
@@ -2,12 +2,14 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/riverqueue/river"
@@ -16,7 +18,22 @@ import (
 	"github.com/clipset/clipset-go/internal/db"
 	"github.com/clipset/clipset-go/internal/db/sqlc"
 	"github.com/clipset/clipset-go/internal/domain"
+	"github.com/clipset/clipset-go/internal/services/storage"
 	"github.com/clipset/clipset-go/internal/services/video"
+	"github.com/clipset/clipset-go/internal/services/webhook"
+)
+
+// transcodeLogsKept is how many processing logs are kept per video
+const transcodeLogsKept = 5
+
+const (
+	// transcodeOutputFactor is the output space needed per byte of source,
+	// covering a larger encode plus an HLS ladder
+	transcodeOutputFactor = 2
+
+	// diskSpaceDelay is how long a transcode waits for disk space to be freed
+	// before it's tried again
+	diskSpaceDelay = 5 * time.Minute
 )
 
 // TranscodeJobArgs defines the arguments for a transcode job
@@ -64,6 +81,30 @@ func (w *TranscodeWorker) Work(ctx context.Context, job *river.Job[TranscodeJobA
 		return fmt.Errorf("failed to get video: %w", err)
 	}
 
+	// Get transcoding config from database
+	dbConfig, err := w.database.Queries.GetConfig(ctx)
+	if err != nil {
+		log.Printf("Warning: failed to get DB config, using defaults: %v", err)
+		dbConfig = defaultDBConfig()
+	}
+
+	// Wait for a free slot if the concurrency limit is reached
+	release, ok := acquireTranscodeSlot(dbConfig, w.config)
+	if !ok {
+		return river.JobSnooze(transcodeSlotDelay)
+	}
+	defer release()
+
+	// Wait for space rather than fail part way through writing the output
+	if !w.hasOutputSpace(videoRecord.Filename) {
+		return river.JobSnooze(diskSpaceDelay)
+	}
+
+	// Job is running, so the video no longer needs to go through the enqueue outbox
+	if err := w.database.Queries.ClearVideoNeedsEnqueue(ctx, videoUUID); err != nil {
+		log.Printf("Warning: failed to clear needs_enqueue: %v", err)
+	}
+
 	// Update status to processing
 	if _, err := w.database.Queries.UpdateVideoProcessing(ctx, sqlc.UpdateVideoProcessingParams{
 		ID:               videoUUID,
@@ -77,13 +118,6 @@ func (w *TranscodeWorker) Work(ctx context.Context, job *river.Job[TranscodeJobA
 		log.Printf("Warning: failed to update video status to processing: %v", err)
 	}
 
-	// Get transcoding config from database
-	dbConfig, err := w.database.Queries.GetConfig(ctx)
-	if err != nil {
-		log.Printf("Warning: failed to get DB config, using defaults: %v", err)
-		dbConfig = defaultDBConfig()
-	}
-
 	// Build transcode config
 	transcodeCfg := buildTranscodeConfig(dbConfig)
 
@@ -95,25 +129,30 @@ func (w *TranscodeWorker) Work(ctx context.Context, job *river.Job[TranscodeJobA
 	// Check if temp file exists
 	if _, err := os.Stat(tempPath); os.IsNotExist(err) {
 		errMsg := "temp file not found"
-		w.updateVideoFailed(ctx, videoUUID, errMsg)
+		w.updateVideoFailed(ctx, job, videoUUID, errMsg)
 		return fmt.Errorf("temp file not found: %s", tempPath)
 	}
 
-	// Process video
+	// Process video, publishing progress for the processing-progress endpoint
+	defer video.ClearProcessingProgress(videoID)
+	transcodeLog := &video.TranscodeLog{}
 	result, err := w.processor.ProcessVideo(
-		ctx,
+		video.WithTranscodeLog(ctx, transcodeLog),
 		tempPath,
 		outputFilename,
 		thumbnailFilename,
 		transcodeCfg,
 		dbConfig.VideoOutputFormat,
+		func(phase string, percent float64) {
+			video.SetProcessingProgress(videoID, phase, percent)
+		},
 	)
+	saveTranscodeLog(ctx, w.database, videoUUID, TranscodeJobArgs{}.Kind(), err == nil && result.Success, transcodeLog)
 
 	if err != nil {
 		errMsg := fmt.Sprintf("processing failed: %v", err)
-		w.updateVideoFailed(ctx, videoUUID, errMsg)
-		// Clean up temp file on failure
-		os.Remove(tempPath)
+		w.updateVideoFailed(ctx, job, videoUUID, errMsg)
+		// The temp file is kept so the video can be retried from the failed videos view
 		return fmt.Errorf("video processing failed: %w", err)
 	}
 
@@ -122,8 +161,7 @@ func (w *TranscodeWorker) Work(ctx context.Context, job *river.Job[TranscodeJobA
 		if errMsg == "" {
 			errMsg = "processing failed for unknown reason"
 		}
-		w.updateVideoFailed(ctx, videoUUID, errMsg)
-		os.Remove(tempPath)
+		w.updateVideoFailed(ctx, job, videoUUID, errMsg)
 		return fmt.Errorf("video processing failed: %s", errMsg)
 	}
 
@@ -139,6 +177,7 @@ func (w *TranscodeWorker) Work(ctx context.Context, job *river.Job[TranscodeJobA
 	}
 
 	// Update video record with results
+	video.SetProcessingProgress(videoID, video.PhaseFinalizing, 100)
 	duration := int32(result.Duration)
 
 	if _, err := w.database.Queries.UpdateVideoProcessing(ctx, sqlc.UpdateVideoProcessingParams{
@@ -153,20 +192,39 @@ func (w *TranscodeWorker) Work(ctx context.Context, job *river.Job[TranscodeJobA
 		log.Printf("Error updating video after processing: %v", err)
 		return fmt.Errorf("failed to update video record: %w", err)
 	}
+	if result.AudioTracks >= 0 {
+		audioTracks := int32(result.AudioTracks)
+		if err := w.database.Queries.UpdateVideoAudioTrackCount(ctx, sqlc.UpdateVideoAudioTrackCountParams{
+			ID:              videoUUID,
+			AudioTrackCount: &audioTracks,
+		}); err != nil {
+			log.Printf("Warning: failed to update audio track count: %v", err)
+		}
+	}
+	if err := w.database.Queries.UpdateVideoCodec(ctx, sqlc.UpdateVideoCodecParams{
+		ID:         videoUUID,
+		VideoCodec: &result.VideoCodec,
+	}); err != nil {
+		log.Printf("Warning: failed to update video codec: %v", err)
+	}
+	storeOutputSha256(ctx, w.database, videoUUID, videoBasePath(w.config, videoRecord.StoragePath), finalFilename)
 
 	// Clean up temp file
 	if err := os.Remove(tempPath); err != nil {
 		log.Printf("Warning: failed to remove temp file: %v", err)
 	}
 
+	notifyWebhooks(ctx, w.database, w.config, webhook.EventVideoCompleted, videoUUID, nil)
+
 	log.Printf("Transcode job completed for video: %s (duration=%ds, size=%d, format=%s)",
 		videoID, result.Duration, result.FileSize, result.OutputFormat)
 
 	return nil
 }
 
-// updateVideoFailed updates video status to failed with error message
-func (w *TranscodeWorker) updateVideoFailed(ctx context.Context, videoID uuid.UUID, errMsg string) {
+// updateVideoFailed updates video status to failed with error message and
+// counts the failed attempt. Webhooks are told once the job won't be retried.
+func (w *TranscodeWorker) updateVideoFailed(ctx context.Context, job *river.Job[TranscodeJobArgs], videoID uuid.UUID, errMsg string) {
 	if _, err := w.database.Queries.UpdateVideoProcessing(ctx, sqlc.UpdateVideoProcessingParams{
 		ID:               videoID,
 		ProcessingStatus: domain.ProcessingStatusFailed,
@@ -178,6 +236,41 @@ func (w *TranscodeWorker) updateVideoFailed(ctx context.Context, videoID uuid.UU
 	}); err != nil {
 		log.Printf("Error updating video to failed status: %v", err)
 	}
+
+	if err := w.database.Queries.RecordVideoProcessingFailure(ctx, videoID); err != nil {
+		log.Printf("Warning: failed to record processing failure: %v", err)
+	}
+
+	if job.Attempt >= job.MaxAttempts {
+		notifyWebhooks(ctx, w.database, w.config, webhook.EventVideoFailed, videoID, &errMsg)
+	}
+}
+
+// saveTranscodeLog stores a job's FFmpeg log and drops the video's older
+// ones beyond transcodeLogsKept. Jobs that ran no FFmpeg command (e.g. the
+// upload failed validation) have nothing to store.
+func saveTranscodeLog(ctx context.Context, database *db.DB, videoID uuid.UUID, jobKind string, success bool, transcodeLog *video.TranscodeLog) {
+	output := transcodeLog.String()
+	if output == "" {
+		return
+	}
+
+	if err := database.Queries.CreateTranscodeLog(ctx, sqlc.CreateTranscodeLogParams{
+		VideoID: videoID,
+		JobKind: jobKind,
+		Success: success,
+		Output:  output,
+	}); err != nil {
+		log.Printf("Warning: failed to save transcode log for video %s: %v", videoID, err)
+		return
+	}
+
+	if err := database.Queries.PruneTranscodeLogs(ctx, sqlc.PruneTranscodeLogsParams{
+		VideoID: videoID,
+		Limit:   transcodeLogsKept,
+	}); err != nil {
+		log.Printf("Warning: failed to prune transcode logs for video %s: %v", videoID, err)
+	}
 }
 
 // buildTranscodeConfig creates TranscodeConfig from database config
@@ -185,8 +278,15 @@ func buildTranscodeConfig(cfg sqlc.Config) video.TranscodeConfig {
 	// Parse max resolution
 	maxWidth, maxHeight := parseResolution(cfg.MaxResolution)
 
+	renditions, err := video.ParseRenditionLadder(cfg.RenditionLadder)
+	if err != nil {
+		log.Printf("Warning: %v, using a single rendition", err)
+	}
+
 	return video.TranscodeConfig{
 		UseGPU:           cfg.UseGpuTranscoding,
+		GPUBackend:       cfg.GpuBackend,
+		GPUDevice:        int(cfg.GpuDeviceID),
 		MaxWidth:         maxWidth,
 		MaxHeight:        maxHeight,
 		AudioBitrate:     cfg.AudioBitrate,
@@ -197,6 +297,13 @@ func buildTranscodeConfig(cfg sqlc.Config) video.TranscodeConfig {
 		NVENCRateControl: cfg.NvencRateControl,
 		NVENCMaxBitrate:  cfg.NvencMaxBitrate,
 		NVENCBufferSize:  cfg.NvencBufferSize,
+		Renditions:       renditions,
+		NormalizeAudio:   cfg.NormalizeAudio,
+		LoudnessTarget:   int(cfg.LoudnessTargetLufs),
+		AudioTrackMode:   cfg.AudioTrackMode,
+		VideoCodec:       cfg.VideoCodec,
+		HLSSegmentFormat: cfg.HlsSegmentFormat,
+		HWDecode:         cfg.GpuDecoding,
 	}
 }
 
@@ -223,18 +330,43 @@ func parseResolution(resolution string) (int, int) {
 // defaultDBConfig returns fallback config values
 func defaultDBConfig() sqlc.Config {
 	return sqlc.Config{
-		UseGpuTranscoding: false,
-		NvencPreset:       "p4",
-		NvencCq:           18,
-		NvencRateControl:  "vbr",
-		NvencMaxBitrate:   "8M",
-		NvencBufferSize:   "16M",
-		CpuPreset:         "medium",
-		CpuCrf:            18,
-		MaxResolution:     "1920x1080",
-		AudioBitrate:      "192k",
-		VideoOutputFormat: "progressive",
+		UseGpuTranscoding:  false,
+		GpuBackend:         video.GPUBackendNVENC,
+		NvencPreset:        "p4",
+		NvencCq:            18,
+		NvencRateControl:   "vbr",
+		NvencMaxBitrate:    "8M",
+		NvencBufferSize:    "16M",
+		CpuPreset:          "medium",
+		CpuCrf:             18,
+		MaxResolution:      "1920x1080",
+		AudioBitrate:       "192k",
+		VideoOutputFormat:  "progressive",
+		LoudnessTargetLufs: video.DefaultLoudnessTarget,
+		AudioTrackMode:     video.AudioTrackModeFirst,
+		VideoCodec:         video.VideoCodecH264,
+		HlsSegmentFormat:   video.HLSSegmentFormatTS,
+	}
+}
+
+// hasOutputSpace reports whether the video volume has room for a transcode of
+// the uploaded file, leaving the configured free space. A missing upload or
+// unreadable free space lets the transcode go ahead and fail on its own.
+func (w *TranscodeWorker) hasOutputSpace(filename string) bool {
+	info, err := os.Stat(filepath.Join(w.config.TempStoragePath, filename))
+	if err != nil {
+		return true
+	}
+
+	err = storage.CheckFreeSpace(w.config.VideoStoragePath, info.Size()*transcodeOutputFactor, w.config.MinFreeDiskBytes)
+	if errors.Is(err, storage.ErrInsufficientStorage) {
+		log.Printf("Delaying transcode of %s for %v: %v", filename, diskSpaceDelay, err)
+		return false
+	}
+	if err != nil {
+		log.Printf("Warning: failed to check free disk space: %v", err)
 	}
+	return true
 }
 
 // generateOutputFilename generates the output filename from original
@@ -0,0 +1,194 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/riverqueue/river"
+
+	"github.com/clipset/clipset-go/internal/config"
+	"github.com/clipset/clipset-go/internal/db"
+	"github.com/clipset/clipset-go/internal/db/sqlc"
+	"github.com/clipset/clipset-go/internal/services/webhook"
+)
+
+const (
+	// webhookQueue is the queue deliveries run in, apart from transcodes, so
+	// slow receivers never hold up processing
+	webhookQueue = "webhooks"
+
+	// webhookQueueWorkers is how many deliveries run at once
+	webhookQueueWorkers = 2
+
+	// webhookMaxAttempts is how many times a delivery is tried, with
+	// webhookRetryBase, 4x that, 16x that, ... between attempts
+	webhookMaxAttempts = 5
+	webhookRetryBase   = 30 * time.Second
+
+	// webhookDeliveriesKept is how many delivery attempts are kept per webhook
+	webhookDeliveriesKept = 50
+)
+
+// WebhookDeliveryJobArgs defines the arguments for delivering one event to
+// one webhook. The payload is built when the event happens, so retries send
+// the same body.
+type WebhookDeliveryJobArgs struct {
+	WebhookID string          `json:"webhook_id"`
+	Event     string          `json:"event"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// Kind returns the job type identifier
+func (WebhookDeliveryJobArgs) Kind() string {
+	return "webhook_delivery"
+}
+
+// InsertOpts puts deliveries in the webhook queue
+func (WebhookDeliveryJobArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{Queue: webhookQueue, MaxAttempts: webhookMaxAttempts}
+}
+
+// WebhookDeliveryWorker posts events to webhooks and records each attempt
+type WebhookDeliveryWorker struct {
+	river.WorkerDefaults[WebhookDeliveryJobArgs]
+	database *db.DB
+	client   *http.Client
+}
+
+// NewWebhookDeliveryWorker creates a new webhook delivery worker
+func NewWebhookDeliveryWorker(database *db.DB) *WebhookDeliveryWorker {
+	return &WebhookDeliveryWorker{
+		database: database,
+		client:   &http.Client{Timeout: webhook.DeliveryTimeout},
+	}
+}
+
+// NextRetry backs off 30 seconds, 2 minutes, 8 minutes, then 32 minutes
+func (w *WebhookDeliveryWorker) NextRetry(job *river.Job[WebhookDeliveryJobArgs]) time.Time {
+	return time.Now().Add(webhookRetryBase << (2 * (job.Attempt - 1)))
+}
+
+// Timeout bounds a delivery attempt, rather than the transcode job timeout
+func (w *WebhookDeliveryWorker) Timeout(*river.Job[WebhookDeliveryJobArgs]) time.Duration {
+	return webhook.DeliveryTimeout + 5*time.Second
+}
+
+// Work delivers the event, returning an error so River retries it if the
+// receiver didn't answer with a 2xx
+func (w *WebhookDeliveryWorker) Work(ctx context.Context, job *river.Job[WebhookDeliveryJobArgs]) error {
+	webhookID, err := uuid.Parse(job.Args.WebhookID)
+	if err != nil {
+		return river.JobCancel(fmt.Errorf("invalid webhook ID: %w", err))
+	}
+
+	hook, err := w.database.Queries.GetWebhook(ctx, webhookID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Deleted since the event was queued
+			return nil
+		}
+		return fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	start := time.Now()
+	statusCode, deliverErr := webhook.Deliver(ctx, w.client, hook.Url, hook.Secret, job.Args.Event, job.Args.Payload)
+
+	params := sqlc.CreateWebhookDeliveryParams{
+		WebhookID:  webhookID,
+		Event:      job.Args.Event,
+		Attempt:    int32(job.Attempt),
+		DurationMs: int32(time.Since(start).Milliseconds()),
+	}
+	if statusCode != 0 {
+		status := int32(statusCode)
+		params.StatusCode = &status
+	}
+	if deliverErr != nil {
+		errMsg := deliverErr.Error()
+		params.Error = &errMsg
+	}
+	if err := w.database.Queries.CreateWebhookDelivery(ctx, params); err != nil {
+		log.Printf("Warning: failed to record delivery to webhook %s: %v", webhookID, err)
+	} else if err := w.database.Queries.PruneWebhookDeliveries(ctx, sqlc.PruneWebhookDeliveriesParams{
+		WebhookID: webhookID,
+		Limit:     webhookDeliveriesKept,
+	}); err != nil {
+		log.Printf("Warning: failed to prune deliveries of webhook %s: %v", webhookID, err)
+	}
+
+	if deliverErr != nil {
+		return fmt.Errorf("delivery of %s to webhook %s failed: %w", job.Args.Event, webhookID, deliverErr)
+	}
+	return nil
+}
+
+// enqueueWebhookEvent queues a delivery of event to every webhook subscribed
+// to it. failure is the processing error for video.failed.
+func enqueueWebhookEvent(ctx context.Context, client *river.Client[pgx.Tx], database *db.DB, cfg *config.Config, event string, videoID uuid.UUID, failure *string) error {
+	hooks, err := database.Queries.ListWebhooksForEvent(ctx, event)
+	if err != nil {
+		return fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	videoRecord, err := database.Queries.GetVideoByIDWithUploader(ctx, videoID)
+	if err != nil {
+		return fmt.Errorf("failed to get video: %w", err)
+	}
+
+	baseURL := strings.TrimSuffix(cfg.FrontendBaseURL, "/")
+	payloadVideo := webhook.Video{
+		ShortID: videoRecord.ShortID,
+		Title:   videoRecord.Title,
+		URL:     fmt.Sprintf("%s/v/%s", baseURL, videoRecord.ShortID),
+		Uploader: webhook.Uploader{
+			Username:    videoRecord.UploaderUsername,
+			DisplayName: videoRecord.UploaderDisplayName,
+		},
+		DurationSeconds: videoRecord.DurationSeconds,
+		Error:           failure,
+	}
+	if videoRecord.ThumbnailFilename != nil && *videoRecord.ThumbnailFilename != "" {
+		thumbnailURL := fmt.Sprintf("%s/api/videos/%s/thumbnail", baseURL, videoRecord.ShortID)
+		payloadVideo.ThumbnailURL = &thumbnailURL
+	}
+
+	payload, err := json.Marshal(webhook.NewPayload(event, payloadVideo))
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	jobs := make([]river.InsertManyParams, len(hooks))
+	for i, hook := range hooks {
+		jobs[i] = river.InsertManyParams{Args: WebhookDeliveryJobArgs{
+			WebhookID: hook.ID.String(),
+			Event:     event,
+			Payload:   payload,
+		}}
+	}
+	if _, err := client.InsertMany(ctx, jobs); err != nil {
+		return fmt.Errorf("failed to enqueue deliveries: %w", err)
+	}
+
+	log.Printf("Enqueued %s deliveries to %d webhooks for video %s", event, len(hooks), videoID)
+	return nil
+}
+
+// notifyWebhooks queues event deliveries from inside a job. Failures are
+// logged, so webhooks never fail the job.
+func notifyWebhooks(ctx context.Context, database *db.DB, cfg *config.Config, event string, videoID uuid.UUID, failure *string) {
+	client := river.ClientFromContext[pgx.Tx](ctx)
+	if err := enqueueWebhookEvent(ctx, client, database, cfg, event, videoID, failure); err != nil {
+		log.Printf("Warning: failed to queue %s webhooks for video %s: %v", event, videoID, err)
+	}
+}
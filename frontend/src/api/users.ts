@@ -12,13 +12,13 @@ export function useUsers(params: PaginationParams = {}) {
   return useQuery({
     queryKey: ["users", params],
     queryFn: async () => {
-      const response = await apiClient.get<UserResponse[]>("/api/users/", { 
+      const response = await apiClient.get<{ users: UserResponse[]; total: number }>("/api/users/", { 
         params: {
           skip: params.skip ?? (params.page ? (params.page - 1) * (params.page_size ?? 10) : 0),
           limit: params.limit ?? params.page_size ?? 10
         }
       })
-      return response.data
+      return response.data.users
     }
   })
 }
@@ -150,7 +150,7 @@ export interface AdminStats {
 
 export async function getAdminStats(): Promise<AdminStats> {
   // Fetch users (limit to reasonable number for small communities)
-  const usersResponse = await apiClient.get<UserResponse[]>("/api/users/", {
+  const usersResponse = await apiClient.get<{ users: UserResponse[]; total: number }>("/api/users/", {
     params: { skip: 0, limit: 500 }
   })
   
@@ -181,7 +181,7 @@ export async function getAdminStats(): Promise<AdminStats> {
   })
   
   return {
-    totalUsers: usersResponse.data.length,
+    totalUsers: usersResponse.data.total,
     totalVideos: videosResponse.data.total,
     videosByStatus,
     totalStorageBytes
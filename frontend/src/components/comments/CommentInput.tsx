@@ -73,7 +73,7 @@ export function CommentInput({
     <div className="flex gap-4">
       <UserAvatar 
         username={user.username} 
-        avatarUrl={user.avatar_filename ? `/media/avatars/${user.avatar_filename}` : undefined}
+        avatarUrl={user.avatar_url}
         size="sm"
         className="mt-1 shrink-0"
       />
@@ -1,16 +1,21 @@
 export interface InvitationCreate {
-  email: string
+  email?: string
+  role?: string
+  max_uses?: number
 }
 
 export interface InvitationResponse {
   id: string
-  email: string
+  email: string | null
   token: string
   created_by: string
   created_at: string
   expires_at: string
   used: boolean
   used_at: string | null
+  role: string
+  max_uses: number
+  use_count: number
 }
 
 export interface InvitationWithLink extends InvitationResponse {
@@ -19,6 +24,6 @@ export interface InvitationWithLink extends InvitationResponse {
 
 export interface InvitationValidation {
   valid: boolean
-  email?: string
+  email?: string | null
   message?: string
 }